@@ -0,0 +1,51 @@
+// Команда rulesgen генерирует файл правил Prometheus (записывающие правила и алерты)
+// для метрик, которые сервис отдает на /metrics. Пороги берутся из тех же переменных
+// окружения и файла конфигурации, что и конфигурация сервиса (ALERT_*).
+//
+//	go run ./cmd/rulesgen -o deploy/prometheus/subscription-service.rules.yml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+)
+
+func main() {
+	output := flag.String("o", "", "файл для записи правил; по умолчанию stdout")
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "файл конфигурации YAML или JSON")
+	flag.Parse()
+
+	if err := run(*output, *configPath); err != nil {
+		fmt.Fprintln(os.Stderr, "rulesgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, configPath string) error {
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create rules file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return metrics.WriteRules(w, metrics.RuleConfig{
+		Window:          cfg.AlertRuleWindow,
+		For:             cfg.AlertFor,
+		QueryLatencyP95: cfg.AlertQueryLatencyP95,
+		QueryRowsP95:    cfg.AlertQueryRowsP95,
+	})
+}
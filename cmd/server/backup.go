@@ -0,0 +1,120 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/backup"
+	"github.com/Zipklas/subscription-service/internal/blobstore"
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/egress"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/service"
+)
+
+// backupTimeout ограничивает снятие и загрузку резервной копии из командной строки
+const backupTimeout = time.Hour
+
+// runBackup снимает зашифрованную резервную копию таблиц сервиса, загружает ее
+// в хранилище файлов, удаляет копии с истекшим сроком хранения и выводит запись
+// о копии в JSON
+func runBackup(configPath string) error {
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		return err
+	}
+	log := logger.New(cfg.LogLevel)
+
+	if cfg.BackupEncryptionKey == "" {
+		return errors.New("BACKUP_ENCRYPTION_KEY is required for backups")
+	}
+	key, err := backup.ParseKey(cfg.BackupEncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	outbound, err := egress.New(egress.Config{
+		ProxyURL:     cfg.OutboundProxyURL,
+		DialTimeout:  cfg.OutboundDialTimeout,
+		AllowedHosts: cfg.EgressAllowedHosts,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid egress configuration: %w", err)
+	}
+	store, err := blobstore.New(blobstore.Config{
+		Driver:    cfg.BlobDriver,
+		Bucket:    cfg.BlobBucket,
+		LocalDir:  cfg.BlobLocalDir,
+		Endpoint:  cfg.BlobEndpoint,
+		Region:    cfg.BlobRegion,
+		AccessKey: cfg.BlobAccessKey,
+		SecretKey: cfg.BlobSecretKey,
+		Client:    outbound.Client(backupTimeout),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+
+	// Чтение больших таблиц может идти дольше DB_STATEMENT_TIMEOUT, его ограничивает ctx
+	pool, err := database.Open(ctx, cfg.GetDBConnectionString(), database.Settings{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	svc := service.NewBackupService(repository.NewBackupRepository(pool.DB, log), store, service.BackupConfig{
+		Tables:    database.BackupTables(),
+		Key:       key,
+		Prefix:    cfg.BackupPrefix,
+		Retention: time.Duration(cfg.BackupRetentionDays) * 24 * time.Hour,
+	}, log)
+	result, err := svc.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// runBackupDecrypt расшифровывает скачанную копию path ключом BACKUP_ENCRYPTION_KEY
+// и выводит ее NDJSON в stdout
+func runBackupDecrypt(configPath, path string) error {
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		return err
+	}
+	key, err := backup.ParseKey(cfg.BackupEncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decrypted, err := backup.NewReader(file, key)
+	if err != nil {
+		return err
+	}
+	decompressed, err := gzip.NewReader(decrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	_, err = io.Copy(os.Stdout, decompressed)
+	return err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// teardownTimeout ограничивает удаление данных организации из командной строки
+const teardownTimeout = 5 * time.Minute
+
+// teardownOptions - флаги удаления данных организации
+type teardownOptions struct {
+	tenant  string
+	userID  string
+	confirm string
+	dryRun  bool
+	force   bool
+}
+
+// runTeardown удаляет данные организации теми же правилами, что и
+// POST /admin/tenants/{tenant}/teardown, и выводит результат в JSON. force
+// разрешает удаление в production, как TEARDOWN_ALLOW_PRODUCTION
+func runTeardown(configPath string, o teardownOptions) error {
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		return err
+	}
+	log := logger.New(cfg.LogLevel)
+
+	req := model.TeardownRequest{Confirm: o.confirm, DryRun: o.dryRun}
+	if o.userID != "" {
+		userID, err := uuid.Parse(o.userID)
+		if err != nil {
+			return fmt.Errorf("invalid user id %q: %w", o.userID, err)
+		}
+		req.UserID = &userID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), teardownTimeout)
+	defer cancel()
+
+	// Удаление большой организации может идти дольше DB_STATEMENT_TIMEOUT, его ограничивает ctx
+	pool, err := database.Open(ctx, cfg.GetDBConnectionString(), database.Settings{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	svc := service.NewTeardownService(repository.NewTeardownRepository(pool.DB, log), cfg.TeardownEnabled() || o.force, log)
+	result, err := svc.Teardown(ctx, o.tenant, req)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,205 @@
+// Команда swaggerexamples добавляет в документацию Swagger примеры ответов из эталонных
+// ответов тестов обработчиков (internal/handler/testdata/*.golden.json): примеры в
+// документации совпадают с тем, что возвращает API, и обновляются вместе с тестами.
+// Запускается после swag init (см. docs/generate.go):
+//
+//	go run ./cmd/swaggerexamples -docs docs -testdata internal/handler/testdata
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v2"
+)
+
+// mimeJSON - тип содержимого примеров: ответы API описаны как application/json
+const mimeJSON = "application/json"
+
+// example - эталонный ответ операции документации
+type example struct {
+	method string
+	path   string
+	status int
+	golden string
+}
+
+// examples сопоставляет операции с эталонными ответами тестов обработчиков
+var examples = []example{
+	{"get", "/subscriptions", 200, "list_subscriptions"},
+	{"post", "/subscriptions", 201, "create_subscription"},
+	{"post", "/subscriptions", 500, "error_database"},
+	{"get", "/subscriptions/{id}", 200, "get_subscription"},
+	{"get", "/subscriptions/{id}", 400, "error_invalid_id"},
+	{"get", "/subscriptions/{id}", 404, "error_not_found"},
+	{"put", "/subscriptions/{id}", 200, "update_subscription"},
+	{"put", "/subscriptions/{id}", 404, "error_not_found"},
+	{"delete", "/subscriptions/{id}", 200, "delete_subscription"},
+	{"delete", "/subscriptions/{id}", 404, "error_not_found"},
+	{"get", "/subscriptions/summary", 200, "summary"},
+	{"get", "/subscriptions/summary", 400, "error_missing_periods"},
+	{"get", "/users/{id}/services", 200, "list_user_services"},
+}
+
+// schemesLine - строка docs.go, которую swag добавляет в шаблон документа вне JSON
+const schemesLine = "\n    \"schemes\": [[ marshal .Schemes ]],"
+
+func main() {
+	docsDir := flag.String("docs", "docs", "каталог документации swag")
+	testdata := flag.String("testdata", filepath.Join("internal", "handler", "testdata"), "каталог эталонных ответов")
+	flag.Parse()
+
+	if err := run(*docsDir, *testdata); err != nil {
+		fmt.Fprintln(os.Stderr, "swaggerexamples:", err)
+		os.Exit(1)
+	}
+}
+
+func run(docsDir, testdata string) error {
+	jsonPath := filepath.Join(docsDir, "swagger.json")
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return fmt.Errorf("failed to read swagger document: %w", err)
+	}
+	doc, err := addExamples(data, testdata)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(jsonPath, doc, 0o644); err != nil {
+		return fmt.Errorf("failed to write swagger document: %w", err)
+	}
+
+	yamlDoc, err := toYAML(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "swagger.yaml"), yamlDoc, 0o644); err != nil {
+		return fmt.Errorf("failed to write swagger document: %w", err)
+	}
+
+	goPath := filepath.Join(docsDir, "docs.go")
+	source, err := os.ReadFile(goPath)
+	if err != nil {
+		return fmt.Errorf("failed to read docs package: %w", err)
+	}
+	source, err = addTemplateExamples(source, testdata)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(goPath, source, 0o644); err != nil {
+		return fmt.Errorf("failed to write docs package: %w", err)
+	}
+	return nil
+}
+
+// addExamples добавляет примеры в документ data и возвращает его в форматировании swag
+func addExamples(data []byte, testdata string) ([]byte, error) {
+	var swagger spec.Swagger
+	if err := json.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	for _, ex := range examples {
+		body, err := os.ReadFile(filepath.Join(testdata, ex.golden+".golden.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read example: %w", err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return nil, fmt.Errorf("invalid example %s: %w", ex.golden, err)
+		}
+
+		op, err := operation(&swagger, ex)
+		if err != nil {
+			return nil, err
+		}
+		response, ok := op.Responses.StatusCodeResponses[ex.status]
+		if !ok {
+			return nil, fmt.Errorf("%s %s has no %d response", strings.ToUpper(ex.method), ex.path, ex.status)
+		}
+		response.Examples = map[string]interface{}{mimeJSON: value}
+		op.Responses.StatusCodeResponses[ex.status] = response
+	}
+
+	doc, err := json.MarshalIndent(&swagger, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode swagger document: %w", err)
+	}
+	return doc, nil
+}
+
+func operation(swagger *spec.Swagger, ex example) (*spec.Operation, error) {
+	if swagger.Paths == nil {
+		return nil, fmt.Errorf("swagger document has no paths")
+	}
+	item, ok := swagger.Paths.Paths[ex.path]
+	if !ok {
+		return nil, fmt.Errorf("path %s is not documented", ex.path)
+	}
+	op := map[string]*spec.Operation{
+		"get":    item.Get,
+		"post":   item.Post,
+		"put":    item.Put,
+		"delete": item.Delete,
+	}[ex.method]
+	if op == nil || op.Responses == nil {
+		return nil, fmt.Errorf("%s %s is not documented", strings.ToUpper(ex.method), ex.path)
+	}
+	return op, nil
+}
+
+// toYAML переводит документ в YAML так же, как swag: ключи по алфавиту
+func toYAML(doc []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(doc, &value); err != nil {
+		return nil, fmt.Errorf("failed to convert swagger document to YAML: %w", err)
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert swagger document to YAML: %w", err)
+	}
+	return out, nil
+}
+
+// addTemplateExamples добавляет примеры в шаблон документа docs.go. Шаблон - JSON
+// документа с подстановками swag вместо полей info, host и basePath; строка schemes
+// стоит вне JSON и возвращается на место после изменения
+func addTemplateExamples(source []byte, testdata string) ([]byte, error) {
+	const start, end = "const docTemplate = `", "`\n\n"
+	head, rest, ok := bytes.Cut(source, []byte(start))
+	if !ok {
+		return nil, fmt.Errorf("docs.go has no document template")
+	}
+	template, tail, ok := bytes.Cut(rest, []byte(end))
+	if !ok {
+		return nil, fmt.Errorf("docs.go has no document template")
+	}
+
+	backtick := "`+\"`\"+`"
+	doc := strings.ReplaceAll(string(template), backtick, "`")
+	doc, ok = strings.CutPrefix(doc, "{"+schemesLine)
+	if !ok {
+		return nil, fmt.Errorf("docs.go template has no schemes placeholder")
+	}
+
+	updated, err := addExamples([]byte("{"+doc), testdata)
+	if err != nil {
+		return nil, err
+	}
+	doc = "{" + schemesLine + string(updated[1:])
+	doc = strings.ReplaceAll(doc, "`", backtick)
+
+	var out bytes.Buffer
+	out.Write(head)
+	out.WriteString(start)
+	out.WriteString(doc)
+	out.WriteString(end)
+	out.Write(tail)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDocsExamplesUpToDate проверяет, что примеры в документации совпадают с эталонными
+// ответами: после изменения golden-файлов нужно выполнить go generate ./docs
+func TestDocsExamplesUpToDate(t *testing.T) {
+	docsDir := filepath.Join("..", "..", "docs")
+	testdata := filepath.Join("..", "..", "internal", "handler", "testdata")
+
+	data, err := os.ReadFile(filepath.Join(docsDir, "swagger.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := addExamples(data, testdata)
+	if err != nil {
+		t.Fatalf("addExamples: %v", err)
+	}
+	if !bytes.Equal(doc, data) {
+		t.Error("docs/swagger.json examples are stale, run go generate ./docs")
+	}
+
+	source, err := os.ReadFile(filepath.Join(docsDir, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := addTemplateExamples(source, testdata)
+	if err != nil {
+		t.Fatalf("addTemplateExamples: %v", err)
+	}
+	if !bytes.Equal(updated, source) {
+		t.Error("docs/docs.go examples are stale, run go generate ./docs")
+	}
+}
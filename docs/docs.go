@@ -4,11 +4,11 @@ package docs
 import "github.com/swaggo/swag"
 
 const docTemplate = `{
-    "schemes": {{ marshal .Schemes }},
+    "schemes": [[ marshal .Schemes ]],
     "swagger": "2.0",
     "info": {
-        "description": "{{escape .Description}}",
-        "title": "{{.Title}}",
+        "description": "[[escape .Description]]",
+        "title": "[[.Title]]",
         "termsOfService": "http://swagger.io/terms/",
         "contact": {
             "name": "API Support",
@@ -19,35 +19,84 @@ const docTemplate = `{
             "name": "MIT",
             "url": "https://opensource.org/licenses/MIT"
         },
-        "version": "{{.Version}}"
+        "version": "[[.Version]]"
     },
-    "host": "{{.Host}}",
-    "basePath": "{{.BasePath}}",
+    "host": "[[.Host]]",
+    "basePath": "[[.BasePath]]",
     "paths": {
-        "/subscriptions": {
+        "/admin/audit": {
             "get": {
-                "description": "Возвращает список подписок с возможностью фильтрации по пользователю и сервису",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает изменения подписок организации, начиная с последних. Автор (actor) - \"user:\u003cid\u003e\", \"admin:\u003cid\u003e\" или \"admin\"; изменения вне API записываются без автора",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Список подписок",
+                "summary": "Журнал аудита",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID пользователя для фильтрации",
+                        "description": "ID подписки",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID владельца подписки",
                         "name": "user_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Название сервиса для фильтрации",
-                        "name": "service_name",
+                        "description": "Автор изменения",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete"
+                        ],
+                        "type": "string",
+                        "description": "Действие",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID запроса",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Не раньше момента (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Раньше момента (RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Записи старше указанной",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум записей (по умолчанию 100, не больше 1000)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -57,10 +106,28 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Subscription"
+                                "$ref": "#/definitions/model.AuditEntry"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -68,35 +135,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создает новую запись о подписке пользователя",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/backups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает копии, снятые командой server -backup, начиная с новых: ключ в хранилище файлов, состояние, момент и позицию WAL, на которые согласована копия, число строк по таблицам, размер и SHA-256 зашифрованного файла и срок хранения",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Создать подписку",
+                "summary": "Резервные копии",
                 "parameters": [
                     {
-                        "description": "Данные для создания подписки",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateSubscriptionRequest"
-                        }
+                        "type": "integer",
+                        "description": "Максимум записей (по умолчанию 50, не больше 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Subscription"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Backup"
+                            }
                         }
                     },
                     "400": {
@@ -105,6 +176,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -114,44 +197,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/summary": {
+        "/admin/backups/{id}": {
             "get": {
-                "description": "Подсчитывает суммарную стоимость всех подписок за выбранный период с фильтрацией",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает запись журнала резервных копий",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "summary"
+                    "admin"
                 ],
-                "summary": "Подсчет стоимости",
+                "summary": "Резервная копия",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID пользователя для фильтрации",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Название сервиса для фильтрации",
-                        "name": "service_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Начало периода (формат: YYYY-MM)",
-                        "name": "start_period",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Конец периода (формат: YYYY-MM)",
-                        "name": "end_period",
-                        "in": "query",
+                        "description": "ID копии",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -159,7 +225,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.SummaryResponse"
+                            "$ref": "#/definitions/model.Backup"
                         }
                     },
                     "400": {
@@ -168,6 +234,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -177,9 +261,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/{id}": {
-            "get": {
-                "description": "Возвращает информацию о подписке по её ID",
+        "/admin/db/analyze": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполняет ANALYZE таблиц subscriptions, subscription_changes, subscription_pauses и subscription_transfers (или перечисленных в tables) по очереди и возвращает время каждой. Тело можно не передавать",
                 "consumes": [
                     "application/json"
                 ],
@@ -187,23 +276,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Получить подписку",
+                "summary": "ANALYZE таблиц подписок",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Таблицы",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.AnalyzeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Subscription"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AnalyzedTable"
+                            }
                         }
                     },
                     "400": {
@@ -212,8 +305,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -225,52 +324,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновляет информацию о подписке",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/db/indexes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает для индексов таблиц сервиса число просмотров, прочитанных строк и размер, начиная с реже всего используемых. unused - неуникальный индекс без единого просмотра с последнего сброса статистики",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
-                ],
-                "summary": "Обновить подписку",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные для обновления подписки",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateSubscriptionRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "Статистика индексов БД",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.SuccessResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.IndexStats"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -282,9 +370,51 @@ const docTemplate = `{
                         }
                     }
                 }
+            }
+        },
+        "/admin/db/pool": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает настройки пула, статистику соединений и результат ping базы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Состояние пула соединений с БД",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PoolStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
             },
-            "delete": {
-                "description": "Удаляет запись о подписке",
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет пул соединений пулом с новыми размерами и statement_timeout. Выполняющиеся запросы не прерываются: соединения прежнего пула закрываются по мере их возврата. Счетчики статистики пула начинаются заново",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,23 +422,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Удалить подписку",
+                "summary": "Изменить настройки пула соединений с БД",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Новые настройки",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdatePoolSettingsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.SuccessResponse"
+                            "$ref": "#/definitions/model.PoolStatus"
                         }
                     },
                     "400": {
@@ -317,127 +449,7993 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
+        },
+        "/admin/db/queries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает для каждого запроса репозитория число выполнений и гистограммы количества возвращенных строк и времени выполнения с момента запуска сервиса. Корзины накопительные: count - число наблюдений не больше le",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика запросов к БД",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.QueryStats"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/db/schema": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Проверяет, что в базе есть все таблицы, колонки и индексы, с которыми работают репозитории. Результат также обновляет метрику subscription_service_schema_drift_objects",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Расхождение схемы БД",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SchemaDrift"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/db/tables": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает для таблиц сервиса число живых и мертвых строк (dead_ratio - оценка раздувания), размеры с индексами, число последовательных и индексных просмотров, изменения после последнего ANALYZE и время последних VACUUM и ANALYZE, начиная с самых больших таблиц",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика таблиц БД",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TableStats"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/inbound-events/deduplicated": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает события, повторные доставки которых были отброшены, начиная с последнего повтора: источник, идентификатор и тип события, затронутую подписку, время приема и обработки, число повторов и время последнего",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отброшенные повторы входящих событий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Максимум записей (по умолчанию 50, не больше 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.InboundRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает расписание, время последнего и следующего запуска и ошибки фоновых задач",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Фоновые задачи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.JobStatus"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/routes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает зарегистрированные маршруты с методами, обработчиками, middleware в порядке выполнения и требованиями аутентификации",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Маршруты API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RouteInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/sso-groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сопоставления групп SSO",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GroupMapping"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Участники группы из claim OIDC_GROUPS_CLAIM токена получают доступ к организации tenant: role member - к своим данным, admin - права администратора в ней. admin без tenant дает права администратора во всех организациях. Повторный запрос с тем же телом ничего не меняет; изменения видны другим репликам в течение 30 секунд",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сохранить сопоставление группы SSO",
+                "parameters": [
+                    {
+                        "description": "Группа, организация и роль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SaveGroupMappingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupMapping"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить сопоставление группы SSO",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenants/{tenant}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Декларативное заведение организации для систем развертывания: название, налоговые настройки, сервисы каталога, теги и личные токены API пользователей в одной транзакции. Повтор запроса ничего не меняет: существующие сервисы приводятся к описанию, недостающие теги создаются, токен выпускается, только если у пользователя нет токена с тем же названием. Не перечисленные в запросе сервисы, теги и токены не удаляются. Блок settings задает ставку налога и округление организации целиком: незаданное поле возвращает значение из конфигурации, без блока настройки не меняются. Секреты выпущенных токенов показываются только в этом ответе. 201 - организация заведена, 200 - уже существовала",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Завести организацию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор организации",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Желаемое состояние организации",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ProvisionTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProvisionTenantResult"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProvisionTenantResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenants/{tenant}/teardown": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет подписки, паузы, передачи, скидки, счета, журналы изменений и аудита, отклоненные запросы и настройки уведомлений организации (с user_id - только данные пользователя) для сброса демо- и staging-стендов. confirm должен совпадать с идентификатором организации; dry_run возвращает число строк, ничего не удаляя. При APP_ENV=production запрещено, пока не задан TEARDOWN_ALLOW_PRODUCTION=true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить данные организации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор организации",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Подтверждение и область удаления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TeardownRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeardownResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает для каждого адреса из WEBHOOK_URLS глубину очереди, число доставленных, неудачных и отброшенных событий и состояние выключателя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Доставка вебхуков",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookEndpointStats"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/analytics/retention": {
+            "get": {
+                "description": "Для каждого месяца первой подписки (когорты) возвращает число пользователей и сколько из них сохранили подписку через 0, 1, 2... месяцев до текущего, с долями. С service_name учитываются только подписки на этот сервис",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Удержание по когортам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество когорт - последних месяцев, включая текущий (по умолчанию 12)",
+                        "name": "months",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RetentionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/discounts": {
+            "get": {
+                "description": "Возвращает скидки с возможностью фильтрации по подписке и пользователю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Список скидок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "subscription_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя (скидки на все подписки пользователя)",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Discount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает процентную или фиксированную скидку на подписку или на все подписки пользователя. Скидка учитывается в расчете суммарной стоимости за месяцы своего действия. Скидки создает, меняет и удаляет только администратор",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Создать скидку",
+                "parameters": [
+                    {
+                        "description": "Данные скидки",
+                        "name": "discount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DiscountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Discount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/discounts/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Получить скидку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID скидки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Discount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Обновить скидку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID скидки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные скидки",
+                        "name": "discount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DiscountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Discount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Удалить скидку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID скидки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event-schemas": {
+            "get": {
+                "description": "Возвращает типы событий вебхуков и версии JSON-схем их данных. Вебхук передает тип и версию в заголовках X-Event-Type и X-Event-Schema-Version; новая версия совместима с предыдущими",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Схемы событий",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.EventSchemaVersions"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/event-schemas/{type}/{version}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Схема события",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип события, например subscription.renewal_reminder",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Версия схемы",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/inbound": {
+            "post": {
+                "description": "Применяет событие внешней системы об изменении подписки: subscription.created (data - тело POST /subscriptions), subscription.updated (id и тело PUT /subscriptions/{id}), subscription.cancelled (id и тело POST /subscriptions/{id}/cancel) или subscription.deleted (id). Событие определяется парой source и id: повторная доставка уже примененного события не применяется второй раз и возвращает status duplicate. Повтор события, которое еще обрабатывается, - 409; событие, применение которого завершилось ошибкой, можно доставить повторно",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Принять входящее событие",
+                "parameters": [
+                    {
+                        "description": "Событие",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InboundEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.InboundEventResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Проверка работоспособности сервиса. Пока не установлено первое подключение к базе (DB_LAZY_CONNECT), отвечает 503 со status=degraded",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Отвечает 200, пока процесс обрабатывает HTTP-запросы. Зависимости не проверяются, чтобы сбой базы не приводил к перезапуску экземпляров",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness-проба",
+                "responses": {
+                    "200": {
+                        "description": "status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Получить счет",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID счета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/export": {
+            "get": {
+                "description": "Выгружает строки счета в CSV; последняя строка содержит итоги с подписью месяца на языке из Accept-Language (ru или en, по умолчанию REPORT_LOCALE), например \"Итого: Январь 2025\"",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Выгрузка счета",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID счета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык подписей: ru или en",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV с заголовком",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/usage": {
+            "get": {
+                "description": "Возвращает число запросов клиента по эндпоинтам за последние дни (UTC) и потребление лимита в текущей минуте. Клиент определяется заголовком X-API-Key; ключ не проверяется, поэтому статистика справочная",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Статистика использования API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ клиента",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "За сколько дней вернуть статистику, включая текущий (по умолчанию 7)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics/subscriptions/activity": {
+            "get": {
+                "description": "Возвращает по интервалам количество созданных, отмененных, удаленных подписок и изменений цены для дашбордов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Активность подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (формат: YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода включительно (формат: YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Интервал группировки",
+                        "name": "bucket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ActivityBucket"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет зависимости (ping базы, применение миграций) с таймаутом READINESS_TIMEOUT. Если хотя бы одна недоступна, отвечает 503, и Kubernetes перестает направлять запросы на экземпляр",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness-проба",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rejected-requests": {
+            "get": {
+                "description": "Возвращает запросы на запись организации, отклоненные с кодом 4xx, начиная с последних: маршрут, код причины (validation, unauthenticated, forbidden, not_found, conflict, too_large, rate_limited, rejected) и сообщение об ошибке. Обычный пользователь видит только свои запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rejected-requests"
+                ],
+                "summary": "Отклоненные запросы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код причины",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаблон маршрута, например /api/v1/subscriptions",
+                        "name": "route",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Не раньше момента (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум записей (по умолчанию 100, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RejectedRequest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "description": "Возвращает сервисы каталога по названию с возможностью фильтрации по категории",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Каталог сервисов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Категория",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CatalogService"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет известный сервис подписок: каноническое название (уникально без учета регистра), категорию, стоимость по умолчанию и иконку. Подписки ссылаются на сервис через service_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Добавить сервис в каталог",
+                "parameters": [
+                    {
+                        "description": "Данные сервиса",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogService"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Получить сервис каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogService"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет поля сервиса; подписки, ссылающиеся на сервис, не меняются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Обновить сервис каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные сервиса",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogService"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Подписки, ссылавшиеся на сервис, остаются без service_id",
+                "tags": [
+                    "services"
+                ],
+                "summary": "Удалить сервис каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "description": "Возвращает список подписок с возможностью фильтрации по пользователю, сервису и состоянию",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Список подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя для фильтрации",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса для фильтрации",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID сервиса из каталога для фильтрации",
+                        "name": "service_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег подписки для фильтрации (без учета регистра)",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Состояние подписки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Исключить подписки сервиса; параметр повторяется",
+                        "name": "exclude_service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Исключить подписки пользователя; параметр повторяется",
+                        "name": "exclude_user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 100, максимум 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение от начала списка",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из X-Next-Cursor; пустое значение включает курсорный режим с начала списка. Несовместим с offset",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimate"
+                        ],
+                        "type": "string",
+                        "description": "Подсчет X-Total-Count: точный или оценка планировщика для больших выборок (по умолчанию exact)",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Subscription"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Курсор следующей страницы (только с cursor)"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Общее количество подписок под фильтром (только без cursor)"
+                            },
+                            "X-Total-Count-Estimated": {
+                                "type": "boolean",
+                                "description": "X-Total-Count может быть оценкой (только с count=estimate)"
+                            }
+                        },
+                        "examples": {
+                            "application/json": [
+                                {
+                                    "billing_period": "monthly",
+                                    "change_seq": 42,
+                                    "cost": 400,
+                                    "created_at": "2025-07-10 12:30:00",
+                                    "end_date": "12-2025",
+                                    "id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11",
+                                    "is_draft": false,
+                                    "monthly_cost": 400,
+                                    "service_name": "Yandex Plus",
+                                    "start_date": "07-2025",
+                                    "status": "active",
+                                    "updated_at": "2025-07-10 12:30:00",
+                                    "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новую запись о подписке пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Создать подписку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "billing_period": "monthly",
+                                "change_seq": 42,
+                                "cost": 400,
+                                "created_at": "2025-07-10 12:30:00",
+                                "end_date": "12-2025",
+                                "id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11",
+                                "is_draft": false,
+                                "monthly_cost": 400,
+                                "service_name": "Yandex Plus",
+                                "start_date": "07-2025",
+                                "status": "active",
+                                "updated_at": "2025-07-10 12:30:00",
+                                "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "INTERNAL_ERROR",
+                                "detail": "database is unavailable",
+                                "status": 500,
+                                "title": "Internal Server Error",
+                                "type": "urn:subscription-service:problem:internal-error"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/batch": {
+            "put": {
+                "description": "Изменяет до 1000 подписок. Каждый элемент - id и тело PUT /subscriptions/{id}. Режимы и коды ответа - как у POST /subscriptions/batch; элементы без изменений отмечаются unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Изменить подписки пачкой",
+                "parameters": [
+                    {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Режим: atomic или best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Изменения подписок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает до 1000 подписок. Каждый элемент проверяется как тело POST /subscriptions. В режиме atomic (по умолчанию) подписки создаются в одной транзакции: при ошибке любого элемента не создается ни одна, остальные элементы отмечаются skipped, ответ - 422. В режиме best_effort элементы создаются независимо, при ошибках части элементов ответ - 207. Результат каждого элемента возвращается в порядке запроса",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Создать подписки пачкой",
+                "parameters": [
+                    {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Режим: atomic или best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет до 1000 подписок по идентификаторам. Режимы и коды ответа - как у POST /subscriptions/batch; отсутствующая подписка - ошибка элемента",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удалить подписки пачкой",
+                "parameters": [
+                    {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Режим: atomic или best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Идентификаторы подписок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkDeleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/changes": {
+            "get": {
+                "description": "Возвращает операции создания, изменения и удаления подписок с полным образом строки в порядке seq, начиная после курсора since_seq, для инкрементальной синхронизации без CDC. Номера выдаются в порядке фиксации транзакций, поэтому изменение не появится позади курсора. Журнал содержит подписки всех пользователей: при включенной аутентификации доступен только администратору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Изменения подписок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер последнего полученного изменения (next_since_seq из предыдущего ответа)",
+                        "name": "since_seq",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество записей (по умолчанию 100, максимум 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ChangesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/export": {
+            "get": {
+                "description": "Потоково выгружает все подписки в CSV в порядке (created_at, id). Подписки, существовавшие на момент начала выгрузки, попадают в нее ровно один раз даже при одновременных вставках",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Выгрузка подписок",
+                "responses": {
+                    "200": {
+                        "description": "CSV с заголовком",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/import": {
+            "post": {
+                "description": "Создает подписки из файла CSV или XLSX (первый лист). Первая строка - названия столбцов: service_name, user_id, start_date, monthly_cost, prepaid_amount или is_free, необязательные end_date и is_draft; остальные столбцы игнорируются, поэтому принимается файл из /subscriptions/export. Периоды в XLSX должны быть текстовыми ячейками MM-YYYY. Каждая строка проверяется как тело POST /subscriptions, корректные строки создаются пачками в отдельных транзакциях, по остальным возвращаются ошибки с номерами строк файла. Не больше 10000 строк и 10 МБ",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Импорт подписок",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Файл .csv или .xlsx",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/search": {
+            "get": {
+                "description": "Ищет без учета регистра и диакритики (unaccent + ILIKE) с учетом опечаток (pg_trgm). Сначала идут точные совпадения, затем совпадения с начала названия, по подстроке и похожие названия",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Поиск подписок по названию сервиса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество результатов (по умолчанию 20, максимум 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/stream": {
+            "get": {
+                "description": "Пишет подписки под фильтром по одной JSON-строке в порядке (created_at, id) по мере чтения строк из базы, не собирая весь список в памяти. Фильтры - как у GET /subscriptions. Если чтение прервалось после начала ответа, последней строкой приходит объект {\"error\": \"...\"}",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Потоковая выгрузка подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя для фильтрации",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса для фильтрации",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID сервиса из каталога для фильтрации",
+                        "name": "service_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег подписки для фильтрации (без учета регистра)",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Состояние подписки",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Одна подписка на строку",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/summary": {
+            "get": {
+                "description": "Подсчитывает суммарную стоимость всех подписок за выбранный период с фильтрацией",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "summary"
+                ],
+                "summary": "Подсчет стоимости",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя для фильтрации",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса для фильтрации",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID сервиса из каталога для фильтрации",
+                        "name": "service_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег подписки для фильтрации (без учета регистра)",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (формат: MM-YYYY)",
+                        "name": "start_period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (формат: MM-YYYY)",
+                        "name": "end_period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "gross",
+                            "net"
+                        ],
+                        "type": "string",
+                        "description": "Вид суммы: gross (с налогом) или net (без налога)",
+                        "name": "amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Исключить подписки сервиса; параметр повторяется",
+                        "name": "exclude_service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Исключить подписки пользователя; параметр повторяется",
+                        "name": "exclude_user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить параметры расчета и подпись HMAC (требует SUMMARY_SIGNING_KEY)",
+                        "name": "signed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "metadata.project",
+                        "description": "Суммы по значениям метки (metadata.\u003ckey\u003e, поле groups) или по сервисам (service, поле breakdown); не сочетается с signed",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SummaryResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "active_cost": 1600,
+                                "cancelled_cost": 800,
+                                "total_cost": 2400
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "INVALID_PARAMETER",
+                                "detail": "start_period and end_period are required",
+                                "status": 400,
+                                "title": "Bad Request",
+                                "type": "urn:subscription-service:problem:invalid-parameter"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/summary/verify": {
+            "post": {
+                "description": "Проверяет, что итоги, полученные из /subscriptions/summary с signed=true, не изменены: тело - ответ summary как есть. Неверная подпись - не ошибка запроса: ответ 200 с valid=false и причиной",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "summary"
+                ],
+                "summary": "Проверить подпись итогов",
+                "parameters": [
+                    {
+                        "description": "Подписанные итоги",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SummaryResponse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SummaryVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/validate": {
+            "post": {
+                "description": "Выполняет все проверки POST /subscriptions, ничего не сохраняя. Некорректное тело (нет обязательных полей, неверные типы) возвращает 400; ошибки дат и предоплаты возвращаются с 200 и valid=false. Для корректного запроса возвращается нормализованное тело (end_date и monthly_cost рассчитаны по предоплате) и предупреждения: пересечение с другой подпиской пользователя на тот же сервис (overlap) и окончание раньше текущего месяца (expired)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Проверить подписку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ValidationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "description": "Возвращает информацию о подписке по её ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "billing_period": "monthly",
+                                "change_seq": 42,
+                                "cost": 400,
+                                "created_at": "2025-07-10 12:30:00",
+                                "end_date": "12-2025",
+                                "id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11",
+                                "is_draft": false,
+                                "monthly_cost": 400,
+                                "service_name": "Yandex Plus",
+                                "start_date": "07-2025",
+                                "status": "active",
+                                "updated_at": "2025-07-10 12:30:00",
+                                "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "INVALID_ID",
+                                "detail": "invalid subscription ID",
+                                "status": 400,
+                                "title": "Bad Request",
+                                "type": "urn:subscription-service:problem:invalid-id"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "SUBSCRIPTION_NOT_FOUND",
+                                "detail": "subscription not found",
+                                "status": 404,
+                                "title": "Not Found",
+                                "type": "urn:subscription-service:problem:subscription-not-found"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет информацию о подписке. Состояние меняется по переходам active -\u003e paused/cancelled и paused -\u003e active/cancelled; при отмене подписка заканчивается текущим месяцем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Обновить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для обновления подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "message": "subscription updated successfully"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "SUBSCRIPTION_NOT_FOUND",
+                                "detail": "subscription not found",
+                                "status": 404,
+                                "title": "Not Found",
+                                "type": "urn:subscription-service:problem:subscription-not-found"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет запись о подписке",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удалить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "message": "subscription deleted successfully"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        },
+                        "examples": {
+                            "application/json": {
+                                "code": "SUBSCRIPTION_NOT_FOUND",
+                                "detail": "subscription not found",
+                                "status": 404,
+                                "title": "Not Found",
+                                "type": "urn:subscription-service:problem:subscription-not-found"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/activate": {
+            "post": {
+                "description": "Переводит черновик подписки в активное состояние, после чего она учитывается в подсчете стоимости",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Активировать подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "description": "Отменяет подписку с сохранением причины. По умолчанию подписка заканчивается текущим месяцем или месяцем effective_period; с at_period_end - в конце оплаченного срока (end_date подписки, а если его нет - текущим месяцем)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Отменить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры отмены",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.CancelSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "description": "Возвращает изменения подписки из журнала аудита, начиная с последних: действие, образы до и после, автор и ID запроса (X-Request-ID). История удаленной подписки сохраняется. Следующую страницу возвращает before_id, равный id последней записи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "История подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Записи старше указанной",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум записей (по умолчанию 100, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/prices": {
+            "get": {
+                "description": "Возвращает стоимость подписки по месяцам ее срока: с какого (from) по какой (to) месяц действовала каждая стоимость. Последняя запись действует до конца подписки, у бессрочной подписки to не задан. Стоимость, не менявшаяся с создания, возвращается одной записью",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "История стоимости подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SubscriptionPrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/reminder-settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Настройки напоминаний подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReminderSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Создает или заменяет настройки напоминаний о продлении подписки: за сколько дней (1-365) до окончания периода напоминать и единственный канал напоминаний (log, webhook, email или inbox). Незаданное значение - RENEWAL_REMINDER_DAYS или каналы RENEWAL_REMINDER_CHANNELS. Канал должен быть доступен: email - только с EMAIL_DRIVER",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Сохранить настройки напоминаний подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Настройки напоминаний",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SaveReminderSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReminderSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удалить настройки напоминаний подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет владельца подписки, например при переходе между членами семьи или командами. Сервис не аутентифицирует пользователей, поэтому согласие обеих сторон не проверяется и передача доступна только с административным токеном. Передача записывается в историю (subscription_transfers) и в журнал изменений операцией transfer. Отмененные и истекшие подписки не передаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Передать подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый владелец",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TransferSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Возвращает теги по названию с числом подписок у каждого",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Список тегов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает тег организации заранее; теги из поля tags подписок создаются автоматически. Название хранится в нижнем регистре",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Создать тег",
+                "parameters": [
+                    {
+                        "description": "Название тега",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "description": "Переименовывает тег у всех подписок, на которых он стоит",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Переименовать тег",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое название тега",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет тег и снимает его со всех подписок",
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Удалить тег",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "description": "Возвращает последнюю версию каждого шаблона; шаблоны без версий в базе возвращаются встроенными",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Список шаблонов писем",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.EmailTemplate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}": {
+            "get": {
+                "description": "Возвращает текущую или указанную версию шаблона",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Получить шаблон письма",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя шаблона",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии (по умолчанию текущая)",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новую версию шаблона. Тема - text/template, тело - html/template. Требует ADMIN_TOKEN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Сохранить шаблон письма",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя шаблона",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тема и тело шаблона",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SaveTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Рендерит текущую версию шаблона или переданные subject/body с примерными или переданными данными. Требует ADMIN_TOKEN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Предпросмотр шаблона письма",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя шаблона",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Шаблон и данные для предпросмотра",
+                        "name": "preview",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.PreviewTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RenderedTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}/versions": {
+            "get": {
+                "description": "Возвращает все сохраненные версии шаблона, начиная с последней",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Версии шаблона письма",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя шаблона",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.EmailTemplate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenant/subscriptions/overview": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Для администраторов: траты каждого пользователя организации в текущем месяце, сервисы, на которые подписаны несколько пользователей, и сервисы, которые оплачивают по отдельности 3 и более пользователей (кандидаты на общую подписку). Названия сервисов сравниваются без учета регистра и лишних пробелов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenant"
+                ],
+                "summary": "Обзор подписок организации",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user"
+                        ],
+                        "type": "string",
+                        "default": "user",
+                        "description": "Группировка",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantOverview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/anomalies": {
+            "get": {
+                "description": "Возвращает месяцы, в которых траты пользователя превысили среднее за предыдущие месяцы больше настроенного порога. После месяцев без трат месяц с тратами от ANOMALY_MIN_SPEND тоже аномален: trailing_average и deviation_percent у него 0",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Аномалии трат пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько последних месяцев проверять, включая текущий (по умолчанию 6)",
+                        "name": "months",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SpendAnomaly"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/data-quality": {
+            "get": {
+                "description": "Проверяет подписки пользователя и возвращает долю подписок без замечаний (score, 0-100) и замечания с действиями: действующая подписка без end_date (missing_end_date), черновик, начало которого уже прошло (stale_draft), название сервиса, которое другие пользователи чаще пишут иначе (nonstandard_service_name, с предлагаемым написанием)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Качество данных пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DataQualityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/invoices": {
+            "get": {
+                "description": "Возвращает счета пользователя без строк, начиная с последнего месяца",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Счета пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Invoice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает счет со строкой по каждой подписке, активной в месяце: стоимость месяца (для годовой предоплаты - ее двенадцатая часть), скидки и разложение по налогу. Повторный счет за тот же месяц не создается",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Выставить счет за месяц",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц счета (MM-YYYY)",
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notification-preferences": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Создает или заменяет настройки уведомлений: адрес писем и виды писем - напоминания о продлении и письма об отмене подписки. Не указанные виды включены. Пользователь без настроек писем не получает",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сохранить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SaveNotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications": {
+            "get": {
+                "description": "Возвращает уведомления центра уведомлений, начиная с последних: скорое окончание или продление подписки (expiring_soon), повышение цены (price_increase) и аномальные траты (spend_anomaly). unread - число непрочитанных уведомлений пользователя независимо от фильтра",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Уведомления пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только непрочитанные",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, не больше 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.InboxPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество уведомлений под фильтром"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications/read-all": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Отметить все уведомления прочитанными",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MarkAllReadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications/{notification_id}/read": {
+            "post": {
+                "description": "Повторная отметка не меняет read_at",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Отметить уведомление прочитанным",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID уведомления",
+                        "name": "notification_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.InboxNotification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/services": {
+            "get": {
+                "description": "Возвращает сервисы с подписками пользователя, действующими в текущем месяце (без черновиков): число подписок и их суммарную стоимость в месяц. Приостановленные подписки учитываются в числе, но не в стоимости. Сортировка по убыванию стоимости",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сервисы пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.UserService"
+                            }
+                        },
+                        "examples": {
+                            "application/json": [
+                                {
+                                    "monthly_cost": 800,
+                                    "service_name": "Yandex Plus",
+                                    "subscriptions": 2
+                                },
+                                {
+                                    "monthly_cost": 300,
+                                    "service_name": "Kinopoisk",
+                                    "subscriptions": 1
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/spend/sparkline": {
+            "get": {
+                "description": "Возвращает компактный массив помесячных трат за последние месяцы (включая текущий) для отрисовки sparkline. Ответ кэшируется",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Мини-график трат пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество месяцев (по умолчанию 12)",
+                        "name": "months",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Sparkline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/subscriptions:sync": {
+            "put": {
+                "description": "Принимает полный желаемый набор подписок пользователя (до 1000) и сам вычисляет изменения: элемент с id изменяет эту подписку, элемент без id изменяет подписку того же сервиса с тем же start_date или создает новую, подписки пользователя вне набора удаляются. Каждый элемент проверяется как тело PUT /subscriptions/{id}, user_id можно не указывать. Изменения применяются в одной транзакции и возвращаются в ответе; с dry_run=true только вычисляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Синхронизировать подписки пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только вычислить изменения",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Желаемый набор подписок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SyncSubscriptionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Личные токены API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.APIToken"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выпускает токен для интеграций (таблицы, дашборды) с правами scopes: read:subscriptions - чтение подписок, read:summary - итоги и динамика трат, write:subscriptions - изменение подписок. Токен передается в заголовке \"Authorization: Bearer \u003ctoken\u003e\" и показывается только в этом ответе. Запрос с личным токеном новые токены не выпускает",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Выпустить личный токен API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Название, права и срок действия токена",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateAPITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.IssuedAPIToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tokens/{token_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Отозвать личный токен API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID токена",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code - стабильный машиночитаемый код ошибки",
+                    "type": "string",
+                    "example": "SUBSCRIPTION_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "subscription not found"
+                },
+                "errors": {
+                    "description": "Errors - поля и параметры запроса с некорректными значениями",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.FieldError"
+                    }
+                },
+                "request_id": {
+                    "description": "RequestID - идентификатор запроса для поиска в журналах",
+                    "type": "string",
+                    "example": "5f0c6c1e4b7a4d0e9a3c2b1d8e7f6a5b"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "description": "Type - URI типа проблемы, однозначно соответствует Code",
+                    "type": "string",
+                    "example": "urn:subscription-service:problem:subscription-not-found"
+                }
+            }
+        },
+        "handler.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "user_id"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "invalid UUID length: 10"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "not-a-uuid"
+                }
+            }
         },
         "handler.SuccessResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "message": {
+                    "type": "string",
+                    "example": "subscription updated successfully"
+                }
+            }
+        },
+        "model.APIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-08 12:30:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "2b8e1c4a-5d6f-4a7b-9c8d-0e1f2a3b4c5d"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Google Sheets"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read:subscriptions",
+                        "read:summary"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.ActivityBucket": {
+            "type": "object",
+            "properties": {
+                "cancellations": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "creations": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "deletions": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "price_changes": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2025-07-10T00:00:00Z"
+                }
+            }
+        },
+        "model.AnalyzeRequest": {
+            "type": "object",
+            "properties": {
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions"
+                    ]
+                }
+            }
+        },
+        "model.AnalyzedTable": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 180
+                },
+                "table": {
+                    "type": "string",
+                    "example": "subscriptions"
+                }
+            }
+        },
+        "model.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "user:60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:30:00Z"
+                },
+                "entity_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "entity_type": {
+                    "type": "string",
+                    "example": "subscription"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1042
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "0b6c1f0e-3c1d-4f5a-9a57-3d8f2c4e7b21"
+                },
+                "user_id": {
+                    "description": "UserID - владелец подписки после изменения (для удаления - до него)",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.Backup": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-08-09T03:00:00Z"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:42Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4f1c2a9e-7b3d-4e8a-9c6f-2d5b8e1a7c30"
+                },
+                "key": {
+                    "description": "Key - ключ зашифрованной копии в хранилище файлов",
+                    "type": "string",
+                    "example": "backups/2025/07/10/4f1c2a9e-7b3d-4e8a-9c6f-2d5b8e1a7c30.ndjson.gz.enc"
+                },
+                "sha256": {
+                    "description": "SHA256 - контрольная сумма зашифрованного файла для проверки после скачивания",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "snapshot_at": {
+                    "description": "SnapshotAt и WALPosition - момент и позиция WAL, на которые согласованы все таблицы копии",
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00.123Z"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "completed",
+                        "failed",
+                        "expired"
+                    ],
+                    "example": "completed"
+                },
+                "tables": {
+                    "description": "Tables - число строк каждой таблицы в копии",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "wal_lsn": {
+                    "type": "string",
+                    "example": "0/16B3748"
+                }
+            }
+        },
+        "model.BulkCreateRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.CreateSubscriptionRequest"
+                    }
+                }
+            }
+        },
+        "model.BulkDeleteRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.BulkItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "end date cannot be before start date"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "unchanged",
+                        "deleted",
+                        "failed",
+                        "skipped"
+                    ],
+                    "example": "created"
+                },
+                "subscription": {
+                    "description": "Subscription - созданная подписка",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BulkItemResult"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "example": "atomic"
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.BulkUpdateItem": {
+            "type": "object",
+            "required": [
+                "id",
+                "service_name",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "yearly"
+                },
+                "cost": {
+                    "description": "Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "description": {
+                    "description": "Description - заметки о подписке, до 2000 символов; без поля описание удаляется",
+                    "type": "string",
+                    "example": "Семейный тариф на четверых, продлевать вручную"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "is_free": {
+                    "description": "IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются",
+                    "type": "boolean",
+                    "example": false
+                },
+                "metadata": {
+                    "description": "Metadata - метки подписки, заменяют прежние целиком; без поля метки удаляются",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "project": "apollo"
+                    }
+                },
+                "monthly_cost": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 400
+                },
+                "prepaid_amount": {
+                    "description": "PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "price_effective_from": {
+                    "description": "PriceEffectiveFrom - месяц, с которого действует новая стоимость; прошлые месяцы\nсчитаются по прежней. По умолчанию текущий месяц, у подписок, которые еще не\nначались или уже закончились, - месяц начала (стоимость заменяется целиком)",
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "service_id": {
+                    "description": "ServiceID - сервис из каталога; без поля связь с каталогом удаляется",
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "status": {
+                    "description": "Status - новое состояние подписки; если не задано, состояние не меняется",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled"
+                    ],
+                    "example": "paused"
+                },
+                "tags": {
+                    "description": "Tags - теги подписки, заменяют прежние целиком; без поля теги снимаются",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.BulkUpdateRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.BulkUpdateItem"
+                    }
+                }
+            }
+        },
+        "model.CancelSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "at_period_end": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "effective_period": {
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "too expensive"
+                }
+            }
+        },
+        "model.CatalogService": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "default_monthly_cost": {
+                    "description": "DefaultMonthlyCost - обычная месячная стоимость сервиса, справочно",
+                    "type": "integer",
+                    "example": 400
+                },
+                "icon_url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/icons/yandex-plus.png"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                }
+            }
+        },
+        "model.CatalogServiceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "music"
+                },
+                "default_monthly_cost": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 400
+                },
+                "icon_url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://cdn.example.com/icons/yandex-plus.png"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "model.ChangesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SubscriptionChange"
+                    }
+                },
+                "next_since_seq": {
+                    "type": "integer",
+                    "example": 1024
+                }
+            }
+        },
+        "model.ConsolidationOpportunity": {
+            "type": "object",
+            "properties": {
+                "average_cost": {
+                    "description": "AverageCost - средняя стоимость подписки пользователя в месяц",
+                    "type": "integer",
+                    "example": 400
+                },
+                "monthly_cost": {
+                    "description": "MonthlyCost - сколько организация платит за отдельные подписки в месяц",
+                    "type": "integer",
+                    "example": 1600
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "model.CreateAPITokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1,
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Google Sheets"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "read:subscriptions",
+                            "read:summary",
+                            "write:subscriptions"
+                        ]
+                    },
+                    "example": [
+                        "read:subscriptions",
+                        "read:summary"
+                    ]
+                }
+            }
+        },
+        "model.CreateSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "service_name",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "yearly"
+                },
+                "cost": {
+                    "description": "Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "description": {
+                    "description": "Description - заметки о подписке, до 2000 символов",
+                    "type": "string",
+                    "example": "Семейный тариф на четверых, продлевать вручную"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "is_draft": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "is_free": {
+                    "description": "IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются",
+                    "type": "boolean",
+                    "example": false
+                },
+                "metadata": {
+                    "description": "Metadata - метки подписки: до 20 ключей a-z, 0-9, _ и -",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "project": "apollo"
+                    }
+                },
+                "monthly_cost": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 400
+                },
+                "prepaid_amount": {
+                    "description": "PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "service_id": {
+                    "description": "ServiceID - сервис из каталога; должен существовать",
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "tags": {
+                    "description": "Tags - до 20 тегов; хранятся в нижнем регистре, новые теги создаются автоматически",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.DailyUsage": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-07-10"
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EndpointUsage"
+                    }
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1800
+                }
+            }
+        },
+        "model.DataQualityItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "set end_date if the subscription is not renewed indefinitely"
+                },
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "missing_end_date",
+                        "stale_draft",
+                        "nonstandard_service_name"
+                    ],
+                    "example": "missing_end_date"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "suggestion": {
+                    "description": "Suggestion - предлагаемое значение (для nonstandard_service_name - распространенное написание)",
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "model.DataQualityReport": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DataQualityItem"
+                    }
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 75
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "error": {
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "description": "Status - ok или unavailable",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "model.Discount": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b7e3c2a-5d1f-4e8a-9c6b-2f4d8a1e7b90"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "percent"
+                },
+                "promo_code": {
+                    "type": "string",
+                    "example": "SUMMER25"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "value": {
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "model.DiscountRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "start_date",
+                "value"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "percent"
+                },
+                "promo_code": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "SUMMER25"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "value": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 20
+                }
+            }
+        },
+        "model.EmailTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "\u003cp\u003eПодписка {{.ServiceName}} продлится {{.RenewalDate}}\u003c/p\u003e"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "renewal_reminder"
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "database",
+                        "default"
+                    ],
+                    "example": "database"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Скоро продление {{.ServiceName}}"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.EndpointUsage": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "type": "string",
+                    "example": "GET /api/v1/subscriptions"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1520
+                }
+            }
+        },
+        "model.EventSchemaVersions": {
+            "type": "object",
+            "properties": {
+                "latest": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "type": "string",
+                    "example": "subscription.renewal_reminder"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1
+                    ]
+                }
+            }
+        },
+        "model.GroupMapping": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "/acme/billing-admins"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "admin"
+                    ],
+                    "example": "admin"
+                },
+                "tenant": {
+                    "description": "Tenant - организация участников группы; пустое значение - роль admin во всех организациях",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                }
+            }
+        },
+        "model.Histogram": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HistogramBucket"
+                    }
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "sum": {
+                    "type": "integer",
+                    "example": 48000
+                }
+            }
+        },
+        "model.HistogramBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "le": {
+                    "type": "string",
+                    "example": "100"
+                }
+            }
+        },
+        "model.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid start date format, expected MM-YYYY"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.ImportResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 98
+                }
+            }
+        },
+        "model.InboundEvent": {
+            "type": "object",
+            "required": [
+                "data",
+                "id",
+                "source",
+                "type"
+            ],
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "evt_1Q2w3E4r"
+                },
+                "source": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "billing"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "subscription.created",
+                        "subscription.updated",
+                        "subscription.cancelled",
+                        "subscription.deleted"
+                    ],
+                    "example": "subscription.cancelled"
+                }
+            }
+        },
+        "model.InboundEventResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "evt_1Q2w3E4r"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "processed",
+                        "duplicate"
+                    ],
+                    "example": "processed"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "subscription.cancelled"
+                }
+            }
+        },
+        "model.InboundRecord": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "Duplicates - сколько повторов события отброшено",
+                    "type": "integer",
+                    "example": 2
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "evt_1Q2w3E4r"
+                },
+                "last_duplicate_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:45:00"
+                },
+                "processed_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:01"
+                },
+                "received_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "processing",
+                        "processed"
+                    ],
+                    "example": "processed"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "subscription.cancelled"
+                }
+            }
+        },
+        "model.InboxNotification": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0c5b1d4e-6a3f-4f0e-8b1a-7e2d9c3f4a51"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "expiring_soon",
+                        "price_increase",
+                        "spend_anomaly"
+                    ],
+                    "example": "price_increase"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Стоимость подписки Yandex Plus выросла с 400 до 500 ₽ в месяц"
+                },
+                "read": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "read_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:45:00"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.InboxPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InboxNotification"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "unread": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.IndexStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 4194304
+                },
+                "index": {
+                    "type": "string",
+                    "example": "idx_subscriptions_tenant_user"
+                },
+                "scans": {
+                    "description": "Scans - число просмотров индекса с последнего сброса статистики",
+                    "type": "integer",
+                    "example": 98000
+                },
+                "table": {
+                    "type": "string",
+                    "example": "subscriptions"
+                },
+                "tuples_fetched": {
+                    "type": "integer",
+                    "example": 440000
+                },
+                "tuples_read": {
+                    "type": "integer",
+                    "example": 450000
+                },
+                "unique": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "unused": {
+                    "description": "Unused - индекс не просматривался ни разу; уникальные индексы нужны и без просмотров",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "model.Invoice": {
+            "type": "object",
+            "properties": {
+                "base_total": {
+                    "type": "integer",
+                    "example": 1400
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-01 09:00:00"
+                },
+                "discount_total": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "gross_total": {
+                    "type": "integer",
+                    "example": 1320
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3d9a1f6e-2c4b-4e7a-8f1d-5b6c7a8e9f01"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InvoiceLine"
+                    }
+                },
+                "net_total": {
+                    "type": "integer",
+                    "example": 1100
+                },
+                "period": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "tax_rate": {
+                    "type": "string",
+                    "example": "20.00"
+                },
+                "tax_total": {
+                    "type": "integer",
+                    "example": 220
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.InvoiceLine": {
+            "type": "object",
+            "properties": {
+                "base_amount": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "discount_amount": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "gross_amount": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "net_amount": {
+                    "type": "integer",
+                    "example": 267
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "tax_amount": {
+                    "type": "integer",
+                    "example": 53
+                }
+            }
+        },
+        "model.IssuedAPIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-08 12:30:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "2b8e1c4a-5d6f-4a7b-9c8d-0e1f2a3b4c5d"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Google Sheets"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read:subscriptions",
+                        "read:summary"
+                    ]
+                },
+                "token": {
+                    "type": "string",
+                    "example": "sst_q3Jx0k9cX2mVbW7n1LrT8yZ4aPdGfHsE6uIoKlMnBvC"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.JobStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "failures": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_duration_ms": {
+                    "type": "integer",
+                    "example": 840
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00Z"
+                },
+                "last_success_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "anomaly_detection"
+                },
+                "next_run_at": {
+                    "type": "string",
+                    "example": "2025-07-11T09:00:00Z"
+                },
+                "running": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "runs": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 9 * * *"
+                },
+                "skipped": {
+                    "description": "Skipped - запуски, пропущенные, потому что задачу выполняла другая реплика",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "model.MarkAllReadResponse": {
+            "type": "object",
+            "properties": {
+                "marked": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "cancellations": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "renewal_reminders": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.PodMetadata": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "subscription-service-7d9f8b6c4-x2lkq"
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "node": {
+                    "type": "string",
+                    "example": "worker-3"
+                }
+            }
+        },
+        "model.PoolSettings": {
+            "type": "object",
+            "properties": {
+                "conn_max_idle_time": {
+                    "type": "string",
+                    "example": "0s"
+                },
+                "conn_max_lifetime": {
+                    "type": "string",
+                    "example": "5m0s"
+                },
+                "max_idle_conns": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "max_open_conns": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "statement_timeout": {
+                    "type": "string",
+                    "example": "30s"
+                }
+            }
+        },
+        "model.PoolStatus": {
+            "type": "object",
+            "properties": {
+                "healthy": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "idle": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "in_use": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "max_idle_closed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "max_lifetime_closed": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "open_connections": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "ping_error": {
+                    "type": "string"
+                },
+                "ping_ms": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "settings": {
+                    "$ref": "#/definitions/model.PoolSettings"
+                },
+                "wait_count": {
+                    "type": "integer",
+                    "example": 130
+                },
+                "wait_duration": {
+                    "type": "string",
+                    "example": "1.5s"
+                }
+            }
+        },
+        "model.PreviewTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProvisionAPIToken": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes",
+                "user_id"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1,
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Google Sheets"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "read:subscriptions",
+                            "read:summary",
+                            "write:subscriptions"
+                        ]
+                    },
+                    "example": [
+                        "read:subscriptions",
+                        "read:summary"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.ProvisionTenantRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "api_tokens": {
+                    "description": "APITokens - личные токены API; токен выпускается, если у пользователя нет токена с таким названием",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProvisionAPIToken"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme Inc"
+                },
+                "services": {
+                    "description": "Services - сервисы каталога организации; существующие приводятся к описанию из запроса",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CatalogServiceRequest"
+                    }
+                },
+                "settings": {
+                    "description": "Settings - налоговые настройки организации целиком: незаданное поле сбрасывается\nк значению из конфигурации. Без блока настройки не меняются",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TenantSettings"
+                        }
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "personal"
+                    ]
+                }
+            }
+        },
+        "model.ProvisionTenantResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created - организация заведена этим запросом",
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_services": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Yandex Plus"
+                    ]
+                },
+                "created_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "personal"
+                    ]
+                },
+                "issued_tokens": {
+                    "description": "IssuedTokens - токены, выпущенные этим запросом; секреты показываются только здесь",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IssuedAPIToken"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme Inc"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updated_services": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_settings": {
+                    "description": "UpdatedSettings - запрос изменил налоговые настройки организации",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.QueryStats": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "duration_ms": {
+                    "$ref": "#/definitions/model.Histogram"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "subscriptions.list"
+                },
+                "rows": {
+                    "$ref": "#/definitions/model.Histogram"
+                }
+            }
+        },
+        "model.RateLimitUsage": {
+            "type": "object",
+            "properties": {
+                "limit_per_minute": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 558
+                },
+                "reset_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:31:00Z"
+                },
+                "used": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "model.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DependencyStatus"
+                    }
+                },
+                "pod": {
+                    "description": "Pod - экземпляр, ответивший на пробу; не выводится вне Kubernetes",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PodMetadata"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "model.RejectedRequest": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:30:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1042
+                },
+                "message": {
+                    "type": "string",
+                    "example": "price must be positive"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "validation"
+                },
+                "route": {
+                    "description": "Route - шаблон маршрута, а не фактический путь",
+                    "type": "string",
+                    "example": "/api/v1/subscriptions"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.ReminderSettings": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "Channel - единственный канал напоминаний подписки",
+                    "type": "string",
+                    "enum": [
+                        "log",
+                        "webhook",
+                        "email",
+                        "inbox"
+                    ],
+                    "example": "email"
+                },
+                "days": {
+                    "description": "Days - за сколько дней до окончания периода отправляется напоминание",
+                    "type": "integer",
+                    "example": 30
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                }
+            }
+        },
+        "model.RenderedTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "\u003cp\u003eПодписка Yandex Plus продлится 01-08-2025\u003c/p\u003e"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Скоро продление Yandex Plus"
+                }
+            }
+        },
+        "model.RetentionCohort": {
+            "type": "object",
+            "properties": {
+                "cohort": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    },
+                    "example": [
+                        1,
+                        0.775,
+                        0.675
+                    ]
+                },
+                "retained": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        40,
+                        31,
+                        27
+                    ]
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "model.RetentionReport": {
+            "type": "object",
+            "properties": {
+                "cohorts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RetentionCohort"
+                    }
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
+        "model.RouteInfo": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "description": "Auth - требование аутентификации: none, bearer (токен провайдера или ADMIN_TOKEN) или admin_token",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "bearer",
+                        "admin_token"
+                    ],
+                    "example": "bearer"
+                },
+                "handler": {
+                    "type": "string",
+                    "example": "handler.(*SubscriptionHandler).GetSubscription-fm"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "GET"
+                },
+                "middlewares": {
+                    "description": "Middlewares - middleware маршрута в порядке выполнения",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "handler.Authenticate"
+                    ]
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/subscriptions/:id"
+                }
+            }
+        },
+        "model.SaveGroupMappingRequest": {
+            "type": "object",
+            "required": [
+                "group",
+                "role"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "/acme/billing-admins"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "admin"
+                    ],
+                    "example": "admin"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "model.SaveNotificationPreferencesRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "cancellations": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": "user@example.com"
+                },
+                "renewal_reminders": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.SaveReminderSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "log",
+                        "webhook",
+                        "email",
+                        "inbox"
+                    ],
+                    "example": "email"
+                },
+                "days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1,
+                    "example": 30
+                }
+            }
+        },
+        "model.SaveTemplateRequest": {
+            "type": "object",
+            "required": [
+                "body",
+                "subject"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "\u003cp\u003eПодписка {{.ServiceName}} продлится {{.RenewalDate}}\u003c/p\u003e"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Скоро продление {{.ServiceName}}"
+                }
+            }
+        },
+        "model.SchemaDrift": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:00:00Z"
+                },
+                "drifted": {
+                    "description": "Drifted - в базе нет хотя бы одного ожидаемого объекта",
+                    "type": "boolean",
+                    "example": true
+                },
+                "missing_columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions.tenant_id"
+                    ]
+                },
+                "missing_indexes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "idx_subscriptions_tenant_user"
+                    ]
+                },
+                "missing_tables": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rejected_requests"
+                    ]
+                }
+            }
+        },
+        "model.SharedService": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "integer",
+                    "example": 1600
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "model.Sparkline": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "08-2024"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        800,
+                        800,
+                        1200,
+                        1200,
+                        1200,
+                        1600,
+                        1600,
+                        1600,
+                        1600,
+                        2000,
+                        2000,
+                        2400
+                    ]
+                }
+            }
+        },
+        "model.SpendAnomaly": {
+            "type": "object",
+            "properties": {
+                "deviation_percent": {
+                    "type": "integer",
+                    "example": 75
+                },
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "spend": {
+                    "type": "integer",
+                    "example": 2100
+                },
+                "threshold_percent": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "trailing_average": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.Subscription": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod - период оплаты; MonthlyCost для недельной и годовой оплаты - Cost, переведенная в месяц",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "yearly"
+                },
+                "cancel_reason": {
+                    "description": "CancelReason и CancelledAt заполняются при отмене через POST /subscriptions/{id}/cancel",
+                    "type": "string",
+                    "example": "too expensive"
+                },
+                "cancelled_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "change_seq": {
+                    "description": "ChangeSeq растет с каждым изменением подписки; порядок изменений разных подписок не задает",
+                    "type": "integer",
+                    "example": 42
+                },
+                "cost": {
+                    "description": "Cost - стоимость за период оплаты; хранится только для недельной и годовой оплаты,\nв ответе API у помесячных подписок равна monthly_cost",
+                    "type": "integer",
+                    "example": 4800
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "description": {
+                    "description": "Description - заметки о подписке в свободной форме",
+                    "type": "string",
+                    "example": "Семейный тариф на четверых, продлевать вручную"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "is_draft": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "metadata": {
+                    "description": "Metadata - метки организации, по которым группируются итоги (group_by=metadata.\u003ckey\u003e)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "project": "apollo"
+                    }
+                },
+                "monthly_cost": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "prepaid_amount": {
+                    "description": "PrepaidAmount - сумма годовой предоплаты, MonthlyCost для таких подписок - ее двенадцатая часть",
+                    "type": "integer",
+                    "example": 4800
+                },
+                "service_id": {
+                    "description": "ServiceID - сервис из каталога (/services), к которому относится подписка",
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "status": {
+                    "description": "Status - состояние подписки; expired не хранится и вычисляется для активных подписок с прошедшим end_date",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled",
+                        "expired"
+                    ],
+                    "example": "active"
+                },
+                "tags": {
+                    "description": "Tags - теги подписки для группировки трат (work, entertainment)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.SubscriptionChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string",
+                    "example": "2025-07-10T09:30:00Z"
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "transfer"
+                    ],
+                    "example": "update"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "seq": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                }
+            }
+        },
+        "model.SubscriptionPrice": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod и Cost - период оплаты и стоимость за него, как у подписки",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "cost": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "from": {
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "monthly_cost": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "to": {
+                    "description": "To - последний месяц действия стоимости; у последней записи - конец подписки,\nу бессрочной подписки не задан",
+                    "type": "string",
+                    "example": "08-2025"
+                }
+            }
+        },
+        "model.SummaryAdjustment": {
+            "type": "object",
+            "properties": {
+                "active_cost": {
+                    "type": "integer",
+                    "example": -160
+                },
+                "cancelled_cost": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "description": {
+                    "type": "string",
+                    "example": "корпоративная скидка 10%"
+                },
+                "modifier": {
+                    "type": "string",
+                    "example": "company_discount"
+                }
+            }
+        },
+        "model.SummaryBreakdown": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "description": "Months - число оплачиваемых месяцев подписок сервиса: две подписки за три месяца - 6",
+                    "type": "integer",
+                    "example": 6
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2400
+                }
+            }
+        },
+        "model.SummaryCalculation": {
+            "type": "object",
+            "properties": {
+                "algorithm_version": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "calculated_at": {
+                    "type": "string",
+                    "example": "2025-07-10T12:30:00Z"
+                },
+                "filters": {
+                    "description": "Filters - фильтр расчета; для пользователя с токеном OIDC user_id - его собственный",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SummaryCalculationFilters"
+                        }
+                    ]
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "model.SummaryCalculationFilters": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "enum": [
+                        "gross",
+                        "net"
+                    ],
+                    "example": "gross"
+                },
+                "end_period": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "exclude_service_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Zoom"
+                    ]
+                },
+                "exclude_user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "service_id": {
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_period": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "work"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.SummaryGroup": {
+            "type": "object",
+            "properties": {
+                "active_cost": {
+                    "type": "integer",
+                    "example": 800
+                },
+                "cancelled_cost": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "key": {
+                    "type": "string",
+                    "example": "apollo"
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "model.SummaryResponse": {
+            "type": "object",
+            "properties": {
+                "active_cost": {
+                    "description": "ActiveCost - часть суммы по подпискам, которые активны сейчас",
+                    "type": "integer",
+                    "example": 1600
+                },
+                "adjustments": {
+                    "description": "Adjustments - корректировки модификаторов развертывания (SUMMARY_MODIFIERS), уже\nучтенные в суммах выше",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SummaryAdjustment"
+                    }
+                },
+                "amount_type": {
+                    "type": "string",
+                    "enum": [
+                        "gross",
+                        "net"
+                    ],
+                    "example": "gross"
+                },
+                "breakdown": {
+                    "description": "Breakdown возвращается с group_by=service вместо Groups: суммы по сервисам по убыванию",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SummaryBreakdown"
+                    }
+                },
+                "calculation": {
+                    "description": "Calculation и Signature возвращаются с signed=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SummaryCalculation"
+                        }
+                    ]
+                },
+                "cancelled_cost": {
+                    "description": "CancelledCost - часть суммы по подпискам, которые уже закончились",
+                    "type": "integer",
+                    "example": 800
+                },
+                "group_by": {
+                    "description": "GroupBy и Groups возвращаются с group_by: суммы по значениям метки. Корректировки\nмодификаторов в группы не входят, а округленные суммы групп могут отличаться\nот итогов на копейки округления",
+                    "type": "string",
+                    "example": "metadata.project"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SummaryGroup"
+                    }
+                },
+                "signature": {
+                    "$ref": "#/definitions/model.SummarySignature"
+                },
+                "tax_amount": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "tax_rate": {
+                    "type": "string",
+                    "example": "20.00"
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 2400
+                }
+            }
+        },
+        "model.SummarySignature": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string",
+                    "example": "HMAC-SHA256"
+                },
+                "key_id": {
+                    "type": "string",
+                    "example": "v1"
+                },
+                "value": {
+                    "description": "Value - подпись SigningPayload в шестнадцатеричном виде",
+                    "type": "string",
+                    "example": "5d41402abc4b2a76b9719d911017c592..."
                 }
             }
         },
-        "model.CreateSubscriptionRequest": {
+        "model.SummaryVerification": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "signature mismatch"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.SyncResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Subscription"
+                    }
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Subscription"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "unchanged": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SyncUpdate"
+                    }
+                }
+            }
+        },
+        "model.SyncSubscriptionItem": {
             "type": "object",
             "required": [
-                "monthly_cost",
                 "service_name",
                 "start_date",
                 "user_id"
             ],
             "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "yearly"
+                },
+                "cost": {
+                    "description": "Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "description": {
+                    "description": "Description - заметки о подписке, до 2000 символов; без поля описание удаляется",
+                    "type": "string",
+                    "example": "Семейный тариф на четверых, продлевать вручную"
+                },
                 "end_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "description": "ID - существующая подписка пользователя",
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                },
+                "is_free": {
+                    "description": "IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются",
+                    "type": "boolean",
+                    "example": false
+                },
+                "metadata": {
+                    "description": "Metadata - метки подписки, заменяют прежние целиком; без поля метки удаляются",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "project": "apollo"
+                    }
                 },
                 "monthly_cost": {
                     "type": "integer",
-                    "minimum": 1
+                    "minimum": 1,
+                    "example": 400
+                },
+                "prepaid_amount": {
+                    "description": "PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "price_effective_from": {
+                    "description": "PriceEffectiveFrom - месяц, с которого действует новая стоимость; прошлые месяцы\nсчитаются по прежней. По умолчанию текущий месяц, у подписок, которые еще не\nначались или уже закончились, - месяц начала (стоимость заменяется целиком)",
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "service_id": {
+                    "description": "ServiceID - сервис из каталога; без поля связь с каталогом удаляется",
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
                 },
                 "service_name": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Yandex Plus"
                 },
                 "start_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "status": {
+                    "description": "Status - новое состояние подписки; если не задано, состояние не меняется",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled"
+                    ],
+                    "example": "paused"
+                },
+                "tags": {
+                    "description": "Tags - теги подписки, заменяют прежние целиком; без поля теги снимаются",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
                 },
                 "user_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
         },
-        "model.Subscription": {
+        "model.SyncSubscriptionsRequest": {
             "type": "object",
+            "required": [
+                "subscriptions"
+            ],
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "subscriptions": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "$ref": "#/definitions/model.SyncSubscriptionItem"
+                    }
+                }
+            }
+        },
+        "model.SyncUpdate": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/model.Subscription"
                 },
-                "end_date": {
-                    "type": "string"
+                "before": {
+                    "$ref": "#/definitions/model.Subscription"
                 },
                 "id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                }
+            }
+        },
+        "model.TableStats": {
+            "type": "object",
+            "properties": {
+                "dead_ratio": {
+                    "description": "DeadRatio - доля мертвых строк, оценка раздувания таблицы",
+                    "type": "number",
+                    "example": 0.065
+                },
+                "dead_rows": {
+                    "type": "integer",
+                    "example": 8400
+                },
+                "index_bytes": {
+                    "type": "integer",
+                    "example": 20971520
+                },
+                "index_scans": {
+                    "type": "integer",
+                    "example": 98000
+                },
+                "last_analyze": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00Z"
+                },
+                "last_autoanalyze": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00Z"
+                },
+                "last_autovacuum": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00Z"
+                },
+                "last_vacuum": {
+                    "type": "string",
+                    "example": "2025-07-10T03:00:00Z"
+                },
+                "live_rows": {
+                    "type": "integer",
+                    "example": 120000
+                },
+                "modified_since_analyze": {
+                    "type": "integer",
+                    "example": 3100
+                },
+                "seq_scans": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "table": {
+                    "type": "string",
+                    "example": "subscriptions"
+                },
+                "total_bytes": {
+                    "description": "TotalBytes - размер таблицы с индексами и TOAST, IndexBytes - только индексов",
+                    "type": "integer",
+                    "example": 52428800
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-10 12:30:00"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9a4e2c71-3b5d-4f8e-a1c6-7d0b2e9f4a53"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "work"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "model.TagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "work"
+                }
+            }
+        },
+        "model.TeardownRequest": {
+            "type": "object",
+            "required": [
+                "confirm"
+            ],
+            "properties": {
+                "confirm": {
+                    "description": "Confirm - подтверждение: должен совпадать с идентификатором организации",
+                    "type": "string",
+                    "example": "demo"
+                },
+                "dry_run": {
+                    "description": "DryRun считает строки, которые будут удалены, ничего не удаляя",
+                    "type": "boolean",
+                    "example": false
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.TeardownResult": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "demo"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.TenantOverview": {
+            "type": "object",
+            "properties": {
+                "consolidation": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConsolidationOpportunity"
+                    }
+                },
+                "group_by": {
+                    "type": "string",
+                    "enum": [
+                        "user"
+                    ],
+                    "example": "user"
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
                 },
                 "monthly_cost": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 5400
                 },
-                "service_name": {
-                    "type": "string"
+                "shared_services": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SharedService"
+                    }
                 },
-                "start_date": {
-                    "type": "string"
+                "tenant": {
+                    "type": "string",
+                    "example": "default"
                 },
-                "updated_at": {
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserSpend"
+                    }
+                }
+            }
+        },
+        "model.TenantSettings": {
+            "type": "object",
+            "properties": {
+                "rounding": {
+                    "type": "string",
+                    "enum": [
+                        "half_up",
+                        "half_even"
+                    ],
+                    "example": "half_even"
+                },
+                "tax_rate_percent": {
+                    "type": "string",
+                    "example": "20"
+                }
+            }
+        },
+        "model.TransferSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "family plan"
                 },
                 "user_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "8d2f1c4e-5b6a-4c3d-9e8f-0a1b2c3d4e5f"
                 }
             }
         },
-        "model.SummaryResponse": {
+        "model.UpdatePoolSettingsRequest": {
             "type": "object",
             "properties": {
-                "total_cost": {
-                    "type": "integer"
+                "conn_max_idle_time": {
+                    "type": "string",
+                    "example": "1m"
+                },
+                "conn_max_lifetime": {
+                    "type": "string",
+                    "example": "10m"
+                },
+                "max_idle_conns": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "max_open_conns": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "statement_timeout": {
+                    "type": "string",
+                    "example": "5s"
                 }
             }
         },
         "model.UpdateSubscriptionRequest": {
             "type": "object",
             "required": [
-                "monthly_cost",
                 "service_name",
                 "start_date",
                 "user_id"
             ],
             "properties": {
+                "billing_period": {
+                    "description": "BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "yearly"
+                },
+                "cost": {
+                    "description": "Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "description": {
+                    "description": "Description - заметки о подписке, до 2000 символов; без поля описание удаляется",
+                    "type": "string",
+                    "example": "Семейный тариф на четверых, продлевать вручную"
+                },
                 "end_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "is_free": {
+                    "description": "IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются",
+                    "type": "boolean",
+                    "example": false
+                },
+                "metadata": {
+                    "description": "Metadata - метки подписки, заменяют прежние целиком; без поля метки удаляются",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "project": "apollo"
+                    }
                 },
                 "monthly_cost": {
                     "type": "integer",
-                    "minimum": 1
+                    "minimum": 1,
+                    "example": 400
+                },
+                "prepaid_amount": {
+                    "description": "PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4800
+                },
+                "price_effective_from": {
+                    "description": "PriceEffectiveFrom - месяц, с которого действует новая стоимость; прошлые месяцы\nсчитаются по прежней. По умолчанию текущий месяц, у подписок, которые еще не\nначались или уже закончились, - месяц начала (стоимость заменяется целиком)",
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "service_id": {
+                    "description": "ServiceID - сервис из каталога; без поля связь с каталогом удаляется",
+                    "type": "string",
+                    "example": "3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"
                 },
                 "service_name": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Yandex Plus"
                 },
                 "start_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "status": {
+                    "description": "Status - новое состояние подписки; если не задано, состояние не меняется",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled"
+                    ],
+                    "example": "paused"
+                },
+                "tags": {
+                    "description": "Tags - теги подписки, заменяют прежние целиком; без поля теги снимаются",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
                 },
                 "user_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.UsageResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "description": "APIKey - маскированный ключ клиента",
+                    "type": "string",
+                    "example": "sk_live_****9f2c"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DailyUsage"
+                    }
+                },
+                "rate_limit": {
+                    "$ref": "#/definitions/model.RateLimitUsage"
+                }
+            }
+        },
+        "model.UserService": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "integer",
+                    "example": 800
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.UserSpend": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "services": {
+                    "description": "Services - число разных сервисов (по model.NormalizeServiceName)",
+                    "type": "integer",
+                    "example": 2
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "model.ValidationResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "end date cannot be before start date"
+                },
+                "normalized": {
+                    "$ref": "#/definitions/model.CreateSubscriptionRequest"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ValidationWarning"
+                    }
+                }
+            }
+        },
+        "model.ValidationWarning": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "overlap",
+                        "expired"
+                    ],
+                    "example": "overlap"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "user already has a Yandex Plus subscription for 07-2025 - 12-2025"
+                },
+                "subscription_id": {
+                    "description": "SubscriptionID - подписка, с которой пересекается запрос (для overlap)",
+                    "type": "string",
+                    "example": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+                }
+            }
+        },
+        "model.WebhookEndpointStats": {
+            "type": "object",
+            "properties": {
+                "circuit_open": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "delivered": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "dropped": {
+                    "description": "Dropped - события, отброшенные из-за переполненной очереди или разомкнутого выключателя",
+                    "type": "integer",
+                    "example": 0
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "queue_depth": {
+                    "description": "QueueDepth - сколько событий ждет отправки",
+                    "type": "integer",
+                    "example": 3
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/subscriptions"
                 }
             }
         }
@@ -461,8 +8459,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "REST API для управления подписками пользователей",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
-	LeftDelim:        "{{",
-	RightDelim:       "}}",
+	LeftDelim:        "[[",
+	RightDelim:       "]]",
 }
 
 func init() {
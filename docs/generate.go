@@ -0,0 +1,6 @@
+package docs
+
+// Документация строится swag по аннотациям обработчиков, затем в нее добавляются примеры
+// ответов из эталонных ответов тестов обработчиков. Подстановки шаблона - [[ ]]: в
+// примерах моделей встречаются шаблоны уведомлений с {{ }}
+//go:generate sh -c "cd .. && swag init -g cmd/server/main.go -o docs --templateDelims [[,]] && go run ./cmd/swaggerexamples"
@@ -0,0 +1,117 @@
+// Package auth хранит в контексте запроса пользователя, от имени которого он выполняется,
+// и ограничивает выборки его данными
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+
+	"github.com/google/uuid"
+)
+
+// Caller - аутентифицированный пользователь запроса. Admin видит данные всех
+// пользователей и может явно выбрать пользователя параметром user_id. Tenant - организация
+// пользователя из токена; пустое значение - токен организацию не задает. Scopes - права
+// личного токена API; nil - запрос выполнен не личным токеном и права не ограничены.
+// Memberships - организации пользователя по его группам SSO и права администратора в них;
+// пустое значение - сопоставлений групп нет
+type Caller struct {
+	UserID      uuid.UUID
+	Admin       bool
+	Tenant      string
+	Scopes      []string
+	Memberships map[string]bool
+}
+
+// HasScope сообщает, что пользователю запроса разрешено действие scope: права
+// ограничивает только личный токен API
+func (c Caller) HasScope(scope string) bool {
+	if c.Scopes == nil {
+		return true
+	}
+	return slices.Contains(c.Scopes, scope)
+}
+
+// Ошибки аутентификации и прав доступа
+var (
+	// ErrInvalidToken - токен не прошел проверку: ответ 401
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrForbidden - у пользователя запроса нет прав на действие: ответ 403
+	ErrForbidden = errors.New("forbidden")
+)
+
+type callerKey struct{}
+
+// WithCaller возвращает контекст с пользователем запроса; его вызывает middleware аутентификации.
+// ID пользователя сохраняется и в ctxutil, откуда его берут логгер и сервисы
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	if caller.UserID != uuid.Nil {
+		ctx = ctxutil.WithUserID(ctx, caller.UserID)
+	}
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom возвращает пользователя запроса; false - запрос без аутентификации
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
+
+// ScopeUserID возвращает пользователя, данными которого ограничивается выборка.
+// Без аутентификации и для администратора requested возвращается как есть (nil - все
+// пользователи). Обычному пользователю подставляется его собственный ID, запрос чужих
+// данных завершается ошибкой "forbidden"
+func ScopeUserID(ctx context.Context, requested *uuid.UUID) (*uuid.UUID, error) {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Admin {
+		return requested, nil
+	}
+	if requested != nil && *requested != caller.UserID {
+		return nil, fmt.Errorf("%w: cannot access subscriptions of user %s", ErrForbidden, *requested)
+	}
+	userID := caller.UserID
+	return &userID, nil
+}
+
+// Restricted сообщает, что запрос выполняет аутентифицированный пользователь без прав
+// администратора: ему доступны только его собственные данные
+func Restricted(ctx context.Context) bool {
+	caller, ok := CallerFrom(ctx)
+	return ok && !caller.Admin
+}
+
+// CanAccessUser сообщает, что пользователю запроса доступны данные пользователя userID.
+// Без аутентификации и администратору доступны данные всех пользователей
+func CanAccessUser(ctx context.Context, userID uuid.UUID) bool {
+	caller, ok := CallerFrom(ctx)
+	return !ok || caller.Admin || caller.UserID == userID
+}
+
+// RequireAdmin возвращает ошибку "forbidden", если запрос выполняет аутентифицированный
+// пользователь без прав администратора. Запросы без аутентификации пропускаются
+func RequireAdmin(ctx context.Context, action string) error {
+	if caller, ok := CallerFrom(ctx); ok && !caller.Admin {
+		return fmt.Errorf("%w: %s requires admin rights", ErrForbidden, action)
+	}
+	return nil
+}
+
+// Actor возвращает автора изменений для журнала аудита: "user:<id>", "admin:<id>",
+// "admin" для ADMIN_TOKEN без пользователя; без аутентификации - пустую строку
+func Actor(ctx context.Context) string {
+	caller, ok := CallerFrom(ctx)
+	switch {
+	case !ok:
+		return ""
+	case caller.Admin && caller.UserID == uuid.Nil:
+		return "admin"
+	case caller.Admin:
+		return "admin:" + caller.UserID.String()
+	default:
+		return "user:" + caller.UserID.String()
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestScopeUserID(t *testing.T) {
+	self := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested *uuid.UUID
+		want      *uuid.UUID
+		wantErr   bool
+	}{
+		{"anonymous keeps all users", context.Background(), nil, nil, false},
+		{"anonymous keeps requested", context.Background(), &other, &other, false},
+		{"user defaults to self", WithCaller(context.Background(), Caller{UserID: self}), nil, &self, false},
+		{"user requests self", WithCaller(context.Background(), Caller{UserID: self}), &self, &self, false},
+		{"user requests other", WithCaller(context.Background(), Caller{UserID: self}), &other, nil, true},
+		{"admin overrides", WithCaller(context.Background(), Caller{UserID: self, Admin: true}), &other, &other, false},
+		{"admin sees all", WithCaller(context.Background(), Caller{UserID: self, Admin: true}), nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScopeUserID(tt.ctx, tt.requested)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "forbidden") {
+					t.Fatalf("error = %v, want forbidden", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScopeUserID() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ScopeUserID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActor(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"anonymous", context.Background(), ""},
+		{"user", WithCaller(context.Background(), Caller{UserID: id}), "user:" + id.String()},
+		{"admin user", WithCaller(context.Background(), Caller{UserID: id, Admin: true}), "admin:" + id.String()},
+		{"admin token", WithCaller(context.Background(), Caller{Admin: true}), "admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Actor(tt.ctx); got != tt.want {
+				t.Errorf("Actor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanAccessUser(t *testing.T) {
+	self := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want bool
+	}{
+		{"anonymous", context.Background(), true},
+		{"owner", WithCaller(context.Background(), Caller{UserID: self}), false},
+		{"admin", WithCaller(context.Background(), Caller{UserID: self, Admin: true}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !CanAccessUser(tt.ctx, self) {
+				t.Error("CanAccessUser(self) = false, want true")
+			}
+			if got := CanAccessUser(tt.ctx, other); got != tt.want {
+				t.Errorf("CanAccessUser(other) = %v, want %v", got, tt.want)
+			}
+			if got := Restricted(tt.ctx); got == tt.want {
+				t.Errorf("Restricted() = %v, want %v", got, !tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// clockSkew - допустимое расхождение часов сервиса и провайдера при проверке exp и nbf
+const clockSkew = time.Minute
+
+// jwksRefreshInterval - не чаще этого интервала ключи перечитываются из-за неизвестного kid,
+// чтобы токены с выдуманным kid не превращались в запросы к провайдеру
+const jwksRefreshInterval = 30 * time.Second
+
+// jwksTTL - через сколько ключи перечитываются, даже если все kid известны
+const jwksTTL = time.Hour
+
+// OIDCConfig - параметры проверки токенов внешнего провайдера (Keycloak, Auth0 и т.п.)
+type OIDCConfig struct {
+	// JWKSURL - адрес набора открытых ключей провайдера
+	JWKSURL string
+	// Issuer - ожидаемое значение iss; пустое значение не проверяется
+	Issuer string
+	// Audience - значение, которое должно входить в aud; пустое значение не проверяется
+	Audience string
+	// AdminRole - роль, дающая права администратора; ищется в claims roles и realm_access.roles
+	AdminRole string
+	// TenantClaim - claim токена с организацией пользователя; пустое значение - организация
+	// токеном не задается
+	TenantClaim string
+	// GroupsClaim - claim токена с группами пользователя (список строк); пустое значение -
+	// группы из токена не читаются
+	GroupsClaim string
+}
+
+// Claims - проверенные claims токена, нужные сервису
+type Claims struct {
+	Issuer  string
+	Subject string
+	Roles   []string
+	// Tenant - значение claim OIDCConfig.TenantClaim; пустое, если claim не настроен или отсутствует
+	Tenant string
+	// Groups - значение claim OIDCConfig.GroupsClaim; пустое, если claim не настроен или отсутствует
+	Groups []string
+}
+
+// Verifier проверяет подпись и срок действия JWT по ключам из JWKS провайдера
+type Verifier struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func NewVerifier(cfg OIDCConfig, client *http.Client) *Verifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{cfg: cfg, client: client}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtPayload struct {
+	Issuer      string          `json:"iss"`
+	Subject     string          `json:"sub"`
+	Audience    json.RawMessage `json:"aud"`
+	Expiry      *int64          `json:"exp"`
+	NotBefore   *int64          `json:"nbf"`
+	Roles       []string        `json:"roles"`
+	RealmAccess struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+}
+
+// Verify проверяет токен и возвращает его claims. Поддерживаются подписи RS256/384/512
+// и ES256/384/512; токены без подписи и с симметричной подписью отклоняются
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %w", ErrInvalidToken, err)
+	}
+	hash, err := algorithmHash(header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad signature encoding: %w", ErrInvalidToken, err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(key, header.Alg, hash, h.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+
+	var payload jwtPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("%w: bad payload: %w", ErrInvalidToken, err)
+	}
+	if err := v.validate(&payload, time.Now()); err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{
+		Issuer:  payload.Issuer,
+		Subject: payload.Subject,
+		Roles:   append(payload.Roles, payload.RealmAccess.Roles...),
+	}
+	if v.cfg.TenantClaim != "" || v.cfg.GroupsClaim != "" {
+		// Имена claims задаются конфигурацией, поэтому читаются из payload отдельно
+		var raw map[string]json.RawMessage
+		if err := decodeSegment(parts[1], &raw); err != nil {
+			return nil, fmt.Errorf("%w: bad payload: %w", ErrInvalidToken, err)
+		}
+		if value, ok := raw[v.cfg.TenantClaim]; ok && v.cfg.TenantClaim != "" {
+			if err := json.Unmarshal(value, &claims.Tenant); err != nil {
+				return nil, fmt.Errorf("%w: %s claim is not a string", ErrInvalidToken, v.cfg.TenantClaim)
+			}
+		}
+		if value, ok := raw[v.cfg.GroupsClaim]; ok && v.cfg.GroupsClaim != "" {
+			if err := json.Unmarshal(value, &claims.Groups); err != nil {
+				return nil, fmt.Errorf("%w: %s claim is not a list of strings", ErrInvalidToken, v.cfg.GroupsClaim)
+			}
+		}
+	}
+	return claims, nil
+}
+
+// Caller сопоставляет claims пользователю сервиса. sub в виде UUID (Keycloak) используется
+// как user_id напрямую, остальные значения (например, "auth0|abc") - через UUIDv5 от iss и sub,
+// поэтому один и тот же пользователь провайдера всегда получает один user_id
+func (v *Verifier) Caller(claims *Claims) Caller {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		userID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(claims.Issuer+"#"+claims.Subject))
+	}
+
+	admin := false
+	if v.cfg.AdminRole != "" {
+		for _, role := range claims.Roles {
+			if role == v.cfg.AdminRole {
+				admin = true
+				break
+			}
+		}
+	}
+	return Caller{UserID: userID, Admin: admin, Tenant: claims.Tenant}
+}
+
+func (v *Verifier) validate(payload *jwtPayload, now time.Time) error {
+	if payload.Subject == "" {
+		return fmt.Errorf("%w: sub claim is missing", ErrInvalidToken)
+	}
+	if payload.Expiry == nil {
+		return fmt.Errorf("%w: exp claim is missing", ErrInvalidToken)
+	}
+	if now.Add(-clockSkew).After(time.Unix(*payload.Expiry, 0)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+	if payload.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*payload.NotBefore, 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	if v.cfg.Issuer != "" && payload.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, payload.Issuer)
+	}
+	if v.cfg.Audience != "" && !hasAudience(payload.Audience, v.cfg.Audience) {
+		return fmt.Errorf("%w: audience does not include %q", ErrInvalidToken, v.cfg.Audience)
+	}
+	return nil
+}
+
+// hasAudience проверяет aud, который по RFC 7519 бывает строкой или массивом строк
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, aud := range list {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func algorithmHash(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "RS256", "ES256":
+		return crypto.SHA256, nil
+	case "RS384", "ES384":
+		return crypto.SHA384, nil
+	case "RS512", "ES512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("%w: unsupported signing algorithm %q", ErrInvalidToken, alg)
+	}
+}
+
+func verifySignature(key crypto.PublicKey, alg string, hash crypto.Hash, digest, signature []byte) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("%w: algorithm %s does not match RSA key", ErrInvalidToken, alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("%w: algorithm %s does not match EC key", ErrInvalidToken, alg)
+		}
+		// Подпись JWS - r и s фиксированной длины подряд, а не DER
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported key type", ErrInvalidToken)
+	}
+}
+
+// key возвращает ключ по kid, при необходимости перечитывая JWKS: провайдер мог
+// сменить ключи, а закэшированный набор - устареть
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > jwksTTL
+	if ok && !stale {
+		return key, nil
+	}
+	if !stale && time.Since(v.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if ok {
+			// Провайдер недоступен, но ключ известен: продолжаем проверять им
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Ключи неподдерживаемых типов пропускаем, остальные остаются рабочими
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
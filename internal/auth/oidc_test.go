@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testProvider - JWKS провайдера с одним RSA и одним EC ключом
+type testProvider struct {
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	server  *httptest.Server
+	fetches int
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &testProvider{rsaKey: rsaKey, ecKey: ecKey}
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.fetches++
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, p.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, p.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	provider := newTestProvider(t)
+	verifier := NewVerifier(OIDCConfig{
+		JWKSURL:  provider.server.URL,
+		Issuer:   "https://sso.example.com/realms/main",
+		Audience: "subscription-service",
+	}, provider.server.Client())
+
+	now := time.Now().Unix()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": "https://sso.example.com/realms/main",
+			"sub": "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			"aud": []string{"account", "subscription-service"},
+			"exp": now + 300,
+		}
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	// Токен без подписи должен отклоняться до поиска ключа
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"x","exp":9999999999}`)) + "."
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"rs256", provider.sign(t, "RS256", "rsa-1", valid()), ""},
+		{"es256", provider.sign(t, "ES256", "ec-1", valid()), ""},
+		{"audience as string", provider.sign(t, "RS256", "rsa-1", with("aud", "subscription-service")), ""},
+		{"expired", provider.sign(t, "RS256", "rsa-1", with("exp", now-2*int64(clockSkew.Seconds()))), "token is expired"},
+		{"without exp", provider.sign(t, "RS256", "rsa-1", with("exp", nil)), "exp claim is missing"},
+		{"not yet valid", provider.sign(t, "RS256", "rsa-1", with("nbf", now+3600)), "not valid yet"},
+		{"other issuer", provider.sign(t, "RS256", "rsa-1", with("iss", "https://evil.example.com")), "unexpected issuer"},
+		{"other audience", provider.sign(t, "RS256", "rsa-1", with("aud", "billing")), "audience"},
+		{"unknown kid", provider.sign(t, "RS256", "rsa-2", valid()), "unknown key id"},
+		{"algorithm confusion", provider.sign(t, "ES256", "rsa-1", valid()), "does not match RSA key"},
+		{"alg none", none, "unsupported signing algorithm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				if claims.Subject != "60601fee-2bf1-4721-ae6f-7636e79a0cba" {
+					t.Errorf("subject = %q", claims.Subject)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid token") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Неизвестный kid не перечитывает JWKS чаще jwksRefreshInterval
+	if provider.fetches != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", provider.fetches)
+	}
+
+	tampered := provider.sign(t, "RS256", "rsa-1", valid())
+	parts := strings.Split(tampered, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"attacker","exp":9999999999}`))
+	if _, err := verifier.Verify(context.Background(), strings.Join(parts, ".")); err == nil {
+		t.Error("tampered payload was accepted")
+	}
+}
+
+func TestVerifierCaller(t *testing.T) {
+	verifier := NewVerifier(OIDCConfig{AdminRole: "subscriptions-admin"}, nil)
+
+	keycloak := verifier.Caller(&Claims{Subject: "60601fee-2bf1-4721-ae6f-7636e79a0cba", Roles: []string{"offline_access", "subscriptions-admin"}})
+	if keycloak.UserID.String() != "60601fee-2bf1-4721-ae6f-7636e79a0cba" || !keycloak.Admin {
+		t.Errorf("unexpected caller for UUID subject: %+v", keycloak)
+	}
+
+	// Не-UUID sub детерминированно отображается в UUID, свой для каждого провайдера
+	auth0 := verifier.Caller(&Claims{Issuer: "https://tenant.auth0.com/", Subject: "auth0|abc"})
+	again := verifier.Caller(&Claims{Issuer: "https://tenant.auth0.com/", Subject: "auth0|abc"})
+	other := verifier.Caller(&Claims{Issuer: "https://other.auth0.com/", Subject: "auth0|abc"})
+	if auth0.UserID == uuid.Nil || auth0.UserID != again.UserID || auth0.UserID == other.UserID || auth0.Admin {
+		t.Errorf("unexpected callers for opaque subject: %+v %+v %+v", auth0, again, other)
+	}
+}
+
+func TestVerifyTenantClaim(t *testing.T) {
+	provider := newTestProvider(t)
+	verifier := NewVerifier(OIDCConfig{JWKSURL: provider.server.URL, TenantClaim: "org"}, provider.server.Client())
+
+	claims := map[string]interface{}{"sub": "auth0|abc", "exp": time.Now().Unix() + 300, "org": "acme"}
+	verified, err := verifier.Verify(context.Background(), provider.sign(t, "RS256", "rsa-1", claims))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if caller := verifier.Caller(verified); caller.Tenant != "acme" {
+		t.Errorf("caller tenant = %q, want %q", caller.Tenant, "acme")
+	}
+
+	claims["org"] = 42
+	if _, err := verifier.Verify(context.Background(), provider.sign(t, "RS256", "rsa-1", claims)); err == nil || !strings.Contains(err.Error(), "org claim is not a string") {
+		t.Errorf("Verify() error = %v, want non-string claim error", err)
+	}
+}
+
+func TestVerifyGroupsClaim(t *testing.T) {
+	provider := newTestProvider(t)
+	verifier := NewVerifier(OIDCConfig{JWKSURL: provider.server.URL, GroupsClaim: "groups"}, provider.server.Client())
+
+	claims := map[string]interface{}{"sub": "auth0|abc", "exp": time.Now().Unix() + 300, "groups": []string{"/acme/billing", "/beta"}}
+	verified, err := verifier.Verify(context.Background(), provider.sign(t, "RS256", "rsa-1", claims))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if want := []string{"/acme/billing", "/beta"}; !slices.Equal(verified.Groups, want) {
+		t.Errorf("groups = %v, want %v", verified.Groups, want)
+	}
+
+	claims["groups"] = "/acme/billing"
+	if _, err := verifier.Verify(context.Background(), provider.sign(t, "RS256", "rsa-1", claims)); err == nil || !strings.Contains(err.Error(), "groups claim is not a list of strings") {
+		t.Errorf("Verify() error = %v, want non-list claim error", err)
+	}
+}
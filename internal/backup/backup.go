@@ -0,0 +1,211 @@
+// Package backup шифрует логические резервные копии базы для хранения в blobstore.
+// Копия шифруется AES-256-GCM блоками по 64 КиБ: каждый блок проверяется отдельно,
+// поэтому копию можно расшифровывать потоком, а подмена, перестановка и обрезка блоков
+// обнаруживаются при чтении
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// KeySize - длина ключа шифрования копий (AES-256)
+const KeySize = 32
+
+// chunkSize - размер открытого текста в блоке
+const chunkSize = 64 << 10
+
+// magic - заголовок зашифрованной копии с версией формата
+var magic = []byte("SSBK\x01")
+
+// noncePrefixSize - случайная часть nonce блока, общая для копии; остальные 4 байта - номер блока
+const noncePrefixSize = 8
+
+// ErrCorrupted возвращается, если копия повреждена, обрезана или зашифрована другим ключом
+var ErrCorrupted = errors.New("backup is corrupted or encrypted with another key")
+
+// ParseKey читает ключ шифрования копий из base64 (например, `openssl rand -base64 32`)
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("backup key must be base64 encoded: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("backup key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce собирает nonce блока n; additional data отмечает последний блок,
+// чтобы обрезка копии по границе блока не проходила незамеченной
+func chunkNonce(prefix []byte, n uint32, final bool) (nonce, additional []byte) {
+	nonce = make([]byte, noncePrefixSize+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], n)
+	additional = append(append([]byte{}, magic...), 0)
+	if final {
+		additional[len(additional)-1] = 1
+	}
+	return nonce, additional
+}
+
+// Writer шифрует записанные данные в dst. Close записывает последний блок и обязателен
+type Writer struct {
+	dst    io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	buf    []byte
+	closed bool
+}
+
+// NewWriter начинает зашифрованную копию в dst
+func NewWriter(dst io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := dst.Write(append(append([]byte{}, magic...), prefix...)); err != nil {
+		return nil, err
+	}
+	return &Writer{dst: dst, aead: aead, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed backup writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		// Полный блок записывается, только когда известно, что он не последний
+		if len(w.buf) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close записывает последний блок; dst не закрывается
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *Writer) seal(final bool) error {
+	if w.n == math.MaxUint32 {
+		return errors.New("backup is too large")
+	}
+	nonce, additional := chunkNonce(w.prefix, w.n, final)
+	sealed := w.aead.Seal(nil, nonce, w.buf, additional)
+
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(sealed)))
+	if _, err := w.dst.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.dst.Write(sealed); err != nil {
+		return err
+	}
+	w.n++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Reader расшифровывает копию, записанную Writer
+type Reader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	plain  []byte
+	final  bool
+}
+
+// NewReader проверяет заголовок копии src
+func NewReader(src io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(magic)+noncePrefixSize)
+	if _, err := io.ReadFull(src, header); err != nil || !bytes.Equal(header[:len(magic)], magic) {
+		return nil, ErrCorrupted
+	}
+	return &Reader{src: src, aead: aead, prefix: header[len(magic):]}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.final {
+			// После последнего блока данных быть не должно
+			if n, _ := r.src.Read(make([]byte, 1)); n > 0 {
+				return 0, ErrCorrupted
+			}
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open читает и проверяет следующий блок
+func (r *Reader) open() error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r.src, header); err != nil {
+		// Копия закончилась без последнего блока - она обрезана
+		return ErrCorrupted
+	}
+	size := binary.BigEndian.Uint32(header)
+	if size > chunkSize+uint32(r.aead.Overhead()) {
+		return ErrCorrupted
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		return ErrCorrupted
+	}
+
+	// Последним может оказаться любой блок, поэтому проверяются оба варианта
+	for _, final := range []bool{false, true} {
+		nonce, additional := chunkNonce(r.prefix, r.n, final)
+		if plain, err := r.aead.Open(nil, nonce, sealed, additional); err == nil {
+			r.plain = plain
+			r.final = final
+			r.n++
+			return nil
+		}
+	}
+	return ErrCorrupted
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func encrypt(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(&out, key)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.Bytes()
+}
+
+func decrypt(key, sealed []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+
+	for _, size := range []int{0, 10, chunkSize, 2*chunkSize + 7} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		got, err := decrypt(key, encrypt(t, key, plain))
+		if err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: decrypted data differs", size)
+		}
+	}
+}
+
+func TestCorruptedBackup(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	plain := make([]byte, 2*chunkSize+7)
+	rand.Read(plain)
+	sealed := encrypt(t, key, plain)
+
+	otherKey := make([]byte, KeySize)
+	rand.Read(otherKey)
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)/2] ^= 1
+	// Обрезка по границе блока: заголовок, два полных блока без последнего
+	blockSize := 4 + chunkSize + 16
+	truncated := sealed[:len(magic)+noncePrefixSize+2*blockSize]
+
+	tests := map[string]struct {
+		key    []byte
+		sealed []byte
+	}{
+		"other key": {otherKey, sealed},
+		"tampered":  {key, flipped},
+		"truncated": {key, truncated},
+		"trailing":  {key, append(append([]byte{}, sealed...), 0)},
+	}
+	for name, tt := range tests {
+		if _, err := decrypt(tt.key, tt.sealed); !errors.Is(err, ErrCorrupted) {
+			t.Errorf("%s: error = %v, want ErrCorrupted", name, err)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey("c2hvcnQ="); err == nil {
+		t.Error("ParseKey accepted a short key")
+	}
+	if _, err := ParseKey("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="); err != nil {
+		t.Errorf("ParseKey: %v", err)
+	}
+}
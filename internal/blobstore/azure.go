@@ -0,0 +1,185 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const azureAPIVersion = "2021-08-06"
+
+// azureStore работает с Azure Blob Storage через REST API с авторизацией Shared Key.
+// Bucket используется как имя контейнера, AccessKey - как имя storage account
+type azureStore struct {
+	endpoint  *url.URL
+	account   string
+	container string
+	key       []byte
+	client    *http.Client
+}
+
+func newAzureStore(cfg Config) (Store, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("azure blob storage requires container, account name and account key")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("azure account key must be base64 encoded: %w", err)
+	}
+
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.AccessKey)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(rawEndpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid blob storage endpoint %q", rawEndpoint)
+	}
+
+	return &azureStore{
+		endpoint:  endpoint,
+		account:   cfg.AccessKey,
+		container: cfg.Bucket,
+		key:       key,
+		client:    cfg.Client,
+	}, nil
+}
+
+func (s *azureStore) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.container + "/" + key
+	u.RawPath = s.endpoint.EscapedPath() + "/" + uriEncode(s.container, false) + "/" + uriEncode(key, true)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build blob request: %w", err)
+	}
+	return req, nil
+}
+
+func (s *azureStore) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+	req.Header.Set("Authorization", "SharedKey "+s.account+":"+s.sign(req))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("blob storage request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sign строит подпись Shared Key: стандартные заголовки, затем x-ms-* заголовки
+// и канонический путь ресурса с параметрами запроса
+func (s *azureStore) sign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(strings.Join(values, ",")))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	resource := "/" + s.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(query[name], ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date передается через x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		resource,
+	}, "\n")
+
+	return base64.StdEncoding.EncodeToString(hmacSHA256(s.key, stringToSign))
+}
+
+func (s *azureStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusCreated)
+}
+
+func (s *azureStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *azureStore) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkResponse(resp, http.StatusAccepted)
+}
@@ -0,0 +1,91 @@
+// Package blobstore хранит файлы (вложения, выгрузки) в локальной файловой системе
+// или облачном объектном хранилище. Драйвер выбирается конфигурацией.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverGCS   = "gcs"
+	DriverAzure = "azure"
+)
+
+// ErrNotFound возвращается, если объекта с таким ключом нет
+var ErrNotFound = errors.New("blob not found")
+
+// Store - хранилище объектов. Ключи имеют вид "exports/2025/07/report.csv"
+type Store interface {
+	// Put сохраняет объект, перезаписывая существующий. size - длина содержимого в байтах
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; вызывающий обязан закрыть ридер
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; удаление отсутствующего объекта не считается ошибкой
+	Delete(ctx context.Context, key string) error
+}
+
+// Config - параметры хранилища. Значения AccessKey/SecretKey зависят от драйвера:
+// для s3 - ключи доступа AWS, для gcs - HMAC-ключи сервисного аккаунта,
+// для azure - имя storage account и его ключ в base64
+type Config struct {
+	Driver    string
+	Bucket    string
+	LocalDir  string
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Client - клиент облачного хранилища с настройками исходящих запросов; nil - клиент
+	// с таймаутом 5 минут
+	Client *http.Client
+}
+
+// New создает хранилище выбранного драйвера
+func New(cfg Config) (Store, error) {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocalStore(cfg.LocalDir)
+	case DriverS3:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+		}
+		return newS3Store(cfg)
+	case DriverGCS:
+		// GCS поддерживает S3-совместимый XML API с HMAC-ключами
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://storage.googleapis.com"
+		}
+		if cfg.Region == "" {
+			cfg.Region = "auto"
+		}
+		return newS3Store(cfg)
+	case DriverAzure:
+		return newAzureStore(cfg)
+	default:
+		return nil, fmt.Errorf("unknown blob storage driver %q (expected local, s3, gcs or azure)", cfg.Driver)
+	}
+}
+
+// validateKey запрещает пустые ключи и выход за пределы корня хранилища
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
@@ -3,10 +3,27 @@ package config
 import (
 	"fmt"
 	"log/slog"
-	"os"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/backup"
+	"github.com/Zipklas/subscription-service/internal/egress"
 )
 
+// JobConfig - расписание фоновой задачи
+type JobConfig struct {
+	// Schedule - cron-выражение из пяти полей (UTC), дескриптор (@daily) или "@every <duration>"
+	Schedule string
+	Enabled  bool
+	// Jitter - максимальная случайная задержка запуска
+	Jitter time.Duration
+}
+
 type Config struct {
+	// DBDriver - хранилище подписок: postgres, sqlite (файл SQLitePath) или memory
+	// (данные в памяти процесса, без базы)
+	DBDriver   string
+	SQLitePath string
 	DBHost     string
 	DBPort     string
 	DBName     string
@@ -14,20 +31,451 @@ type Config struct {
 	DBPassword string
 	AppPort    string
 	LogLevel   slog.Level
+
+	// Таймауты HTTP-сервера
+	ServerReadTimeout  time.Duration
+	ServerWriteTimeout time.Duration
+	ServerIdleTimeout  time.Duration
+
+	// CORSAllowedOrigins - разрешенные источники CORS; "*" разрешает любой
+	CORSAllowedOrigins []string
+
+	// Пул соединений; значения можно менять на лету через /admin/db/pool
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  time.Duration
+	DBStatementTimeout time.Duration
+
+	// Подключение при старте: попытки с экспоненциальной задержкой от DBConnectRetryInitial
+	// до DBConnectRetryMax, не дольше DBConnectMaxWait (0 - без ограничения). DBLazyConnect
+	// запускает сервис сразу, а подключение продолжается в фоне
+	DBConnectRetryInitial time.Duration
+	DBConnectRetryMax     time.Duration
+	DBConnectMaxWait      time.Duration
+	DBLazyConnect         bool
+
+	// ReadinessTimeout ограничивает каждую проверку зависимости в /readyz
+	ReadinessTimeout time.Duration
+
+	// Метаданные пода Kubernetes из downward API; добавляются в логи, /metrics и пробы
+	PodName      string
+	PodNamespace string
+	NodeName     string
+
+	// MsgpackEnabled разрешает application/msgpack в запросах и ответах API
+	MsgpackEnabled bool
+
+	// ServerTimingEnabled добавляет в ответы заголовок Server-Timing со временем обработчика,
+	// сервиса и базы; ResponseTimeBudget - ожидаемое время ответа, более долгие запросы
+	// пишутся в лог
+	ServerTimingEnabled bool
+	ResponseTimeBudget  time.Duration
+
+	// ReportLocale - язык подписей в отчетах, если клиент не передал поддерживаемый Accept-Language
+	ReportLocale string
+
+	// PeriodTimezone - часовой пояс, в котором определяется текущий месяц, границы интервалов
+	// аналитики и выводятся created_at/updated_at
+	PeriodTimezone string
+
+	// UUIDVersions - допустимые версии UUID в пути, параметрах и теле запросов; пустой список разрешает любые
+	UUIDVersions []int
+
+	// StrictFilters отклоняет запросы списка с некорректными фильтрами; false пропускает такие фильтры
+	StrictFilters bool
+
+	// Учет запросов клиентов с X-API-Key; нулевой RateLimitPerMinute отключает ограничение
+	RateLimitPerMinute int
+	UsageRetentionDays int
+
+	// Сколько дней хранятся отклоненные запросы на запись
+	RejectedRequestsRetentionDays int
+
+	// Сколько хранится ответ на запрос с Idempotency-Key; ноль отключает ключи идемпотентности
+	IdempotencyTTL time.Duration
+
+	// Сколько дней хранятся идентификаторы обработанных входящих событий: повтор события
+	// после этого срока применится заново
+	InboundEventsRetentionDays int
+
+	// Постепенный перевод месяцев в ответах на формат YYYY-MM: организации, переведенные
+	// целиком ("*" - все), и доля остальных клиентов в процентах
+	DateFormatISOTenants []string
+	DateFormatISOPercent int
+
+	// Пороги алертов Prometheus для cmd/rulesgen
+	AlertRuleWindow      time.Duration
+	AlertFor             time.Duration
+	AlertQueryLatencyP95 time.Duration
+	AlertQueryRowsP95    int
+
+	// AdminToken - Bearer-токен административного API; пустое значение отключает API
+	AdminToken string
+
+	// AppEnv - окружение развертывания (APP_ENV). В production удаление данных
+	// организации запрещено, пока не задан TeardownAllowProduction
+	AppEnv                  string
+	TeardownAllowProduction bool
+
+	// OIDC: проверка токенов внешнего провайдера. Пустой OIDCJWKSURL отключает аутентификацию
+	OIDCJWKSURL   string
+	OIDCIssuer    string
+	OIDCAudience  string
+	OIDCAdminRole string
+	// OIDCTenantClaim - claim токена с организацией пользователя
+	OIDCTenantClaim string
+	// OIDCGroupsClaim - claim токена с группами пользователя для сопоставлений групп SSO
+	OIDCGroupsClaim string
+
+	// Трассировка: пустой OTLPEndpoint отключает запись спанов
+	OTLPEndpoint        string
+	TraceServiceName    string
+	TraceExportInterval time.Duration
+
+	// TenantHeader - заголовок с организацией запроса без организации в токене;
+	// пустое значение отключает выбор организации заголовком
+	TenantHeader string
+
+	// Налоги и округление в отчетах
+	TaxRatePercent   string
+	PricesIncludeTax bool
+	RoundingMode     string
+
+	// SummaryModifiers - модификаторы итогов /subscriptions/summary по порядку: имена из
+	// встроенного реестра или адреса сайдкаров (http://, https://)
+	SummaryModifiers       []string
+	SummaryModifierTimeout time.Duration
+
+	// Подпись итогов /subscriptions/summary?signed=true: секрет HMAC и его идентификатор
+	// в ответе; пустой SummarySigningKey отключает подпись
+	SummarySigningKey   string
+	SummarySigningKeyID string
+
+	// Исходящие запросы: прокси (пустое значение - из HTTP_PROXY, HTTPS_PROXY, NO_PROXY),
+	// таймаут соединения и разрешенные хосты; пустой EgressAllowedHosts разрешает любые
+	OutboundProxyURL    string
+	OutboundDialTimeout time.Duration
+	EgressAllowedHosts  []string
+
+	// Рассылка событий на адреса подписчиков; пустой WebhookURLs отключает ее
+	WebhookURLs             []string
+	WebhookWorkers          int
+	WebhookPerEndpoint      int
+	WebhookQueueSize        int
+	WebhookTimeout          time.Duration
+	WebhookFailureThreshold int
+	WebhookCooldown         time.Duration
+
+	// Поиск аномальных трат
+	AnomalyThresholdPercent int
+	AnomalyLookbackMonths   int
+	AnomalyMinSpend         int
+
+	// SparklineCacheTTL - время кэширования мини-графиков трат
+	SparklineCacheTTL time.Duration
+
+	// Напоминания о продлении: за сколько дней до окончания периода, напоминать ли
+	// о продлении бессрочных подписок и каналы доставки (log, webhook, email)
+	RenewalReminderDays      int
+	RenewalReminderOpenEnded bool
+	RenewalReminderChannels  []string
+
+	// Письма пользователям: драйвер smtp или sendgrid (пустой отключает письма), отправитель
+	// и учетные данные выбранного драйвера
+	EmailDriver    string
+	EmailFrom      string
+	EmailFromName  string
+	EmailTimeout   time.Duration
+	SMTPHost       string
+	SMTPPort       int
+	SMTPUsername   string
+	SMTPPassword   string
+	SendGridAPIKey string
+
+	// Расписания фоновых задач
+	AnomalyDetectionJob      JobConfig
+	RejectedRequestsPurgeJob JobConfig
+	RenewalRemindersJob      JobConfig
+	IdempotencyPurgeJob      JobConfig
+	InboundEventsPurgeJob    JobConfig
+
+	// Хранилище вложений и выгрузок: local, s3, gcs или azure
+	BlobDriver    string
+	BlobBucket    string
+	BlobLocalDir  string
+	BlobEndpoint  string
+	BlobRegion    string
+	BlobAccessKey string
+	BlobSecretKey string
+
+	// Резервные копии (server -backup): ключ шифрования AES-256 в base64, префикс ключей
+	// в хранилище файлов и срок хранения; 0 дней - копии не удаляются
+	BackupEncryptionKey string
+	BackupPrefix        string
+	BackupRetentionDays int
 }
 
+// Load читает конфигурацию из переменных окружения. Некорректные значения заменяются
+// значениями по умолчанию; проверку с ошибками выполняет LoadFile
 func Load() *Config {
+	cfg, _ := load(newSource(nil))
+	return cfg
+}
+
+// LoadFile читает конфигурацию из файла YAML или JSON path (пустой path - только окружение).
+// Переменные окружения переопределяют значения файла. Ошибка перечисляет неизвестные ключи
+// файла, некорректные и недостающие значения
+func LoadFile(path string) (*Config, error) {
+	var values map[string]string
+	if path != "" {
+		var err error
+		if values, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	s := newSource(values)
+	cfg, problems := load(s)
+	problems = append(problems, s.unknownKeys()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return cfg, nil
+}
+
+func load(s *source) (*Config, []string) {
 	cfg := &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBName:     getEnv("DB_NAME", "subscription_db"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "1234"),
-		AppPort:    getEnv("APP_PORT", "8080"),
-		LogLevel:   getLogLevel(getEnv("LOG_LEVEL", "info")),
+		DBDriver:   s.getEnv("DB_DRIVER", "postgres"),
+		SQLitePath: s.getEnv("SQLITE_PATH", "data/subscriptions.db"),
+		DBHost:     s.getEnv("DB_HOST", "localhost"),
+		DBPort:     s.getEnv("DB_PORT", "5432"),
+		DBName:     s.getEnv("DB_NAME", "subscription_db"),
+		DBUser:     s.getEnv("DB_USER", "postgres"),
+		DBPassword: s.getEnv("DB_PASSWORD", "1234"),
+		AppPort:    s.getEnv("APP_PORT", "8080"),
+		LogLevel:   getLogLevel(s.getEnv("LOG_LEVEL", "info")),
+
+		ServerReadTimeout:  s.getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerWriteTimeout: s.getEnvDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
+		ServerIdleTimeout:  s.getEnvDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+		CORSAllowedOrigins: s.getEnvList("CORS_ALLOWED_ORIGINS"),
+
+		DBMaxOpenConns:     s.getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:     s.getEnvInt("DB_MAX_IDLE_CONNS", 25),
+		DBConnMaxLifetime:  s.getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		DBStatementTimeout: s.getEnvDuration("DB_STATEMENT_TIMEOUT", 0),
+
+		DBConnectRetryInitial: s.getEnvDuration("DB_CONNECT_RETRY_INITIAL", 500*time.Millisecond),
+		DBConnectRetryMax:     s.getEnvDuration("DB_CONNECT_RETRY_MAX", 10*time.Second),
+		DBConnectMaxWait:      s.getEnvDuration("DB_CONNECT_MAX_WAIT", time.Minute),
+		DBLazyConnect:         s.getEnvBool("DB_LAZY_CONNECT", false),
+
+		ReadinessTimeout: s.getEnvDuration("READINESS_TIMEOUT", 2*time.Second),
+
+		PodName:      s.getEnv("POD_NAME", ""),
+		PodNamespace: s.getEnv("POD_NAMESPACE", ""),
+		NodeName:     s.getEnv("NODE_NAME", ""),
+
+		MsgpackEnabled: s.getEnvBool("MSGPACK_ENABLED", false),
+
+		ServerTimingEnabled: s.getEnvBool("SERVER_TIMING_ENABLED", false),
+		ResponseTimeBudget:  s.getEnvDuration("RESPONSE_TIME_BUDGET", 0),
+
+		ReportLocale:   s.getEnv("REPORT_LOCALE", "ru"),
+		UUIDVersions:   s.getEnvIntList("UUID_VERSIONS"),
+		PeriodTimezone: s.getEnv("PERIOD_TIMEZONE", "Europe/Moscow"),
+		StrictFilters:  s.getEnvBool("STRICT_FILTERS", true),
+
+		RateLimitPerMinute: s.getEnvInt("RATE_LIMIT_PER_MINUTE", 0),
+		UsageRetentionDays: s.getEnvInt("USAGE_RETENTION_DAYS", 30),
+
+		RejectedRequestsRetentionDays: s.getEnvInt("REJECTED_REQUESTS_RETENTION_DAYS", 30),
+
+		IdempotencyTTL: s.getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		InboundEventsRetentionDays: s.getEnvInt("INBOUND_EVENTS_RETENTION_DAYS", 7),
+
+		DateFormatISOTenants: s.getEnvList("DATE_FORMAT_ISO_TENANTS"),
+		DateFormatISOPercent: s.getEnvInt("DATE_FORMAT_ISO_PERCENT", 0),
+
+		AdminToken: s.getEnv("ADMIN_TOKEN", ""),
+
+		AppEnv:                  s.getEnv("APP_ENV", ""),
+		TeardownAllowProduction: s.getEnvBool("TEARDOWN_ALLOW_PRODUCTION", false),
+
+		OIDCJWKSURL:   s.getEnv("OIDC_JWKS_URL", ""),
+		OIDCIssuer:    s.getEnv("OIDC_ISSUER", ""),
+		OIDCAudience:  s.getEnv("OIDC_AUDIENCE", ""),
+		OIDCAdminRole: s.getEnv("OIDC_ADMIN_ROLE", ""),
+
+		OIDCTenantClaim: s.getEnv("OIDC_TENANT_CLAIM", ""),
+		OIDCGroupsClaim: s.getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		TenantHeader:    s.getEnv("TENANT_HEADER", "X-Tenant-ID"),
+
+		OTLPEndpoint:        s.getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		TraceServiceName:    s.getEnv("OTEL_SERVICE_NAME", "subscription-service"),
+		TraceExportInterval: s.getEnvDuration("OTEL_BSP_SCHEDULE_DELAY", 5*time.Second),
+
+		AlertRuleWindow:      s.getEnvDuration("ALERT_RULE_WINDOW", 5*time.Minute),
+		AlertFor:             s.getEnvDuration("ALERT_FOR", 10*time.Minute),
+		AlertQueryLatencyP95: s.getEnvDuration("ALERT_DB_QUERY_LATENCY_P95", 500*time.Millisecond),
+		AlertQueryRowsP95:    s.getEnvInt("ALERT_DB_QUERY_ROWS_P95", 1000),
+
+		TaxRatePercent:   s.getEnv("TAX_RATE_PERCENT", "0"),
+		PricesIncludeTax: s.getEnvBool("PRICES_INCLUDE_TAX", true),
+		RoundingMode:     s.getEnv("ROUNDING_MODE", "half_up"),
+
+		SummaryModifiers:       s.getEnvList("SUMMARY_MODIFIERS"),
+		SummaryModifierTimeout: s.getEnvDuration("SUMMARY_MODIFIER_TIMEOUT", 2*time.Second),
+
+		SummarySigningKey:   s.getEnv("SUMMARY_SIGNING_KEY", ""),
+		SummarySigningKeyID: s.getEnv("SUMMARY_SIGNING_KEY_ID", "v1"),
+
+		OutboundProxyURL:    s.getEnv("OUTBOUND_PROXY_URL", ""),
+		OutboundDialTimeout: s.getEnvDuration("OUTBOUND_DIAL_TIMEOUT", 10*time.Second),
+		EgressAllowedHosts:  s.getEnvList("EGRESS_ALLOWED_HOSTS"),
+
+		WebhookURLs:             s.getEnvList("WEBHOOK_URLS"),
+		WebhookWorkers:          s.getEnvInt("WEBHOOK_WORKERS", 16),
+		WebhookPerEndpoint:      s.getEnvInt("WEBHOOK_PER_ENDPOINT_CONCURRENCY", 2),
+		WebhookQueueSize:        s.getEnvInt("WEBHOOK_QUEUE_SIZE", 1000),
+		WebhookTimeout:          s.getEnvDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+		WebhookFailureThreshold: s.getEnvInt("WEBHOOK_FAILURE_THRESHOLD", 5),
+		WebhookCooldown:         s.getEnvDuration("WEBHOOK_COOLDOWN", time.Minute),
+
+		AnomalyThresholdPercent: s.getEnvInt("ANOMALY_THRESHOLD_PERCENT", 50),
+		AnomalyLookbackMonths:   s.getEnvInt("ANOMALY_LOOKBACK_MONTHS", 3),
+		AnomalyMinSpend:         s.getEnvInt("ANOMALY_MIN_SPEND", 1000),
+
+		SparklineCacheTTL: s.getEnvDuration("SPARKLINE_CACHE_TTL", 15*time.Minute),
+
+		RenewalReminderDays:      s.getEnvInt("RENEWAL_REMINDER_DAYS", 7),
+		RenewalReminderOpenEnded: s.getEnvBool("RENEWAL_REMINDER_OPEN_ENDED", false),
+		RenewalReminderChannels:  s.getEnvList("RENEWAL_REMINDER_CHANNELS"),
+
+		EmailDriver:    s.getEnv("EMAIL_DRIVER", ""),
+		EmailFrom:      s.getEnv("EMAIL_FROM", ""),
+		EmailFromName:  s.getEnv("EMAIL_FROM_NAME", ""),
+		EmailTimeout:   s.getEnvDuration("EMAIL_TIMEOUT", 10*time.Second),
+		SMTPHost:       s.getEnv("SMTP_HOST", ""),
+		SMTPPort:       s.getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:   s.getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:   s.getEnv("SMTP_PASSWORD", ""),
+		SendGridAPIKey: s.getEnv("SENDGRID_API_KEY", ""),
+
+		// ANOMALY_CHECK_INTERVAL оставлен для совместимости и задает расписание по умолчанию
+		AnomalyDetectionJob:      s.getJobConfig("ANOMALY_DETECTION", s.getEnvDuration("ANOMALY_CHECK_INTERVAL", 24*time.Hour)),
+		RejectedRequestsPurgeJob: s.getJobConfig("REJECTED_REQUESTS_PURGE", 24*time.Hour),
+		RenewalRemindersJob:      s.getJobConfig("RENEWAL_REMINDERS", 24*time.Hour),
+		IdempotencyPurgeJob:      s.getJobConfig("IDEMPOTENCY_PURGE", time.Hour),
+		InboundEventsPurgeJob:    s.getJobConfig("INBOUND_EVENTS_PURGE", 24*time.Hour),
+
+		BlobDriver:    s.getEnv("BLOB_DRIVER", "local"),
+		BlobBucket:    s.getEnv("BLOB_BUCKET", ""),
+		BlobLocalDir:  s.getEnv("BLOB_LOCAL_DIR", "./data/blobs"),
+		BlobEndpoint:  s.getEnv("BLOB_ENDPOINT", ""),
+		BlobRegion:    s.getEnv("BLOB_REGION", ""),
+		BlobAccessKey: s.getEnv("BLOB_ACCESS_KEY", ""),
+		BlobSecretKey: s.getEnv("BLOB_SECRET_KEY", ""),
+
+		BackupEncryptionKey: s.getEnv("BACKUP_ENCRYPTION_KEY", ""),
+		BackupPrefix:        s.getEnv("BACKUP_PREFIX", "backups"),
+		BackupRetentionDays: s.getEnvInt("BACKUP_RETENTION_DAYS", 30),
+	}
+	if len(cfg.CORSAllowedOrigins) == 0 {
+		cfg.CORSAllowedOrigins = []string{"*"}
+	}
+	if len(cfg.RenewalReminderChannels) == 0 {
+		cfg.RenewalReminderChannels = []string{"log"}
 	}
 
-	return cfg
+	problems := validate(s)
+	return cfg, append(s.invalid, problems...)
+}
+
+// minSigningKeyLength - наименьшая длина секрета подписи итогов (256 бит для HMAC-SHA256)
+const minSigningKeyLength = 32
+
+// validate проверяет значения, которые нельзя заменить значениями по умолчанию
+func validate(s *source) []string {
+	var problems []string
+	switch level := s.getEnv("LOG_LEVEL", "info"); level {
+	case "debug", "info", "warn", "error":
+	default:
+		s.reportInvalid("LOG_LEVEL", level, "debug, info, warn or error")
+	}
+	switch driver := s.getEnv("DB_DRIVER", "postgres"); driver {
+	case "postgres", "sqlite", "memory":
+	default:
+		s.reportInvalid("DB_DRIVER", driver, "postgres, sqlite or memory")
+	}
+	switch driver := s.getEnv("BLOB_DRIVER", "local"); driver {
+	case "local":
+	case "s3", "gcs", "azure":
+		if s.getEnv("BLOB_BUCKET", "") == "" {
+			problems = append(problems, fmt.Sprintf("BLOB_BUCKET: missing, required by BLOB_DRIVER=%s", driver))
+		}
+	default:
+		s.reportInvalid("BLOB_DRIVER", driver, "local, s3, gcs or azure")
+	}
+	if proxy := s.getEnv("OUTBOUND_PROXY_URL", ""); proxy != "" {
+		if _, err := egress.ParseProxyURL(proxy); err != nil {
+			s.reportInvalid("OUTBOUND_PROXY_URL", proxy, "an http://, https:// or socks5:// URL")
+		}
+	}
+	// Значение секрета не попадает в сообщение об ошибке
+	if key := s.getEnv("SUMMARY_SIGNING_KEY", ""); key != "" && len(key) < minSigningKeyLength {
+		problems = append(problems, fmt.Sprintf("SUMMARY_SIGNING_KEY: too short, expected at least %d characters", minSigningKeyLength))
+	}
+	if key := s.getEnv("BACKUP_ENCRYPTION_KEY", ""); key != "" {
+		if _, err := backup.ParseKey(key); err != nil {
+			problems = append(problems, fmt.Sprintf("BACKUP_ENCRYPTION_KEY: invalid, expected %d random bytes in base64", backup.KeySize))
+		}
+	}
+	if days := s.getEnvInt("BACKUP_RETENTION_DAYS", 30); days < 0 {
+		s.reportInvalid("BACKUP_RETENTION_DAYS", s.lookup("BACKUP_RETENTION_DAYS"), "a non-negative number of days")
+	}
+	if days := s.getEnvInt("INBOUND_EVENTS_RETENTION_DAYS", 7); days < 1 {
+		s.reportInvalid("INBOUND_EVENTS_RETENTION_DAYS", s.lookup("INBOUND_EVENTS_RETENTION_DAYS"), "a positive number of days")
+	}
+	if percent := s.getEnvInt("DATE_FORMAT_ISO_PERCENT", 0); percent < 0 || percent > 100 {
+		s.reportInvalid("DATE_FORMAT_ISO_PERCENT", s.lookup("DATE_FORMAT_ISO_PERCENT"), "a percentage from 0 to 100")
+	}
+	emailDriver := s.getEnv("EMAIL_DRIVER", "")
+	switch emailDriver {
+	case "":
+	case "smtp", "sendgrid":
+		if s.getEnv("EMAIL_FROM", "") == "" {
+			problems = append(problems, fmt.Sprintf("EMAIL_FROM: missing, required by EMAIL_DRIVER=%s", emailDriver))
+		}
+		required := "SMTP_HOST"
+		if emailDriver == "sendgrid" {
+			required = "SENDGRID_API_KEY"
+		}
+		if s.getEnv(required, "") == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing, required by EMAIL_DRIVER=%s", required, emailDriver))
+		}
+	default:
+		s.reportInvalid("EMAIL_DRIVER", emailDriver, "smtp or sendgrid")
+	}
+	for _, channel := range s.getEnvList("RENEWAL_REMINDER_CHANNELS") {
+		switch channel {
+		case "log", "webhook":
+		case "email":
+			if emailDriver == "" {
+				problems = append(problems, "EMAIL_DRIVER: missing, required by RENEWAL_REMINDER_CHANNELS=email")
+			}
+		default:
+			s.reportInvalid("RENEWAL_REMINDER_CHANNELS", channel, "a comma-separated list of log, webhook and email")
+		}
+	}
+	for _, key := range []string{"OIDC_ISSUER", "OIDC_AUDIENCE", "OIDC_ADMIN_ROLE", "OIDC_TENANT_CLAIM", "OIDC_GROUPS_CLAIM"} {
+		if s.getEnv(key, "") != "" && s.getEnv("OIDC_JWKS_URL", "") == "" {
+			problems = append(problems, fmt.Sprintf("OIDC_JWKS_URL: missing, required by %s", key))
+			break
+		}
+	}
+	return problems
 }
 
 func (c *Config) GetDBConnectionString() string {
@@ -35,11 +483,10 @@ func (c *Config) GetDBConnectionString() string {
 		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// TeardownEnabled сообщает, разрешено ли удаление данных организации: в production
+// только с TEARDOWN_ALLOW_PRODUCTION
+func (c *Config) TeardownEnabled() bool {
+	return c.AppEnv != "production" || c.TeardownAllowProduction
 }
 
 func getLogLevel(level string) slog.Level {
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/scheduler"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileYAMLWithEnvOverride(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+app:
+  port: "9090"
+db:
+  host: db.internal
+  max_open_conns: 50
+server:
+  read_timeout: 30s
+cors:
+  allowed_origins: [https://a.example, https://b.example]
+auth:
+  admin_token: secret
+features:
+  strict_filters: true
+`)
+	t.Setenv("DB_HOST", "override.internal")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.AppPort != "9090" || cfg.DBMaxOpenConns != 50 || cfg.AdminToken != "secret" || !cfg.StrictFilters {
+		t.Errorf("file values not applied: port=%s conns=%d token=%q strict=%v", cfg.AppPort, cfg.DBMaxOpenConns, cfg.AdminToken, cfg.StrictFilters)
+	}
+	if cfg.DBHost != "override.internal" {
+		t.Errorf("DBHost = %q, environment must override file", cfg.DBHost)
+	}
+	if cfg.ServerReadTimeout != 30*time.Second || cfg.ServerWriteTimeout != 15*time.Second {
+		t.Errorf("timeouts = %v/%v", cfg.ServerReadTimeout, cfg.ServerWriteTimeout)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.CORSAllowedOrigins, want) {
+		t.Errorf("CORSAllowedOrigins = %v, want %v", cfg.CORSAllowedOrigins, want)
+	}
+}
+
+func TestLoadFileJSON(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"db": {"name": "billing"}, "log": {"level": "debug"}}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.DBName != "billing" || cfg.LogLevel.String() != "DEBUG" {
+		t.Errorf("DBName = %q, LogLevel = %v", cfg.DBName, cfg.LogLevel)
+	}
+	if want := []string{"*"}; !reflect.DeepEqual(cfg.CORSAllowedOrigins, want) {
+		t.Errorf("CORSAllowedOrigins = %v, want %v", cfg.CORSAllowedOrigins, want)
+	}
+}
+
+func TestLoadFileReportsProblems(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+db:
+  max_open_conns: many
+  hots: typo
+server:
+  write_timeout: soon
+log:
+  level: verbose
+blob:
+  driver: s3
+outbound:
+  proxy_url: ftp://proxy:21
+email:
+  driver: sendgrid
+summary:
+  signing_key: secret
+backup:
+  encryption_key: c2hvcnQ=
+`)
+
+	_, err := LoadFile(path)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		`DB_MAX_OPEN_CONNS: invalid value "many"`,
+		"DB_HOTS: unknown key",
+		`SERVER_WRITE_TIMEOUT: invalid value "soon"`,
+		`LOG_LEVEL: invalid value "verbose"`,
+		"BLOB_BUCKET: missing, required by BLOB_DRIVER=s3",
+		`OUTBOUND_PROXY_URL: invalid value "ftp://proxy:21"`,
+		"EMAIL_FROM: missing, required by EMAIL_DRIVER=sendgrid",
+		"SENDGRID_API_KEY: missing, required by EMAIL_DRIVER=sendgrid",
+		"SUMMARY_SIGNING_KEY: too short",
+		"BACKUP_ENCRYPTION_KEY: invalid",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "absent.yaml")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestJobConfigDisabledByZeroInterval(t *testing.T) {
+	t.Setenv("ANOMALY_CHECK_INTERVAL", "0")
+
+	cfg, err := LoadFile(writeConfig(t, "config.yaml", "app:\n  port: \"8080\"\n"))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	job := cfg.AnomalyDetectionJob
+	if job.Enabled {
+		t.Error("anomaly detection job enabled with ANOMALY_CHECK_INTERVAL=0")
+	}
+	// Планировщик разбирает расписание и отключенной задачи: "@every 0s" не давал сервису стартовать
+	if _, err := scheduler.Parse(job.Schedule); err != nil {
+		t.Errorf("schedule %q of disabled job: %v", job.Schedule, err)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// fileSections - разделы файла конфигурации, ключи которых совпадают с именами
+// переменных окружения без префикса раздела: auth.admin_token - ADMIN_TOKEN,
+// features.msgpack_enabled - MSGPACK_ENABLED. Ключи остальных разделов получают
+// префикс: db.host - DB_HOST, server.read_timeout - SERVER_READ_TIMEOUT
+var fileSections = map[string]bool{
+	"auth":     true,
+	"features": true,
+}
+
+// source - значения конфигурации: переменные окружения, затем файл. Запоминает
+// прочитанные ключи и некорректные значения
+type source struct {
+	file    map[string]string
+	used    map[string]bool
+	invalid []string
+}
+
+func newSource(file map[string]string) *source {
+	return &source{file: file, used: make(map[string]bool)}
+}
+
+func (s *source) lookup(key string) string {
+	s.used[key] = true
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return s.file[key]
+}
+
+func (s *source) reportInvalid(key, value, expected string) {
+	s.invalid = append(s.invalid, fmt.Sprintf("%s: invalid value %q, expected %s", key, value, expected))
+}
+
+// unknownKeys возвращает ключи файла, которые конфигурация не читает, - скорее всего опечатки
+func (s *source) unknownKeys() []string {
+	var unknown []string
+	for key := range s.file {
+		if !s.used[key] {
+			unknown = append(unknown, fmt.Sprintf("%s: unknown key", key))
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func (s *source) getEnv(key, defaultValue string) string {
+	if value := s.lookup(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvBool(key string, defaultValue bool) bool {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		s.reportInvalid(key, value, "true or false")
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvInt(key string, defaultValue int) int {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		s.reportInvalid(key, value, "an integer")
+	}
+	return defaultValue
+}
+
+// getEnvIntList читает список целых через запятую; нечисловые элементы пропускаются
+func (s *source) getEnvIntList(key string) []int {
+	var values []int
+	for _, item := range strings.Split(s.lookup(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if parsed, err := strconv.Atoi(item); err == nil {
+			values = append(values, parsed)
+		} else {
+			s.reportInvalid(key, item, "a comma-separated list of integers")
+		}
+	}
+	return values
+}
+
+func (s *source) getEnvList(key string) []string {
+	var values []string
+	for _, item := range strings.Split(s.lookup(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func (s *source) getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		s.reportInvalid(key, value, "a duration such as 30s or 5m")
+	}
+	return defaultValue
+}
+
+// getJobConfig читает JOB_<NAME>_SCHEDULE, JOB_<NAME>_ENABLED и JOB_<NAME>_JITTER.
+// Без явного расписания задача запускается с интервалом defaultInterval,
+// неположительный интервал по умолчанию отключает ее. Расписание отключенной задачи
+// все равно разбирается планировщиком, поэтому для нее по умолчанию берется @daily
+func (s *source) getJobConfig(name string, defaultInterval time.Duration) JobConfig {
+	prefix := "JOB_" + name + "_"
+
+	defaultSchedule := "@daily"
+	if defaultInterval > 0 {
+		defaultSchedule = "@every " + defaultInterval.String()
+	}
+	return JobConfig{
+		Schedule: s.getEnv(prefix+"SCHEDULE", defaultSchedule),
+		Enabled:  s.getEnvBool(prefix+"ENABLED", defaultInterval > 0),
+		Jitter:   s.getEnvDuration(prefix+"JITTER", 0),
+	}
+}
+
+// readFile читает файл конфигурации YAML или JSON (JSON - частный случай YAML)
+// и переводит вложенные ключи в имена переменных окружения
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", filepath.Base(path), err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(values, "", tree, true); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", filepath.Base(path), err)
+	}
+	return values, nil
+}
+
+func flatten(values map[string]string, prefix string, node map[string]interface{}, top bool) error {
+	for name, value := range node {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			childPrefix := key
+			if top && fileSections[strings.ToLower(name)] {
+				childPrefix = ""
+			}
+			if err := flatten(values, childPrefix, v, false); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				if _, nested := item.(map[string]interface{}); nested {
+					return fmt.Errorf("%s: lists may contain only scalar values", key)
+				}
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
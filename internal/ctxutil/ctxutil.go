@@ -0,0 +1,87 @@
+// Package ctxutil хранит в контексте значения запроса: его идентификатор, пользователя,
+// организацию и язык отчетов. Ключи контекста объявлены только здесь, поэтому
+// middleware, логгер, сервисы и репозитории читают одни и те же значения
+package ctxutil
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Zipklas/subscription-service/internal/i18n"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+type (
+	requestIDKey struct{}
+	userIDKey    struct{}
+	tenantIDKey  struct{}
+	localeKey    struct{}
+)
+
+// WithRequestID возвращает контекст с идентификатором HTTP-запроса
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса; вне HTTP-запроса - пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithUserID возвращает контекст с аутентифицированным пользователем запроса
+func WithUserID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserID возвращает пользователя запроса; false - запрос без пользователя
+// (без аутентификации или с административным токеном)
+func UserID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(userIDKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+// WithTenantID возвращает контекст с организацией запроса
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, id)
+}
+
+// TenantID возвращает организацию запроса; без нее - tenant.Default
+func TenantID(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return tenant.Default
+}
+
+// WithLocale возвращает контекст с языком отчетов запроса
+func WithLocale(ctx context.Context, locale i18n.Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale возвращает язык отчетов запроса; без него - i18n.Default
+func Locale(ctx context.Context) i18n.Locale {
+	if locale, ok := ctx.Value(localeKey{}).(i18n.Locale); ok {
+		return locale
+	}
+	return i18n.Default
+}
+
+// LogAttrs возвращает атрибуты записи лога из значений, явно сохраненных в контексте:
+// request_id, tenant_id и user_id. Организация по умолчанию не добавляется, чтобы
+// записи фоновых задач не выглядели записями организации default
+func LogAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if id, ok := ctx.Value(tenantIDKey{}).(string); ok && id != "" {
+		attrs = append(attrs, slog.String("tenant_id", id))
+	}
+	if id, ok := UserID(ctx); ok {
+		attrs = append(attrs, slog.String("user_id", id.String()))
+	}
+	return attrs
+}
@@ -0,0 +1,67 @@
+package ctxutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/i18n"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+func TestValues(t *testing.T) {
+	ctx := context.Background()
+	if got := RequestID(ctx); got != "" {
+		t.Errorf("RequestID() without id = %q, want empty", got)
+	}
+	if _, ok := UserID(ctx); ok {
+		t.Error("UserID() without user ok = true, want false")
+	}
+	if got := TenantID(ctx); got != tenant.Default {
+		t.Errorf("TenantID() without tenant = %q, want %q", got, tenant.Default)
+	}
+	if got := Locale(ctx); got != i18n.Default {
+		t.Errorf("Locale() without locale = %q, want %q", got, i18n.Default)
+	}
+
+	userID := uuid.New()
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithUserID(ctx, userID)
+	ctx = WithTenantID(ctx, "acme")
+	ctx = WithLocale(ctx, i18n.EN)
+	if got := RequestID(ctx); got != "req-1" {
+		t.Errorf("RequestID() = %q, want req-1", got)
+	}
+	if got, ok := UserID(ctx); !ok || got != userID {
+		t.Errorf("UserID() = %s, %v, want %s", got, ok, userID)
+	}
+	if got := TenantID(ctx); got != "acme" {
+		t.Errorf("TenantID() = %q, want acme", got)
+	}
+	if got := Locale(ctx); got != i18n.EN {
+		t.Errorf("Locale() = %q, want en", got)
+	}
+}
+
+func TestLogAttrs(t *testing.T) {
+	if attrs := LogAttrs(WithTenantID(context.Background(), "")); len(attrs) != 0 {
+		t.Errorf("LogAttrs() without values = %v, want none", attrs)
+	}
+
+	userID := uuid.New()
+	ctx := WithUserID(WithTenantID(WithRequestID(context.Background(), "req-1"), "acme"), userID)
+	got := map[string]string{}
+	for _, attr := range LogAttrs(ctx) {
+		got[attr.Key] = attr.Value.String()
+	}
+	want := map[string]string{"request_id": "req-1", "tenant_id": "acme", "user_id": userID.String()}
+	if len(got) != len(want) {
+		t.Fatalf("LogAttrs() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("LogAttrs()[%s] = %q, want %q", key, got[key], value)
+		}
+	}
+}
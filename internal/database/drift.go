@@ -0,0 +1,220 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// expectedColumns - колонки таблиц, которые читают и пишут репозитории, в состоянии
+// после последней миграции. Миграция, добавляющая таблицу или колонку, дополняет список
+var expectedColumns = map[string][]string{
+	"subscriptions": {
+		"id", "service_name", "monthly_cost", "user_id", "start_date", "end_date", "created_at", "updated_at",
+		"is_draft", "change_seq", "prepaid_amount", "status", "cancel_reason", "cancelled_at", "tenant_id",
+		"metadata", "service_id", "description", "billing_period", "cost",
+	},
+	"subscription_changes":           {"seq", "subscription_id", "operation", "payload", "previous", "changed_at", "tenant_id"},
+	"email_templates":                {"id", "name", "version", "subject", "body", "created_at"},
+	"discounts":                      {"id", "kind", "value", "subscription_id", "user_id", "promo_code", "start_date", "end_date", "created_at", "tenant_id"},
+	"invoices":                       {"id", "user_id", "period", "tax_rate", "base_total", "discount_total", "net_total", "tax_total", "gross_total", "created_at", "tenant_id"},
+	"invoice_lines":                  {"invoice_id", "line_no", "subscription_id", "service_name", "base_amount", "discount_amount", "net_amount", "tax_amount", "gross_amount"},
+	"subscription_pauses":            {"id", "subscription_id", "start_date", "end_date"},
+	"subscription_transfers":         {"id", "subscription_id", "from_user_id", "to_user_id", "reason", "transferred_at"},
+	"rejected_requests":              {"id", "tenant_id", "user_id", "method", "route", "status", "reason", "message", "created_at"},
+	"renewal_reminders":              {"subscription_id", "renews_at", "kind", "sent_at"},
+	"notification_preferences":       {"tenant_id", "user_id", "email", "renewal_reminders", "cancellations", "updated_at"},
+	"audit_log":                      {"id", "tenant_id", "entity_type", "entity_id", "user_id", "action", "actor", "request_id", "before", "after", "created_at"},
+	"idempotency_keys":               {"tenant_id", "actor", "idempotency_key", "fingerprint", "status", "response_status", "content_type", "response_body", "locked_until", "created_at", "expires_at"},
+	"user_notifications":             {"id", "tenant_id", "user_id", "kind", "subscription_id", "message", "data", "dedup_key", "created_at", "read_at"},
+	"services":                       {"id", "tenant_id", "name", "category", "default_monthly_cost", "icon_url", "created_at", "updated_at"},
+	"tags":                           {"id", "tenant_id", "name", "created_at"},
+	"subscription_tags":              {"subscription_id", "tag_id"},
+	"subscription_reminder_settings": {"subscription_id", "days", "channel", "updated_at"},
+	"subscription_prices":            {"subscription_id", "effective_from", "monthly_cost", "billing_period", "cost", "created_at"},
+	"inbound_events":                 {"tenant_id", "source", "event_id", "type", "status", "locked_until", "subscription_id", "received_at", "processed_at", "duplicates", "last_duplicate_at"},
+	"api_tokens":                     {"id", "tenant_id", "user_id", "name", "token_hash", "scopes", "created_at", "expires_at"},
+	"sso_group_mappings":             {"group_name", "tenant_id", "role", "created_at", "updated_at"},
+	"tenants":                        {"id", "name", "created_at", "updated_at", "tax_rate_percent", "rounding_mode"},
+	"backups":                        {"id", "blob_key", "status", "started_at", "finished_at", "snapshot_at", "wal_lsn", "table_rows", "size_bytes", "sha256", "error", "expires_at"},
+}
+
+// expectedIndexes - индексы, на которые рассчитаны запросы репозиториев, по таблицам.
+// Без них запросы не падают, но переходят на полный просмотр таблицы
+var expectedIndexes = map[string][]string{
+	"subscriptions": {
+		"idx_subscriptions_service_name", "idx_subscriptions_dates", "idx_subscriptions_is_draft",
+		"idx_subscriptions_change_seq", "idx_subscriptions_service_name_trgm", "idx_subscriptions_status",
+		"idx_subscriptions_tenant_user", "idx_subscriptions_tenant_created_at_id", "idx_subscriptions_metadata",
+		"idx_subscriptions_service_id",
+	},
+	"subscription_changes":   {"idx_subscription_changes_subscription_id", "idx_subscription_changes_tenant_seq"},
+	"discounts":              {"idx_discounts_subscription_id", "idx_discounts_tenant_user"},
+	"invoices":               {"invoices_tenant_user_period_key"},
+	"subscription_pauses":    {"idx_subscription_pauses_subscription_id"},
+	"subscription_transfers": {"idx_subscription_transfers_subscription_id"},
+	"rejected_requests":      {"idx_rejected_requests_tenant_created_at", "idx_rejected_requests_tenant_user"},
+	"audit_log":              {"idx_audit_log_tenant_entity", "idx_audit_log_tenant_id", "idx_audit_log_tenant_user"},
+	"idempotency_keys":       {"idx_idempotency_keys_expires_at"},
+	"inbound_events":         {"idx_inbound_events_processed_at", "idx_inbound_events_last_duplicate"},
+	"api_tokens":             {"api_tokens_token_hash_key", "idx_api_tokens_tenant_user"},
+	"sso_group_mappings":     {"sso_group_mappings_pkey"},
+	"tenants":                {"tenants_pkey"},
+	"user_notifications":     {"idx_user_notifications_tenant_user", "idx_user_notifications_unread", "idx_user_notifications_dedup"},
+	"services":               {"idx_services_tenant_name", "idx_services_tenant_category"},
+	"tags":                   {"tags_tenant_id_name_key"},
+	"subscription_tags":      {"subscription_tags_pkey", "idx_subscription_tags_tag_id"},
+	"subscription_prices":    {"subscription_prices_pkey"},
+	"backups":                {"idx_backups_started_at", "idx_backups_expires_at"},
+}
+
+// DriftDetector сравнивает схему базы с ожидаемой и хранит результат последней проверки
+// для /metrics и административного API
+type DriftDetector struct {
+	db *sql.DB
+
+	mu   sync.Mutex
+	last *model.SchemaDrift
+}
+
+func NewDriftDetector(db *sql.DB) *DriftDetector {
+	return &DriftDetector{db: db}
+}
+
+// Detect читает таблицы, колонки и индексы из каталога Postgres и возвращает
+// отсутствующие в базе объекты
+func (d *DriftDetector) Detect(ctx context.Context) (model.SchemaDrift, error) {
+	tables := make([]string, 0, len(expectedColumns))
+	for table := range expectedColumns {
+		tables = append(tables, table)
+	}
+
+	columns, err := d.readNames(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, tables)
+	if err != nil {
+		return model.SchemaDrift{}, fmt.Errorf("failed to read schema columns: %w", err)
+	}
+	indexes, err := d.readNames(ctx, `
+		SELECT tablename, indexname
+		FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename = ANY($1)
+	`, tables)
+	if err != nil {
+		return model.SchemaDrift{}, fmt.Errorf("failed to read schema indexes: %w", err)
+	}
+
+	drift := compareSchema(columns, indexes)
+	drift.CheckedAt = time.Now().UTC()
+
+	d.mu.Lock()
+	d.last = &drift
+	d.mu.Unlock()
+
+	return drift, nil
+}
+
+// Last возвращает результат последней проверки; false, если проверок еще не было
+func (d *DriftDetector) Last() (model.SchemaDrift, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.last == nil {
+		return model.SchemaDrift{}, false
+	}
+	return *d.last, true
+}
+
+// readNames возвращает множество пар "таблица.объект" из запроса с двумя колонками
+func (d *DriftDetector) readNames(ctx context.Context, query string, tables []string) (map[string]bool, error) {
+	rows, err := d.db.QueryContext(ctx, query, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var table, name string
+		if err := rows.Scan(&table, &name); err != nil {
+			return nil, err
+		}
+		names[table+"."+name] = true
+	}
+	return names, rows.Err()
+}
+
+// compareSchema сравнивает найденные колонки и индексы ("таблица.имя") с ожидаемыми.
+// Таблица без единой колонки считается отсутствующей целиком и не дублируется
+// в списках колонок и индексов
+func compareSchema(columns, indexes map[string]bool) model.SchemaDrift {
+	drift := model.SchemaDrift{
+		MissingTables:  []string{},
+		MissingColumns: []string{},
+		MissingIndexes: []string{},
+	}
+
+	// Колонки незавершенных изменений проверяет CheckPhases: после contract старой колонки нет
+	changing := make(map[string]bool)
+	for _, t := range transitions {
+		changing[t.Table+"."+t.OldColumn] = true
+		changing[t.Table+"."+t.NewColumn] = true
+	}
+
+	missingTables := make(map[string]bool)
+	for table, names := range expectedColumns {
+		var missing []string
+		for _, column := range names {
+			if !columns[table+"."+column] && !changing[table+"."+column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+		if len(missing) == len(names) {
+			missingTables[table] = true
+			drift.MissingTables = append(drift.MissingTables, table)
+			continue
+		}
+		drift.MissingColumns = append(drift.MissingColumns, missing...)
+	}
+	for table, names := range expectedIndexes {
+		if missingTables[table] {
+			continue
+		}
+		for _, index := range names {
+			if !indexes[table+"."+index] {
+				drift.MissingIndexes = append(drift.MissingIndexes, index)
+			}
+		}
+	}
+
+	sort.Strings(drift.MissingTables)
+	sort.Strings(drift.MissingColumns)
+	sort.Strings(drift.MissingIndexes)
+	drift.Drifted = len(drift.MissingTables)+len(drift.MissingColumns)+len(drift.MissingIndexes) > 0
+	return drift
+}
+
+// WritePrometheus пишет число отсутствующих объектов схемы по видам по результату
+// последней проверки; до первой проверки метрика не выводится
+func (d *DriftDetector) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# HELP %s Schema objects expected by the service but missing in the database.\n", metrics.SchemaDriftMetric)
+	fmt.Fprintf(bw, "# TYPE %s gauge\n", metrics.SchemaDriftMetric)
+	if drift, ok := d.Last(); ok {
+		fmt.Fprintf(bw, "%s{kind=\"table\"} %d\n", metrics.SchemaDriftMetric, len(drift.MissingTables))
+		fmt.Fprintf(bw, "%s{kind=\"column\"} %d\n", metrics.SchemaDriftMetric, len(drift.MissingColumns))
+		fmt.Fprintf(bw, "%s{kind=\"index\"} %d\n", metrics.SchemaDriftMetric, len(drift.MissingIndexes))
+	}
+
+	return bw.Flush()
+}
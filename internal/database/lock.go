@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// unlockTimeout ограничивает снятие блокировки после задачи, контекст которой мог быть отменен
+const unlockTimeout = 5 * time.Second
+
+// AdvisoryLocker выбирает реплику, выполняющую задачу, блокировкой pg_try_advisory_lock.
+// Блокировка сессионная, поэтому держится на выделенном соединении до снятия
+type AdvisoryLocker struct {
+	pool *Pool
+}
+
+func NewAdvisoryLocker(pool *Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{pool: pool}
+}
+
+// TryLock берет блокировку name без ожидания. false - блокировку держит другая реплика.
+// unlock снимает блокировку и возвращает соединение в пул
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	key := int64(h.Sum64())
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection for lock %s: %w", name, err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			// Закрытое соединение пул не выдает повторно, блокировку снимет закрытие сессии
+			conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// analyzeTables - таблицы подписок, для которых администратор может запустить ANALYZE.
+// Запросы списков и итогов читают их, и план зависит от свежести их статистики
+var analyzeTables = []string{"subscriptions", "subscription_changes", "subscription_pauses", "subscription_transfers", "subscription_prices"}
+
+// Maintenance читает статистику таблиц и индексов сервиса и обновляет статистику
+// планировщика без прямого доступа к базе
+type Maintenance struct {
+	db *sql.DB
+}
+
+func NewMaintenance(db *sql.DB) *Maintenance {
+	return &Maintenance{db: db}
+}
+
+// serviceTables возвращает таблицы, с которыми работают репозитории, по expectedColumns
+func serviceTables() []string {
+	tables := make([]string, 0, len(expectedColumns))
+	for table := range expectedColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// BackupTables возвращает таблицы сервиса для логической резервной копии: все, кроме
+// журнала самих копий, который меняется во время копирования
+func BackupTables() []string {
+	tables := serviceTables()
+	for i, table := range tables {
+		if table == "backups" {
+			return append(tables[:i], tables[i+1:]...)
+		}
+	}
+	return tables
+}
+
+// TableStats возвращает размер, мертвые строки и время очистки и анализа таблиц сервиса,
+// начиная с самых больших
+func (m *Maintenance) TableStats(ctx context.Context) ([]model.TableStats, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT relname, n_live_tup, n_dead_tup,
+			pg_total_relation_size(relid), pg_indexes_size(relid),
+			COALESCE(seq_scan, 0), COALESCE(idx_scan, 0), n_mod_since_analyze,
+			last_vacuum, last_autovacuum, last_analyze, last_autoanalyze
+		FROM pg_stat_user_tables
+		WHERE schemaname = current_schema() AND relname = ANY($1)
+		ORDER BY pg_total_relation_size(relid) DESC, relname
+	`, serviceTables())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []model.TableStats{}
+	for rows.Next() {
+		var s model.TableStats
+		err := rows.Scan(
+			&s.Table,
+			&s.LiveRows,
+			&s.DeadRows,
+			&s.TotalBytes,
+			&s.IndexBytes,
+			&s.SeqScans,
+			&s.IndexScans,
+			&s.ModifiedSinceAnalyze,
+			&s.LastVacuum,
+			&s.LastAutovacuum,
+			&s.LastAnalyze,
+			&s.LastAutoanalyze,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table stats: %w", err)
+		}
+		if total := s.LiveRows + s.DeadRows; total > 0 {
+			s.DeadRatio = float64(s.DeadRows) / float64(total)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read table stats: %w", err)
+	}
+	return stats, nil
+}
+
+// IndexStats возвращает использование индексов таблиц сервиса, начиная с реже всего
+// просматриваемых
+func (m *Maintenance) IndexStats(ctx context.Context) ([]model.IndexStats, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT s.relname, s.indexrelname, s.idx_scan, s.idx_tup_read, s.idx_tup_fetch,
+			pg_relation_size(s.indexrelid), i.indisunique
+		FROM pg_stat_user_indexes s
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		WHERE s.schemaname = current_schema() AND s.relname = ANY($1)
+		ORDER BY s.idx_scan, pg_relation_size(s.indexrelid) DESC, s.indexrelname
+	`, serviceTables())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []model.IndexStats{}
+	for rows.Next() {
+		var s model.IndexStats
+		if err := rows.Scan(&s.Table, &s.Index, &s.Scans, &s.TuplesRead, &s.TuplesFetched, &s.Bytes, &s.Unique); err != nil {
+			return nil, fmt.Errorf("failed to scan index stats: %w", err)
+		}
+		s.Unused = s.Scans == 0 && !s.Unique
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Analyze выполняет ANALYZE таблиц tables по очереди; без tables - всех analyzeTables.
+// Таблицы не из analyzeTables отклоняются до обращения к базе
+func (m *Maintenance) Analyze(ctx context.Context, tables []string) ([]model.AnalyzedTable, error) {
+	tables, err := analyzeTargets(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.AnalyzedTable, 0, len(tables))
+	for _, table := range tables {
+		start := time.Now()
+		if _, err := m.db.ExecContext(ctx, "ANALYZE "+pgx.Identifier{table}.Sanitize()); err != nil {
+			return result, fmt.Errorf("failed to analyze %s: %w", table, err)
+		}
+		result = append(result, model.AnalyzedTable{Table: table, DurationMs: time.Since(start).Milliseconds()})
+	}
+	return result, nil
+}
+
+// analyzeTargets проверяет запрошенные таблицы и убирает повторы
+func analyzeTargets(tables []string) ([]string, error) {
+	if len(tables) == 0 {
+		return analyzeTables, nil
+	}
+
+	allowed := make(map[string]bool, len(analyzeTables))
+	for _, table := range analyzeTables {
+		allowed[table] = true
+	}
+	seen := make(map[string]bool, len(tables))
+	targets := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !allowed[table] {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid table %q: expected one of %s", table, strings.Join(analyzeTables, ", "))
+		}
+		if !seen[table] {
+			seen[table] = true
+			targets = append(targets, table)
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestAnalyzeTargets(t *testing.T) {
+	all, err := analyzeTargets(nil)
+	if err != nil || !reflect.DeepEqual(all, analyzeTables) {
+		t.Errorf("analyzeTargets(nil) = %v, %v, want all subscription tables", all, err)
+	}
+
+	got, err := analyzeTargets([]string{"subscription_pauses", "subscriptions", "subscription_pauses"})
+	if err != nil || !reflect.DeepEqual(got, []string{"subscription_pauses", "subscriptions"}) {
+		t.Errorf("analyzeTargets() = %v, %v, want requested tables without repeats", got, err)
+	}
+
+	// Имя таблицы попадает в текст запроса, поэтому принимаются только известные таблицы
+	for _, table := range []string{"audit_log", `subscriptions"; DROP TABLE subscriptions; --`} {
+		if _, err := analyzeTargets([]string{table}); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("analyzeTargets(%q) error = %v, want ErrInvalidInput", table, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Phase - состояние схемы в изменении expand/contract. Переименование или смена типа
+// колонки выполняется в два шага: expand добавляет новую колонку рядом со старой,
+// contract после выката всех реплик удаляет старую
+type Phase string
+
+const (
+	// PhasePending - expand еще не применен, есть только старая колонка
+	PhasePending Phase = "pending"
+	// PhaseExpanded - есть обе колонки
+	PhaseExpanded Phase = "expanded"
+	// PhaseContracted - contract применен, осталась только новая колонка
+	PhaseContracted Phase = "contracted"
+)
+
+// Transition - незавершенное изменение колонки и фазы схемы, с которыми работает
+// эта сборка сервиса. Сборка, которая пишет в обе колонки, совместима с expanded,
+// следующая сборка, читающая только новую колонку, - с expanded и contracted
+type Transition struct {
+	Migration  string  `json:"migration"`
+	Table      string  `json:"table"`
+	OldColumn  string  `json:"old_column"`
+	NewColumn  string  `json:"new_column"`
+	Compatible []Phase `json:"compatible"`
+}
+
+// transitions - изменения схемы, которые сейчас выкатываются. Миграция expand кладется
+// в migrations/ и применяется вместе с остальными, миграция contract - в migrations/contract/
+// и применяется вручную, когда ни одна запущенная сборка не требует старой колонки.
+// После contract изменение удаляется из списка, а новая колонка попадает в expectedColumns
+var transitions = []Transition{}
+
+// Transitions возвращает незавершенные изменения схемы и совместимые с этой сборкой
+// фазы, например, чтобы конвейер выката решил, можно ли применять contract
+func Transitions() []Transition {
+	return slices.Clone(transitions)
+}
+
+// CheckPhases проверяет, что каждое незавершенное изменение схемы находится в фазе,
+// с которой работает эта сборка
+func CheckPhases(ctx context.Context, db *sql.DB) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+	tables := make([]string, 0, len(transitions))
+	for _, t := range transitions {
+		tables = append(tables, t.Table)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, tables)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("failed to scan schema column: %w", err)
+		}
+		columns[table+"."+column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	return incompatiblePhases(columns, transitions)
+}
+
+// phaseOf определяет фазу изменения по колонкам таблицы; false - нет ни одной из колонок
+func phaseOf(columns map[string]bool, t Transition) (Phase, bool) {
+	hasOld, hasNew := columns[t.Table+"."+t.OldColumn], columns[t.Table+"."+t.NewColumn]
+	switch {
+	case hasOld && hasNew:
+		return PhaseExpanded, true
+	case hasOld:
+		return PhasePending, true
+	case hasNew:
+		return PhaseContracted, true
+	default:
+		return "", false
+	}
+}
+
+func incompatiblePhases(columns map[string]bool, transitions []Transition) error {
+	var problems []string
+	for _, t := range transitions {
+		phase, ok := phaseOf(columns, t)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s (%s: neither %s nor %s exists)", t.Migration, t.Table, t.OldColumn, t.NewColumn))
+			continue
+		}
+		if !slices.Contains(t.Compatible, phase) {
+			problems = append(problems, fmt.Sprintf("%s (%s.%s -> %s is %s, supported: %s)",
+				t.Migration, t.Table, t.OldColumn, t.NewColumn, phase, joinPhases(t.Compatible)))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("schema phase is not supported by this build: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func joinPhases(phases []Phase) string {
+	names := make([]string, len(phases))
+	for i, p := range phases {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ",")
+}
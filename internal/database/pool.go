@@ -0,0 +1,224 @@
+// Package database открывает пул соединений с Postgres и позволяет менять его
+// параметры (размеры пула, statement_timeout) без перезапуска сервиса.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Settings - параметры пула. Нулевые длительности означают "без ограничения"
+type Settings struct {
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+}
+
+func (s Settings) validate() error {
+	if s.MaxOpenConns < 1 {
+		return fmt.Errorf("max_open_conns must be positive")
+	}
+	if s.MaxIdleConns < 0 || s.MaxIdleConns > s.MaxOpenConns {
+		return fmt.Errorf("max_idle_conns must be between 0 and max_open_conns")
+	}
+	if s.ConnMaxLifetime < 0 || s.ConnMaxIdleTime < 0 || s.StatementTimeout < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	return nil
+}
+
+// unlimited заменяет нулевые длительности: pgxpool считает нулевой срок жизни истекшим сразу
+const unlimited = 100 * 365 * 24 * time.Hour
+
+// Pool - пул соединений pgxpool с изменяемыми во время работы настройками. Работающий
+// pgxpool не меняет размеры и таймауты, поэтому Update создает новый пул, а прежний
+// закрывается, когда к нему вернутся выданные соединения
+type Pool struct {
+	// DB - database/sql поверх текущего пула для репозиториев, которые не работают с pgx напрямую
+	DB *sql.DB
+
+	// config - разобранная строка подключения, общая для всех пулов
+	config  *pgxpool.Config
+	current atomic.Pointer[pgxpool.Pool]
+
+	mu       sync.Mutex
+	settings Settings
+
+	// ready выставляется после первой успешной проверки подключения
+	ready atomic.Bool
+}
+
+// Open открывает пул и проверяет подключение
+func Open(ctx context.Context, dsn string, settings Settings) (*Pool, error) {
+	p, err := New(dsn, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Ping(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	p.ready.Store(true)
+
+	return p, nil
+}
+
+// New создает пул без подключения к базе: соединения открываются при первом запросе
+// или при вызове WaitReady
+func New(dsn string, settings Settings) (*Pool, error) {
+	if err := settings.validate(); err != nil {
+		return nil, fmt.Errorf("invalid database pool settings: %w", err)
+	}
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	config.ConnConfig.Tracer = queryTracer{}
+
+	p := &Pool{config: config}
+	if err := p.apply(settings); err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	p.DB = sql.OpenDB(poolConnector{pool: p})
+	// Простаивающие соединения держит pgxpool: database/sql возвращает соединение сразу после запроса
+	p.DB.SetMaxIdleConns(0)
+
+	return p, nil
+}
+
+// WaitReady проверяет подключение к базе, повторяя попытки с задержкой b
+func (p *Pool) WaitReady(ctx context.Context, b Backoff, onRetry func(attempt int, delay time.Duration, err error)) error {
+	err := Retry(ctx, b, p.Ping, onRetry)
+	if err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	p.ready.Store(true)
+	return nil
+}
+
+// Ready сообщает, что подключение к базе хотя бы раз удалось
+func (p *Pool) Ready() bool {
+	return p.ready.Load()
+}
+
+// Settings возвращает текущие настройки пула
+func (p *Pool) Settings() Settings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.settings
+}
+
+// Update применяет новые настройки к работающему пулу. Выполняющиеся запросы
+// не прерываются: соединения прежнего пула закрываются по мере их возврата
+func (p *Pool) Update(settings Settings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.apply(settings)
+}
+
+// apply создает пул с настройками settings и заменяет им текущий
+func (p *Pool) apply(settings Settings) error {
+	config := p.config.Copy()
+	config.MaxConns = int32(settings.MaxOpenConns)
+	config.MaxConnLifetime = orUnlimited(settings.ConnMaxLifetime)
+	config.MaxConnIdleTime = orUnlimited(settings.ConnMaxIdleTime)
+	// Параметр передается при открытии соединения, поэтому действует и на запросы database/sql
+	config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(settings.StatementTimeout.Milliseconds(), 10)
+
+	// pgxpool не ограничивает простаивающие соединения: соединение сверх MaxIdleConns
+	// закрывается при возврате в пул
+	var pool *pgxpool.Pool
+	maxIdle := int32(settings.MaxIdleConns)
+	config.AfterRelease = func(*pgx.Conn) bool {
+		return pool.Stat().IdleConns() < maxIdle
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	if previous := p.current.Swap(pool); previous != nil {
+		// Close ждет возврата выданных соединений
+		go previous.Close()
+	}
+	p.settings = settings
+	return nil
+}
+
+func orUnlimited(d time.Duration) time.Duration {
+	if d == 0 {
+		return unlimited
+	}
+	return d
+}
+
+// Ping проверяет подключение, получая соединение из пула
+func (p *Pool) Ping(ctx context.Context) error {
+	return p.current.Load().Ping(ctx)
+}
+
+// Acquire выдает соединение текущего пула; его нужно вернуть через Release
+func (p *Pool) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	return p.current.Load().Acquire(ctx)
+}
+
+// Begin начинает транзакцию на соединении текущего пула
+func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.current.Load().Begin(ctx)
+}
+
+func (p *Pool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return p.current.Load().Exec(ctx, sql, args...)
+}
+
+func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return p.current.Load().Query(ctx, sql, args...)
+}
+
+func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return p.current.Load().QueryRow(ctx, sql, args...)
+}
+
+// Stats возвращает статистику текущего пула; счетчики ведутся с последнего изменения настроек
+func (p *Pool) Stats() *pgxpool.Stat {
+	return p.current.Load().Stat()
+}
+
+// Close закрывает все соединения пула
+func (p *Pool) Close() error {
+	err := p.DB.Close()
+	p.current.Load().Close()
+	return err
+}
+
+// poolConnector выдает database/sql соединения текущего пула pgxpool
+type poolConnector struct {
+	pool *Pool
+}
+
+func (c poolConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return stdlib.GetPoolConnector(c.pool.current.Load()).Connect(ctx)
+}
+
+func (c poolConnector) Driver() driver.Driver {
+	return stdlib.GetDefaultDriver()
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolUpdateReplacesPool(t *testing.T) {
+	// Пул не подключается к базе до первого запроса, поэтому адрес может быть недоступен
+	p, err := New("host=127.0.0.1 port=1 user=test dbname=test sslmode=disable", Settings{MaxOpenConns: 4, MaxIdleConns: 2, StatementTimeout: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+
+	previous := p.current.Load()
+	if got := previous.Config().ConnConfig.RuntimeParams["statement_timeout"]; got != "30000" {
+		t.Errorf("statement_timeout = %q, want 30000", got)
+	}
+	if got := previous.Config().MaxConnLifetime; got != unlimited {
+		t.Errorf("MaxConnLifetime = %s, want unlimited", got)
+	}
+
+	settings := Settings{MaxOpenConns: 10, MaxIdleConns: 5, ConnMaxLifetime: time.Minute, StatementTimeout: 5 * time.Second}
+	if err := p.Update(settings); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	current := p.current.Load()
+	if current == previous {
+		t.Fatal("Update() kept the previous pool")
+	}
+	config := current.Config()
+	if config.MaxConns != 10 || config.MaxConnLifetime != time.Minute || config.ConnConfig.RuntimeParams["statement_timeout"] != "5000" {
+		t.Errorf("pool config = max %d, lifetime %s, statement_timeout %q", config.MaxConns, config.MaxConnLifetime, config.ConnConfig.RuntimeParams["statement_timeout"])
+	}
+	if p.Settings() != settings {
+		t.Errorf("Settings() = %+v, want %+v", p.Settings(), settings)
+	}
+
+	// Неверные настройки не заменяют пул
+	if err := p.Update(Settings{MaxOpenConns: 1, MaxIdleConns: 2}); err == nil {
+		t.Error("Update() with max_idle_conns > max_open_conns succeeded")
+	}
+	if p.current.Load() != current {
+		t.Error("invalid Update() replaced the pool")
+	}
+}
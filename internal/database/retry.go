@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Backoff - экспоненциальная задержка между попытками подключения: Initial, затем
+// вдвое больше, но не больше Max. MaxWait ограничивает общее время ожидания, 0 - без ограничения
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	MaxWait time.Duration
+}
+
+func (b Backoff) delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// Retry вызывает try, пока он не завершится успешно, не истечет MaxWait или не отменится ctx.
+// onRetry вызывается после каждой неудачной попытки перед ожиданием delay
+func Retry(ctx context.Context, b Backoff, try func(ctx context.Context) error, onRetry func(attempt int, delay time.Duration, err error)) error {
+	var deadline time.Time
+	if b.MaxWait > 0 {
+		deadline = time.Now().Add(b.MaxWait)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := try(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay := b.delay(attempt)
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("gave up after %d attempts in %s: %w", attempt, b.MaxWait, err)
+		}
+		if onRetry != nil {
+			onRetry(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: last error: %v", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := b.delay(i + 1); got != w*time.Millisecond {
+			t.Errorf("delay(%d) = %s, want %s", i+1, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		var delays []time.Duration
+		calls := 0
+		err := Retry(context.Background(), Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond, MaxWait: time.Second},
+			func(ctx context.Context) error {
+				calls++
+				if calls < 4 {
+					return errDown
+				}
+				return nil
+			},
+			func(attempt int, delay time.Duration, err error) {
+				delays = append(delays, delay)
+			},
+		)
+		if err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+		if calls != 4 || len(delays) != 3 || delays[2] != 2*time.Millisecond {
+			t.Errorf("calls = %d, delays = %v", calls, delays)
+		}
+	})
+
+	t.Run("gives up after max wait", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), Backoff{Initial: 10 * time.Millisecond, Max: 10 * time.Millisecond, MaxWait: 25 * time.Millisecond},
+			func(ctx context.Context) error {
+				calls++
+				return errDown
+			}, nil)
+		if !errors.Is(err, errDown) {
+			t.Fatalf("Retry() error = %v, want wrapped last error", err)
+		}
+		if calls < 2 || calls > 3 {
+			t.Errorf("calls = %d, want 2-3 attempts within max wait", calls)
+		}
+	})
+
+	t.Run("stops on context cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, Backoff{Initial: time.Hour, Max: time.Hour},
+			func(ctx context.Context) error { return errDown },
+			func(attempt int, delay time.Duration, err error) { cancel() },
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Retry() error = %v, want context.Canceled", err)
+		}
+	})
+}
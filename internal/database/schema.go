@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// schemaMarker - колонка, которую создает миграция. Миграции применяются скриптами
+// docker-entrypoint-initdb.d без таблицы версий, поэтому применение проверяется по схеме
+type schemaMarker struct {
+	migration string
+	table     string
+	column    string
+}
+
+// schemaMarkers - по колонке на миграцию, меняющую таблицы. Новая миграция с таблицей
+// или колонкой, от которой зависит код, добавляет сюда свой маркер
+var schemaMarkers = []schemaMarker{
+	{"001", "subscriptions", "id"},
+	{"002", "subscriptions", "is_draft"},
+	{"003", "subscriptions", "change_seq"},
+	{"004", "subscription_changes", "seq"},
+	{"005", "subscriptions", "prepaid_amount"},
+	{"006", "email_templates", "version"},
+	{"009", "discounts", "id"},
+	{"010", "invoice_lines", "line_no"},
+	{"011", "subscription_pauses", "id"},
+	{"012", "subscriptions", "cancelled_at"},
+	{"013", "subscription_transfers", "from_user_id"},
+	{"014", "subscriptions", "tenant_id"},
+	{"015", "rejected_requests", "reason"},
+	{"017", "renewal_reminders", "renews_at"},
+	{"018", "notification_preferences", "email"},
+	{"019", "audit_log", "request_id"},
+	{"020", "idempotency_keys", "fingerprint"},
+	{"021", "subscriptions", "metadata"},
+	{"022", "user_notifications", "id"},
+	{"023", "subscriptions", "service_id"},
+	{"024", "subscription_tags", "tag_id"},
+	{"025", "backups", "wal_lsn"},
+	{"026", "subscriptions", "description"},
+	{"027", "subscriptions", "cost"},
+	{"028", "subscription_reminder_settings", "channel"},
+	{"029", "inbound_events", "event_id"},
+	{"030", "subscription_prices", "effective_from"},
+	{"031", "api_tokens", "token_hash"},
+	{"033", "sso_group_mappings", "group_name"},
+	{"034", "tenants", "id"},
+	{"035", "tenants", "rounding_mode"},
+}
+
+// CheckSchema проверяет, что в базе применены все миграции, от которых зависит код
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	tables := make([]string, 0, len(schemaMarkers))
+	for _, m := range schemaMarkers {
+		tables = append(tables, m.table)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, tables)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("failed to scan schema column: %w", err)
+		}
+		columns[table+"."+column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	return missingMigrations(columns)
+}
+
+func missingMigrations(columns map[string]bool) error {
+	var missing []string
+	for _, m := range schemaMarkers {
+		if !columns[m.table+"."+m.column] {
+			missing = append(missing, fmt.Sprintf("%s (%s.%s)", m.migration, m.table, m.column))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("migrations are not applied: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
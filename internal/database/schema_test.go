@@ -0,0 +1,98 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingMigrations(t *testing.T) {
+	columns := make(map[string]bool)
+	for _, m := range schemaMarkers {
+		columns[m.table+"."+m.column] = true
+	}
+	if err := missingMigrations(columns); err != nil {
+		t.Fatalf("missingMigrations() with full schema = %v", err)
+	}
+
+	delete(columns, "rejected_requests.reason")
+	delete(columns, "subscriptions.tenant_id")
+	err := missingMigrations(columns)
+	if err == nil || !strings.Contains(err.Error(), "014 (subscriptions.tenant_id), 015 (rejected_requests.reason)") {
+		t.Errorf("missingMigrations() = %v, want 014 and 015 reported", err)
+	}
+}
+
+func TestCompareSchema(t *testing.T) {
+	columns := make(map[string]bool)
+	for table, names := range expectedColumns {
+		for _, column := range names {
+			columns[table+"."+column] = true
+		}
+	}
+	indexes := make(map[string]bool)
+	for table, names := range expectedIndexes {
+		for _, index := range names {
+			indexes[table+"."+index] = true
+		}
+	}
+	if drift := compareSchema(columns, indexes); drift.Drifted {
+		t.Fatalf("compareSchema() with full schema = %+v", drift)
+	}
+
+	for _, column := range expectedColumns["rejected_requests"] {
+		delete(columns, "rejected_requests."+column)
+	}
+	delete(columns, "subscriptions.cancel_reason")
+	delete(indexes, "subscriptions.idx_subscriptions_tenant_user")
+
+	drift := compareSchema(columns, indexes)
+	if !drift.Drifted {
+		t.Fatal("expected drift")
+	}
+	if !reflect.DeepEqual(drift.MissingTables, []string{"rejected_requests"}) {
+		t.Errorf("MissingTables = %v", drift.MissingTables)
+	}
+	if !reflect.DeepEqual(drift.MissingColumns, []string{"subscriptions.cancel_reason"}) {
+		t.Errorf("MissingColumns = %v", drift.MissingColumns)
+	}
+	// Индексы отсутствующей таблицы не дублируются
+	if !reflect.DeepEqual(drift.MissingIndexes, []string{"idx_subscriptions_tenant_user"}) {
+		t.Errorf("MissingIndexes = %v", drift.MissingIndexes)
+	}
+}
+
+func TestIncompatiblePhases(t *testing.T) {
+	rename := []Transition{{
+		Migration:  "016",
+		Table:      "subscriptions",
+		OldColumn:  "monthly_cost",
+		NewColumn:  "price",
+		Compatible: []Phase{PhaseExpanded, PhaseContracted},
+	}}
+
+	tests := []struct {
+		name    string
+		columns map[string]bool
+		want    string
+	}{
+		{"expanded", map[string]bool{"subscriptions.monthly_cost": true, "subscriptions.price": true}, ""},
+		{"contracted", map[string]bool{"subscriptions.price": true}, ""},
+		{"pending", map[string]bool{"subscriptions.monthly_cost": true}, "016 (subscriptions.monthly_cost -> price is pending, supported: expanded,contracted)"},
+		{"missing", map[string]bool{}, "016 (subscriptions: neither monthly_cost nor price exists)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := incompatiblePhases(tt.columns, rename)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("incompatiblePhases() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("incompatiblePhases() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+//go:embed sqlite_schema.sql
+var sqliteSchema string
+
+// OpenSQLite открывает файл базы SQLite path (создает его и каталог при необходимости)
+// и применяет схему подписок. Журнал WAL позволяет читать во время записи, транзакции
+// сразу берут блокировку записи, а занятая база ожидается до пяти секунд
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create sqlite directory: %w", err)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("_foreign_keys", "1")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to apply sqlite schema: %w", err)
+	}
+	if err := addSQLiteColumns(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteColumns - столбцы, добавленные в схему после создания первых баз SQLite.
+// CREATE TABLE IF NOT EXISTS не меняет существующие таблицы, поэтому такие столбцы
+// добавляются отдельно
+var sqliteColumns = []struct {
+	table, column, definition string
+}{
+	{"subscriptions", "metadata", `TEXT NOT NULL DEFAULT '{}' CHECK (json_type(metadata) = 'object')`},
+	{"subscriptions", "service_id", `TEXT NULL`},
+	{"subscriptions", "description", `TEXT NULL`},
+	{"subscriptions", "billing_period", `TEXT NOT NULL DEFAULT 'monthly' CHECK (billing_period IN ('weekly', 'monthly', 'yearly'))`},
+	{"subscriptions", "cost", `INTEGER NULL CHECK (cost > 0)`},
+}
+
+// addSQLiteColumns добавляет недостающие столбцы sqliteColumns
+func addSQLiteColumns(ctx context.Context, db *sql.DB) error {
+	for _, c := range sqliteColumns {
+		var exists bool
+		err := db.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM pragma_table_info(?) WHERE name = ?)`, c.table, c.column,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check sqlite column %s.%s: %w", c.table, c.column, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("failed to add sqlite column %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
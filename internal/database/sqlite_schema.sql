@@ -0,0 +1,159 @@
+-- Схема SQLite для DB_DRIVER=sqlite: подписки, журнал изменений, паузы, передачи, теги и история цен.
+-- Повторяет таблицы миграций Postgres; применяется при каждом запуске и не меняет
+-- существующие таблицы. Время и даты хранятся текстом в UTC фиксированной ширины
+-- (2006-01-02T15:04:05.000000Z), поэтому строки сравниваются в хронологическом порядке
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    service_name TEXT NOT NULL,
+    monthly_cost INTEGER NOT NULL CHECK (monthly_cost >= 0),
+    user_id TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    prepaid_amount INTEGER NULL CHECK (prepaid_amount > 0),
+    is_draft BOOLEAN NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled')),
+    cancel_reason TEXT NULL,
+    cancelled_at TIMESTAMP NULL,
+    metadata TEXT NOT NULL DEFAULT '{}' CHECK (json_type(metadata) = 'object'),
+    -- service_id - запись каталога сервисов; каталог есть только в Postgres, поэтому без внешнего ключа
+    service_id TEXT NULL,
+    description TEXT NULL,
+    -- cost - стоимость за billing_period; у помесячных подписок не хранится и равна monthly_cost
+    billing_period TEXT NOT NULL DEFAULT 'monthly' CHECK (billing_period IN ('weekly', 'monthly', 'yearly')),
+    cost INTEGER NULL CHECK (cost > 0),
+    change_seq INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_tenant_user ON subscriptions(tenant_id, user_id, service_name);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_tenant_created_at_id ON subscriptions(tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_dates ON subscriptions(start_date, end_date);
+
+-- Номер изменения подписки: в Postgres - последовательность subscriptions_change_seq
+CREATE TABLE IF NOT EXISTS sequences (
+    name TEXT PRIMARY KEY,
+    value INTEGER NOT NULL
+);
+INSERT OR IGNORE INTO sequences (name, value) VALUES ('subscriptions_change_seq', 0);
+
+CREATE TABLE IF NOT EXISTS subscription_changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete', 'transfer')),
+    payload TEXT NOT NULL,
+    previous TEXT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_changes_tenant_seq ON subscription_changes(tenant_id, seq);
+
+CREATE TABLE IF NOT EXISTS subscription_pauses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id TEXT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_pauses_subscription_id ON subscription_pauses(subscription_id);
+
+CREATE TABLE IF NOT EXISTS subscription_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id TEXT NOT NULL,
+    from_user_id TEXT NOT NULL,
+    to_user_id TEXT NOT NULL,
+    reason TEXT NULL,
+    transferred_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_transfers_subscription_id ON subscription_transfers(subscription_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS subscription_tags (
+    subscription_id TEXT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_tags_tag_id ON subscription_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id TEXT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL,
+    monthly_cost INTEGER NOT NULL CHECK (monthly_cost >= 0),
+    billing_period TEXT NOT NULL DEFAULT 'monthly' CHECK (billing_period IN ('weekly', 'monthly', 'yearly')),
+    cost INTEGER NULL CHECK (cost > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Триггеры повторяют set_change_seq, update_updated_at_column и log_subscription_change
+-- из миграций Postgres. SQLite не позволяет менять NEW, поэтому номер изменения и updated_at
+-- записываются в строку после вставки или изменения. Изменение change_seq самим триггером
+-- не считается изменением строки (условие WHEN), поэтому триггер не срабатывает повторно
+CREATE TRIGGER IF NOT EXISTS log_subscriptions_insert
+AFTER INSERT ON subscriptions
+BEGIN
+    UPDATE sequences SET value = value + 1 WHERE name = 'subscriptions_change_seq';
+    UPDATE subscriptions
+    SET change_seq = (SELECT value FROM sequences WHERE name = 'subscriptions_change_seq')
+    WHERE id = NEW.id;
+    INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload)
+    SELECT s.id, s.tenant_id, 'create', json_object(
+        'id', s.id, 'service_name', s.service_name, 'monthly_cost', s.monthly_cost, 'user_id', s.user_id,
+        'start_date', substr(s.start_date, 1, 10), 'end_date', substr(s.end_date, 1, 10),
+        'prepaid_amount', s.prepaid_amount, 'is_draft', json(CASE WHEN s.is_draft THEN 'true' ELSE 'false' END),
+        'status', s.status, 'cancel_reason', s.cancel_reason, 'cancelled_at', s.cancelled_at,
+        'metadata', json(s.metadata), 'service_id', s.service_id, 'description', s.description, 'billing_period', s.billing_period, 'cost', s.cost, 'change_seq', s.change_seq, 'created_at', s.created_at, 'updated_at', s.updated_at, 'tenant_id', s.tenant_id
+    )
+    FROM subscriptions s WHERE s.id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS log_subscriptions_update
+AFTER UPDATE ON subscriptions
+WHEN NEW.change_seq = OLD.change_seq
+BEGIN
+    UPDATE sequences SET value = value + 1 WHERE name = 'subscriptions_change_seq';
+    UPDATE subscriptions
+    SET change_seq = (SELECT value FROM sequences WHERE name = 'subscriptions_change_seq'),
+        updated_at = (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'))
+    WHERE id = NEW.id;
+    INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload, previous)
+    SELECT s.id, s.tenant_id, CASE WHEN NEW.user_id <> OLD.user_id THEN 'transfer' ELSE 'update' END, json_object(
+        'id', s.id, 'service_name', s.service_name, 'monthly_cost', s.monthly_cost, 'user_id', s.user_id,
+        'start_date', substr(s.start_date, 1, 10), 'end_date', substr(s.end_date, 1, 10),
+        'prepaid_amount', s.prepaid_amount, 'is_draft', json(CASE WHEN s.is_draft THEN 'true' ELSE 'false' END),
+        'status', s.status, 'cancel_reason', s.cancel_reason, 'cancelled_at', s.cancelled_at,
+        'metadata', json(s.metadata), 'service_id', s.service_id, 'description', s.description, 'billing_period', s.billing_period, 'cost', s.cost, 'change_seq', s.change_seq, 'created_at', s.created_at, 'updated_at', s.updated_at, 'tenant_id', s.tenant_id
+    ), json_object(
+        'id', OLD.id, 'service_name', OLD.service_name, 'monthly_cost', OLD.monthly_cost, 'user_id', OLD.user_id,
+        'start_date', substr(OLD.start_date, 1, 10), 'end_date', substr(OLD.end_date, 1, 10),
+        'prepaid_amount', OLD.prepaid_amount, 'is_draft', json(CASE WHEN OLD.is_draft THEN 'true' ELSE 'false' END),
+        'status', OLD.status, 'cancel_reason', OLD.cancel_reason, 'cancelled_at', OLD.cancelled_at,
+        'metadata', json(OLD.metadata), 'service_id', OLD.service_id, 'description', OLD.description, 'billing_period', OLD.billing_period, 'cost', OLD.cost, 'change_seq', OLD.change_seq, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'tenant_id', OLD.tenant_id
+    )
+    FROM subscriptions s WHERE s.id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS log_subscriptions_delete
+AFTER DELETE ON subscriptions
+BEGIN
+    INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload)
+    VALUES (OLD.id, OLD.tenant_id, 'delete', json_object(
+        'id', OLD.id, 'service_name', OLD.service_name, 'monthly_cost', OLD.monthly_cost, 'user_id', OLD.user_id,
+        'start_date', substr(OLD.start_date, 1, 10), 'end_date', substr(OLD.end_date, 1, 10),
+        'prepaid_amount', OLD.prepaid_amount, 'is_draft', json(CASE WHEN OLD.is_draft THEN 'true' ELSE 'false' END),
+        'status', OLD.status, 'cancel_reason', OLD.cancel_reason, 'cancelled_at', OLD.cancelled_at,
+        'metadata', json(OLD.metadata), 'service_id', OLD.service_id, 'description', OLD.description, 'billing_period', OLD.billing_period, 'cost', OLD.cost, 'change_seq', OLD.change_seq, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'tenant_id', OLD.tenant_id
+    ));
+END;
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/timing"
+	"github.com/Zipklas/subscription-service/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryTracer создает спаны запросов и учитывает их время в Server-Timing. pgx вызывает его
+// и для запросов database/sql, так как они выполняются на соединениях того же пула
+type queryTracer struct{}
+
+// queryTraceKey - ключ контекста с началом запроса и его спаном
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	span  *tracing.Span
+	start time.Time
+}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := strings.Join(strings.Fields(data.SQL), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	return startTrace(ctx, operation, statement)
+}
+
+// TraceQueryEnd вызывается после чтения всех строк, поэтому спан и Server-Timing
+// покрывают запрос вместе с чтением результата
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endTrace(ctx, data.Err)
+}
+
+func (queryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	statement := "COPY " + data.TableName.Sanitize() + " (" + strings.Join(data.ColumnNames, ", ") + ") FROM STDIN"
+	return startTrace(ctx, "COPY", statement)
+}
+
+func (queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endTrace(ctx, data.Err)
+}
+
+// startTrace начинает спан запроса к базе, если запрос выполняется внутри трассы:
+// запросы фоновых задач и служебные запросы пула трассы не создают
+func startTrace(ctx context.Context, operation, statement string) context.Context {
+	trace := &queryTrace{start: time.Now()}
+	if tracing.SpanFromContext(ctx) != nil {
+		_, trace.span = tracing.Start(ctx, "db "+strings.ToUpper(operation), tracing.KindClient,
+			tracing.Attribute{Key: "db.system", Value: "postgresql"},
+			tracing.Attribute{Key: "db.statement", Value: statement},
+		)
+	}
+	return context.WithValue(ctx, queryTraceKey{}, trace)
+}
+
+func endTrace(ctx context.Context, err error) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	timing.FromContext(ctx).Query(time.Since(trace.start))
+	trace.span.RecordError(err)
+	trace.span.End()
+}
@@ -0,0 +1,135 @@
+// Package egress создает HTTP-клиенты для исходящих запросов сервиса (вебхуки, OIDC,
+// трассировка, сайдкары модификаторов, объектное хранилище) с общими настройками
+// прокси, таймаутов и списком разрешенных хостов
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrHostNotAllowed возвращается для запроса к хосту вне списка разрешенных
+var ErrHostNotAllowed = errors.New("egress host is not allowed")
+
+// Config - настройки исходящих запросов
+type Config struct {
+	// ProxyURL - прокси для всех запросов (http://, https://, socks5://). Пустое значение -
+	// прокси из HTTP_PROXY, HTTPS_PROXY и NO_PROXY
+	ProxyURL string
+	// DialTimeout ограничивает установку соединения и TLS-рукопожатие; 0 - 10 секунд
+	DialTimeout time.Duration
+	// AllowedHosts - разрешенные хосты: точное имя или "*.example.com" для поддоменов.
+	// Пустой список разрешает любые хосты
+	AllowedHosts []string
+}
+
+// Factory создает HTTP-клиенты с общим транспортом. Методы nil-Factory возвращают
+// клиенты со стандартным транспортом, поэтому пакеты и тесты могут не задавать ее
+type Factory struct {
+	transport http.RoundTripper
+	allowed   []string
+}
+
+// New создает фабрику клиентов
+func New(cfg Config) (*Factory, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		u, err := ParseProxyURL(cfg.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 10 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = dialTimeout
+
+	allowed := make([]string, 0, len(cfg.AllowedHosts))
+	for _, host := range cfg.AllowedHosts {
+		allowed = append(allowed, strings.ToLower(strings.TrimSpace(host)))
+	}
+
+	f := &Factory{allowed: allowed}
+	f.transport = &guardedTransport{next: transport, factory: f}
+	return f, nil
+}
+
+// ParseProxyURL проверяет адрес прокси
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", raw, u.Scheme)
+	}
+}
+
+// Client возвращает клиент с таймаутом всего запроса timeout (0 - без ограничения)
+func (f *Factory) Client(timeout time.Duration) *http.Client {
+	if f == nil {
+		return &http.Client{Timeout: timeout}
+	}
+	return &http.Client{Timeout: timeout, Transport: f.transport}
+}
+
+// Check сообщает, разрешены ли запросы по адресу rawURL. Используется при запуске,
+// чтобы ошибка в настройках адресов была видна сразу, а не при первой отправке
+func (f *Factory) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	return f.allow(u.Hostname())
+}
+
+func (f *Factory) allow(host string) error {
+	if f == nil || len(f.allowed) == 0 {
+		return nil
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range f.allowed {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+			continue
+		}
+		if host == pattern {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// guardedTransport отклоняет запросы к неразрешенным хостам до соединения, в том числе
+// переходы по редиректам
+type guardedTransport struct {
+	next    http.RoundTripper
+	factory *Factory
+}
+
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.factory.allow(req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,88 @@
+package egress
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	f, err := New(Config{AllowedHosts: []string{"hooks.example.com", "*.corp.example", "127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{url: "https://hooks.example.com/events", allowed: true},
+		{url: "https://HOOKS.example.com:8443/events", allowed: true},
+		{url: "https://api.corp.example/v1", allowed: true},
+		{url: "https://corp.example/v1", allowed: false},
+		{url: "https://example.com", allowed: false},
+		{url: "https://hooks.example.com.evil.io", allowed: false},
+	}
+	for _, tt := range tests {
+		err := f.Check(tt.url)
+		if tt.allowed != (err == nil) {
+			t.Errorf("Check(%q) error = %v, want allowed %v", tt.url, err, tt.allowed)
+		}
+		if err != nil && !errors.Is(err, ErrHostNotAllowed) {
+			t.Errorf("Check(%q) error = %v, want ErrHostNotAllowed", tt.url, err)
+		}
+	}
+}
+
+func TestClientRejectsRedirectToDisallowedHost(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached disallowed host")
+	}))
+	defer target.Close()
+	targetURL, _ := url.Parse(target.URL)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// localhost и 127.0.0.1 - разные хосты для списка разрешенных
+		http.Redirect(w, r, "http://localhost:"+targetURL.Port(), http.StatusFound)
+	}))
+	defer origin.Close()
+
+	f, err := New(Config{AllowedHosts: []string{"127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = f.Client(time.Second).Get(origin.URL)
+	if !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("Get() error = %v, want ErrHostNotAllowed", err)
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+	}))
+	defer proxy.Close()
+
+	f, err := New(Config{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := f.Client(time.Second).Get("http://upstream.invalid/events")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got := <-proxied; got != "http://upstream.invalid/events" {
+		t.Errorf("proxy got %q", got)
+	}
+
+	if _, err := New(Config{ProxyURL: "ftp://proxy:21"}); err == nil {
+		t.Error("New() accepted unsupported proxy scheme")
+	}
+}
@@ -0,0 +1,92 @@
+package eventschema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compatible проверяет, что данные по схеме next проходят проверку схемой prev, то есть
+// получатель, написанный под prev, прочитает их. Возвращает нарушения: удаленное или
+// ставшее необязательным поле, изменение типа, новые значения перечисления, новые
+// поля объекта без additionalProperties
+func Compatible(prev, next *Schema) []string {
+	var problems []string
+	compatible("$", prev, next, &problems)
+	return problems
+}
+
+func compatible(path string, prev, next *Schema, problems *[]string) {
+	if len(prev.Type) > 0 {
+		if len(next.Type) == 0 {
+			*problems = append(*problems, fmt.Sprintf("%s: type restriction removed", path))
+		}
+		for _, typ := range next.Type {
+			if !prev.Type.has(typ) {
+				*problems = append(*problems, fmt.Sprintf("%s: type %s is not allowed by previous version", path, typ))
+			}
+		}
+	}
+	if len(prev.Enum) > 0 {
+		if len(next.Enum) == 0 {
+			*problems = append(*problems, fmt.Sprintf("%s: enum restriction removed", path))
+		}
+		for _, value := range next.Enum {
+			if !containsValue(prev.Enum, value) {
+				*problems = append(*problems, fmt.Sprintf("%s: enum value %v is not allowed by previous version", path, value))
+			}
+		}
+	}
+	if prev.Format != "" && next.Format != prev.Format {
+		*problems = append(*problems, fmt.Sprintf("%s: format changed from %s to %q", path, prev.Format, next.Format))
+	}
+	if prev.Pattern != "" && next.Pattern != prev.Pattern {
+		*problems = append(*problems, fmt.Sprintf("%s: pattern changed", path))
+	}
+	if prev.Minimum != nil && (next.Minimum == nil || *next.Minimum < *prev.Minimum) {
+		*problems = append(*problems, fmt.Sprintf("%s: minimum relaxed", path))
+	}
+
+	nextRequired := map[string]bool{}
+	for _, name := range next.Required {
+		nextRequired[name] = true
+	}
+	for _, name := range prev.Required {
+		if !nextRequired[name] {
+			*problems = append(*problems, fmt.Sprintf("%s: property %q is no longer required", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(prev.Properties))
+	for name := range prev.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nextProperty, ok := next.Properties[name]
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: property %q removed", path, name))
+			continue
+		}
+		compatible(path+"."+name, prev.Properties[name], nextProperty, problems)
+	}
+	if prev.AdditionalProperties != nil && !*prev.AdditionalProperties {
+		added := make([]string, 0, len(next.Properties))
+		for name := range next.Properties {
+			added = append(added, name)
+		}
+		sort.Strings(added)
+		for _, name := range added {
+			if _, ok := prev.Properties[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: property %q added to a closed object", path, name))
+			}
+		}
+	}
+
+	if prev.Items != nil {
+		if next.Items == nil {
+			*problems = append(*problems, fmt.Sprintf("%s: items restriction removed", path))
+			return
+		}
+		compatible(path+"[]", prev.Items, next.Items, problems)
+	}
+}
@@ -0,0 +1,130 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// Набор проверок совместимости: добавляя версию схемы, добавьте пример ее данных
+// в testdata/<тип>.v<версия>.json. Пример должен проходить проверку схемами всех
+// предыдущих версий, иначе получатели, написанные под них, сломаются
+
+func TestVersionsAreBackwardCompatible(t *testing.T) {
+	for _, eventType := range Default.Types() {
+		latest, _ := Default.Latest(eventType)
+		for version := 2; version <= latest; version++ {
+			prev, _ := Default.Schema(eventType, version-1)
+			next, _ := Default.Schema(eventType, version)
+			if problems := Compatible(prev, next); len(problems) > 0 {
+				t.Errorf("%s v%d is incompatible with v%d:\n%s", eventType, version, version-1, strings.Join(problems, "\n"))
+			}
+		}
+	}
+}
+
+func TestExamplesValidateAgainstAllPreviousVersions(t *testing.T) {
+	for _, eventType := range Default.Types() {
+		latest, _ := Default.Latest(eventType)
+		for version := 1; version <= latest; version++ {
+			data, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("%s.v%d.json", eventType, version)))
+			if err != nil {
+				t.Errorf("missing example for %s v%d: %v", eventType, version, err)
+				continue
+			}
+			for reader := 1; reader <= version; reader++ {
+				if err := Default.Validate(eventType, reader, data); err != nil {
+					t.Errorf("%s v%d example rejected by v%d schema: %v", eventType, version, reader, err)
+				}
+			}
+		}
+	}
+}
+
+// Данные, которые сервис публикует, должны соответствовать последней версии схемы
+func TestPublishedPayloadsMatchLatestSchema(t *testing.T) {
+	payloads := map[string]interface{}{
+		service.EventSpendAnomaly: model.SpendAnomaly{
+			UserID:           uuid.New(),
+			Month:            time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			Spend:            2100,
+			TrailingAverage:  1200,
+			DeviationPercent: 75,
+			ThresholdPercent: 50,
+		},
+		service.EventRenewalReminder: model.RenewalReminder{
+			SubscriptionID: uuid.New(),
+			UserID:         uuid.New(),
+			ServiceName:    "Yandex Plus",
+			MonthlyCost:    400,
+			Kind:           model.ReminderExpiring,
+			RenewalDate:    "01-08-2025",
+			DaysLeft:       5,
+		},
+	}
+
+	for _, eventType := range Default.Types() {
+		if _, ok := payloads[eventType]; !ok {
+			t.Errorf("no published payload sample for %s", eventType)
+		}
+	}
+	for eventType, payload := range payloads {
+		latest, ok := Default.Latest(eventType)
+		if !ok {
+			t.Errorf("no schema registered for published event %s", eventType)
+			continue
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Marshal %s: %v", eventType, err)
+		}
+		if err := Default.Validate(eventType, latest, data); err != nil {
+			t.Errorf("published %s payload: %v", eventType, err)
+		}
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	base := `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "kind": {"type": "string", "enum": ["a", "b"]}}}`
+
+	tests := []struct {
+		name string
+		next string
+		want string
+	}{
+		{"optional field added", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "kind": {"type": "string", "enum": ["a", "b"]}, "note": {"type": "string"}}}`, ""},
+		{"field removed", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}`, `property "kind" removed`},
+		{"required dropped", `{"type": "object", "properties": {"id": {"type": "string"}, "kind": {"type": "string", "enum": ["a", "b"]}}}`, `property "id" is no longer required`},
+		{"type changed", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "kind": {"type": "string", "enum": ["a", "b"]}}}`, "$.id: type integer"},
+		{"enum value added", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "kind": {"type": "string", "enum": ["a", "b", "c"]}}}`, "enum value c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev, next Schema
+			if err := json.Unmarshal([]byte(base), &prev); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.next), &next); err != nil {
+				t.Fatal(err)
+			}
+
+			problems := strings.Join(Compatible(&prev, &next), "\n")
+			if tt.want == "" && problems != "" {
+				t.Errorf("Compatible() = %q, want none", problems)
+			}
+			if tt.want != "" && !strings.Contains(problems, tt.want) {
+				t.Errorf("Compatible() = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
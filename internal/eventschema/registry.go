@@ -0,0 +1,141 @@
+// Package eventschema хранит версионированные JSON-схемы данных событий, которые сервис
+// публикует подписчикам. Событие проверяется по схеме последней версии своего типа перед
+// отправкой, а версия передается получателю, поэтому изменение данных события не ломает
+// получателей незаметно: новая версия схемы должна быть совместима с предыдущими
+package eventschema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// schemas - схемы в файлах schemas/<тип события>.v<версия>.json
+//
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// fileName разбирает имя файла схемы на тип события и версию
+var fileName = regexp.MustCompile(`^([a-z0-9_.]+)\.v([1-9][0-9]*)\.json$`)
+
+// Registry - схемы событий по типам и версиям
+type Registry struct {
+	// versions - схемы типа по возрастанию версии; версии идут подряд с 1
+	versions map[string][]*Schema
+	raw      map[string][]json.RawMessage
+}
+
+// Default - схемы, встроенные в сервис
+var Default = mustLoad(schemas)
+
+func mustLoad(fsys fs.FS) *Registry {
+	r, err := Load(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Load читает схемы из каталога schemas файловой системы fsys
+func Load(fsys fs.FS) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, "schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event schemas: %w", err)
+	}
+
+	type file struct {
+		version int
+		schema  *Schema
+		raw     json.RawMessage
+	}
+	files := map[string][]file{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid event schema file name %q: expected <type>.v<version>.json", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[2])
+
+		data, err := fs.ReadFile(fsys, path.Join("schemas", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event schema %s: %w", entry.Name(), err)
+		}
+		var schema Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("failed to parse event schema %s: %w", entry.Name(), err)
+		}
+		if err := schema.compile("$"); err != nil {
+			return nil, fmt.Errorf("invalid event schema %s: %w", entry.Name(), err)
+		}
+		files[match[1]] = append(files[match[1]], file{version: version, schema: &schema, raw: data})
+	}
+
+	r := &Registry{versions: map[string][]*Schema{}, raw: map[string][]json.RawMessage{}}
+	for eventType, list := range files {
+		sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+		for i, f := range list {
+			if f.version != i+1 {
+				return nil, fmt.Errorf("invalid event schemas of %s: missing version %d", eventType, i+1)
+			}
+			r.versions[eventType] = append(r.versions[eventType], f.schema)
+			r.raw[eventType] = append(r.raw[eventType], f.raw)
+		}
+	}
+	return r, nil
+}
+
+// Types возвращает типы событий по алфавиту
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.versions))
+	for eventType := range r.versions {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Latest возвращает последнюю версию схемы типа; false - тип не зарегистрирован
+func (r *Registry) Latest(eventType string) (int, bool) {
+	versions := r.versions[eventType]
+	return len(versions), len(versions) > 0
+}
+
+// Schema возвращает схему версии version типа eventType
+func (r *Registry) Schema(eventType string, version int) (*Schema, bool) {
+	versions := r.versions[eventType]
+	if version < 1 || version > len(versions) {
+		return nil, false
+	}
+	return versions[version-1], true
+}
+
+// Raw возвращает исходный текст схемы для получателей событий
+func (r *Registry) Raw(eventType string, version int) (json.RawMessage, bool) {
+	raw := r.raw[eventType]
+	if version < 1 || version > len(raw) {
+		return nil, false
+	}
+	return raw[version-1], true
+}
+
+// Validate проверяет данные события в JSON по схеме версии version
+func (r *Registry) Validate(eventType string, version int, data []byte) error {
+	schema, ok := r.Schema(eventType, version)
+	if !ok {
+		return fmt.Errorf("no schema registered for event %s v%d", eventType, version)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid %s v%d payload: %w", eventType, version, err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return fmt.Errorf("invalid %s v%d payload: %w", eventType, version, err)
+	}
+	return nil
+}
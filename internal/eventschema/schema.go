@@ -0,0 +1,217 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema - подмножество JSON Schema, которого достаточно для событий сервиса: типы,
+// обязательные поля, свойства объектов, элементы массивов, перечисления, шаблоны строк,
+// форматы uuid и date-time и минимум чисел
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Types - допустимые типы значения: строка или массив строк в JSON
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = list
+	return nil
+}
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// has сообщает, что значение типа typ допустимо; пустой список допускает любой тип
+func (t Types) has(typ string) bool {
+	if len(t) == 0 {
+		return true
+	}
+	for _, allowed := range t {
+		// integer - частный случай number
+		if allowed == typ || (allowed == "number" && typ == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// compile проверяет схему и готовит шаблоны строк
+func (s *Schema) compile(path string) error {
+	for _, typ := range s.Type {
+		switch typ {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("%s: unknown type %q", path, typ)
+		}
+	}
+	switch s.Format {
+	case "", "uuid", "date-time":
+	default:
+		return fmt.Errorf("%s: unsupported format %q", path, s.Format)
+	}
+	for _, value := range s.Enum {
+		switch value.(type) {
+		case string, float64, bool, nil:
+		default:
+			return fmt.Errorf("%s: enum values must be scalars", path)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+		s.pattern = re
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("%s: required property %q is not described", path, name)
+		}
+	}
+	for name, property := range s.Properties {
+		if err := property.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Validate проверяет значение, разобранное encoding/json, и возвращает все нарушения
+func (s *Schema) Validate(value interface{}) error {
+	var problems []string
+	s.validate("$", value, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	typ := jsonType(value)
+	if !s.Type.has(typ) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), typ))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		*problems = append(*problems, fmt.Sprintf("%s: value %v is not one of %v", path, value, s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(path, v, problems)
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			*problems = append(*problems, fmt.Sprintf("%s: %v is less than minimum %v", path, v, *s.Minimum))
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			switch {
+			case ok:
+				property.validate(path+"."+name, v[name], problems)
+			case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				*problems = append(*problems, fmt.Sprintf("%s: unexpected property %q", path, name))
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateString(path, v string, problems *[]string) {
+	switch s.Format {
+	case "uuid":
+		if _, err := uuid.Parse(v); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not a UUID", path, v))
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not an RFC 3339 date-time", path, v))
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		*problems = append(*problems, fmt.Sprintf("%s: %q does not match %s", path, v, s.Pattern))
+	}
+}
+
+// jsonType возвращает тип JSON значения; целые числа - integer
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
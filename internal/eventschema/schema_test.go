@@ -0,0 +1,35 @@
+package eventschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"valid", `{"subscription_id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11", "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "service_name": "Yandex Plus", "monthly_cost": 400, "kind": "renewal", "renewal_date": "01-08-2025", "days_left": 0, "extra": true}`, ""},
+		{"missing field", `{"subscription_id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11", "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "service_name": "Yandex Plus", "monthly_cost": 400, "kind": "renewal", "renewal_date": "01-08-2025"}`, `missing required property "days_left"`},
+		{"wrong type", `{"subscription_id": "6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11", "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "service_name": "Yandex Plus", "monthly_cost": 400.5, "kind": "renewal", "renewal_date": "01-08-2025", "days_left": 1}`, "$.monthly_cost: expected integer, got number"},
+		{"bad format", `{"subscription_id": "42", "user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "service_name": "Yandex Plus", "monthly_cost": 400, "kind": "paused", "renewal_date": "2025-08-01", "days_left": -1}`, "$.days_left: -1 is less than minimum 0; $.kind: value paused is not one of [expiring renewal]; $.renewal_date"},
+		{"not an object", `[]`, "$: expected object, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Default.Validate("subscription.renewal_reminder", 1, []byte(tt.payload))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/scheduler"
+	"github.com/Zipklas/subscription-service/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+const poolPingTimeout = 2 * time.Second
+
+type AdminHandler struct {
+	pool     *database.Pool
+	jobs     *scheduler.Scheduler
+	queries  *metrics.Queries
+	webhooks *webhook.Dispatcher
+	drift    *database.DriftDetector
+	upkeep   *database.Maintenance
+	token    string
+	logger   *logger.Logger
+
+	// routes - описание маршрутов сервиса; задается SetRoutes после сборки роутера
+	routes    []model.RouteInfo
+	adminPath string
+}
+
+func NewAdminHandler(pool *database.Pool, jobs *scheduler.Scheduler, queries *metrics.Queries, webhooks *webhook.Dispatcher, drift *database.DriftDetector, upkeep *database.Maintenance, token string, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		pool:     pool,
+		jobs:     jobs,
+		queries:  queries,
+		webhooks: webhooks,
+		drift:    drift,
+		upkeep:   upkeep,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes регистрирует административные маршруты в группе API
+func (h *AdminHandler) RegisterRoutes(api gin.IRouter) {
+	admin := api.Group("/admin", RequireAdminToken(h.token))
+	h.adminPath = admin.BasePath()
+	admin.GET("/db/pool", h.GetPool)
+	admin.PUT("/db/pool", h.UpdatePool)
+	admin.GET("/db/queries", h.ListQueryStats)
+	admin.GET("/db/schema", h.CheckSchemaDrift)
+	admin.GET("/db/tables", h.ListTableStats)
+	admin.GET("/db/indexes", h.ListIndexStats)
+	admin.POST("/db/analyze", h.Analyze)
+	admin.GET("/jobs", h.ListJobs)
+	admin.GET("/routes", h.ListRoutes)
+	admin.GET("/webhooks", h.ListWebhooks)
+}
+
+// SetRoutes сохраняет описание маршрутов собранного роутера для ListRoutes. К groups
+// добавляется группа административных маршрутов этого обработчика
+func (h *AdminHandler) SetRoutes(routes gin.RoutesInfo, groups []RouteGroup) {
+	groups = append(groups, RouteGroup{
+		Prefix:      h.adminPath,
+		Middlewares: []gin.HandlerFunc{RequireAdminToken(h.token)},
+		Auth:        RouteAuthAdminToken,
+	})
+	h.routes = DescribeRoutes(routes, groups)
+}
+
+// ListRoutes возвращает зарегистрированные маршруты
+// @Summary Маршруты API
+// @Description Возвращает зарегистрированные маршруты с методами, обработчиками, middleware в порядке выполнения и требованиями аутентификации
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.RouteInfo
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/routes [get]
+func (h *AdminHandler) ListRoutes(c *gin.Context) {
+	routes := h.routes
+	if routes == nil {
+		routes = []model.RouteInfo{}
+	}
+	respond(c, http.StatusOK, routes)
+}
+
+// ListWebhooks возвращает состояние доставки событий по адресам подписчиков
+// @Summary Доставка вебхуков
+// @Description Возвращает для каждого адреса из WEBHOOK_URLS глубину очереди, число доставленных, неудачных и отброшенных событий и состояние выключателя
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.WebhookEndpointStats
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/webhooks [get]
+func (h *AdminHandler) ListWebhooks(c *gin.Context) {
+	respond(c, http.StatusOK, h.webhooks.Stats())
+}
+
+// ListJobs возвращает состояние фоновых задач
+// @Summary Фоновые задачи
+// @Description Возвращает расписание, время последнего и следующего запуска и ошибки фоновых задач
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.JobStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/jobs [get]
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	respond(c, http.StatusOK, h.jobs.Status())
+}
+
+// ListQueryStats возвращает гистограммы размеров результатов и времени запросов репозиториев
+// @Summary Статистика запросов к БД
+// @Description Возвращает для каждого запроса репозитория число выполнений и гистограммы количества возвращенных строк и времени выполнения с момента запуска сервиса. Корзины накопительные: count - число наблюдений не больше le
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.QueryStats
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/db/queries [get]
+func (h *AdminHandler) ListQueryStats(c *gin.Context) {
+	respond(c, http.StatusOK, h.queries.Snapshot())
+}
+
+// GetPool возвращает настройки и состояние пула соединений
+// @Summary Состояние пула соединений с БД
+// @Description Возвращает настройки пула, статистику соединений и результат ping базы
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.PoolStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/db/pool [get]
+func (h *AdminHandler) GetPool(c *gin.Context) {
+	respond(c, http.StatusOK, h.poolStatus(c.Request.Context()))
+}
+
+// CheckSchemaDrift сравнивает схему базы с ожидаемой сервисом
+// @Summary Расхождение схемы БД
+// @Description Проверяет, что в базе есть все таблицы, колонки и индексы, с которыми работают репозитории. Результат также обновляет метрику subscription_service_schema_drift_objects
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SchemaDrift
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/db/schema [get]
+func (h *AdminHandler) CheckSchemaDrift(c *gin.Context) {
+	ctx := c.Request.Context()
+	drift, err := h.drift.Detect(ctx)
+	if err != nil {
+		h.logger.Error(ctx, "Failed to check schema drift", "error", err)
+		respondProblem(c, http.StatusInternalServerError, CodeInternal, "failed to check schema drift")
+		return
+	}
+	respond(c, http.StatusOK, drift)
+}
+
+// ListTableStats возвращает размер и состояние очистки таблиц сервиса
+// @Summary Статистика таблиц БД
+// @Description Возвращает для таблиц сервиса число живых и мертвых строк (dead_ratio - оценка раздувания), размеры с индексами, число последовательных и индексных просмотров, изменения после последнего ANALYZE и время последних VACUUM и ANALYZE, начиная с самых больших таблиц
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.TableStats
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/db/tables [get]
+func (h *AdminHandler) ListTableStats(c *gin.Context) {
+	stats, err := h.upkeep.TableStats(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to read table stats")
+		return
+	}
+	respond(c, http.StatusOK, stats)
+}
+
+// ListIndexStats возвращает использование индексов таблиц сервиса
+// @Summary Статистика индексов БД
+// @Description Возвращает для индексов таблиц сервиса число просмотров, прочитанных строк и размер, начиная с реже всего используемых. unused - неуникальный индекс без единого просмотра с последнего сброса статистики
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.IndexStats
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/db/indexes [get]
+func (h *AdminHandler) ListIndexStats(c *gin.Context) {
+	stats, err := h.upkeep.IndexStats(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to read index stats")
+		return
+	}
+	respond(c, http.StatusOK, stats)
+}
+
+// Analyze обновляет статистику планировщика для таблиц подписок
+// @Summary ANALYZE таблиц подписок
+// @Description Выполняет ANALYZE таблиц subscriptions, subscription_changes, subscription_pauses и subscription_transfers (или перечисленных в tables) по очереди и возвращает время каждой. Тело можно не передавать
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.AnalyzeRequest false "Таблицы"
+// @Success 200 {array} model.AnalyzedTable
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/db/analyze [post]
+func (h *AdminHandler) Analyze(c *gin.Context) {
+	var req model.AnalyzeRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindBody(c, &req); err != nil {
+			respondError(c, h.logger, err, "Invalid request body")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	analyzed, err := h.upkeep.Analyze(ctx, req.Tables)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to analyze tables",
+			"tables", req.Tables,
+			"analyzed", analyzed,
+		)
+		return
+	}
+
+	h.logger.Info(ctx, "Tables analyzed", "tables", analyzed)
+	respond(c, http.StatusOK, analyzed)
+}
+
+// UpdatePool меняет настройки пула без перезапуска сервиса
+// @Summary Изменить настройки пула соединений с БД
+// @Description Заменяет пул соединений пулом с новыми размерами и statement_timeout. Выполняющиеся запросы не прерываются: соединения прежнего пула закрываются по мере их возврата. Счетчики статистики пула начинаются заново
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body model.UpdatePoolSettingsRequest true "Новые настройки"
+// @Success 200 {object} model.PoolStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/db/pool [put]
+func (h *AdminHandler) UpdatePool(c *gin.Context) {
+	var req model.UpdatePoolSettingsRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	previous := h.pool.Settings()
+	settings, err := mergePoolSettings(previous, req)
+	if err == nil {
+		err = h.pool.Update(settings)
+	}
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid database pool settings",
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Database pool settings updated",
+		"previous", toPoolSettings(previous),
+		"current", toPoolSettings(settings),
+	)
+
+	respond(c, http.StatusOK, h.poolStatus(c.Request.Context()))
+}
+
+func (h *AdminHandler) poolStatus(ctx context.Context) model.PoolStatus {
+	pingCtx, cancel := context.WithTimeout(ctx, poolPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	pingErr := h.pool.Ping(pingCtx)
+	stats := h.pool.Stats()
+
+	status := model.PoolStatus{
+		Settings:          toPoolSettings(h.pool.Settings()),
+		Healthy:           pingErr == nil,
+		PingMs:            time.Since(start).Milliseconds(),
+		OpenConnections:   int(stats.TotalConns()),
+		InUse:             int(stats.AcquiredConns()),
+		Idle:              int(stats.IdleConns()),
+		WaitCount:         stats.EmptyAcquireCount(),
+		WaitDuration:      stats.EmptyAcquireWaitTime().String(),
+		MaxIdleClosed:     stats.MaxIdleDestroyCount(),
+		MaxLifetimeClosed: stats.MaxLifetimeDestroyCount(),
+	}
+	if pingErr != nil {
+		status.PingError = pingErr.Error()
+	}
+	return status
+}
+
+func toPoolSettings(s database.Settings) model.PoolSettings {
+	return model.PoolSettings{
+		MaxOpenConns:     s.MaxOpenConns,
+		MaxIdleConns:     s.MaxIdleConns,
+		ConnMaxLifetime:  s.ConnMaxLifetime.String(),
+		ConnMaxIdleTime:  s.ConnMaxIdleTime.String(),
+		StatementTimeout: s.StatementTimeout.String(),
+	}
+}
+
+func mergePoolSettings(s database.Settings, req model.UpdatePoolSettingsRequest) (database.Settings, error) {
+	if req.MaxOpenConns != nil {
+		s.MaxOpenConns = *req.MaxOpenConns
+	}
+	if req.MaxIdleConns != nil {
+		s.MaxIdleConns = *req.MaxIdleConns
+	}
+
+	durations := []struct {
+		name  string
+		value *string
+		dst   *time.Duration
+	}{
+		{"conn_max_lifetime", req.ConnMaxLifetime, &s.ConnMaxLifetime},
+		{"conn_max_idle_time", req.ConnMaxIdleTime, &s.ConnMaxIdleTime},
+		{"statement_timeout", req.StatementTimeout, &s.StatementTimeout},
+	}
+	for _, d := range durations {
+		if d.value == nil {
+			continue
+		}
+		parsed, err := time.ParseDuration(*d.value)
+		if err != nil {
+			return s, fmt.Errorf("invalid %s: %w", d.name, err)
+		}
+		*d.dst = parsed
+	}
+
+	return s, nil
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальная длина периода активности, чтобы ответ оставался компактным
+const maxActivityRange = 366 * 24 * time.Hour
+
+const (
+	defaultRetentionMonths = 12
+	maxRetentionMonths     = 36
+)
+
+type AnalyticsHandler struct {
+	service service.AnalyticsService
+	logger  *logger.Logger
+}
+
+func NewAnalyticsHandler(service service.AnalyticsService, logger *logger.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты аналитики в группе API
+func (h *AnalyticsHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/metrics/subscriptions/activity", h.Activity)
+	api.GET("/analytics/retention", h.Retention)
+}
+
+// Activity возвращает количество созданий, отмен и изменений цены по интервалам
+// @Summary Активность подписок
+// @Description Возвращает по интервалам количество созданных, отмененных, удаленных подписок и изменений цены для дашбордов
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param from query string true "Начало периода (формат: YYYY-MM-DD)"
+// @Param to query string true "Конец периода включительно (формат: YYYY-MM-DD)"
+// @Param bucket query string false "Интервал группировки" Enums(day, week, month) default(day)
+// @Success 200 {array} model.ActivityBucket
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /metrics/subscriptions/activity [get]
+func (h *AnalyticsHandler) Activity(c *gin.Context) {
+	// Даты - полночь в часовом поясе периодов, в котором считаются и границы интервалов
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), model.PeriodLocation())
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid from parameter",
+			"from", c.Query("from"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "from is required, expected YYYY-MM-DD")
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", c.Query("to"), model.PeriodLocation())
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid to parameter",
+			"to", c.Query("to"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "to is required, expected YYYY-MM-DD")
+		return
+	}
+
+	if to.Before(from) || to.Sub(from) > maxActivityRange {
+		h.logger.Warn(c.Request.Context(), "Invalid activity period",
+			"from", from,
+			"to", to,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "to must not be before from and the period cannot be longer than 366 days")
+		return
+	}
+
+	filter := model.ActivityFilter{
+		From:   from,
+		To:     to,
+		Bucket: c.DefaultQuery("bucket", "day"),
+	}
+
+	if !service.IsValidActivityBucket(filter.Bucket) {
+		h.logger.Warn(c.Request.Context(), "Invalid activity bucket",
+			"bucket", filter.Bucket,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "bucket must be one of day, week, month")
+		return
+	}
+
+	buckets, err := h.service.Activity(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get subscription activity")
+		return
+	}
+
+	respond(c, http.StatusOK, buckets)
+}
+
+// Retention возвращает таблицу удержания пользователей по когортам
+// @Summary Удержание по когортам
+// @Description Для каждого месяца первой подписки (когорты) возвращает число пользователей и сколько из них сохранили подписку через 0, 1, 2... месяцев до текущего, с долями. С service_name учитываются только подписки на этот сервис
+// @Tags analytics
+// @Produce json
+// @Param service_name query string false "Название сервиса"
+// @Param months query int false "Количество когорт - последних месяцев, включая текущий (по умолчанию 12)"
+// @Success 200 {object} model.RetentionReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /analytics/retention [get]
+func (h *AnalyticsHandler) Retention(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", strconv.Itoa(defaultRetentionMonths)))
+	if err != nil || months < 1 || months > maxRetentionMonths {
+		h.logger.Warn(c.Request.Context(), "Invalid months parameter",
+			"months", c.Query("months"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("months must be between 1 and %d", maxRetentionMonths))
+		return
+	}
+
+	report, err := h.service.Retention(c.Request.Context(), c.Query("service_name"), months)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get retention cohorts")
+		return
+	}
+
+	respond(c, http.StatusOK, report)
+}
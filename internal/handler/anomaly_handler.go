@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAnomalyMonths = 6
+	maxAnomalyMonths     = 36
+)
+
+type AnomalyHandler struct {
+	service service.AnomalyService
+	logger  *logger.Logger
+}
+
+func NewAnomalyHandler(service service.AnomalyService, logger *logger.Logger) *AnomalyHandler {
+	return &AnomalyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты аномалий трат в группе API
+func (h *AnomalyHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/users/:id/anomalies", h.ListAnomalies)
+}
+
+// ListAnomalies возвращает месяцы с аномальными тратами пользователя
+// @Summary Аномалии трат пользователя
+// @Description Возвращает месяцы, в которых траты пользователя превысили среднее за предыдущие месяцы больше настроенного порога. После месяцев без трат месяц с тратами от ANOMALY_MIN_SPEND тоже аномален: trailing_average и deviation_percent у него 0
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param months query int false "Сколько последних месяцев проверять, включая текущий (по умолчанию 6)"
+// @Success 200 {array} model.SpendAnomaly
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/anomalies [get]
+func (h *AnomalyHandler) ListAnomalies(c *gin.Context) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return
+	}
+
+	months, err := strconv.Atoi(c.DefaultQuery("months", strconv.Itoa(defaultAnomalyMonths)))
+	if err != nil || months < 1 || months > maxAnomalyMonths {
+		h.logger.Warn(c.Request.Context(), "Invalid months parameter",
+			"months", c.Query("months"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("months must be between 1 and %d", maxAnomalyMonths))
+		return
+	}
+
+	anomalies, err := h.service.DetectAnomalies(c.Request.Context(), userID, months)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to detect spend anomalies",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, anomalies)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APITokenHandler struct {
+	service service.APITokenService
+	logger  *logger.Logger
+}
+
+func NewAPITokenHandler(service service.APITokenService, logger *logger.Logger) *APITokenHandler {
+	return &APITokenHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты личных токенов API в группе API
+func (h *APITokenHandler) RegisterRoutes(api gin.IRouter) {
+	api.POST("/users/:id/tokens", h.IssueToken)
+	api.GET("/users/:id/tokens", h.ListTokens)
+	api.DELETE("/users/:id/tokens/:token_id", h.RevokeToken)
+}
+
+// IssueToken выпускает личный токен API пользователя
+// @Summary Выпустить личный токен API
+// @Description Выпускает токен для интеграций (таблицы, дашборды) с правами scopes: read:subscriptions - чтение подписок, read:summary - итоги и динамика трат, write:subscriptions - изменение подписок. Токен передается в заголовке "Authorization: Bearer <token>" и показывается только в этом ответе. Запрос с личным токеном новые токены не выпускает
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param request body model.CreateAPITokenRequest true "Название, права и срок действия токена"
+// @Success 201 {object} model.IssuedAPIToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/tokens [post]
+func (h *APITokenHandler) IssueToken(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateAPITokenRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	token, err := h.service.IssueToken(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to issue API token",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusCreated, token)
+}
+
+// ListTokens возвращает личные токены API пользователя без их секретов
+// @Summary Личные токены API
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} model.APIToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.service.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list API tokens",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, tokens)
+}
+
+// RevokeToken отзывает личный токен API: запросы с ним отклоняются с ответом 401
+// @Summary Отозвать личный токен API
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param token_id path string true "ID токена"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/tokens/{token_id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	id, err := parseUUID(c, c.Param("token_id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid API token ID format",
+			"token_id", c.Param("token_id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid token ID")
+		return
+	}
+
+	if err := h.service.RevokeToken(c.Request.Context(), userID, id); err != nil {
+		respondError(c, h.logger, err, "Failed to revoke API token",
+			"user_id", userID,
+			"token_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "API token revoked successfully"})
+}
+
+func (h *APITokenHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
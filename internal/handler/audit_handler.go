@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditHandler struct {
+	service service.AuditService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewAuditHandler(service service.AuditService, token string, logger *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует историю подписки и журнал аудита организации в группе API
+func (h *AuditHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/subscriptions/:id/history", h.GetHistory)
+	api.GET("/admin/audit", RequireAdminToken(h.token), h.ListAudit)
+}
+
+// GetHistory возвращает историю изменений подписки
+// @Summary История подписки
+// @Description Возвращает изменения подписки из журнала аудита, начиная с последних: действие, образы до и после, автор и ID запроса (X-Request-ID). История удаленной подписки сохраняется. Следующую страницу возвращает before_id, равный id последней записи
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param before_id query int false "Записи старше указанной"
+// @Param limit query int false "Максимум записей (по умолчанию 100, не больше 1000)"
+// @Success 200 {array} model.AuditEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/history [get]
+func (h *AuditHandler) GetHistory(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format",
+			"subscription_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+		return
+	}
+
+	var filter model.AuditFilter
+	if err := h.parsePage(c, &filter); err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	entries, err := h.service.History(c.Request.Context(), id, filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get subscription history",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, entries)
+}
+
+// ListAudit возвращает журнал аудита организации
+// @Summary Журнал аудита
+// @Description Возвращает изменения подписок организации, начиная с последних. Автор (actor) - "user:<id>", "admin:<id>" или "admin"; изменения вне API записываются без автора
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param entity_id query string false "ID подписки"
+// @Param user_id query string false "ID владельца подписки"
+// @Param actor query string false "Автор изменения"
+// @Param action query string false "Действие" Enums(create, update, delete)
+// @Param request_id query string false "ID запроса"
+// @Param since query string false "Не раньше момента (RFC 3339)"
+// @Param until query string false "Раньше момента (RFC 3339)"
+// @Param before_id query int false "Записи старше указанной"
+// @Param limit query int false "Максимум записей (по умолчанию 100, не больше 1000)"
+// @Success 200 {array} model.AuditEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/audit [get]
+func (h *AuditHandler) ListAudit(c *gin.Context) {
+	filter := model.AuditFilter{
+		Actor:     c.Query("actor"),
+		Action:    c.Query("action"),
+		RequestID: c.Query("request_id"),
+	}
+
+	if raw := c.Query("entity_id"); raw != "" {
+		id, err := parseUUID(c, raw)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid entity ID")
+			return
+		}
+		filter.EntityID = &id
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := parseUUID(c, raw)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+			return
+		}
+		filter.UserID = &id
+	}
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, param.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*param.dest = &t
+	}
+	if err := h.parsePage(c, &filter); err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	entries, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list audit log")
+		return
+	}
+
+	respond(c, http.StatusOK, entries)
+}
+
+// parsePage разбирает параметры страницы журнала: before_id и limit
+func (h *AuditHandler) parsePage(c *gin.Context, filter *model.AuditFilter) error {
+	if raw := c.Query("before_id"); raw != "" {
+		beforeID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return fmt.Errorf("before_id must be a positive integer")
+		}
+		filter.BeforeID = beforeID
+	}
+
+	limit, err := parseLimit(c, defaultAuditLimit, maxAuditLimit)
+	if err != nil {
+		return err
+	}
+	filter.Limit = limit
+	return nil
+}
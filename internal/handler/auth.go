@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdminToken пропускает только запросы с заголовком "Authorization: Bearer <token>".
+// Пустой токен в конфигурации отключает административные маршруты. Запрос без пользователя
+// выполняется от имени администратора, чтобы его изменения попали в журнал аудита с автором
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			abortProblem(c, http.StatusForbidden, CodeForbidden, "admin API is disabled")
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			abortProblem(c, http.StatusUnauthorized, CodeUnauthorized, "invalid admin token")
+			return
+		}
+
+		if _, ok := auth.CallerFrom(c.Request.Context()); !ok {
+			c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), auth.Caller{Admin: true}))
+		}
+		c.Next()
+	}
+}
+
+// TokenAuthenticator проверяет личные токены API (с префиксом model.APITokenPrefix)
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Caller, error)
+}
+
+// GroupResolver добавляет пользователю провайдера организации и права по его группам SSO
+type GroupResolver interface {
+	ApplyGroups(ctx context.Context, caller auth.Caller, groups []string) (auth.Caller, error)
+}
+
+// Authenticate определяет пользователя запроса по заголовку "Authorization: Bearer <token>".
+// Токен ADMIN_TOKEN дает права администратора, личные токены API проверяет tokens,
+// остальные токены проверяются verifier по JWKS провайдера, а права по группам из токена
+// добавляет groups. Без verifier (OIDC не настроен) запросы без личного токена проходят без
+// пользователя, как раньше; nil tokens отключает личные токены, nil groups - сопоставления групп
+func Authenticate(verifier *auth.Verifier, tokens TokenAuthenticator, groups GroupResolver, adminToken string, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok && tokens != nil && strings.HasPrefix(token, model.APITokenPrefix) {
+			caller, err := tokens.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				abortAuthentication(c, log, err, "API token store is unavailable")
+				return
+			}
+			log.Debug(c.Request.Context(), "Request authenticated with API token",
+				"user_id", caller.UserID,
+				"scopes", caller.Scopes,
+			)
+			c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), caller))
+			c.Next()
+			return
+		}
+
+		if verifier == nil {
+			c.Next()
+			return
+		}
+
+		if !ok || token == "" {
+			abortProblem(c, http.StatusUnauthorized, CodeUnauthorized, "authentication required")
+			return
+		}
+
+		if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), auth.Caller{Admin: true}))
+			c.Next()
+			return
+		}
+
+		claims, err := verifier.Verify(c.Request.Context(), token)
+		if err != nil {
+			abortAuthentication(c, log, err, "identity provider is unavailable")
+			return
+		}
+
+		caller := verifier.Caller(claims)
+		if groups != nil {
+			caller, err = groups.ApplyGroups(c.Request.Context(), caller, claims.Groups)
+			if err != nil {
+				abortAuthentication(c, log, err, "SSO group mappings are unavailable")
+				return
+			}
+		}
+		log.Debug(c.Request.Context(), "Request authenticated",
+			"user_id", caller.UserID,
+			"admin", caller.Admin,
+			"memberships", caller.Memberships,
+		)
+		c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), caller))
+		c.Next()
+	}
+}
+
+// abortAuthentication отвечает на ошибку проверки токена: отклоненный токен - 401,
+// недоступная проверка - 503 с сообщением unavailable
+func abortAuthentication(c *gin.Context, log *logger.Logger, err error, unavailable string) {
+	if errors.Is(err, auth.ErrInvalidToken) {
+		log.Warn(c.Request.Context(), "Rejected bearer token", "error", err)
+		abortProblem(c, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+		return
+	}
+	log.Error(c.Request.Context(), "Failed to verify bearer token", "error", err)
+	abortProblem(c, http.StatusServiceUnavailable, CodeServiceUnavailable, unavailable)
+}
+
+// tokenScopes - права личных токенов API на маршруты "METHOD путь" без префикса API.
+// Маршруты не из списка личным токенам недоступны
+var tokenScopes = map[string]string{
+	"GET /subscriptions":                  model.ScopeReadSubscriptions,
+	"GET /subscriptions/search":           model.ScopeReadSubscriptions,
+	"GET /subscriptions/stream":           model.ScopeReadSubscriptions,
+	"GET /subscriptions/export":           model.ScopeReadSubscriptions,
+	"GET /subscriptions/:id":              model.ScopeReadSubscriptions,
+	"GET /subscriptions/:id/prices":       model.ScopeReadSubscriptions,
+	"GET /subscriptions/:id/history":      model.ScopeReadSubscriptions,
+	"GET /users/:id/services":             model.ScopeReadSubscriptions,
+	"GET /subscriptions/summary":          model.ScopeReadSummary,
+	"POST /subscriptions/summary/verify":  model.ScopeReadSummary,
+	"GET /users/:id/spend/sparkline":      model.ScopeReadSummary,
+	"POST /subscriptions":                 model.ScopeWriteSubscriptions,
+	"POST /subscriptions/validate":        model.ScopeWriteSubscriptions,
+	"POST /subscriptions/import":          model.ScopeWriteSubscriptions,
+	"POST /subscriptions/batch":           model.ScopeWriteSubscriptions,
+	"PUT /subscriptions/batch":            model.ScopeWriteSubscriptions,
+	"DELETE /subscriptions/batch":         model.ScopeWriteSubscriptions,
+	"PUT /subscriptions/:id":              model.ScopeWriteSubscriptions,
+	"DELETE /subscriptions/:id":           model.ScopeWriteSubscriptions,
+	"POST /subscriptions/:id/activate":    model.ScopeWriteSubscriptions,
+	"POST /subscriptions/:id/cancel":      model.ScopeWriteSubscriptions,
+	"PUT /users/:id/subscriptions:method": model.ScopeWriteSubscriptions,
+}
+
+// RequireTokenScope пропускает запрос с личным токеном API, только если у токена есть
+// право на маршрут; должен выполняться после Authenticate. basePath - префикс маршрутов
+// API. Запросы без личного токена проходят без проверки
+func RequireTokenScope(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, ok := auth.CallerFrom(c.Request.Context())
+		if !ok || caller.Scopes == nil {
+			c.Next()
+			return
+		}
+
+		scope, known := tokenScopes[c.Request.Method+" "+strings.TrimPrefix(c.FullPath(), basePath)]
+		switch {
+		case !known:
+			abortProblem(c, http.StatusForbidden, CodeForbidden, "forbidden: personal API tokens cannot access this endpoint")
+			return
+		case !caller.HasScope(scope):
+			abortProblem(c, http.StatusForbidden, CodeForbidden, "forbidden: API token lacks scope "+scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ResolveTenant определяет организацию запроса; должен выполняться после Authenticate.
+// Организация из токена имеет приоритет, заголовок header может ее только повторить.
+// Участник групп SSO выбирает заголовком одну из своих организаций (без заголовка - первую
+// по имени) и получает в ней права администратора, если их дает группа. Без аутентификации
+// и администратору организацию задает заголовок, остальным пользователям - только Default.
+// Пустой header отключает выбор заголовком
+func ResolveTenant(header string, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var requested string
+		if header != "" {
+			requested = c.GetHeader(header)
+		}
+
+		id := tenant.Default
+		caller, authenticated := auth.CallerFrom(c.Request.Context())
+		switch {
+		case authenticated && caller.Tenant != "":
+			if requested != "" && requested != caller.Tenant {
+				abortProblem(c, http.StatusForbidden, CodeForbidden, "forbidden: tenant does not match token")
+				return
+			}
+			id = caller.Tenant
+		case authenticated && len(caller.Memberships) > 0:
+			id = requested
+			if id == "" {
+				id = slices.Min(slices.Collect(maps.Keys(caller.Memberships)))
+			}
+			admin, member := caller.Memberships[id]
+			if !member && !caller.Admin {
+				abortProblem(c, http.StatusForbidden, CodeForbidden, "forbidden: not a member of tenant "+id)
+				return
+			}
+			if admin && !caller.Admin {
+				caller.Admin = true
+				c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), caller))
+			}
+		case authenticated && !caller.Admin:
+			if requested != "" && requested != tenant.Default {
+				abortProblem(c, http.StatusForbidden, CodeForbidden, "forbidden: selecting a tenant requires admin rights")
+				return
+			}
+		case requested != "":
+			id = requested
+		}
+
+		if err := tenant.Validate(id); err != nil {
+			abortProblem(c, http.StatusBadRequest, CodeInvalidTenant, err.Error())
+			return
+		}
+
+		log.Debug(c.Request.Context(), "Request tenant resolved", "tenant", id)
+		c.Request = c.Request.WithContext(ctxutil.WithTenantID(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,242 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	// JWKS недоступен: проверка токенов провайдера должна завершаться 503, а не пропускать запрос
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer jwks.Close()
+	verifier := auth.NewVerifier(auth.OIDCConfig{JWKSURL: jwks.URL}, jwks.Client())
+	owner := uuid.New()
+	tokens := tokenAuthenticatorStub{"sst_valid": owner}
+
+	tests := []struct {
+		name       string
+		verifier   *auth.Verifier
+		tokens     handler.TokenAuthenticator
+		header     string
+		wantStatus int
+		wantCaller string
+	}{
+		{"oidc disabled", nil, nil, "", http.StatusOK, "none"},
+		{"missing token", verifier, nil, "", http.StatusUnauthorized, ""},
+		{"admin token", verifier, nil, "Bearer " + testAdminToken, http.StatusOK, "admin"},
+		{"malformed token", verifier, nil, "Bearer not-a-jwt", http.StatusUnauthorized, ""},
+		{"provider unavailable", verifier, nil, "Bearer eyJhbGciOiJSUzI1NiIsImtpZCI6ImsxIn0.e30.c2ln", http.StatusServiceUnavailable, ""},
+		{"api token", verifier, tokens, "Bearer sst_valid", http.StatusOK, owner.String()},
+		{"api token without oidc", nil, tokens, "Bearer sst_valid", http.StatusOK, owner.String()},
+		{"unknown api token", nil, tokens, "Bearer sst_unknown", http.StatusUnauthorized, ""},
+		{"api token store unavailable", nil, tokens, "Bearer sst_down", http.StatusServiceUnavailable, ""},
+		{"api tokens disabled", nil, nil, "Bearer sst_valid", http.StatusOK, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", handler.Authenticate(tt.verifier, tt.tokens, nil, testAdminToken, log), func(c *gin.Context) {
+				caller, ok := auth.CallerFrom(c.Request.Context())
+				switch {
+				case !ok:
+					c.String(http.StatusOK, "none")
+				case caller.Admin:
+					c.String(http.StatusOK, "admin")
+				default:
+					c.String(http.StatusOK, caller.UserID.String())
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCaller != "" && rec.Body.String() != tt.wantCaller {
+				t.Errorf("caller = %q, want %q", rec.Body.String(), tt.wantCaller)
+			}
+		})
+	}
+}
+
+// tokenAuthenticatorStub проверяет личные токены по таблице токен - пользователь;
+// токен "sst_down" имитирует недоступное хранилище
+type tokenAuthenticatorStub map[string]uuid.UUID
+
+func (s tokenAuthenticatorStub) Authenticate(ctx context.Context, token string) (auth.Caller, error) {
+	if token == "sst_down" {
+		return auth.Caller{}, errors.New("connection refused")
+	}
+	userID, ok := s[token]
+	if !ok {
+		return auth.Caller{}, fmt.Errorf("%w: unknown API token", auth.ErrInvalidToken)
+	}
+	return auth.Caller{UserID: userID, Scopes: []string{model.ScopeReadSubscriptions}}, nil
+}
+
+func TestRequireTokenScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reader := &auth.Caller{UserID: uuid.New(), Scopes: []string{model.ScopeReadSubscriptions}}
+	tests := []struct {
+		name       string
+		caller     *auth.Caller
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"anonymous", nil, http.MethodPost, "/api/v1/subscriptions", http.StatusOK},
+		{"oidc user", &auth.Caller{UserID: uuid.New()}, http.MethodPost, "/api/v1/subscriptions", http.StatusOK},
+		{"scope granted", reader, http.MethodGet, "/api/v1/subscriptions/" + uuid.NewString(), http.StatusOK},
+		{"scope missing", reader, http.MethodPost, "/api/v1/subscriptions", http.StatusForbidden},
+		{"other scope route", reader, http.MethodGet, "/api/v1/subscriptions/summary", http.StatusForbidden},
+		{"route outside scopes", reader, http.MethodGet, "/api/v1/users/" + uuid.NewString() + "/tokens", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			api := router.Group("/api/v1", func(c *gin.Context) {
+				if tt.caller != nil {
+					c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), *tt.caller))
+				}
+			}, handler.RequireTokenScope("/api/v1"))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			api.GET("/subscriptions/summary", ok)
+			api.GET("/subscriptions/:id", ok)
+			api.POST("/subscriptions", ok)
+			api.GET("/users/:id/tokens", ok)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestResolveTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	user := &auth.Caller{}
+	tests := []struct {
+		name       string
+		caller     *auth.Caller
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{"anonymous default", nil, "", http.StatusOK, tenant.Default},
+		{"anonymous header", nil, "acme", http.StatusOK, "acme"},
+		{"invalid header", nil, "Acme Corp", http.StatusBadRequest, ""},
+		{"token tenant", &auth.Caller{Tenant: "acme"}, "", http.StatusOK, "acme"},
+		{"token tenant repeated", &auth.Caller{Tenant: "acme"}, "acme", http.StatusOK, "acme"},
+		{"token tenant mismatch", &auth.Caller{Tenant: "acme", Admin: true}, "globex", http.StatusForbidden, ""},
+		{"user without tenant claim", user, "", http.StatusOK, tenant.Default},
+		{"user selects tenant", user, "globex", http.StatusForbidden, ""},
+		{"admin selects tenant", &auth.Caller{Admin: true}, "globex", http.StatusOK, "globex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.caller != nil {
+					c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), *tt.caller))
+				}
+			}, handler.ResolveTenant("X-Tenant-ID", log), func(c *gin.Context) {
+				c.String(http.StatusOK, ctxutil.TenantID(c.Request.Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantTenant != "" && rec.Body.String() != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", rec.Body.String(), tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestResolveTenantGroupMemberships(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	member := auth.Caller{Memberships: map[string]bool{"globex": false, "acme": true}}
+	tests := []struct {
+		name       string
+		caller     auth.Caller
+		header     string
+		wantStatus int
+		wantTenant string
+		wantAdmin  bool
+	}{
+		{"first tenant by name", member, "", http.StatusOK, "acme", true},
+		{"member tenant", member, "globex", http.StatusOK, "globex", false},
+		{"foreign tenant", member, "initech", http.StatusForbidden, "", false},
+		{"global admin selects any tenant", auth.Caller{Admin: true, Memberships: member.Memberships}, "initech", http.StatusOK, "initech", true},
+		{"token tenant wins", auth.Caller{Tenant: "initech", Memberships: member.Memberships}, "", http.StatusOK, "initech", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), tt.caller))
+			}, handler.ResolveTenant("X-Tenant-ID", log), func(c *gin.Context) {
+				caller, _ := auth.CallerFrom(c.Request.Context())
+				c.String(http.StatusOK, fmt.Sprintf("%s %t", ctxutil.TenantID(c.Request.Context()), caller.Admin))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if want := fmt.Sprintf("%s %t", tt.wantTenant, tt.wantAdmin); tt.wantStatus == http.StatusOK && rec.Body.String() != want {
+				t.Errorf("tenant and admin = %q, want %q", rec.Body.String(), want)
+			}
+		})
+	}
+}
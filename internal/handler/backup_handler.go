@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultBackupLimit = 50
+	maxBackupLimit     = 500
+)
+
+type BackupHandler struct {
+	service service.BackupService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewBackupHandler(service service.BackupService, token string, logger *logger.Logger) *BackupHandler {
+	return &BackupHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует журнал резервных копий среди административных маршрутов
+func (h *BackupHandler) RegisterRoutes(api gin.IRouter) {
+	admin := api.Group("/admin/backups", RequireAdminToken(h.token))
+	admin.GET("", h.ListBackups)
+	admin.GET("/:id", h.GetBackup)
+}
+
+// ListBackups возвращает журнал резервных копий
+// @Summary Резервные копии
+// @Description Возвращает копии, снятые командой server -backup, начиная с новых: ключ в хранилище файлов, состояние, момент и позицию WAL, на которые согласована копия, число строк по таблицам, размер и SHA-256 зашифрованного файла и срок хранения
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Максимум записей (по умолчанию 50, не больше 500)"
+// @Success 200 {array} model.Backup
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/backups [get]
+func (h *BackupHandler) ListBackups(c *gin.Context) {
+	limit, err := parseLimit(c, defaultBackupLimit, maxBackupLimit)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	backups, err := h.service.List(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list backups")
+		return
+	}
+
+	respond(c, http.StatusOK, backups)
+}
+
+// GetBackup возвращает резервную копию по ID
+// @Summary Резервная копия
+// @Description Возвращает запись журнала резервных копий
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID копии"
+// @Success 200 {object} model.Backup
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/backups/{id} [get]
+func (h *BackupHandler) GetBackup(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid backup ID")
+		return
+	}
+
+	backup, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get backup",
+			"backup_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, backup)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// bulkMode читает режим пакетной операции из параметра mode; по умолчанию - атомарный
+func bulkMode(c *gin.Context) (string, error) {
+	mode := c.DefaultQuery("mode", model.BulkAtomic)
+	if !model.IsValidBulkMode(mode) {
+		return "", fmt.Errorf("invalid mode %q, expected %s or %s", mode, model.BulkAtomic, model.BulkBestEffort)
+	}
+	return mode, nil
+}
+
+// bulkStatus выбирает код ответа пакетной операции: 200 - применены все элементы,
+// 207 - в режиме best_effort часть элементов с ошибкой, 422 - атомарная пачка не применена
+func bulkStatus(resp *model.BulkResponse) int {
+	switch {
+	case resp.Failed == 0:
+		return http.StatusOK
+	case resp.Mode == model.BulkAtomic:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
+// validateItem проверяет элемент пачки как тело одиночного запроса
+func validateItem(c *gin.Context, obj interface{}) error {
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		return err
+	}
+	return checkBodyUUIDs(c, obj)
+}
+
+// BulkCreateSubscriptions создает несколько подписок
+// @Summary Создать подписки пачкой
+// @Description Создает до 1000 подписок. Каждый элемент проверяется как тело POST /subscriptions. В режиме atomic (по умолчанию) подписки создаются в одной транзакции: при ошибке любого элемента не создается ни одна, остальные элементы отмечаются skipped, ответ - 422. В режиме best_effort элементы создаются независимо, при ошибках части элементов ответ - 207. Результат каждого элемента возвращается в порядке запроса
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param mode query string false "Режим: atomic или best_effort" Enums(atomic, best_effort)
+// @Param request body model.BulkCreateRequest true "Подписки"
+// @Success 200 {object} model.BulkResponse
+// @Success 207 {object} model.BulkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/batch [post]
+func (h *SubscriptionHandler) BulkCreateSubscriptions(c *gin.Context) {
+	mode, err := bulkMode(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	var req model.BulkCreateRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	invalid := make(map[int]error)
+	for i := range req.Items {
+		if err := validateItem(c, &req.Items[i]); err != nil {
+			invalid[i] = err
+		}
+	}
+
+	resp, err := h.service.BulkCreateSubscriptions(c.Request.Context(), mode, req.Items, invalid)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to create subscriptions in bulk")
+		return
+	}
+
+	respond(c, bulkStatus(resp), resp)
+}
+
+// BulkUpdateSubscriptions изменяет несколько подписок
+// @Summary Изменить подписки пачкой
+// @Description Изменяет до 1000 подписок. Каждый элемент - id и тело PUT /subscriptions/{id}. Режимы и коды ответа - как у POST /subscriptions/batch; элементы без изменений отмечаются unchanged
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param mode query string false "Режим: atomic или best_effort" Enums(atomic, best_effort)
+// @Param request body model.BulkUpdateRequest true "Изменения подписок"
+// @Success 200 {object} model.BulkResponse
+// @Success 207 {object} model.BulkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/batch [put]
+func (h *SubscriptionHandler) BulkUpdateSubscriptions(c *gin.Context) {
+	mode, err := bulkMode(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	var req model.BulkUpdateRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	invalid := make(map[int]error)
+	for i := range req.Items {
+		err := validateItem(c, &req.Items[i])
+		if err == nil {
+			err = checkBodyUUIDs(c, &req.Items[i].UpdateSubscriptionRequest)
+		}
+		if err != nil {
+			invalid[i] = err
+		}
+	}
+
+	resp, err := h.service.BulkUpdateSubscriptions(c.Request.Context(), mode, req.Items, invalid)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to update subscriptions in bulk")
+		return
+	}
+
+	respond(c, bulkStatus(resp), resp)
+}
+
+// BulkDeleteSubscriptions удаляет несколько подписок
+// @Summary Удалить подписки пачкой
+// @Description Удаляет до 1000 подписок по идентификаторам. Режимы и коды ответа - как у POST /subscriptions/batch; отсутствующая подписка - ошибка элемента
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param mode query string false "Режим: atomic или best_effort" Enums(atomic, best_effort)
+// @Param request body model.BulkDeleteRequest true "Идентификаторы подписок"
+// @Success 200 {object} model.BulkResponse
+// @Success 207 {object} model.BulkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/batch [delete]
+func (h *SubscriptionHandler) BulkDeleteSubscriptions(c *gin.Context) {
+	mode, err := bulkMode(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	var req model.BulkDeleteRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+	for _, id := range req.IDs {
+		if err := checkUUIDVersion(c, id); err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("ids: %s", err))
+			return
+		}
+	}
+
+	resp, err := h.service.BulkDeleteSubscriptions(c.Request.Context(), mode, req.IDs)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to delete subscriptions in bulk")
+		return
+	}
+
+	respond(c, bulkStatus(resp), resp)
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CatalogHandler struct {
+	service service.CatalogService
+	logger  *logger.Logger
+}
+
+func NewCatalogHandler(service service.CatalogService, logger *logger.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты каталога сервисов в группе API
+func (h *CatalogHandler) RegisterRoutes(api gin.IRouter) {
+	services := api.Group("/services")
+	{
+		services.POST("", h.CreateService)
+		services.GET("", h.ListServices)
+		services.GET("/:id", h.GetService)
+		services.PUT("/:id", h.UpdateService)
+		services.DELETE("/:id", h.DeleteService)
+	}
+}
+
+// CreateService добавляет сервис в каталог
+// @Summary Добавить сервис в каталог
+// @Description Добавляет известный сервис подписок: каноническое название (уникально без учета регистра), категорию, стоимость по умолчанию и иконку. Подписки ссылаются на сервис через service_id
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body model.CatalogServiceRequest true "Данные сервиса"
+// @Success 201 {object} model.CatalogService
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(c *gin.Context) {
+	var req model.CatalogServiceRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.CreateService(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to create service",
+			"name", req.Name,
+		)
+		return
+	}
+
+	respond(c, http.StatusCreated, result)
+}
+
+// ListServices возвращает каталог сервисов
+// @Summary Каталог сервисов
+// @Description Возвращает сервисы каталога по названию с возможностью фильтрации по категории
+// @Tags services
+// @Produce json
+// @Param category query string false "Категория"
+// @Success 200 {array} model.CatalogService
+// @Failure 500 {object} ErrorResponse
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(c *gin.Context) {
+	var filter model.CatalogFilter
+	if category := c.Query("category"); category != "" {
+		filter.Category = &category
+	}
+
+	services, err := h.service.ListServices(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list services")
+		return
+	}
+
+	respond(c, http.StatusOK, services)
+}
+
+// GetService возвращает сервис каталога по ID
+// @Summary Получить сервис каталога
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} model.CatalogService
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetService(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get service",
+			"service_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// UpdateService изменяет сервис каталога
+// @Summary Обновить сервис каталога
+// @Description Заменяет поля сервиса; подписки, ссылающиеся на сервис, не меняются
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param service body model.CatalogServiceRequest true "Данные сервиса"
+// @Success 200 {object} model.CatalogService
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CatalogServiceRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body",
+			"service_id", id,
+		)
+		return
+	}
+
+	result, err := h.service.UpdateService(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to update service",
+			"service_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// DeleteService удаляет сервис из каталога
+// @Summary Удалить сервис каталога
+// @Description Подписки, ссылавшиеся на сервис, остаются без service_id
+// @Tags services
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteService(c.Request.Context(), id); err != nil {
+		respondError(c, h.logger, err, "Failed to delete service",
+			"service_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "service deleted successfully"})
+}
+
+func (h *CatalogHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid service ID format",
+			"service_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid service ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
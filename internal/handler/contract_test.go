@@ -0,0 +1,208 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// Контрактные тесты проверяют, что мок-сервис из тестов обработчиков ведет себя так же,
+// как настоящий сервис: обработчики сопоставляют ошибки по тексту, и расхождение в тексте
+// ошибки превращает 404 в 500 незаметно для тестов на моках.
+// Один и тот же набор проверок запускается против обеих реализаций.
+
+// contractFixture - сервис под проверкой и идентификаторы заранее созданных подписок
+type contractFixture struct {
+	svc    service.SubscriptionService
+	active uuid.UUID
+	draft  uuid.UUID
+}
+
+func TestSubscriptionServiceContract(t *testing.T) {
+	implementations := map[string]func(t *testing.T) contractFixture{
+		"service": newServiceFixture,
+		"mock":    newMockFixture,
+	}
+
+	for name, newFixture := range implementations {
+		t.Run(name, func(t *testing.T) {
+			runSubscriptionContract(t, newFixture)
+		})
+	}
+}
+
+func runSubscriptionContract(t *testing.T, newFixture func(t *testing.T) contractFixture) {
+	ctx := context.Background()
+	missing := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	t.Run("get missing", func(t *testing.T) {
+		_, err := newFixture(t).svc.GetSubscription(ctx, missing)
+		assertContractError(t, err, errNotFound)
+	})
+
+	t.Run("update missing", func(t *testing.T) {
+		err := newFixture(t).svc.UpdateSubscription(ctx, missing, model.UpdateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			MonthlyCost: 400,
+			UserID:      uuid.New(),
+			StartDate:   "07-2025",
+		})
+		assertContractError(t, err, errNotFound)
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := newFixture(t).svc.DeleteSubscription(ctx, missing)
+		assertContractError(t, err, errNotFound)
+	})
+
+	t.Run("activate missing", func(t *testing.T) {
+		_, err := newFixture(t).svc.ActivateSubscription(ctx, missing)
+		assertContractError(t, err, errNotFound)
+	})
+
+	t.Run("activate active", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.svc.ActivateSubscription(ctx, f.active)
+		assertContractError(t, err, errAlreadyActive)
+	})
+
+	t.Run("activate draft", func(t *testing.T) {
+		f := newFixture(t)
+		sub, err := f.svc.ActivateSubscription(ctx, f.draft)
+		if err != nil {
+			t.Fatalf("ActivateSubscription() error = %v", err)
+		}
+		if sub.ID != f.draft || sub.IsDraft {
+			t.Errorf("ActivateSubscription() = %+v, want active subscription %s", sub, f.draft)
+		}
+	})
+
+	t.Run("get existing", func(t *testing.T) {
+		f := newFixture(t)
+		sub, err := f.svc.GetSubscription(ctx, f.active)
+		if err != nil {
+			t.Fatalf("GetSubscription() error = %v", err)
+		}
+		if sub.ID != f.active {
+			t.Errorf("GetSubscription() id = %s, want %s", sub.ID, f.active)
+		}
+	})
+
+	t.Run("delete existing", func(t *testing.T) {
+		f := newFixture(t)
+		if err := f.svc.DeleteSubscription(ctx, f.active); err != nil {
+			t.Fatalf("DeleteSubscription() error = %v", err)
+		}
+		_, err := f.svc.GetSubscription(ctx, f.active)
+		assertContractError(t, err, errNotFound)
+	})
+}
+
+// assertContractError сравнивает ошибки по тексту, как это делают обработчики
+func assertContractError(t *testing.T, got, want error) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatalf("error = nil, want %q", want)
+	}
+	if got.Error() != want.Error() {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+// TestDeleteMissingSubscriptionThroughService проверяет статус ответа на всем пути
+// от обработчика до репозитория, а не только на моке
+func TestDeleteMissingSubscriptionThroughService(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, subscriptionPath, nil)
+	rec := httptest.NewRecorder()
+
+	newTestRouter(newServiceFixture(t).svc).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusNotFound, rec.Body.String())
+	}
+}
+
+func newServiceFixture(t *testing.T) contractFixture {
+	t.Helper()
+
+	tax, err := money.NewTax("0", true, "half_up")
+	if err != nil {
+		t.Fatalf("failed to create tax: %v", err)
+	}
+	svc := service.NewSubscriptionService(repository.NewInMemorySubscriptionRepository(), service.FixedTax(tax), nil, nil, logger.New(slog.LevelError+4))
+
+	create := func(isDraft bool) uuid.UUID {
+		sub, err := svc.CreateSubscription(context.Background(), model.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			MonthlyCost: 400,
+			UserID:      uuid.New(),
+			StartDate:   "07-2025",
+			IsDraft:     isDraft,
+		})
+		if err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+		return sub.ID
+	}
+
+	return contractFixture{svc: svc, active: create(false), draft: create(true)}
+}
+
+// newMockFixture собирает мок-сервис из тех же ошибок, что возвращают моки в тестах обработчиков
+func newMockFixture(t *testing.T) contractFixture {
+	t.Helper()
+
+	active := fixtureSubscription()
+	draft := fixtureSubscription()
+	draft.ID = uuid.New()
+	draft.IsDraft = true
+
+	subs := map[uuid.UUID]*model.Subscription{active.ID: active, draft.ID: draft}
+
+	svc := &mockService{
+		getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+			sub, ok := subs[id]
+			if !ok {
+				return nil, errNotFound
+			}
+			return sub, nil
+		},
+		updateFn: func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+			if _, ok := subs[id]; !ok {
+				return errNotFound
+			}
+			return nil
+		},
+		deleteFn: func(ctx context.Context, id uuid.UUID) error {
+			if _, ok := subs[id]; !ok {
+				return errNotFound
+			}
+			delete(subs, id)
+			return nil
+		},
+		activateFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+			sub, ok := subs[id]
+			if !ok {
+				return nil, errNotFound
+			}
+			if !sub.IsDraft {
+				return nil, errAlreadyActive
+			}
+			sub.IsDraft = false
+			return sub, nil
+		},
+	}
+
+	return contractFixture{svc: svc, active: active.ID, draft: draft.ID}
+}
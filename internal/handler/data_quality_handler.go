@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DataQualityHandler struct {
+	service service.DataQualityService
+	logger  *logger.Logger
+}
+
+func NewDataQualityHandler(service service.DataQualityService, logger *logger.Logger) *DataQualityHandler {
+	return &DataQualityHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршрут отчета о качестве данных в группе API
+func (h *DataQualityHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/users/:id/data-quality", h.GetReport)
+}
+
+// GetReport возвращает отчет о полноте данных пользователя
+// @Summary Качество данных пользователя
+// @Description Проверяет подписки пользователя и возвращает долю подписок без замечаний (score, 0-100) и замечания с действиями: действующая подписка без end_date (missing_end_date), черновик, начало которого уже прошло (stale_draft), название сервиса, которое другие пользователи чаще пишут иначе (nonstandard_service_name, с предлагаемым написанием)
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.DataQualityReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/data-quality [get]
+func (h *DataQualityHandler) GetReport(c *gin.Context) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to build data quality report",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, report)
+}
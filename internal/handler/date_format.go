@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"hash/fnv"
+	"regexp"
+	"slices"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Форматы месяцев в ответах API
+const (
+	// DateFormatLegacy - прежний формат MM-YYYY
+	DateFormatLegacy = "legacy"
+	// DateFormatISO - формат ISO 8601 YYYY-MM
+	DateFormatISO = "iso"
+)
+
+// DateFormatHeader - заголовок, которым клиент выбирает формат месяцев, а сервис
+// сообщает выбранный
+const DateFormatHeader = "X-Date-Format"
+
+const dateFormatKey = "date_format"
+
+// legacyMonthField находит месяцы MM-YYYY в полях периодов компактного JSON. Внутри
+// строк кавычки экранированы, поэтому шаблон совпадает только с настоящими полями
+var legacyMonthField = regexp.MustCompile(`"(start_date|end_date|month|period|from|to)":"(0[1-9]|1[0-2])-([0-9]{4})"`)
+
+// DateFormatRollout - постепенный перевод ответов на месяцы в формате YYYY-MM
+type DateFormatRollout struct {
+	// Tenants - организации, переведенные на новый формат целиком; "*" - все
+	Tenants []string
+	// Percent - доля остальных клиентов (0-100) с новым форматом. Клиент попадает
+	// в долю по хешу X-API-Key, без ключа - по хешу организации, поэтому его формат
+	// не меняется от запроса к запросу
+	Percent int
+}
+
+// format выбирает формат месяцев: заголовок клиента, затем список организаций, затем доля
+func (r DateFormatRollout) format(requested, tenantID, client string) string {
+	switch requested {
+	case DateFormatISO, DateFormatLegacy:
+		return requested
+	}
+	if slices.Contains(r.Tenants, "*") || slices.Contains(r.Tenants, tenantID) {
+		return DateFormatISO
+	}
+	if r.Percent <= 0 {
+		return DateFormatLegacy
+	}
+
+	key := client
+	if key == "" {
+		key = "tenant:" + tenantID
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if int(h.Sum32()%100) < r.Percent {
+		return DateFormatISO
+	}
+	return DateFormatLegacy
+}
+
+// DateFormat выбирает формат месяцев ответа (X-Date-Format: iso или legacy от клиента,
+// иначе по rollout), возвращает его в заголовке X-Date-Format и учитывает в counters.
+// Должен выполняться после выбора организации
+func DateFormat(rollout DateFormatRollout, counters *metrics.DateFormats) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := ctxutil.TenantID(c.Request.Context())
+		apiKey := c.GetHeader(apiKeyHeader)
+		format := rollout.format(c.GetHeader(DateFormatHeader), tenantID, apiKey)
+
+		c.Set(dateFormatKey, format)
+		c.Header(DateFormatHeader, format)
+
+		var client string
+		if apiKey != "" {
+			client = maskAPIKey(apiKey)
+		}
+		counters.Inc(format, tenantID, client)
+
+		c.Next()
+	}
+}
+
+// isoMonths переводит месяцы полей периодов JSON-ответа в формат YYYY-MM
+func isoMonths(body []byte) []byte {
+	return legacyMonthField.ReplaceAll(body, []byte(`"$1":"$3-$2"`))
+}
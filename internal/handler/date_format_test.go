@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestDateFormatRollout(t *testing.T) {
+	svc := &mockService{
+		getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+			sub := fixtureSubscription()
+			sub.ServiceName = `"start_date":"07-2025"`
+			return sub, nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		rollout   handler.DateFormatRollout
+		tenant    string
+		requested string
+		want      string
+	}{
+		{name: "legacy by default", tenant: "acme", want: handler.DateFormatLegacy},
+		{name: "tenant enrolled", rollout: handler.DateFormatRollout{Tenants: []string{"acme"}}, tenant: "acme", want: handler.DateFormatISO},
+		{name: "all tenants", rollout: handler.DateFormatRollout{Tenants: []string{"*"}}, tenant: "other", want: handler.DateFormatISO},
+		{name: "full percent", rollout: handler.DateFormatRollout{Percent: 100}, tenant: "acme", want: handler.DateFormatISO},
+		{name: "client opts in", tenant: "acme", requested: "iso", want: handler.DateFormatISO},
+		{name: "client opts out", rollout: handler.DateFormatRollout{Tenants: []string{"*"}}, tenant: "acme", requested: "legacy", want: handler.DateFormatLegacy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			counters := metrics.NewDateFormats()
+			router := gin.New()
+			api := router.Group("/api/v1", func(c *gin.Context) {
+				c.Request = c.Request.WithContext(ctxutil.WithTenantID(c.Request.Context(), tt.tenant))
+			}, handler.DateFormat(tt.rollout, counters))
+			handler.NewSubscriptionHandler(svc, testAdminToken, logger.New(slog.LevelError+4)).RegisterRoutes(api)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11", nil)
+			if tt.requested != "" {
+				req.Header.Set(handler.DateFormatHeader, tt.requested)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get(handler.DateFormatHeader); got != tt.want {
+				t.Errorf("%s = %q, want %q", handler.DateFormatHeader, got, tt.want)
+			}
+
+			body := rec.Body.String()
+			start, end := `"start_date":"07-2025"`, `"end_date":"12-2025"`
+			if tt.want == handler.DateFormatISO {
+				start, end = `"start_date":"2025-07"`, `"end_date":"2025-12"`
+			}
+			if !strings.Contains(body, start) || !strings.Contains(body, end) {
+				t.Errorf("body %s: want %s and %s", body, start, end)
+			}
+			// Значения строк не переписываются
+			if !strings.Contains(body, `"service_name":"\"start_date\":\"07-2025\""`) {
+				t.Errorf("body %s: service name rewritten", body)
+			}
+
+			var out strings.Builder
+			if err := counters.WritePrometheus(&out); err != nil {
+				t.Fatal(err)
+			}
+			if want := `format="` + tt.want + `",tenant="` + tt.tenant + `",client=""} 1`; !strings.Contains(out.String(), want) {
+				t.Errorf("metrics %s: want %s", out.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DiscountHandler struct {
+	service service.DiscountService
+	logger  *logger.Logger
+}
+
+func NewDiscountHandler(service service.DiscountService, logger *logger.Logger) *DiscountHandler {
+	return &DiscountHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты скидок в группе API
+func (h *DiscountHandler) RegisterRoutes(api gin.IRouter) {
+	discounts := api.Group("/discounts")
+	{
+		discounts.POST("", h.CreateDiscount)
+		discounts.GET("", h.ListDiscounts)
+		discounts.GET("/:id", h.GetDiscount)
+		discounts.PUT("/:id", h.UpdateDiscount)
+		discounts.DELETE("/:id", h.DeleteDiscount)
+	}
+}
+
+// CreateDiscount создает скидку
+// @Summary Создать скидку
+// @Description Создает процентную или фиксированную скидку на подписку или на все подписки пользователя. Скидка учитывается в расчете суммарной стоимости за месяцы своего действия. Скидки создает, меняет и удаляет только администратор
+// @Tags discounts
+// @Accept json
+// @Produce json
+// @Param discount body model.DiscountRequest true "Данные скидки"
+// @Success 201 {object} model.Discount
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /discounts [post]
+func (h *DiscountHandler) CreateDiscount(c *gin.Context) {
+	var req model.DiscountRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	discount, err := h.service.CreateDiscount(c.Request.Context(), req)
+	if err != nil {
+		h.respondDiscountError(c, "Failed to create discount", err)
+		return
+	}
+
+	respond(c, http.StatusCreated, discount)
+}
+
+// ListDiscounts возвращает список скидок
+// @Summary Список скидок
+// @Description Возвращает скидки с возможностью фильтрации по подписке и пользователю
+// @Tags discounts
+// @Produce json
+// @Param subscription_id query string false "ID подписки"
+// @Param user_id query string false "ID пользователя (скидки на все подписки пользователя)"
+// @Success 200 {array} model.Discount
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /discounts [get]
+func (h *DiscountHandler) ListDiscounts(c *gin.Context) {
+	var filter model.DiscountFilter
+
+	if raw := c.Query("subscription_id"); raw != "" {
+		id, err := parseUUID(c, raw)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+			return
+		}
+		filter.SubscriptionID = &id
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := parseUUID(c, raw)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+			return
+		}
+		filter.UserID = &id
+	}
+
+	discounts, err := h.service.ListDiscounts(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list discounts")
+		return
+	}
+
+	respond(c, http.StatusOK, discounts)
+}
+
+// GetDiscount возвращает скидку по ID
+// @Summary Получить скидку
+// @Tags discounts
+// @Produce json
+// @Param id path string true "ID скидки"
+// @Success 200 {object} model.Discount
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /discounts/{id} [get]
+func (h *DiscountHandler) GetDiscount(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	discount, err := h.service.GetDiscount(c.Request.Context(), id)
+	if err != nil {
+		h.respondDiscountError(c, "Failed to get discount", err)
+		return
+	}
+
+	respond(c, http.StatusOK, discount)
+}
+
+// UpdateDiscount изменяет скидку
+// @Summary Обновить скидку
+// @Tags discounts
+// @Accept json
+// @Produce json
+// @Param id path string true "ID скидки"
+// @Param discount body model.DiscountRequest true "Данные скидки"
+// @Success 200 {object} model.Discount
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /discounts/{id} [put]
+func (h *DiscountHandler) UpdateDiscount(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req model.DiscountRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body",
+			"discount_id", id,
+		)
+		return
+	}
+
+	discount, err := h.service.UpdateDiscount(c.Request.Context(), id, req)
+	if err != nil {
+		h.respondDiscountError(c, "Failed to update discount", err)
+		return
+	}
+
+	respond(c, http.StatusOK, discount)
+}
+
+// DeleteDiscount удаляет скидку
+// @Summary Удалить скидку
+// @Tags discounts
+// @Param id path string true "ID скидки"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /discounts/{id} [delete]
+func (h *DiscountHandler) DeleteDiscount(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDiscount(c.Request.Context(), id); err != nil {
+		h.respondDiscountError(c, "Failed to delete discount", err)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "discount deleted successfully"})
+}
+
+func (h *DiscountHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid discount ID format",
+			"discount_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid discount ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *DiscountHandler) respondDiscountError(c *gin.Context, msg string, err error) {
+	respondError(c, h.logger, err, msg,
+		"discount_id", c.Param("id"),
+	)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/eventschema"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventSchemaHandler struct {
+	schemas *eventschema.Registry
+	logger  *logger.Logger
+}
+
+func NewEventSchemaHandler(schemas *eventschema.Registry, logger *logger.Logger) *EventSchemaHandler {
+	return &EventSchemaHandler{
+		schemas: schemas,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты схем событий в группе API
+func (h *EventSchemaHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/event-schemas", h.ListSchemas)
+	api.GET("/event-schemas/:type/:version", h.GetSchema)
+}
+
+// ListSchemas возвращает типы событий и версии их схем
+// @Summary Схемы событий
+// @Description Возвращает типы событий вебхуков и версии JSON-схем их данных. Вебхук передает тип и версию в заголовках X-Event-Type и X-Event-Schema-Version; новая версия совместима с предыдущими
+// @Tags events
+// @Produce json
+// @Success 200 {array} model.EventSchemaVersions
+// @Router /event-schemas [get]
+func (h *EventSchemaHandler) ListSchemas(c *gin.Context) {
+	result := []model.EventSchemaVersions{}
+	for _, eventType := range h.schemas.Types() {
+		latest, _ := h.schemas.Latest(eventType)
+		versions := make([]int, latest)
+		for i := range versions {
+			versions[i] = i + 1
+		}
+		result = append(result, model.EventSchemaVersions{Type: eventType, Versions: versions, Latest: latest})
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// GetSchema возвращает JSON-схему данных события
+// @Summary Схема события
+// @Tags events
+// @Produce json
+// @Param type path string true "Тип события, например subscription.renewal_reminder"
+// @Param version path int true "Версия схемы"
+// @Success 200 {object} object
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /event-schemas/{type}/{version} [get]
+func (h *EventSchemaHandler) GetSchema(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "invalid version: expected a positive integer")
+		return
+	}
+
+	raw, ok := h.schemas.Raw(c.Param("type"), version)
+	if !ok {
+		respondProblem(c, http.StatusNotFound, CodeEventSchemaNotFound, "event schema not found")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/schema+json", raw)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+const strictFiltersKey = "strict_filters"
+
+// StrictFilters задает реакцию на некорректные значения фильтров списка: в строгом
+// режиме запрос отклоняется с 400, иначе такие фильтры пропускаются, как раньше.
+// Без этого middleware действует строгий режим
+func StrictFilters(strict bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictFiltersKey, strict)
+		c.Next()
+	}
+}
+
+func strictFilters(c *gin.Context) bool {
+	strict, ok := c.Get(strictFiltersKey)
+	return !ok || strict.(bool)
+}
+
+// FieldError - некорректное значение параметра запроса
+type FieldError struct {
+	Field  string `json:"field" example:"user_id"`
+	Value  string `json:"value" example:"not-a-uuid"`
+	Reason string `json:"reason" example:"invalid UUID length: 10"`
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListSubscriptionsInvalidFilters(t *testing.T) {
+	const path = "/api/v1/subscriptions?user_id=not-a-uuid&status=deleted&service_name=Netflix"
+
+	t.Run("strict", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newTestRouter(&mockService{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+		var resp handler.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Code != handler.CodeInvalidFilter || len(resp.Errors) != 2 || resp.Errors[0].Field != "user_id" || resp.Errors[1].Field != "status" {
+			t.Errorf("fields = %+v, want user_id and status", resp.Errors)
+		}
+		if resp.Errors[0].Value != "not-a-uuid" {
+			t.Errorf("value = %q, want the rejected value", resp.Errors[0].Value)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		svc := &mockService{
+			listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+				if filter.UserID != nil || filter.Status != nil {
+					t.Errorf("invalid filters were passed to service: %+v", filter)
+				}
+				if filter.ServiceName == nil || *filter.ServiceName != "Netflix" {
+					t.Errorf("valid filter was dropped: %+v", filter)
+				}
+				return &model.SubscriptionPage{}, nil
+			},
+		}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		api := router.Group("/api/v1", handler.StrictFilters(false))
+		handler.NewSubscriptionHandler(svc, "", logger.New(slog.LevelError+4)).RegisterRoutes(api)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	})
+}
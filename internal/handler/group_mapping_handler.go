@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupMappingHandler struct {
+	service service.GroupMappingService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewGroupMappingHandler(service service.GroupMappingService, token string, logger *logger.Logger) *GroupMappingHandler {
+	return &GroupMappingHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует сопоставления групп SSO среди административных маршрутов.
+// Группа удаляется по параметру запроса: имена групп Keycloak содержат "/"
+func (h *GroupMappingHandler) RegisterRoutes(api gin.IRouter) {
+	admin := api.Group("/admin/sso-groups", RequireAdminToken(h.token))
+	admin.GET("", h.ListMappings)
+	admin.PUT("", h.SaveMapping)
+	admin.DELETE("", h.DeleteMapping)
+}
+
+// ListMappings возвращает сопоставления групп SSO организациям и ролям
+// @Summary Сопоставления групп SSO
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.GroupMapping
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/sso-groups [get]
+func (h *GroupMappingHandler) ListMappings(c *gin.Context) {
+	mappings, err := h.service.ListMappings(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list SSO group mappings")
+		return
+	}
+
+	respond(c, http.StatusOK, mappings)
+}
+
+// SaveMapping создает или заменяет сопоставление группы SSO
+// @Summary Сохранить сопоставление группы SSO
+// @Description Участники группы из claim OIDC_GROUPS_CLAIM токена получают доступ к организации tenant: role member - к своим данным, admin - права администратора в ней. admin без tenant дает права администратора во всех организациях. Повторный запрос с тем же телом ничего не меняет; изменения видны другим репликам в течение 30 секунд
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SaveGroupMappingRequest true "Группа, организация и роль"
+// @Success 200 {object} model.GroupMapping
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/sso-groups [put]
+func (h *GroupMappingHandler) SaveMapping(c *gin.Context) {
+	var req model.SaveGroupMappingRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	mapping, err := h.service.SaveMapping(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to save SSO group mapping",
+			"group", req.Group,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, mapping)
+}
+
+// DeleteMapping удаляет сопоставление группы SSO: ее участники теряют выданный им доступ
+// @Summary Удалить сопоставление группы SSO
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param group query string true "Имя группы"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/sso-groups [delete]
+func (h *GroupMappingHandler) DeleteMapping(c *gin.Context) {
+	group := c.Query("group")
+	if group == "" {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidRequest, "group is required")
+		return
+	}
+
+	if err := h.service.DeleteMapping(c.Request.Context(), group); err != nil {
+		respondError(c, h.logger, err, "Failed to delete SSO group mapping",
+			"group", group,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "SSO group mapping deleted successfully"})
+}
@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// groupMappingServiceStub запоминает удаленную группу; остальные методы не вызываются
+type groupMappingServiceStub struct {
+	service.GroupMappingService
+	deleted string
+}
+
+func (s *groupMappingServiceStub) SaveMapping(ctx context.Context, req model.SaveGroupMappingRequest) (*model.GroupMapping, error) {
+	return &model.GroupMapping{Group: req.Group, Tenant: req.Tenant, Role: req.Role}, nil
+}
+
+func (s *groupMappingServiceStub) DeleteMapping(ctx context.Context, group string) error {
+	if group != "/acme/admins" {
+		return service.ErrGroupMappingNotFound
+	}
+	s.deleted = group
+	return nil
+}
+
+func TestGroupMappingRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		token      string
+		wantStatus int
+	}{
+		{"save without token", http.MethodPut, "/api/v1/admin/sso-groups", `{"group": "/acme/admins", "tenant": "acme", "role": "admin"}`, "", http.StatusUnauthorized},
+		{"save", http.MethodPut, "/api/v1/admin/sso-groups", `{"group": "/acme/admins", "tenant": "acme", "role": "admin"}`, testAdminToken, http.StatusOK},
+		{"save unknown role", http.MethodPut, "/api/v1/admin/sso-groups", `{"group": "/acme/admins", "tenant": "acme", "role": "owner"}`, testAdminToken, http.StatusBadRequest},
+		{"delete group with slashes", http.MethodDelete, "/api/v1/admin/sso-groups?group=%2Facme%2Fadmins", "", testAdminToken, http.StatusOK},
+		{"delete unknown group", http.MethodDelete, "/api/v1/admin/sso-groups?group=other", "", testAdminToken, http.StatusNotFound},
+		{"delete without group", http.MethodDelete, "/api/v1/admin/sso-groups", "", testAdminToken, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &groupMappingServiceStub{}
+			router := gin.New()
+			handler.NewGroupMappingHandler(svc, testAdminToken, log).RegisterRoutes(router.Group("/api/v1"))
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
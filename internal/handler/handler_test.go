@@ -0,0 +1,245 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testAdminToken - административный токен роутера newTestRouter
+const testAdminToken = "test-admin-token"
+
+// mockService позволяет подменять отдельные методы сервиса в тестах.
+// Методы без заданной функции паникуют через встроенный nil-интерфейс,
+// поэтому тест сразу покажет неожиданный вызов.
+type mockService struct {
+	service.SubscriptionService
+
+	createFn    func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error)
+	getFn       func(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	updateFn    func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error
+	deleteFn    func(ctx context.Context, id uuid.UUID) error
+	listFn      func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error)
+	listAfterFn func(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error)
+	exportFn    func(ctx context.Context, fn func(*model.Subscription) error) error
+	activateFn  func(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	cancelFn    func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error)
+	changesFn   func(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error)
+	totalCostFn func(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error)
+	servicesFn  func(ctx context.Context, userID uuid.UUID) ([]model.UserService, error)
+	importFn    func(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error)
+	validateFn  func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error)
+	transferFn  func(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error)
+	streamFn    func(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error
+	syncFn      func(ctx context.Context, userID uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error)
+}
+
+func (m *mockService) CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+	return m.createFn(ctx, req)
+}
+
+func (m *mockService) ImportSubscriptions(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error) {
+	return m.importFn(ctx, rows)
+}
+
+func (m *mockService) ValidateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error) {
+	return m.validateFn(ctx, req)
+}
+
+func (m *mockService) GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	return m.getFn(ctx, id)
+}
+
+func (m *mockService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+	return m.updateFn(ctx, id, req)
+}
+
+func (m *mockService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return m.deleteFn(ctx, id)
+}
+
+func (m *mockService) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+	return m.listFn(ctx, filter, page)
+}
+
+func (m *mockService) ListSubscriptionsAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error) {
+	return m.listAfterFn(ctx, filter, after, limit)
+}
+
+func (m *mockService) ExportSubscriptions(ctx context.Context, fn func(*model.Subscription) error) error {
+	return m.exportFn(ctx, fn)
+}
+
+func (m *mockService) StreamSubscriptions(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	return m.streamFn(ctx, filter, fn)
+}
+
+func (m *mockService) ActivateSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	return m.activateFn(ctx, id)
+}
+
+func (m *mockService) CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+	return m.cancelFn(ctx, id, req)
+}
+
+func (m *mockService) TransferSubscription(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+	return m.transferFn(ctx, id, req)
+}
+
+func (m *mockService) ListChanges(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error) {
+	return m.changesFn(ctx, sinceSeq, limit)
+}
+
+func (m *mockService) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+	return m.totalCostFn(ctx, filter)
+}
+
+func (m *mockService) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	return m.servicesFn(ctx, userID)
+}
+
+func (m *mockService) SyncUserSubscriptions(ctx context.Context, userID uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error) {
+	return m.syncFn(ctx, userID, items, dryRun)
+}
+
+// newTestRouter собирает роутер с маршрутами подписок поверх мок-сервиса
+func newTestRouter(svc service.SubscriptionService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	// Уровень выше Error, чтобы логи не засоряли вывод тестов
+	log := logger.New(slog.LevelError + 4)
+
+	router := gin.New()
+	api := router.Group("/api/v1", handler.ContentNegotiation(true))
+	handler.NewSubscriptionHandler(svc, testAdminToken, log).RegisterRoutes(api)
+	return router
+}
+
+type apiTestCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	// contentType заменяет Content-Type по умолчанию (application/json) для запросов с телом
+	contentType string
+	// adminToken передается в заголовке Authorization: Bearer
+	adminToken string
+	service    *mockService
+	wantStatus int
+	// golden - имя файла в testdata с ожидаемым телом ответа; пустое значение пропускает сравнение
+	golden string
+}
+
+func runAPITests(t *testing.T, tests []apiTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := tt.service
+			if svc == nil {
+				svc = &mockService{}
+			}
+
+			var body io.Reader
+			if tt.body != "" {
+				body = bytes.NewBufferString(tt.body)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.body != "" {
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				req.Header.Set("Content-Type", contentType)
+			}
+			if tt.adminToken != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.adminToken)
+			}
+			rec := httptest.NewRecorder()
+
+			newTestRouter(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			if tt.golden != "" {
+				assertGolden(t, tt.golden, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+// assertGolden сравнивает JSON ответа с файлом testdata/<name>.golden.json.
+// Запуск с флагом -update перезаписывает файл текущим ответом.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, got, "", "  "); err != nil {
+		t.Fatalf("response is not valid JSON: %v: %s", err, got)
+	}
+	buf.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+
+	if !bytes.Equal(want, buf.Bytes()) {
+		t.Errorf("response does not match %s\ngot:\n%s\nwant:\n%s", path, buf.String(), want)
+	}
+}
+
+// fixtureSubscription возвращает подписку с фиксированными значениями для golden-файлов
+func fixtureSubscription() *model.Subscription {
+	endDate := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+	return &model.Subscription{
+		ID:          uuid.MustParse("6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"),
+		ServiceName: "Yandex Plus",
+		MonthlyCost: 400,
+		UserID:      uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+		StartDate:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     &endDate,
+		Status:      model.StatusActive,
+		ChangeSeq:   42,
+		CreatedAt:   time.Date(2025, time.July, 10, 9, 30, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2025, time.July, 10, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+var (
+	errNotFound          = service.ErrSubscriptionNotFound
+	errAlreadyActive     = service.ErrSubscriptionAlreadyActive
+	errInvalidTransition = fmt.Errorf("%w from cancelled to active", service.ErrInvalidStatusTransition)
+	errDatabase          = errors.New("database is unavailable")
+)
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dependencyOK          = "ok"
+	dependencyUnavailable = "unavailable"
+)
+
+// ReadinessCheck - проверка зависимости, без которой сервис не может обслуживать запросы
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler отвечает на пробы Kubernetes: /healthz - процесс жив,
+// /readyz - зависимости доступны и запросы можно направлять на экземпляр
+type HealthHandler struct {
+	checks  []ReadinessCheck
+	timeout time.Duration
+	pod     model.PodMetadata
+	logger  *logger.Logger
+}
+
+// NewHealthHandler создает обработчик проб; каждая проверка ограничена timeout.
+// Непустой pod добавляется в ответы, чтобы отличать реплики
+func NewHealthHandler(checks []ReadinessCheck, timeout time.Duration, pod model.PodMetadata, logger *logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+		pod:     pod,
+		logger:  logger,
+	}
+}
+
+// RegisterProbes регистрирует /healthz и /readyz
+func (h *HealthHandler) RegisterProbes(router gin.IRouter) {
+	router.GET("/healthz", h.Liveness)
+	router.GET("/readyz", h.Readiness)
+}
+
+// Liveness отвечает, пока процесс обрабатывает запросы
+// @Summary Liveness-проба
+// @Description Отвечает 200, пока процесс обрабатывает HTTP-запросы. Зависимости не проверяются, чтобы сбой базы не приводил к перезапуску экземпляров
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string "status"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	body := gin.H{"status": dependencyOK}
+	if !h.pod.IsZero() {
+		body["pod"] = h.pod
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// Readiness проверяет зависимости параллельно
+// @Summary Readiness-проба
+// @Description Проверяет зависимости (ping базы, применение миграций) с таймаутом READINESS_TIMEOUT. Если хотя бы одна недоступна, отвечает 503, и Kubernetes перестает направлять запросы на экземпляр
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.ReadinessResponse
+// @Failure 503 {object} model.ReadinessResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	results := make([]model.DependencyStatus, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(c.Request.Context(), check)
+		}()
+	}
+	wg.Wait()
+
+	response := model.ReadinessResponse{Status: dependencyOK, Dependencies: results}
+	if !h.pod.IsZero() {
+		response.Pod = &h.pod
+	}
+	code := http.StatusOK
+	for _, result := range results {
+		if result.Status != dependencyOK {
+			response.Status, code = dependencyUnavailable, http.StatusServiceUnavailable
+			h.logger.Warn(c.Request.Context(), "Readiness check failed",
+				"dependency", result.Name,
+				"error", result.Error,
+			)
+		}
+	}
+
+	c.JSON(code, response)
+}
+
+func (h *HealthHandler) run(ctx context.Context, check ReadinessCheck) model.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	status := model.DependencyStatus{
+		Name:       check.Name,
+		Status:     dependencyOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status, status.Error = dependencyUnavailable, err.Error()
+	}
+	return status
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dbErr := error(nil)
+	probes := handler.NewHealthHandler([]handler.ReadinessCheck{
+		{Name: "database", Check: func(ctx context.Context) error { return dbErr }},
+		{Name: "slow", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}, 20*time.Millisecond, model.PodMetadata{Name: "api-0", Namespace: "billing"}, logger.New(slog.LevelError+4))
+	router := gin.New()
+	probes.RegisterProbes(router)
+
+	get := func(path string) (*httptest.ResponseRecorder, model.ReadinessResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body model.ReadinessResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	dbErr = errors.New("connection refused")
+	if rec, _ := get("/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("/healthz status = %d, want 200 regardless of dependencies", rec.Code)
+	}
+
+	rec, body := get("/readyz")
+	if rec.Code != http.StatusServiceUnavailable || body.Status != "unavailable" {
+		t.Fatalf("/readyz = %d %+v, want 503 unavailable", rec.Code, body)
+	}
+	want := []model.DependencyStatus{
+		{Name: "database", Status: "unavailable", Error: "connection refused"},
+		{Name: "slow", Status: "unavailable", Error: "context deadline exceeded"},
+	}
+	if want := (model.PodMetadata{Name: "api-0", Namespace: "billing"}); body.Pod == nil || *body.Pod != want {
+		t.Errorf("/readyz pod = %+v, want %+v", body.Pod, want)
+	}
+	for i, dep := range body.Dependencies {
+		dep.DurationMs = 0
+		if dep != want[i] {
+			t.Errorf("dependency %d = %+v, want %+v", i, dep, want[i])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader - заголовок ключа идемпотентности запроса
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader отмечает ответ, возвращенный повтору из сохраненных
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// idempotencyWriter запоминает тело ответа, чтобы сохранить его для повторов запроса
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Idempotency выполняет POST- и PATCH-запросы с заголовком Idempotency-Key один раз:
+// повтор с тем же ключом получает сохраненный ответ с заголовком Idempotent-Replayed.
+// Повтор во время выполнения запроса получает 409, тот же ключ с другим запросом - 422.
+// Должен стоять после аутентификации и выбора организации: ключ принадлежит автору запроса
+func Idempotency(svc service.IdempotencyService, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortProblem(c, http.StatusBadRequest, CodeMalformedBody, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		replay, err := svc.Begin(c.Request.Context(), key, fingerprint)
+		if err != nil {
+			respondError(c, log, err, "Failed to check idempotency key")
+			c.Abort()
+			return
+		}
+		if replay != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(replay.ResponseStatus, replay.ContentType, replay.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		svc.Finish(c.Request.Context(), key, fingerprint, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+	}
+}
+
+// requestFingerprint - SHA-256 метода, пути с параметрами и тела запроса
+func requestFingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+uri+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyServiceStub хранит ответы по ключу в памяти
+type idempotencyServiceStub struct {
+	records map[string]*model.IdempotencyRecord
+}
+
+func (s *idempotencyServiceStub) Begin(ctx context.Context, key, fingerprint string) (*model.IdempotencyRecord, error) {
+	record, ok := s.records[key]
+	switch {
+	case !ok:
+		s.records[key] = &model.IdempotencyRecord{Key: key, Fingerprint: fingerprint, Status: model.IdempotencyProcessing}
+		return nil, nil
+	case record.Fingerprint != fingerprint:
+		return nil, fmt.Errorf("%w: key %q was used with a different request", service.ErrIdempotencyKeyReused, key)
+	case record.Status != model.IdempotencyCompleted:
+		return nil, fmt.Errorf("%w: request with key %q is still in progress", service.ErrIdempotencyKeyInUse, key)
+	default:
+		return record, nil
+	}
+}
+
+func (s *idempotencyServiceStub) Finish(ctx context.Context, key, fingerprint string, status int, contentType string, body []byte) {
+	record := s.records[key]
+	record.Status = model.IdempotencyCompleted
+	record.ResponseStatus, record.ContentType, record.ResponseBody = status, contentType, body
+}
+
+func (s *idempotencyServiceStub) Purge(ctx context.Context) error {
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	router := gin.New()
+	router.Use(handler.Idempotency(&idempotencyServiceStub{records: map[string]*model.IdempotencyRecord{}}, logger.New(slog.LevelError+4)))
+	router.POST("/subscriptions", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(handler.IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("key-1", `{"service_name":"Netflix"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(handler.IdempotentReplayedHeader) != "" {
+		t.Fatalf("first request: %d, replayed %q", first.Code, first.Header().Get(handler.IdempotentReplayedHeader))
+	}
+
+	retry := send("key-1", `{"service_name":"Netflix"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get(handler.IdempotentReplayedHeader) != "true" {
+		t.Errorf("retry: %d %s, replayed %q; want replay of %s", retry.Code, retry.Body, retry.Header().Get(handler.IdempotentReplayedHeader), first.Body)
+	}
+	if ct := retry.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("retry Content-Type = %q", ct)
+	}
+
+	if rec := send("key-1", `{"service_name":"Spotify"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other request with same key: %d, want 422", rec.Code)
+	}
+	if rec := send("", `{"service_name":"Netflix"}`); rec.Code != http.StatusCreated {
+		t.Errorf("request without key: %d, want 201", rec.Code)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
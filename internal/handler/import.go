@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/xlsx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// maxImportFileSize - максимальный размер загружаемого файла импорта
+	maxImportFileSize = 10 << 20
+	// maxImportRows - максимальное количество строк с данными в файле импорта
+	maxImportRows = 10000
+)
+
+// importRequiredColumns - обязательные столбцы файла импорта. Названия столбцов совпадают
+// с полями CreateSubscriptionRequest, поэтому файл выгрузки /subscriptions/export
+// импортируется без изменений; лишние столбцы (id, created_at и т.п.) игнорируются
+var importRequiredColumns = []string{"service_name", "user_id", "start_date"}
+
+// importRecord - строка файла импорта с номером строки в файле
+type importRecord struct {
+	line  int
+	cells []string
+}
+
+// readImportFile читает строки загруженного файла; формат определяется по расширению
+func readImportFile(file *multipart.FileHeader) ([]importRecord, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv", ".txt", "":
+		return readImportCSV(f)
+	case ".xlsx":
+		return readImportXLSX(f, file.Size)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, use .csv or .xlsx", filepath.Ext(file.Filename))
+	}
+}
+
+func readImportCSV(r io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var records []importRecord
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(records) == 0 && len(cells) > 0 {
+			// Excel сохраняет CSV в UTF-8 с BOM
+			cells[0] = strings.TrimPrefix(cells[0], "\ufeff")
+		}
+		if isBlankRecord(cells) {
+			continue
+		}
+		if len(records) > maxImportRows {
+			return nil, errTooManyImportRows
+		}
+		records = append(records, importRecord{line: line, cells: cells})
+	}
+	return records, nil
+}
+
+func readImportXLSX(r io.Reader, size int64) ([]importRecord, error) {
+	// multipart.File реализует io.ReaderAt, но файл из памяти или с диска - разные типы,
+	// поэтому читаем целиком: размер уже ограничен maxImportFileSize
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	rows, err := xlsx.ReadFirstSheet(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+
+	records := make([]importRecord, 0, len(rows))
+	for _, row := range rows {
+		if isBlankRecord(row.Cells) {
+			continue
+		}
+		if len(records) > maxImportRows {
+			return nil, errTooManyImportRows
+		}
+		records = append(records, importRecord{line: row.Line, cells: row.Cells})
+	}
+	return records, nil
+}
+
+var errTooManyImportRows = fmt.Errorf("import file has more than %d rows", maxImportRows)
+
+func isBlankRecord(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importHeader сопоставляет названия столбцов из первой строки файла с их индексами
+func importHeader(cells []string) (map[string]int, error) {
+	header := make(map[string]int, len(cells))
+	for i, cell := range cells {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		if _, ok := header[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		header[name] = i
+	}
+
+	var missing []string
+	for _, name := range importRequiredColumns {
+		if _, ok := header[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	_, hasCost := header["monthly_cost"]
+	_, hasPrepaid := header["prepaid_amount"]
+	_, hasFree := header["is_free"]
+	if !hasCost && !hasPrepaid && !hasFree {
+		missing = append(missing, "monthly_cost")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return header, nil
+}
+
+// importRequest собирает запрос на создание подписки из строки файла и проверяет его
+// теми же правилами, что и тело POST /subscriptions
+func importRequest(c *gin.Context, header map[string]int, cells []string) (model.CreateSubscriptionRequest, error) {
+	get := func(name string) string {
+		i, ok := header[name]
+		if !ok || i >= len(cells) {
+			return ""
+		}
+		return strings.TrimSpace(cells[i])
+	}
+
+	req := model.CreateSubscriptionRequest{
+		ServiceName: unescapeCSVFormula(get("service_name")),
+		StartDate:   get("start_date"),
+	}
+
+	var err error
+	if v := get("monthly_cost"); v != "" {
+		if req.MonthlyCost, err = strconv.Atoi(v); err != nil {
+			return req, fmt.Errorf("invalid monthly_cost %q", v)
+		}
+	}
+	if v := get("user_id"); v != "" {
+		if req.UserID, err = parseUUID(c, v); err != nil {
+			return req, fmt.Errorf("invalid user_id %q: %w", v, err)
+		}
+	}
+	if v := get("end_date"); v != "" {
+		req.EndDate = &v
+	}
+	if v := get("prepaid_amount"); v != "" {
+		prepaid, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid prepaid_amount %q", v)
+		}
+		req.PrepaidAmount = &prepaid
+	}
+	if v := get("is_draft"); v != "" {
+		if req.IsDraft, err = strconv.ParseBool(v); err != nil {
+			return req, fmt.Errorf("invalid is_draft %q", v)
+		}
+	}
+	if v := get("is_free"); v != "" {
+		if req.IsFree, err = strconv.ParseBool(v); err != nil {
+			return req, fmt.Errorf("invalid is_free %q", v)
+		}
+	}
+	// В выгрузке бесплатные подписки отличаются только нулевой monthly_cost
+	if get("monthly_cost") == "0" && req.PrepaidAmount == nil {
+		req.IsFree = true
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// unescapeCSVFormula снимает экранирование, добавленное escapeCSVFormula при выгрузке
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// isUploadTooLarge сообщает, что тело запроса превысило ограничение MaxBytesReader
+func isUploadTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
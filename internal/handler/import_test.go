@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// importRequest собирает multipart-запрос на импорт с файлом filename
+func importRequest(t *testing.T, filename, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions/import", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	const file = "\ufeffservice_name,monthly_cost,user_id,start_date,end_date,is_draft,created_at\n" +
+		"Yandex Plus,400,60601fee-2bf1-4721-ae6f-7636e79a0cba,07-2025,,,2025-07-10T09:30:00Z\n" +
+		"\n" +
+		"Kinopoisk,abc,60601fee-2bf1-4721-ae6f-7636e79a0cba,07-2025,,,\n" +
+		"'=Netflix,500,60601fee-2bf1-4721-ae6f-7636e79a0cba,08-2025,12-2025,true,\n" +
+		",500,60601fee-2bf1-4721-ae6f-7636e79a0cba,08-2025,,,\n" +
+		"Spotify Free,0,60601fee-2bf1-4721-ae6f-7636e79a0cba,09-2025,,,\n"
+
+	var got []model.ImportRow
+	svc := &mockService{
+		importFn: func(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error) {
+			got = rows
+			return &model.ImportResult{
+				Imported: 1,
+				Errors:   []model.ImportError{{Line: 5, Error: "end date cannot be before start date"}},
+			}, nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter(svc).ServeHTTP(rec, importRequest(t, "subscriptions.csv", file))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	endDate := "12-2025"
+	wantRows := []int{2, 5, 7}
+	if len(got) != len(wantRows) {
+		t.Fatalf("service got %d rows, want %d: %+v", len(got), len(wantRows), got)
+	}
+	for i, line := range wantRows {
+		if got[i].Line != line {
+			t.Errorf("row %d line = %d, want %d", i, got[i].Line, line)
+		}
+	}
+	if got[1].Request.ServiceName != "=Netflix" || !got[1].Request.IsDraft || !reflect.DeepEqual(got[1].Request.EndDate, &endDate) {
+		t.Errorf("unexpected request from line 5: %+v", got[1].Request)
+	}
+	if !got[2].Request.IsFree || got[2].Request.MonthlyCost != 0 {
+		t.Errorf("unexpected request from line 7: %+v", got[2].Request)
+	}
+
+	var result model.ImportResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	// Ошибки разбора и ошибки сервиса объединяются в порядке строк файла
+	var lines []int
+	for _, e := range result.Errors {
+		lines = append(lines, e.Line)
+	}
+	if result.Imported != 1 || result.Failed != 3 || !reflect.DeepEqual(lines, []int{4, 5, 6}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestImportSubscriptionsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+	}{
+		{"missing columns", "subscriptions.csv", "service_name,user_id\nYandex Plus,60601fee-2bf1-4721-ae6f-7636e79a0cba\n"},
+		{"header only", "subscriptions.csv", "service_name,monthly_cost,user_id,start_date\n"},
+		{"unsupported type", "subscriptions.xls", "binary"},
+		{"broken xlsx", "subscriptions.xlsx", "service_name,monthly_cost\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestRouter(&mockService{}).ServeHTTP(rec, importRequest(t, tt.filename, tt.content))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMultipartOnlyForImport(t *testing.T) {
+	req := importRequest(t, "subscriptions.csv", "service_name\n")
+	req.URL.Path = "/api/v1/subscriptions"
+
+	rec := httptest.NewRecorder()
+	newTestRouter(&mockService{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+}
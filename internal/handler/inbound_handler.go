@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeduplicatedLimit = 50
+	maxDeduplicatedLimit     = 500
+)
+
+type InboundHandler struct {
+	service service.InboundService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewInboundHandler(service service.InboundService, token string, logger *logger.Logger) *InboundHandler {
+	return &InboundHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует прием входящих событий и их просмотр среди административных маршрутов
+func (h *InboundHandler) RegisterRoutes(api gin.IRouter) {
+	api.POST("/events/inbound", h.ConsumeEvent)
+	api.GET("/admin/inbound-events/deduplicated", RequireAdminToken(h.token), h.ListDeduplicated)
+}
+
+// ConsumeEvent применяет входящее событие интеграции
+// @Summary Принять входящее событие
+// @Description Применяет событие внешней системы об изменении подписки: subscription.created (data - тело POST /subscriptions), subscription.updated (id и тело PUT /subscriptions/{id}), subscription.cancelled (id и тело POST /subscriptions/{id}/cancel) или subscription.deleted (id). Событие определяется парой source и id: повторная доставка уже примененного события не применяется второй раз и возвращает status duplicate. Повтор события, которое еще обрабатывается, - 409; событие, применение которого завершилось ошибкой, можно доставить повторно
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param request body model.InboundEvent true "Событие"
+// @Success 200 {object} model.InboundEventResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/inbound [post]
+func (h *InboundHandler) ConsumeEvent(c *gin.Context) {
+	var event model.InboundEvent
+	if err := bindBody(c, &event); err != nil {
+		respondError(c, h.logger, err, "Invalid inbound event")
+		return
+	}
+
+	payload, err := event.DecodeData()
+	if err == nil {
+		if err = validateItem(c, payload); err != nil {
+			err = nestedBodyError(payload, err, "data")
+		}
+	}
+	if err != nil {
+		respondError(c, h.logger, err, "Invalid inbound event data",
+			"source", event.Source,
+			"event_id", event.ID,
+			"type", event.Type,
+		)
+		return
+	}
+
+	result, err := h.service.Consume(c.Request.Context(), event)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to consume inbound event",
+			"source", event.Source,
+			"event_id", event.ID,
+			"type", event.Type,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// ListDeduplicated возвращает недавно отброшенные повторы входящих событий
+// @Summary Отброшенные повторы входящих событий
+// @Description Возвращает события, повторные доставки которых были отброшены, начиная с последнего повтора: источник, идентификатор и тип события, затронутую подписку, время приема и обработки, число повторов и время последнего
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Максимум записей (по умолчанию 50, не больше 500)"
+// @Success 200 {array} model.InboundRecord
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/inbound-events/deduplicated [get]
+func (h *InboundHandler) ListDeduplicated(c *gin.Context) {
+	limit, err := parseLimit(c, defaultDeduplicatedLimit, maxDeduplicatedLimit)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	records, err := h.service.ListDeduplicated(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list deduplicated inbound events")
+		return
+	}
+
+	respond(c, http.StatusOK, records)
+}
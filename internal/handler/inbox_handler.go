@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+type InboxHandler struct {
+	service service.InboxService
+	logger  *logger.Logger
+}
+
+func NewInboxHandler(service service.InboxService, logger *logger.Logger) *InboxHandler {
+	return &InboxHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты центра уведомлений в группе API
+func (h *InboxHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/users/:id/notifications", h.List)
+	api.POST("/users/:id/notifications/read-all", h.MarkAllRead)
+	api.POST("/users/:id/notifications/:notification_id/read", h.MarkRead)
+}
+
+// List возвращает уведомления пользователя
+// @Summary Уведомления пользователя
+// @Description Возвращает уведомления центра уведомлений, начиная с последних: скорое окончание или продление подписки (expiring_soon), повышение цены (price_increase) и аномальные траты (spend_anomaly). unread - число непрочитанных уведомлений пользователя независимо от фильтра
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param unread query bool false "Только непрочитанные"
+// @Param limit query int false "Размер страницы (по умолчанию 20, не больше 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} model.InboxPage
+// @Header 200 {integer} X-Total-Count "Количество уведомлений под фильтром"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notifications [get]
+func (h *InboxHandler) List(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter model.InboxFilter
+	if unread := c.Query("unread"); unread != "" {
+		var err error
+		if filter.UnreadOnly, err = strconv.ParseBool(unread); err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "invalid unread: expected true or false")
+			return
+		}
+	}
+	page, err := parsePagination(c, defaultInboxLimit, maxInboxLimit)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	result, err := h.service.List(c.Request.Context(), userID, filter, page)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list notifications",
+			"user_id", userID,
+		)
+		return
+	}
+
+	setPaginationHeaders(c, page, result.Total)
+	respond(c, http.StatusOK, result)
+}
+
+// MarkRead отмечает уведомление прочитанным
+// @Summary Отметить уведомление прочитанным
+// @Description Повторная отметка не меняет read_at
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param notification_id path string true "ID уведомления"
+// @Success 200 {object} model.InboxNotification
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notifications/{notification_id}/read [post]
+func (h *InboxHandler) MarkRead(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	id, err := parseUUID(c, c.Param("notification_id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid notification ID format",
+			"notification_id", c.Param("notification_id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid notification ID")
+		return
+	}
+
+	n, err := h.service.MarkRead(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to mark notification as read",
+			"user_id", userID,
+			"notification_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, n)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+// @Summary Отметить все уведомления прочитанными
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.MarkAllReadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notifications/read-all [post]
+func (h *InboxHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to mark notifications as read",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, model.MarkAllReadResponse{Marked: marked})
+}
+
+func (h *InboxHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
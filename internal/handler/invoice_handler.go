@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/i18n"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvoiceHandler struct {
+	service service.InvoiceService
+	logger  *logger.Logger
+}
+
+func NewInvoiceHandler(service service.InvoiceService, logger *logger.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты счетов в группе API
+func (h *InvoiceHandler) RegisterRoutes(api gin.IRouter) {
+	api.POST("/users/:id/invoices", h.GenerateInvoice)
+	api.GET("/users/:id/invoices", h.ListInvoices)
+	api.GET("/invoices/:id", h.GetInvoice)
+	api.GET("/invoices/:id/export", h.ExportInvoice)
+}
+
+// GenerateInvoice выставляет счет пользователю за месяц
+// @Summary Выставить счет за месяц
+// @Description Создает счет со строкой по каждой подписке, активной в месяце: стоимость месяца (для годовой предоплаты - ее двенадцатая часть), скидки и разложение по налогу. Повторный счет за тот же месяц не создается
+// @Tags invoices
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param period query string true "Месяц счета (MM-YYYY)"
+// @Success 201 {object} model.Invoice
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/invoices [post]
+func (h *InvoiceHandler) GenerateInvoice(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "period is required")
+		return
+	}
+
+	invoice, err := h.service.GenerateInvoice(c.Request.Context(), userID, period)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to generate invoice",
+			"user_id", userID,
+			"period", period,
+		)
+		return
+	}
+
+	respond(c, http.StatusCreated, invoice)
+}
+
+// ListInvoices возвращает счета пользователя
+// @Summary Счета пользователя
+// @Description Возвращает счета пользователя без строк, начиная с последнего месяца
+// @Tags invoices
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} model.Invoice
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/invoices [get]
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := h.service.ListInvoices(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list invoices",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, invoices)
+}
+
+// GetInvoice возвращает счет со строками
+// @Summary Получить счет
+// @Tags invoices
+// @Produce json
+// @Param id path string true "ID счета"
+// @Success 200 {object} model.Invoice
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	invoice, ok := h.getInvoice(c)
+	if !ok {
+		return
+	}
+
+	respond(c, http.StatusOK, invoice)
+}
+
+// ExportInvoice выгружает строки счета в CSV
+// @Summary Выгрузка счета
+// @Description Выгружает строки счета в CSV; последняя строка содержит итоги с подписью месяца на языке из Accept-Language (ru или en, по умолчанию REPORT_LOCALE), например "Итого: Январь 2025"
+// @Tags invoices
+// @Produce text/csv
+// @Param id path string true "ID счета"
+// @Param Accept-Language header string false "Язык подписей: ru или en"
+// @Success 200 {string} string "CSV с заголовком"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/export [get]
+func (h *InvoiceHandler) ExportInvoice(c *gin.Context) {
+	invoice, ok := h.getInvoice(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Language", string(ctxutil.Locale(c.Request.Context())))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="invoice-%s-%s.csv"`,
+		invoice.UserID, invoice.Period.Format("01-2006")))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	records := [][]string{invoiceExportHeader}
+	for _, line := range invoice.Lines {
+		records = append(records, []string{
+			line.SubscriptionID.String(),
+			escapeCSVFormula(line.ServiceName),
+			strconv.Itoa(line.BaseAmount),
+			strconv.Itoa(line.DiscountAmount),
+			strconv.Itoa(line.NetAmount),
+			strconv.Itoa(line.TaxAmount),
+			strconv.Itoa(line.GrossAmount),
+		})
+	}
+	// Итоговая строка - подпись для людей на языке запроса с названием месяца счета
+	locale := ctxutil.Locale(c.Request.Context())
+	records = append(records, []string{
+		"", i18n.Total(locale) + ": " + i18n.MonthYear(invoice.Period, locale),
+		strconv.Itoa(invoice.BaseTotal),
+		strconv.Itoa(invoice.DiscountTotal),
+		strconv.Itoa(invoice.NetTotal),
+		strconv.Itoa(invoice.TaxTotal),
+		strconv.Itoa(invoice.GrossTotal),
+	})
+
+	if err := w.WriteAll(records); err != nil {
+		h.logger.Error(c.Request.Context(), "Failed to write invoice export",
+			"invoice_id", invoice.ID,
+			"error", err,
+		)
+	}
+}
+
+var invoiceExportHeader = []string{
+	"subscription_id", "service_name", "base_amount", "discount_amount", "net_amount", "tax_amount", "gross_amount",
+}
+
+func (h *InvoiceHandler) getInvoice(c *gin.Context) (*model.Invoice, bool) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid invoice ID format",
+			"invoice_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid invoice ID")
+		return nil, false
+	}
+
+	invoice, err := h.service.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get invoice",
+			"invoice_id", id,
+		)
+		return nil, false
+	}
+
+	return invoice, true
+}
+
+func (h *InvoiceHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
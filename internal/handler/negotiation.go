@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+const msgpackEnabledKey = "msgpack_enabled"
+
+// multipartRoutes - маршруты (в виде gin FullPath), принимающие загрузку файлов
+// в multipart/form-data. Заполняется при регистрации маршрутов
+var multipartRoutes sync.Map
+
+// acceptMultipart разрешает маршруту path загрузку файлов в multipart/form-data
+func acceptMultipart(path string) {
+	multipartRoutes.Store(path, struct{}{})
+}
+
+// ContentNegotiation требует application/json (или MessagePack, если он включен)
+// в запросах с телом и отвечает 415 на остальные типы содержимого. multipart/form-data
+// принимается только маршрутами, зарегистрированными через acceptMultipart
+func ContentNegotiation(allowMsgpack bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(msgpackEnabledKey, allowMsgpack)
+
+		if !hasBody(c.Request) {
+			c.Next()
+			return
+		}
+
+		switch c.ContentType() {
+		case binding.MIMEJSON:
+		case binding.MIMEMultipartPOSTForm:
+			if _, ok := multipartRoutes.Load(c.FullPath()); !ok {
+				unsupportedMediaType(c)
+				return
+			}
+		case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+			if !allowMsgpack {
+				unsupportedMediaType(c)
+				return
+			}
+		default:
+			unsupportedMediaType(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Localization выбирает язык подписей в отчетах по заголовку Accept-Language;
+// без поддерживаемого языка используется fallback
+func Localization(fallback i18n.Locale) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"), fallback)
+		c.Request = c.Request.WithContext(ctxutil.WithLocale(c.Request.Context(), locale))
+		c.Next()
+	}
+}
+
+func hasBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return r.ContentLength != 0
+	default:
+		return false
+	}
+}
+
+func unsupportedMediaType(c *gin.Context) {
+	abortProblem(c, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+		fmt.Sprintf("unsupported Content-Type %q, use %s", c.ContentType(), binding.MIMEJSON))
+}
+
+func msgpackEnabled(c *gin.Context) bool {
+	return c.GetBool(msgpackEnabledKey)
+}
+
+// bindBody разбирает тело запроса в формате JSON или MessagePack по Content-Type
+// и проверяет версии UUID в его полях
+func bindBody(c *gin.Context, obj interface{}) error {
+	var err error
+	switch c.ContentType() {
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		if msgpackEnabled(c) {
+			err = c.ShouldBindWith(obj, binding.MsgPack)
+			break
+		}
+		fallthrough
+	default:
+		err = c.ShouldBindJSON(obj)
+	}
+	if err != nil {
+		return bodyError(obj, err)
+	}
+	return checkBodyUUIDs(c, obj)
+}
+
+// wantsMsgpack сообщает, что клиент предпочитает MessagePack в заголовке Accept
+func wantsMsgpack(c *gin.Context) bool {
+	if !msgpackEnabled(c) {
+		return false
+	}
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEMSGPACK2, binding.MIMEMSGPACK) {
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		return true
+	default:
+		return false
+	}
+}
+
+// respond пишет ответ в формате, согласованном по заголовку Accept (JSON по умолчанию)
+func respond(c *gin.Context, status int, obj interface{}) {
+	if wantsMsgpack(c) {
+		c.Render(status, render.MsgPack{Data: obj})
+		return
+	}
+	if c.GetString(dateFormatKey) == DateFormatISO {
+		if body, err := json.Marshal(obj); err == nil {
+			c.Data(status, binding.MIMEJSON+"; charset=utf-8", isoMonths(body))
+			return
+		}
+	}
+	c.JSON(status, obj)
+}
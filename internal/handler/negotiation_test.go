@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/i18n"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ugorji/go/codec"
+)
+
+func TestMsgpackNegotiation(t *testing.T) {
+	var handle codec.MsgpackHandle
+	handle.RawToString = true
+
+	var body []byte
+	req := map[string]interface{}{
+		"service_name": "Yandex Plus",
+		"monthly_cost": 400,
+		// В MessagePack UUID передается 16 байтами (bin), а не строкой
+		"user_id":    uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+		"start_date": "07-2025",
+	}
+	if err := codec.NewEncoderBytes(&body, &handle).Encode(req); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &mockService{
+		createFn: func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+			if req.ServiceName != "Yandex Plus" || req.MonthlyCost != 400 || req.StartDate != "07-2025" {
+				t.Errorf("unexpected request passed to service: %+v", req)
+			}
+			return fixtureSubscription(), nil
+		},
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/msgpack")
+	httpReq.Header.Set("Accept", "application/msgpack")
+	rec := httptest.NewRecorder()
+
+	newTestRouter(svc).ServeHTTP(rec, httpReq)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/msgpack; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want msgpack", got)
+	}
+
+	var resp map[string]interface{}
+	if err := codec.NewDecoderBytes(rec.Body.Bytes(), &handle).Decode(&resp); err != nil {
+		t.Fatalf("response is not valid msgpack: %v", err)
+	}
+	if resp["service_name"] != "Yandex Plus" {
+		t.Errorf("service_name = %v, want Yandex Plus", resp["service_name"])
+	}
+}
+
+func TestLocalization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/report", handler.Localization(i18n.EN), func(c *gin.Context) {
+		c.String(http.StatusOK, string(ctxutil.Locale(c.Request.Context())))
+	})
+
+	for header, want := range map[string]string{
+		"":                          "en",
+		"ru-RU,ru;q=0.9":            "ru",
+		"de-DE, ru;q=0.4, en;q=0.6": "en",
+		"fr":                        "en",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		req.Header.Set("Accept-Language", header)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Body.String() != want {
+			t.Errorf("Accept-Language %q: locale = %q, want %q", header, rec.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	service service.NotificationService
+	logger  *logger.Logger
+}
+
+func NewNotificationHandler(service service.NotificationService, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты настроек уведомлений в группе API
+func (h *NotificationHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/users/:id/notification-preferences", h.GetPreferences)
+	api.PUT("/users/:id/notification-preferences", h.SavePreferences)
+	api.DELETE("/users/:id/notification-preferences", h.DeletePreferences)
+}
+
+// GetPreferences возвращает настройки уведомлений пользователя
+// @Summary Настройки уведомлений
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.NotificationPreferences
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notification-preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get notification preferences",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, prefs)
+}
+
+// SavePreferences задает адрес и виды писем пользователя
+// @Summary Сохранить настройки уведомлений
+// @Description Создает или заменяет настройки уведомлений: адрес писем и виды писем - напоминания о продлении и письма об отмене подписки. Не указанные виды включены. Пользователь без настроек писем не получает
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body model.SaveNotificationPreferencesRequest true "Настройки уведомлений"
+// @Success 200 {object} model.NotificationPreferences
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notification-preferences [put]
+func (h *NotificationHandler) SavePreferences(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SaveNotificationPreferencesRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	prefs, err := h.service.SavePreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to save notification preferences",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, prefs)
+}
+
+// DeletePreferences удаляет настройки уведомлений: пользователь перестает получать письма
+// @Summary Удалить настройки уведомлений
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/notification-preferences [delete]
+func (h *NotificationHandler) DeletePreferences(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePreferences(c.Request.Context(), userID); err != nil {
+		respondError(c, h.logger, err, "Failed to delete notification preferences",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "notification preferences deleted successfully"})
+}
+
+func (h *NotificationHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// notificationRepoStub хранит настройки уведомлений в памяти
+type notificationRepoStub struct {
+	prefs map[uuid.UUID]*model.NotificationPreferences
+}
+
+func (r *notificationRepoStub) Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	return r.prefs[userID], nil
+}
+
+func (r *notificationRepoStub) Save(ctx context.Context, prefs *model.NotificationPreferences) error {
+	r.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+func (r *notificationRepoStub) Delete(ctx context.Context, userID uuid.UUID) error {
+	delete(r.prefs, userID)
+	return nil
+}
+
+// TestNotificationPreferencesOwnership проверяет запросы пользователя без прав
+// администратора к настройкам уведомлений через настоящий сервис: чужие настройки ему
+// недоступны, иначе он перенаправил бы чужие письма на свой адрес
+func TestNotificationPreferencesOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+	self := uuid.New()
+	other := uuid.New()
+
+	repo := &notificationRepoStub{prefs: map[uuid.UUID]*model.NotificationPreferences{
+		other: {UserID: other, Email: "other@example.com", RenewalReminders: true, Cancellations: true},
+	}}
+	svc := service.NewNotificationService(repo, nil, nil, log)
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), auth.Caller{UserID: self}))
+	})
+	handler.NewNotificationHandler(svc, log).RegisterRoutes(api)
+
+	const body = `{"email": "attacker@example.com"}`
+	otherPath := "/api/v1/users/" + other.String() + "/notification-preferences"
+	selfPath := "/api/v1/users/" + self.String() + "/notification-preferences"
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"get foreign", http.MethodGet, otherPath, "", http.StatusForbidden},
+		{"save foreign", http.MethodPut, otherPath, body, http.StatusForbidden},
+		{"delete foreign", http.MethodDelete, otherPath, "", http.StatusForbidden},
+		{"get own missing", http.MethodGet, selfPath, "", http.StatusNotFound},
+		{"save own", http.MethodPut, selfPath, `{"email": "self@example.com"}`, http.StatusOK},
+		{"get own", http.MethodGet, selfPath, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	// Чужие настройки не изменились
+	if prefs := repo.prefs[other]; prefs == nil || prefs.Email != "other@example.com" {
+		t.Errorf("foreign preferences changed: %+v", prefs)
+	}
+}
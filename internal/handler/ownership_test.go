@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Заглушки репозиториев отдают данные другого пользователя; остальные методы не
+// вызываются, а их вызов завершает запрос ошибкой 500
+type auditRepoStub struct {
+	repository.AuditRepository
+	entry *model.AuditEntry
+}
+
+func (r *auditRepoStub) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	if filter.UserID != nil && *filter.UserID != r.entry.UserID {
+		return nil, nil
+	}
+	return []*model.AuditEntry{r.entry}, nil
+}
+
+type invoiceRepoStub struct {
+	repository.InvoiceRepository
+	invoice *model.Invoice
+}
+
+func (r *invoiceRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error) {
+	if id != r.invoice.ID {
+		return nil, nil
+	}
+	return r.invoice, nil
+}
+
+type discountRepoStub struct {
+	repository.DiscountRepository
+	discount *model.Discount
+}
+
+func (r *discountRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Discount, error) {
+	if id != r.discount.ID {
+		return nil, nil
+	}
+	return r.discount, nil
+}
+
+// TestCrossUserAccess проходит по всем маршрутам API с пользователем (/users/:id) или
+// ресурсом пользователя по ID и проверяет, что пользователь без прав администратора
+// получает 403 или 404 на данные другого пользователя. Новый маршрут с параметром без
+// строки в таблице или в списке общих маршрутов тоже завершает тест ошибкой
+func TestCrossUserAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	log := logger.New(slog.LevelError + 4)
+	self := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	other := uuid.New()
+
+	subscriptions := repository.NewInMemorySubscriptionRepository()
+	foreign := &model.Subscription{ServiceName: "Netflix", MonthlyCost: 500, UserID: other, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Status: model.StatusActive}
+	draft := &model.Subscription{ServiceName: "Spotify", MonthlyCost: 300, UserID: other, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Status: model.StatusActive, IsDraft: true}
+	for _, sub := range []*model.Subscription{foreign, draft} {
+		if err := subscriptions.Create(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invoice := &model.Invoice{ID: uuid.New(), UserID: other, Period: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)}
+	discount := &model.Discount{ID: uuid.New(), Kind: model.DiscountFixed, Value: 100, UserID: &other}
+	audit := &model.AuditEntry{ID: 1, EntityType: "subscription", EntityID: foreign.ID, UserID: other, Action: "create"}
+
+	tax, _ := money.NewTax("0", true, "half_up")
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), auth.Caller{UserID: self}))
+	})
+	for _, h := range []interface{ RegisterRoutes(gin.IRouter) }{
+		handler.NewSubscriptionHandler(service.NewSubscriptionService(subscriptions, service.FixedTax(tax), nil, nil, log), testAdminToken, log),
+		handler.NewAnomalyHandler(service.NewAnomalyService(subscriptions, service.AnomalyConfig{ThresholdPercent: 50, LookbackMonths: 3}, nil, nil, log), log),
+		handler.NewSpendHandler(service.NewSparklineService(subscriptions, time.Minute, log), log),
+		handler.NewDataQualityHandler(service.NewDataQualityService(subscriptions, log), log),
+		handler.NewAPITokenHandler(service.NewAPITokenService(nil, log), log),
+		handler.NewAuditHandler(service.NewAuditService(&auditRepoStub{entry: audit}, log), testAdminToken, log),
+		handler.NewDiscountHandler(service.NewDiscountService(&discountRepoStub{discount: discount}, subscriptions, log), log),
+		handler.NewInvoiceHandler(service.NewInvoiceService(&invoiceRepoStub{invoice: invoice}, subscriptions, service.FixedTax(tax), log), log),
+		handler.NewInboxHandler(service.NewInboxService(nil, log), log),
+		handler.NewNotificationHandler(service.NewNotificationService(nil, nil, nil, log), log),
+		handler.NewReminderHandler(service.NewReminderService(nil, subscriptions, service.ReminderConfig{}, nil, log), log),
+		// Маршруты без данных пользователя: регистрируются, чтобы их параметры попали в проверку
+		handler.NewCatalogHandler(nil, log),
+		handler.NewTagHandler(nil, log),
+		handler.NewTemplateHandler(nil, testAdminToken, log),
+		handler.NewEventSchemaHandler(nil, log),
+		handler.NewBackupHandler(nil, testAdminToken, log),
+		handler.NewTeardownHandler(nil, testAdminToken, log),
+		handler.NewTenantHandler(nil, testAdminToken, log),
+	} {
+		h.RegisterRoutes(api)
+	}
+
+	// shared - маршруты с параметром без данных отдельного пользователя
+	shared := map[string]string{
+		"/api/v1/services/:id":                   "каталог сервисов общий для организации",
+		"/api/v1/tags/:id":                       "теги общие для организации",
+		"/api/v1/templates/:name":                "шаблоны писем общие, изменяет администратор",
+		"/api/v1/templates/:name/versions":       "шаблоны писем общие",
+		"/api/v1/templates/:name/preview":        "требует ADMIN_TOKEN",
+		"/api/v1/event-schemas/:type/:version":   "схемы событий общие",
+		"/api/v1/subscriptions/:id/transfer":     "требует ADMIN_TOKEN",
+		"/api/v1/admin/backups/:id":              "требует ADMIN_TOKEN",
+		"/api/v1/admin/tenants/:tenant/teardown": "требует ADMIN_TOKEN",
+		"/api/v1/admin/tenants/:tenant":          "требует ADMIN_TOKEN",
+	}
+
+	users := "/api/v1/users/" + other.String()
+	subscription := "/api/v1/subscriptions/" + foreign.ID.String()
+	otherDiscount := `{"kind":"percent","value":100,"user_id":"` + other.String() + `","start_date":"07-2025"}`
+	tests := []struct {
+		method     string
+		route      string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{http.MethodGet, "/api/v1/subscriptions/:id", subscription, "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/subscriptions/:id", subscription, validCreateBody, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/subscriptions/:id", subscription, "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/subscriptions/:id/prices", subscription + "/prices", "", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/subscriptions/:id/activate", "/api/v1/subscriptions/" + draft.ID.String() + "/activate", "", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/subscriptions/:id/cancel", subscription + "/cancel", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/subscriptions/:id/reminder-settings", subscription + "/reminder-settings", "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/subscriptions/:id/reminder-settings", subscription + "/reminder-settings", `{"days":3}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/subscriptions/:id/reminder-settings", subscription + "/reminder-settings", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/users/:id/services", users + "/services", "", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/users/:id/subscriptions:method", users + "/subscriptions:sync", `{"subscriptions":[]}`, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/anomalies", users + "/anomalies", "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/spend/sparkline", users + "/spend/sparkline", "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/data-quality", users + "/data-quality", "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/users/:id/tokens", users + "/tokens", `{"name":"ci","scopes":["read:subscriptions"]}`, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/tokens", users + "/tokens", "", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/users/:id/tokens/:token_id", users + "/tokens/" + uuid.NewString(), "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/discounts", "/api/v1/discounts", otherDiscount, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/discounts", "/api/v1/discounts?user_id=" + other.String(), "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/discounts/:id", "/api/v1/discounts/" + discount.ID.String(), "", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/discounts/:id", "/api/v1/discounts/" + discount.ID.String(), otherDiscount, http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/discounts/:id", "/api/v1/discounts/" + discount.ID.String(), "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/users/:id/invoices", users + "/invoices?period=07-2025", "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/invoices", users + "/invoices", "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/invoices/:id", "/api/v1/invoices/" + invoice.ID.String(), "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/invoices/:id/export", "/api/v1/invoices/" + invoice.ID.String() + "/export", "", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/users/:id/notifications", users + "/notifications", "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/users/:id/notifications/read-all", users + "/notifications/read-all", "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/users/:id/notifications/:notification_id/read", users + "/notifications/" + uuid.NewString() + "/read", "", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/users/:id/notification-preferences", users + "/notification-preferences", "", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/users/:id/notification-preferences", users + "/notification-preferences", `{"email":"attacker@example.com"}`, http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/users/:id/notification-preferences", users + "/notification-preferences", "", http.StatusForbidden},
+	}
+
+	covered := make(map[string]bool, len(tests))
+	for _, tt := range tests {
+		covered[tt.method+" "+tt.route] = true
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	// История чужой подписки отвечает 200, но без ее изменений: пользователь видит только
+	// изменения, после которых подписка принадлежала ему
+	covered[http.MethodGet+" /api/v1/subscriptions/:id/history"] = true
+	req := httptest.NewRequest(http.MethodGet, subscription+"/history", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("history of foreign subscription = %d %s, want empty list", rec.Code, rec.Body.String())
+	}
+
+	for _, route := range router.Routes() {
+		if !strings.Contains(route.Path, ":") || covered[route.Method+" "+route.Path] {
+			continue
+		}
+		if _, ok := shared[route.Path]; !ok {
+			t.Errorf("%s %s is not covered by the cross-user table", route.Method, route.Path)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// parsePagination читает limit и offset из query-параметров
+func parsePagination(c *gin.Context, defaultLimit, maxLimit int) (model.Pagination, error) {
+	limit, err := parseLimit(c, defaultLimit, maxLimit)
+	if err != nil {
+		return model.Pagination{}, err
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return model.Pagination{}, fmt.Errorf("offset must be a non-negative integer")
+	}
+
+	return model.Pagination{Limit: limit, Offset: offset}, nil
+}
+
+// TotalCountEstimatedHeader - заголовок ответа списка, X-Total-Count которого может быть оценкой
+const TotalCountEstimatedHeader = "X-Total-Count-Estimated"
+
+// parseCountMode читает режим подсчета общего количества из параметра count;
+// без параметра возвращает пустой режим - точный подсчет
+func parseCountMode(c *gin.Context) (string, error) {
+	switch mode := c.Query("count"); mode {
+	case "", model.CountExact, model.CountEstimate:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("count must be exact or estimate")
+	}
+}
+
+// setPaginationHeaders добавляет X-Total-Count и Link (RFC 8288) со ссылками на соседние страницы
+func setPaginationHeaders(c *gin.Context, page model.Pagination, total int) {
+	c.Header("X-Total-Count", strconv.Itoa(total))
+
+	var links []string
+	if page.Offset+page.Limit < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, page.Limit, page.Offset+page.Limit)))
+	}
+	if page.Offset > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, page.Limit, max(page.Offset-page.Limit, 0))))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageURL(c *gin.Context, limit, offset int) string {
+	query := c.Request.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// parseLimit читает limit из query-параметров
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) (int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return limit, nil
+}
+
+// encodeCursor упаковывает позицию в непрозрачную для клиента строку
+func encodeCursor(cursor model.SubscriptionCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor разбирает курсор, выданный encodeCursor. Пустая строка означает начало списка
+func decodeCursor(s string) (*model.SubscriptionCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+
+	createdAtStr, idStr, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &model.SubscriptionCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// setCursorHeaders добавляет X-Next-Cursor и Link на следующую страницу, если она есть
+func setCursorHeaders(c *gin.Context, next *model.SubscriptionCursor) {
+	if next == nil {
+		return
+	}
+
+	cursor := encodeCursor(*next)
+	c.Header("X-Next-Cursor", cursor)
+
+	query := c.Request.URL.Query()
+	query.Set("cursor", cursor)
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.String()))
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// MIMEProblemJSON - тип содержимого описания ошибки по RFC 7807
+const MIMEProblemJSON = "application/problem+json"
+
+// problemTypePrefix - префикс URI типа проблемы; тип строится из кода ошибки
+const problemTypePrefix = "urn:subscription-service:problem:"
+
+// Коды ошибок API. Коды стабильны: клиенты различают ошибки по ним, а не по тексту detail
+const (
+	// Ошибки запроса
+	CodeValidationFailed      = "VALIDATION_FAILED"
+	CodeMalformedBody         = "MALFORMED_BODY"
+	CodeInvalidID             = "INVALID_ID"
+	CodeInvalidParameter      = "INVALID_PARAMETER"
+	CodeInvalidFilter         = "INVALID_FILTER"
+	CodeInvalidPeriod         = "INVALID_PERIOD"
+	CodeInvalidPeriodFormat   = "INVALID_PERIOD_FORMAT"
+	CodeInvalidTenant         = "INVALID_TENANT"
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeInvalidRequest        = "INVALID_REQUEST"
+	CodePayloadTooLarge       = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType  = "UNSUPPORTED_MEDIA_TYPE"
+
+	// Доступ
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeRateLimited  = "RATE_LIMITED"
+
+	// Ресурсы
+	CodeNotFound                        = "NOT_FOUND"
+	CodeMethodNotAllowed                = "METHOD_NOT_ALLOWED"
+	CodeSubscriptionNotFound            = "SUBSCRIPTION_NOT_FOUND"
+	CodeDiscountNotFound                = "DISCOUNT_NOT_FOUND"
+	CodeTemplateNotFound                = "TEMPLATE_NOT_FOUND"
+	CodeInvoiceNotFound                 = "INVOICE_NOT_FOUND"
+	CodeNotificationPreferencesNotFound = "NOTIFICATION_PREFERENCES_NOT_FOUND"
+	CodeNotificationNotFound            = "NOTIFICATION_NOT_FOUND"
+	CodeEventSchemaNotFound             = "EVENT_SCHEMA_NOT_FOUND"
+	CodeServiceNotFound                 = "SERVICE_NOT_FOUND"
+	CodeTagNotFound                     = "TAG_NOT_FOUND"
+	CodeBackupNotFound                  = "BACKUP_NOT_FOUND"
+	CodeReminderSettingsNotFound        = "REMINDER_SETTINGS_NOT_FOUND"
+	CodeAPITokenNotFound                = "API_TOKEN_NOT_FOUND"
+	CodeGroupMappingNotFound            = "GROUP_MAPPING_NOT_FOUND"
+
+	// Конфликты состояния
+	CodeInvalidStatusTransition   = "INVALID_STATUS_TRANSITION"
+	CodeSubscriptionAlreadyActive = "SUBSCRIPTION_ALREADY_ACTIVE"
+	CodeTransferNotAllowed        = "TRANSFER_NOT_ALLOWED"
+	CodeInvoiceAlreadyExists      = "INVOICE_ALREADY_EXISTS"
+	CodeIdempotencyKeyInUse       = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused      = "IDEMPOTENCY_KEY_REUSED"
+	CodeServiceAlreadyExists      = "SERVICE_ALREADY_EXISTS"
+	CodeTagAlreadyExists          = "TAG_ALREADY_EXISTS"
+	CodeSyncConflict              = "SYNC_CONFLICT"
+	CodeInboundEventInProgress    = "INBOUND_EVENT_IN_PROGRESS"
+
+	// Ошибки сервера
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+)
+
+// ErrorResponse - описание ошибки по RFC 7807 (application/problem+json)
+type ErrorResponse struct {
+	// Type - URI типа проблемы, однозначно соответствует Code
+	Type   string `json:"type" example:"urn:subscription-service:problem:subscription-not-found"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail" example:"subscription not found"`
+	// Code - стабильный машиночитаемый код ошибки
+	Code string `json:"code" example:"SUBSCRIPTION_NOT_FOUND"`
+	// RequestID - идентификатор запроса для поиска в журналах
+	RequestID string `json:"request_id,omitempty" example:"5f0c6c1e4b7a4d0e9a3c2b1d8e7f6a5b"`
+	// Errors - поля и параметры запроса с некорректными значениями
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// errorRule сопоставляет ошибку сервиса, для которой errors.Is(err, target), статусу и коду ответа
+type errorRule struct {
+	target error
+	status int
+	code   string
+}
+
+// errorRules - единая таблица ошибок сервисов. Правила проверяются по порядку,
+// поэтому конкретные виды ошибок данных стоят раньше общего model.ErrInvalidInput
+var errorRules = []errorRule{
+	{service.ErrSubscriptionNotFound, http.StatusNotFound, CodeSubscriptionNotFound},
+	{service.ErrDiscountNotFound, http.StatusNotFound, CodeDiscountNotFound},
+	{service.ErrTemplateNotFound, http.StatusNotFound, CodeTemplateNotFound},
+	{service.ErrInvoiceNotFound, http.StatusNotFound, CodeInvoiceNotFound},
+	{service.ErrNotificationPreferencesNotFound, http.StatusNotFound, CodeNotificationPreferencesNotFound},
+	{service.ErrNotificationNotFound, http.StatusNotFound, CodeNotificationNotFound},
+	{service.ErrCatalogServiceNotFound, http.StatusNotFound, CodeServiceNotFound},
+	{service.ErrTagNotFound, http.StatusNotFound, CodeTagNotFound},
+	{service.ErrBackupNotFound, http.StatusNotFound, CodeBackupNotFound},
+	{service.ErrReminderSettingsNotFound, http.StatusNotFound, CodeReminderSettingsNotFound},
+	{service.ErrAPITokenNotFound, http.StatusNotFound, CodeAPITokenNotFound},
+	{service.ErrGroupMappingNotFound, http.StatusNotFound, CodeGroupMappingNotFound},
+
+	{service.ErrInvalidStatusTransition, http.StatusConflict, CodeInvalidStatusTransition},
+	{service.ErrSubscriptionAlreadyActive, http.StatusConflict, CodeSubscriptionAlreadyActive},
+	{service.ErrTransferNotAllowed, http.StatusConflict, CodeTransferNotAllowed},
+	{service.ErrInvoiceAlreadyExists, http.StatusConflict, CodeInvoiceAlreadyExists},
+	{service.ErrIdempotencyKeyInUse, http.StatusConflict, CodeIdempotencyKeyInUse},
+	{service.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused},
+	{service.ErrCatalogServiceExists, http.StatusConflict, CodeServiceAlreadyExists},
+	{service.ErrTagExists, http.StatusConflict, CodeTagAlreadyExists},
+	{service.ErrSyncConflict, http.StatusConflict, CodeSyncConflict},
+	{service.ErrInboundEventInProgress, http.StatusConflict, CodeInboundEventInProgress},
+
+	{model.ErrInvalidPeriodFormat, http.StatusBadRequest, CodeInvalidPeriodFormat},
+	{model.ErrInvalidPeriod, http.StatusBadRequest, CodeInvalidPeriod},
+	{service.ErrInvalidIdempotencyKey, http.StatusBadRequest, CodeInvalidIdempotencyKey},
+	{tenant.ErrInvalidTenant, http.StatusBadRequest, CodeInvalidTenant},
+	{model.ErrInvalidInput, http.StatusBadRequest, CodeInvalidRequest},
+
+	{auth.ErrForbidden, http.StatusForbidden, CodeForbidden},
+}
+
+// requestError - ошибка разбора тела запроса; отвечает 400 с кодом code
+type requestError struct {
+	code   string
+	err    error
+	fields []FieldError
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+
+func (e *requestError) Unwrap() error { return e.err }
+
+// classify возвращает статус и код ответа на ошибку
+func classify(err error) (int, string, []FieldError) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return http.StatusBadRequest, reqErr.code, reqErr.fields
+	}
+
+	for _, rule := range errorRules {
+		if errors.Is(err, rule.target) {
+			return rule.status, rule.code, nil
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal, nil
+}
+
+// newProblem собирает описание ошибки запроса c
+func newProblem(c *gin.Context, status int, code, detail string, fields []FieldError) ErrorResponse {
+	return ErrorResponse{
+		Type:      problemTypePrefix + strings.ToLower(strings.ReplaceAll(code, "_", "-")),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: ctxutil.RequestID(c.Request.Context()),
+		Errors:    fields,
+	}
+}
+
+// errorProblem описывает ошибку по таблице errorRules
+func errorProblem(c *gin.Context, err error) ErrorResponse {
+	status, code, fields := classify(err)
+	return newProblem(c, status, code, err.Error(), fields)
+}
+
+// respondProblem отвечает описанием ошибки: application/problem+json, а клиенту,
+// согласовавшему MessagePack, - в MessagePack
+func respondProblem(c *gin.Context, status int, code, detail string, fields ...FieldError) {
+	writeProblem(c, newProblem(c, status, code, detail, fields))
+}
+
+func writeProblem(c *gin.Context, problem ErrorResponse) {
+	if wantsMsgpack(c) {
+		c.Render(problem.Status, render.MsgPack{Data: problem})
+		return
+	}
+	body, err := json.Marshal(problem)
+	if err != nil {
+		c.Status(problem.Status)
+		return
+	}
+	c.Data(problem.Status, MIMEProblemJSON, body)
+}
+
+// abortProblem отвечает описанием ошибки и прерывает цепочку обработчиков
+func abortProblem(c *gin.Context, status int, code, detail string) {
+	respondProblem(c, status, code, detail)
+	c.Abort()
+}
+
+// AbortProblem отвечает описанием ошибки из обработчиков вне пакета (404, 405 роутера)
+func AbortProblem(c *gin.Context, status int, code, detail string) {
+	abortProblem(c, status, code, detail)
+}
+
+// respondError отвечает на ошибку по таблице errorRules и пишет ее в журнал с сообщением
+// msg: ошибки клиента - Warn, остальные - Error
+func respondError(c *gin.Context, log *logger.Logger, err error, msg string, args ...interface{}) {
+	problem := errorProblem(c, err)
+
+	args = append(args, "error", err, "code", problem.Code)
+	if problem.Status >= http.StatusInternalServerError {
+		log.Error(c.Request.Context(), msg, args...)
+	} else {
+		log.Warn(c.Request.Context(), msg, args...)
+	}
+
+	writeProblem(c, problem)
+}
+
+// bodyError оборачивает ошибку разбора тела obj: ошибки валидации - с полями
+// в именах JSON, ошибки типов - с путем поля
+func bodyError(obj interface{}, err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, e := range validationErrs {
+			reason := e.Tag()
+			if e.Param() != "" {
+				reason += "=" + e.Param()
+			}
+			fields = append(fields, FieldError{
+				Field:  jsonFieldPath(reflect.TypeOf(obj), e.StructNamespace()),
+				Value:  fmt.Sprint(e.Value()),
+				Reason: reason,
+			})
+		}
+		return &requestError{code: CodeValidationFailed, err: err, fields: fields}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &requestError{code: CodeValidationFailed, err: err, fields: []FieldError{{
+			Field:  typeErr.Field,
+			Value:  typeErr.Value,
+			Reason: "expected " + typeErr.Type.String(),
+		}}}
+	}
+
+	return &requestError{code: CodeMalformedBody, err: err}
+}
+
+// nestedBodyError оборачивает ошибку проверки obj, вложенного в тело запроса по пути path:
+// пути полей и сообщение начинаются с path
+func nestedBodyError(obj interface{}, err error, path string) error {
+	var reqErr *requestError
+	if !errors.As(err, &reqErr) {
+		errors.As(bodyError(obj, err), &reqErr)
+	}
+	for i := range reqErr.fields {
+		reqErr.fields[i].Field = path + "." + reqErr.fields[i].Field
+	}
+	reqErr.err = fmt.Errorf("%s: %w", path, reqErr.err)
+	return reqErr
+}
+
+// jsonFieldPath переводит путь поля валидатора (CreateSubscriptionRequest.StartDate,
+// BulkCreateRequest.Items[0].ServiceName) в путь из имен JSON (start_date, items[0].service_name).
+// Встроенные структуры в путь не попадают
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	var path []string
+	for _, segment := range segments[1:] {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		if t.Kind() != reflect.Struct {
+			path = append(path, segment)
+			continue
+		}
+
+		field, ok := t.FieldByName(name)
+		if !ok {
+			path = append(path, segment)
+			continue
+		}
+		t = field.Type
+		if field.Anonymous {
+			continue
+		}
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+			name = tag
+		}
+		path = append(path, name+index)
+	}
+	return strings.Join(path, ".")
+}
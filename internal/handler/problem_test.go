@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func TestProblemDetails(t *testing.T) {
+	activateErr := func(err error) *mockService {
+		return &mockService{
+			activateFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+				return nil, err
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		service    *mockService
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name:       "not found",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/activate",
+			service:    activateErr(service.ErrSubscriptionNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   handler.CodeSubscriptionNotFound,
+		},
+		{
+			name:       "conflict",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/activate",
+			service:    activateErr(service.ErrSubscriptionAlreadyActive),
+			wantStatus: http.StatusConflict,
+			wantCode:   handler.CodeSubscriptionAlreadyActive,
+		},
+		{
+			name:       "forbidden",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/activate",
+			service:    activateErr(fmt.Errorf("%w: subscription belongs to another user", auth.ErrForbidden)),
+			wantStatus: http.StatusForbidden,
+			wantCode:   handler.CodeForbidden,
+		},
+		{
+			name:   "period format",
+			method: http.MethodPost,
+			path:   "/api/v1/subscriptions",
+			body:   `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"2025-07"}`,
+			service: &mockService{
+				createFn: func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+					return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start date format, expected MM-YYYY: parsing time")
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   handler.CodeInvalidPeriodFormat,
+		},
+		{
+			name:       "validation",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"monthly_cost":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba"}`,
+			service:    &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   handler.CodeValidationFailed,
+			wantFields: []string{"service_name", "start_date"},
+		},
+		{
+			name:       "field type",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"service_name":"Yandex Plus","monthly_cost":"400"}`,
+			service:    &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   handler.CodeValidationFailed,
+			wantFields: []string{"monthly_cost"},
+		},
+		{
+			name:       "malformed",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"service_name":`,
+			service:    &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   handler.CodeMalformedBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			newTestRouter(tt.service).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != handler.MIMEProblemJSON {
+				t.Errorf("Content-Type = %q, want %s", got, handler.MIMEProblemJSON)
+			}
+
+			var problem handler.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.wantCode || problem.Status != tt.wantStatus || problem.Detail == "" {
+				t.Errorf("problem = %+v, want code %s", problem, tt.wantCode)
+			}
+			if want := "urn:subscription-service:problem:" + strings.ToLower(strings.ReplaceAll(tt.wantCode, "_", "-")); problem.Type != want {
+				t.Errorf("type = %q, want %q", problem.Type, want)
+			}
+
+			var fields []string
+			for _, field := range problem.Errors {
+				fields = append(fields, field.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRejectionLimit = 100
+	maxRejectionLimit     = 1000
+	// rejectionBodyLimit - сколько байт тела ответа с ошибкой читается для сообщения
+	rejectionBodyLimit = 4096
+)
+
+type RejectionHandler struct {
+	service service.RejectionService
+	logger  *logger.Logger
+}
+
+func NewRejectionHandler(service service.RejectionService, logger *logger.Logger) *RejectionHandler {
+	return &RejectionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты журнала отклоненных запросов в группе API
+func (h *RejectionHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/rejected-requests", h.ListRejections)
+}
+
+// rejectionWriter запоминает начало тела ответа с кодом 4xx, чтобы сохранить сообщение об ошибке
+type rejectionWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *rejectionWriter) Write(data []byte) (int, error) {
+	if status := w.Status(); status >= 400 && status < 500 && w.body.Len() < rejectionBodyLimit {
+		w.body.Write(data[:min(len(data), rejectionBodyLimit-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *rejectionWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Middleware записывает запросы на запись (POST, PUT, PATCH, DELETE), отклоненные с кодом 4xx:
+// ошибки валидации, отказы в доступе, превышение лимита запросов. Должен стоять первым
+// в группе API, чтобы видеть отказы остальных middleware
+func (h *RejectionHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		writer := &rejectionWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		status := writer.Status()
+		if status < 400 || status >= 500 {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		// Контекст запроса уже содержит пользователя и организацию, определенные middleware
+		h.service.Record(c.Request.Context(), model.RejectedRequest{
+			Method:  c.Request.Method,
+			Route:   route,
+			Status:  status,
+			Reason:  rejectionReason(status),
+			Message: rejectionMessage(writer.body.Bytes()),
+		})
+	}
+}
+
+// rejectionReason возвращает код причины отклонения по статусу ответа
+func rejectionReason(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return model.RejectionValidation
+	case http.StatusUnauthorized:
+		return model.RejectionUnauthenticated
+	case http.StatusForbidden:
+		return model.RejectionForbidden
+	case http.StatusNotFound:
+		return model.RejectionNotFound
+	case http.StatusConflict:
+		return model.RejectionConflict
+	case http.StatusRequestEntityTooLarge:
+		return model.RejectionTooLarge
+	case http.StatusTooManyRequests:
+		return model.RejectionRateLimited
+	default:
+		return model.RejectionOther
+	}
+}
+
+// rejectionMessage извлекает detail из ErrorResponse. Ответы в MessagePack
+// и обрезанные тела сохраняются без сообщения
+func rejectionMessage(body []byte) string {
+	var response ErrorResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+	return response.Detail
+}
+
+// ListRejections возвращает отклоненные запросы на запись
+// @Summary Отклоненные запросы
+// @Description Возвращает запросы на запись организации, отклоненные с кодом 4xx, начиная с последних: маршрут, код причины (validation, unauthenticated, forbidden, not_found, conflict, too_large, rate_limited, rejected) и сообщение об ошибке. Обычный пользователь видит только свои запросы
+// @Tags rejected-requests
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param reason query string false "Код причины"
+// @Param route query string false "Шаблон маршрута, например /api/v1/subscriptions"
+// @Param since query string false "Не раньше момента (RFC 3339)"
+// @Param limit query int false "Максимум записей (по умолчанию 100, не больше 1000)"
+// @Success 200 {array} model.RejectedRequest
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rejected-requests [get]
+func (h *RejectionHandler) ListRejections(c *gin.Context) {
+	filter := model.RejectionFilter{
+		Reason: c.Query("reason"),
+		Route:  c.Query("route"),
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := parseUUID(c, raw)
+		if err != nil {
+			h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+				"user_id", raw,
+				"error", err,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	if raw := c.Query("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			h.logger.Warn(c.Request.Context(), "Invalid since parameter",
+				"since", raw,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = &since
+	}
+
+	limit, err := parseLimit(c, defaultRejectionLimit, maxRejectionLimit)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	filter.Limit = limit
+
+	rejections, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list rejected requests")
+		return
+	}
+
+	respond(c, http.StatusOK, rejections)
+}
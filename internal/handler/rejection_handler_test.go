@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type rejectionServiceStub struct {
+	service.RejectionService
+	recorded []model.RejectedRequest
+}
+
+func (s *rejectionServiceStub) Record(ctx context.Context, rejection model.RejectedRequest) {
+	s.recorded = append(s.recorded, rejection)
+}
+
+func TestRejectionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &rejectionServiceStub{}
+
+	router := gin.New()
+	api := router.Group("/api/v1", handler.NewRejectionHandler(svc, logger.New(slog.LevelError+4)).Middleware())
+	api.POST("/subscriptions", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusBadRequest, handler.ErrorResponse{Detail: "price must be positive"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+	api.DELETE("/subscriptions/:id", func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, handler.ErrorResponse{Detail: "rate limit exceeded"})
+	})
+	api.GET("/subscriptions", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, handler.ErrorResponse{Detail: "invalid limit"})
+	})
+
+	for _, r := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/subscriptions?fail=1"},
+		{http.MethodPost, "/api/v1/subscriptions"},
+		{http.MethodDelete, "/api/v1/subscriptions/42"},
+		{http.MethodGet, "/api/v1/subscriptions"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(r.method, r.path, strings.NewReader("{}")))
+		if r.method == http.MethodPost && strings.Contains(r.path, "fail") && !strings.Contains(rec.Body.String(), "price must be positive") {
+			t.Errorf("response body was not passed through: %s", rec.Body.String())
+		}
+	}
+
+	want := []model.RejectedRequest{
+		{Method: http.MethodPost, Route: "/api/v1/subscriptions", Status: http.StatusBadRequest, Reason: model.RejectionValidation, Message: "price must be positive"},
+		{Method: http.MethodDelete, Route: "/api/v1/subscriptions/:id", Status: http.StatusTooManyRequests, Reason: model.RejectionRateLimited, Message: "rate limit exceeded"},
+	}
+	if len(svc.recorded) != len(want) {
+		t.Fatalf("recorded %d rejections, want %d: %+v", len(svc.recorded), len(want), svc.recorded)
+	}
+	for i := range want {
+		if svc.recorded[i] != want[i] {
+			t.Errorf("rejection %d = %+v, want %+v", i, svc.recorded[i], want[i])
+		}
+	}
+}
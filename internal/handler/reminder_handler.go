@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReminderHandler struct {
+	service service.ReminderService
+	logger  *logger.Logger
+}
+
+func NewReminderHandler(service service.ReminderService, logger *logger.Logger) *ReminderHandler {
+	return &ReminderHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты настроек напоминаний подписки в группе API
+func (h *ReminderHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/subscriptions/:id/reminder-settings", h.GetSettings)
+	api.PUT("/subscriptions/:id/reminder-settings", h.SaveSettings)
+	api.DELETE("/subscriptions/:id/reminder-settings", h.DeleteSettings)
+}
+
+// GetSettings возвращает настройки напоминаний подписки
+// @Summary Настройки напоминаний подписки
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} model.ReminderSettings
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/reminder-settings [get]
+func (h *ReminderHandler) GetSettings(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to get reminder settings",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, settings)
+}
+
+// SaveSettings задает срок и канал напоминаний подписки
+// @Summary Сохранить настройки напоминаний подписки
+// @Description Создает или заменяет настройки напоминаний о продлении подписки: за сколько дней (1-365) до окончания периода напоминать и единственный канал напоминаний (log, webhook, email или inbox). Незаданное значение - RENEWAL_REMINDER_DAYS или каналы RENEWAL_REMINDER_CHANNELS. Канал должен быть доступен: email - только с EMAIL_DRIVER
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body model.SaveReminderSettingsRequest true "Настройки напоминаний"
+// @Success 200 {object} model.ReminderSettings
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/reminder-settings [put]
+func (h *ReminderHandler) SaveSettings(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SaveReminderSettingsRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	settings, err := h.service.SaveSettings(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to save reminder settings",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, settings)
+}
+
+// DeleteSettings удаляет настройки напоминаний: подписка получает напоминания по умолчанию
+// @Summary Удалить настройки напоминаний подписки
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/reminder-settings [delete]
+func (h *ReminderHandler) DeleteSettings(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSettings(c.Request.Context(), id); err != nil {
+		respondError(c, h.logger, err, "Failed to delete reminder settings",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "reminder settings deleted successfully"})
+}
+
+func (h *ReminderHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format",
+			"subscription_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
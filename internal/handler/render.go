@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Списки меньше этого размера сериализуются обычным respond
+	streamArrayThreshold = 512
+	// Количество элементов, сериализуемых одной горутиной за раз
+	streamChunkSize = 256
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 64*1024))
+	},
+}
+
+type encodedChunk struct {
+	buf *bytes.Buffer
+	err error
+}
+
+// writeJSONArray пишет массив в ответ. Большие массивы сериализуются
+// параллельно чанками в переиспользуемые буферы и отправляются клиенту
+// по мере готовности с сохранением порядка элементов. Клиентам, запросившим
+// MessagePack, список отдается целиком через respond. Пустой список, в том
+// числе nil, отдается как [].
+func writeJSONArray[T any](c *gin.Context, status int, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+	if len(items) < streamArrayThreshold || wantsMsgpack(c) {
+		respond(c, status, items)
+		return
+	}
+
+	chunks := (len(items) + streamChunkSize - 1) / streamChunkSize
+	results := make([]chan encodedChunk, chunks)
+	for i := range results {
+		results[i] = make(chan encodedChunk, 1)
+	}
+
+	// Ограничиваем число одновременно сериализуемых чанков количеством CPU
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; i < chunks; i++ {
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			start := i * streamChunkSize
+			end := min(start+streamChunkSize, len(items))
+
+			go func(out chan<- encodedChunk, part []T) {
+				defer func() { <-sem }()
+				out <- encodeChunk(part)
+			}(results[i], items[start:end])
+		}
+	}()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(status)
+
+	w := c.Writer
+	_, _ = w.WriteString("[")
+	for i, ch := range results {
+		chunk := <-ch
+		if chunk.err != nil {
+			// Заголовки уже отправлены, поэтому просто обрываем ответ
+			_ = c.Error(chunk.err)
+			return
+		}
+		if i > 0 {
+			_, _ = w.WriteString(",")
+		}
+		_, err := w.Write(chunk.buf.Bytes())
+		chunk.buf.Reset()
+		bufferPool.Put(chunk.buf)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		w.Flush()
+	}
+	_, _ = w.WriteString("]")
+}
+
+func encodeChunk[T any](items []T) encodedChunk {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	enc := json.NewEncoder(buf)
+
+	for i := range items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(items[i]); err != nil {
+			buf.Reset()
+			bufferPool.Put(buf)
+			return encodedChunk{err: err}
+		}
+		// Encoder добавляет перевод строки после каждого значения
+		buf.Truncate(buf.Len() - 1)
+	}
+
+	return encodedChunk{buf: buf}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renderArray вызывает writeJSONArray и возвращает записанный ответ и контекст с ошибками
+func renderArray[T any](t *testing.T, items []T) (*httptest.ResponseRecorder, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil)
+	writeJSONArray(c, http.StatusOK, items)
+	return rec, c
+}
+
+func TestWriteJSONArrayOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{"below threshold", streamArrayThreshold - 1},
+		{"threshold", streamArrayThreshold},
+		{"whole chunks", streamChunkSize * 4},
+		{"partial last chunk", streamChunkSize*5 + 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]int, tt.n)
+			for i := range items {
+				items[i] = i
+			}
+
+			rec, c := renderArray(t, items)
+			if len(c.Errors) > 0 {
+				t.Fatalf("errors = %v", c.Errors)
+			}
+			var got []int
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if !slices.Equal(got, items) {
+				t.Errorf("got %d items out of order, want 0..%d", len(got), tt.n-1)
+			}
+		})
+	}
+}
+
+func TestWriteJSONArrayEmpty(t *testing.T) {
+	for name, items := range map[string][]int{"empty": {}, "nil": nil} {
+		t.Run(name, func(t *testing.T) {
+			rec, _ := renderArray(t, items)
+			if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+				t.Errorf("response = %d %q, want 200 []", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// failingItem не сериализуется, если совпадает с failAt
+type failingItem int
+
+const failAt = streamChunkSize*2 + 5
+
+var errEncode = errors.New("encode failed")
+
+func (i failingItem) MarshalJSON() ([]byte, error) {
+	if i == failAt {
+		return nil, errEncode
+	}
+	return []byte(strconv.Itoa(int(i))), nil
+}
+
+// TestWriteJSONArrayEncodeError проверяет, что при ошибке сериализации чанка после
+// отправки заголовков ответ обрывается на предыдущем чанке: клиент получает
+// незакрытый массив, а не корректный JSON без части элементов
+func TestWriteJSONArrayEncodeError(t *testing.T) {
+	items := make([]failingItem, streamChunkSize*4)
+	for i := range items {
+		items[i] = failingItem(i)
+	}
+
+	rec, c := renderArray(t, items)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 sent before the failure", rec.Code)
+	}
+	if len(c.Errors) != 1 || !errors.Is(c.Errors[0].Err, errEncode) {
+		t.Errorf("errors = %v, want the encoding error", c.Errors)
+	}
+
+	body := rec.Body.String()
+	if json.Valid(rec.Body.Bytes()) {
+		t.Fatalf("body is valid JSON, want truncated array")
+	}
+	// Записаны только чанки до сломанного, целиком и по порядку
+	written := make([]string, 0, streamChunkSize*2)
+	for i := range streamChunkSize * 2 {
+		written = append(written, strconv.Itoa(i))
+	}
+	if want := "[" + strings.Join(written, ","); body != want {
+		t.Errorf("body = %.40q... (%d bytes), want first %d items (%d bytes)", body, len(body), len(written), len(want))
+	}
+}
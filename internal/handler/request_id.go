@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestID берет идентификатор запроса из заголовка X-Request-ID или создает новый,
+// возвращает его в ответе и сохраняет в контексте для логов и журнала аудита.
+// Некорректный идентификатор от клиента заменяется новым
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(ctxutil.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(handler.RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, ctxutil.RequestID(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"kept", "gateway-42", true},
+		{"invalid replaced", "bad id", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestid.Header)
+			if got == "" || got != rec.Body.String() {
+				t.Fatalf("header %q, context %q: want equal non-empty ids", got, rec.Body.String())
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("request id = %q, incoming %q, keep %v", got, tt.incoming, tt.keep)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Требования аутентификации маршрутов в model.RouteInfo
+const (
+	RouteAuthNone       = "none"
+	RouteAuthBearer     = "bearer"
+	RouteAuthAdminToken = "admin_token"
+)
+
+// RouteGroup описывает middleware и требование аутентификации маршрутов с префиксом Prefix.
+// gin не раскрывает цепочки обработчиков зарегистрированных маршрутов, поэтому группы
+// передаются тем же кодом, который их создает
+type RouteGroup struct {
+	Prefix      string
+	Middlewares []gin.HandlerFunc
+	// Auth - требование аутентификации; пустое значение наследует требование внешней группы
+	Auth string
+}
+
+// DescribeRoutes собирает описание маршрутов: middleware всех групп с подходящим префиксом
+// в порядке groups и требование аутентификации самой вложенной из них
+func DescribeRoutes(routes gin.RoutesInfo, groups []RouteGroup) []model.RouteInfo {
+	described := make([]model.RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		info := model.RouteInfo{
+			Method:      route.Method,
+			Path:        route.Path,
+			Handler:     shortFuncName(route.Handler),
+			Middlewares: []string{},
+			Auth:        RouteAuthNone,
+		}
+		authPrefix := -1
+		for _, group := range groups {
+			if !hasPathPrefix(route.Path, group.Prefix) {
+				continue
+			}
+			for _, middleware := range group.Middlewares {
+				info.Middlewares = append(info.Middlewares, middlewareName(middleware))
+			}
+			if group.Auth != "" && len(group.Prefix) > authPrefix {
+				info.Auth = group.Auth
+				authPrefix = len(group.Prefix)
+			}
+		}
+		described = append(described, info)
+	}
+
+	sort.Slice(described, func(i, j int) bool {
+		if described[i].Path != described[j].Path {
+			return described[i].Path < described[j].Path
+		}
+		return described[i].Method < described[j].Method
+	})
+	return described
+}
+
+// hasPathPrefix проверяет, что path совпадает с prefix или продолжает его сегментом пути
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// closureSuffix - суффикс имени анонимной функции: ".func1", ".func2.1"
+var closureSuffix = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+
+// middlewareName возвращает имя функции, создавшей middleware: "handler.Authenticate"
+// вместо "github.com/.../internal/handler.Authenticate.func1"
+func middlewareName(middleware gin.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer()).Name()
+	return shortFuncName(closureSuffix.ReplaceAllString(name, ""))
+}
+
+// shortFuncName убирает путь модуля из полного имени функции
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
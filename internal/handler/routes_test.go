@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/health", func(c *gin.Context) {})
+	api := router.Group("/api/v1", handler.ContentNegotiation(false))
+	api.GET("/subscriptions", func(c *gin.Context) {})
+	admin := handler.NewAdminHandler(nil, nil, nil, nil, nil, nil, testAdminToken, log)
+	admin.RegisterRoutes(api)
+
+	admin.SetRoutes(router.Routes(), []handler.RouteGroup{
+		{Prefix: "/", Middlewares: []gin.HandlerFunc{gin.Recovery()}},
+		{Prefix: "/api/v1", Middlewares: []gin.HandlerFunc{handler.ContentNegotiation(false)}, Auth: handler.RouteAuthBearer},
+	})
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/routes", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get(""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", rec.Code)
+	}
+
+	rec := get(testAdminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var routes []model.RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	byRoute := make(map[string]model.RouteInfo)
+	for _, route := range routes {
+		byRoute[route.Method+" "+route.Path] = route
+	}
+
+	tests := []struct {
+		route           string
+		wantMiddlewares []string
+		wantAuth        string
+	}{
+		{"GET /health", []string{"gin.CustomRecoveryWithWriter"}, handler.RouteAuthNone},
+		{"GET /api/v1/subscriptions", []string{"gin.CustomRecoveryWithWriter", "handler.ContentNegotiation"}, handler.RouteAuthBearer},
+		{"GET /api/v1/admin/routes", []string{"gin.CustomRecoveryWithWriter", "handler.ContentNegotiation", "handler.RequireAdminToken"}, handler.RouteAuthAdminToken},
+	}
+	for _, tt := range tests {
+		route, ok := byRoute[tt.route]
+		if !ok {
+			t.Errorf("route %s is not listed", tt.route)
+			continue
+		}
+		if !reflect.DeepEqual(route.Middlewares, tt.wantMiddlewares) || route.Auth != tt.wantAuth {
+			t.Errorf("%s: middlewares = %v, auth = %q, want %v, %q", tt.route, route.Middlewares, route.Auth, tt.wantMiddlewares, tt.wantAuth)
+		}
+	}
+	if got := byRoute["GET /api/v1/admin/routes"].Handler; got != "handler.(*AdminHandler).ListRoutes-fm" {
+		t.Errorf("handler = %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/timing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timingWriter добавляет заголовок Server-Timing перед отправкой заголовков ответа
+type timingWriter struct {
+	gin.ResponseWriter
+	once   sync.Once
+	header func()
+}
+
+func (w *timingWriter) setHeader() {
+	w.once.Do(w.header)
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
+
+// ServerTiming добавляет в ответ заголовок Server-Timing со временем обработчика, сервиса
+// подписок и запросов к базе. Для потоковых ответов время считается до первой записи.
+// Запрос дольше непустого budget пишется в лог
+func ServerTiming(budget time.Duration, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recorder := timing.NewRecorder()
+		writer := &timingWriter{ResponseWriter: c.Writer}
+		writer.header = func() {
+			if !writer.Written() {
+				writer.Header().Set("Server-Timing", recorder.Header(budget))
+			}
+		}
+		c.Writer = writer
+		c.Request = c.Request.WithContext(timing.WithRecorder(c.Request.Context(), recorder))
+		c.Next()
+		// Ответ без тела (204, 304) не вызывает Write
+		writer.setHeader()
+		c.Writer = writer.ResponseWriter
+
+		if elapsed := recorder.Elapsed(); budget > 0 && elapsed > budget {
+			route := c.FullPath()
+			if route == "" {
+				route = c.Request.URL.Path
+			}
+			log.Warn(c.Request.Context(), "Response time budget exceeded",
+				"method", c.Request.Method,
+				"route", route,
+				"duration", elapsed.String(),
+				"budget", budget.String(),
+			)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package handler_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/timing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServerTiming(t *testing.T) {
+	router := gin.New()
+	router.Use(handler.ServerTiming(time.Second, logger.New(slog.LevelError+4)))
+	router.GET("/json", func(c *gin.Context) {
+		done := timing.FromContext(c.Request.Context()).Service()
+		timing.FromContext(c.Request.Context()).Query(time.Millisecond)
+		done()
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.DELETE("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tt := range []struct {
+		method, path string
+		wantDB       string
+	}{
+		{http.MethodGet, "/json", `db;dur=1.0;desc="queries: 1"`},
+		{http.MethodDelete, "/empty", `db;dur=0.0;desc="queries: 0"`},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		header := rec.Header().Get("Server-Timing")
+		if !strings.Contains(header, tt.wantDB) || !strings.Contains(header, "budget;dur=1000.0") {
+			t.Errorf("%s %s: Server-Timing = %q, want %s and budget", tt.method, tt.path, header, tt.wantDB)
+		}
+	}
+}
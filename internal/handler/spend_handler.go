@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSparklineMonths = 12
+	maxSparklineMonths     = 36
+)
+
+type SpendHandler struct {
+	service service.SparklineService
+	logger  *logger.Logger
+}
+
+func NewSpendHandler(service service.SparklineService, logger *logger.Logger) *SpendHandler {
+	return &SpendHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты трат пользователя в группе API
+func (h *SpendHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/users/:id/spend/sparkline", h.GetSparkline)
+}
+
+// GetSparkline возвращает помесячные траты для мини-графика
+// @Summary Мини-график трат пользователя
+// @Description Возвращает компактный массив помесячных трат за последние месяцы (включая текущий) для отрисовки sparkline. Ответ кэшируется
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param months query int false "Количество месяцев (по умолчанию 12)"
+// @Success 200 {object} model.Sparkline
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/spend/sparkline [get]
+func (h *SpendHandler) GetSparkline(c *gin.Context) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return
+	}
+
+	months, err := strconv.Atoi(c.DefaultQuery("months", strconv.Itoa(defaultSparklineMonths)))
+	if err != nil || months < 1 || months > maxSparklineMonths {
+		h.logger.Warn(c.Request.Context(), "Invalid months parameter",
+			"months", c.Query("months"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("months must be between 1 and %d", maxSparklineMonths))
+		return
+	}
+
+	sparkline, err := h.service.Sparkline(c.Request.Context(), userID, months)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to build spend sparkline",
+			"user_id", userID,
+		)
+		return
+	}
+
+	if ttl := h.service.CacheTTL(); ttl > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+	}
+	respond(c, http.StatusOK, sparkline)
+}
@@ -1,26 +1,87 @@
 package handler
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Zipklas/subscription-service/internal/logger"
 	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
 	"github.com/Zipklas/subscription-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+
+	defaultListLimit = 100
+	maxListLimit     = 1000
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQueryLen  = 100
+)
+
 type SubscriptionHandler struct {
 	service service.SubscriptionService
-	logger  *logger.Logger
+	// adminToken защищает операции, которые нельзя доверить владельцу подписки (передача)
+	adminToken string
+	logger     *logger.Logger
 }
 
-func NewSubscriptionHandler(service service.SubscriptionService, logger *logger.Logger) *SubscriptionHandler {
+func NewSubscriptionHandler(service service.SubscriptionService, adminToken string, logger *logger.Logger) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		service: service,
-		logger:  logger,
+		service:    service,
+		adminToken: adminToken,
+		logger:     logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты подписок в группе API
+func (h *SubscriptionHandler) RegisterRoutes(api gin.IRouter) {
+	// Subscription CRUDL routes
+	subscriptions := api.Group("/subscriptions")
+	{
+		subscriptions.POST("", h.CreateSubscription)
+		subscriptions.POST("/validate", h.ValidateSubscription)
+		subscriptions.GET("", h.ListSubscriptions)
+		subscriptions.GET("/export", h.ExportSubscriptions)
+		subscriptions.GET("/stream", h.StreamSubscriptions)
+		subscriptions.POST("/import", h.ImportSubscriptions)
+		subscriptions.GET("/search", h.SearchSubscriptions)
+		subscriptions.POST("/batch", h.BulkCreateSubscriptions)
+		subscriptions.PUT("/batch", h.BulkUpdateSubscriptions)
+		subscriptions.DELETE("/batch", h.BulkDeleteSubscriptions)
+		subscriptions.GET("/:id", h.GetSubscription)
+		subscriptions.GET("/:id/prices", h.ListSubscriptionPrices)
+		subscriptions.PUT("/:id", h.UpdateSubscription)
+		subscriptions.DELETE("/:id", h.DeleteSubscription)
+		subscriptions.POST("/:id/activate", h.ActivateSubscription)
+		subscriptions.POST("/:id/cancel", h.CancelSubscription)
+		subscriptions.POST("/:id/transfer", RequireAdminToken(h.adminToken), h.TransferSubscription)
+
+		// Summary route
+		subscriptions.GET("/summary", h.CalculateTotalCost)
+		subscriptions.POST("/summary/verify", h.VerifySummary)
+
+		// Incremental sync
+		subscriptions.GET("/changes", h.ListChanges)
 	}
+
+	api.GET("/users/:id/services", h.ListUserServices)
+	// gin не разбирает двоеточие внутри сегмента пути: ":sync" приходит в параметре method
+	api.PUT("/users/:id/subscriptions:method", h.SyncUserSubscriptions)
+
+	acceptMultipart(subscriptions.BasePath() + "/import")
 }
 
 // CreateSubscription создает новую подписку
@@ -36,11 +97,8 @@ func NewSubscriptionHandler(service service.SubscriptionService, logger *logger.
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	var req model.CreateSubscriptionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn(c.Request.Context(), "Invalid request body for subscription creation",
-			"error", err,
-		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body for subscription creation")
 		return
 	}
 
@@ -51,12 +109,10 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 
 	subscription, err := h.service.CreateSubscription(c.Request.Context(), req)
 	if err != nil {
-		h.logger.Error(c.Request.Context(), "Failed to create subscription",
+		respondError(c, h.logger, err, "Failed to create subscription",
 			"service_name", req.ServiceName,
 			"user_id", req.UserID,
-			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -65,7 +121,37 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 		"service_name", req.ServiceName,
 	)
 
-	c.JSON(http.StatusCreated, subscription)
+	respond(c, http.StatusCreated, subscription)
+}
+
+// ValidateSubscription проверяет запрос на создание подписки без сохранения
+// @Summary Проверить подписку
+// @Description Выполняет все проверки POST /subscriptions, ничего не сохраняя. Некорректное тело (нет обязательных полей, неверные типы) возвращает 400; ошибки дат и предоплаты возвращаются с 200 и valid=false. Для корректного запроса возвращается нормализованное тело (end_date и monthly_cost рассчитаны по предоплате) и предупреждения: пересечение с другой подпиской пользователя на тот же сервис (overlap) и окончание раньше текущего месяца (expired)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSubscriptionRequest true "Данные для создания подписки"
+// @Success 200 {object} model.ValidationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/validate [post]
+func (h *SubscriptionHandler) ValidateSubscription(c *gin.Context) {
+	var req model.CreateSubscriptionRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body for subscription validation")
+		return
+	}
+
+	result, err := h.service.ValidateSubscription(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to validate subscription",
+			"service_name", req.ServiceName,
+			"user_id", req.UserID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
 }
 
 // GetSubscription получает подписку по ID
@@ -81,13 +167,13 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := parseUUID(c, c.Param("id"))
 	if err != nil {
 		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format",
 			"subscription_id", c.Param("id"),
 			"error", err,
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid subscription ID"})
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
 		return
 	}
 
@@ -97,18 +183,9 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 
 	subscription, err := h.service.GetSubscription(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "subscription not found" {
-			h.logger.Warn(c.Request.Context(), "Subscription not found",
-				"subscription_id", id,
-			)
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
-			return
-		}
-		h.logger.Error(c.Request.Context(), "Failed to get subscription",
+		respondError(c, h.logger, err, "Failed to get subscription",
 			"subscription_id", id,
-			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -116,12 +193,45 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 		"subscription_id", id,
 	)
 
-	c.JSON(http.StatusOK, subscription)
+	respond(c, http.StatusOK, subscription)
+}
+
+// ListSubscriptionPrices возвращает историю стоимости подписки
+// @Summary История стоимости подписки
+// @Description Возвращает стоимость подписки по месяцам ее срока: с какого (from) по какой (to) месяц действовала каждая стоимость. Последняя запись действует до конца подписки, у бессрочной подписки to не задан. Стоимость, не менявшаяся с создания, возвращается одной записью
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} model.SubscriptionPrice
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/prices [get]
+func (h *SubscriptionHandler) ListSubscriptionPrices(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format",
+			"subscription_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+		return
+	}
+
+	prices, err := h.service.ListSubscriptionPrices(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list subscription prices",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, prices)
 }
 
 // UpdateSubscription обновляет подписку
 // @Summary Обновить подписку
-// @Description Обновляет информацию о подписке
+// @Description Обновляет информацию о подписке. Состояние меняется по переходам active -> paused/cancelled и paused -> active/cancelled; при отмене подписка заканчивается текущим месяцем
 // @Tags subscriptions
 // @Accept json
 // @Produce json
@@ -130,26 +240,25 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := parseUUID(c, c.Param("id"))
 	if err != nil {
 		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format for update",
 			"subscription_id", c.Param("id"),
 			"error", err,
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid subscription ID"})
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
 		return
 	}
 
 	var req model.UpdateSubscriptionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn(c.Request.Context(), "Invalid request body for subscription update",
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body for subscription update",
 			"subscription_id", id,
-			"error", err,
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -160,18 +269,9 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	)
 
 	if err := h.service.UpdateSubscription(c.Request.Context(), id, req); err != nil {
-		if err.Error() == "subscription not found" {
-			h.logger.Warn(c.Request.Context(), "Subscription not found for update",
-				"subscription_id", id,
-			)
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
-			return
-		}
-		h.logger.Error(c.Request.Context(), "Failed to update subscription",
+		respondError(c, h.logger, err, "Failed to update subscription",
 			"subscription_id", id,
-			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -179,7 +279,7 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 		"subscription_id", id,
 	)
 
-	c.JSON(http.StatusOK, SuccessResponse{Message: "subscription updated successfully"})
+	respond(c, http.StatusOK, SuccessResponse{Message: "subscription updated successfully"})
 }
 
 // DeleteSubscription удаляет подписку
@@ -195,13 +295,13 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := parseUUID(c, c.Param("id"))
 	if err != nil {
 		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format for deletion",
 			"subscription_id", c.Param("id"),
 			"error", err,
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid subscription ID"})
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
 		return
 	}
 
@@ -210,75 +310,733 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 	)
 
 	if err := h.service.DeleteSubscription(c.Request.Context(), id); err != nil {
-		if err.Error() == "subscription not found" {
-			h.logger.Warn(c.Request.Context(), "Subscription not found for deletion",
+		respondError(c, h.logger, err, "Failed to delete subscription",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Subscription deleted successfully",
+		"subscription_id", id,
+	)
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "subscription deleted successfully"})
+}
+
+// ActivateSubscription активирует черновик подписки
+// @Summary Активировать подписку
+// @Description Переводит черновик подписки в активное состояние, после чего она учитывается в подсчете стоимости
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} model.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/activate [post]
+func (h *SubscriptionHandler) ActivateSubscription(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format for activation",
+			"subscription_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Activating subscription",
+		"subscription_id", id,
+	)
+
+	subscription, err := h.service.ActivateSubscription(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to activate subscription",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Subscription activated successfully",
+		"subscription_id", id,
+	)
+
+	respond(c, http.StatusOK, subscription)
+}
+
+// CancelSubscription отменяет подписку
+// @Summary Отменить подписку
+// @Description Отменяет подписку с сохранением причины. По умолчанию подписка заканчивается текущим месяцем или месяцем effective_period; с at_period_end - в конце оплаченного срока (end_date подписки, а если его нет - текущим месяцем)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body model.CancelSubscriptionRequest false "Параметры отмены"
+// @Success 200 {object} model.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format for cancellation",
+			"subscription_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
+		return
+	}
+
+	// Тело необязательно: без него подписка отменяется с текущего месяца
+	var req model.CancelSubscriptionRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindBody(c, &req); err != nil {
+			respondError(c, h.logger, err, "Invalid request body for subscription cancellation",
 				"subscription_id", id,
 			)
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
 			return
 		}
-		h.logger.Error(c.Request.Context(), "Failed to delete subscription",
+	}
+
+	subscription, err := h.service.CancelSubscription(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to cancel subscription",
 			"subscription_id", id,
+		)
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Subscription cancelled successfully",
+		"subscription_id", id,
+	)
+
+	respond(c, http.StatusOK, subscription)
+}
+
+// TransferSubscription передает подписку другому пользователю
+// @Summary Передать подписку
+// @Description Меняет владельца подписки, например при переходе между членами семьи или командами. Сервис не аутентифицирует пользователей, поэтому согласие обеих сторон не проверяется и передача доступна только с административным токеном. Передача записывается в историю (subscription_transfers) и в журнал изменений операцией transfer. Отмененные и истекшие подписки не передаются
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Param request body model.TransferSubscriptionRequest true "Новый владелец"
+// @Success 200 {object} model.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/transfer [post]
+func (h *SubscriptionHandler) TransferSubscription(c *gin.Context) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid subscription ID format for transfer",
+			"subscription_id", c.Param("id"),
 			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid subscription ID")
 		return
 	}
 
-	h.logger.Info(c.Request.Context(), "Subscription deleted successfully",
+	var req model.TransferSubscriptionRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body for subscription transfer",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	subscription, err := h.service.TransferSubscription(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to transfer subscription",
+			"subscription_id", id,
+		)
+		return
+	}
+
+	h.logger.Info(c.Request.Context(), "Subscription transferred successfully",
 		"subscription_id", id,
+		"to_user_id", req.UserID,
 	)
 
-	c.JSON(http.StatusOK, SuccessResponse{Message: "subscription deleted successfully"})
+	respond(c, http.StatusOK, subscription)
 }
 
 // ListSubscriptions возвращает список подписок
 // @Summary Список подписок
-// @Description Возвращает список подписок с возможностью фильтрации по пользователю и сервису
+// @Description Возвращает список подписок с возможностью фильтрации по пользователю, сервису и состоянию
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param user_id query string false "ID пользователя для фильтрации"
 // @Param service_name query string false "Название сервиса для фильтрации"
+// @Param service_id query string false "ID сервиса из каталога для фильтрации"
+// @Param tag query string false "Тег подписки для фильтрации (без учета регистра)"
+// @Param status query string false "Состояние подписки" Enums(active, paused, cancelled, expired)
+// @Param exclude_service_name query []string false "Исключить подписки сервиса; параметр повторяется" collectionFormat(multi)
+// @Param exclude_user_id query []string false "Исключить подписки пользователя; параметр повторяется" collectionFormat(multi)
+// @Param limit query int false "Размер страницы (по умолчанию 100, максимум 1000)"
+// @Param offset query int false "Смещение от начала списка"
+// @Param cursor query string false "Курсор из X-Next-Cursor; пустое значение включает курсорный режим с начала списка. Несовместим с offset"
+// @Param count query string false "Подсчет X-Total-Count: точный или оценка планировщика для больших выборок (по умолчанию exact)" Enums(exact, estimate)
 // @Success 200 {array} model.Subscription
+// @Header 200 {integer} X-Total-Count "Общее количество подписок под фильтром (только без cursor)"
+// @Header 200 {boolean} X-Total-Count-Estimated "X-Total-Count может быть оценкой (только с count=estimate)"
+// @Header 200 {string} X-Next-Cursor "Курсор следующей страницы (только с cursor)"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
-	var userID *uuid.UUID
-	var serviceName *string
+	filter, ok := h.subscriptionFilter(c)
+	if !ok {
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.listSubscriptionsAfter(c, filter, cursor)
+		return
+	}
+
+	page, err := parsePagination(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid pagination parameters",
+			"limit", c.Query("limit"),
+			"offset", c.Query("offset"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if page.Count, err = parseCountMode(c); err != nil {
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	h.logger.Debug(c.Request.Context(), "Listing subscriptions",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"status", filter.Status,
+		"limit", page.Limit,
+		"offset", page.Offset,
+	)
+
+	result, err := h.service.ListSubscriptions(c.Request.Context(), filter, page)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list subscriptions",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+		)
+		return
+	}
+
+	h.logger.Debug(c.Request.Context(), "Subscriptions listed successfully",
+		"count", len(result.Items),
+		"total", result.Total,
+		"user_id", filter.UserID,
+	)
+
+	setPaginationHeaders(c, page, result.Total)
+	if result.Estimated {
+		c.Header(TotalCountEstimatedHeader, "true")
+	}
+	writeJSONArray(c, http.StatusOK, result.Items)
+}
+
+// subscriptionFilter разбирает фильтры списка подписок из параметров запроса. Если
+// значения некорректны в строгом режиме, отвечает 400 и возвращает false
+func (h *SubscriptionHandler) subscriptionFilter(c *gin.Context) (model.SubscriptionFilter, bool) {
+	var filter model.SubscriptionFilter
+	var invalid []FieldError
 
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		if id, err := uuid.Parse(userIDStr); err == nil {
-			userID = &id
+		if id, err := parseUUID(c, userIDStr); err == nil {
+			filter.UserID = &id
+		} else {
+			invalid = append(invalid, FieldError{Field: "user_id", Value: userIDStr, Reason: err.Error()})
 		}
 	}
 
 	if serviceNameStr := c.Query("service_name"); serviceNameStr != "" {
-		serviceName = &serviceNameStr
+		filter.ServiceName = &serviceNameStr
 	}
 
-	h.logger.Debug(c.Request.Context(), "Listing subscriptions",
-		"user_id", userID,
-		"service_name", serviceName,
+	if serviceIDStr := c.Query("service_id"); serviceIDStr != "" {
+		if id, err := parseUUID(c, serviceIDStr); err == nil {
+			filter.ServiceID = &id
+		} else {
+			invalid = append(invalid, FieldError{Field: "service_id", Value: serviceIDStr, Reason: err.Error()})
+		}
+	}
+
+	// Теги хранятся в нижнем регистре
+	if tag := strings.ToLower(strings.TrimSpace(c.Query("tag"))); tag != "" {
+		filter.Tag = &tag
+	}
+
+	if status := c.Query("status"); status != "" {
+		if model.IsValidStatus(status) {
+			filter.Status = &status
+		} else {
+			invalid = append(invalid, FieldError{Field: "status", Value: status, Reason: "must be one of active, paused, cancelled, expired"})
+		}
+	}
+
+	var exclusionErrors []FieldError
+	filter.ExcludeServiceNames, filter.ExcludeUserIDs, exclusionErrors = parseExclusions(c)
+	invalid = append(invalid, exclusionErrors...)
+
+	if len(invalid) > 0 {
+		// Пропущенный фильтр расширяет выборку, например до подписок всех пользователей,
+		// поэтому нестрогий режим оставлен только на время перехода клиентов
+		if strictFilters(c) {
+			h.logger.Warn(c.Request.Context(), "Invalid subscription list filters",
+				"fields", invalid,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidFilter, "invalid filter values", invalid...)
+			return filter, false
+		}
+		h.logger.Warn(c.Request.Context(), "Ignoring invalid subscription list filters",
+			"fields", invalid,
+		)
+	}
+
+	return filter, true
+}
+
+// maxExclusions ограничивает число значений каждого параметра exclude_*
+const maxExclusions = 100
+
+// parseExclusions читает повторяемые параметры exclude_service_name и exclude_user_id
+func parseExclusions(c *gin.Context) ([]string, []uuid.UUID, []FieldError) {
+	var invalid []FieldError
+
+	var serviceNames []string
+	for _, name := range c.QueryArray("exclude_service_name") {
+		if name != "" {
+			serviceNames = append(serviceNames, name)
+		}
+	}
+	if len(serviceNames) > maxExclusions {
+		invalid = append(invalid, FieldError{Field: "exclude_service_name", Value: strconv.Itoa(len(serviceNames)), Reason: fmt.Sprintf("at most %d values are allowed", maxExclusions)})
+		serviceNames = nil
+	}
+
+	var userIDs []uuid.UUID
+	rawIDs := c.QueryArray("exclude_user_id")
+	if len(rawIDs) > maxExclusions {
+		return serviceNames, nil, append(invalid, FieldError{Field: "exclude_user_id", Value: strconv.Itoa(len(rawIDs)), Reason: fmt.Sprintf("at most %d values are allowed", maxExclusions)})
+	}
+	for _, raw := range rawIDs {
+		if raw == "" {
+			continue
+		}
+		id, err := parseUUID(c, raw)
+		if err != nil {
+			invalid = append(invalid, FieldError{Field: "exclude_user_id", Value: raw, Reason: err.Error()})
+			continue
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return serviceNames, userIDs, invalid
+}
+
+// listSubscriptionsAfter отдает страницу в порядке (created_at, id) после курсора.
+// В отличие от OFFSET, сравнение по ключу не деградирует на больших таблицах и не
+// пропускает и не повторяет подписки при вставках между запросами
+func (h *SubscriptionHandler) listSubscriptionsAfter(c *gin.Context, filter model.SubscriptionFilter, cursor string) {
+	if _, ok := c.GetQuery("offset"); ok {
+		h.logger.Warn(c.Request.Context(), "Both cursor and offset are set")
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "cursor and offset cannot be used together")
+		return
+	}
+
+	limit, err := parseLimit(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid pagination parameters",
+			"limit", c.Query("limit"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid cursor",
+			"cursor", cursor,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	result, err := h.service.ListSubscriptionsAfter(c.Request.Context(), filter, after, limit)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list subscriptions after cursor",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+		)
+		return
+	}
+
+	h.logger.Debug(c.Request.Context(), "Subscriptions page listed successfully",
+		"count", len(result.Items),
+		"has_next", result.Next != nil,
 	)
 
-	subscriptions, err := h.service.ListSubscriptions(c.Request.Context(), userID, serviceName)
+	setCursorHeaders(c, result.Next)
+	writeJSONArray(c, http.StatusOK, result.Items)
+}
+
+// SearchSubscriptions ищет подписки по названию сервиса
+// @Summary Поиск подписок по названию сервиса
+// @Description Ищет без учета регистра и диакритики (unaccent + ILIKE) с учетом опечаток (pg_trgm). Сначала идут точные совпадения, затем совпадения с начала названия, по подстроке и похожие названия
+// @Tags subscriptions
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param user_id query string false "ID пользователя"
+// @Param limit query int false "Максимальное количество результатов (по умолчанию 20, максимум 100)"
+// @Success 200 {array} model.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/search [get]
+func (h *SubscriptionHandler) SearchSubscriptions(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || len([]rune(query)) > maxSearchQueryLen {
+		h.logger.Warn(c.Request.Context(), "Invalid search query",
+			"q", c.Query("q"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("q must be between 1 and %d characters", maxSearchQueryLen))
+		return
+	}
+
+	var userID *uuid.UUID
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		id, err := parseUUID(c, userIDStr)
+		if err != nil {
+			h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+				"user_id", userIDStr,
+				"error", err,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+			return
+		}
+		userID = &id
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		h.logger.Warn(c.Request.Context(), "Invalid limit parameter",
+			"limit", c.Query("limit"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+		return
+	}
+
+	subscriptions, err := h.service.SearchSubscriptions(c.Request.Context(), query, userID, limit)
 	if err != nil {
-		h.logger.Error(c.Request.Context(), "Failed to list subscriptions",
-			"user_id", userID,
-			"service_name", serviceName,
+		respondError(c, h.logger, err, "Failed to search subscriptions",
+			"q", query,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, subscriptions)
+}
+
+// ExportSubscriptions выгружает все подписки в CSV
+// @Summary Выгрузка подписок
+// @Description Потоково выгружает все подписки в CSV в порядке (created_at, id). Подписки, существовавшие на момент начала выгрузки, попадают в нее ровно один раз даже при одновременных вставках
+// @Tags subscriptions
+// @Produce text/csv
+// @Success 200 {string} string "CSV с заголовком"
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/export [get]
+func (h *SubscriptionHandler) ExportSubscriptions(c *gin.Context) {
+	w := csv.NewWriter(c.Writer)
+	started := false
+
+	// Заголовки отправляем только после успешного чтения первой страницы,
+	// чтобы ошибку базы можно было вернуть обычным ответом
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="subscriptions.csv"`)
+		c.Status(http.StatusOK)
+		return w.Write(exportHeader)
+	}
+
+	err := h.service.ExportSubscriptions(c.Request.Context(), func(sub *model.Subscription) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return w.Write(exportRecord(sub))
+	})
+	if err == nil {
+		err = start()
+	}
+	if err != nil {
+		if !started {
+			respondError(c, h.logger, err, "Failed to export subscriptions")
+			return
+		}
+		// Часть CSV уже отправлена, обрываем ответ без завершающих данных
+		h.logger.Error(c.Request.Context(), "Failed to export subscriptions",
 			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	h.logger.Debug(c.Request.Context(), "Subscriptions listed successfully",
-		"count", len(subscriptions),
-		"user_id", userID,
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.Error(c.Request.Context(), "Failed to write subscriptions export",
+			"error", err,
+		)
+	}
+}
+
+// ImportSubscriptions создает подписки из загруженного CSV или XLSX
+// @Summary Импорт подписок
+// @Description Создает подписки из файла CSV или XLSX (первый лист). Первая строка - названия столбцов: service_name, user_id, start_date, monthly_cost, prepaid_amount или is_free, необязательные end_date и is_draft; остальные столбцы игнорируются, поэтому принимается файл из /subscriptions/export. Периоды в XLSX должны быть текстовыми ячейками MM-YYYY. Каждая строка проверяется как тело POST /subscriptions, корректные строки создаются пачками в отдельных транзакциях, по остальным возвращаются ошибки с номерами строк файла. Не больше 10000 строк и 10 МБ
+// @Tags subscriptions
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Файл .csv или .xlsx"
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/import [post]
+func (h *SubscriptionHandler) ImportSubscriptions(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid import upload",
+			"error", err,
+		)
+		if isUploadTooLarge(err) {
+			respondProblem(c, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, fmt.Sprintf("import file is larger than %d bytes", maxImportFileSize))
+			return
+		}
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "file is required: "+err.Error())
+		return
+	}
+
+	records, err := readImportFile(file)
+	if err == nil && len(records) < 2 {
+		err = fmt.Errorf("import file has no rows")
+	}
+	var header map[string]int
+	if err == nil {
+		header, err = importHeader(records[0].cells)
+	}
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid import file",
+			"filename", file.Filename,
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	var rows []model.ImportRow
+	var parseErrors []model.ImportError
+	for _, record := range records[1:] {
+		req, err := importRequest(c, header, record.cells)
+		if err != nil {
+			parseErrors = append(parseErrors, model.ImportError{Line: record.line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, model.ImportRow{Line: record.line, Request: req})
+	}
+
+	h.logger.Info(c.Request.Context(), "Importing subscriptions",
+		"filename", file.Filename,
+		"rows", len(records)-1,
+		"invalid", len(parseErrors),
 	)
 
-	c.JSON(http.StatusOK, subscriptions)
+	result, err := h.service.ImportSubscriptions(c.Request.Context(), rows)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to import subscriptions",
+			"filename", file.Filename,
+		)
+		return
+	}
+
+	if len(parseErrors) > 0 {
+		result.Errors = append(result.Errors, parseErrors...)
+		sort.SliceStable(result.Errors, func(i, j int) bool {
+			return result.Errors[i].Line < result.Errors[j].Line
+		})
+		result.Failed = len(result.Errors)
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// streamFlushEvery - через сколько строк NDJSON ответ сбрасывается клиенту
+const streamFlushEvery = 100
+
+// StreamSubscriptions выгружает подписки в формате NDJSON по мере чтения из базы
+// @Summary Потоковая выгрузка подписок
+// @Description Пишет подписки под фильтром по одной JSON-строке в порядке (created_at, id) по мере чтения строк из базы, не собирая весь список в памяти. Фильтры - как у GET /subscriptions. Если чтение прервалось после начала ответа, последней строкой приходит объект {"error": "..."}
+// @Tags subscriptions
+// @Produce application/x-ndjson
+// @Param user_id query string false "ID пользователя для фильтрации"
+// @Param service_name query string false "Название сервиса для фильтрации"
+// @Param service_id query string false "ID сервиса из каталога для фильтрации"
+// @Param tag query string false "Тег подписки для фильтрации (без учета регистра)"
+// @Param status query string false "Состояние подписки" Enums(active, paused, cancelled, expired)
+// @Success 200 {object} model.Subscription "Одна подписка на строку"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/stream [get]
+func (h *SubscriptionHandler) StreamSubscriptions(c *gin.Context) {
+	filter, ok := h.subscriptionFilter(c)
+	if !ok {
+		return
+	}
+
+	enc := json.NewEncoder(c.Writer)
+	enc.SetEscapeHTML(false)
+	streamed := 0
+
+	// Заголовки отправляем с первой подпиской, чтобы ошибку запроса к базе
+	// можно было вернуть обычным ответом
+	err := h.service.StreamSubscriptions(c.Request.Context(), filter, func(sub *model.Subscription) error {
+		if streamed == 0 {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+		}
+		if err := enc.Encode(sub); err != nil {
+			return err
+		}
+		streamed++
+		if streamed%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if streamed == 0 {
+			respondError(c, h.logger, err, "Failed to stream subscriptions")
+			return
+		}
+		h.logger.Error(c.Request.Context(), "Failed to stream subscriptions",
+			"streamed", streamed,
+			"error", err,
+		)
+		// Статус уже отправлен: сообщаем об обрыве последней строкой
+		_ = enc.Encode(errorProblem(c, err))
+		return
+	}
+
+	if streamed == 0 {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+	}
+	c.Writer.Flush()
+}
+
+var exportHeader = []string{
+	"id", "service_name", "monthly_cost", "user_id", "start_date", "end_date",
+	"prepaid_amount", "is_draft", "created_at", "updated_at",
+}
+
+// escapeCSVFormula не дает табличным редакторам выполнить значение как формулу
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func exportRecord(sub *model.Subscription) []string {
+	endDate := ""
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.Format("01-2006")
+	}
+	prepaid := ""
+	if sub.PrepaidAmount != nil {
+		prepaid = strconv.Itoa(*sub.PrepaidAmount)
+	}
+
+	return []string{
+		sub.ID.String(),
+		escapeCSVFormula(sub.ServiceName),
+		strconv.Itoa(sub.MonthlyCost),
+		sub.UserID.String(),
+		sub.StartDate.Format("01-2006"),
+		endDate,
+		prepaid,
+		strconv.FormatBool(sub.IsDraft),
+		sub.CreatedAt.UTC().Format(time.RFC3339),
+		sub.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ListChanges возвращает журнал изменений подписок после указанного номера
+// @Summary Изменения подписок
+// @Description Возвращает операции создания, изменения и удаления подписок с полным образом строки в порядке seq, начиная после курсора since_seq, для инкрементальной синхронизации без CDC. Номера выдаются в порядке фиксации транзакций, поэтому изменение не появится позади курсора. Журнал содержит подписки всех пользователей: при включенной аутентификации доступен только администратору
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param since_seq query int false "Номер последнего полученного изменения (next_since_seq из предыдущего ответа)"
+// @Param limit query int false "Максимальное количество записей (по умолчанию 100, максимум 1000)"
+// @Success 200 {object} model.ChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/changes [get]
+func (h *SubscriptionHandler) ListChanges(c *gin.Context) {
+	// since оставлен как устаревший синоним since_seq
+	sinceParam := c.Query("since_seq")
+	if sinceParam == "" {
+		sinceParam = c.DefaultQuery("since", "0")
+	}
+
+	sinceSeq, err := strconv.ParseInt(sinceParam, 10, 64)
+	if err != nil || sinceSeq < 0 {
+		h.logger.Warn(c.Request.Context(), "Invalid since_seq parameter",
+			"since_seq", sinceParam,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "invalid since_seq parameter")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChangesLimit)))
+	if err != nil || limit < 1 || limit > maxChangesLimit {
+		h.logger.Warn(c.Request.Context(), "Invalid limit parameter",
+			"limit", c.Query("limit"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("limit must be between 1 and %d", maxChangesLimit))
+		return
+	}
+
+	changes, err := h.service.ListChanges(c.Request.Context(), sinceSeq, limit)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list subscription changes",
+			"since_seq", sinceSeq,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, changes)
 }
 
 // CalculateTotalCost подсчитывает суммарную стоимость подписок
@@ -289,10 +1047,18 @@ func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
 // @Produce json
 // @Param user_id query string false "ID пользователя для фильтрации"
 // @Param service_name query string false "Название сервиса для фильтрации"
+// @Param service_id query string false "ID сервиса из каталога для фильтрации"
+// @Param tag query string false "Тег подписки для фильтрации (без учета регистра)"
 // @Param start_period query string true "Начало периода (формат: MM-YYYY)"
 // @Param end_period query string true "Конец периода (формат: MM-YYYY)"
+// @Param amount query string false "Вид суммы: gross (с налогом) или net (без налога)" Enums(gross, net)
+// @Param exclude_service_name query []string false "Исключить подписки сервиса; параметр повторяется" collectionFormat(multi)
+// @Param exclude_user_id query []string false "Исключить подписки пользователя; параметр повторяется" collectionFormat(multi)
+// @Param signed query bool false "Добавить параметры расчета и подпись HMAC (требует SUMMARY_SIGNING_KEY)"
+// @Param group_by query string false "Суммы по значениям метки (metadata.<key>, поле groups) или по сервисам (service, поле breakdown); не сочетается с signed" example(metadata.project)
 // @Success 200 {object} model.SummaryResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/summary [get]
 func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
@@ -300,22 +1066,57 @@ func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
 
 	// Парсим user_id вручную, если передан
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		userID, err := uuid.Parse(userIDStr)
+		userID, err := parseUUID(c, userIDStr)
 		if err != nil {
 			h.logger.Warn(c.Request.Context(), "Invalid user_id format",
 				"user_id", userIDStr,
 				"error", err,
 			)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id format"})
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user_id format")
 			return
 		}
 		filter.UserID = userID
 	}
 
+	if serviceIDStr := c.Query("service_id"); serviceIDStr != "" {
+		serviceID, err := parseUUID(c, serviceIDStr)
+		if err != nil {
+			h.logger.Warn(c.Request.Context(), "Invalid service_id format",
+				"service_id", serviceIDStr,
+				"error", err,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid service_id format")
+			return
+		}
+		filter.ServiceID = &serviceID
+	}
+
 	// Парсим остальные параметры
 	filter.ServiceName = c.Query("service_name")
+	filter.Tag = strings.ToLower(strings.TrimSpace(c.Query("tag")))
 	filter.StartPeriod = c.Query("start_period")
 	filter.EndPeriod = c.Query("end_period")
+	filter.Amount = c.Query("amount")
+	filter.GroupBy = c.Query("group_by")
+	if signed := c.Query("signed"); signed != "" {
+		var err error
+		if filter.Signed, err = strconv.ParseBool(signed); err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "invalid signed: expected true or false")
+			return
+		}
+	}
+
+	// Сумма с молча пропущенным исключением выглядела бы корректной, поэтому
+	// некорректные значения отклоняются независимо от STRICT_FILTERS
+	var invalid []FieldError
+	filter.ExcludeServiceNames, filter.ExcludeUserIDs, invalid = parseExclusions(c)
+	if len(invalid) > 0 {
+		h.logger.Warn(c.Request.Context(), "Invalid summary exclusion filters",
+			"fields", invalid,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidFilter, "invalid filter values", invalid...)
+		return
+	}
 
 	// Валидация обязательных полей
 	if filter.StartPeriod == "" || filter.EndPeriod == "" {
@@ -323,10 +1124,21 @@ func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
 			"start_period", filter.StartPeriod,
 			"end_period", filter.EndPeriod,
 		)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "start_period and end_period are required"})
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "start_period and end_period are required")
 		return
 	}
 
+	if filter.Amount != "" {
+		if _, err := money.ParseAmountType(filter.Amount); err != nil {
+			h.logger.Warn(c.Request.Context(), "Invalid amount type for cost calculation",
+				"amount", filter.Amount,
+				"error", err,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+			return
+		}
+	}
+
 	h.logger.Info(c.Request.Context(), "Calculating total cost",
 		"start_period", filter.StartPeriod,
 		"end_period", filter.EndPeriod,
@@ -336,12 +1148,10 @@ func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
 
 	result, err := h.service.CalculateTotalCost(c.Request.Context(), filter)
 	if err != nil {
-		h.logger.Error(c.Request.Context(), "Failed to calculate total cost",
+		respondError(c, h.logger, err, "Failed to calculate total cost",
 			"start_period", filter.StartPeriod,
 			"end_period", filter.EndPeriod,
-			"error", err,
 		)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -351,14 +1161,69 @@ func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
 		"end_period", filter.EndPeriod,
 	)
 
-	c.JSON(http.StatusOK, result)
+	respond(c, http.StatusOK, result)
+}
+
+// VerifySummary проверяет подпись итогов
+// @Summary Проверить подпись итогов
+// @Description Проверяет, что итоги, полученные из /subscriptions/summary с signed=true, не изменены: тело - ответ summary как есть. Неверная подпись - не ошибка запроса: ответ 200 с valid=false и причиной
+// @Tags summary
+// @Accept json
+// @Produce json
+// @Param request body model.SummaryResponse true "Подписанные итоги"
+// @Success 200 {object} model.SummaryVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/summary/verify [post]
+func (h *SubscriptionHandler) VerifySummary(c *gin.Context) {
+	var summary model.SummaryResponse
+	if err := bindBody(c, &summary); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.VerifySummary(c.Request.Context(), &summary)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to verify summary signature")
+		return
+	}
+
+	respond(c, http.StatusOK, result)
 }
 
 // Вспомогательные структуры для ответов
-type ErrorResponse struct {
-	Error string `json:"error"`
+// ListUserServices возвращает сервисы, на которые подписан пользователь
+// @Summary Сервисы пользователя
+// @Description Возвращает сервисы с подписками пользователя, действующими в текущем месяце (без черновиков): число подписок и их суммарную стоимость в месяц. Приостановленные подписки учитываются в числе, но не в стоимости. Сортировка по убыванию стоимости
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} model.UserService
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/services [get]
+func (h *SubscriptionHandler) ListUserServices(c *gin.Context) {
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return
+	}
+
+	services, err := h.service.ListUserServices(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list user services",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, services)
 }
 
 type SuccessResponse struct {
-	Message string `json:"message"`
+	Message string `json:"message" example:"subscription updated successfully"`
 }
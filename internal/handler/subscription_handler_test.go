@@ -0,0 +1,1068 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	subscriptionPath = "/api/v1/subscriptions/6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"
+	validCreateBody  = `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025"}`
+)
+
+func TestCreateSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "created",
+			method: http.MethodPost,
+			path:   "/api/v1/subscriptions",
+			body:   validCreateBody,
+			service: &mockService{
+				createFn: func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+					if req.ServiceName != "Yandex Plus" || req.StartDate != "07-2025" {
+						t.Errorf("unexpected request passed to service: %+v", req)
+					}
+					return fixtureSubscription(), nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			golden:     "create_subscription",
+		},
+		{
+			name:       "malformed json",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"service_name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			method:      http.MethodPost,
+			path:        "/api/v1/subscriptions",
+			body:        validCreateBody,
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "missing required fields",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"service_name":"Yandex Plus"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "zero monthly cost",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions",
+			body:       `{"service_name":"Yandex Plus","monthly_cost":0,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "service error",
+			method: http.MethodPost,
+			path:   "/api/v1/subscriptions",
+			body:   validCreateBody,
+			service: &mockService{
+				createFn: func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+					return nil, errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			golden:     "error_database",
+		},
+	})
+}
+
+func TestGetSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "found",
+			method: http.MethodGet,
+			path:   subscriptionPath,
+			service: &mockService{
+				getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return fixtureSubscription(), nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "get_subscription",
+		},
+		{
+			name:       "bad uuid",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+			golden:     "error_invalid_id",
+		},
+		{
+			name:   "not found",
+			method: http.MethodGet,
+			path:   subscriptionPath,
+			service: &mockService{
+				getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return nil, errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			golden:     "error_not_found",
+		},
+		{
+			name:   "service error",
+			method: http.MethodGet,
+			path:   subscriptionPath,
+			service: &mockService{
+				getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return nil, errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "updated",
+			method: http.MethodPut,
+			path:   subscriptionPath,
+			body:   validCreateBody,
+			service: &mockService{
+				updateFn: func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+					return nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "update_subscription",
+		},
+		{
+			name:       "bad uuid",
+			method:     http.MethodPut,
+			path:       "/api/v1/subscriptions/123",
+			body:       validCreateBody,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			method:     http.MethodPut,
+			path:       subscriptionPath,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "not found",
+			method: http.MethodPut,
+			path:   subscriptionPath,
+			body:   validCreateBody,
+			service: &mockService{
+				updateFn: func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+					return errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "invalid status transition",
+			method: http.MethodPut,
+			path:   subscriptionPath,
+			body:   validCreateBody,
+			service: &mockService{
+				updateFn: func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+					return errInvalidTransition
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "unknown status",
+			method:     http.MethodPut,
+			path:       subscriptionPath,
+			body:       `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025","status":"expired"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "deleted",
+			method: http.MethodDelete,
+			path:   subscriptionPath,
+			service: &mockService{
+				deleteFn: func(ctx context.Context, id uuid.UUID) error {
+					return nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "delete_subscription",
+		},
+		{
+			name:       "bad uuid",
+			method:     http.MethodDelete,
+			path:       "/api/v1/subscriptions/xyz",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "not found",
+			method: http.MethodDelete,
+			path:   subscriptionPath,
+			service: &mockService{
+				deleteFn: func(ctx context.Context, id uuid.UUID) error {
+					return errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	})
+}
+
+func TestActivateSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "activated",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/activate",
+			service: &mockService{
+				activateFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return fixtureSubscription(), nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "already active",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/activate",
+			service: &mockService{
+				activateFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return nil, errAlreadyActive
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "not found",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/activate",
+			service: &mockService{
+				activateFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+					return nil, errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	})
+}
+
+func TestCancelSubscription(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "without body",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/cancel",
+			service: &mockService{
+				cancelFn: func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+					if req != (model.CancelSubscriptionRequest{}) {
+						t.Errorf("unexpected request passed to service: %+v", req)
+					}
+					return fixtureSubscription(), nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "with reason at period end",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/cancel",
+			body:   `{"reason":"too expensive","at_period_end":true}`,
+			service: &mockService{
+				cancelFn: func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+					if req.Reason == nil || *req.Reason != "too expensive" || !req.AtPeriodEnd {
+						t.Errorf("unexpected request passed to service: %+v", req)
+					}
+					return fixtureSubscription(), nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "invalid effective period",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/cancel",
+			body:   `{"effective_period":"2025-09"}`,
+			service: &mockService{
+				cancelFn: func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+					return nil, model.Invalid(model.ErrInvalidInput, "invalid cancellation: effective period format, expected MM-YYYY")
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "already cancelled",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/cancel",
+			service: &mockService{
+				cancelFn: func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+					return nil, fmt.Errorf("%w from cancelled to cancelled", service.ErrInvalidStatusTransition)
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "not found",
+			method: http.MethodPost,
+			path:   subscriptionPath + "/cancel",
+			service: &mockService{
+				cancelFn: func(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+					return nil, errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	})
+}
+
+func TestListSubscriptions(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "filtered by user and service",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions?user_id=60601fee-2bf1-4721-ae6f-7636e79a0cba&service_name=Yandex%20Plus",
+			service: &mockService{
+				listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+					if filter.UserID == nil || filter.ServiceName == nil || *filter.ServiceName != "Yandex Plus" {
+						t.Errorf("filters were not passed to service: %+v", filter)
+					}
+					if page != (model.Pagination{Limit: 100, Offset: 0}) {
+						t.Errorf("unexpected default pagination: %+v", page)
+					}
+					return &model.SubscriptionPage{Items: []*model.Subscription{fixtureSubscription()}, Total: 1}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "list_subscriptions",
+		},
+		{
+			name:   "filtered by status",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions?status=expired",
+			service: &mockService{
+				listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+					if filter.Status == nil || *filter.Status != model.StatusExpired {
+						t.Errorf("status filter was not passed to service: %+v", filter)
+					}
+					return &model.SubscriptionPage{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid status",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?status=deleted",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid limit",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "limit above maximum",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?limit=1001",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative offset",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?offset=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "service error",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions",
+			service: &mockService{
+				listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+					return nil, errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestListSubscriptionsPaginationHeaders(t *testing.T) {
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+			if page != (model.Pagination{Limit: 10, Offset: 20}) {
+				t.Errorf("pagination was not passed to service: %+v", page)
+			}
+			return &model.SubscriptionPage{Items: []*model.Subscription{fixtureSubscription()}, Total: 45}, nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?service_name=Netflix&limit=10&offset=20", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "45" {
+		t.Errorf("X-Total-Count = %q, want 45", got)
+	}
+	wantLink := `</api/v1/subscriptions?limit=10&offset=30&service_name=Netflix>; rel="next", ` +
+		`</api/v1/subscriptions?limit=10&offset=10&service_name=Netflix>; rel="prev"`
+	if got := rec.Header().Get("Link"); got != wantLink {
+		t.Errorf("Link = %q, want %q", got, wantLink)
+	}
+}
+
+func TestListSubscriptionsEstimatedCount(t *testing.T) {
+	svc := &mockService{
+		listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+			if page.Count != model.CountEstimate {
+				t.Errorf("count mode = %q, want estimate", page.Count)
+			}
+			return &model.SubscriptionPage{Items: []*model.Subscription{fixtureSubscription()}, Total: 250000, Estimated: true}, nil
+		},
+	}
+	router := newTestRouter(svc)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?count=estimate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "250000" {
+		t.Errorf("X-Total-Count = %q, want 250000", got)
+	}
+	if got := rec.Header().Get("X-Total-Count-Estimated"); got != "true" {
+		t.Errorf("X-Total-Count-Estimated = %q, want true", got)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?count=approximate", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status for unknown count mode = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestListSubscriptionsCursor(t *testing.T) {
+	next := fixtureSubscription()
+	var gotAfter []*model.SubscriptionCursor
+
+	svc := &mockService{
+		listAfterFn: func(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error) {
+			if limit != 1 || filter.ServiceName == nil || *filter.ServiceName != "Netflix" {
+				t.Errorf("unexpected arguments: limit=%d filter=%+v", limit, filter)
+			}
+			gotAfter = append(gotAfter, after)
+			if after == nil {
+				return &model.SubscriptionCursorPage{
+					Items: []*model.Subscription{next},
+					Next:  &model.SubscriptionCursor{CreatedAt: next.CreatedAt, ID: next.ID},
+				}, nil
+			}
+			return &model.SubscriptionCursorPage{}, nil
+		},
+	}
+	router := newTestRouter(svc)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?service_name=Netflix&limit=1&cursor=", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Total-Count") != "" {
+		t.Error("X-Total-Count must not be set in cursor mode")
+	}
+	cursor := rec.Header().Get("X-Next-Cursor")
+	if cursor == "" {
+		t.Fatal("X-Next-Cursor is not set")
+	}
+	wantLink := `</api/v1/subscriptions?cursor=` + cursor + `&limit=1&service_name=Netflix>; rel="next"`
+	if got := rec.Header().Get("Link"); got != wantLink {
+		t.Errorf("Link = %q, want %q", got, wantLink)
+	}
+
+	// Курсор из ответа возвращает ту же позицию в сервис
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?service_name=Netflix&limit=1&cursor="+cursor, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Header().Get("X-Next-Cursor") != "" || rec.Header().Get("Link") != "" {
+		t.Error("last page must not have a next cursor")
+	}
+	if len(gotAfter) != 2 || gotAfter[1] == nil || !gotAfter[1].CreatedAt.Equal(next.CreatedAt) || gotAfter[1].ID != next.ID {
+		t.Errorf("cursor was not decoded back to the last item: %+v", gotAfter)
+	}
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:       "invalid cursor",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?cursor=not-a-cursor",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "cursor with offset",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?cursor=&offset=10",
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestExportSubscriptions(t *testing.T) {
+	sub := fixtureSubscription()
+	sub.ServiceName = "=HYPERLINK(\"x\")"
+
+	svc := &mockService{
+		exportFn: func(ctx context.Context, fn func(*model.Subscription) error) error {
+			return fn(sub)
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	want := "id,service_name,monthly_cost,user_id,start_date,end_date,prepaid_amount,is_draft,created_at,updated_at\n" +
+		"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11,\"'=HYPERLINK(\"\"x\"\")\",400,60601fee-2bf1-4721-ae6f-7636e79a0cba,07-2025,12-2025,,false,2025-07-10T09:30:00Z,2025-07-10T09:30:00Z\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body =\n%s\nwant\n%s", got, want)
+	}
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "service error before first row",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/export",
+			service: &mockService{
+				exportFn: func(ctx context.Context, fn func(*model.Subscription) error) error {
+					return errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			golden:     "error_database",
+		},
+	})
+}
+
+func TestStreamSubscriptions(t *testing.T) {
+	first := fixtureSubscription()
+	second := fixtureSubscription()
+	second.ID = uuid.MustParse("0b9d1c3e-7f2a-4e5b-8c6d-1a2b3c4d5e6f")
+
+	svc := &mockService{
+		streamFn: func(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+			if filter.Status == nil || *filter.Status != model.StatusActive {
+				t.Errorf("unexpected filter passed to service: %+v", filter)
+			}
+			if err := fn(first); err != nil {
+				return err
+			}
+			if err := fn(second); err != nil {
+				return err
+			}
+			return errDatabase
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/stream?status=active", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+
+	// Две подписки и строка с ошибкой, оборвавшей выгрузку
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), rec.Body.String())
+	}
+	var sub struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &sub); err != nil || sub.ID != second.ID.String() {
+		t.Errorf("second line = %s, want subscription %s", lines[1], second.ID)
+	}
+	var problem handler.ErrorResponse
+	if err := json.Unmarshal([]byte(lines[2]), &problem); err != nil || problem.Code != handler.CodeInternal || problem.Detail != "database is unavailable" {
+		t.Errorf("last line = %s, want problem details", lines[2])
+	}
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "service error before first row",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/stream",
+			service: &mockService{
+				streamFn: func(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+					return errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			golden:     "error_database",
+		},
+		{
+			name:       "invalid filter",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/stream?user_id=not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestCalculateTotalCost(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "calculated",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&user_id=60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			service: &mockService{
+				totalCostFn: func(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+					if filter.StartPeriod != "01-2025" || filter.EndPeriod != "12-2025" || filter.UserID == uuid.Nil {
+						t.Errorf("unexpected filter passed to service: %+v", filter)
+					}
+					return &model.SummaryResponse{TotalCost: 2400, ActiveCost: 1600, CancelledCost: 800}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "summary",
+		},
+		{
+			name:       "missing periods",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/summary?start_period=01-2025",
+			wantStatus: http.StatusBadRequest,
+			golden:     "error_missing_periods",
+		},
+		{
+			name:       "bad user id",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&user_id=nope",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown amount type",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&amount=brutto",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "exclusions",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&exclude_service_name=Yandex+Plus&exclude_service_name=Netflix&exclude_user_id=60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			service: &mockService{
+				totalCostFn: func(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+					wantUsers := []uuid.UUID{uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")}
+					if !reflect.DeepEqual(filter.ExcludeServiceNames, []string{"Yandex Plus", "Netflix"}) || !reflect.DeepEqual(filter.ExcludeUserIDs, wantUsers) {
+						t.Errorf("unexpected exclusions passed to service: %+v", filter)
+					}
+					return &model.SummaryResponse{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bad excluded user id",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&exclude_user_id=nope",
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestListChanges(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "page after cursor",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/changes?since_seq=10&limit=2",
+			service: &mockService{
+				changesFn: func(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error) {
+					if sinceSeq != 10 || limit != 2 {
+						t.Errorf("since_seq=%d limit=%d, want 10 and 2", sinceSeq, limit)
+					}
+					return &model.ChangesResponse{Items: []*model.SubscriptionChange{}, NextSinceSeq: 10}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "negative cursor",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/changes?since_seq=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "limit too large",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/changes?limit=100000",
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestListUserServices(t *testing.T) {
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "services",
+			method: http.MethodGet,
+			path:   "/api/v1/users/60601fee-2bf1-4721-ae6f-7636e79a0cba/services",
+			service: &mockService{
+				servicesFn: func(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+					return []model.UserService{
+						{ServiceName: "Yandex Plus", Subscriptions: 2, MonthlyCost: 800},
+						{ServiceName: "Kinopoisk", Subscriptions: 1, MonthlyCost: 300},
+					}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			golden:     "list_user_services",
+		},
+		{
+			name:       "bad uuid",
+			method:     http.MethodGet,
+			path:       "/api/v1/users/123/services",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "service error",
+			method: http.MethodGet,
+			path:   "/api/v1/users/60601fee-2bf1-4721-ae6f-7636e79a0cba/services",
+			service: &mockService{
+				servicesFn: func(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+					return nil, errDatabase
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestSyncUserSubscriptions(t *testing.T) {
+	const (
+		path = "/api/v1/users/60601fee-2bf1-4721-ae6f-7636e79a0cba/subscriptions:sync"
+		body = `{"subscriptions":[{"service_name":"Yandex Plus","monthly_cost":400,"start_date":"07-2025"}]}`
+	)
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "dry run",
+			method: http.MethodPut,
+			path:   path + "?dry_run=true",
+			body:   body,
+			service: &mockService{
+				syncFn: func(ctx context.Context, id uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error) {
+					if id != userID || !dryRun || len(items) != 1 || items[0].UserID != userID {
+						return nil, fmt.Errorf("unexpected sync: %s %v %+v", id, dryRun, items)
+					}
+					return &model.SyncResponse{DryRun: true, Created: []*model.Subscription{}, Updated: []model.SyncUpdate{}, Deleted: []*model.Subscription{}, Unchanged: []uuid.UUID{}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "foreign user in item",
+			method:     http.MethodPut,
+			path:       path,
+			body:       `{"subscriptions":[{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"8d2f1c4e-5b6a-4c3d-9e8f-0a1b2c3d4e5f","start_date":"07-2025"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid item",
+			method:     http.MethodPut,
+			path:       path,
+			body:       `{"subscriptions":[{"monthly_cost":400,"start_date":"07-2025"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid dry_run",
+			method:     http.MethodPut,
+			path:       path + "?dry_run=maybe",
+			body:       body,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown method",
+			method:     http.MethodPut,
+			path:       "/api/v1/users/60601fee-2bf1-4721-ae6f-7636e79a0cba/subscriptions:merge",
+			body:       body,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "conflict",
+			method: http.MethodPut,
+			path:   path,
+			body:   body,
+			service: &mockService{
+				syncFn: func(ctx context.Context, id uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error) {
+					return nil, service.ErrSyncConflict
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+	})
+}
+
+func TestTransferSubscription(t *testing.T) {
+	const body = `{"user_id":"8d2f1c4e-5b6a-4c3d-9e8f-0a1b2c3d4e5f","reason":"family plan"}`
+	transferred := func(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+		if req.UserID.String() != "8d2f1c4e-5b6a-4c3d-9e8f-0a1b2c3d4e5f" || req.Reason == nil || *req.Reason != "family plan" {
+			t.Errorf("unexpected request passed to service: %+v", req)
+		}
+		sub := fixtureSubscription()
+		sub.UserID = req.UserID
+		return sub, nil
+	}
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:       "transferred",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			adminToken: testAdminToken,
+			service:    &mockService{transferFn: transferred},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "without admin token",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong admin token",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			adminToken: "wrong",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing user_id",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       `{"reason":"family plan"}`,
+			adminToken: testAdminToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "same owner",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			adminToken: testAdminToken,
+			service: &mockService{
+				transferFn: func(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+					return nil, model.Invalid(model.ErrInvalidInput, "invalid transfer: subscription already belongs to user %s", req.UserID)
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "cancelled",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			adminToken: testAdminToken,
+			service: &mockService{
+				transferFn: func(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+					return nil, fmt.Errorf("%w: it is cancelled", service.ErrTransferNotAllowed)
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "not found",
+			method:     http.MethodPost,
+			path:       subscriptionPath + "/transfer",
+			body:       body,
+			adminToken: testAdminToken,
+			service: &mockService{
+				transferFn: func(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+					return nil, errNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	})
+}
+
+func TestValidateSubscriptionAPI(t *testing.T) {
+	body := `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025"}`
+	validated := func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error) {
+		return &model.ValidationResult{Valid: true, Warnings: []model.ValidationWarning{}, Normalized: &req}, nil
+	}
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:       "valid",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions/validate",
+			body:       body,
+			service:    &mockService{validateFn: validated},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing required fields",
+			method:     http.MethodPost,
+			path:       "/api/v1/subscriptions/validate",
+			body:       `{"monthly_cost":400}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "repository failure",
+			method: http.MethodPost,
+			path:   "/api/v1/subscriptions/validate",
+			body:   body,
+			service: &mockService{
+				validateFn: func(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error) {
+					return nil, errors.New("failed to check overlapping subscriptions: connection refused")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestScopedListForbidden(t *testing.T) {
+	forbidden := fmt.Errorf("%w: cannot access subscriptions of user 60601fee-2bf1-4721-ae6f-7636e79a0cba", auth.ErrForbidden)
+
+	runAPITests(t, []apiTestCase{
+		{
+			name:   "list",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions?user_id=60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			service: &mockService{
+				listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+					return nil, forbidden
+				},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "summary",
+			method: http.MethodGet,
+			path:   "/api/v1/subscriptions/summary?start_period=01-2025&end_period=12-2025&user_id=60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			service: &mockService{
+				totalCostFn: func(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+					return nil, forbidden
+				},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	})
+}
+
+// TestSubscriptionOwnership проверяет запросы пользователя без прав администратора к
+// подпискам по ID через настоящий сервис: чужие подписки для него не существуют
+func TestSubscriptionOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	self := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	other := uuid.New()
+
+	repo := repository.NewInMemorySubscriptionRepository()
+	tax, _ := money.NewTax("0", true, "half_up")
+	svc := service.NewSubscriptionService(repo, service.FixedTax(tax), nil, nil, logger.New(slog.LevelError+4))
+	foreign := &model.Subscription{ServiceName: "Netflix", MonthlyCost: 500, UserID: other, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Status: model.StatusActive}
+	draft := &model.Subscription{ServiceName: "Spotify", MonthlyCost: 300, UserID: other, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Status: model.StatusActive, IsDraft: true}
+	own := &model.Subscription{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: self, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Status: model.StatusActive}
+	for _, sub := range []*model.Subscription{foreign, draft, own} {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithCaller(c.Request.Context(), auth.Caller{UserID: self}))
+	})
+	handler.NewSubscriptionHandler(svc, testAdminToken, logger.New(slog.LevelError+4)).RegisterRoutes(api)
+
+	foreignPath := "/api/v1/subscriptions/" + foreign.ID.String()
+	otherBody := strings.Replace(validCreateBody, self.String(), other.String(), 1)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"get foreign", http.MethodGet, foreignPath, "", http.StatusNotFound},
+		{"prices of foreign", http.MethodGet, foreignPath + "/prices", "", http.StatusNotFound},
+		{"update foreign", http.MethodPut, foreignPath, validCreateBody, http.StatusNotFound},
+		{"cancel foreign", http.MethodPost, foreignPath + "/cancel", "", http.StatusNotFound},
+		{"activate foreign", http.MethodPost, "/api/v1/subscriptions/" + draft.ID.String() + "/activate", "", http.StatusNotFound},
+		{"delete foreign", http.MethodDelete, foreignPath, "", http.StatusNotFound},
+		{"bulk delete foreign", http.MethodDelete, "/api/v1/subscriptions/batch", `{"ids":["` + foreign.ID.String() + `"]}`, http.StatusUnprocessableEntity},
+		{"create for other user", http.MethodPost, "/api/v1/subscriptions", otherBody, http.StatusForbidden},
+		{"give own to other user", http.MethodPut, "/api/v1/subscriptions/" + own.ID.String(), otherBody, http.StatusForbidden},
+		{"get own", http.MethodGet, "/api/v1/subscriptions/" + own.ID.String(), "", http.StatusOK},
+		{"create for self", http.MethodPost, "/api/v1/subscriptions", validCreateBody, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	// Чужие подписки не изменились
+	for _, sub := range []*model.Subscription{foreign, draft} {
+		stored, err := repo.GetByID(ctx, sub.ID)
+		if err != nil || stored == nil || stored.EndDate != nil || stored.IsDraft != sub.IsDraft || stored.UserID != other {
+			t.Errorf("foreign subscription %s changed: %+v, %v", sub.ServiceName, stored, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// syncMethod - суффикс пути синхронизации подписок пользователя
+const syncMethod = ":sync"
+
+// SyncUserSubscriptions приводит подписки пользователя к желаемому набору
+// @Summary Синхронизировать подписки пользователя
+// @Description Принимает полный желаемый набор подписок пользователя (до 1000) и сам вычисляет изменения: элемент с id изменяет эту подписку, элемент без id изменяет подписку того же сервиса с тем же start_date или создает новую, подписки пользователя вне набора удаляются. Каждый элемент проверяется как тело PUT /subscriptions/{id}, user_id можно не указывать. Изменения применяются в одной транзакции и возвращаются в ответе; с dry_run=true только вычисляются
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param dry_run query bool false "Только вычислить изменения"
+// @Param request body model.SyncSubscriptionsRequest true "Желаемый набор подписок"
+// @Success 200 {object} model.SyncResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/subscriptions:sync [put]
+func (h *SubscriptionHandler) SyncUserSubscriptions(c *gin.Context) {
+	if c.Param("method") != syncMethod {
+		respondProblem(c, http.StatusNotFound, CodeNotFound, "route not found")
+		return
+	}
+
+	userID, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid user ID format",
+			"user_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid user ID")
+		return
+	}
+
+	var dryRun bool
+	if v := c.Query("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "invalid dry_run: expected true or false")
+			return
+		}
+	}
+
+	var req model.SyncSubscriptionsRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body for subscription sync")
+		return
+	}
+
+	for i := range req.Subscriptions {
+		if err := validateSyncItem(c, userID, i, &req.Subscriptions[i]); err != nil {
+			respondError(c, h.logger, err, "Invalid subscription in sync request",
+				"user_id", userID,
+				"index", i,
+			)
+			return
+		}
+	}
+
+	resp, err := h.service.SyncUserSubscriptions(c.Request.Context(), userID, req.Subscriptions, dryRun)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to sync user subscriptions",
+			"user_id", userID,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, resp)
+}
+
+// validateSyncItem проверяет i-й элемент желаемого набора как тело PUT /subscriptions/{id}.
+// Пустой user_id заполняется пользователем из пути, другой пользователь - ошибка
+func validateSyncItem(c *gin.Context, userID uuid.UUID, i int, item *model.SyncSubscriptionItem) error {
+	if item.UserID == uuid.Nil {
+		item.UserID = userID
+	}
+	if item.UserID != userID {
+		return model.Invalid(model.ErrInvalidInput, "subscriptions[%d]: user_id does not match user %s", i, userID)
+	}
+
+	err := validateItem(c, item)
+	if err == nil {
+		err = checkBodyUUIDs(c, &item.UpdateSubscriptionRequest)
+	}
+	if err == nil {
+		return nil
+	}
+
+	return nestedBodyError(item, err, fmt.Sprintf("subscriptions[%d]", i))
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TagHandler struct {
+	service service.TagService
+	logger  *logger.Logger
+}
+
+func NewTagHandler(service service.TagService, logger *logger.Logger) *TagHandler {
+	return &TagHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты тегов в группе API
+func (h *TagHandler) RegisterRoutes(api gin.IRouter) {
+	tags := api.Group("/tags")
+	{
+		tags.POST("", h.CreateTag)
+		tags.GET("", h.ListTags)
+		tags.PUT("/:id", h.RenameTag)
+		tags.DELETE("/:id", h.DeleteTag)
+	}
+}
+
+// CreateTag создает тег
+// @Summary Создать тег
+// @Description Создает тег организации заранее; теги из поля tags подписок создаются автоматически. Название хранится в нижнем регистре
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body model.TagRequest true "Название тега"
+// @Success 201 {object} model.Tag
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req model.TagRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.CreateTag(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to create tag",
+			"name", req.Name,
+		)
+		return
+	}
+
+	respond(c, http.StatusCreated, result)
+}
+
+// ListTags возвращает теги организации
+// @Summary Список тегов
+// @Description Возвращает теги по названию с числом подписок у каждого
+// @Tags tags
+// @Produce json
+// @Success 200 {array} model.Tag
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list tags")
+		return
+	}
+
+	respond(c, http.StatusOK, tags)
+}
+
+// RenameTag переименовывает тег
+// @Summary Переименовать тег
+// @Description Переименовывает тег у всех подписок, на которых он стоит
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "ID тега"
+// @Param tag body model.TagRequest true "Новое название тега"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/{id} [put]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req model.TagRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body",
+			"tag_id", id,
+		)
+		return
+	}
+
+	result, err := h.service.RenameTag(c.Request.Context(), id, req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to rename tag",
+			"tag_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// DeleteTag удаляет тег
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех подписок
+// @Tags tags
+// @Param id path string true "ID тега"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(c.Request.Context(), id); err != nil {
+		respondError(c, h.logger, err, "Failed to delete tag",
+			"tag_id", id,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, SuccessResponse{Message: "tag deleted successfully"})
+}
+
+func (h *TagHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := parseUUID(c, c.Param("id"))
+	if err != nil {
+		h.logger.Warn(c.Request.Context(), "Invalid tag ID format",
+			"tag_id", c.Param("id"),
+			"error", err,
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidID, "invalid tag ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
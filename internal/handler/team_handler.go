@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamHandler struct {
+	service service.TeamService
+	logger  *logger.Logger
+}
+
+func NewTeamHandler(service service.TeamService, logger *logger.Logger) *TeamHandler {
+	return &TeamHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршрут обзора подписок организации в группе API
+func (h *TeamHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/tenant/subscriptions/overview", h.GetOverview)
+}
+
+// GetOverview возвращает обзор подписок пользователей организации
+// @Summary Обзор подписок организации
+// @Description Для администраторов: траты каждого пользователя организации в текущем месяце, сервисы, на которые подписаны несколько пользователей, и сервисы, которые оплачивают по отдельности 3 и более пользователей (кандидаты на общую подписку). Названия сервисов сравниваются без учета регистра и лишних пробелов
+// @Tags tenant
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string false "Группировка" Enums(user) default(user)
+// @Success 200 {object} model.TenantOverview
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tenant/subscriptions/overview [get]
+func (h *TeamHandler) GetOverview(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", model.OverviewGroupByUser)
+
+	overview, err := h.service.Overview(c.Request.Context(), groupBy)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to build tenant overview",
+			"group_by", groupBy,
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, overview)
+}
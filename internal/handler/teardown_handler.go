@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeardownHandler struct {
+	service service.TeardownService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewTeardownHandler(service service.TeardownService, token string, logger *logger.Logger) *TeardownHandler {
+	return &TeardownHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует удаление данных организации среди административных маршрутов
+func (h *TeardownHandler) RegisterRoutes(api gin.IRouter) {
+	api.POST("/admin/tenants/:tenant/teardown", RequireAdminToken(h.token), h.Teardown)
+}
+
+// Teardown удаляет тестовые данные организации или одного ее пользователя
+// @Summary Удалить данные организации
+// @Description Удаляет подписки, паузы, передачи, скидки, счета, журналы изменений и аудита, отклоненные запросы и настройки уведомлений организации (с user_id - только данные пользователя) для сброса демо- и staging-стендов. confirm должен совпадать с идентификатором организации; dry_run возвращает число строк, ничего не удаляя. При APP_ENV=production запрещено, пока не задан TEARDOWN_ALLOW_PRODUCTION=true
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant path string true "Идентификатор организации"
+// @Param request body model.TeardownRequest true "Подтверждение и область удаления"
+// @Success 200 {object} model.TeardownResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants/{tenant}/teardown [post]
+func (h *TeardownHandler) Teardown(c *gin.Context) {
+	var req model.TeardownRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Teardown(c.Request.Context(), c.Param("tenant"), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to tear down tenant data",
+			"tenant", c.Param("tenant"),
+		)
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
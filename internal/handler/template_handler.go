@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateHandler struct {
+	service service.TemplateService
+	// token - ADMIN_TOKEN: шаблоны общие для всех пользователей, поэтому их меняет
+	// и рендерит с произвольным текстом только администратор
+	token  string
+	logger *logger.Logger
+}
+
+func NewTemplateHandler(service service.TemplateService, token string, logger *logger.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты шаблонов писем в группе API
+func (h *TemplateHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/templates", h.ListTemplates)
+	api.GET("/templates/:name", h.GetTemplate)
+	api.PUT("/templates/:name", RequireAdminToken(h.token), h.SaveTemplate)
+	api.GET("/templates/:name/versions", h.ListVersions)
+	api.POST("/templates/:name/preview", RequireAdminToken(h.token), h.PreviewTemplate)
+}
+
+// ListTemplates возвращает текущие версии всех шаблонов
+// @Summary Список шаблонов писем
+// @Description Возвращает последнюю версию каждого шаблона; шаблоны без версий в базе возвращаются встроенными
+// @Tags templates
+// @Produce json
+// @Success 200 {array} model.EmailTemplate
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [get]
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.service.ListTemplates(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to list templates")
+		return
+	}
+
+	respond(c, http.StatusOK, templates)
+}
+
+// GetTemplate возвращает шаблон
+// @Summary Получить шаблон письма
+// @Description Возвращает текущую или указанную версию шаблона
+// @Tags templates
+// @Produce json
+// @Param name path string true "Имя шаблона"
+// @Param version query int false "Номер версии (по умолчанию текущая)"
+// @Success 200 {object} model.EmailTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	version := 0
+	if raw := c.Query("version"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			h.logger.Warn(c.Request.Context(), "Invalid template version",
+				"version", raw,
+			)
+			respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, "version must be a positive integer")
+			return
+		}
+		version = parsed
+	}
+
+	tmpl, err := h.service.GetTemplate(c.Request.Context(), c.Param("name"), version)
+	if err != nil {
+		h.respondTemplateError(c, "Failed to get template", err)
+		return
+	}
+
+	respond(c, http.StatusOK, tmpl)
+}
+
+// ListVersions возвращает историю версий шаблона
+// @Summary Версии шаблона письма
+// @Description Возвращает все сохраненные версии шаблона, начиная с последней
+// @Tags templates
+// @Produce json
+// @Param name path string true "Имя шаблона"
+// @Success 200 {array} model.EmailTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name}/versions [get]
+func (h *TemplateHandler) ListVersions(c *gin.Context) {
+	versions, err := h.service.ListVersions(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.respondTemplateError(c, "Failed to list template versions", err)
+		return
+	}
+
+	respond(c, http.StatusOK, versions)
+}
+
+// SaveTemplate сохраняет новую версию шаблона
+// @Summary Сохранить шаблон письма
+// @Description Создает новую версию шаблона. Тема - text/template, тело - html/template. Требует ADMIN_TOKEN
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Имя шаблона"
+// @Param template body model.SaveTemplateRequest true "Тема и тело шаблона"
+// @Success 201 {object} model.EmailTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [put]
+func (h *TemplateHandler) SaveTemplate(c *gin.Context) {
+	var req model.SaveTemplateRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	tmpl, err := h.service.SaveTemplate(c.Request.Context(), c.Param("name"), req)
+	if err != nil {
+		h.respondTemplateError(c, "Failed to save template", err)
+		return
+	}
+
+	respond(c, http.StatusCreated, tmpl)
+}
+
+// PreviewTemplate рендерит шаблон без сохранения
+// @Summary Предпросмотр шаблона письма
+// @Description Рендерит текущую версию шаблона или переданные subject/body с примерными или переданными данными. Требует ADMIN_TOKEN
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Имя шаблона"
+// @Param preview body model.PreviewTemplateRequest false "Шаблон и данные для предпросмотра"
+// @Success 200 {object} model.RenderedTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name}/preview [post]
+func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
+	var req model.PreviewTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindBody(c, &req); err != nil {
+			respondError(c, h.logger, err, "Invalid request body")
+			return
+		}
+	}
+
+	rendered, err := h.service.Preview(c.Request.Context(), c.Param("name"), req)
+	if err != nil {
+		h.respondTemplateError(c, "Failed to preview template", err)
+		return
+	}
+
+	respond(c, http.StatusOK, rendered)
+}
+
+func (h *TemplateHandler) respondTemplateError(c *gin.Context, msg string, err error) {
+	respondError(c, h.logger, err, msg,
+		"name", c.Param("name"),
+	)
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateServiceStub считает изменения шаблонов; остальные методы не вызываются
+type templateServiceStub struct {
+	service.TemplateService
+	saved    int
+	previews int
+}
+
+func (s *templateServiceStub) GetTemplate(ctx context.Context, name string, version int) (*model.EmailTemplate, error) {
+	return &model.EmailTemplate{Name: name, Subject: "subject", Body: "body"}, nil
+}
+
+func (s *templateServiceStub) SaveTemplate(ctx context.Context, name string, req model.SaveTemplateRequest) (*model.EmailTemplate, error) {
+	s.saved++
+	return &model.EmailTemplate{Name: name, Subject: req.Subject, Body: req.Body, Version: 1}, nil
+}
+
+func (s *templateServiceStub) Preview(ctx context.Context, name string, req model.PreviewTemplateRequest) (*model.RenderedTemplate, error) {
+	s.previews++
+	return &model.RenderedTemplate{Subject: "subject", Body: "body"}, nil
+}
+
+func TestTemplateWritesRequireAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	const body = `{"subject": "Скоро продление {{.ServiceName}}", "body": "<p>{{.ServiceName}}</p>"}`
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{"save without token", http.MethodPut, "/api/v1/templates/renewal_reminder", "", http.StatusUnauthorized},
+		{"save with wrong token", http.MethodPut, "/api/v1/templates/renewal_reminder", "user-token", http.StatusUnauthorized},
+		{"save with admin token", http.MethodPut, "/api/v1/templates/renewal_reminder", testAdminToken, http.StatusCreated},
+		{"preview without token", http.MethodPost, "/api/v1/templates/renewal_reminder/preview", "", http.StatusUnauthorized},
+		{"preview with admin token", http.MethodPost, "/api/v1/templates/renewal_reminder/preview", testAdminToken, http.StatusOK},
+		{"read without token", http.MethodGet, "/api/v1/templates/renewal_reminder", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &templateServiceStub{}
+			router := gin.New()
+			handler.NewTemplateHandler(svc, testAdminToken, log).RegisterRoutes(router.Group("/api/v1"))
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code >= 400 && svc.saved+svc.previews > 0 {
+				t.Error("rejected request reached the template service")
+			}
+		})
+	}
+
+	// Без ADMIN_TOKEN изменение шаблонов отключено
+	router := gin.New()
+	handler.NewTemplateHandler(&templateServiceStub{}, "", log).RegisterRoutes(router.Group("/api/v1"))
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/templates/renewal_reminder", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("save with admin API disabled = %d, want 403", rec.Code)
+	}
+}
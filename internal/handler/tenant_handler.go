@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	service service.TenantService
+	token   string
+	logger  *logger.Logger
+}
+
+func NewTenantHandler(service service.TenantService, token string, logger *logger.Logger) *TenantHandler {
+	return &TenantHandler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует заведение организаций среди административных маршрутов
+func (h *TenantHandler) RegisterRoutes(api gin.IRouter) {
+	api.PUT("/admin/tenants/:tenant", RequireAdminToken(h.token), h.Provision)
+}
+
+// Provision заводит организацию или приводит ее к описанию из запроса
+// @Summary Завести организацию
+// @Description Декларативное заведение организации для систем развертывания: название, налоговые настройки, сервисы каталога, теги и личные токены API пользователей в одной транзакции. Повтор запроса ничего не меняет: существующие сервисы приводятся к описанию, недостающие теги создаются, токен выпускается, только если у пользователя нет токена с тем же названием. Не перечисленные в запросе сервисы, теги и токены не удаляются. Блок settings задает ставку налога и округление организации целиком: незаданное поле возвращает значение из конфигурации, без блока настройки не меняются. Секреты выпущенных токенов показываются только в этом ответе. 201 - организация заведена, 200 - уже существовала
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant path string true "Идентификатор организации"
+// @Param request body model.ProvisionTenantRequest true "Желаемое состояние организации"
+// @Success 200 {object} model.ProvisionTenantResult
+// @Success 201 {object} model.ProvisionTenantResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants/{tenant} [put]
+func (h *TenantHandler) Provision(c *gin.Context) {
+	var req model.ProvisionTenantRequest
+	if err := bindBody(c, &req); err != nil {
+		respondError(c, h.logger, err, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Provision(c.Request.Context(), c.Param("tenant"), req)
+	if err != nil {
+		respondError(c, h.logger, err, "Failed to provision tenant",
+			"tenant", c.Param("tenant"),
+		)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+	respond(c, status, result)
+}
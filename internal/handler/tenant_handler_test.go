@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenantServiceStub заводит организацию только при первом вызове, как повторяемый запрос
+type tenantServiceStub struct {
+	service.TenantService
+	calls int
+}
+
+func (s *tenantServiceStub) Provision(ctx context.Context, tenantID string, req model.ProvisionTenantRequest) (*model.ProvisionTenantResult, error) {
+	s.calls++
+	return &model.ProvisionTenantResult{Tenant: tenantID, Name: req.Name, Created: s.calls == 1}, nil
+}
+
+func TestProvisionTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(slog.LevelError + 4)
+
+	svc := &tenantServiceStub{}
+	router := gin.New()
+	handler.NewTenantHandler(svc, testAdminToken, log).RegisterRoutes(router.Group("/api/v1"))
+
+	send := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/tenants/acme", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	const body = `{"name": "Acme Inc", "tags": ["work"]}`
+	if rec := send("", body); rec.Code != http.StatusUnauthorized || svc.calls != 0 {
+		t.Fatalf("without admin token status = %d, calls = %d, want 401 without provisioning", rec.Code, svc.calls)
+	}
+	if rec := send(testAdminToken, `{"tags": ["work"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("without name status = %d, want 400", rec.Code)
+	}
+	if rec := send(testAdminToken, body); rec.Code != http.StatusCreated {
+		t.Errorf("first call status = %d, want 201, body: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(testAdminToken, body); rec.Code != http.StatusOK {
+		t.Errorf("repeated call status = %d, want 200, body: %s", rec.Code, rec.Body.String())
+	}
+}
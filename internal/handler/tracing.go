@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing начинает спан запроса, продолжая трассу из заголовка traceparent, и возвращает
+// traceparent спана в ответе, чтобы клиент мог найти трассу своего запроса
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if parent, err := tracing.ParseTraceparent(c.GetHeader("traceparent")); err == nil {
+			ctx = tracing.WithRemoteParent(ctx, parent)
+		}
+
+		// Шаблон маршрута вместо пути, чтобы запросы к разным подпискам попадали в один спан
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+		ctx, span := tracing.Start(ctx, name, tracing.KindServer,
+			tracing.Attribute{Key: "http.request.method", Value: c.Request.Method},
+			tracing.Attribute{Key: "http.route", Value: route},
+			tracing.Attribute{Key: "url.path", Value: c.Request.URL.Path},
+		)
+		if span == nil {
+			c.Next()
+			return
+		}
+		defer span.End()
+
+		c.Header("traceparent", span.Context.Traceparent())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(tracing.Attribute{Key: "http.response.status_code", Value: status})
+		if status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("%d %s", status, http.StatusText(status)))
+		}
+	}
+}
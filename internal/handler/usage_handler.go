@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader - заголовок, которым интеграторы представляются API
+const apiKeyHeader = "X-API-Key"
+
+const defaultUsageDays = 7
+
+type UsageHandler struct {
+	store   *usage.Store
+	limiter *usage.Limiter
+	logger  *logger.Logger
+}
+
+func NewUsageHandler(store *usage.Store, limiter *usage.Limiter, logger *logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		store:   store,
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes регистрирует маршруты статистики использования в группе API
+func (h *UsageHandler) RegisterRoutes(api gin.IRouter) {
+	api.GET("/me/usage", h.GetUsage)
+}
+
+// Middleware считает запросы клиентов с X-API-Key и применяет лимит запросов в минуту.
+// Запросы без ключа не учитываются
+func (h *UsageHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader(apiKeyHeader)
+		if apiKey == "" {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		limit, allowed := h.limiter.Allow(apiKey, now)
+		if limit.LimitPerMinute > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(limit.LimitPerMinute))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(limit.ResetAt.Unix(), 10))
+		}
+		if !allowed {
+			h.logger.Warn(c.Request.Context(), "Rate limit exceeded",
+				"api_key", maskAPIKey(apiKey),
+				"path", c.FullPath(),
+			)
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(limit.ResetAt).Seconds())+1))
+			abortProblem(c, http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded")
+			return
+		}
+
+		h.store.Increment(apiKey, c.Request.Method+" "+c.FullPath(), now)
+		c.Next()
+	}
+}
+
+// GetUsage возвращает статистику запросов текущего клиента
+// @Summary Статистика использования API
+// @Description Возвращает число запросов клиента по эндпоинтам за последние дни (UTC) и потребление лимита в текущей минуте. Клиент определяется заголовком X-API-Key; ключ не проверяется, поэтому статистика справочная
+// @Tags usage
+// @Produce json
+// @Param X-API-Key header string true "Ключ клиента"
+// @Param days query int false "За сколько дней вернуть статистику, включая текущий (по умолчанию 7)"
+// @Success 200 {object} model.UsageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/usage [get]
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	apiKey := c.GetHeader(apiKeyHeader)
+	if apiKey == "" {
+		respondProblem(c, http.StatusUnauthorized, CodeUnauthorized, "X-API-Key header is required")
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultUsageDays)))
+	if err != nil || days < 1 || days > h.store.Retention() {
+		h.logger.Warn(c.Request.Context(), "Invalid days parameter",
+			"days", c.Query("days"),
+		)
+		respondProblem(c, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("days must be between 1 and %d", h.store.Retention()))
+		return
+	}
+
+	now := time.Now()
+	respond(c, http.StatusOK, model.UsageResponse{
+		APIKey:    maskAPIKey(apiKey),
+		Days:      h.store.Usage(apiKey, days, now),
+		RateLimit: h.limiter.Usage(apiKey, now),
+	})
+}
+
+// maskAPIKey оставляет только последние символы ключа для логов и ответов
+func maskAPIKey(apiKey string) string {
+	const visible = 4
+	if len(apiKey) <= visible*2 {
+		return "****"
+	}
+	return apiKey[:visible] + "****" + apiKey[len(apiKey)-visible:]
+}
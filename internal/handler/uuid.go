@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const uuidVersionsKey = "uuid_versions"
+
+// UUIDValidation задает допустимые версии UUID для разбора идентификаторов в запросе.
+// Пустой список разрешает UUID любой версии
+func UUIDValidation(versions []int) gin.HandlerFunc {
+	allowed := make(map[uuid.Version]struct{}, len(versions))
+	for _, v := range versions {
+		allowed[uuid.Version(v)] = struct{}{}
+	}
+	return func(c *gin.Context) {
+		if len(allowed) > 0 {
+			c.Set(uuidVersionsKey, allowed)
+		}
+		c.Next()
+	}
+}
+
+// parseUUID разбирает UUID из пути или параметра запроса. Принимаются запись с дефисами
+// и без них, в любом регистре, в фигурных скобках и с префиксом urn:uuid:; результат
+// всегда выводится в канонической форме. Версия проверяется, если она ограничена UUIDValidation
+func parseUUID(c *gin.Context, raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := checkUUIDVersion(c, id); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func checkUUIDVersion(c *gin.Context, id uuid.UUID) error {
+	value, ok := c.Get(uuidVersionsKey)
+	if !ok {
+		return nil
+	}
+	if _, ok := value.(map[uuid.Version]struct{})[id.Version()]; !ok {
+		return fmt.Errorf("unsupported UUID version %d", id.Version())
+	}
+	return nil
+}
+
+var (
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	uuidPtrType = reflect.TypeOf(&uuid.UUID{})
+)
+
+// checkBodyUUIDs проверяет версии UUID в полях верхнего уровня разобранного тела запроса.
+// Формат таких полей уже нормализован при декодировании
+func checkBodyUUIDs(c *gin.Context, obj interface{}) error {
+	if _, ok := c.Get(uuidVersionsKey); !ok {
+		return nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		var id uuid.UUID
+		switch field.Type() {
+		case uuidType:
+			id = field.Interface().(uuid.UUID)
+		case uuidPtrType:
+			if field.IsNil() {
+				continue
+			}
+			id = *field.Interface().(*uuid.UUID)
+		default:
+			continue
+		}
+		if err := checkUUIDVersion(c, id); err != nil {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			return &requestError{
+				code:   CodeValidationFailed,
+				err:    fmt.Errorf("%s: %w", name, err),
+				fields: []FieldError{{Field: name, Value: id.String(), Reason: err.Error()}},
+			}
+		}
+	}
+	return nil
+}
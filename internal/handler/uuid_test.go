@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// v1UUID - UUID версии 1 (на основе времени)
+const v1UUID = "c232ab00-9414-11ec-b3c8-9f6bdeced846"
+
+func newUUIDTestRouter(svc *mockService, versions []int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1", handler.ContentNegotiation(false), handler.UUIDValidation(versions))
+	handler.NewSubscriptionHandler(svc, "", logger.New(slog.LevelError+4)).RegisterRoutes(api)
+	return router
+}
+
+func TestUUIDParsing(t *testing.T) {
+	want := fixtureSubscription().ID
+
+	svc := &mockService{
+		getFn: func(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+			if id != want {
+				t.Errorf("id = %s, want %s", id, want)
+			}
+			return fixtureSubscription(), nil
+		},
+		listFn: func(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+			return &model.SubscriptionPage{}, nil
+		},
+		updateFn: func(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		versions   []int
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "uppercase without hyphens",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/" + strings.ToUpper(strings.ReplaceAll(want.String(), "-", "")),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "braces",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions/%7B" + want.String() + "%7D",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "any version by default",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?user_id=" + v1UUID,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid user_id filter is rejected",
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?user_id=not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "version not allowed in query",
+			versions:   []int{4},
+			method:     http.MethodGet,
+			path:       "/api/v1/subscriptions?user_id=" + v1UUID,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "version not allowed in body",
+			versions:   []int{4},
+			method:     http.MethodPut,
+			path:       subscriptionPath,
+			body:       `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"` + v1UUID + `","start_date":"07-2025"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "allowed version in body",
+			versions:   []int{1, 4},
+			method:     http.MethodPut,
+			path:       subscriptionPath,
+			body:       `{"service_name":"Yandex Plus","monthly_cost":400,"user_id":"` + v1UUID + `","start_date":"07-2025"}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+
+			newUUIDTestRouter(svc, tt.versions).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
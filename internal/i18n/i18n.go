@@ -0,0 +1,86 @@
+// Package i18n локализует подписи в отчетах для людей (выгрузки счетов и т.п.).
+// JSON-ответы остаются машинным форматом и не локализуются
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Locale string
+
+const (
+	RU Locale = "ru"
+	EN Locale = "en"
+
+	// Default - язык отчетов, если ни клиент, ни конфигурация его не задали
+	Default = RU
+)
+
+var monthNames = map[Locale][12]string{
+	RU: {"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь", "Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+	EN: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+var totalLabels = map[Locale]string{
+	RU: "Итого",
+	EN: "Total",
+}
+
+// Parse проверяет код языка: ru или en, регистр и регион (en-US) не учитываются
+func Parse(code string) (Locale, error) {
+	locale := Locale(strings.ToLower(strings.TrimSpace(primaryTag(code))))
+	if _, ok := monthNames[locale]; !ok {
+		return "", fmt.Errorf("unsupported locale %q: expected ru or en", code)
+	}
+	return locale, nil
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учетом весов q.
+// Если ни один язык заголовка не поддерживается, возвращает fallback
+func Negotiate(header string, fallback Locale) Locale {
+	best, bestWeight := fallback, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+
+		locale, err := Parse(tag)
+		if err != nil || weight <= bestWeight {
+			continue
+		}
+		best, bestWeight = locale, weight
+	}
+	return best
+}
+
+func primaryTag(tag string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	return primary
+}
+
+// MonthYear возвращает подпись месяца: "Январь 2025" или "January 2025"
+func MonthYear(t time.Time, locale Locale) string {
+	names, ok := monthNames[locale]
+	if !ok {
+		names = monthNames[Default]
+	}
+	return fmt.Sprintf("%s %d", names[t.Month()-1], t.Year())
+}
+
+// Total возвращает подпись итоговой строки отчета
+func Total(locale Locale) string {
+	if label, ok := totalLabels[locale]; ok {
+		return label
+	}
+	return totalLabels[Default]
+}
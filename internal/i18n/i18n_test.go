@@ -0,0 +1,48 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthYear(t *testing.T) {
+	month := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if got := MonthYear(month, RU); got != "Январь 2025" {
+		t.Errorf("MonthYear(ru) = %q, want %q", got, "Январь 2025")
+	}
+	if got := MonthYear(month, EN); got != "January 2025" {
+		t.Errorf("MonthYear(en) = %q, want %q", got, "January 2025")
+	}
+	if got := MonthYear(month.AddDate(0, 11, 0), "de"); got != "Декабрь 2025" {
+		t.Errorf("MonthYear(de) = %q, want fallback to %s", got, Default)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{"", RU},
+		{"en-US,en;q=0.9", EN},
+		{"de-DE, en;q=0.8, ru;q=0.9", RU},
+		{"fr, en;q=0.5", EN},
+		{"fr, de", RU},
+		{"en;q=abc", RU},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header, RU); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if locale, err := Parse("EN_gb"); err != nil || locale != EN {
+		t.Errorf("Parse(EN_gb) = %q, %v", locale, err)
+	}
+	if _, err := Parse("de"); err == nil {
+		t.Error("Parse(de) error = nil, want unsupported locale")
+	}
+}
@@ -0,0 +1,85 @@
+// Package lifecycle запускает и останавливает модули сервиса (база, шина событий,
+// фоновые задачи, HTTP) в порядке их сборки. Модуль регистрирует хуки при создании,
+// поэтому новая подсистема добавляется своим модулем, а не правкой общей функции запуска.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook - действия модуля при запуске и остановке сервиса; любое из них может быть nil
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle хранит хуки модулей. Запуск идет в порядке регистрации,
+// остановка - в обратном, чтобы модуль останавливался раньше своих зависимостей
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	stopped bool
+}
+
+// New возвращает пустой Lifecycle
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Append регистрирует хук модуля
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// Start выполняет OnStart всех еще не запущенных хуков. При ошибке уже запущенные
+// модули останавливаются, а ошибка возвращается с именем модуля
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		hook := l.hooks[l.started]
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				return errors.Join(fmt.Errorf("failed to start %s: %w", hook.Name, err), l.stop(ctx))
+			}
+		}
+		l.started++
+	}
+	return nil
+}
+
+// Stop выполняет OnStop в обратном порядке для всех зарегистрированных хуков, даже если
+// Start не вызывался: модуль, собранный до ошибки сборки, все равно освобождает ресурсы.
+// Ошибки модулей объединяются; повторный вызов ничего не делает
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stop(ctx)
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	if l.stopped {
+		return nil
+	}
+	l.stopped = true
+
+	var errs []error
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		hook := l.hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		if err := hook.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
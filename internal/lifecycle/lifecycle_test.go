@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLifecycleOrder(t *testing.T) {
+	var calls []string
+	lc := New()
+	for _, name := range []string{"db", "bus", "http"} {
+		lc.Append(Hook{
+			Name:    name,
+			OnStart: func(context.Context) error { calls = append(calls, "start "+name); return nil },
+			OnStop:  func(context.Context) error { calls = append(calls, "stop "+name); return nil },
+		})
+	}
+
+	if err := lc.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := lc.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	// Повторная остановка ничего не делает
+	if err := lc.Stop(context.Background()); err != nil {
+		t.Fatalf("second Stop: %v", err)
+	}
+
+	want := []string{"start db", "start bus", "start http", "stop http", "stop bus", "stop db"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestLifecycleStartFailureStopsModules(t *testing.T) {
+	var stopped []string
+	lc := New()
+	lc.Append(Hook{
+		Name:   "db",
+		OnStop: func(context.Context) error { stopped = append(stopped, "db"); return nil },
+	})
+	lc.Append(Hook{
+		Name:    "jobs",
+		OnStart: func(context.Context) error { return errors.New("invalid schedule") },
+		OnStop:  func(context.Context) error { stopped = append(stopped, "jobs"); return nil },
+	})
+
+	err := lc.Start(context.Background())
+	if err == nil || err.Error() != "failed to start jobs: invalid schedule" {
+		t.Fatalf("Start error = %v", err)
+	}
+	if want := []string{"jobs", "db"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+}
+
+func TestLifecycleStopJoinsErrors(t *testing.T) {
+	errPool := errors.New("pool busy")
+	lc := New()
+	lc.Append(Hook{Name: "db", OnStop: func(context.Context) error { return errPool }})
+	lc.Append(Hook{Name: "http"})
+
+	err := lc.Stop(context.Background())
+	if !errors.Is(err, errPool) {
+		t.Fatalf("Stop error = %v, want %v", err, errPool)
+	}
+}
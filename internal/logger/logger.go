@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"os"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
 )
 
 type Logger struct {
@@ -16,8 +18,50 @@ func New(level slog.Level) *Logger {
 	})
 
 	return &Logger{
-		Logger: slog.New(handler),
+		Logger: slog.New(contextHandler{handler}),
+	}
+}
+
+// contextHandler добавляет к записям идентификаторы запроса, организации и пользователя
+// из контекста, чтобы записи одного запроса можно было найти без явной передачи полей.
+// Поле, переданное в записи явно (например, user_id владельца подписки), не заменяется
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+	attrs := ctxutil.LogAttrs(ctx)
+	if len(attrs) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	explicit := make(map[string]bool, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		explicit[attr.Key] = true
+		return true
+	})
+	for _, attr := range attrs {
+		if !explicit[attr.Key] {
+			r.AddAttrs(attr)
+		}
 	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// With возвращает логгер, добавляющий args ко всем записям
+func (l *Logger) With(args ...interface{}) *Logger {
+	return &Logger{Logger: l.Logger.With(args...)}
 }
 
 // Методы с контекстом
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// CoalescedRequestsMetric - счетчик запросов, получивших результат одновременного
+// запроса с тем же ключом вместо собственного обращения к базе
+const CoalescedRequestsMetric = Namespace + "_coalesced_requests_total"
+
+// Coalesced - счетчики объединенных запросов по операциям. Нулевой указатель допустим
+// и ничего не учитывает
+type Coalesced struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewCoalesced() *Coalesced {
+	return &Coalesced{counts: make(map[string]int64)}
+}
+
+// Inc учитывает запрос операции operation, объединенный с уже выполняющимся
+func (c *Coalesced) Inc(operation string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.counts[operation]++
+	c.mu.Unlock()
+}
+
+// Count возвращает число объединенных запросов операции
+func (c *Coalesced) Count(operation string) int64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[operation]
+}
+
+// WritePrometheus пишет счетчики в текстовом формате Prometheus
+func (c *Coalesced) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var operations []string
+	counts := map[string]int64{}
+	if c != nil {
+		c.mu.Lock()
+		for operation, count := range c.counts {
+			operations = append(operations, operation)
+			counts[operation] = count
+		}
+		c.mu.Unlock()
+	}
+	sort.Strings(operations)
+
+	fmt.Fprintf(bw, "# HELP %s Requests served by a concurrent identical query instead of their own.\n", CoalescedRequestsMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", CoalescedRequestsMetric)
+	for _, operation := range operations {
+		fmt.Fprintf(bw, "%s{operation=%q} %d\n", CoalescedRequestsMetric, operation, counts[operation])
+	}
+
+	return bw.Flush()
+}
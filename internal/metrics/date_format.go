@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// DateFormatResponsesMetric - счетчик ответов API по формату месяцев, организациям и клиентам.
+// Показывает, кто еще получает прежний формат MM-YYYY
+const DateFormatResponsesMetric = Namespace + "_date_format_responses_total"
+
+type dateFormatKey struct {
+	format string
+	tenant string
+	client string
+}
+
+// DateFormats - счетчики ответов по формату месяцев. Нулевой указатель допустим
+// и ничего не учитывает
+type DateFormats struct {
+	mu     sync.Mutex
+	counts map[dateFormatKey]int64
+}
+
+func NewDateFormats() *DateFormats {
+	return &DateFormats{counts: make(map[dateFormatKey]int64)}
+}
+
+// Inc учитывает ответ в формате format организации tenant клиенту client
+// (замаскированный X-API-Key; пустой - клиент без ключа)
+func (d *DateFormats) Inc(format, tenant, client string) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	d.counts[dateFormatKey{format: format, tenant: tenant, client: client}]++
+	d.mu.Unlock()
+}
+
+// WritePrometheus пишет счетчики в текстовом формате Prometheus
+func (d *DateFormats) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var keys []dateFormatKey
+	counts := map[dateFormatKey]int64{}
+	if d != nil {
+		d.mu.Lock()
+		for key, count := range d.counts {
+			keys = append(keys, key)
+			counts[key] = count
+		}
+		d.mu.Unlock()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].format != keys[j].format {
+			return keys[i].format < keys[j].format
+		}
+		if keys[i].tenant != keys[j].tenant {
+			return keys[i].tenant < keys[j].tenant
+		}
+		return keys[i].client < keys[j].client
+	})
+
+	fmt.Fprintf(bw, "# HELP %s API responses by month format, tenant and client.\n", DateFormatResponsesMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", DateFormatResponsesMetric)
+	for _, key := range keys {
+		fmt.Fprintf(bw, "%s{format=%q,tenant=%q,client=%q} %d\n", DateFormatResponsesMetric, key.format, key.tenant, key.client, counts[key])
+	}
+
+	return bw.Flush()
+}
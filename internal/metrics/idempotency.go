@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// IdempotencyRequestsMetric - счетчик запросов с Idempotency-Key по результату проверки ключа
+const IdempotencyRequestsMetric = Namespace + "_idempotency_requests_total"
+
+// Результаты проверки ключа идемпотентности. Доля попаданий - hit / (hit + miss)
+const (
+	// IdempotencyMiss - ключ новый, запрос выполнен
+	IdempotencyMiss = "miss"
+	// IdempotencyHit - повтор получил сохраненный ответ
+	IdempotencyHit = "hit"
+	// IdempotencyInProgress - повтор пришел, пока запрос с ключом выполняется
+	IdempotencyInProgress = "in_progress"
+	// IdempotencyMismatch - ключ повторно использован с другим запросом
+	IdempotencyMismatch = "mismatch"
+)
+
+// Idempotency - счетчики запросов с ключом идемпотентности. Нулевой указатель допустим
+// и ничего не учитывает
+type Idempotency struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewIdempotency() *Idempotency {
+	return &Idempotency{counts: make(map[string]int64)}
+}
+
+// Inc учитывает запрос с результатом проверки ключа outcome
+func (m *Idempotency) Inc(outcome string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.counts[outcome]++
+	m.mu.Unlock()
+}
+
+// Count возвращает число запросов с результатом outcome
+func (m *Idempotency) Count(outcome string) int64 {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[outcome]
+}
+
+// WritePrometheus пишет счетчики в текстовом формате Prometheus
+func (m *Idempotency) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var outcomes []string
+	counts := map[string]int64{}
+	if m != nil {
+		m.mu.Lock()
+		for outcome, count := range m.counts {
+			outcomes = append(outcomes, outcome)
+			counts[outcome] = count
+		}
+		m.mu.Unlock()
+	}
+	sort.Strings(outcomes)
+
+	fmt.Fprintf(bw, "# HELP %s Requests with an Idempotency-Key by key check outcome.\n", IdempotencyRequestsMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", IdempotencyRequestsMetric)
+	for _, outcome := range outcomes {
+		fmt.Fprintf(bw, "%s{outcome=%q} %d\n", IdempotencyRequestsMetric, outcome, counts[outcome])
+	}
+
+	return bw.Flush()
+}
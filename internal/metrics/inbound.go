@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// InboundEventsMetric - счетчик входящих событий по источнику и результату приема
+const InboundEventsMetric = Namespace + "_inbound_events_total"
+
+// Результаты приема входящего события. Доля повторов - duplicate / (processed + duplicate)
+const (
+	// InboundProcessed - событие применено
+	InboundProcessed = "processed"
+	// InboundDuplicate - повтор обработанного события отброшен
+	InboundDuplicate = "duplicate"
+	// InboundInProgress - повтор пришел, пока событие обрабатывается
+	InboundInProgress = "in_progress"
+	// InboundFailed - событие не применено и будет принято повторно
+	InboundFailed = "failed"
+)
+
+type inboundKey struct {
+	source  string
+	outcome string
+}
+
+// InboundEvents - счетчики входящих событий. Нулевой указатель допустим и ничего не учитывает
+type InboundEvents struct {
+	mu     sync.Mutex
+	counts map[inboundKey]int64
+}
+
+func NewInboundEvents() *InboundEvents {
+	return &InboundEvents{counts: make(map[inboundKey]int64)}
+}
+
+// Inc учитывает событие источника source с результатом outcome
+func (m *InboundEvents) Inc(source, outcome string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.counts[inboundKey{source, outcome}]++
+	m.mu.Unlock()
+}
+
+// Count возвращает число событий источника source с результатом outcome
+func (m *InboundEvents) Count(source, outcome string) int64 {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[inboundKey{source, outcome}]
+}
+
+// WritePrometheus пишет счетчики в текстовом формате Prometheus
+func (m *InboundEvents) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var keys []inboundKey
+	counts := map[inboundKey]int64{}
+	if m != nil {
+		m.mu.Lock()
+		for key, count := range m.counts {
+			keys = append(keys, key)
+			counts[key] = count
+		}
+		m.mu.Unlock()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+
+	fmt.Fprintf(bw, "# HELP %s Inbound integration events by source and outcome.\n", InboundEventsMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", InboundEventsMetric)
+	for _, key := range keys {
+		fmt.Fprintf(bw, "%s{source=%q,outcome=%q} %d\n", InboundEventsMetric, key.source, key.outcome, counts[key])
+	}
+
+	return bw.Flush()
+}
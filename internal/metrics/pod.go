@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// PodInfoMetric - информационная метрика со значением 1 и метками pod, namespace и node.
+// Метки не добавляются к остальным метрикам, их присоединяют в запросе:
+// ... * on (instance) group_left(pod, node) subscription_service_pod_info
+const PodInfoMetric = Namespace + "_pod_info"
+
+// PodInfo пишет PodInfoMetric для пода экземпляра
+type PodInfo struct {
+	pod model.PodMetadata
+}
+
+func NewPodInfo(pod model.PodMetadata) *PodInfo {
+	return &PodInfo{pod: pod}
+}
+
+// WritePrometheus пишет метрику в текстовом формате Prometheus; вне Kubernetes
+// выводятся только HELP и TYPE
+func (p *PodInfo) WritePrometheus(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s Kubernetes pod running this instance.\n# TYPE %s gauge\n", PodInfoMetric, PodInfoMetric); err != nil {
+		return err
+	}
+	if p.pod.IsZero() {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%s{pod=%q,namespace=%q,node=%q} 1\n", PodInfoMetric, p.pod.Name, p.pod.Namespace, p.pod.Node)
+	return err
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Имена метрик в формате Prometheus. Их же используют правила из WriteRules,
+// поэтому переименование метрики меняет и сгенерированные правила
+const (
+	Namespace = "subscription_service"
+
+	// QueryRowsMetric - гистограмма числа строк, возвращенных запросом репозитория
+	QueryRowsMetric = Namespace + "_db_query_rows"
+	// QueryDurationMetric - гистограмма времени выполнения запроса репозитория
+	QueryDurationMetric = Namespace + "_db_query_duration_seconds"
+
+	// QueryLabel - метка с именем запроса (subscriptions.list и т.п.)
+	QueryLabel = "query"
+
+	// WebhookQueueDepthMetric - число событий в очереди адреса подписчика
+	WebhookQueueDepthMetric = Namespace + "_webhook_queue_depth"
+	// WebhookDeliveriesMetric - счетчик событий адреса по результату (delivered, failed, dropped)
+	WebhookDeliveriesMetric = Namespace + "_webhook_deliveries_total"
+	// WebhookCircuitOpenMetric - 1, если выключатель адреса разомкнут
+	WebhookCircuitOpenMetric = Namespace + "_webhook_circuit_open"
+
+	// SchemaDriftMetric - число ожидаемых таблиц, колонок и индексов, которых нет в базе (метка kind)
+	SchemaDriftMetric = Namespace + "_schema_drift_objects"
+)
+
+// WritePrometheus пишет гистограммы запросов в текстовом формате Prometheus
+func (q *Queries) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var names []string
+	stats := map[string]*queryStats{}
+	if q != nil {
+		q.mu.Lock()
+		for name, s := range q.stats {
+			names = append(names, name)
+			// Копируем счетчики, чтобы не держать блокировку во время записи
+			stats[name] = &queryStats{count: s.count, rows: s.rows.clone(), duration: s.duration.clone()}
+		}
+		q.mu.Unlock()
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(bw, "# HELP %s Rows returned by repository queries.\n", QueryRowsMetric)
+	fmt.Fprintf(bw, "# TYPE %s histogram\n", QueryRowsMetric)
+	for _, name := range names {
+		writeHistogram(bw, QueryRowsMetric, name, stats[name].rows, 1)
+	}
+
+	fmt.Fprintf(bw, "# HELP %s Repository query latency.\n", QueryDurationMetric)
+	fmt.Fprintf(bw, "# TYPE %s histogram\n", QueryDurationMetric)
+	for _, name := range names {
+		// Время хранится в миллисекундах, Prometheus ожидает секунды
+		writeHistogram(bw, QueryDurationMetric, name, stats[name].duration, 1000)
+	}
+
+	return bw.Flush()
+}
+
+func (h *histogram) clone() *histogram {
+	c := *h
+	c.counts = append([]int64(nil), h.counts...)
+	return &c
+}
+
+// writeHistogram пишет корзины, сумму и количество; значения делятся на divisor
+func writeHistogram(w io.Writer, metric, query string, h *histogram, divisor float64) {
+	// Имена запросов - константы репозитория из ASCII, поэтому экранирования %q достаточно
+	label := fmt.Sprintf("%s=%q", QueryLabel, query)
+
+	var cumulative int64
+	for i, count := range h.counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = formatFloat(float64(h.bounds[i]) / divisor)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=%q} %d\n", metric, label, le, cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", metric, label, formatFloat(float64(h.sum)/divisor))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", metric, label, cumulative)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	queries := NewQueries()
+	queries.Observe("subscriptions.list", 100, 20*time.Millisecond)
+	queries.Observe("subscriptions.list", 7000, 3*time.Second)
+
+	var out strings.Builder
+	if err := queries.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+
+	for _, line := range []string{
+		"# TYPE subscription_service_db_query_rows histogram",
+		`subscription_service_db_query_rows_bucket{query="subscriptions.list",le="100"} 1`,
+		`subscription_service_db_query_rows_bucket{query="subscriptions.list",le="+Inf"} 2`,
+		`subscription_service_db_query_rows_sum{query="subscriptions.list"} 7100`,
+		`subscription_service_db_query_duration_seconds_bucket{query="subscriptions.list",le="0.05"} 1`,
+		`subscription_service_db_query_duration_seconds_sum{query="subscriptions.list"} 3.02`,
+		`subscription_service_db_query_duration_seconds_count{query="subscriptions.list"} 2`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestWriteRules(t *testing.T) {
+	cfg := RuleConfig{Window: 5 * time.Minute, For: 10 * time.Minute, QueryLatencyP95: 500 * time.Millisecond, QueryRowsP95: 1000}
+
+	var out strings.Builder
+	if err := WriteRules(&out, cfg); err != nil {
+		t.Fatalf("WriteRules: %v", err)
+	}
+	for _, want := range []string{
+		QueryDurationMetric + "_bucket[5m]",
+		QueryRowsMetric + "_bucket[5m]",
+		"subscription_service:db_query_duration_seconds:p95_5m > 0.5",
+		"subscription_service:db_query_rows:p95_5m > 1000",
+		"sum(subscription_service_schema_drift_objects) > 0",
+		"for: 10m",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("rules do not contain %q", want)
+		}
+	}
+
+	// Порог выше последней конечной корзины никогда не сработает
+	cfg.QueryRowsP95 = 10000
+	if err := WriteRules(&out, cfg); err == nil {
+		t.Error("expected error for rows threshold above the largest bucket")
+	}
+}
+
+func TestRejectionsWritePrometheus(t *testing.T) {
+	rejections := NewRejections()
+	rejections.Inc("validation", "/api/v1/subscriptions")
+	rejections.Inc("validation", "/api/v1/subscriptions")
+	rejections.Inc("forbidden", "/api/v1/subscriptions/:id")
+
+	var out strings.Builder
+	if err := rejections.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+
+	want := "# HELP subscription_service_rejected_requests_total Write requests rejected with a 4xx status.\n" +
+		"# TYPE subscription_service_rejected_requests_total counter\n" +
+		`subscription_service_rejected_requests_total{reason="forbidden",route="/api/v1/subscriptions/:id"} 1` + "\n" +
+		`subscription_service_rejected_requests_total{reason="validation",route="/api/v1/subscriptions"} 2` + "\n"
+	if out.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	var empty strings.Builder
+	if err := (*Rejections)(nil).WritePrometheus(&empty); err != nil {
+		t.Fatalf("WritePrometheus on nil: %v", err)
+	}
+}
+
+func TestCoalescedWritePrometheus(t *testing.T) {
+	coalesced := NewCoalesced()
+	coalesced.Inc("subscriptions.get_by_id")
+	coalesced.Inc("subscriptions.get_by_id")
+
+	var out strings.Builder
+	if err := coalesced.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	if want := `subscription_service_coalesced_requests_total{operation="subscriptions.get_by_id"} 2` + "\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("WritePrometheus() =\n%s\nmissing %q", out.String(), want)
+	}
+}
+
+func TestPodInfoWritePrometheus(t *testing.T) {
+	var out strings.Builder
+	if err := NewPodInfo(model.PodMetadata{Name: "api-0", Namespace: "billing", Node: "worker-3"}).WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	if want := `subscription_service_pod_info{pod="api-0",namespace="billing",node="worker-3"} 1` + "\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("WritePrometheus() =\n%s\nwant suffix %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := NewPodInfo(model.PodMetadata{}).WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	if strings.Contains(out.String(), "} 1") {
+		t.Errorf("WritePrometheus() outside Kubernetes = %q, want no samples", out.String())
+	}
+}
@@ -0,0 +1,117 @@
+// Package metrics собирает гистограммы размеров результатов и времени запросов к БД.
+// Значения хранятся в памяти процесса и сбрасываются при перезапуске.
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+var (
+	// rowBuckets - верхние границы корзин числа строк результата
+	rowBuckets = []int64{0, 1, 10, 50, 100, 500, 1000, 5000}
+	// durationBuckets - верхние границы корзин времени запроса, мс
+	durationBuckets = []int64{1, 5, 10, 50, 100, 500, 1000, 5000}
+)
+
+// histogram - гистограмма с фиксированными границами; последняя корзина - переполнение
+type histogram struct {
+	bounds []int64
+	counts []int64
+	sum    int64
+	max    int64
+}
+
+func newHistogram(bounds []int64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(value int64) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return value <= h.bounds[i] })
+	h.counts[i]++
+	h.sum += value
+	if value > h.max {
+		h.max = value
+	}
+}
+
+// snapshot возвращает накопительные счетчики корзин, как в формате Prometheus
+func (h *histogram) snapshot() model.Histogram {
+	snap := model.Histogram{Sum: h.sum, Max: h.max, Buckets: make([]model.HistogramBucket, 0, len(h.counts))}
+	var cumulative int64
+	for i, count := range h.counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatInt(h.bounds[i], 10)
+		}
+		snap.Buckets = append(snap.Buckets, model.HistogramBucket{Le: le, Count: cumulative})
+	}
+	return snap
+}
+
+type queryStats struct {
+	count    int64
+	rows     *histogram
+	duration *histogram
+}
+
+// Queries - гистограммы по именованным запросам. Нулевой указатель допустим и ничего не учитывает
+type Queries struct {
+	mu    sync.Mutex
+	stats map[string]*queryStats
+}
+
+func NewQueries() *Queries {
+	return &Queries{stats: make(map[string]*queryStats)}
+}
+
+// Observe учитывает выполнение запроса name, вернувшего rows строк за duration
+func (q *Queries) Observe(name string, rows int, duration time.Duration) {
+	if q == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats, ok := q.stats[name]
+	if !ok {
+		stats = &queryStats{rows: newHistogram(rowBuckets), duration: newHistogram(durationBuckets)}
+		q.stats[name] = stats
+	}
+	stats.count++
+	stats.rows.observe(int64(rows))
+	stats.duration.observe(duration.Milliseconds())
+}
+
+// Snapshot возвращает статистику запросов, начиная с возвращающих больше всего строк
+func (q *Queries) Snapshot() []model.QueryStats {
+	if q == nil {
+		return []model.QueryStats{}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]model.QueryStats, 0, len(q.stats))
+	for name, stats := range q.stats {
+		result = append(result, model.QueryStats{
+			Query:      name,
+			Count:      stats.count,
+			Rows:       stats.rows.snapshot(),
+			DurationMs: stats.duration.snapshot(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rows.Sum != result[j].Rows.Sum {
+			return result[i].Rows.Sum > result[j].Rows.Sum
+		}
+		return result[i].Query < result[j].Query
+	})
+	return result
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueriesSnapshot(t *testing.T) {
+	queries := NewQueries()
+	queries.Observe("subscriptions.list", 0, time.Millisecond)
+	queries.Observe("subscriptions.list", 100, 20*time.Millisecond)
+	queries.Observe("subscriptions.list", 7000, 3*time.Second)
+	queries.Observe("invoices.list_by_user", 3, 2*time.Millisecond)
+
+	snapshot := queries.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Query != "subscriptions.list" {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	list := snapshot[0]
+	if list.Count != 3 || list.Rows.Sum != 7100 || list.Rows.Max != 7000 {
+		t.Errorf("unexpected rows stats: %+v", list)
+	}
+
+	// Корзины накопительные, последняя (+Inf) содержит все наблюдения
+	wantRows := map[string]int64{"0": 1, "1": 1, "10": 1, "50": 1, "100": 2, "5000": 2, "+Inf": 3}
+	for _, bucket := range list.Rows.Buckets {
+		if want, ok := wantRows[bucket.Le]; ok && bucket.Count != want {
+			t.Errorf("rows bucket le=%s: got %d, want %d", bucket.Le, bucket.Count, want)
+		}
+	}
+	wantDuration := map[string]int64{"1": 1, "10": 1, "50": 2, "1000": 2, "5000": 3}
+	for _, bucket := range list.DurationMs.Buckets {
+		if want, ok := wantDuration[bucket.Le]; ok && bucket.Count != want {
+			t.Errorf("duration bucket le=%s: got %d, want %d", bucket.Le, bucket.Count, want)
+		}
+	}
+}
+
+func TestNilQueries(t *testing.T) {
+	var queries *Queries
+	queries.Observe("subscriptions.list", 1, time.Millisecond)
+	if snapshot := queries.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("nil collector must not record queries: %+v", snapshot)
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// RejectedRequestsMetric - счетчик отклоненных запросов на запись по причинам и маршрутам
+const RejectedRequestsMetric = Namespace + "_rejected_requests_total"
+
+type rejectionKey struct {
+	reason string
+	route  string
+}
+
+// Rejections - счетчики отклоненных запросов. Нулевой указатель допустим и ничего не учитывает
+type Rejections struct {
+	mu     sync.Mutex
+	counts map[rejectionKey]int64
+}
+
+func NewRejections() *Rejections {
+	return &Rejections{counts: make(map[rejectionKey]int64)}
+}
+
+// Inc учитывает запрос к маршруту route, отклоненный по причине reason
+func (r *Rejections) Inc(reason, route string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.counts[rejectionKey{reason: reason, route: route}]++
+	r.mu.Unlock()
+}
+
+// WritePrometheus пишет счетчики в текстовом формате Prometheus
+func (r *Rejections) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var keys []rejectionKey
+	counts := map[rejectionKey]int64{}
+	if r != nil {
+		r.mu.Lock()
+		for key, count := range r.counts {
+			keys = append(keys, key)
+			counts[key] = count
+		}
+		r.mu.Unlock()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].reason != keys[j].reason {
+			return keys[i].reason < keys[j].reason
+		}
+		return keys[i].route < keys[j].route
+	})
+
+	fmt.Fprintf(bw, "# HELP %s Write requests rejected with a 4xx status.\n", RejectedRequestsMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", RejectedRequestsMetric)
+	for _, key := range keys {
+		// Причины - константы, маршруты - шаблоны роутера, поэтому экранирования %q достаточно
+		fmt.Fprintf(bw, "%s{reason=%q,route=%q} %d\n", RejectedRequestsMetric, key.reason, key.route, counts[key])
+	}
+
+	return bw.Flush()
+}
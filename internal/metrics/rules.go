@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// RuleConfig - окно расчета и пороги алертов для правил Prometheus
+type RuleConfig struct {
+	// Window - окно rate() в записывающих правилах
+	Window time.Duration
+	// For - сколько порог должен нарушаться, прежде чем алерт сработает
+	For time.Duration
+	// QueryLatencyP95 - допустимый 95-й перцентиль времени запроса репозитория
+	QueryLatencyP95 time.Duration
+	// QueryRowsP95 - допустимый 95-й перцентиль числа строк результата
+	QueryRowsP95 int
+}
+
+func (c RuleConfig) validate() error {
+	if c.Window <= 0 || c.For < 0 {
+		return fmt.Errorf("window must be positive and for must not be negative")
+	}
+	// Выше последней корзины histogram_quantile не различает значения
+	maxLatency := time.Duration(durationBuckets[len(durationBuckets)-1]) * time.Millisecond
+	if c.QueryLatencyP95 <= 0 || c.QueryLatencyP95 > maxLatency {
+		return fmt.Errorf("query latency threshold must be in (0, %s]", maxLatency)
+	}
+	maxRows := rowBuckets[len(rowBuckets)-1]
+	if c.QueryRowsP95 <= 0 || int64(c.QueryRowsP95) > maxRows {
+		return fmt.Errorf("query rows threshold must be in (0, %d]", maxRows)
+	}
+	return nil
+}
+
+// Имена записывающих правил по соглашению level:metric:operations
+func recordName(metric, op string, window time.Duration) string {
+	return fmt.Sprintf("%s:%s:%s_%s", Namespace, strings.TrimPrefix(metric, Namespace+"_"), op, promDuration(window))
+}
+
+// WriteRules пишет файл правил Prometheus (записывающие правила и алерты) для метрик,
+// которые сервис отдает на /metrics
+func WriteRules(w io.Writer, cfg RuleConfig) error {
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("invalid rule config: %w", err)
+	}
+
+	window := promDuration(cfg.Window)
+	latencyP95 := recordName(QueryDurationMetric, "p95", cfg.Window)
+	rowsP95 := recordName(QueryRowsMetric, "p95", cfg.Window)
+	rowsAvg := recordName(QueryRowsMetric, "avg", cfg.Window)
+
+	quantile := func(metric string) string {
+		return fmt.Sprintf("histogram_quantile(0.95, sum by (%s, le) (rate(%s_bucket[%s])))", QueryLabel, metric, window)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Сгенерировано cmd/rulesgen из имен метрик internal/metrics, не редактируйте вручную\n")
+	b.WriteString("groups:\n")
+	fmt.Fprintf(&b, "  - name: %s_recording\n", Namespace)
+	b.WriteString("    rules:\n")
+	writeRecord(&b, latencyP95, quantile(QueryDurationMetric))
+	writeRecord(&b, rowsP95, quantile(QueryRowsMetric))
+	writeRecord(&b, rowsAvg, fmt.Sprintf("sum by (%[1]s) (rate(%[2]s_sum[%[3]s])) / sum by (%[1]s) (rate(%[2]s_count[%[3]s]))", QueryLabel, QueryRowsMetric, window))
+
+	fmt.Fprintf(&b, "  - name: %s_alerts\n", Namespace)
+	b.WriteString("    rules:\n")
+	writeAlert(&b, "SubscriptionServiceSlowQueries",
+		fmt.Sprintf("%s > %s", latencyP95, formatFloat(cfg.QueryLatencyP95.Seconds())),
+		cfg.For,
+		fmt.Sprintf("p95 latency of {{ $labels.%s }} is above %s", QueryLabel, cfg.QueryLatencyP95),
+	)
+	writeAlert(&b, "SubscriptionServiceLargeResultSets",
+		fmt.Sprintf("%s > %d", rowsP95, cfg.QueryRowsP95),
+		cfg.For,
+		fmt.Sprintf("p95 result size of {{ $labels.%s }} is above %d rows", QueryLabel, cfg.QueryRowsP95),
+	)
+	writeAlert(&b, "SubscriptionServiceSchemaDrift",
+		fmt.Sprintf("sum(%s) > 0", SchemaDriftMetric),
+		cfg.For,
+		"database schema is missing tables, columns or indexes expected by the service",
+	)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRecord(b *strings.Builder, name, expr string) {
+	fmt.Fprintf(b, "      - record: %s\n", name)
+	fmt.Fprintf(b, "        expr: %s\n", expr)
+}
+
+func writeAlert(b *strings.Builder, name, expr string, forDuration time.Duration, summary string) {
+	fmt.Fprintf(b, "      - alert: %s\n", name)
+	fmt.Fprintf(b, "        expr: %s\n", expr)
+	fmt.Fprintf(b, "        for: %s\n", promDuration(forDuration))
+	b.WriteString("        labels:\n")
+	b.WriteString("          severity: warning\n")
+	b.WriteString("        annotations:\n")
+	fmt.Fprintf(b, "          summary: %q\n", summary)
+}
+
+// promDuration форматирует длительность в синтаксисе Prometheus (5m, 30s, 250ms)
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0 && d != 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+}
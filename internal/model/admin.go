@@ -0,0 +1,191 @@
+package model
+
+import "time"
+
+// PoolSettings - настройки пула соединений с БД. Длительности в формате Go ("5m", "30s"), "0s" - без ограничения
+type PoolSettings struct {
+	MaxOpenConns     int    `json:"max_open_conns" example:"25"`
+	MaxIdleConns     int    `json:"max_idle_conns" example:"25"`
+	ConnMaxLifetime  string `json:"conn_max_lifetime" example:"5m0s"`
+	ConnMaxIdleTime  string `json:"conn_max_idle_time" example:"0s"`
+	StatementTimeout string `json:"statement_timeout" example:"30s"`
+}
+
+// UpdatePoolSettingsRequest - изменяемые настройки пула; незаданные поля остаются прежними
+type UpdatePoolSettingsRequest struct {
+	MaxOpenConns     *int    `json:"max_open_conns,omitempty" example:"50"`
+	MaxIdleConns     *int    `json:"max_idle_conns,omitempty" example:"10"`
+	ConnMaxLifetime  *string `json:"conn_max_lifetime,omitempty" example:"10m"`
+	ConnMaxIdleTime  *string `json:"conn_max_idle_time,omitempty" example:"1m"`
+	StatementTimeout *string `json:"statement_timeout,omitempty" example:"5s"`
+}
+
+// PoolStatus - настройки, статистика и доступность пула соединений
+type PoolStatus struct {
+	Settings          PoolSettings `json:"settings"`
+	Healthy           bool         `json:"healthy" example:"true"`
+	PingError         string       `json:"ping_error,omitempty"`
+	PingMs            int64        `json:"ping_ms" example:"2"`
+	OpenConnections   int          `json:"open_connections" example:"12"`
+	InUse             int          `json:"in_use" example:"4"`
+	Idle              int          `json:"idle" example:"8"`
+	WaitCount         int64        `json:"wait_count" example:"130"`
+	WaitDuration      string       `json:"wait_duration" example:"1.5s"`
+	MaxIdleClosed     int64        `json:"max_idle_closed" example:"3"`
+	MaxLifetimeClosed int64        `json:"max_lifetime_closed" example:"40"`
+}
+
+// JobStatus - состояние фоновой задачи
+type JobStatus struct {
+	Name                string     `json:"name" example:"anomaly_detection"`
+	Schedule            string     `json:"schedule" example:"0 9 * * *"`
+	Enabled             bool       `json:"enabled" example:"true"`
+	Running             bool       `json:"running" example:"false"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty" example:"2025-07-10T09:00:00Z"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty" example:"2025-07-10T09:00:00Z"`
+	LastDurationMs      int64      `json:"last_duration_ms" example:"840"`
+	LastError           string     `json:"last_error,omitempty"`
+	NextRunAt           *time.Time `json:"next_run_at,omitempty" example:"2025-07-11T09:00:00Z"`
+	Runs                int64      `json:"runs" example:"12"`
+	Failures            int64      `json:"failures" example:"1"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0"`
+	// Skipped - запуски, пропущенные, потому что задачу выполняла другая реплика
+	Skipped int64 `json:"skipped" example:"0"`
+}
+
+// QueryStats - размеры результатов и время выполнения запроса репозитория
+type QueryStats struct {
+	Query      string    `json:"query" example:"subscriptions.list"`
+	Count      int64     `json:"count" example:"1200"`
+	Rows       Histogram `json:"rows"`
+	DurationMs Histogram `json:"duration_ms"`
+}
+
+// Histogram - гистограмма с накопительными счетчиками корзин: Count - число наблюдений не больше Le
+type Histogram struct {
+	Buckets []HistogramBucket `json:"buckets"`
+	Sum     int64             `json:"sum" example:"48000"`
+	Max     int64             `json:"max" example:"1000"`
+}
+
+type HistogramBucket struct {
+	Le    string `json:"le" example:"100"`
+	Count int64  `json:"count" example:"900"`
+}
+
+// RouteInfo - зарегистрированный маршрут HTTP API
+type RouteInfo struct {
+	Method  string `json:"method" example:"GET"`
+	Path    string `json:"path" example:"/api/v1/subscriptions/:id"`
+	Handler string `json:"handler" example:"handler.(*SubscriptionHandler).GetSubscription-fm"`
+	// Middlewares - middleware маршрута в порядке выполнения
+	Middlewares []string `json:"middlewares" example:"handler.Authenticate"`
+	// Auth - требование аутентификации: none, bearer (токен провайдера или ADMIN_TOKEN) или admin_token
+	Auth string `json:"auth" enums:"none,bearer,admin_token" example:"bearer"`
+}
+
+// WebhookEndpointStats - состояние доставки событий на адрес подписчика
+type WebhookEndpointStats struct {
+	URL string `json:"url" example:"https://hooks.example.com/subscriptions"`
+	// QueueDepth - сколько событий ждет отправки
+	QueueDepth int   `json:"queue_depth" example:"3"`
+	Delivered  int64 `json:"delivered" example:"1520"`
+	Failed     int64 `json:"failed" example:"2"`
+	// Dropped - события, отброшенные из-за переполненной очереди или разомкнутого выключателя
+	Dropped     int64 `json:"dropped" example:"0"`
+	CircuitOpen bool  `json:"circuit_open" example:"false"`
+}
+
+// DependencyStatus - результат проверки зависимости в /readyz
+type DependencyStatus struct {
+	Name string `json:"name" example:"database"`
+	// Status - ok или unavailable
+	Status     string `json:"status" example:"ok"`
+	Error      string `json:"error,omitempty" example:"context deadline exceeded"`
+	DurationMs int64  `json:"duration_ms" example:"3"`
+}
+
+// ReadinessResponse - ответ /readyz. Status - ok, если все зависимости доступны, иначе unavailable
+type ReadinessResponse struct {
+	Status       string             `json:"status" example:"ok"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	// Pod - экземпляр, ответивший на пробу; не выводится вне Kubernetes
+	Pod *PodMetadata `json:"pod,omitempty"`
+}
+
+// PodMetadata - под Kubernetes, в котором работает экземпляр сервиса (downward API)
+type PodMetadata struct {
+	Name      string `json:"name,omitempty" example:"subscription-service-7d9f8b6c4-x2lkq"`
+	Namespace string `json:"namespace,omitempty" example:"billing"`
+	Node      string `json:"node,omitempty" example:"worker-3"`
+}
+
+// IsZero сообщает, что метаданные не заданы - сервис запущен вне Kubernetes
+func (p PodMetadata) IsZero() bool {
+	return p == PodMetadata{}
+}
+
+// LogAttrs возвращает заданные поля для логгера: pod, namespace, node
+func (p PodMetadata) LogAttrs() []interface{} {
+	var attrs []interface{}
+	for _, attr := range []struct{ key, value string }{{"pod", p.Name}, {"namespace", p.Namespace}, {"node", p.Node}} {
+		if attr.value != "" {
+			attrs = append(attrs, attr.key, attr.value)
+		}
+	}
+	return attrs
+}
+
+// SchemaDrift - расхождение схемы базы с ожидаемой сервисом
+type SchemaDrift struct {
+	// Drifted - в базе нет хотя бы одного ожидаемого объекта
+	Drifted        bool      `json:"drifted" example:"true"`
+	MissingTables  []string  `json:"missing_tables" example:"rejected_requests"`
+	MissingColumns []string  `json:"missing_columns" example:"subscriptions.tenant_id"`
+	MissingIndexes []string  `json:"missing_indexes" example:"idx_subscriptions_tenant_user"`
+	CheckedAt      time.Time `json:"checked_at" example:"2025-01-15T10:00:00Z"`
+}
+
+// TableStats - размер и состояние очистки таблицы по pg_stat_user_tables
+type TableStats struct {
+	Table    string `json:"table" example:"subscriptions"`
+	LiveRows int64  `json:"live_rows" example:"120000"`
+	DeadRows int64  `json:"dead_rows" example:"8400"`
+	// DeadRatio - доля мертвых строк, оценка раздувания таблицы
+	DeadRatio float64 `json:"dead_ratio" example:"0.065"`
+	// TotalBytes - размер таблицы с индексами и TOAST, IndexBytes - только индексов
+	TotalBytes           int64      `json:"total_bytes" example:"52428800"`
+	IndexBytes           int64      `json:"index_bytes" example:"20971520"`
+	SeqScans             int64      `json:"seq_scans" example:"12"`
+	IndexScans           int64      `json:"index_scans" example:"98000"`
+	ModifiedSinceAnalyze int64      `json:"modified_since_analyze" example:"3100"`
+	LastVacuum           *time.Time `json:"last_vacuum,omitempty" example:"2025-07-10T03:00:00Z"`
+	LastAutovacuum       *time.Time `json:"last_autovacuum,omitempty" example:"2025-07-10T03:00:00Z"`
+	LastAnalyze          *time.Time `json:"last_analyze,omitempty" example:"2025-07-10T03:00:00Z"`
+	LastAutoanalyze      *time.Time `json:"last_autoanalyze,omitempty" example:"2025-07-10T03:00:00Z"`
+}
+
+// IndexStats - использование индекса по pg_stat_user_indexes
+type IndexStats struct {
+	Table string `json:"table" example:"subscriptions"`
+	Index string `json:"index" example:"idx_subscriptions_tenant_user"`
+	// Scans - число просмотров индекса с последнего сброса статистики
+	Scans         int64 `json:"scans" example:"98000"`
+	TuplesRead    int64 `json:"tuples_read" example:"450000"`
+	TuplesFetched int64 `json:"tuples_fetched" example:"440000"`
+	Bytes         int64 `json:"bytes" example:"4194304"`
+	Unique        bool  `json:"unique" example:"false"`
+	// Unused - индекс не просматривался ни разу; уникальные индексы нужны и без просмотров
+	Unused bool `json:"unused" example:"false"`
+}
+
+// AnalyzeRequest - таблицы для ANALYZE; без tables обновляется статистика всех таблиц подписок
+type AnalyzeRequest struct {
+	Tables []string `json:"tables,omitempty" example:"subscriptions"`
+}
+
+// AnalyzedTable - результат ANALYZE таблицы
+type AnalyzedTable struct {
+	Table      string `json:"table" example:"subscriptions"`
+	DurationMs int64  `json:"duration_ms" example:"180"`
+}
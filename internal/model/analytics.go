@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// ActivityBucket - количество изменений подписок за интервал времени
+type ActivityBucket struct {
+	Start         time.Time `json:"start" example:"2025-07-10T00:00:00Z"`
+	Creations     int       `json:"creations" example:"12"`
+	Cancellations int       `json:"cancellations" example:"3"`
+	PriceChanges  int       `json:"price_changes" example:"1"`
+	Deletions     int       `json:"deletions" example:"0"`
+}
+
+// ActivityFilter - параметры выборки активности
+type ActivityFilter struct {
+	From   time.Time
+	To     time.Time
+	Bucket string
+}
+
+// RetentionFilter - параметры расчета удержания: когорты с From по To (первые числа месяцев)
+// и подписки на сервис ServiceName; пустое название - подписки на любые сервисы
+type RetentionFilter struct {
+	ServiceName string
+	From        time.Time
+	To          time.Time
+}
+
+// RetentionCell - пользователи когорты Cohort (Users), у которых через Offset месяцев
+// после первой подписки действует подписка (Retained)
+type RetentionCell struct {
+	Cohort   time.Time
+	Users    int
+	Offset   int
+	Retained int
+}
+
+// RetentionCohort - строка таблицы удержания: пользователи, впервые подписавшиеся в месяце
+// Cohort, и сколько из них сохранили подписку через 0, 1, 2... месяцев до текущего
+type RetentionCohort struct {
+	Cohort   string    `json:"cohort" example:"01-2025"`
+	Users    int       `json:"users" example:"40"`
+	Retained []int     `json:"retained" example:"40,31,27"`
+	Rates    []float64 `json:"rates" example:"1,0.775,0.675"`
+}
+
+// RetentionReport - таблица удержания по когортам
+type RetentionReport struct {
+	ServiceName string            `json:"service_name,omitempty" example:"Netflix"`
+	Cohorts     []RetentionCohort `json:"cohorts"`
+}
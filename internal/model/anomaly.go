@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MonthlySpend - суммарная стоимость подписок пользователя за месяц
+type MonthlySpend struct {
+	Month time.Time `json:"month" swaggertype:"string" example:"07-2025"`
+	Total int       `json:"total" example:"1200"`
+}
+
+func (m MonthlySpend) MarshalJSON() ([]byte, error) {
+	type Alias MonthlySpend
+	return json.Marshal(&struct {
+		Month string `json:"month"`
+		*Alias
+	}{
+		Month: formatMonthYear(m.Month),
+		Alias: (*Alias)(&m),
+	})
+}
+
+// SpendAnomaly - месяц, в котором траты пользователя заметно превысили среднее за предыдущие месяцы.
+// TrailingAverage 0 - в предыдущих месяцах трат не было; DeviationPercent тогда тоже 0
+type SpendAnomaly struct {
+	UserID           uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Month            time.Time `json:"month" swaggertype:"string" example:"07-2025"`
+	Spend            int       `json:"spend" example:"2100"`
+	TrailingAverage  int       `json:"trailing_average" example:"1200"`
+	DeviationPercent int       `json:"deviation_percent" example:"75"`
+	ThresholdPercent int       `json:"threshold_percent" example:"50"`
+}
+
+func (a SpendAnomaly) MarshalJSON() ([]byte, error) {
+	type Alias SpendAnomaly
+	return json.Marshal(&struct {
+		Month string `json:"month"`
+		*Alias
+	}{
+		Month: formatMonthYear(a.Month),
+		Alias: (*Alias)(&a),
+	})
+}
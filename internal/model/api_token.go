@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Права личных токенов API
+const (
+	// ScopeReadSubscriptions - чтение подписок пользователя: список, поиск, выгрузка, подписка по ID
+	ScopeReadSubscriptions = "read:subscriptions"
+	// ScopeReadSummary - итоги стоимости подписок и динамика трат
+	ScopeReadSummary = "read:summary"
+	// ScopeWriteSubscriptions - создание, изменение, отмена и удаление подписок
+	ScopeWriteSubscriptions = "write:subscriptions"
+)
+
+// APITokenPrefix - префикс личных токенов API; по нему middleware аутентификации отличает
+// их от токенов провайдера
+const APITokenPrefix = "sst_"
+
+// APIToken - личный токен API пользователя. Сам токен не хранится: он показывается
+// один раз при выпуске, в базе остается только его хеш
+type APIToken struct {
+	ID        uuid.UUID  `json:"id" example:"2b8e1c4a-5d6f-4a7b-9c8d-0e1f2a3b4c5d"`
+	UserID    uuid.UUID  `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Name      string     `json:"name" example:"Google Sheets"`
+	Scopes    []string   `json:"scopes" example:"read:subscriptions,read:summary"`
+	CreatedAt time.Time  `json:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" swaggertype:"string" example:"2025-10-08 12:30:00"`
+	// Tenant - организация, в которой выпущен токен; запросы с токеном выполняются в ней
+	Tenant string `json:"-"`
+}
+
+func (t APIToken) MarshalJSON() ([]byte, error) {
+	type Alias APIToken
+	return json.Marshal(&struct {
+		CreatedAt string  `json:"created_at"`
+		ExpiresAt *string `json:"expires_at,omitempty"`
+		*Alias
+	}{
+		CreatedAt: formatDateTime(t.CreatedAt),
+		ExpiresAt: formatDateTimePtr(t.ExpiresAt),
+		Alias:     (*Alias)(&t),
+	})
+}
+
+// Expired сообщает, что срок действия токена истек к моменту now
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// IssuedAPIToken - выпущенный токен вместе с секретом. Секрет больше не показывается:
+// список токенов возвращает только их описание
+type IssuedAPIToken struct {
+	APIToken
+	Token string `json:"token" example:"sst_q3Jx0k9cX2mVbW7n1LrT8yZ4aPdGfHsE6uIoKlMnBvC"`
+}
+
+func (t IssuedAPIToken) MarshalJSON() ([]byte, error) {
+	// Alias без методов: иначе MarshalJSON встроенного APIToken скрыл бы поле token
+	type Alias APIToken
+	return json.Marshal(&struct {
+		CreatedAt string  `json:"created_at"`
+		ExpiresAt *string `json:"expires_at,omitempty"`
+		*Alias
+		Token string `json:"token"`
+	}{
+		CreatedAt: formatDateTime(t.CreatedAt),
+		ExpiresAt: formatDateTimePtr(t.ExpiresAt),
+		Alias:     (*Alias)(&t.APIToken),
+		Token:     t.Token,
+	})
+}
+
+// CreateAPITokenRequest - тело выпуска личного токена. Без expires_in_days токен бессрочный
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"Google Sheets"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read:subscriptions read:summary write:subscriptions" enums:"read:subscriptions,read:summary,write:subscriptions" example:"read:subscriptions,read:summary"`
+	ExpiresInDays *int     `json:"expires_in_days,omitempty" binding:"omitempty,min=1,max=365" example:"90"`
+}
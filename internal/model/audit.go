@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Действия журнала аудита
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry - запись журнала аудита: образы подписки до и после изменения, автор
+// и идентификатор запроса. Actor - "user:<id>", "admin:<id>" или "admin"; пустой
+// у изменений вне API
+type AuditEntry struct {
+	ID         int64     `json:"id" example:"1042"`
+	EntityType string    `json:"entity_type" example:"subscription"`
+	EntityID   uuid.UUID `json:"entity_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	// UserID - владелец подписки после изменения (для удаления - до него)
+	UserID    uuid.UUID       `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Action    string          `json:"action" enums:"create,update,delete" example:"update"`
+	Actor     string          `json:"actor,omitempty" example:"user:60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	RequestID string          `json:"request_id,omitempty" example:"0b6c1f0e-3c1d-4f5a-9a57-3d8f2c4e7b21"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2025-07-10T09:30:00Z"`
+}
+
+// AuditFilter - условия выборки журнала аудита; нулевые поля не ограничивают выборку.
+// BeforeID продолжает выборку с записей старше указанной
+type AuditFilter struct {
+	EntityID  *uuid.UUID
+	UserID    *uuid.UUID
+	Actor     string
+	Action    string
+	RequestID string
+	Since     *time.Time
+	Until     *time.Time
+	BeforeID  int64
+	Limit     int
+}
+
+// IsValidAuditAction проверяет действие журнала аудита
+func IsValidAuditAction(action string) bool {
+	return action == AuditCreate || action == AuditUpdate || action == AuditDelete
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Состояния резервной копии
+const (
+	BackupRunning   = "running"
+	BackupCompleted = "completed"
+	BackupFailed    = "failed"
+	// BackupExpired - копию удалила политика хранения, запись о ней осталась
+	BackupExpired = "expired"
+)
+
+// Backup - запись журнала логических резервных копий
+type Backup struct {
+	ID uuid.UUID `json:"id" example:"4f1c2a9e-7b3d-4e8a-9c6f-2d5b8e1a7c30"`
+	// Key - ключ зашифрованной копии в хранилище файлов
+	Key        string     `json:"key" example:"backups/2025/07/10/4f1c2a9e-7b3d-4e8a-9c6f-2d5b8e1a7c30.ndjson.gz.enc"`
+	Status     string     `json:"status" enums:"running,completed,failed,expired" example:"completed"`
+	StartedAt  time.Time  `json:"started_at" example:"2025-07-10T03:00:00Z"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2025-07-10T03:00:42Z"`
+	// SnapshotAt и WALPosition - момент и позиция WAL, на которые согласованы все таблицы копии
+	SnapshotAt  *time.Time `json:"snapshot_at,omitempty" example:"2025-07-10T03:00:00.123Z"`
+	WALPosition *string    `json:"wal_lsn,omitempty" example:"0/16B3748"`
+	// Tables - число строк каждой таблицы в копии
+	Tables    map[string]int64 `json:"tables,omitempty"`
+	SizeBytes *int64           `json:"size_bytes,omitempty" example:"1048576"`
+	// SHA256 - контрольная сумма зашифрованного файла для проверки после скачивания
+	SHA256    *string    `json:"sha256,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Error     *string    `json:"error,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-08-09T03:00:00Z"`
+}
+
+// BackupSnapshot - согласованный снимок базы, с которого снята копия
+type BackupSnapshot struct {
+	At          time.Time
+	WALPosition string
+	Tables      map[string]int64
+}
@@ -0,0 +1,72 @@
+package model
+
+import "github.com/google/uuid"
+
+// Режимы пакетных операций
+const (
+	// BulkAtomic - все или ничего: при ошибке любого элемента не применяется ни один
+	BulkAtomic = "atomic"
+	// BulkBestEffort - элементы применяются независимо, ошибки одних не мешают другим
+	BulkBestEffort = "best_effort"
+)
+
+// Состояния элементов пакетной операции
+const (
+	BulkItemCreated   = "created"
+	BulkItemUpdated   = "updated"
+	BulkItemUnchanged = "unchanged"
+	BulkItemDeleted   = "deleted"
+	BulkItemFailed    = "failed"
+	// BulkItemSkipped - корректный элемент атомарной пачки, не примененный из-за ошибки другого
+	BulkItemSkipped = "skipped"
+)
+
+// MaxBulkItems - наибольшее число элементов в одной пакетной операции
+const MaxBulkItems = 1000
+
+// IsValidBulkMode проверяет режим пакетной операции
+func IsValidBulkMode(mode string) bool {
+	return mode == BulkAtomic || mode == BulkBestEffort
+}
+
+type BulkCreateRequest struct {
+	Items []CreateSubscriptionRequest `json:"items" binding:"required,min=1,max=1000"`
+}
+
+// BulkUpdateItem - новые данные подписки ID; поля - как у PUT /subscriptions/{id}
+type BulkUpdateItem struct {
+	ID uuid.UUID `json:"id" binding:"required" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	UpdateSubscriptionRequest
+}
+
+type BulkUpdateRequest struct {
+	Items []BulkUpdateItem `json:"items" binding:"required,min=1,max=1000"`
+}
+
+type BulkDeleteRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"required,min=1,max=1000"`
+}
+
+// BulkItemResult - результат элемента пакетной операции. Index - позиция элемента в запросе
+type BulkItemResult struct {
+	Index  int        `json:"index" example:"0"`
+	ID     *uuid.UUID `json:"id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	Status string     `json:"status" enums:"created,updated,unchanged,deleted,failed,skipped" example:"created"`
+	Error  string     `json:"error,omitempty" example:"end date cannot be before start date"`
+	// Subscription - созданная подписка
+	Subscription *Subscription `json:"subscription,omitempty"`
+}
+
+// BulkResponse - результат пакетной операции по элементам в порядке запроса
+type BulkResponse struct {
+	Mode      string           `json:"mode" enums:"atomic,best_effort" example:"atomic"`
+	Succeeded int              `json:"succeeded" example:"2"`
+	Failed    int              `json:"failed" example:"0"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+// SubscriptionUpdate - изменение одной подписки в пачке
+type SubscriptionUpdate struct {
+	ID           uuid.UUID
+	Subscription *Subscription
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogService - известный сервис подписок из каталога. Подписки ссылаются на него
+// через service_id, чтобы фильтры и отчеты не зависели от написания service_name
+type CatalogService struct {
+	ID       uuid.UUID `json:"id" example:"3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"`
+	Name     string    `json:"name" example:"Yandex Plus"`
+	Category *string   `json:"category,omitempty" example:"music"`
+	// DefaultMonthlyCost - обычная месячная стоимость сервиса, справочно
+	DefaultMonthlyCost *int      `json:"default_monthly_cost,omitempty" example:"400"`
+	IconURL            *string   `json:"icon_url,omitempty" example:"https://cdn.example.com/icons/yandex-plus.png"`
+	CreatedAt          time.Time `json:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	UpdatedAt          time.Time `json:"updated_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (s CatalogService) MarshalJSON() ([]byte, error) {
+	type Alias CatalogService
+	return json.Marshal(&struct {
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+		*Alias
+	}{
+		CreatedAt: formatDateTime(s.CreatedAt),
+		UpdatedAt: formatDateTime(s.UpdatedAt),
+		Alias:     (*Alias)(&s),
+	})
+}
+
+// CatalogServiceRequest - тело создания и изменения сервиса каталога
+type CatalogServiceRequest struct {
+	Name               string  `json:"name" binding:"required,max=255" example:"Yandex Plus"`
+	Category           *string `json:"category,omitempty" binding:"omitempty,max=64" example:"music"`
+	DefaultMonthlyCost *int    `json:"default_monthly_cost,omitempty" binding:"omitempty,min=0" example:"400"`
+	IconURL            *string `json:"icon_url,omitempty" binding:"omitempty,url,max=2048" example:"https://cdn.example.com/icons/yandex-plus.png"`
+}
+
+// CatalogFilter - фильтр списка сервисов каталога
+type CatalogFilter struct {
+	Category *string
+}
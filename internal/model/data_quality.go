@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Коды замечаний отчета о качестве данных
+const (
+	// QualityMissingEndDate - у действующей подписки не указан end_date
+	QualityMissingEndDate = "missing_end_date"
+	// QualityStaleDraft - черновик, начало которого уже прошло: он не входит в суммы
+	QualityStaleDraft = "stale_draft"
+	// QualityNonstandardServiceName - название сервиса написано иначе, чем у большинства пользователей
+	QualityNonstandardServiceName = "nonstandard_service_name"
+)
+
+// DataQualityItem - замечание к подписке и действие, которое его устраняет
+type DataQualityItem struct {
+	Code           string    `json:"code" enums:"missing_end_date,stale_draft,nonstandard_service_name" example:"missing_end_date"`
+	SubscriptionID uuid.UUID `json:"subscription_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	Action         string    `json:"action" example:"set end_date if the subscription is not renewed indefinitely"`
+	// Suggestion - предлагаемое значение (для nonstandard_service_name - распространенное написание)
+	Suggestion *string `json:"suggestion,omitempty" example:"Yandex Plus"`
+}
+
+// DataQualityReport - полнота данных пользователя: Score - доля подписок без замечаний в процентах
+type DataQualityReport struct {
+	UserID        uuid.UUID         `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Score         int               `json:"score" example:"75"`
+	Subscriptions int               `json:"subscriptions" example:"4"`
+	Complete      int               `json:"complete" example:"3"`
+	Items         []DataQualityItem `json:"items"`
+}
+
+// ServiceNameUsage - написание названия сервиса и число пользователей, которые его используют
+type ServiceNameUsage struct {
+	ServiceName string
+	Users       int
+}
+
+// NormalizeServiceName приводит название сервиса к виду для сравнения написаний:
+// нижний регистр и одиночные пробелы. Совпадает с normalizedServiceNameSQL в репозитории
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
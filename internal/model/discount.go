@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DiscountPercent - скидка в процентах от месячной стоимости
+	DiscountPercent = "percent"
+	// DiscountFixed - фиксированная скидка в рублях в месяц
+	DiscountFixed = "fixed"
+)
+
+// Discount - скидка, действующая с StartDate по EndDate включительно (с точностью до месяца).
+// Относится либо к одной подписке (SubscriptionID), либо ко всем подпискам пользователя (UserID).
+// Процентные скидки за месяц складываются (не больше 100%), затем вычитаются фиксированные;
+// стоимость подписки за месяц не становится отрицательной
+type Discount struct {
+	ID             uuid.UUID  `json:"id" example:"0b7e3c2a-5d1f-4e8a-9c6b-2f4d8a1e7b90"`
+	Kind           string     `json:"kind" enums:"percent,fixed" example:"percent"`
+	Value          int        `json:"value" example:"20"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	PromoCode      *string    `json:"promo_code,omitempty" example:"SUMMER25"`
+	StartDate      time.Time  `json:"start_date" swaggertype:"string" example:"07-2025"`
+	EndDate        *time.Time `json:"end_date,omitempty" swaggertype:"string" example:"09-2025"`
+	CreatedAt      time.Time  `json:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (d Discount) MarshalJSON() ([]byte, error) {
+	type Alias Discount
+	return json.Marshal(&struct {
+		StartDate string  `json:"start_date"`
+		EndDate   *string `json:"end_date,omitempty"`
+		CreatedAt string  `json:"created_at"`
+		*Alias
+	}{
+		StartDate: formatMonthYear(d.StartDate),
+		EndDate:   formatMonthYearPtr(d.EndDate),
+		CreatedAt: formatDateTime(d.CreatedAt),
+		Alias:     (*Alias)(&d),
+	})
+}
+
+// DiscountRequest - тело создания и изменения скидки
+type DiscountRequest struct {
+	Kind           string     `json:"kind" binding:"required,oneof=percent fixed" example:"percent"`
+	Value          int        `json:"value" binding:"required,min=1" example:"20"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	PromoCode      *string    `json:"promo_code,omitempty" binding:"omitempty,max=50" example:"SUMMER25"`
+	StartDate      string     `json:"start_date" binding:"required" example:"07-2025"`
+	EndDate        *string    `json:"end_date,omitempty" example:"09-2025"`
+}
+
+// DiscountFilter - фильтр списка скидок
+type DiscountFilter struct {
+	SubscriptionID *uuid.UUID
+	UserID         *uuid.UUID
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок данных запроса. Обработчики различают их через errors.Is и отвечают 400;
+// любая ошибка, созданная Invalid, является ErrInvalidInput
+var (
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrInvalidPeriod       = errors.New("invalid period")
+	ErrInvalidPeriodFormat = errors.New("invalid period format")
+)
+
+// InputError - ошибка данных запроса вида kind с текстом для клиента
+type InputError struct {
+	kind error
+	err  error
+}
+
+// Invalid возвращает ошибку данных запроса вида kind; текст собирается как в fmt.Errorf,
+// поэтому %w сохраняет причину в цепочке
+func Invalid(kind error, format string, args ...interface{}) error {
+	return &InputError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+func (e *InputError) Error() string { return e.err.Error() }
+
+func (e *InputError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// Is относит ошибку любого вида к ErrInvalidInput
+func (e *InputError) Is(target error) bool { return target == ErrInvalidInput }
@@ -0,0 +1,8 @@
+package model
+
+// EventSchemaVersions - версии схемы данных события; Latest публикуется сейчас
+type EventSchemaVersions struct {
+	Type     string `json:"type" example:"subscription.renewal_reminder"`
+	Versions []int  `json:"versions" example:"1"`
+	Latest   int    `json:"latest" example:"1"`
+}
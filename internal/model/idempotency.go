@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Состояния ключа идемпотентности
+const (
+	// IdempotencyProcessing - запрос с ключом выполняется
+	IdempotencyProcessing = "processing"
+	// IdempotencyCompleted - ответ на запрос сохранен и возвращается повторам
+	IdempotencyCompleted = "completed"
+)
+
+// IdempotencyRecord - ключ идемпотентности автора запроса и сохраненный ответ.
+// Fingerprint отличает повтор того же запроса от другого запроса с тем же ключом
+type IdempotencyRecord struct {
+	Actor          string
+	Key            string
+	Fingerprint    string
+	Status         string
+	ResponseStatus int
+	ContentType    string
+	ResponseBody   []byte
+	LockedUntil    time.Time
+	ExpiresAt      time.Time
+}
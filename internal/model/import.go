@@ -0,0 +1,20 @@
+package model
+
+// ImportRow - подписка из файла импорта и номер строки файла, из которой она прочитана
+type ImportRow struct {
+	Line    int
+	Request CreateSubscriptionRequest
+}
+
+// ImportError - причина, по которой строка файла не импортирована
+type ImportError struct {
+	Line  int    `json:"line" example:"3"`
+	Error string `json:"error" example:"invalid start date format, expected MM-YYYY"`
+}
+
+// ImportResult - отчет об импорте подписок: число созданных подписок и ошибки по строкам
+type ImportResult struct {
+	Imported int           `json:"imported" example:"98"`
+	Failed   int           `json:"failed" example:"2"`
+	Errors   []ImportError `json:"errors"`
+}
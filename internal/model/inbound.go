@@ -0,0 +1,123 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы входящих событий об изменении подписок во внешней системе
+const (
+	// InboundSubscriptionCreated - data: тело POST /subscriptions
+	InboundSubscriptionCreated = "subscription.created"
+	// InboundSubscriptionUpdated - data: id и тело PUT /subscriptions/{id}
+	InboundSubscriptionUpdated = "subscription.updated"
+	// InboundSubscriptionCancelled - data: id и тело POST /subscriptions/{id}/cancel
+	InboundSubscriptionCancelled = "subscription.cancelled"
+	// InboundSubscriptionDeleted - data: id
+	InboundSubscriptionDeleted = "subscription.deleted"
+)
+
+// Состояния входящего события
+const (
+	// InboundProcessing - событие обрабатывается
+	InboundProcessing = "processing"
+	// InboundProcessed - событие применено; повторы отбрасываются
+	InboundProcessed = "processed"
+	// InboundDuplicate - результат повтора уже обработанного события
+	InboundDuplicate = "duplicate"
+)
+
+// InboundEvent - событие интеграции. Пара source и id определяет событие: повтор
+// с той же парой не применяется второй раз
+type InboundEvent struct {
+	ID     string          `json:"id" binding:"required,max=255" example:"evt_1Q2w3E4r"`
+	Source string          `json:"source" binding:"required,max=64" example:"billing"`
+	Type   string          `json:"type" binding:"required,oneof=subscription.created subscription.updated subscription.cancelled subscription.deleted" example:"subscription.cancelled"`
+	Data   json.RawMessage `json:"data" binding:"required" swaggertype:"object"`
+}
+
+// InboundSubscriptionRef - данные события subscription.deleted
+type InboundSubscriptionRef struct {
+	ID uuid.UUID `json:"id" binding:"required"`
+}
+
+// InboundSubscriptionUpdate - данные события subscription.updated
+type InboundSubscriptionUpdate struct {
+	ID uuid.UUID `json:"id" binding:"required"`
+	UpdateSubscriptionRequest
+}
+
+// InboundSubscriptionCancel - данные события subscription.cancelled
+type InboundSubscriptionCancel struct {
+	ID uuid.UUID `json:"id" binding:"required"`
+	CancelSubscriptionRequest
+}
+
+// DecodeData разбирает данные события по его типу: *CreateSubscriptionRequest,
+// *InboundSubscriptionUpdate, *InboundSubscriptionCancel или *InboundSubscriptionRef.
+// Неизвестные поля - ошибка, чтобы опечатка в данных не потерялась молча
+func (e InboundEvent) DecodeData() (interface{}, error) {
+	var payload interface{}
+	switch e.Type {
+	case InboundSubscriptionCreated:
+		payload = &CreateSubscriptionRequest{}
+	case InboundSubscriptionUpdated:
+		payload = &InboundSubscriptionUpdate{}
+	case InboundSubscriptionCancelled:
+		payload = &InboundSubscriptionCancel{}
+	case InboundSubscriptionDeleted:
+		payload = &InboundSubscriptionRef{}
+	default:
+		return nil, Invalid(ErrInvalidInput, "unsupported event type %q", e.Type)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(e.Data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return nil, Invalid(ErrInvalidInput, "invalid %s event data: %w", e.Type, err)
+	}
+	return payload, nil
+}
+
+// InboundEventResult - результат приема события: processed - событие применено,
+// duplicate - событие уже было применено и отброшено
+type InboundEventResult struct {
+	ID             string     `json:"id" example:"evt_1Q2w3E4r"`
+	Source         string     `json:"source" example:"billing"`
+	Type           string     `json:"type" example:"subscription.cancelled"`
+	Status         string     `json:"status" enums:"processed,duplicate" example:"processed"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+}
+
+// InboundRecord - запись о принятом событии в таблице inbound_events
+type InboundRecord struct {
+	Source         string     `json:"source" example:"billing"`
+	EventID        string     `json:"event_id" example:"evt_1Q2w3E4r"`
+	Type           string     `json:"type" example:"subscription.cancelled"`
+	Status         string     `json:"status" enums:"processing,processed" example:"processed"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	LockedUntil    time.Time  `json:"-"`
+	ReceivedAt     time.Time  `json:"received_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	ProcessedAt    *time.Time `json:"processed_at,omitempty" swaggertype:"string" example:"2025-07-10 12:30:01"`
+	// Duplicates - сколько повторов события отброшено
+	Duplicates      int        `json:"duplicates" example:"2"`
+	LastDuplicateAt *time.Time `json:"last_duplicate_at,omitempty" swaggertype:"string" example:"2025-07-10 12:45:00"`
+}
+
+func (r InboundRecord) MarshalJSON() ([]byte, error) {
+	type Alias InboundRecord
+	return json.Marshal(&struct {
+		ReceivedAt      string  `json:"received_at"`
+		ProcessedAt     *string `json:"processed_at,omitempty"`
+		LastDuplicateAt *string `json:"last_duplicate_at,omitempty"`
+		*Alias
+	}{
+		ReceivedAt:      formatDateTime(r.ReceivedAt),
+		ProcessedAt:     formatDateTimePtr(r.ProcessedAt),
+		LastDuplicateAt: formatDateTimePtr(r.LastDuplicateAt),
+		Alias:           (*Alias)(&r),
+	})
+}
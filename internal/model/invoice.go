@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invoice - счет пользователя за месяц. Итоги - суммы округленных строк
+type Invoice struct {
+	ID            uuid.UUID     `json:"id" example:"3d9a1f6e-2c4b-4e7a-8f1d-5b6c7a8e9f01"`
+	UserID        uuid.UUID     `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Period        time.Time     `json:"period" swaggertype:"string" example:"07-2025"`
+	TaxRate       string        `json:"tax_rate" example:"20.00"`
+	BaseTotal     int           `json:"base_total" example:"1400"`
+	DiscountTotal int           `json:"discount_total" example:"80"`
+	NetTotal      int           `json:"net_total" example:"1100"`
+	TaxTotal      int           `json:"tax_total" example:"220"`
+	GrossTotal    int           `json:"gross_total" example:"1320"`
+	Lines         []InvoiceLine `json:"lines,omitempty"`
+	CreatedAt     time.Time     `json:"created_at" swaggertype:"string" example:"2025-08-01 09:00:00"`
+}
+
+func (i Invoice) MarshalJSON() ([]byte, error) {
+	type Alias Invoice
+	return json.Marshal(&struct {
+		Period    string `json:"period"`
+		CreatedAt string `json:"created_at"`
+		*Alias
+	}{
+		Period:    formatMonthYear(i.Period),
+		CreatedAt: formatDateTime(i.CreatedAt),
+		Alias:     (*Alias)(&i),
+	})
+}
+
+// InvoiceLine - строка счета по одной подписке.
+// BaseAmount - стоимость месяца до скидок (для годовой предоплаты - ее двенадцатая часть),
+// DiscountAmount - сумма скидок за месяц, Net/Tax/Gross - оставшаяся сумма, разложенная по налогу
+type InvoiceLine struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	BaseAmount     int       `json:"base_amount" example:"400"`
+	DiscountAmount int       `json:"discount_amount" example:"80"`
+	NetAmount      int       `json:"net_amount" example:"267"`
+	TaxAmount      int       `json:"tax_amount" example:"53"`
+	GrossAmount    int       `json:"gross_amount" example:"320"`
+}
+
+// MonthlyCharge - начисление по подписке за месяц, рассчитанное в репозитории без округления
+type MonthlyCharge struct {
+	SubscriptionID uuid.UUID
+	ServiceName    string
+	// Base - стоимость месяца до скидок
+	Base *big.Rat
+	// Amount - стоимость месяца после скидок
+	Amount *big.Rat
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Ограничения метаданных подписки
+const (
+	MaxMetadataKeys        = 20
+	MaxMetadataValueLength = 200
+)
+
+// metadataKeyPattern - допустимый ключ метаданных: строчные латинские буквы, цифры, "_" и "-".
+// Ключ подставляется в путь JSON запросов SQLite, поэтому кавычки и точки в нем запрещены
+var metadataKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// SummaryGroupByMetadata - префикс группировки итогов по ключу метаданных: metadata.<key>
+const SummaryGroupByMetadata = "metadata."
+
+// SummaryGroupByService - группировка итогов по названию сервиса (breakdown)
+const SummaryGroupByService = "service"
+
+// Metadata - произвольные метки подписки (проект, отдел, центр затрат), по которым
+// организация группирует итоги. Хранится в JSONB (в SQLite - JSON в тексте)
+type Metadata map[string]string
+
+// Validate проверяет число меток, ключи и длину значений
+func (m Metadata) Validate() error {
+	if len(m) > MaxMetadataKeys {
+		return Invalid(ErrInvalidInput, "invalid metadata: at most %d keys are allowed", MaxMetadataKeys)
+	}
+	for key, value := range m {
+		if !metadataKeyPattern.MatchString(key) {
+			return Invalid(ErrInvalidInput, "invalid metadata key %q: expected 1-40 characters a-z, 0-9, _ or -", key)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return Invalid(ErrInvalidInput, "invalid metadata value for %q: at most %d bytes are allowed", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// Value записывает метаданные объектом JSON; пустые метаданные - "{}"
+func (m Metadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan читает метаданные из JSONB или текста JSON; пустой объект читается как nil
+func (m *Metadata) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported metadata type %T", src)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	*m = values
+	return nil
+}
+
+// ParseSummaryGroupBy проверяет группировку итогов и возвращает ключ метаданных.
+// Пустая группировка и группировка по сервису ключа не имеют
+func ParseSummaryGroupBy(groupBy string) (string, error) {
+	if groupBy == "" || groupBy == SummaryGroupByService {
+		return "", nil
+	}
+	key, ok := strings.CutPrefix(groupBy, SummaryGroupByMetadata)
+	if !ok || !metadataKeyPattern.MatchString(key) {
+		return "", Invalid(ErrInvalidInput, "invalid group_by %q: expected service or metadata.<key>", groupBy)
+	}
+	return key, nil
+}
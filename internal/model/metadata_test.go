@@ -0,0 +1,65 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestParseSummaryGroupBy(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		want    string
+		wantErr bool
+	}{
+		{groupBy: "", want: ""},
+		{groupBy: "metadata.project", want: "project"},
+		{groupBy: "metadata.cost-center_2", want: "cost-center_2"},
+		{groupBy: "service", want: ""},
+		{groupBy: "metadata.", wantErr: true},
+		{groupBy: "project", wantErr: true},
+		{groupBy: "service_name", wantErr: true},
+		{groupBy: `metadata.a"b`, wantErr: true},
+		{groupBy: "metadata.Project", wantErr: true},
+		{groupBy: "metadata.a.b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			got, err := model.ParseSummaryGroupBy(tt.groupBy)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "invalid group_by") {
+					t.Fatalf("ParseSummaryGroupBy(%q) error = %v, want invalid group_by", tt.groupBy, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSummaryGroupBy(%q) = %q, %v, want %q", tt.groupBy, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataValidateAndScan(t *testing.T) {
+	if err := (model.Metadata{"project": "apollo", "cost-center": "42"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (model.Metadata{"Project": "apollo"}).Validate(); err == nil {
+		t.Error("Validate() accepted key with uppercase letters")
+	}
+	if err := (model.Metadata{"project": strings.Repeat("x", model.MaxMetadataValueLength+1)}).Validate(); err == nil {
+		t.Error("Validate() accepted too long value")
+	}
+
+	var m model.Metadata
+	if err := m.Scan([]byte(`{"project":"apollo"}`)); err != nil || m["project"] != "apollo" {
+		t.Errorf("Scan() = %v, %v", m, err)
+	}
+	if err := m.Scan("{}"); err != nil || m != nil {
+		t.Errorf("Scan({}) = %v, %v, want nil", m, err)
+	}
+	if value, err := m.Value(); err != nil || value != "{}" {
+		t.Errorf("Value() of empty metadata = %v, %v, want {}", value, err)
+	}
+}
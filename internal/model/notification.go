@@ -0,0 +1,93 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreferences - адрес и виды писем пользователя. Пользователь без настроек
+// писем не получает
+type NotificationPreferences struct {
+	UserID           uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Email            string    `json:"email" example:"user@example.com"`
+	RenewalReminders bool      `json:"renewal_reminders" example:"true"`
+	Cancellations    bool      `json:"cancellations" example:"true"`
+	UpdatedAt        time.Time `json:"updated_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (p NotificationPreferences) MarshalJSON() ([]byte, error) {
+	type Alias NotificationPreferences
+	return json.Marshal(&struct {
+		UpdatedAt string `json:"updated_at"`
+		*Alias
+	}{
+		UpdatedAt: formatDateTime(p.UpdatedAt),
+		Alias:     (*Alias)(&p),
+	})
+}
+
+// SaveNotificationPreferencesRequest - тело сохранения настроек уведомлений.
+// Не указанные виды писем включены
+type SaveNotificationPreferencesRequest struct {
+	Email            string `json:"email" binding:"required,email,max=254" example:"user@example.com"`
+	RenewalReminders *bool  `json:"renewal_reminders,omitempty" example:"true"`
+	Cancellations    *bool  `json:"cancellations,omitempty" example:"false"`
+}
+
+// Виды входящих уведомлений пользователя
+const (
+	// InboxExpiringSoon - скоро заканчивается оплаченный период или продлевается подписка
+	InboxExpiringSoon = "expiring_soon"
+	// InboxPriceIncrease - выросла ежемесячная стоимость подписки
+	InboxPriceIncrease = "price_increase"
+	// InboxSpendAnomaly - траты за месяц заметно превысили среднее
+	InboxSpendAnomaly = "spend_anomaly"
+)
+
+// InboxNotification - уведомление в центре уведомлений пользователя. Data - данные
+// уведомления вида Kind (напоминание, старая и новая цена, аномалия трат)
+type InboxNotification struct {
+	ID             uuid.UUID       `json:"id" example:"0c5b1d4e-6a3f-4f0e-8b1a-7e2d9c3f4a51"`
+	UserID         uuid.UUID       `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Kind           string          `json:"kind" enums:"expiring_soon,price_increase,spend_anomaly" example:"price_increase"`
+	SubscriptionID *uuid.UUID      `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	Message        string          `json:"message" example:"Стоимость подписки Yandex Plus выросла с 400 до 500 ₽ в месяц"`
+	Data           json.RawMessage `json:"data" swaggertype:"object"`
+	Read           bool            `json:"read" example:"false"`
+	CreatedAt      time.Time       `json:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	ReadAt         *time.Time      `json:"read_at,omitempty" swaggertype:"string" example:"2025-07-10 12:45:00"`
+	// DedupKey - ключ повторов: уведомление с тем же ключом у пользователя не записывается
+	DedupKey string `json:"-"`
+}
+
+func (n InboxNotification) MarshalJSON() ([]byte, error) {
+	type Alias InboxNotification
+	return json.Marshal(&struct {
+		CreatedAt string  `json:"created_at"`
+		ReadAt    *string `json:"read_at,omitempty"`
+		*Alias
+	}{
+		CreatedAt: formatDateTime(n.CreatedAt),
+		ReadAt:    formatDateTimePtr(n.ReadAt),
+		Alias:     (*Alias)(&n),
+	})
+}
+
+// InboxFilter - условия выборки уведомлений пользователя
+type InboxFilter struct {
+	UnreadOnly bool
+}
+
+// InboxPage - страница уведомлений, их количество под фильтром и число непрочитанных
+type InboxPage struct {
+	Items  []*InboxNotification `json:"items"`
+	Total  int                  `json:"total" example:"12"`
+	Unread int                  `json:"unread" example:"3"`
+}
+
+// MarkAllReadResponse - результат отметки всех уведомлений прочитанными
+type MarkAllReadResponse struct {
+	Marked int `json:"marked" example:"3"`
+}
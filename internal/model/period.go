@@ -0,0 +1,49 @@
+package model
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPeriodTimezone - часовой пояс периодов, пока он не задан через SetPeriodLocation
+const DefaultPeriodTimezone = "Europe/Moscow"
+
+// periodLocation - часовой пояс, в котором момент времени относится к месяцу периода
+// и в котором выводятся created_at/updated_at. Задается один раз при старте (PERIOD_TIMEZONE)
+var periodLocation atomic.Pointer[time.Location]
+
+// defaultPeriodLocation загружает DefaultPeriodTimezone; без базы часовых поясов - UTC
+var defaultPeriodLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation(DefaultPeriodTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+})
+
+// SetPeriodLocation задает часовой пояс периодов; nil возвращает пояс по умолчанию
+func SetPeriodLocation(loc *time.Location) {
+	periodLocation.Store(loc)
+}
+
+// PeriodLocation возвращает часовой пояс периодов
+func PeriodLocation() *time.Location {
+	if loc := periodLocation.Load(); loc != nil {
+		return loc
+	}
+	return defaultPeriodLocation()
+}
+
+// MonthOf возвращает месяц, к которому момент t относится в часовом поясе периодов.
+// Месяц, как и результат ParseMonthYear и значения DATE из базы, - первое число в 00:00 UTC:
+// это календарная дата, а не момент времени, поэтому ее нельзя переводить в другой пояс
+func MonthOf(t time.Time) time.Time {
+	t = t.In(PeriodLocation())
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CurrentMonth возвращает текущий месяц в часовом поясе периодов
+func CurrentMonth() time.Time {
+	return MonthOf(time.Now())
+}
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// setPeriodLocation задает часовой пояс периодов на время теста
+func setPeriodLocation(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	model.SetPeriodLocation(loc)
+	t.Cleanup(func() { model.SetPeriodLocation(nil) })
+}
+
+func TestMonthOfBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		instant  time.Time
+		want     string
+	}{
+		// 31.10 21:30 UTC - уже 1 ноября 00:30 по Москве
+		{"moscow after midnight", "Europe/Moscow", time.Date(2026, time.October, 31, 21, 30, 0, 0, time.UTC), "11-2026"},
+		{"moscow before midnight", "Europe/Moscow", time.Date(2026, time.October, 31, 20, 59, 59, 0, time.UTC), "10-2026"},
+		{"utc same instant", "UTC", time.Date(2026, time.October, 31, 21, 30, 0, 0, time.UTC), "10-2026"},
+		{"new year in moscow", "Europe/Moscow", time.Date(2025, time.December, 31, 21, 0, 0, 0, time.UTC), "01-2026"},
+		// Момент с другим поясом приводится к поясу периодов, а не берется как есть
+		{"instant in other zone", "UTC", time.Date(2026, time.November, 1, 2, 0, 0, 0, time.FixedZone("UTC+3", 3*3600)), "10-2026"},
+		{"los angeles lags behind", "America/Los_Angeles", time.Date(2026, time.November, 1, 5, 0, 0, 0, time.UTC), "10-2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPeriodLocation(t, tt.location)
+
+			month := model.MonthOf(tt.instant)
+			if got := month.Format("01-2006"); got != tt.want {
+				t.Errorf("MonthOf(%s) = %s, want %s", tt.instant, got, tt.want)
+			}
+
+			// Месяц совпадает с тем, что возвращает разбор периода: сравнение с датами из запросов
+			// и из базы не зависит от пояса
+			parsed, err := model.ParseMonthYear(tt.want)
+			if err != nil || !month.Equal(parsed) || month.Location() != time.UTC {
+				t.Errorf("MonthOf = %v, ParseMonthYear = %v (%v)", month, parsed, err)
+			}
+		})
+	}
+}
+
+func TestEffectiveStatusAtMonthBoundary(t *testing.T) {
+	setPeriodLocation(t, "Europe/Moscow")
+	endDate, _ := model.ParseMonthYear("10-2026")
+
+	// В последний вечер октября по UTC в Москве уже ноябрь: подписка до 10-2026 истекла
+	month := model.MonthOf(time.Date(2026, time.October, 31, 22, 0, 0, 0, time.UTC))
+	if status := model.EffectiveStatus(model.StatusActive, &endDate, month); status != model.StatusExpired {
+		t.Errorf("status = %s, want %s", status, model.StatusExpired)
+	}
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SubscriptionPrice - стоимость подписки, действующая с месяца From
+type SubscriptionPrice struct {
+	From time.Time `json:"from" swaggertype:"string" example:"03-2025"`
+	// To - последний месяц действия стоимости; у последней записи - конец подписки,
+	// у бессрочной подписки не задан
+	To          *time.Time `json:"to,omitempty" swaggertype:"string" example:"08-2025"`
+	MonthlyCost int        `json:"monthly_cost" example:"400"`
+	// BillingPeriod и Cost - период оплаты и стоимость за него, как у подписки
+	BillingPeriod string `json:"billing_period" enums:"weekly,monthly,yearly" example:"monthly"`
+	Cost          *int   `json:"cost,omitempty" example:"400"`
+}
+
+func (p SubscriptionPrice) MarshalJSON() ([]byte, error) {
+	type Alias SubscriptionPrice
+	return json.Marshal(&struct {
+		From          string  `json:"from"`
+		To            *string `json:"to,omitempty"`
+		Cost          int     `json:"cost"`
+		BillingPeriod string  `json:"billing_period"`
+		*Alias
+	}{
+		From:          formatMonthYear(p.From),
+		To:            formatMonthYearPtr(p.To),
+		Cost:          p.PeriodCost(),
+		BillingPeriod: p.Period(),
+		Alias:         (*Alias)(&p),
+	})
+}
+
+// Period возвращает период оплаты; запись без периода помесячная
+func (p SubscriptionPrice) Period() string {
+	if p.BillingPeriod == "" {
+		return BillingMonthly
+	}
+	return p.BillingPeriod
+}
+
+// PeriodCost возвращает стоимость за период оплаты; у помесячной оплаты это monthly_cost
+func (p SubscriptionPrice) PeriodCost() int {
+	if p.Cost != nil {
+		return *p.Cost
+	}
+	return p.MonthlyCost
+}
+
+// Price возвращает текущую стоимость подписки, действующую с месяца from
+func (s *Subscription) Price(from time.Time) SubscriptionPrice {
+	return SubscriptionPrice{
+		From:          from,
+		MonthlyCost:   s.MonthlyCost,
+		BillingPeriod: s.Period(),
+		Cost:          s.Cost,
+	}
+}
+
+// SamePrice сообщает, что стоимости совпадают: месяц начала не сравнивается
+func SamePrice(a, b SubscriptionPrice) bool {
+	if a.MonthlyCost != b.MonthlyCost || a.Period() != b.Period() {
+		return false
+	}
+	if a.Cost == nil || b.Cost == nil {
+		return a.Cost == nil && b.Cost == nil
+	}
+	return *a.Cost == *b.Cost
+}
+
+// EffectivePrice возвращает из истории prices (по возрастанию From) стоимость, действующую
+// в месяце month: последнюю запись, вступившую в силу не позже месяца. Месяцы до первой
+// записи считаются по первой записи; false - история пуста
+func EffectivePrice(prices []SubscriptionPrice, month time.Time) (SubscriptionPrice, bool) {
+	if len(prices) == 0 {
+		return SubscriptionPrice{}, false
+	}
+	effective := prices[0]
+	for _, price := range prices[1:] {
+		if price.From.After(month) {
+			break
+		}
+		effective = price
+	}
+	return effective, true
+}
+
+// PriceHistory возвращает стоимость подписки sub по месяцам из записей истории recorded
+// (по возрастанию From), как ее используют расчеты: первая запись действует с начала
+// подписки, записи, вступившие в силу до начала или после конца подписки, не показываются.
+// Без записей стоимость подписки действует весь ее срок
+func PriceHistory(sub *Subscription, recorded []SubscriptionPrice) []SubscriptionPrice {
+	first, ok := EffectivePrice(recorded, sub.StartDate)
+	if !ok {
+		price := sub.Price(sub.StartDate)
+		price.To = sub.EndDate
+		return []SubscriptionPrice{price}
+	}
+
+	first.From = sub.StartDate
+	history := []SubscriptionPrice{first}
+	for _, price := range recorded {
+		if !price.From.After(sub.StartDate) {
+			continue
+		}
+		if sub.EndDate != nil && price.From.After(*sub.EndDate) {
+			break
+		}
+		history = append(history, price)
+	}
+
+	for i := range history {
+		if i+1 < len(history) {
+			to := history[i+1].From.AddDate(0, -1, 0)
+			history[i].To = &to
+		} else {
+			history[i].To = sub.EndDate
+		}
+	}
+	return history
+}
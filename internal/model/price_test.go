@@ -0,0 +1,57 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestPriceHistory(t *testing.T) {
+	month := func(m time.Month, year int) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+	end := month(time.December, 2025)
+	sub := &model.Subscription{MonthlyCost: 300, StartDate: month(time.March, 2025), EndDate: &end}
+
+	tests := []struct {
+		name     string
+		recorded []model.SubscriptionPrice
+		want     string
+	}{
+		{
+			name: "without history",
+			want: `[{"from":"03-2025","to":"12-2025","cost":300,"billing_period":"monthly","monthly_cost":300}]`,
+		},
+		{
+			name: "price changed",
+			recorded: []model.SubscriptionPrice{
+				{From: month(time.March, 2025), MonthlyCost: 100},
+				{From: month(time.June, 2025), MonthlyCost: 300},
+			},
+			want: `[{"from":"03-2025","to":"05-2025","cost":100,"billing_period":"monthly","monthly_cost":100},` +
+				`{"from":"06-2025","to":"12-2025","cost":300,"billing_period":"monthly","monthly_cost":300}]`,
+		},
+		{
+			// Начало подписки перенесено: записи до начала и после конца не показываются
+			name: "start moved",
+			recorded: []model.SubscriptionPrice{
+				{From: month(time.January, 2025), MonthlyCost: 100},
+				{From: month(time.February, 2025), MonthlyCost: 200},
+				{From: month(time.January, 2026), MonthlyCost: 300},
+			},
+			want: `[{"from":"03-2025","to":"12-2025","cost":200,"billing_period":"monthly","monthly_cost":200}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(model.PriceHistory(sub, tt.recorded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("PriceHistory() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Коды причин отклонения запроса на запись
+const (
+	RejectionValidation      = "validation"
+	RejectionUnauthenticated = "unauthenticated"
+	RejectionForbidden       = "forbidden"
+	RejectionNotFound        = "not_found"
+	RejectionConflict        = "conflict"
+	RejectionTooLarge        = "too_large"
+	RejectionRateLimited     = "rate_limited"
+	RejectionOther           = "rejected"
+)
+
+// RejectedRequest - запрос на запись, отклоненный с кодом 4xx
+type RejectedRequest struct {
+	ID     int64      `json:"id" example:"1042"`
+	UserID *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Method string     `json:"method" example:"POST"`
+	// Route - шаблон маршрута, а не фактический путь
+	Route     string    `json:"route" example:"/api/v1/subscriptions"`
+	Status    int       `json:"status" example:"400"`
+	Reason    string    `json:"reason" example:"validation"`
+	Message   string    `json:"message" example:"price must be positive"`
+	CreatedAt time.Time `json:"created_at" example:"2025-07-10T09:30:00Z"`
+}
+
+// RejectionFilter - условия выборки отклоненных запросов; нулевые поля не ограничивают выборку
+type RejectionFilter struct {
+	UserID *uuid.UUID
+	Reason string
+	Route  string
+	Since  *time.Time
+	Limit  int
+}
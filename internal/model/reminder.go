@@ -0,0 +1,84 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды напоминаний о продлении
+const (
+	// ReminderExpiring - у подписки с end_date заканчивается оплаченный период
+	ReminderExpiring = "expiring"
+	// ReminderRenewal - бессрочная подписка продлевается в следующем месяце
+	ReminderRenewal = "renewal"
+)
+
+// RenewalReminder - напоминание об окончании периода подписки; данные события
+// subscription.renewal_reminder
+type RenewalReminder struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	Tenant         string    `json:"-"`
+	UserID         uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	MonthlyCost    int       `json:"monthly_cost" example:"400"`
+	Kind           string    `json:"kind" enums:"expiring,renewal" example:"expiring"`
+	// RenewsAt - первый день месяца после оплаченного периода
+	RenewsAt time.Time `json:"-"`
+	// RenewalDate - RenewsAt в формате DD-MM-YYYY, как в шаблоне письма renewal_reminder
+	RenewalDate string `json:"renewal_date" example:"01-08-2025"`
+	DaysLeft    int    `json:"days_left" example:"5"`
+	// Channel - канал из настроек напоминаний подписки; пустой - каналы по умолчанию
+	Channel string `json:"-"`
+}
+
+// ReminderWindow - окно поиска подписок для напоминаний
+type ReminderWindow struct {
+	// Подписки с end_date, период которых заканчивается после After и не позже Until.
+	// Для подписки со своим сроком напоминаний Until - After плюс этот срок
+	After, Until time.Time
+	// OpenEndedRenewal - ближайшее продление бессрочных подписок; nil - бессрочные не включаются
+	OpenEndedRenewal *time.Time
+}
+
+// Каналы напоминаний о продлении
+const (
+	ReminderChannelLog     = "log"
+	ReminderChannelWebhook = "webhook"
+	ReminderChannelEmail   = "email"
+	ReminderChannelInbox   = "inbox"
+)
+
+// MaxReminderDays - наибольший срок напоминания подписки в днях: о годовом продлении
+// можно напомнить за год
+const MaxReminderDays = 365
+
+// ReminderSettings - настройки напоминаний о продлении подписки. Незаданные значения -
+// RENEWAL_REMINDER_DAYS и каналы RENEWAL_REMINDER_CHANNELS
+type ReminderSettings struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	// Days - за сколько дней до окончания периода отправляется напоминание
+	Days *int `json:"days,omitempty" example:"30"`
+	// Channel - единственный канал напоминаний подписки
+	Channel   *string   `json:"channel,omitempty" enums:"log,webhook,email,inbox" example:"email"`
+	UpdatedAt time.Time `json:"updated_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (s ReminderSettings) MarshalJSON() ([]byte, error) {
+	type Alias ReminderSettings
+	return json.Marshal(&struct {
+		UpdatedAt string `json:"updated_at"`
+		*Alias
+	}{
+		UpdatedAt: formatDateTime(s.UpdatedAt),
+		Alias:     (*Alias)(&s),
+	})
+}
+
+// SaveReminderSettingsRequest - тело сохранения настроек напоминаний подписки;
+// нужно указать срок, канал или оба
+type SaveReminderSettingsRequest struct {
+	Days    *int    `json:"days,omitempty" binding:"required_without=Channel,omitempty,min=1,max=365" example:"30"`
+	Channel *string `json:"channel,omitempty" binding:"omitempty,oneof=log webhook email inbox" example:"email"`
+}
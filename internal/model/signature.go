@@ -0,0 +1,112 @@
+package model
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryAlgorithmVersion - версия правил расчета итогов. Увеличивается, когда те же
+// подписки за тот же период начинают давать другие суммы
+const SummaryAlgorithmVersion = "1"
+
+// SummaryCalculation - параметры, с которыми посчитаны итоги
+type SummaryCalculation struct {
+	AlgorithmVersion string `json:"algorithm_version" example:"1"`
+	Tenant           string `json:"tenant" example:"default"`
+	// Filters - фильтр расчета; для пользователя с токеном OIDC user_id - его собственный
+	Filters      SummaryCalculationFilters `json:"filters"`
+	CalculatedAt time.Time                 `json:"calculated_at" example:"2025-07-10T12:30:00Z"`
+}
+
+type SummaryCalculationFilters struct {
+	StartPeriod         string      `json:"start_period" example:"01-2025"`
+	EndPeriod           string      `json:"end_period" example:"12-2025"`
+	UserID              *uuid.UUID  `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName         string      `json:"service_name,omitempty" example:"Yandex Plus"`
+	ServiceID           *uuid.UUID  `json:"service_id,omitempty" example:"3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"`
+	Tag                 string      `json:"tag,omitempty" example:"work"`
+	Amount              string      `json:"amount,omitempty" enums:"gross,net" example:"gross"`
+	ExcludeServiceNames []string    `json:"exclude_service_names,omitempty" example:"Zoom"`
+	ExcludeUserIDs      []uuid.UUID `json:"exclude_user_ids,omitempty"`
+}
+
+// SummarySignature - подпись итогов вместе с параметрами расчета
+type SummarySignature struct {
+	Algorithm string `json:"algorithm" example:"HMAC-SHA256"`
+	KeyID     string `json:"key_id" example:"v1"`
+	// Value - подпись SigningPayload в шестнадцатеричном виде
+	Value string `json:"value" example:"5d41402abc4b2a76b9719d911017c592..."`
+}
+
+// SummaryVerification - результат проверки подписи итогов
+type SummaryVerification struct {
+	Valid  bool   `json:"valid" example:"true"`
+	Reason string `json:"reason,omitempty" example:"signature mismatch"`
+}
+
+// SigningPayload возвращает подписываемое представление итогов: поля сумм и параметров
+// расчета в виде application/x-www-form-urlencoded с ключами по алфавиту. Представление
+// не зависит от порядка полей JSON, поэтому получатель воспроизводит его на любом языке.
+// Без Calculation возвращает nil
+func (r *SummaryResponse) SigningPayload() []byte {
+	calc := r.Calculation
+	if calc == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	v.Set("algorithm_version", calc.AlgorithmVersion)
+	v.Set("tenant", calc.Tenant)
+	v.Set("calculated_at", calc.CalculatedAt.UTC().Format(time.RFC3339))
+	v.Set("start_period", calc.Filters.StartPeriod)
+	v.Set("end_period", calc.Filters.EndPeriod)
+	if calc.Filters.UserID != nil {
+		v.Set("user_id", calc.Filters.UserID.String())
+	}
+	if calc.Filters.ServiceName != "" {
+		v.Set("service_name", calc.Filters.ServiceName)
+	}
+	if calc.Filters.ServiceID != nil {
+		v.Set("service_id", calc.Filters.ServiceID.String())
+	}
+	if calc.Filters.Tag != "" {
+		v.Set("tag", calc.Filters.Tag)
+	}
+	if calc.Filters.Amount != "" {
+		v.Set("amount", calc.Filters.Amount)
+	}
+	excludedServices := append([]string(nil), calc.Filters.ExcludeServiceNames...)
+	sort.Strings(excludedServices)
+	for _, name := range excludedServices {
+		v.Add("exclude_service_name", name)
+	}
+	excludedUsers := make([]string, 0, len(calc.Filters.ExcludeUserIDs))
+	for _, id := range calc.Filters.ExcludeUserIDs {
+		excludedUsers = append(excludedUsers, id.String())
+	}
+	sort.Strings(excludedUsers)
+	for _, id := range excludedUsers {
+		v.Add("exclude_user_id", id)
+	}
+
+	v.Set("total_cost", strconv.Itoa(r.TotalCost))
+	v.Set("active_cost", strconv.Itoa(r.ActiveCost))
+	v.Set("cancelled_cost", strconv.Itoa(r.CancelledCost))
+	if r.AmountType != "" {
+		v.Set("amount_type", r.AmountType)
+		v.Set("tax_rate", r.TaxRate)
+	}
+	if r.TaxAmount != nil {
+		v.Set("tax_amount", strconv.Itoa(*r.TaxAmount))
+	}
+	// Корректировки - в порядке применения: modifier:active_cost:cancelled_cost
+	for _, a := range r.Adjustments {
+		v.Add("adjustment", a.Modifier+":"+strconv.Itoa(a.ActiveCost)+":"+strconv.Itoa(a.CancelledCost))
+	}
+
+	return []byte(v.Encode())
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Sparkline - помесячные траты пользователя для мини-графика, от самого раннего месяца к текущему
+type Sparkline struct {
+	From   time.Time `json:"from" swaggertype:"string" example:"08-2024"`
+	To     time.Time `json:"to" swaggertype:"string" example:"07-2025"`
+	Values []int     `json:"values" example:"800,800,1200,1200,1200,1600,1600,1600,1600,2000,2000,2400"`
+}
+
+func (s Sparkline) MarshalJSON() ([]byte, error) {
+	type Alias Sparkline
+	return json.Marshal(&struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		*Alias
+	}{
+		From:  formatMonthYear(s.From),
+		To:    formatMonthYear(s.To),
+		Alias: (*Alias)(&s),
+	})
+}
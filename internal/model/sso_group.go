@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Роли участников групп SSO
+const (
+	// GroupRoleMember - доступ к своим данным в организации группы
+	GroupRoleMember = "member"
+	// GroupRoleAdmin - права администратора в организации группы, без организации - во всех
+	GroupRoleAdmin = "admin"
+)
+
+// GroupMapping - сопоставление группы SSO из токена провайдера организации и роли
+type GroupMapping struct {
+	Group string `json:"group" example:"/acme/billing-admins"`
+	// Tenant - организация участников группы; пустое значение - роль admin во всех организациях
+	Tenant    string    `json:"tenant,omitempty" example:"acme"`
+	Role      string    `json:"role" enums:"member,admin" example:"admin"`
+	UpdatedAt time.Time `json:"updated_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (m GroupMapping) MarshalJSON() ([]byte, error) {
+	type Alias GroupMapping
+	return json.Marshal(&struct {
+		UpdatedAt string `json:"updated_at"`
+		*Alias
+	}{
+		UpdatedAt: formatDateTime(m.UpdatedAt),
+		Alias:     (*Alias)(&m),
+	})
+}
+
+// SaveGroupMappingRequest - запрос на создание или замену сопоставления группы SSO
+type SaveGroupMappingRequest struct {
+	Group  string `json:"group" binding:"required,max=255" example:"/acme/billing-admins"`
+	Tenant string `json:"tenant,omitempty" example:"acme"`
+	Role   string `json:"role" binding:"required,oneof=member admin" enums:"member,admin" example:"admin"`
+}
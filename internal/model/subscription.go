@@ -3,65 +3,397 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 type Subscription struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	ServiceName string     `json:"service_name" db:"service_name"`
-	MonthlyCost int        `json:"monthly_cost" db:"monthly_cost"`
-	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
-	StartDate   time.Time  `json:"start_date" db:"start_date"`
-	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID          uuid.UUID  `json:"id" db:"id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	ServiceName string     `json:"service_name" db:"service_name" example:"Yandex Plus"`
+	MonthlyCost int        `json:"monthly_cost" db:"monthly_cost" example:"400"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate   time.Time  `json:"start_date" db:"start_date" swaggertype:"string" example:"07-2025"`
+	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date" swaggertype:"string" example:"12-2025"`
+	// PrepaidAmount - сумма годовой предоплаты, MonthlyCost для таких подписок - ее двенадцатая часть
+	PrepaidAmount *int `json:"prepaid_amount,omitempty" db:"prepaid_amount" example:"4800"`
+	IsDraft       bool `json:"is_draft" db:"is_draft" example:"false"`
+	// Status - состояние подписки; expired не хранится и вычисляется для активных подписок с прошедшим end_date
+	Status string `json:"status" db:"status" enums:"active,paused,cancelled,expired" example:"active"`
+	// CancelReason и CancelledAt заполняются при отмене через POST /subscriptions/{id}/cancel
+	CancelReason *string    `json:"cancel_reason,omitempty" db:"cancel_reason" example:"too expensive"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	// Metadata - метки организации, по которым группируются итоги (group_by=metadata.<key>)
+	Metadata Metadata `json:"metadata,omitempty" db:"metadata" swaggertype:"object,string" example:"project:apollo"`
+	// ServiceID - сервис из каталога (/services), к которому относится подписка
+	ServiceID *uuid.UUID `json:"service_id,omitempty" db:"service_id" example:"3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"`
+	// Description - заметки о подписке в свободной форме
+	Description *string `json:"description,omitempty" db:"description" example:"Семейный тариф на четверых, продлевать вручную"`
+	// BillingPeriod - период оплаты; MonthlyCost для недельной и годовой оплаты - Cost, переведенная в месяц
+	BillingPeriod string `json:"billing_period" db:"billing_period" enums:"weekly,monthly,yearly" example:"yearly"`
+	// Cost - стоимость за период оплаты; хранится только для недельной и годовой оплаты,
+	// в ответе API у помесячных подписок равна monthly_cost
+	Cost *int `json:"cost,omitempty" db:"cost" example:"4800"`
+	// Tags - теги подписки для группировки трат (work, entertainment)
+	Tags Tags `json:"tags,omitempty" swaggertype:"array,string" example:"work"`
+	// PriceFrom - месяц, с которого действует новая стоимость при изменении подписки;
+	// nil - стоимость не менялась. Прежняя стоимость остается в истории для прошлых месяцев
+	PriceFrom *time.Time `json:"-"`
+	// ChangeSeq растет с каждым изменением подписки; порядок изменений разных подписок не задает
+	ChangeSeq int64     `json:"change_seq" db:"change_seq" example:"42"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
 }
 
 // JSON методы для кастомного форматирования дат
 func (s Subscription) MarshalJSON() ([]byte, error) {
 	type Alias Subscription
 	return json.Marshal(&struct {
-		StartDate string  `json:"start_date"`
-		EndDate   *string `json:"end_date,omitempty"`
-		CreatedAt string  `json:"created_at"`
-		UpdatedAt string  `json:"updated_at"`
+		StartDate     string  `json:"start_date"`
+		EndDate       *string `json:"end_date,omitempty"`
+		CancelledAt   *string `json:"cancelled_at,omitempty"`
+		CreatedAt     string  `json:"created_at"`
+		UpdatedAt     string  `json:"updated_at"`
+		IsFree        bool    `json:"is_free,omitempty"`
+		Cost          int     `json:"cost"`
+		BillingPeriod string  `json:"billing_period"`
 		*Alias
 	}{
-		StartDate: formatMonthYear(s.StartDate),
-		EndDate:   formatMonthYearPtr(s.EndDate),
-		CreatedAt: formatDateTime(s.CreatedAt),
-		UpdatedAt: formatDateTime(s.UpdatedAt),
-		Alias:     (*Alias)(&s),
+		StartDate:     formatMonthYear(s.StartDate),
+		EndDate:       formatMonthYearPtr(s.EndDate),
+		CancelledAt:   formatDateTimePtr(s.CancelledAt),
+		CreatedAt:     formatDateTime(s.CreatedAt),
+		UpdatedAt:     formatDateTime(s.UpdatedAt),
+		IsFree:        s.Free(),
+		Cost:          s.PeriodCost(),
+		BillingPeriod: s.Period(),
+		Alias:         (*Alias)(&s),
 	})
 }
 
+// MaxDescriptionLength - наибольшая длина описания подписки в символах
+const MaxDescriptionLength = 2000
+
+// NormalizeDescription убирает пробелы по краям описания; пустое описание не хранится
+func NormalizeDescription(description *string) (*string, error) {
+	if description == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*description)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(trimmed) > MaxDescriptionLength {
+		return nil, Invalid(ErrInvalidInput, "invalid description: at most %d characters are allowed", MaxDescriptionLength)
+	}
+	return &trimmed, nil
+}
+
+const (
+	BillingWeekly  = "weekly"
+	BillingMonthly = "monthly"
+	BillingYearly  = "yearly"
+)
+
+// IsValidBillingPeriod проверяет период оплаты
+func IsValidBillingPeriod(period string) bool {
+	switch period {
+	case BillingWeekly, BillingMonthly, BillingYearly:
+		return true
+	}
+	return false
+}
+
+// MonthlyRate переводит стоимость за период оплаты в стоимость за месяц без округления:
+// в году 12 месяцев и 52 недели
+func MonthlyRate(period string, cost int) *big.Rat {
+	switch period {
+	case BillingWeekly:
+		return big.NewRat(int64(cost)*52, 12)
+	case BillingYearly:
+		return big.NewRat(int64(cost), 12)
+	}
+	return big.NewRat(int64(cost), 1)
+}
+
+// Period возвращает период оплаты; подписка без периода помесячная, как и в базе
+func (s *Subscription) Period() string {
+	if s.BillingPeriod == "" {
+		return BillingMonthly
+	}
+	return s.BillingPeriod
+}
+
+// PeriodCost возвращает стоимость за период оплаты; у помесячных подписок это monthly_cost
+func (s *Subscription) PeriodCost() int {
+	if s.Cost != nil {
+		return *s.Cost
+	}
+	return s.MonthlyCost
+}
+
+// Free сообщает, что подписка бесплатная: она учитывается в количестве подписок
+// и напоминаниях, но не добавляет ничего к суммам
+func (s *Subscription) Free() bool {
+	return s.MonthlyCost == 0 && s.PrepaidAmount == nil
+}
+
 type CreateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" binding:"required"`
-	MonthlyCost int       `json:"monthly_cost" binding:"required,min=1"`
-	UserID      uuid.UUID `json:"user_id" binding:"required"`
-	StartDate   string    `json:"start_date" binding:"required"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	ServiceName string    `json:"service_name" binding:"required" example:"Yandex Plus"`
+	MonthlyCost int       `json:"monthly_cost" binding:"required_without_all=PrepaidAmount IsFree Cost,omitempty,min=1" example:"400"`
+	UserID      uuid.UUID `json:"user_id" binding:"required" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate   string    `json:"start_date" binding:"required" example:"07-2025"`
+	EndDate     *string   `json:"end_date,omitempty" example:"12-2025"`
+	// PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев
+	PrepaidAmount *int `json:"prepaid_amount,omitempty" binding:"omitempty,min=1" example:"4800"`
+	IsDraft       bool `json:"is_draft,omitempty" example:"false"`
+	// IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются
+	IsFree bool `json:"is_free,omitempty" example:"false"`
+	// Metadata - метки подписки: до 20 ключей a-z, 0-9, _ и -
+	Metadata Metadata `json:"metadata,omitempty" swaggertype:"object,string" example:"project:apollo"`
+	// ServiceID - сервис из каталога; должен существовать
+	ServiceID *uuid.UUID `json:"service_id,omitempty" example:"3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"`
+	// Description - заметки о подписке, до 2000 символов
+	Description *string `json:"description,omitempty" example:"Семейный тариф на четверых, продлевать вручную"`
+	// BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost
+	BillingPeriod string `json:"billing_period,omitempty" binding:"omitempty,oneof=weekly monthly yearly" example:"yearly"`
+	// Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее
+	Cost *int `json:"cost,omitempty" binding:"omitempty,min=1" example:"4800"`
+	// Tags - до 20 тегов; хранятся в нижнем регистре, новые теги создаются автоматически
+	Tags []string `json:"tags,omitempty" example:"work"`
 }
 
 type UpdateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" binding:"required"`
-	MonthlyCost int       `json:"monthly_cost" binding:"required,min=1"`
-	UserID      uuid.UUID `json:"user_id" binding:"required"`
-	StartDate   string    `json:"start_date" binding:"required"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	ServiceName string    `json:"service_name" binding:"required" example:"Yandex Plus"`
+	MonthlyCost int       `json:"monthly_cost" binding:"required_without_all=PrepaidAmount IsFree Cost,omitempty,min=1" example:"400"`
+	UserID      uuid.UUID `json:"user_id" binding:"required" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate   string    `json:"start_date" binding:"required" example:"07-2025"`
+	EndDate     *string   `json:"end_date,omitempty" example:"12-2025"`
+	// PrepaidAmount - сумма годовой предоплаты; период должен составлять ровно 12 месяцев
+	PrepaidAmount *int `json:"prepaid_amount,omitempty" binding:"omitempty,min=1" example:"4800"`
+	// IsFree - бесплатная подписка (бесплатный тариф): monthly_cost и prepaid_amount не задаются
+	IsFree bool `json:"is_free,omitempty" example:"false"`
+	// Metadata - метки подписки, заменяют прежние целиком; без поля метки удаляются
+	Metadata Metadata `json:"metadata,omitempty" swaggertype:"object,string" example:"project:apollo"`
+	// ServiceID - сервис из каталога; без поля связь с каталогом удаляется
+	ServiceID *uuid.UUID `json:"service_id,omitempty" example:"3c9a1f52-7e4b-4d8a-b6c1-5f2e9d0a7b34"`
+	// Description - заметки о подписке, до 2000 символов; без поля описание удаляется
+	Description *string `json:"description,omitempty" example:"Семейный тариф на четверых, продлевать вручную"`
+	// BillingPeriod - период оплаты (по умолчанию monthly); для weekly и yearly задается cost
+	BillingPeriod string `json:"billing_period,omitempty" binding:"omitempty,oneof=weekly monthly yearly" example:"yearly"`
+	// Cost - стоимость за период оплаты вместо monthly_cost; monthly_cost вычисляется из нее
+	Cost *int `json:"cost,omitempty" binding:"omitempty,min=1" example:"4800"`
+	// Tags - теги подписки, заменяют прежние целиком; без поля теги снимаются
+	Tags []string `json:"tags,omitempty" example:"work"`
+	// PriceEffectiveFrom - месяц, с которого действует новая стоимость; прошлые месяцы
+	// считаются по прежней. По умолчанию текущий месяц, у подписок, которые еще не
+	// начались или уже закончились, - месяц начала (стоимость заменяется целиком)
+	PriceEffectiveFrom *string `json:"price_effective_from,omitempty" example:"09-2025"`
+	// Status - новое состояние подписки; если не задано, состояние не меняется
+	Status *string `json:"status,omitempty" binding:"omitempty,oneof=active paused cancelled" example:"paused"`
+}
+
+// CancelSubscriptionRequest - отмена подписки. Без AtPeriodEnd подписка заканчивается
+// месяцем EffectivePeriod (по умолчанию текущим), с AtPeriodEnd - в конце оплаченного срока
+type CancelSubscriptionRequest struct {
+	Reason          *string `json:"reason,omitempty" binding:"omitempty,max=500" example:"too expensive"`
+	EffectivePeriod *string `json:"effective_period,omitempty" example:"09-2025"`
+	AtPeriodEnd     bool    `json:"at_period_end,omitempty" example:"false"`
+}
+
+// TransferSubscriptionRequest - передача подписки другому пользователю
+type TransferSubscriptionRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required" example:"8d2f1c4e-5b6a-4c3d-9e8f-0a1b2c3d4e5f"`
+	Reason *string   `json:"reason,omitempty" binding:"omitempty,max=500" example:"family plan"`
 }
 
 type SummaryFilter struct {
 	UserID      uuid.UUID `form:"user_id"`
 	ServiceName string    `form:"service_name"`
-	StartPeriod string    `form:"start_period" binding:"required"`
-	EndPeriod   string    `form:"end_period" binding:"required"`
+	// ServiceID - подписки сервиса из каталога
+	ServiceID *uuid.UUID `form:"service_id"`
+	// Tag - подписки с тегом
+	Tag         string `form:"tag"`
+	StartPeriod string `form:"start_period" binding:"required"`
+	EndPeriod   string `form:"end_period" binding:"required"`
+	Amount      string `form:"amount"`
+	// ExcludeServiceNames и ExcludeUserIDs исключают подписки сервисов и пользователей из сумм
+	ExcludeServiceNames []string    `form:"exclude_service_name"`
+	ExcludeUserIDs      []uuid.UUID `form:"exclude_user_id"`
+	// Signed добавляет к итогам параметры расчета и их подпись
+	Signed bool `form:"signed"`
+	// GroupBy - группировка итогов по метке (metadata.<key>) или по сервису (service)
+	GroupBy string `form:"group_by"`
+}
+
+// CostTotals - точные (неокругленные) суммы за период, посчитанные в репозитории
+type CostTotals struct {
+	Total     *big.Rat
+	Active    *big.Rat
+	Cancelled *big.Rat
+	// Groups - суммы по значениям метки или по сервисам при группировке; Key nil - подписки
+	// без метки
+	Groups []CostGroup
+}
+
+// CostGroup - точные суммы подписок с одним значением метки или одного сервиса
+type CostGroup struct {
+	Key       *string
+	Total     *big.Rat
+	Active    *big.Rat
+	Cancelled *big.Rat
+	// Months - число оплачиваемых месяцев подписок группы в периоде
+	Months int
 }
 
 type SummaryResponse struct {
-	TotalCost int `json:"total_cost"`
+	TotalCost int `json:"total_cost" example:"2400"`
+	// ActiveCost - часть суммы по подпискам, которые активны сейчас
+	ActiveCost int `json:"active_cost" example:"1600"`
+	// CancelledCost - часть суммы по подпискам, которые уже закончились
+	CancelledCost int    `json:"cancelled_cost" example:"800"`
+	AmountType    string `json:"amount_type,omitempty" enums:"gross,net" example:"gross"`
+	TaxRate       string `json:"tax_rate,omitempty" example:"20.00"`
+	TaxAmount     *int   `json:"tax_amount,omitempty" example:"400"`
+	// Adjustments - корректировки модификаторов развертывания (SUMMARY_MODIFIERS), уже
+	// учтенные в суммах выше
+	Adjustments []SummaryAdjustment `json:"adjustments,omitempty"`
+	// GroupBy и Groups возвращаются с group_by: суммы по значениям метки. Корректировки
+	// модификаторов в группы не входят, а округленные суммы групп могут отличаться
+	// от итогов на копейки округления
+	GroupBy string         `json:"group_by,omitempty" example:"metadata.project"`
+	Groups  []SummaryGroup `json:"groups,omitempty"`
+	// Breakdown возвращается с group_by=service вместо Groups: суммы по сервисам по убыванию
+	Breakdown []SummaryBreakdown `json:"breakdown,omitempty"`
+	// Calculation и Signature возвращаются с signed=true
+	Calculation *SummaryCalculation `json:"calculation,omitempty"`
+	Signature   *SummarySignature   `json:"signature,omitempty"`
+}
+
+// SummaryGroup - суммы подписок с одним значением метки; Key null - подписки без метки
+type SummaryGroup struct {
+	Key           *string `json:"key" example:"apollo"`
+	TotalCost     int     `json:"total_cost" example:"1200"`
+	ActiveCost    int     `json:"active_cost" example:"800"`
+	CancelledCost int     `json:"cancelled_cost" example:"400"`
+}
+
+// SummaryBreakdown - сумма подписок одного сервиса за период
+type SummaryBreakdown struct {
+	ServiceName string `json:"service_name" example:"Netflix"`
+	// Months - число оплачиваемых месяцев подписок сервиса: две подписки за три месяца - 6
+	Months int `json:"months" example:"6"`
+	Total  int `json:"total" example:"2400"`
+}
+
+// SummaryAdjustment - корректировка итогов модификатором: на сколько рублей (до пересчета
+// налога) меняются стоимость активных и закончившихся подписок; скидка - отрицательное число
+type SummaryAdjustment struct {
+	Modifier      string `json:"modifier" example:"company_discount"`
+	ActiveCost    int    `json:"active_cost" example:"-160"`
+	CancelledCost int    `json:"cancelled_cost" example:"0"`
+	Description   string `json:"description,omitempty" example:"корпоративная скидка 10%"`
+}
+
+// SubscriptionChange - запись журнала изменений подписки
+type SubscriptionChange struct {
+	Seq            int64           `json:"seq" db:"seq" example:"1024"`
+	SubscriptionID uuid.UUID       `json:"subscription_id" db:"subscription_id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	Operation      string          `json:"operation" db:"operation" enums:"create,update,delete,transfer" example:"update"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object" db:"payload"`
+	ChangedAt      time.Time       `json:"changed_at" db:"changed_at" example:"2025-07-10T09:30:00Z"`
+}
+
+// ChangesResponse - страница изменений подписок для инкрементальной синхронизации
+type ChangesResponse struct {
+	Items        []*SubscriptionChange `json:"items"`
+	NextSinceSeq int64                 `json:"next_since_seq" example:"1024"`
+}
+
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+	// StatusExpired - активная подписка, у которой закончился end_date
+	StatusExpired = "expired"
+)
+
+// EffectiveStatus возвращает состояние подписки для ответа API: активная подписка,
+// последний месяц которой раньше month, считается истекшей
+func EffectiveStatus(status string, endDate *time.Time, month time.Time) string {
+	if status == StatusActive && endDate != nil && endDate.Before(month) {
+		return StatusExpired
+	}
+	return status
+}
+
+// IsValidStatus проверяет значение фильтра по состоянию
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusActive, StatusPaused, StatusCancelled, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// UserService - сервис, на который подписан пользователь, с числом действующих
+// подписок и их суммарной стоимостью в месяц
+type UserService struct {
+	ServiceName   string `json:"service_name" example:"Yandex Plus"`
+	Subscriptions int    `json:"subscriptions" example:"2"`
+	MonthlyCost   int    `json:"monthly_cost" example:"800"`
+}
+
+// SubscriptionFilter - фильтр списка подписок; nil-поля не ограничивают выборку
+type SubscriptionFilter struct {
+	UserID      *uuid.UUID
+	ServiceName *string
+	// ServiceID - подписки сервиса из каталога
+	ServiceID *uuid.UUID
+	// Tag - подписки с тегом
+	Tag    *string
+	Status *string
+	// ExcludeServiceNames и ExcludeUserIDs исключают подписки сервисов и пользователей
+	ExcludeServiceNames []string
+	ExcludeUserIDs      []uuid.UUID
+}
+
+// Режимы подсчета общего количества в постраничном списке
+const (
+	CountExact    = "exact"
+	CountEstimate = "estimate"
+)
+
+// Pagination - окно списка
+type Pagination struct {
+	Limit  int
+	Offset int
+	// Count - режим подсчета общего количества: CountExact (по умолчанию) или CountEstimate
+	Count string
+}
+
+// SubscriptionPage - страница списка подписок и общее количество подписок под фильтром.
+// Estimated - Total может быть оценкой планировщика, а не точным количеством
+type SubscriptionPage struct {
+	Items     []*Subscription
+	Total     int
+	Estimated bool
+}
+
+// SubscriptionCursor - позиция в списке подписок, упорядоченном по (created_at, id)
+type SubscriptionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// SubscriptionCursorPage - страница списка подписок в порядке (created_at, id).
+// Next указывает на последнюю подписку страницы и равен nil, если подписок дальше нет
+type SubscriptionCursorPage struct {
+	Items []*Subscription
+	Next  *SubscriptionCursor
 }
 
 // Вспомогательные функции для форматирования дат
@@ -79,9 +411,16 @@ func formatMonthYearPtr(t *time.Time) *string {
 }
 
 func formatDateTime(t time.Time) string {
-	// Дата и время для created_at/updated_at
-	location, _ := time.LoadLocation("Europe/Moscow")
-	return t.In(location).Format("2006-01-02 15:04:05")
+	// Дата и время для created_at/updated_at в часовом поясе периодов
+	return t.In(PeriodLocation()).Format("2006-01-02 15:04:05")
+}
+
+func formatDateTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatDateTime(*t)
+	return &formatted
 }
 
 // Функции для парсинга периодов (месяц-год)
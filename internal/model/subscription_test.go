@@ -0,0 +1,160 @@
+package model_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestJSONSnapshots фиксирует формат JSON, на который полагаются клиенты:
+// периоды в MM-YYYY, отсутствие end_date у бессрочных подписок и время по Москве
+func TestJSONSnapshots(t *testing.T) {
+	endDate := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+	cancelledAt := time.Date(2025, time.August, 15, 18, 5, 30, 0, time.UTC)
+	prepaid := 4800
+	yearlyCost := 4990
+	reason := "too expensive"
+	taxAmount := 400
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{
+			name: "subscription_open_ended",
+			value: model.Subscription{
+				ID:          uuid.MustParse("6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"),
+				ServiceName: "Yandex Plus",
+				MonthlyCost: 400,
+				UserID:      uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+				StartDate:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+				Status:      model.StatusActive,
+				ChangeSeq:   1,
+				// 22:30 UTC - уже следующие сутки и следующий год по Москве
+				CreatedAt: time.Date(2025, time.December, 31, 22, 30, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2025, time.December, 31, 22, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "subscription_cancelled",
+			value: model.Subscription{
+				ID:            uuid.MustParse("6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"),
+				ServiceName:   "Kinopoisk",
+				MonthlyCost:   400,
+				UserID:        uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+				StartDate:     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:       &endDate,
+				PrepaidAmount: &prepaid,
+				Status:        model.StatusCancelled,
+				CancelReason:  &reason,
+				CancelledAt:   &cancelledAt,
+				ChangeSeq:     42,
+				CreatedAt:     time.Date(2025, time.January, 10, 9, 30, 0, 0, time.UTC),
+				// Время с зоной, отличной от UTC, тоже переводится в московское
+				UpdatedAt: time.Date(2025, time.August, 15, 20, 5, 30, 0, time.FixedZone("CEST", 2*60*60)),
+			},
+		},
+		{
+			name: "subscription_yearly",
+			value: model.Subscription{
+				ID:            uuid.MustParse("6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"),
+				ServiceName:   "Kinopoisk",
+				MonthlyCost:   416,
+				UserID:        uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+				StartDate:     time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+				Status:        model.StatusActive,
+				BillingPeriod: model.BillingYearly,
+				Cost:          &yearlyCost,
+				ChangeSeq:     7,
+				CreatedAt:     time.Date(2025, time.July, 10, 9, 30, 0, 0, time.UTC),
+				UpdatedAt:     time.Date(2025, time.July, 10, 9, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "summary_gross",
+			value: model.SummaryResponse{
+				TotalCost:     2400,
+				ActiveCost:    1600,
+				CancelledCost: 800,
+			},
+		},
+		{
+			name: "summary_net",
+			value: model.SummaryResponse{
+				TotalCost:     2000,
+				ActiveCost:    1400,
+				CancelledCost: 600,
+				AmountType:    "net",
+				TaxRate:       "20.00",
+				TaxAmount:     &taxAmount,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+// assertGolden сравнивает JSON с файлом testdata/<name>.golden.json.
+// Запуск с флагом -update перезаписывает файл текущим значением.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, got, "", "  "); err != nil {
+		t.Fatalf("value is not valid JSON: %v: %s", err, got)
+	}
+	buf.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+
+	if !bytes.Equal(want, buf.Bytes()) {
+		t.Errorf("JSON does not match %s\ngot:\n%s\nwant:\n%s", path, buf.String(), want)
+	}
+}
+
+func TestNormalizeDescription(t *testing.T) {
+	blank := "  \n "
+	padded := "  Семейный тариф "
+	long := strings.Repeat("я", model.MaxDescriptionLength+1)
+
+	if got, err := model.NormalizeDescription(&blank); got != nil || err != nil {
+		t.Errorf("NormalizeDescription(blank) = %v, %v, want nil", got, err)
+	}
+	if got, err := model.NormalizeDescription(&padded); err != nil || got == nil || *got != "Семейный тариф" {
+		t.Errorf("NormalizeDescription(padded) = %v, %v", got, err)
+	}
+	if _, err := model.NormalizeDescription(&long); !errors.Is(err, model.ErrInvalidInput) {
+		t.Errorf("NormalizeDescription(long) error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import "github.com/google/uuid"
+
+// SyncSubscriptionItem - подписка желаемого набора; поля - как у PUT /subscriptions/{id},
+// user_id можно не указывать. Подписка без ID сопоставляется с существующей подпиской
+// пользователя того же сервиса с тем же start_date, несопоставленная - создается
+type SyncSubscriptionItem struct {
+	// ID - существующая подписка пользователя
+	ID *uuid.UUID `json:"id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	UpdateSubscriptionRequest
+}
+
+// SyncSubscriptionsRequest - полный желаемый набор подписок пользователя: подписки,
+// которых в нем нет, удаляются. Пустой список удаляет все подписки пользователя
+type SyncSubscriptionsRequest struct {
+	Subscriptions []SyncSubscriptionItem `json:"subscriptions" binding:"required,max=1000"`
+}
+
+// SyncUpdate - изменение подписки: состояние до и после синхронизации
+type SyncUpdate struct {
+	ID     uuid.UUID     `json:"id" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+	Before *Subscription `json:"before"`
+	After  *Subscription `json:"after"`
+}
+
+// SyncResponse - изменения, которыми набор подписок пользователя приведен к желаемому.
+// С dry_run изменения только вычислены и не применены
+type SyncResponse struct {
+	DryRun    bool            `json:"dry_run" example:"false"`
+	Created   []*Subscription `json:"created"`
+	Updated   []SyncUpdate    `json:"updated"`
+	Deleted   []*Subscription `json:"deleted"`
+	Unchanged []uuid.UUID     `json:"unchanged"`
+}
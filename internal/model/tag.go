@@ -0,0 +1,119 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения тегов подписки
+const (
+	MaxSubscriptionTags = 20
+	MaxTagLength        = 64
+)
+
+// Tags - теги подписки в нижнем регистре по алфавиту. Репозитории читают их массивом JSON
+type Tags []string
+
+// NormalizeTags приводит теги к нижнему регистру без пробелов по краям, убирает повторы
+// и сортирует. Пустые и слишком длинные теги и больше MaxSubscriptionTags тегов - ошибка
+func NormalizeTags(tags []string) (Tags, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		name, err := NormalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	if len(normalized) > MaxSubscriptionTags {
+		return nil, Invalid(ErrInvalidInput, "invalid tags: at most %d tags are allowed", MaxSubscriptionTags)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// NormalizeTagName приводит название тега к виду, в котором оно хранится
+func NormalizeTagName(tag string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(tag))
+	if name == "" {
+		return "", Invalid(ErrInvalidInput, "invalid tag: name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > MaxTagLength {
+		return "", Invalid(ErrInvalidInput, "invalid tag %q: at most %d characters are allowed", tag, MaxTagLength)
+	}
+	return name, nil
+}
+
+// Contains сообщает, что среди тегов есть tag
+func (t Tags) Contains(tag string) bool {
+	for _, name := range t {
+		if name == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan читает теги из массива JSON; пустой массив читается как nil
+func (t *Tags) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported tags type %T", src)
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("failed to decode tags: %w", err)
+	}
+	if len(names) == 0 {
+		names = nil
+	}
+	*t = names
+	return nil
+}
+
+// Tag - тег организации и число подписок с ним
+type Tag struct {
+	ID            uuid.UUID `json:"id" example:"9a4e2c71-3b5d-4f8e-a1c6-7d0b2e9f4a53"`
+	Name          string    `json:"name" example:"work"`
+	Subscriptions int       `json:"subscriptions" example:"4"`
+	CreatedAt     time.Time `json:"created_at" swaggertype:"string" example:"2025-07-10 12:30:00"`
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type Alias Tag
+	return json.Marshal(&struct {
+		CreatedAt string `json:"created_at"`
+		*Alias
+	}{
+		CreatedAt: formatDateTime(t.CreatedAt),
+		Alias:     (*Alias)(&t),
+	})
+}
+
+// TagRequest - тело создания и переименования тега
+type TagRequest struct {
+	Name string `json:"name" binding:"required" example:"work"`
+}
@@ -0,0 +1,43 @@
+package model_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := model.NormalizeTags([]string{" Work", "entertainment", "work", "WORK "})
+	if err != nil {
+		t.Fatalf("NormalizeTags() error = %v", err)
+	}
+	if want := (model.Tags{"entertainment", "work"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+
+	tooMany := make([]string, model.MaxSubscriptionTags+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("t", i+1)
+	}
+	for _, tags := range [][]string{{" "}, {strings.Repeat("x", model.MaxTagLength+1)}, tooMany} {
+		if _, err := model.NormalizeTags(tags); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("NormalizeTags(%d tags) error = %v, want ErrInvalidInput", len(tags), err)
+		}
+	}
+}
+
+func TestTagsScan(t *testing.T) {
+	var tags model.Tags
+	if err := tags.Scan([]byte(`["music","work"]`)); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if want := (model.Tags{"music", "work"}); !reflect.DeepEqual(tags, want) {
+		t.Errorf("Scan() = %v, want %v", tags, want)
+	}
+	if err := tags.Scan("[]"); err != nil || tags != nil {
+		t.Errorf("Scan(empty) = %v, %v, want nil", tags, err)
+	}
+}
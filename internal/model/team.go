@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverviewGroupByUser - группировка обзора подписок организации по пользователям
+const OverviewGroupByUser = "user"
+
+// UserSpend - подписки пользователя, действующие в текущем месяце
+type UserSpend struct {
+	UserID        uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Subscriptions int       `json:"subscriptions" example:"3"`
+	// Services - число разных сервисов (по model.NormalizeServiceName)
+	Services    int `json:"services" example:"2"`
+	MonthlyCost int `json:"monthly_cost" example:"1200"`
+}
+
+// SharedService - сервис, на который подписаны несколько пользователей организации
+type SharedService struct {
+	ServiceName   string `json:"service_name" example:"Yandex Plus"`
+	Users         int    `json:"users" example:"4"`
+	Subscriptions int    `json:"subscriptions" example:"4"`
+	MonthlyCost   int    `json:"monthly_cost" example:"1600"`
+}
+
+// ConsolidationOpportunity - сервис, который многие пользователи оплачивают по отдельности:
+// кандидат на общую (корпоративную) подписку
+type ConsolidationOpportunity struct {
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	Users       int    `json:"users" example:"4"`
+	// MonthlyCost - сколько организация платит за отдельные подписки в месяц
+	MonthlyCost int `json:"monthly_cost" example:"1600"`
+	// AverageCost - средняя стоимость подписки пользователя в месяц
+	AverageCost int `json:"average_cost" example:"400"`
+}
+
+// TenantOverview - обзор подписок организации за текущий месяц. Приостановленные подписки
+// входят в число подписок, но не в стоимость; черновики не учитываются
+type TenantOverview struct {
+	Tenant         string                     `json:"tenant" example:"default"`
+	GroupBy        string                     `json:"group_by" enums:"user" example:"user"`
+	Month          time.Time                  `json:"month" example:"2025-07-01T00:00:00Z"`
+	MonthlyCost    int                        `json:"monthly_cost" example:"5400"`
+	Users          []UserSpend                `json:"users"`
+	SharedServices []SharedService            `json:"shared_services"`
+	Consolidation  []ConsolidationOpportunity `json:"consolidation"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/google/uuid"
+
+// TeardownRequest - удаление тестовых данных организации (демо- и staging-стенды).
+// Без UserID удаляются данные всей организации
+type TeardownRequest struct {
+	// Confirm - подтверждение: должен совпадать с идентификатором организации
+	Confirm string     `json:"confirm" binding:"required" example:"demo"`
+	UserID  *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	// DryRun считает строки, которые будут удалены, ничего не удаляя
+	DryRun bool `json:"dry_run,omitempty" example:"false"`
+}
+
+// TeardownResult - число удаленных (при DryRun - подлежащих удалению) строк по таблицам
+type TeardownResult struct {
+	Tenant  string           `json:"tenant" example:"demo"`
+	UserID  *uuid.UUID       `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	DryRun  bool             `json:"dry_run" example:"false"`
+	Deleted map[string]int64 `json:"deleted"`
+}
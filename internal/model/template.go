@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	// TemplateSourceDatabase - шаблон сохранен в базе данных
+	TemplateSourceDatabase = "database"
+	// TemplateSourceDefault - встроенный шаблон, используется пока нет версий в базе
+	TemplateSourceDefault = "default"
+)
+
+// EmailTemplate - версия шаблона письма
+type EmailTemplate struct {
+	Name      string    `json:"name" example:"renewal_reminder"`
+	Version   int       `json:"version" example:"3"`
+	Subject   string    `json:"subject" example:"Скоро продление {{.ServiceName}}"`
+	Body      string    `json:"body" example:"<p>Подписка {{.ServiceName}} продлится {{.RenewalDate}}</p>"`
+	Source    string    `json:"source" enums:"database,default" example:"database"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type SaveTemplateRequest struct {
+	Subject string `json:"subject" binding:"required" example:"Скоро продление {{.ServiceName}}"`
+	Body    string `json:"body" binding:"required" example:"<p>Подписка {{.ServiceName}} продлится {{.RenewalDate}}</p>"`
+}
+
+// PreviewTemplateRequest - данные для предпросмотра. Если subject/body не заданы,
+// используется текущая версия шаблона; если не задан data, подставляются примерные данные
+type PreviewTemplateRequest struct {
+	Subject *string                `json:"subject,omitempty"`
+	Body    *string                `json:"body,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+type RenderedTemplate struct {
+	Subject string `json:"subject" example:"Скоро продление Yandex Plus"`
+	Body    string `json:"body" example:"<p>Подписка Yandex Plus продлится 01-08-2025</p>"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "github.com/google/uuid"
+
+// ProvisionTenantRequest - желаемое состояние организации для систем развертывания.
+// Повтор запроса ничего не меняет; сервисы, теги и токены, не перечисленные в запросе,
+// не удаляются
+type ProvisionTenantRequest struct {
+	Name string `json:"name" binding:"required,max=255" example:"Acme Inc"`
+	// Services - сервисы каталога организации; существующие приводятся к описанию из запроса
+	Services []CatalogServiceRequest `json:"services,omitempty" binding:"omitempty,dive"`
+	Tags     []string                `json:"tags,omitempty" example:"work,personal"`
+	// APITokens - личные токены API; токен выпускается, если у пользователя нет токена с таким названием
+	APITokens []ProvisionAPIToken `json:"api_tokens,omitempty" binding:"omitempty,dive"`
+	// Settings - налоговые настройки организации целиком: незаданное поле сбрасывается
+	// к значению из конфигурации. Без блока настройки не меняются
+	Settings *TenantSettings `json:"settings,omitempty"`
+}
+
+// ProvisionAPIToken - личный токен API пользователя организации
+type ProvisionAPIToken struct {
+	UserID uuid.UUID `json:"user_id" binding:"required" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	CreateAPITokenRequest
+}
+
+// ProvisionTenantResult - изменения, сделанные запросом заведения организации
+type ProvisionTenantResult struct {
+	Tenant string `json:"tenant" example:"acme"`
+	Name   string `json:"name" example:"Acme Inc"`
+	// Created - организация заведена этим запросом
+	Created bool `json:"created" example:"true"`
+	// UpdatedSettings - запрос изменил налоговые настройки организации
+	UpdatedSettings bool     `json:"updated_settings" example:"true"`
+	CreatedServices []string `json:"created_services" example:"Yandex Plus"`
+	UpdatedServices []string `json:"updated_services"`
+	CreatedTags     []string `json:"created_tags" example:"work,personal"`
+	// IssuedTokens - токены, выпущенные этим запросом; секреты показываются только здесь
+	IssuedTokens []IssuedAPIToken `json:"issued_tokens"`
+}
+
+// TenantSettings - налоговые настройки организации. Незаданное поле означает значение
+// из конфигурации (TAX_RATE_PERCENT, ROUNDING_MODE)
+type TenantSettings struct {
+	TaxRatePercent *string `json:"tax_rate_percent,omitempty" example:"20"`
+	Rounding       *string `json:"rounding,omitempty" enums:"half_up,half_even" example:"half_even"`
+}
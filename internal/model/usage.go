@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// EndpointUsage - число запросов к эндпоинту за день
+type EndpointUsage struct {
+	Endpoint string `json:"endpoint" example:"GET /api/v1/subscriptions"`
+	Requests int64  `json:"requests" example:"1520"`
+}
+
+// DailyUsage - запросы клиента за день (UTC)
+type DailyUsage struct {
+	Date      string          `json:"date" example:"2025-07-10"`
+	Requests  int64           `json:"requests" example:"1800"`
+	Endpoints []EndpointUsage `json:"endpoints"`
+}
+
+// RateLimitUsage - потребление лимита запросов в текущей минуте. Нулевой limit_per_minute - лимита нет
+type RateLimitUsage struct {
+	LimitPerMinute int       `json:"limit_per_minute" example:"600"`
+	Used           int       `json:"used" example:"42"`
+	Remaining      int       `json:"remaining" example:"558"`
+	ResetAt        time.Time `json:"reset_at" example:"2025-07-10T09:31:00Z"`
+}
+
+type UsageResponse struct {
+	// APIKey - маскированный ключ клиента
+	APIKey    string         `json:"api_key" example:"sk_live_****9f2c"`
+	Days      []DailyUsage   `json:"days"`
+	RateLimit RateLimitUsage `json:"rate_limit"`
+}
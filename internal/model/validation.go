@@ -0,0 +1,29 @@
+package model
+
+import "github.com/google/uuid"
+
+// Коды предупреждений проверки подписки
+const (
+	// WarningOverlap - у пользователя уже есть подписка на этот сервис в пересекающийся период
+	WarningOverlap = "overlap"
+	// WarningExpired - подписка заканчивается раньше текущего месяца и будет создана истекшей
+	WarningExpired = "expired"
+)
+
+// ValidationWarning - замечание к корректному запросу, которое не мешает создать подписку
+type ValidationWarning struct {
+	Code    string `json:"code" enums:"overlap,expired" example:"overlap"`
+	Message string `json:"message" example:"user already has a Yandex Plus subscription for 07-2025 - 12-2025"`
+	// SubscriptionID - подписка, с которой пересекается запрос (для overlap)
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" example:"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11"`
+}
+
+// ValidationResult - результат проверки запроса на создание подписки без сохранения.
+// Normalized - запрос в том виде, в котором подписка будет сохранена: с рассчитанными
+// по предоплате end_date и monthly_cost; его можно отправить в POST /subscriptions как есть
+type ValidationResult struct {
+	Valid      bool                       `json:"valid" example:"true"`
+	Error      string                     `json:"error,omitempty" example:"end date cannot be before start date"`
+	Warnings   []ValidationWarning        `json:"warnings"`
+	Normalized *CreateSubscriptionRequest `json:"normalized,omitempty"`
+}
@@ -0,0 +1,64 @@
+package modifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// maxResponseSize - ограничение ответа сайдкара
+const maxResponseSize = 64 << 10
+
+// HTTPModifier вызывает модификатор, работающий отдельным процессом (сайдкаром).
+// Сервис отправляет POST с Request в JSON; ответ 200 с SummaryAdjustment применяется,
+// 204 означает, что корректировки нет. Поле modifier в ответе заменяется именем модификатора
+type HTTPModifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPModifier(url string, client *http.Client) *HTTPModifier {
+	return &HTTPModifier{url: url, client: client}
+}
+
+func (m *HTTPModifier) Name() string {
+	return m.url
+}
+
+func (m *HTTPModifier) Modify(ctx context.Context, req Request) (*model.SummaryAdjustment, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode modifier request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build modifier request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call modifier %s: %w", m.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("modifier %s returned status %d", m.url, resp.StatusCode)
+	}
+
+	var adjustment model.SummaryAdjustment
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&adjustment); err != nil {
+		return nil, fmt.Errorf("failed to decode modifier %s response: %w", m.url, err)
+	}
+	return &adjustment, nil
+}
@@ -0,0 +1,84 @@
+// Package modifier - точка расширения расчета суммарной стоимости: модификаторы добавляют
+// к итогам /subscriptions/summary корректировки, специфичные для развертывания (скидки
+// компании, распределение затрат между отделами и т.п.)
+package modifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// Request - итоги суммарной стоимости до корректировок. Суммы в рублях, в том виде,
+// в котором хранятся цены (до пересчета налога); уже включают корректировки
+// предыдущих модификаторов цепочки
+type Request struct {
+	Filter        model.SummaryFilter `json:"filter"`
+	ActiveCost    int                 `json:"active_cost"`
+	CancelledCost int                 `json:"cancelled_cost"`
+}
+
+// CostModifier корректирует итоги суммарной стоимости. nil-результат означает,
+// что модификатор к запросу не применяется
+type CostModifier interface {
+	Name() string
+	Modify(ctx context.Context, req Request) (*model.SummaryAdjustment, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]CostModifier{}
+)
+
+// Register добавляет модификатор во встроенный реестр. Вызывается из init() пакета
+// с модификаторами развертывания; включается модификатор переменной SUMMARY_MODIFIERS
+func Register(m CostModifier) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := m.Name()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("modifier: %q registered twice", name))
+	}
+	registry[name] = m
+}
+
+// Registered возвращает имена встроенных модификаторов
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain собирает цепочку модификаторов в заданном порядке. Элемент с префиксом
+// http:// или https:// - адрес сайдкара (см. HTTPModifier), остальные - имена из реестра.
+// client выполняет запросы к сайдкарам
+func Chain(specs []string, client *http.Client) ([]CostModifier, error) {
+	chain := make([]CostModifier, 0, len(specs))
+	for _, spec := range specs {
+		if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+			chain = append(chain, NewHTTPModifier(spec, client))
+			continue
+		}
+
+		registryMu.RLock()
+		m, ok := registry[spec]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown summary modifier %q, registered: %v", spec, Registered())
+		}
+		chain = append(chain, m)
+	}
+	return chain, nil
+}
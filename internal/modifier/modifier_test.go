@@ -0,0 +1,78 @@
+package modifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type fixedModifier struct{}
+
+func (fixedModifier) Name() string { return "test_fixed" }
+
+func (fixedModifier) Modify(ctx context.Context, req Request) (*model.SummaryAdjustment, error) {
+	return &model.SummaryAdjustment{ActiveCost: -100}, nil
+}
+
+func TestChain(t *testing.T) {
+	Register(fixedModifier{})
+
+	var received Request
+	sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if received.ActiveCost == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(model.SummaryAdjustment{CancelledCost: 50, Description: "allocation"})
+	}))
+	defer sidecar.Close()
+
+	chain, err := Chain([]string{"test_fixed", sidecar.URL}, &http.Client{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Chain() error = %v", err)
+	}
+	if len(chain) != 2 || chain[0].Name() != "test_fixed" || chain[1].Name() != sidecar.URL {
+		t.Fatalf("unexpected chain: %v", chain)
+	}
+
+	adjustment, err := chain[1].Modify(context.Background(), Request{Filter: model.SummaryFilter{ServiceName: "Netflix"}, ActiveCost: 400})
+	if err != nil {
+		t.Fatalf("Modify() error = %v", err)
+	}
+	if adjustment == nil || adjustment.CancelledCost != 50 || adjustment.Description != "allocation" {
+		t.Errorf("unexpected adjustment: %+v", adjustment)
+	}
+	if received.Filter.ServiceName != "Netflix" || received.ActiveCost != 400 {
+		t.Errorf("sidecar received %+v", received)
+	}
+
+	// 204 - корректировки нет
+	if adjustment, err := chain[1].Modify(context.Background(), Request{}); err != nil || adjustment != nil {
+		t.Errorf("Modify() = %+v, %v, want no adjustment", adjustment, err)
+	}
+
+	if _, err := Chain([]string{"missing"}, http.DefaultClient); err == nil || !strings.Contains(err.Error(), "test_fixed") {
+		t.Errorf("Chain() error = %v, want unknown modifier with registered names", err)
+	}
+}
+
+func TestHTTPModifierFailure(t *testing.T) {
+	sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer sidecar.Close()
+
+	m := NewHTTPModifier(sidecar.URL, sidecar.Client())
+	if _, err := m.Modify(context.Background(), Request{}); err == nil {
+		t.Error("expected error for failed sidecar")
+	}
+}
@@ -0,0 +1,88 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode определяет правило округления денежных сумм до целых
+type RoundingMode string
+
+const (
+	// RoundHalfUp - математическое округление, половина округляется от нуля
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven - банковское округление, половина округляется к четному
+	RoundHalfEven RoundingMode = "half_even"
+)
+
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch RoundingMode(mode) {
+	case RoundHalfUp, RoundHalfEven:
+		return RoundingMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown rounding mode %q, expected %s or %s", mode, RoundHalfUp, RoundHalfEven)
+	}
+}
+
+// Round округляет рациональное число до целого по выбранному правилу
+func Round(x *big.Rat, mode RoundingMode) int {
+	num := new(big.Int).Set(x.Num())
+	den := x.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return int(quo.Int64())
+	}
+
+	// Сравниваем удвоенный остаток со знаменателем, чтобы понять, где мы относительно половины
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+
+	awayFromZero := false
+	switch twiceRem.Cmp(den) {
+	case 1:
+		awayFromZero = true
+	case 0:
+		if mode == RoundHalfEven {
+			awayFromZero = quo.Bit(0) == 1
+		} else {
+			awayFromZero = true
+		}
+	}
+
+	if awayFromZero {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return int(quo.Int64())
+}
+
+// ParsePercent разбирает ставку в процентах ("20", "5.5") и возвращает долю
+func ParsePercent(percent string) (*big.Rat, error) {
+	if percent == "" {
+		return new(big.Rat), nil
+	}
+
+	rate, ok := new(big.Rat).SetString(percent)
+	if !ok {
+		return nil, fmt.Errorf("invalid percent value %q", percent)
+	}
+	if rate.Sign() < 0 {
+		return nil, fmt.Errorf("percent value cannot be negative: %q", percent)
+	}
+
+	return rate.Quo(rate, big.NewRat(100, 1)), nil
+}
+
+// ParseDecimal разбирает десятичное число из базы данных ("1234.5000") без потери точности
+func ParseDecimal(value string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal value %q", value)
+	}
+	return r, nil
+}
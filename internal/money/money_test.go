@@ -0,0 +1,85 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		halfUp   int
+		halfEven int
+	}{
+		{5, 2, 3, 2},     // 2.5
+		{7, 2, 4, 4},     // 3.5
+		{1, 2, 1, 0},     // 0.5
+		{-1, 2, -1, 0},   // -0.5
+		{-5, 2, -3, -2},  // -2.5
+		{-7, 2, -4, -4},  // -3.5
+		{249, 100, 2, 2}, // 2.49
+		{251, 100, 3, 3}, // 2.51
+		{-251, 100, -3, -3},
+		{-249, 100, -2, -2},
+		{12, 1, 12, 12},
+		{-12, 1, -12, -12},
+		{0, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		x := big.NewRat(tt.num, tt.den)
+		if got := Round(x, RoundHalfUp); got != tt.halfUp {
+			t.Errorf("Round(%s, half_up) = %d, want %d", x.FloatString(2), got, tt.halfUp)
+		}
+		if got := Round(x, RoundHalfEven); got != tt.halfEven {
+			t.Errorf("Round(%s, half_even) = %d, want %d", x.FloatString(2), got, tt.halfEven)
+		}
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []string{"half_up", "half_even"} {
+		if got, err := ParseRoundingMode(mode); err != nil || string(got) != mode {
+			t.Errorf("ParseRoundingMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+	for _, mode := range []string{"", "HALF_UP", "bankers"} {
+		if _, err := ParseRoundingMode(mode); err == nil {
+			t.Errorf("ParseRoundingMode(%q) error = nil, want error", mode)
+		}
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		percent string
+		want    *big.Rat
+		wantErr bool
+	}{
+		{"", new(big.Rat), false},
+		{"20", big.NewRat(1, 5), false},
+		{"5.5", big.NewRat(11, 200), false},
+		{"0", new(big.Rat), false},
+		{"-1", nil, true},
+		{"twenty", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePercent(tt.percent)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePercent(%q) error = %v, wantErr %t", tt.percent, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Cmp(tt.want) != 0 {
+			t.Errorf("ParsePercent(%q) = %s, want %s", tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	got, err := ParseDecimal("1234.5000")
+	if err != nil || got.Cmp(big.NewRat(2469, 2)) != 0 {
+		t.Errorf("ParseDecimal() = %v, %v, want 1234.5", got, err)
+	}
+	if _, err := ParseDecimal("12,5"); err == nil {
+		t.Error("ParseDecimal(\"12,5\") error = nil, want error")
+	}
+}
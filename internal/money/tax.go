@@ -0,0 +1,74 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// AmountType - вид суммы в отчетах: с налогом или без
+type AmountType string
+
+const (
+	AmountGross AmountType = "gross"
+	AmountNet   AmountType = "net"
+)
+
+func ParseAmountType(amount string) (AmountType, error) {
+	switch AmountType(amount) {
+	case AmountGross, AmountNet:
+		return AmountType(amount), nil
+	default:
+		return "", fmt.Errorf("unknown amount type %q, expected %s or %s", amount, AmountGross, AmountNet)
+	}
+}
+
+// Tax описывает налоговую политику для пересчета сумм
+type Tax struct {
+	// Rate - ставка налога в виде доли (0.2 для 20%)
+	Rate *big.Rat
+	// PricesIncludeTax - хранимые стоимости подписок уже включают налог
+	PricesIncludeTax bool
+	Rounding         RoundingMode
+}
+
+func NewTax(ratePercent string, pricesIncludeTax bool, rounding string) (Tax, error) {
+	rate, err := ParsePercent(ratePercent)
+	if err != nil {
+		return Tax{}, fmt.Errorf("invalid tax rate: %w", err)
+	}
+
+	mode, err := ParseRoundingMode(rounding)
+	if err != nil {
+		return Tax{}, err
+	}
+
+	return Tax{
+		Rate:             rate,
+		PricesIncludeTax: pricesIncludeTax,
+		Rounding:         mode,
+	}, nil
+}
+
+// RatePercent возвращает ставку в процентах для вывода в ответах
+func (t Tax) RatePercent() string {
+	if t.Rate == nil {
+		return "0"
+	}
+	return new(big.Rat).Mul(t.Rate, big.NewRat(100, 1)).FloatString(2)
+}
+
+// Split раскладывает хранимую сумму на сумму без налога, налог и сумму с налогом
+func (t Tax) Split(amount int) (net, tax, gross int) {
+	if t.Rate == nil || t.Rate.Sign() == 0 {
+		return amount, 0, amount
+	}
+
+	if t.PricesIncludeTax {
+		divisor := new(big.Rat).Add(big.NewRat(1, 1), t.Rate)
+		net = Round(new(big.Rat).Quo(big.NewRat(int64(amount), 1), divisor), t.Rounding)
+		return net, amount - net, amount
+	}
+
+	tax = Round(new(big.Rat).Mul(big.NewRat(int64(amount), 1), t.Rate), t.Rounding)
+	return amount, tax, amount + tax
+}
@@ -0,0 +1,105 @@
+package money
+
+import (
+	"testing"
+)
+
+func mustTax(t *testing.T, rate string, pricesIncludeTax bool, rounding RoundingMode) Tax {
+	t.Helper()
+	tax, err := NewTax(rate, pricesIncludeTax, string(rounding))
+	if err != nil {
+		t.Fatalf("NewTax(%q) error = %v", rate, err)
+	}
+	return tax
+}
+
+func TestTaxSplit(t *testing.T) {
+	tests := []struct {
+		name                        string
+		rate                        string
+		pricesIncludeTax            bool
+		rounding                    RoundingMode
+		amount                      int
+		wantNet, wantTax, wantGross int
+	}{
+		{"no tax", "", false, RoundHalfUp, 1000, 1000, 0, 1000},
+		{"tax on top", "20", false, RoundHalfUp, 1000, 1000, 200, 1200},
+		{"tax included", "20", true, RoundHalfUp, 1200, 1000, 200, 1200},
+		// 25 * 0.1 = 2.5: половина
+		{"half up on top", "10", false, RoundHalfUp, 25, 25, 3, 28},
+		{"half even on top", "10", false, RoundHalfEven, 25, 25, 2, 27},
+		{"half even on top odd", "10", false, RoundHalfEven, 35, 35, 4, 39},
+		// 105 / 1.05 = 100 ровно
+		{"included exact", "5", true, RoundHalfEven, 105, 100, 5, 105},
+		// 5.5% от 100 = 5.5 и 2.5% от 100 = 2.5: к четному 6 и 2
+		{"fractional rate half up", "5.5", false, RoundHalfUp, 100, 100, 6, 106},
+		{"fractional rate half even", "5.5", false, RoundHalfEven, 100, 100, 6, 106},
+		{"fractional rate half even down", "2.5", false, RoundHalfEven, 100, 100, 2, 102},
+		// Возвраты и корректировки - отрицательные суммы округляются симметрично
+		{"negative on top", "20", false, RoundHalfUp, -1000, -1000, -200, -1200},
+		{"negative half up", "10", false, RoundHalfUp, -25, -25, -3, -28},
+		{"negative half even", "10", false, RoundHalfEven, -25, -25, -2, -27},
+		{"negative included", "20", true, RoundHalfUp, -1200, -1000, -200, -1200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, tax, gross := mustTax(t, tt.rate, tt.pricesIncludeTax, tt.rounding).Split(tt.amount)
+			if net != tt.wantNet || tax != tt.wantTax || gross != tt.wantGross {
+				t.Errorf("Split(%d) = %d, %d, %d, want %d, %d, %d", tt.amount, net, tax, gross, tt.wantNet, tt.wantTax, tt.wantGross)
+			}
+		})
+	}
+}
+
+// Части суммы всегда складываются в сумму с налогом, а хранимая сумма остается одной из частей
+func TestTaxSplitPartsAddUp(t *testing.T) {
+	for _, rate := range []string{"0", "5.5", "10", "18", "20", "33.3333"} {
+		for _, rounding := range []RoundingMode{RoundHalfUp, RoundHalfEven} {
+			for _, included := range []bool{false, true} {
+				tax := mustTax(t, rate, included, rounding)
+				for amount := -1000; amount <= 1000; amount++ {
+					net, taxAmount, gross := tax.Split(amount)
+					if net+taxAmount != gross {
+						t.Fatalf("rate %s %s included=%t: Split(%d) = %d + %d != %d", rate, rounding, included, amount, net, taxAmount, gross)
+					}
+					stored := net
+					if included {
+						stored = gross
+					}
+					if stored != amount {
+						t.Fatalf("rate %s %s included=%t: Split(%d) = %d, %d, %d, want stored amount unchanged", rate, rounding, included, amount, net, taxAmount, gross)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Сумма без налога, пересчитанная в сумму с налогом и обратно, не меняется: ошибка
+// округления налога меньше половины и после деления на 1+ставка тоже
+func TestTaxGrossNetRoundTrip(t *testing.T) {
+	for _, rate := range []string{"5.5", "10", "18", "20", "33.3333"} {
+		for _, rounding := range []RoundingMode{RoundHalfUp, RoundHalfEven} {
+			onTop := mustTax(t, rate, false, rounding)
+			included := mustTax(t, rate, true, rounding)
+			for amount := -1000; amount <= 1000; amount++ {
+				_, _, gross := onTop.Split(amount)
+				if net, _, _ := included.Split(gross); net != amount {
+					t.Fatalf("rate %s %s: net %d -> gross %d -> net %d", rate, rounding, amount, gross, net)
+				}
+			}
+		}
+	}
+}
+
+func TestNewTaxErrors(t *testing.T) {
+	if _, err := NewTax("-5", false, "half_up"); err == nil {
+		t.Error("NewTax() with negative rate error = nil")
+	}
+	if _, err := NewTax("20", false, "ceil"); err == nil {
+		t.Error("NewTax() with unknown rounding error = nil")
+	}
+	if got := mustTax(t, "5.5", false, RoundHalfUp).RatePercent(); got != "5.50" {
+		t.Errorf("RatePercent() = %q, want 5.50", got)
+	}
+}
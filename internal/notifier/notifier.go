@@ -0,0 +1,65 @@
+// Package notifier отправляет письма пользователям через SMTP-сервер или API SendGrid.
+// Драйвер выбирается конфигурацией.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	DriverSMTP     = "smtp"
+	DriverSendGrid = "sendgrid"
+)
+
+// Message - письмо одному получателю; тело - HTML
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Sender отправляет письма
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config - параметры отправки. SMTP* используются драйвером smtp, SendGrid* - sendgrid
+type Config struct {
+	Driver   string
+	From     string
+	FromName string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	SendGridAPIKey string
+	// SendGridURL - адрес API отправки; пустое значение - DefaultSendGridURL
+	SendGridURL string
+
+	// Timeout ограничивает отправку одного письма
+	Timeout time.Duration
+}
+
+// New создает отправителя выбранного драйвера. client выполняет запросы к API SendGrid
+func New(cfg Config, client *http.Client) (Sender, error) {
+	if cfg.From == "" {
+		return nil, fmt.Errorf("email sender address is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		return newSMTPSender(cfg)
+	case DriverSendGrid:
+		return newSendGridSender(cfg, client)
+	default:
+		return nil, fmt.Errorf("unknown email driver %q (expected smtp or sendgrid)", cfg.Driver)
+	}
+}
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendGridSend(t *testing.T) {
+	var got sendGridRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sender, err := New(Config{
+		Driver:         DriverSendGrid,
+		From:           "billing@example.com",
+		FromName:       "Billing",
+		SendGridAPIKey: "secret",
+		SendGridURL:    srv.URL,
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	msg := Message{To: "user@example.com", Subject: "Продление", HTML: "<p>hi</p>"}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if len(got.Personalizations) != 1 || got.Personalizations[0].To[0].Email != msg.To {
+		t.Errorf("unexpected recipients: %+v", got.Personalizations)
+	}
+	if got.From.Email != "billing@example.com" || got.Subject != msg.Subject || got.Content[0].Value != msg.HTML {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestSendGridSendRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	sender, err := New(Config{Driver: DriverSendGrid, From: "billing@example.com", SendGridAPIKey: "bad", SendGridURL: srv.URL}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = sender.Send(context.Background(), Message{To: "user@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Send() error = %v, want status 401", err)
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "no sender", cfg: Config{Driver: DriverSMTP, SMTPHost: "mail.example.com"}},
+		{name: "smtp without host", cfg: Config{Driver: DriverSMTP, From: "a@example.com"}},
+		{name: "sendgrid without key", cfg: Config{Driver: DriverSendGrid, From: "a@example.com"}},
+		{name: "unknown driver", cfg: Config{Driver: "ses", From: "a@example.com"}},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.cfg, nil); err == nil {
+			t.Errorf("%s: New() error = nil", tt.name)
+		}
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	html := strings.Repeat("<p>Подписка продлевается</p>", 10)
+	raw := string(buildMessage(
+		mail.Address{Name: "Billing", Address: "billing@example.com"},
+		Message{To: "user@example.com", Subject: "Продление подписки", HTML: html},
+		time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	))
+
+	header, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no header separator in %q", raw)
+	}
+	for _, want := range []string{
+		`From: "Billing" <billing@example.com>`,
+		"To: <user@example.com>",
+		"Subject: =?UTF-8?q?",
+		"Content-Type: text/html; charset=UTF-8",
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header missing %q:\n%s", want, header)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(body), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("body line longer than 76 characters: %d", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if err != nil || string(decoded) != html {
+		t.Errorf("decoded body = %q, %v", decoded, err)
+	}
+}
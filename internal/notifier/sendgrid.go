@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultSendGridURL - адрес API отправки писем SendGrid
+const DefaultSendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridSender отправляет письма через API SendGrid v3
+type sendGridSender struct {
+	url      string
+	apiKey   string
+	from     string
+	fromName string
+	client   *http.Client
+}
+
+func newSendGridSender(cfg Config, client *http.Client) (Sender, error) {
+	if cfg.SendGridAPIKey == "" {
+		return nil, fmt.Errorf("sendgrid email driver requires api key")
+	}
+	url := cfg.SendGridURL
+	if url == "" {
+		url = DefaultSendGridURL
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	// Таймаут клиента общий для всех вызовов, поэтому отправку ограничивает копия
+	limited := *client
+	limited.Timeout = cfg.Timeout
+
+	return &sendGridSender{
+		url:      url,
+		apiKey:   cfg.SendGridAPIKey,
+		from:     cfg.From,
+		fromName: cfg.FromName,
+		client:   &limited,
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+func (s *sendGridSender) Send(ctx context.Context, msg Message) error {
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: s.from, Name: s.fromName},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: msg.HTML}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build sendgrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send email: sendgrid returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// smtpSender отправляет письма через SMTP-сервер; STARTTLS используется, если сервер
+// его поддерживает, аутентификация - если задан пользователь
+type smtpSender struct {
+	addr     string
+	host     string
+	from     mail.Address
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func newSMTPSender(cfg Config) (Sender, error) {
+	if cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("smtp email driver requires host")
+	}
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	return &smtpSender{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(port)),
+		host:     cfg.SMTPHost,
+		from:     mail.Address{Name: cfg.FromName, Address: cfg.From},
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("failed to authenticate on smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(buildMessage(s.from, msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage собирает письмо MIME: тема в кодировке RFC 2047, HTML-тело в base64
+func buildMessage(from mail.Address, msg Message, date time.Time) []byte {
+	var b bytes.Buffer
+	to := mail.Address{Address: msg.To}
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.HTML))
+	// Строки письма не длиннее 76 символов (RFC 2045)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
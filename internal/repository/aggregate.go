@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Расчеты по подпискам, которые Postgres выполняет функциями без аналогов в SQLite
+// и в памяти процесса (unaccent, pg_trgm, regexp_replace, mode()). Их используют
+// репозитории в памяти и SQLite
+
+// monthlyBase - начисление за месяц без скидок: годовая предоплата делится на 12,
+// стоимость за неделю или год переводится в месяц без округления monthly_cost
+func monthlyBase(monthlyCost int, prepaid *int, period string, cost *int) *big.Rat {
+	if prepaid != nil {
+		return big.NewRat(int64(*prepaid), 12)
+	}
+	if cost != nil {
+		return model.MonthlyRate(period, *cost)
+	}
+	return big.NewRat(int64(monthlyCost), 1)
+}
+
+// rankSearch отбирает подписки, похожие на query, и упорядочивает их, как
+// subscriptionRepo.Search: точное совпадение, начало названия, подстрока, опечатка
+func rankSearch(subs []*model.Subscription, query string, limit int) []*model.Subscription {
+	term := normalizeSearch(query)
+	type match struct {
+		sub        *model.Subscription
+		rank       int
+		similarity float64
+	}
+
+	var matches []match
+	for _, sub := range subs {
+		name := normalizeSearch(sub.ServiceName)
+		similarity := trigramSimilarity(name, term)
+
+		rank := 3
+		switch {
+		case name == term:
+			rank = 0
+		case strings.HasPrefix(name, term):
+			rank = 1
+		case strings.Contains(name, term):
+			rank = 2
+		case similarity < searchSimilarityThreshold:
+			continue
+		}
+		matches = append(matches, match{sub: sub, rank: rank, similarity: similarity})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.similarity != b.similarity {
+			return a.similarity > b.similarity
+		}
+		if !a.sub.CreatedAt.Equal(b.sub.CreatedAt) {
+			return a.sub.CreatedAt.After(b.sub.CreatedAt)
+		}
+		return bytes.Compare(a.sub.ID[:], b.sub.ID[:]) < 0
+	})
+
+	ranked := make([]*model.Subscription, 0, len(matches))
+	for _, m := range matches {
+		ranked = append(ranked, m.sub)
+	}
+	return window(ranked, 0, limit)
+}
+
+// userServices группирует действующие подписки пользователя по названию сервиса.
+// Приостановленные подписки учитываются в количестве, но не в стоимости
+func userServices(subs []*model.Subscription) []model.UserService {
+	byName := make(map[string]*model.UserService)
+	for _, sub := range subs {
+		service, ok := byName[sub.ServiceName]
+		if !ok {
+			service = &model.UserService{ServiceName: sub.ServiceName}
+			byName[sub.ServiceName] = service
+		}
+		service.Subscriptions++
+		if sub.Status != model.StatusPaused {
+			service.MonthlyCost += sub.MonthlyCost
+		}
+	}
+
+	services := []model.UserService{}
+	for _, service := range byName {
+		services = append(services, *service)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].MonthlyCost != services[j].MonthlyCost {
+			return services[i].MonthlyCost > services[j].MonthlyCost
+		}
+		return services[i].ServiceName < services[j].ServiceName
+	})
+	return services
+}
+
+// serviceNameUsage считает пользователей каждого написания названия, нормализованная
+// форма которого входит в normalized
+func serviceNameUsage(subs []*model.Subscription, normalized []string) []model.ServiceNameUsage {
+	wanted := make(map[string]bool, len(normalized))
+	for _, name := range normalized {
+		wanted[name] = true
+	}
+
+	users := make(map[string]map[uuid.UUID]bool)
+	for _, sub := range subs {
+		if !wanted[model.NormalizeServiceName(sub.ServiceName)] {
+			continue
+		}
+		if users[sub.ServiceName] == nil {
+			users[sub.ServiceName] = make(map[uuid.UUID]bool)
+		}
+		users[sub.ServiceName][sub.UserID] = true
+	}
+
+	var usage []model.ServiceNameUsage
+	for name, ids := range users {
+		usage = append(usage, model.ServiceNameUsage{ServiceName: name, Users: len(ids)})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].ServiceName < usage[j].ServiceName })
+	return usage
+}
+
+// userSpend группирует действующие подписки по пользователям, начиная с самых дорогих
+func userSpend(subs []*model.Subscription) []model.UserSpend {
+	type user struct {
+		spend    model.UserSpend
+		services map[string]bool
+	}
+	byUser := make(map[uuid.UUID]*user)
+	for _, sub := range subs {
+		u, ok := byUser[sub.UserID]
+		if !ok {
+			u = &user{spend: model.UserSpend{UserID: sub.UserID}, services: make(map[string]bool)}
+			byUser[sub.UserID] = u
+		}
+		u.spend.Subscriptions++
+		u.services[model.NormalizeServiceName(sub.ServiceName)] = true
+		if sub.Status != model.StatusPaused {
+			u.spend.MonthlyCost += sub.MonthlyCost
+		}
+	}
+
+	spend := []model.UserSpend{}
+	for _, u := range byUser {
+		u.spend.Services = len(u.services)
+		spend = append(spend, u.spend)
+	}
+	sort.Slice(spend, func(i, j int) bool {
+		if spend[i].MonthlyCost != spend[j].MonthlyCost {
+			return spend[i].MonthlyCost > spend[j].MonthlyCost
+		}
+		return bytes.Compare(spend[i].UserID[:], spend[j].UserID[:]) < 0
+	})
+	return spend
+}
+
+// serviceSpend группирует действующие подписки по сервисам (model.NormalizeServiceName),
+// начиная с самых популярных
+func serviceSpend(subs []*model.Subscription) []model.SharedService {
+	type service struct {
+		spend     model.SharedService
+		users     map[uuid.UUID]bool
+		spellings map[string]int
+	}
+	byService := make(map[string]*service)
+	for _, sub := range subs {
+		key := model.NormalizeServiceName(sub.ServiceName)
+		svc, ok := byService[key]
+		if !ok {
+			svc = &service{users: make(map[uuid.UUID]bool), spellings: make(map[string]int)}
+			byService[key] = svc
+		}
+		svc.spend.Subscriptions++
+		svc.users[sub.UserID] = true
+		svc.spellings[sub.ServiceName]++
+		if sub.Status != model.StatusPaused {
+			svc.spend.MonthlyCost += sub.MonthlyCost
+		}
+	}
+
+	services := []model.SharedService{}
+	for _, svc := range byService {
+		// Название - самое частое написание, при равенстве - первое по алфавиту
+		for name, count := range svc.spellings {
+			best := svc.spellings[svc.spend.ServiceName]
+			if count > best || (count == best && name < svc.spend.ServiceName) || svc.spend.ServiceName == "" {
+				svc.spend.ServiceName = name
+			}
+		}
+		svc.spend.Users = len(svc.users)
+		services = append(services, svc.spend)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		a, b := services[i], services[j]
+		if a.Users != b.Users {
+			return a.Users > b.Users
+		}
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost > b.MonthlyCost
+		}
+		return a.ServiceName < b.ServiceName
+	})
+	return services
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type AnalyticsRepository interface {
+	Activity(ctx context.Context, filter model.ActivityFilter) ([]model.ActivityBucket, error)
+	// Retention возвращает по когортам пользователей число сохранивших подписку через
+	// каждый месяц после первой подписки; месяцы без таких пользователей пропускаются
+	Retention(ctx context.Context, filter model.RetentionFilter) ([]model.RetentionCell, error)
+}
+
+type analyticsRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewAnalyticsRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) AnalyticsRepository {
+	return &analyticsRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *analyticsRepo) Activity(ctx context.Context, filter model.ActivityFilter) ([]model.ActivityBucket, error) {
+	// Интервалы без изменений тоже возвращаются, чтобы графики не имели разрывов.
+	// Границы интервалов считаются по местному времени часового пояса периодов ($4):
+	// иначе изменение в первые часы месяца по Москве попадало бы в предыдущий месяц
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($3, $1::timestamptz AT TIME ZONE $4),
+				date_trunc($3, $2::timestamptz AT TIME ZONE $4),
+				('1 ' || $3)::interval
+			) AS start
+		),
+		activity AS (
+			SELECT
+				date_trunc($3, changed_at AT TIME ZONE $4) AS start,
+				COUNT(*) FILTER (WHERE operation = 'create') AS creations,
+				COUNT(*) FILTER (
+					WHERE operation = 'update'
+						AND previous->>'end_date' IS NULL
+						AND payload->>'end_date' IS NOT NULL
+				) AS cancellations,
+				COUNT(*) FILTER (
+					WHERE operation = 'update'
+						AND previous->'monthly_cost' IS DISTINCT FROM payload->'monthly_cost'
+				) AS price_changes,
+				COUNT(*) FILTER (WHERE operation = 'delete') AS deletions
+			FROM subscription_changes
+			WHERE tenant_id = $5
+				AND changed_at >= date_trunc($3, $1::timestamptz AT TIME ZONE $4) AT TIME ZONE $4
+				AND changed_at < (date_trunc($3, $2::timestamptz AT TIME ZONE $4) + ('1 ' || $3)::interval) AT TIME ZONE $4
+			GROUP BY 1
+		)
+		SELECT
+			b.start AT TIME ZONE $4,
+			COALESCE(a.creations, 0),
+			COALESCE(a.cancellations, 0),
+			COALESCE(a.price_changes, 0),
+			COALESCE(a.deletions, 0)
+		FROM buckets b
+		LEFT JOIN activity a ON a.start = b.start
+		ORDER BY b.start
+	`
+
+	r.logger.Debug(ctx, "Aggregating subscription activity in database",
+		"from", filter.From,
+		"to", filter.To,
+		"bucket", filter.Bucket,
+	)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, filter.From, filter.To, filter.Bucket, model.PeriodLocation().String(), ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to aggregate subscription activity in database",
+			"from", filter.From,
+			"to", filter.To,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to aggregate activity: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []model.ActivityBucket{}
+	for rows.Next() {
+		var bucket model.ActivityBucket
+		err := rows.Scan(
+			&bucket.Start,
+			&bucket.Creations,
+			&bucket.Cancellations,
+			&bucket.PriceChanges,
+			&bucket.Deletions,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan activity row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	r.logger.Debug(ctx, "Subscription activity aggregated successfully",
+		"buckets", len(buckets),
+	)
+
+	r.queries.Observe("analytics.activity", len(buckets), time.Since(start))
+
+	return buckets, nil
+}
+
+func (r *analyticsRepo) Retention(ctx context.Context, filter model.RetentionFilter) ([]model.RetentionCell, error) {
+	// Когорта пользователя - месяц его первой подписки (оконная функция по user_id).
+	// Пользователь сохранен в месяце, если у него действует хотя бы одна подписка;
+	// приостановка не считается уходом. Месяцы считаются до $3 - последней когорты
+	query := `
+		WITH subs AS (
+			SELECT
+				user_id,
+				start_date,
+				end_date,
+				MIN(start_date) OVER (PARTITION BY user_id) AS cohort
+			FROM subscriptions
+			WHERE tenant_id = $1
+				AND NOT is_draft
+				AND ($4 = '' OR service_name = $4)
+		),
+		cohorts AS (
+			SELECT cohort, COUNT(DISTINCT user_id) AS users
+			FROM subs
+			WHERE cohort BETWEEN $2 AND $3
+			GROUP BY cohort
+		),
+		retained AS (
+			SELECT s.cohort, m.month, COUNT(DISTINCT s.user_id) AS users
+			FROM subs s
+			CROSS JOIN LATERAL generate_series(
+				s.start_date,
+				LEAST(COALESCE(s.end_date, $3::date), $3::date),
+				interval '1 month'
+			) AS m(month)
+			WHERE s.cohort BETWEEN $2 AND $3
+			GROUP BY s.cohort, m.month
+		)
+		SELECT
+			c.cohort,
+			c.users,
+			((EXTRACT(YEAR FROM r.month) - EXTRACT(YEAR FROM c.cohort)) * 12
+				+ EXTRACT(MONTH FROM r.month) - EXTRACT(MONTH FROM c.cohort))::int,
+			r.users
+		FROM cohorts c
+		JOIN retained r ON r.cohort = c.cohort
+		ORDER BY 1, 3
+	`
+
+	r.logger.Debug(ctx, "Calculating retention cohorts in database",
+		"service_name", filter.ServiceName,
+		"from", filter.From,
+		"to", filter.To,
+	)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, ctxutil.TenantID(ctx), filter.From, filter.To, filter.ServiceName)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate retention cohorts in database",
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate retention: %w", err)
+	}
+	defer rows.Close()
+
+	var cells []model.RetentionCell
+	for rows.Next() {
+		var cell model.RetentionCell
+		if err := rows.Scan(&cell.Cohort, &cell.Users, &cell.Offset, &cell.Retained); err != nil {
+			r.logger.Error(ctx, "Failed to scan retention row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan retention: %w", err)
+		}
+		cells = append(cells, cell)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate retention rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read retention: %w", err)
+	}
+
+	r.queries.Observe("analytics.retention", len(cells), time.Since(start))
+
+	return cells, nil
+}
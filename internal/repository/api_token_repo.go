@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type APITokenRepository interface {
+	// Create сохраняет токен с хешем секрета hash и заполняет CreatedAt
+	Create(ctx context.Context, token *model.APIToken, hash string) error
+	// ListByUser возвращает токены пользователя, начиная с последнего выпущенного
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error)
+	// Delete удаляет токен пользователя; false - такого токена нет
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	// FindByHash ищет токен по хешу секрета во всех организациях: организацию запроса
+	// задает сам токен. nil - токена нет
+	FindByHash(ctx context.Context, hash string) (*model.APIToken, error)
+}
+
+type apiTokenRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewAPITokenRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) APITokenRepository {
+	return &apiTokenRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *apiTokenRepo) Create(ctx context.Context, token *model.APIToken, hash string) error {
+	query := `
+		INSERT INTO api_tokens (id, tenant_id, user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	token.Tenant = ctxutil.TenantID(ctx)
+	start := time.Now()
+	err := r.db.QueryRowContext(ctx, query,
+		token.ID,
+		token.Tenant,
+		token.UserID,
+		token.Name,
+		hash,
+		token.Scopes,
+		token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to create API token in database",
+			"user_id", token.UserID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	r.queries.Observe("api_tokens.create", 1, time.Since(start))
+	return nil
+}
+
+func (r *apiTokenRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error) {
+	query := `
+		SELECT id, tenant_id, user_id, name, scopes, created_at, expires_at
+		FROM api_tokens
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY created_at DESC, id
+	`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, ctxutil.TenantID(ctx), userID)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list API tokens from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate API tokens: %w", err)
+	}
+	r.queries.Observe("api_tokens.list", len(tokens), time.Since(start))
+	return tokens, nil
+}
+
+func (r *apiTokenRepo) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	query := `DELETE FROM api_tokens WHERE tenant_id = $1 AND user_id = $2 AND id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, ctxutil.TenantID(ctx), userID, id)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete API token from database",
+			"user_id", userID,
+			"token_id", id,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to delete API token: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *apiTokenRepo) FindByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	query := `
+		SELECT id, tenant_id, user_id, name, scopes, created_at, expires_at
+		FROM api_tokens
+		WHERE token_hash = $1
+	`
+
+	start := time.Now()
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to find API token in database", "error", err)
+		return nil, fmt.Errorf("failed to find API token: %w", err)
+	}
+	r.queries.Observe("api_tokens.find", 1, time.Since(start))
+	return token, nil
+}
+
+func scanAPIToken(row rowScanner) (*model.APIToken, error) {
+	var token model.APIToken
+	err := row.Scan(
+		&token.ID,
+		&token.Tenant,
+		&token.UserID,
+		&token.Name,
+		// Массив text[] database/sql читает через типы pgx; Map не потокобезопасен
+		pgtype.NewMap().SQLScanner(&token.Scopes),
+		&token.CreatedAt,
+		&token.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type AuditRepository interface {
+	// List возвращает записи журнала аудита организации, начиная с последних
+	List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+// auditFilterColumns - колонки audit_log, доступные для фильтрации
+var auditFilterColumns = newColumnSet(
+	"tenant_id",
+	"id",
+	"entity_id",
+	"user_id",
+	"actor",
+	"action",
+	"request_id",
+	"created_at",
+)
+
+type auditRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewAuditRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) AuditRepository {
+	return &auditRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *auditRepo) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	query := `
+		SELECT id, entity_type, entity_id, user_id, action, COALESCE(actor, ''), COALESCE(request_id, ''), before, after, created_at
+		FROM audit_log
+		WHERE 1=1
+	`
+
+	where := newWhereBuilder(auditFilterColumns)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.EntityID != nil {
+		where.Where("entity_id", opEq, *filter.EntityID)
+	}
+	if filter.UserID != nil {
+		where.Where("user_id", opEq, *filter.UserID)
+	}
+	if filter.Actor != "" {
+		where.Where("actor", opEq, filter.Actor)
+	}
+	if filter.Action != "" {
+		where.Where("action", opEq, filter.Action)
+	}
+	if filter.RequestID != "" {
+		where.Where("request_id", opEq, filter.RequestID)
+	}
+	if filter.Since != nil {
+		where.Where("created_at", opGte, *filter.Since)
+	}
+	if filter.Until != nil {
+		where.Where("created_at", opLt, *filter.Until)
+	}
+	if filter.BeforeID > 0 {
+		where.Where("id", opLt, filter.BeforeID)
+	}
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build audit log filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	// id растет вместе со временем записи, поэтому порядок по нему совпадает с хронологическим
+	query = appendConditions(query, conditions) + " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	r.logger.Debug(ctx, "Executing dynamic query",
+		"query", query,
+		"args_count", len(args),
+	)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list audit log from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var entry model.AuditEntry
+		var before, after []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.Actor,
+			&entry.RequestID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan audit log row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit log: %w", err)
+	}
+
+	r.queries.Observe("audit_log.list", len(entries), time.Since(start))
+
+	return entries, nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type BackupRepository interface {
+	// Dump пишет строки таблиц tables в w в формате NDJSON ({"table": ..., "row": {...}})
+	// из одного снимка базы и возвращает момент, позицию WAL и число строк снимка
+	Dump(ctx context.Context, tables []string, w io.Writer) (*model.BackupSnapshot, error)
+	// Create записывает начатую копию и заполняет ID и StartedAt
+	Create(ctx context.Context, backup *model.Backup) error
+	// Finish сохраняет состояние и результат копии
+	Finish(ctx context.Context, backup *model.Backup) error
+	// GetByID возвращает копию или nil, если ее нет
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Backup, error)
+	// List возвращает последние limit копий, начиная с новых
+	List(ctx context.Context, limit int) ([]*model.Backup, error)
+	// ListExpired возвращает завершенные копии, срок хранения которых истек к now
+	ListExpired(ctx context.Context, now time.Time) ([]*model.Backup, error)
+	// MarkExpired отмечает копию удаленной политикой хранения
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+}
+
+const backupColumns = `id, blob_key, status, started_at, finished_at, snapshot_at, wal_lsn, table_rows, size_bytes, sha256, error, expires_at`
+
+type backupRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewBackupRepository(db *sql.DB, logger *logger.Logger) BackupRepository {
+	return &backupRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *backupRepo) Dump(ctx context.Context, tables []string, w io.Writer) (*model.BackupSnapshot, error) {
+	// Все таблицы читаются из снимка одной транзакции, поэтому копия согласована
+	// на момент ее начала без блокировки записи
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := &model.BackupSnapshot{Tables: make(map[string]int64, len(tables))}
+	err = tx.QueryRowContext(ctx, `
+		SELECT now(), (CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END)::text
+	`).Scan(&snapshot.At, &snapshot.WALPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot position: %w", err)
+	}
+
+	out := bufio.NewWriter(w)
+	for _, table := range tables {
+		rows, err := r.dumpTable(ctx, tx, table, out)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to dump table",
+				"table", table,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to dump %s: %w", table, err)
+		}
+		snapshot.Tables[table] = rows
+	}
+	if err := out.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return snapshot, nil
+}
+
+// dumpTable пишет строки таблицы в w и возвращает их число
+func (r *backupRepo) dumpTable(ctx context.Context, tx *sql.Tx, table string, w io.Writer) (int64, error) {
+	name, err := json.Marshal(table)
+	if err != nil {
+		return 0, err
+	}
+	prefix := `{"table":` + string(name) + `,"row":`
+
+	rows, err := tx.QueryContext(ctx, `SELECT row_to_json(t)::text FROM `+pgx.Identifier{table}.Sanitize()+` t`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	var row string
+	for rows.Next() {
+		if err := rows.Scan(&row); err != nil {
+			return 0, err
+		}
+		if _, err := io.WriteString(w, prefix+row+"}\n"); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+func (r *backupRepo) Create(ctx context.Context, backup *model.Backup) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO backups (id, blob_key, status)
+		VALUES ($1, $2, $3)
+		RETURNING started_at
+	`, backup.ID, backup.Key, backup.Status).Scan(&backup.StartedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to record backup",
+			"backup_id", backup.ID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to record backup: %w", err)
+	}
+	return nil
+}
+
+func (r *backupRepo) Finish(ctx context.Context, backup *model.Backup) error {
+	// Незавершенная копия сохраняется без table_rows (NULL)
+	var tables interface{}
+	if backup.Tables != nil {
+		encoded, err := json.Marshal(backup.Tables)
+		if err != nil {
+			return fmt.Errorf("failed to encode table rows: %w", err)
+		}
+		tables = string(encoded)
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE backups
+		SET status = $1, finished_at = NOW(), snapshot_at = $2, wal_lsn = $3, table_rows = $4,
+			size_bytes = $5, sha256 = $6, error = $7, expires_at = $8
+		WHERE id = $9
+		RETURNING finished_at
+	`,
+		backup.Status,
+		backup.SnapshotAt,
+		backup.WALPosition,
+		tables,
+		backup.SizeBytes,
+		backup.SHA256,
+		backup.Error,
+		backup.ExpiresAt,
+		backup.ID,
+	).Scan(&backup.FinishedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("backup %w", ErrNotFound)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to finish backup record",
+			"backup_id", backup.ID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return nil
+}
+
+func (r *backupRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Backup, error) {
+	backup, err := scanBackup(r.db.QueryRowContext(ctx, `SELECT `+backupColumns+` FROM backups WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get backup from database",
+			"backup_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	return backup, nil
+}
+
+func (r *backupRepo) List(ctx context.Context, limit int) ([]*model.Backup, error) {
+	return r.list(ctx, `SELECT `+backupColumns+` FROM backups ORDER BY started_at DESC LIMIT $1`, limit)
+}
+
+func (r *backupRepo) ListExpired(ctx context.Context, now time.Time) ([]*model.Backup, error) {
+	return r.list(ctx, `
+		SELECT `+backupColumns+`
+		FROM backups
+		WHERE status = 'completed' AND expires_at <= $1
+		ORDER BY expires_at
+	`, now)
+}
+
+func (r *backupRepo) list(ctx context.Context, query string, args ...interface{}) ([]*model.Backup, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list backups from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	defer rows.Close()
+
+	backups := []*model.Backup{}
+	for rows.Next() {
+		backup, err := scanBackup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backup: %w", err)
+		}
+		backups = append(backups, backup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate backups: %w", err)
+	}
+	return backups, nil
+}
+
+func (r *backupRepo) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE backups SET status = 'expired' WHERE id = $1`, id); err != nil {
+		r.logger.Error(ctx, "Failed to mark backup expired",
+			"backup_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to mark backup expired: %w", err)
+	}
+	return nil
+}
+
+// scanBackup читает копию в порядке backupColumns
+func scanBackup(row rowScanner) (*model.Backup, error) {
+	var backup model.Backup
+	var tables []byte
+	err := row.Scan(
+		&backup.ID,
+		&backup.Key,
+		&backup.Status,
+		&backup.StartedAt,
+		&backup.FinishedAt,
+		&backup.SnapshotAt,
+		&backup.WALPosition,
+		&tables,
+		&backup.SizeBytes,
+		&backup.SHA256,
+		&backup.Error,
+		&backup.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tables != nil {
+		if err := json.Unmarshal(tables, &backup.Tables); err != nil {
+			return nil, fmt.Errorf("failed to decode table rows: %w", err)
+		}
+	}
+	return &backup, nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type CatalogRepository interface {
+	// Create сохраняет сервис и заполняет ID, CreatedAt и UpdatedAt
+	Create(ctx context.Context, service *model.CatalogService) error
+	// GetByID возвращает сервис или nil, если его нет
+	GetByID(ctx context.Context, id uuid.UUID) (*model.CatalogService, error)
+	// Update изменяет сервис и заполняет CreatedAt и UpdatedAt
+	Update(ctx context.Context, id uuid.UUID, service *model.CatalogService) error
+	// Delete удаляет сервис; подписки, ссылавшиеся на него, остаются без service_id
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter model.CatalogFilter) ([]*model.CatalogService, error)
+}
+
+const catalogColumns = `id, name, category, default_monthly_cost, icon_url, created_at, updated_at`
+
+// catalogFilterColumns - колонки services, доступные для фильтрации
+var catalogFilterColumns = newColumnSet(
+	"tenant_id",
+	"category",
+)
+
+type catalogRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewCatalogRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) CatalogRepository {
+	return &catalogRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *catalogRepo) Create(ctx context.Context, service *model.CatalogService) error {
+	query := `
+		INSERT INTO services (name, category, default_monthly_cost, icon_url, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	r.logger.Info(ctx, "Creating catalog service in database",
+		"name", service.Name,
+	)
+
+	err := r.db.QueryRowContext(ctx, query,
+		service.Name,
+		service.Category,
+		service.DefaultMonthlyCost,
+		service.IconURL,
+		ctxutil.TenantID(ctx),
+	).Scan(&service.ID, &service.CreatedAt, &service.UpdatedAt)
+	if err != nil {
+		if pgErr, ok := asPgError(err); ok && pgErr.Code == uniqueViolation {
+			r.logger.Warn(ctx, "Catalog service already exists",
+				"name", service.Name,
+			)
+			return fmt.Errorf("service already exists: %w", ErrConflict)
+		}
+		r.logger.Error(ctx, "Failed to create catalog service in database",
+			"name", service.Name,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+
+	r.logger.Info(ctx, "Catalog service created successfully",
+		"service_id", service.ID,
+	)
+	return nil
+}
+
+func (r *catalogRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.CatalogService, error) {
+	query := `
+		SELECT ` + catalogColumns + `
+		FROM services
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	r.logger.Debug(ctx, "Getting catalog service from database",
+		"service_id", id,
+	)
+
+	service, err := scanCatalogService(r.db.QueryRowContext(ctx, query, id, ctxutil.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		r.logger.Debug(ctx, "Catalog service not found in database",
+			"service_id", id,
+		)
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get catalog service from database",
+			"service_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	return service, nil
+}
+
+func (r *catalogRepo) Update(ctx context.Context, id uuid.UUID, service *model.CatalogService) error {
+	query := `
+		UPDATE services
+		SET name = $1, category = $2, default_monthly_cost = $3, icon_url = $4, updated_at = NOW()
+		WHERE id = $5 AND tenant_id = $6
+		RETURNING created_at, updated_at
+	`
+
+	r.logger.Info(ctx, "Updating catalog service in database",
+		"service_id", id,
+	)
+
+	err := r.db.QueryRowContext(ctx, query,
+		service.Name,
+		service.Category,
+		service.DefaultMonthlyCost,
+		service.IconURL,
+		id,
+		ctxutil.TenantID(ctx),
+	).Scan(&service.CreatedAt, &service.UpdatedAt)
+	if err == sql.ErrNoRows {
+		r.logger.Warn(ctx, "Catalog service not found for update",
+			"service_id", id,
+		)
+		return fmt.Errorf("service %w", ErrNotFound)
+	}
+	if err != nil {
+		if pgErr, ok := asPgError(err); ok && pgErr.Code == uniqueViolation {
+			r.logger.Warn(ctx, "Catalog service name already taken",
+				"service_id", id,
+				"name", service.Name,
+			)
+			return fmt.Errorf("service already exists: %w", ErrConflict)
+		}
+		r.logger.Error(ctx, "Failed to update catalog service in database",
+			"service_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to update service: %w", err)
+	}
+
+	service.ID = id
+	return nil
+}
+
+func (r *catalogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM services WHERE id = $1 AND tenant_id = $2`
+
+	r.logger.Info(ctx, "Deleting catalog service from database",
+		"service_id", id,
+	)
+
+	result, err := r.db.ExecContext(ctx, query, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete catalog service from database",
+			"service_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"service_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Catalog service not found for deletion",
+			"service_id", id,
+		)
+		return fmt.Errorf("service %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *catalogRepo) List(ctx context.Context, filter model.CatalogFilter) ([]*model.CatalogService, error) {
+	query := `
+		SELECT ` + catalogColumns + `
+		FROM services
+		WHERE 1=1
+	`
+
+	where := newWhereBuilder(catalogFilterColumns)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.Category != nil {
+		where.Where("category", opEq, *filter.Category)
+	}
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build catalog filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	query = appendConditions(query, conditions) + " ORDER BY lower(name)"
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list catalog services from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []*model.CatalogService{}
+	for rows.Next() {
+		service, err := scanCatalogService(rows)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan catalog service row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan service: %w", err)
+		}
+		services = append(services, service)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate services: %w", err)
+	}
+
+	r.queries.Observe("services.list", len(services), time.Since(start))
+
+	return services, nil
+}
+
+// scanCatalogService читает сервис в порядке catalogColumns
+func scanCatalogService(row rowScanner) (*model.CatalogService, error) {
+	var service model.CatalogService
+	err := row.Scan(
+		&service.ID,
+		&service.Name,
+		&service.Category,
+		&service.DefaultMonthlyCost,
+		&service.IconURL,
+		&service.CreatedAt,
+		&service.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
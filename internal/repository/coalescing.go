@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// CoalescedGetByID - имя операции GetByID в метрике объединенных запросов
+const CoalescedGetByID = "subscriptions.get_by_id"
+
+// coalescingSubscriptionRepo объединяет одновременные GetByID одной подписки в один
+// запрос к базе. Остальные методы передаются next без изменений
+type coalescingSubscriptionRepo struct {
+	SubscriptionRepository
+
+	group     singleflight.Group
+	coalesced *metrics.Coalesced
+	logger    *logger.Logger
+}
+
+// NewCoalescingSubscriptionRepository оборачивает next: запросы GetByID с тем же ID
+// в той же организации, пришедшие во время выполнения первого, получают его результат
+func NewCoalescingSubscriptionRepository(next SubscriptionRepository, coalesced *metrics.Coalesced, logger *logger.Logger) SubscriptionRepository {
+	return &coalescingSubscriptionRepo{
+		SubscriptionRepository: next,
+		coalesced:              coalesced,
+		logger:                 logger,
+	}
+}
+
+func (r *coalescingSubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	// Организация входит в ключ: одинаковый ID в разных организациях - разные запросы
+	key := ctxutil.TenantID(ctx) + "/" + id.String()
+
+	// leader выставляет только вызов, выполняющий запрос; остальные участники
+	// с shared=true получили его результат
+	leader := false
+	result, err, shared := r.group.Do(key, func() (interface{}, error) {
+		leader = true
+		// Результат получат все ожидающие, поэтому отмена запроса первого из них
+		// не должна прерывать запрос остальных; время ограничивает statement_timeout
+		return r.SubscriptionRepository.GetByID(context.WithoutCancel(ctx), id)
+	})
+	coalesced := shared && !leader
+	if coalesced {
+		r.coalesced.Inc(CoalescedGetByID)
+		r.logger.Debug(ctx, "Subscription lookup coalesced", "subscription_id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sub := result.(*model.Subscription)
+	if sub == nil || !coalesced {
+		return sub, nil
+	}
+	// Каждый получатель общего результата работает со своей копией
+	copied := *sub
+	return &copied, nil
+}
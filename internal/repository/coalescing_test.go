@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// slowGetRepo отвечает на GetByID после release и считает обращения
+type slowGetRepo struct {
+	SubscriptionRepository
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *slowGetRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	r.calls.Add(1)
+	<-r.release
+	return &model.Subscription{ID: id, ServiceName: "Netflix"}, nil
+}
+
+func TestCoalescingGetByID(t *testing.T) {
+	next := &slowGetRepo{release: make(chan struct{})}
+	coalesced := metrics.NewCoalesced()
+	repo := NewCoalescingSubscriptionRepository(next, coalesced, logger.New(slog.LevelError+4))
+	id := uuid.New()
+
+	const callers = 10
+	results := make([]*model.Subscription, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sub, err := repo.GetByID(context.Background(), id)
+			if err != nil {
+				t.Errorf("GetByID: %v", err)
+			}
+			results[i] = sub
+		}(i)
+	}
+
+	// Даем остальным вызовам встать в ожидание первого запроса
+	for next.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	calls, shared := int64(next.calls.Load()), coalesced.Count(CoalescedGetByID)
+	if shared == 0 || calls+shared != callers {
+		t.Errorf("repository calls = %d, coalesced = %d, want one call for %d callers", calls, shared, callers)
+	}
+	seen := make(map[*model.Subscription]bool)
+	for i, sub := range results {
+		if sub == nil || sub.ID != id || seen[sub] {
+			t.Fatalf("caller %d got shared or wrong subscription %+v", i, sub)
+		}
+		seen[sub] = true
+	}
+}
+
+func TestCoalescingGetByIDSeparatesTenants(t *testing.T) {
+	next := &slowGetRepo{release: make(chan struct{})}
+	repo := NewCoalescingSubscriptionRepository(next, metrics.NewCoalesced(), logger.New(slog.LevelError+4))
+	id := uuid.New()
+
+	var wg sync.WaitGroup
+	for _, tenantID := range []string{"first", "second"} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if _, err := repo.GetByID(ctx, id); err != nil {
+				t.Errorf("GetByID: %v", err)
+			}
+		}(ctxutil.WithTenantID(context.Background(), tenantID))
+	}
+
+	// Оба запроса должны дойти до репозитория, пока ни один не завершен
+	deadline := time.Now().Add(time.Second)
+	for next.calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(next.release)
+	wg.Wait()
+
+	if got := next.calls.Load(); got != 2 {
+		t.Errorf("repository calls = %d, want 2", got)
+	}
+}
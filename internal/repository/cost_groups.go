@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// costAccumulator складывает стоимость подписок в итоги и, при группировке,
+// в группы по значению метки или названию сервиса
+type costAccumulator struct {
+	totals  *model.CostTotals
+	grouped bool
+	groups  map[string]*model.CostGroup
+	// missing - группа подписок без метки
+	missing *model.CostGroup
+}
+
+func newCostAccumulator(grouped bool) *costAccumulator {
+	return &costAccumulator{
+		totals:  &model.CostTotals{Total: new(big.Rat), Active: new(big.Rat), Cancelled: new(big.Rat)},
+		grouped: grouped,
+		groups:  make(map[string]*model.CostGroup),
+	}
+}
+
+// add добавляет стоимость подписок группы key за months месяцев к активным и закончившимся
+func (a *costAccumulator) add(key *string, active, cancelled *big.Rat, months int) {
+	a.totals.Total.Add(a.totals.Total, active).Add(a.totals.Total, cancelled)
+	a.totals.Active.Add(a.totals.Active, active)
+	a.totals.Cancelled.Add(a.totals.Cancelled, cancelled)
+	if !a.grouped {
+		return
+	}
+
+	group := a.missing
+	if key != nil {
+		group = a.groups[*key]
+	}
+	if group == nil {
+		group = &model.CostGroup{Key: key, Total: new(big.Rat), Active: new(big.Rat), Cancelled: new(big.Rat)}
+		if key != nil {
+			a.groups[*key] = group
+		} else {
+			a.missing = group
+		}
+	}
+	group.Total.Add(group.Total, active).Add(group.Total, cancelled)
+	group.Active.Add(group.Active, active)
+	group.Cancelled.Add(group.Cancelled, cancelled)
+	group.Months += months
+}
+
+// addCost добавляет стоимость одной подписки за months месяцев: отмененные подписки
+// и подписки, закончившиеся до текущего месяца, считаются отмененными
+func (a *costAccumulator) addCost(key *string, cost *big.Rat, months int, ended bool) {
+	if ended {
+		a.add(key, new(big.Rat), cost, months)
+	} else {
+		a.add(key, cost, new(big.Rat), months)
+	}
+}
+
+// result возвращает итоги с группами по возрастанию значения метки или названия сервиса;
+// группа подписок без метки - последняя
+func (a *costAccumulator) result() *model.CostTotals {
+	if !a.grouped {
+		return a.totals
+	}
+
+	a.totals.Groups = make([]model.CostGroup, 0, len(a.groups)+1)
+	for _, group := range a.groups {
+		a.totals.Groups = append(a.totals.Groups, *group)
+	}
+	sort.Slice(a.totals.Groups, func(i, j int) bool {
+		return *a.totals.Groups[i].Key < *a.totals.Groups[j].Key
+	})
+	if a.missing != nil {
+		a.totals.Groups = append(a.totals.Groups, *a.missing)
+	}
+	return a.totals
+}
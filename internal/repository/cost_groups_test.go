@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestCalculateTotalCostGroupedByMetadata(t *testing.T) {
+	repos := map[string]func(t *testing.T) SubscriptionRepository{
+		"memory": func(t *testing.T) SubscriptionRepository { return NewInMemorySubscriptionRepository() },
+		"sqlite": newSQLiteRepo,
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo(t)
+			current := model.CurrentMonth()
+			start := current.AddDate(0, -1, 0)
+			ended := start
+			userID := uuid.New()
+
+			subs := []*model.Subscription{
+				// 2 месяца по 100
+				{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start, Metadata: model.Metadata{"project": "apollo"}},
+				// Закончилась в прошлом месяце: 1 месяц по 30
+				{ServiceName: "Spotify", MonthlyCost: 30, UserID: userID, StartDate: start, EndDate: &ended, Metadata: model.Metadata{"project": "apollo", "team": "web"}},
+				// 2 месяца по 50
+				{ServiceName: "Zoom", MonthlyCost: 50, UserID: userID, StartDate: start, Metadata: model.Metadata{"project": "gemini"}},
+				// Без метки: 2 месяца по 7
+				{ServiceName: "iCloud", MonthlyCost: 7, UserID: userID, StartDate: start},
+			}
+			for _, sub := range subs {
+				if err := repo.Create(ctx, sub); err != nil {
+					t.Fatalf("failed to create subscription: %v", err)
+				}
+			}
+
+			stored, err := repo.GetByID(ctx, subs[1].ID)
+			if err != nil || stored == nil {
+				t.Fatalf("GetByID: %v, %v", stored, err)
+			}
+			if stored.Metadata["team"] != "web" || len(stored.Metadata) != 2 {
+				t.Errorf("stored metadata = %v", stored.Metadata)
+			}
+
+			totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+				StartPeriod: start.Format("01-2006"),
+				EndPeriod:   current.Format("01-2006"),
+				GroupBy:     "metadata.project",
+			})
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+
+			if got := totals.Total.RatString(); got != "344" {
+				t.Errorf("total = %s, want 344", got)
+			}
+			want := []struct {
+				key                      string
+				total, active, cancelled string
+			}{
+				{"apollo", "230", "200", "30"},
+				{"gemini", "100", "100", "0"},
+				{"", "14", "14", "0"},
+			}
+			if len(totals.Groups) != len(want) {
+				t.Fatalf("groups = %d, want %d", len(totals.Groups), len(want))
+			}
+			for i, w := range want {
+				g := totals.Groups[i]
+				key := ""
+				if g.Key != nil {
+					key = *g.Key
+				}
+				if key != w.key || g.Total.RatString() != w.total || g.Active.RatString() != w.active || g.Cancelled.RatString() != w.cancelled {
+					t.Errorf("group %d = %s %s/%s/%s, want %+v", i, key, g.Total.RatString(), g.Active.RatString(), g.Cancelled.RatString(), w)
+				}
+			}
+			if totals.Groups[2].Key != nil {
+				t.Errorf("last group key = %q, want nil", *totals.Groups[2].Key)
+			}
+
+			// Метки заменяются целиком при изменении подписки
+			update := *subs[2]
+			update.Metadata = nil
+			if err := repo.Update(ctx, update.ID, &update); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			totals, err = repo.CalculateTotalCost(ctx, model.SummaryFilter{
+				StartPeriod: start.Format("01-2006"),
+				EndPeriod:   current.Format("01-2006"),
+				GroupBy:     "metadata.project",
+			})
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+			if len(totals.Groups) != 2 || totals.Groups[1].Total.RatString() != "114" {
+				t.Errorf("groups after update = %+v", totals.Groups)
+			}
+		})
+	}
+}
+
+func TestCalculateTotalCostGroupedByService(t *testing.T) {
+	repos := map[string]func(t *testing.T) SubscriptionRepository{
+		"memory": func(t *testing.T) SubscriptionRepository { return NewInMemorySubscriptionRepository() },
+		"sqlite": newSQLiteRepo,
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo(t)
+			current := model.CurrentMonth()
+			start := current.AddDate(0, -2, 0)
+			userID := uuid.New()
+
+			subs := []*model.Subscription{
+				// Две подписки на один сервис: 3 + 2 месяца по 100
+				{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start},
+				{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: current.AddDate(0, -1, 0)},
+				// 3 месяца по 20
+				{ServiceName: "Spotify", MonthlyCost: 20, UserID: userID, StartDate: start},
+			}
+			for _, sub := range subs {
+				if err := repo.Create(ctx, sub); err != nil {
+					t.Fatalf("failed to create subscription: %v", err)
+				}
+			}
+
+			totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+				StartPeriod: start.Format("01-2006"),
+				EndPeriod:   current.Format("01-2006"),
+				GroupBy:     model.SummaryGroupByService,
+			})
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+
+			if got := totals.Total.RatString(); got != "560" {
+				t.Errorf("total = %s, want 560", got)
+			}
+			want := []struct {
+				key    string
+				total  string
+				months int
+			}{
+				{"Netflix", "500", 5},
+				{"Spotify", "60", 3},
+			}
+			if len(totals.Groups) != len(want) {
+				t.Fatalf("groups = %d, want %d", len(totals.Groups), len(want))
+			}
+			for i, w := range want {
+				g := totals.Groups[i]
+				if g.Key == nil || *g.Key != w.key || g.Total.RatString() != w.total || g.Months != w.months {
+					t.Errorf("group %d = %v %s/%d, want %+v", i, g.Key, g.Total.RatString(), g.Months, w)
+				}
+			}
+		})
+	}
+}
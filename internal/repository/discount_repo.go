@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type DiscountRepository interface {
+	// Create сохраняет скидку и заполняет ID и CreatedAt
+	Create(ctx context.Context, discount *model.Discount) error
+	// GetByID возвращает скидку или nil, если ее нет
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Discount, error)
+	Update(ctx context.Context, id uuid.UUID, discount *model.Discount) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter model.DiscountFilter) ([]*model.Discount, error)
+}
+
+// discountFilterColumns - колонки discounts, доступные для фильтрации
+var discountFilterColumns = newColumnSet(
+	"tenant_id",
+	"subscription_id",
+	"user_id",
+)
+
+type discountRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewDiscountRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) DiscountRepository {
+	return &discountRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *discountRepo) Create(ctx context.Context, discount *model.Discount) error {
+	query := `
+		INSERT INTO discounts (kind, value, subscription_id, user_id, promo_code, start_date, end_date, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	r.logger.Info(ctx, "Creating discount in database",
+		"kind", discount.Kind,
+		"subscription_id", discount.SubscriptionID,
+		"user_id", discount.UserID,
+	)
+
+	err := r.db.QueryRowContext(ctx, query,
+		discount.Kind,
+		discount.Value,
+		discount.SubscriptionID,
+		discount.UserID,
+		discount.PromoCode,
+		discount.StartDate,
+		discount.EndDate,
+		ctxutil.TenantID(ctx),
+	).Scan(&discount.ID, &discount.CreatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to create discount in database",
+			"error", err,
+		)
+		return fmt.Errorf("failed to create discount: %w", err)
+	}
+
+	r.logger.Info(ctx, "Discount created successfully",
+		"discount_id", discount.ID,
+	)
+	return nil
+}
+
+func (r *discountRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Discount, error) {
+	query := `
+		SELECT id, kind, value, subscription_id, user_id, promo_code, start_date, end_date, created_at
+		FROM discounts
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	r.logger.Debug(ctx, "Getting discount from database",
+		"discount_id", id,
+	)
+
+	var discount model.Discount
+	err := r.db.QueryRowContext(ctx, query, id, ctxutil.TenantID(ctx)).Scan(
+		&discount.ID,
+		&discount.Kind,
+		&discount.Value,
+		&discount.SubscriptionID,
+		&discount.UserID,
+		&discount.PromoCode,
+		&discount.StartDate,
+		&discount.EndDate,
+		&discount.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		r.logger.Debug(ctx, "Discount not found in database",
+			"discount_id", id,
+		)
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get discount from database",
+			"discount_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get discount: %w", err)
+	}
+
+	return &discount, nil
+}
+
+func (r *discountRepo) Update(ctx context.Context, id uuid.UUID, discount *model.Discount) error {
+	query := `
+		UPDATE discounts
+		SET kind = $1, value = $2, subscription_id = $3, user_id = $4, promo_code = $5, start_date = $6, end_date = $7
+		WHERE id = $8 AND tenant_id = $9
+	`
+
+	r.logger.Info(ctx, "Updating discount in database",
+		"discount_id", id,
+	)
+
+	result, err := r.db.ExecContext(ctx, query,
+		discount.Kind,
+		discount.Value,
+		discount.SubscriptionID,
+		discount.UserID,
+		discount.PromoCode,
+		discount.StartDate,
+		discount.EndDate,
+		id,
+		ctxutil.TenantID(ctx),
+	)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to update discount in database",
+			"discount_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to update discount: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"discount_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Discount not found for update",
+			"discount_id", id,
+		)
+		return fmt.Errorf("discount %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *discountRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM discounts WHERE id = $1 AND tenant_id = $2`
+
+	r.logger.Info(ctx, "Deleting discount from database",
+		"discount_id", id,
+	)
+
+	result, err := r.db.ExecContext(ctx, query, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete discount from database",
+			"discount_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete discount: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"discount_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Discount not found for deletion",
+			"discount_id", id,
+		)
+		return fmt.Errorf("discount %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *discountRepo) List(ctx context.Context, filter model.DiscountFilter) ([]*model.Discount, error) {
+	query := `
+		SELECT id, kind, value, subscription_id, user_id, promo_code, start_date, end_date, created_at
+		FROM discounts
+		WHERE 1=1
+	`
+
+	where := newWhereBuilder(discountFilterColumns)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.SubscriptionID != nil {
+		where.Where("subscription_id", opEq, *filter.SubscriptionID)
+	}
+	if filter.UserID != nil {
+		where.Where("user_id", opEq, *filter.UserID)
+	}
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build discounts filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	query = appendConditions(query, conditions) + " ORDER BY start_date, created_at"
+	r.logQuery(ctx, query, args)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list discounts from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list discounts: %w", err)
+	}
+	defer rows.Close()
+
+	var discounts []*model.Discount
+	for rows.Next() {
+		var discount model.Discount
+		err := rows.Scan(
+			&discount.ID,
+			&discount.Kind,
+			&discount.Value,
+			&discount.SubscriptionID,
+			&discount.UserID,
+			&discount.PromoCode,
+			&discount.StartDate,
+			&discount.EndDate,
+			&discount.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan discount row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan discount: %w", err)
+		}
+		discounts = append(discounts, &discount)
+	}
+
+	r.queries.Observe("discounts.list", len(discounts), time.Since(start))
+
+	return discounts, nil
+}
+
+func (r *discountRepo) logQuery(ctx context.Context, query string, args []interface{}) {
+	r.logger.Debug(ctx, "Executing dynamic query",
+		"query", query,
+		"args_count", len(args),
+	)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "errors"
+
+// Ошибки репозиториев. Репозитории оборачивают их с названием сущности
+// ("subscription not found"), а сервисы по errors.Is переводят в ошибки предметной области
+var (
+	// ErrNotFound - строки с таким идентификатором нет в организации запроса
+	ErrNotFound = errors.New("not found")
+	// ErrConflict - запись нарушает уникальность или изменена параллельным запросом
+	ErrConflict = errors.New("conflict")
+)
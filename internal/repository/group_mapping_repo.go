@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// GroupMappingRepository хранит сопоставления групп SSO. Сопоставления общие для всех
+// организаций: организацию задает само сопоставление
+type GroupMappingRepository interface {
+	// List возвращает все сопоставления по имени группы
+	List(ctx context.Context) ([]model.GroupMapping, error)
+	// Save создает или заменяет сопоставление группы и заполняет UpdatedAt
+	Save(ctx context.Context, mapping *model.GroupMapping) error
+	// Delete удаляет сопоставление группы; false - такого сопоставления нет
+	Delete(ctx context.Context, group string) (bool, error)
+}
+
+type groupMappingRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewGroupMappingRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) GroupMappingRepository {
+	return &groupMappingRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *groupMappingRepo) List(ctx context.Context) ([]model.GroupMapping, error) {
+	query := `
+		SELECT group_name, COALESCE(tenant_id, ''), role, updated_at
+		FROM sso_group_mappings
+		ORDER BY group_name
+	`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list SSO group mappings from database", "error", err)
+		return nil, fmt.Errorf("failed to list SSO group mappings: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := []model.GroupMapping{}
+	for rows.Next() {
+		var mapping model.GroupMapping
+		if err := rows.Scan(&mapping.Group, &mapping.Tenant, &mapping.Role, &mapping.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan SSO group mapping: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate SSO group mappings: %w", err)
+	}
+	r.queries.Observe("sso_group_mappings.list", len(mappings), time.Since(start))
+	return mappings, nil
+}
+
+func (r *groupMappingRepo) Save(ctx context.Context, mapping *model.GroupMapping) error {
+	query := `
+		INSERT INTO sso_group_mappings (group_name, tenant_id, role)
+		VALUES ($1, NULLIF($2, ''), $3)
+		ON CONFLICT (group_name) DO UPDATE SET
+			tenant_id = EXCLUDED.tenant_id,
+			role = EXCLUDED.role,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	start := time.Now()
+	err := r.db.QueryRowContext(ctx, query, mapping.Group, mapping.Tenant, mapping.Role).Scan(&mapping.UpdatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save SSO group mapping in database",
+			"group", mapping.Group,
+			"error", err,
+		)
+		return fmt.Errorf("failed to save SSO group mapping: %w", err)
+	}
+	r.queries.Observe("sso_group_mappings.save", 1, time.Since(start))
+	return nil
+}
+
+func (r *groupMappingRepo) Delete(ctx context.Context, group string) (bool, error) {
+	query := `DELETE FROM sso_group_mappings WHERE group_name = $1`
+
+	result, err := r.db.ExecContext(ctx, query, group)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete SSO group mapping from database",
+			"group", group,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to delete SSO group mapping: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
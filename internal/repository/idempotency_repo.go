@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type IdempotencyRepository interface {
+	// Reserve занимает ключ record.Key автора record.Actor. Если ключ уже занят действующей
+	// записью, возвращает ее и ничего не меняет; истекшая запись и запись, выполнение которой
+	// не завершилось до LockedUntil, занимаются заново
+	Reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	// Complete сохраняет ответ на запрос с ключом
+	Complete(ctx context.Context, record *model.IdempotencyRecord) error
+	// Release освобождает ключ, чтобы повтор запроса выполнился заново
+	Release(ctx context.Context, actor, key string) error
+	// DeleteExpired удаляет истекшие ключи всех организаций и возвращает их число
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewIdempotencyRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) IdempotencyRepository {
+	return &idempotencyRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *idempotencyRepo) Reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	// Одновременные запросы с одним ключом на разных репликах сериализует первичный ключ:
+	// запись получает только один из них, остальные читают ее
+	reserveQuery := `
+		INSERT INTO idempotency_keys (tenant_id, actor, idempotency_key, fingerprint, status, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, 'processing', $5, $6)
+		ON CONFLICT (tenant_id, actor, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			status = 'processing',
+			response_status = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			locked_until = EXCLUDED.locked_until,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+			OR (idempotency_keys.status = 'processing' AND idempotency_keys.locked_until <= NOW())
+		RETURNING idempotency_key
+	`
+	existingQuery := `
+		SELECT fingerprint, status, COALESCE(response_status, 0), COALESCE(content_type, ''), response_body, locked_until, expires_at
+		FROM idempotency_keys
+		WHERE tenant_id = $1 AND actor = $2 AND idempotency_key = $3
+	`
+
+	tenantID := ctxutil.TenantID(ctx)
+	start := time.Now()
+	var key string
+	err := r.db.QueryRowContext(ctx, reserveQuery,
+		tenantID,
+		record.Actor,
+		record.Key,
+		record.Fingerprint,
+		record.LockedUntil,
+		record.ExpiresAt,
+	).Scan(&key)
+	if err == nil {
+		r.queries.Observe("idempotency_keys.reserve", 1, time.Since(start))
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error(ctx, "Failed to reserve idempotency key",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	existing := model.IdempotencyRecord{Actor: record.Actor, Key: record.Key}
+	err = r.db.QueryRowContext(ctx, existingQuery, tenantID, record.Actor, record.Key).Scan(
+		&existing.Fingerprint,
+		&existing.Status,
+		&existing.ResponseStatus,
+		&existing.ContentType,
+		&existing.ResponseBody,
+		&existing.LockedUntil,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		// Запись могла быть удалена между запросами; повтор займет ключ
+		r.logger.Error(ctx, "Failed to read reserved idempotency key",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+	r.queries.Observe("idempotency_keys.reserve", 1, time.Since(start))
+
+	return &existing, nil
+}
+
+func (r *idempotencyRepo) Complete(ctx context.Context, record *model.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = 'completed', response_status = $1, content_type = $2, response_body = $3
+		WHERE tenant_id = $4 AND actor = $5 AND idempotency_key = $6 AND fingerprint = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.ResponseStatus,
+		record.ContentType,
+		record.ResponseBody,
+		ctxutil.TenantID(ctx),
+		record.Actor,
+		record.Key,
+		record.Fingerprint,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save idempotent response",
+			"error", err,
+		)
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) Release(ctx context.Context, actor, key string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE tenant_id = $1 AND actor = $2 AND idempotency_key = $3 AND status = 'processing'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, ctxutil.TenantID(ctx), actor, key); err != nil {
+		r.logger.Error(ctx, "Failed to release idempotency key",
+			"error", err,
+		)
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete expired idempotency keys",
+			"error", err,
+		)
+		return 0, fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
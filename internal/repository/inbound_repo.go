@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type InboundRepository interface {
+	// Claim занимает событие record.Source и record.EventID для обработки. Если событие уже
+	// принято, учитывает повтор обработанного события и возвращает запись, ничего больше
+	// не меняя; событие, обработка которого не завершилась до LockedUntil, занимается заново
+	Claim(ctx context.Context, record *model.InboundRecord) (*model.InboundRecord, error)
+	// Complete отмечает событие обработанным
+	Complete(ctx context.Context, source, eventID string, subscriptionID *uuid.UUID) error
+	// Release освобождает событие, чтобы повтор обработался заново
+	Release(ctx context.Context, source, eventID string) error
+	// ListDuplicates возвращает до limit событий с отброшенными повторами, начиная с последнего повтора
+	ListDuplicates(ctx context.Context, limit int) ([]model.InboundRecord, error)
+	// DeleteProcessedBefore удаляет события всех организаций, обработанные до before, и возвращает их число
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type inboundRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewInboundRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) InboundRepository {
+	return &inboundRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *inboundRepo) Claim(ctx context.Context, record *model.InboundRecord) (*model.InboundRecord, error) {
+	// Одновременные доставки одного события на разных репликах сериализует первичный ключ:
+	// событие получает только одна из них, остальные учитываются как повтор
+	claimQuery := `
+		INSERT INTO inbound_events (tenant_id, source, event_id, type, status, locked_until)
+		VALUES ($1, $2, $3, $4, 'processing', $5)
+		ON CONFLICT (tenant_id, source, event_id) DO UPDATE
+		SET type = EXCLUDED.type,
+			locked_until = EXCLUDED.locked_until,
+			received_at = NOW()
+		WHERE inbound_events.status = 'processing' AND inbound_events.locked_until <= NOW()
+		RETURNING event_id
+	`
+	duplicateQuery := `
+		UPDATE inbound_events
+		SET duplicates = duplicates + CASE WHEN status = 'processed' THEN 1 ELSE 0 END,
+			last_duplicate_at = CASE WHEN status = 'processed' THEN NOW() ELSE last_duplicate_at END
+		WHERE tenant_id = $1 AND source = $2 AND event_id = $3
+		RETURNING type, status, subscription_id, locked_until, received_at, processed_at, duplicates, last_duplicate_at
+	`
+
+	tenantID := ctxutil.TenantID(ctx)
+	start := time.Now()
+	var eventID string
+	err := r.db.QueryRowContext(ctx, claimQuery,
+		tenantID,
+		record.Source,
+		record.EventID,
+		record.Type,
+		record.LockedUntil,
+	).Scan(&eventID)
+	if err == nil {
+		r.queries.Observe("inbound_events.claim", 1, time.Since(start))
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error(ctx, "Failed to claim inbound event",
+			"source", record.Source,
+			"event_id", record.EventID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to claim inbound event: %w", err)
+	}
+
+	existing := model.InboundRecord{Source: record.Source, EventID: record.EventID}
+	err = r.db.QueryRowContext(ctx, duplicateQuery, tenantID, record.Source, record.EventID).Scan(
+		&existing.Type,
+		&existing.Status,
+		&existing.SubscriptionID,
+		&existing.LockedUntil,
+		&existing.ReceivedAt,
+		&existing.ProcessedAt,
+		&existing.Duplicates,
+		&existing.LastDuplicateAt,
+	)
+	if err != nil {
+		// Запись могла быть освобождена между запросами; повтор займет событие
+		r.logger.Error(ctx, "Failed to read claimed inbound event",
+			"source", record.Source,
+			"event_id", record.EventID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read inbound event: %w", err)
+	}
+	r.queries.Observe("inbound_events.claim", 1, time.Since(start))
+
+	return &existing, nil
+}
+
+func (r *inboundRepo) Complete(ctx context.Context, source, eventID string, subscriptionID *uuid.UUID) error {
+	query := `
+		UPDATE inbound_events
+		SET status = 'processed', subscription_id = $1, processed_at = NOW()
+		WHERE tenant_id = $2 AND source = $3 AND event_id = $4
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, subscriptionID, ctxutil.TenantID(ctx), source, eventID); err != nil {
+		r.logger.Error(ctx, "Failed to complete inbound event",
+			"source", source,
+			"event_id", eventID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to complete inbound event: %w", err)
+	}
+	return nil
+}
+
+func (r *inboundRepo) Release(ctx context.Context, source, eventID string) error {
+	query := `
+		DELETE FROM inbound_events
+		WHERE tenant_id = $1 AND source = $2 AND event_id = $3 AND status = 'processing'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, ctxutil.TenantID(ctx), source, eventID); err != nil {
+		r.logger.Error(ctx, "Failed to release inbound event",
+			"source", source,
+			"event_id", eventID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to release inbound event: %w", err)
+	}
+	return nil
+}
+
+func (r *inboundRepo) ListDuplicates(ctx context.Context, limit int) ([]model.InboundRecord, error) {
+	query := `
+		SELECT source, event_id, type, status, subscription_id, received_at, processed_at, duplicates, last_duplicate_at
+		FROM inbound_events
+		WHERE tenant_id = $1 AND last_duplicate_at IS NOT NULL
+		ORDER BY last_duplicate_at DESC
+		LIMIT $2
+	`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, ctxutil.TenantID(ctx), limit)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list deduplicated inbound events",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list deduplicated inbound events: %w", err)
+	}
+	defer rows.Close()
+
+	records := []model.InboundRecord{}
+	for rows.Next() {
+		var record model.InboundRecord
+		if err := rows.Scan(
+			&record.Source,
+			&record.EventID,
+			&record.Type,
+			&record.Status,
+			&record.SubscriptionID,
+			&record.ReceivedAt,
+			&record.ProcessedAt,
+			&record.Duplicates,
+			&record.LastDuplicateAt,
+		); err != nil {
+			r.logger.Error(ctx, "Failed to scan inbound event",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan inbound event: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Error iterating inbound events rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list deduplicated inbound events: %w", err)
+	}
+	r.queries.Observe("inbound_events.list_duplicates", len(records), time.Since(start))
+
+	return records, nil
+}
+
+func (r *inboundRepo) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM inbound_events WHERE status = 'processed' AND processed_at < $1`, before)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete processed inbound events",
+			"error", err,
+		)
+		return 0, fmt.Errorf("failed to delete inbound events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type InboxRepository interface {
+	// Add записывает уведомление и заполняет ID и CreatedAt. Уведомление с уже записанным
+	// у пользователя DedupKey не записывается, и Add возвращает false
+	Add(ctx context.Context, notification *model.InboxNotification) (bool, error)
+	// List возвращает уведомления пользователя, начиная с последних
+	List(ctx context.Context, userID uuid.UUID, filter model.InboxFilter, page model.Pagination) (*model.InboxPage, error)
+	// MarkRead отмечает уведомление прочитанным и возвращает его; повторная отметка
+	// не меняет read_at. Уведомление другого пользователя не отмечается - ErrNotFound
+	MarkRead(ctx context.Context, userID, id uuid.UUID) (*model.InboxNotification, error)
+	// MarkAllRead отмечает прочитанными все уведомления пользователя и возвращает их число
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+const inboxColumns = `id, user_id, kind, subscription_id, message, data, read_at, created_at`
+
+type inboxRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewInboxRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) InboxRepository {
+	return &inboxRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *inboxRepo) Add(ctx context.Context, n *model.InboxNotification) (bool, error) {
+	query := `
+		INSERT INTO user_notifications (id, tenant_id, user_id, kind, subscription_id, message, data, dedup_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+		ON CONFLICT (tenant_id, user_id, dedup_key) WHERE dedup_key IS NOT NULL DO NOTHING
+		RETURNING created_at
+	`
+
+	n.ID = uuid.New()
+	data := []byte(n.Data)
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		n.ID,
+		ctxutil.TenantID(ctx),
+		n.UserID,
+		n.Kind,
+		n.SubscriptionID,
+		n.Message,
+		data,
+		n.DedupKey,
+	).Scan(&n.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to add notification to database",
+			"user_id", n.UserID,
+			"kind", n.Kind,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to add notification: %w", err)
+	}
+	return true, nil
+}
+
+func (r *inboxRepo) List(ctx context.Context, userID uuid.UUID, filter model.InboxFilter, page model.Pagination) (*model.InboxPage, error) {
+	tenantID := ctxutil.TenantID(ctx)
+	start := time.Now()
+
+	result := &model.InboxPage{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE read_at IS NULL)
+		FROM user_notifications
+		WHERE tenant_id = $1 AND user_id = $2
+	`, tenantID, userID).Scan(&result.Total, &result.Unread)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to count notifications in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	if filter.UnreadOnly {
+		result.Total = result.Unread
+	}
+
+	query := `SELECT ` + inboxColumns + `
+		FROM user_notifications
+		WHERE tenant_id = $1 AND user_id = $2 AND ($3 = FALSE OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.QueryContext(ctx, query, tenantID, userID, filter.UnreadOnly, page.Limit, page.Offset)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list notifications from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	result.Items = []*model.InboxNotification{}
+	for rows.Next() {
+		n, err := scanInboxNotification(rows)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan notification row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		result.Items = append(result.Items, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	r.queries.Observe("user_notifications.list", len(result.Items), time.Since(start))
+
+	return result, nil
+}
+
+func (r *inboxRepo) MarkRead(ctx context.Context, userID, id uuid.UUID) (*model.InboxNotification, error) {
+	query := `
+		UPDATE user_notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE tenant_id = $1 AND user_id = $2 AND id = $3
+		RETURNING ` + inboxColumns
+
+	n, err := scanInboxNotification(r.db.QueryRowContext(ctx, query, ctxutil.TenantID(ctx), userID, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification %w", ErrNotFound)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to mark notification as read in database",
+			"notification_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return n, nil
+}
+
+func (r *inboxRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		UPDATE user_notifications
+		SET read_at = NOW()
+		WHERE tenant_id = $1 AND user_id = $2 AND read_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, ctxutil.TenantID(ctx), userID)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to mark notifications as read in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	marked, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return int(marked), nil
+}
+
+// scanInboxNotification читает уведомление в порядке inboxColumns
+func scanInboxNotification(row rowScanner) (*model.InboxNotification, error) {
+	var n model.InboxNotification
+	var data []byte
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Kind,
+		&n.SubscriptionID,
+		&n.Message,
+		&data,
+		&n.ReadAt,
+		&n.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	n.Data = data
+	n.Read = n.ReadAt != nil
+	return &n, nil
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceRepository interface {
+	// Create сохраняет счет вместе со строками в одной транзакции и заполняет ID и CreatedAt.
+	// Если счет пользователя за этот месяц уже есть, возвращает ошибку "invoice already exists"
+	Create(ctx context.Context, invoice *model.Invoice) error
+	// GetByID возвращает счет со строками или nil, если его нет
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error)
+	// GetByPeriod возвращает счет пользователя за месяц или nil, если его нет
+	GetByPeriod(ctx context.Context, userID uuid.UUID, period time.Time) (*model.Invoice, error)
+	// ListByUser возвращает счета пользователя без строк, начиная с последнего периода
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Invoice, error)
+}
+
+type invoiceRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewInvoiceRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) InvoiceRepository {
+	return &invoiceRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+const invoiceColumns = `id, user_id, period, tax_rate, base_total, discount_total, net_total, tax_total, gross_total, created_at`
+
+func (r *invoiceRepo) Create(ctx context.Context, invoice *model.Invoice) error {
+	r.logger.Info(ctx, "Creating invoice in database",
+		"user_id", invoice.UserID,
+		"period", invoice.Period,
+		"lines", len(invoice.Lines),
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin invoice transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO invoices (user_id, period, tax_rate, base_total, discount_total, net_total, tax_total, gross_total, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`,
+		invoice.UserID,
+		invoice.Period,
+		invoice.TaxRate,
+		invoice.BaseTotal,
+		invoice.DiscountTotal,
+		invoice.NetTotal,
+		invoice.TaxTotal,
+		invoice.GrossTotal,
+		ctxutil.TenantID(ctx),
+	).Scan(&invoice.ID, &invoice.CreatedAt)
+	if err != nil {
+		if pgErr, ok := asPgError(err); ok && pgErr.Code == uniqueViolation {
+			r.logger.Warn(ctx, "Invoice for period already exists",
+				"user_id", invoice.UserID,
+				"period", invoice.Period,
+			)
+			return fmt.Errorf("invoice already exists: %w", ErrConflict)
+		}
+		r.logger.Error(ctx, "Failed to create invoice in database",
+			"user_id", invoice.UserID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	for i, line := range invoice.Lines {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO invoice_lines (invoice_id, line_no, subscription_id, service_name, base_amount, discount_amount, net_amount, tax_amount, gross_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			invoice.ID,
+			i+1,
+			line.SubscriptionID,
+			line.ServiceName,
+			line.BaseAmount,
+			line.DiscountAmount,
+			line.NetAmount,
+			line.TaxAmount,
+			line.GrossAmount,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to create invoice line in database",
+				"invoice_id", invoice.ID,
+				"line_no", i+1,
+				"error", err,
+			)
+			return fmt.Errorf("failed to create invoice line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit invoice transaction",
+			"invoice_id", invoice.ID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Invoice created successfully",
+		"invoice_id", invoice.ID,
+		"gross_total", invoice.GrossTotal,
+	)
+	return nil
+}
+
+func (r *invoiceRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error) {
+	return r.getOne(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 AND tenant_id = $2`, id, ctxutil.TenantID(ctx))
+}
+
+func (r *invoiceRepo) GetByPeriod(ctx context.Context, userID uuid.UUID, period time.Time) (*model.Invoice, error) {
+	return r.getOne(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE user_id = $1 AND period = $2 AND tenant_id = $3`, userID, period, ctxutil.TenantID(ctx))
+}
+
+func (r *invoiceRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE user_id = $1 AND tenant_id = $2 ORDER BY period DESC`
+
+	r.logger.Debug(ctx, "Listing invoices from database",
+		"user_id", userID,
+	)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, userID, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list invoices from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*model.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan invoice row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	r.queries.Observe("invoices.list_by_user", len(invoices), time.Since(start))
+
+	return invoices, nil
+}
+
+func (r *invoiceRepo) getOne(ctx context.Context, query string, args ...interface{}) (*model.Invoice, error) {
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get invoice from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subscription_id, service_name, base_amount, discount_amount, net_amount, tax_amount, gross_amount
+		FROM invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY line_no
+	`, invoice.ID)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get invoice lines from database",
+			"invoice_id", invoice.ID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	invoice.Lines = []model.InvoiceLine{}
+	for rows.Next() {
+		var line model.InvoiceLine
+		err := rows.Scan(
+			&line.SubscriptionID,
+			&line.ServiceName,
+			&line.BaseAmount,
+			&line.DiscountAmount,
+			&line.NetAmount,
+			&line.TaxAmount,
+			&line.GrossAmount,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan invoice line row",
+				"invoice_id", invoice.ID,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	return invoice, nil
+}
+
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row rowScanner) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.UserID,
+		&invoice.Period,
+		&invoice.TaxRate,
+		&invoice.BaseTotal,
+		&invoice.DiscountTotal,
+		&invoice.NetTotal,
+		&invoice.TaxTotal,
+		&invoice.GrossTotal,
+		&invoice.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
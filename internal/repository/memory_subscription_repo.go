@@ -0,0 +1,844 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// searchSimilarityThreshold - порог похожести по триграммам, как pg_trgm.similarity_threshold
+const searchSimilarityThreshold = 0.3
+
+// memorySubscription - подписка с организацией и историей приостановок
+type memorySubscription struct {
+	sub    model.Subscription
+	tenant string
+	pauses []memoryPause
+	// prices - записи subscription_prices по возрастанию месяца
+	prices []model.SubscriptionPrice
+}
+
+// memoryPause - месяцы приостановки; нулевой end - пауза не закрыта
+type memoryPause struct {
+	start time.Time
+	end   *time.Time
+}
+
+// memoryTransfer - запись subscription_transfers
+type memoryTransfer struct {
+	subscriptionID uuid.UUID
+	from, to       uuid.UUID
+	reason         *string
+}
+
+type memoryChange struct {
+	change model.SubscriptionChange
+	tenant string
+}
+
+// memorySubscriptionRepo хранит подписки в памяти процесса и повторяет поведение
+// репозитория PostgreSQL: ограничение организацией, журнал изменений, учет пауз
+// и состояние expired. Скидки хранит DiscountRepository в базе, поэтому расчеты
+// стоимости здесь их не учитывают
+type memorySubscriptionRepo struct {
+	mu        sync.RWMutex
+	subs      map[uuid.UUID]*memorySubscription
+	changes   []memoryChange
+	transfers []memoryTransfer
+	seq       int64
+	changeSeq int64
+}
+
+// NewInMemorySubscriptionRepository создает репозиторий подписок в памяти для
+// демонстраций (DB_DRIVER=memory) и тестов без PostgreSQL
+func NewInMemorySubscriptionRepository() SubscriptionRepository {
+	return &memorySubscriptionRepo{subs: make(map[uuid.UUID]*memorySubscription)}
+}
+
+// now - время изменения с точностью timestamptz, чтобы курсоры совпадали с сохраненными значениями
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (r *memorySubscriptionRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insert(ctx, sub, uuid.New())
+	return nil
+}
+
+func (r *memorySubscriptionRepo) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sub := range subs {
+		id := sub.ID
+		if id == uuid.Nil {
+			id = uuid.New()
+		}
+		r.insert(ctx, sub, id)
+	}
+	return nil
+}
+
+// insert сохраняет подписку и заполняет поля, которые в базе задают значения по умолчанию и триггеры
+func (r *memorySubscriptionRepo) insert(ctx context.Context, sub *model.Subscription, id uuid.UUID) {
+	r.seq++
+	sub.ID = id
+	if sub.Status == "" {
+		sub.Status = model.StatusActive
+	}
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = model.BillingMonthly
+	}
+	sub.ChangeSeq = r.seq
+	sub.CreatedAt = now()
+	sub.UpdatedAt = sub.CreatedAt
+
+	stored := &memorySubscription{sub: *sub, tenant: ctxutil.TenantID(ctx)}
+	r.subs[id] = stored
+	r.logChange(stored, "create")
+}
+
+func (r *memorySubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok {
+		return nil, nil
+	}
+	return stored.view(model.CurrentMonth()), nil
+}
+
+func (r *memorySubscriptionRepo) Update(ctx context.Context, id uuid.UUID, sub *model.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+
+	r.update(stored, sub)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) UpdateBatch(ctx context.Context, updates []model.SubscriptionUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Все подписки проверяются до изменений, чтобы ошибка не оставила пачку примененной частично
+	stored := make([]*memorySubscription, len(updates))
+	for i, u := range updates {
+		s, ok := r.find(ctx, u.ID)
+		if !ok {
+			return &BatchRowError{Row: i, Action: "update", Message: "subscription not found"}
+		}
+		stored[i] = s
+	}
+	for i, u := range updates {
+		r.update(stored[i], u.Subscription)
+	}
+	return nil
+}
+
+// update сохраняет поля подписки и ведет учет месяцев приостановки
+func (r *memorySubscriptionRepo) update(stored *memorySubscription, sub *model.Subscription) {
+	previous := stored.sub.Status
+	if sub.PriceFrom != nil {
+		stored.recordPrice(*sub.PriceFrom, sub)
+	}
+	stored.sub.ServiceName = sub.ServiceName
+	stored.sub.MonthlyCost = sub.MonthlyCost
+	stored.sub.UserID = sub.UserID
+	stored.sub.StartDate = sub.StartDate
+	stored.sub.EndDate = sub.EndDate
+	stored.sub.PrepaidAmount = sub.PrepaidAmount
+	stored.sub.Metadata = sub.Metadata
+	stored.sub.ServiceID = sub.ServiceID
+	stored.sub.Description = sub.Description
+	stored.sub.BillingPeriod = sub.BillingPeriod
+	if stored.sub.BillingPeriod == "" {
+		stored.sub.BillingPeriod = model.BillingMonthly
+	}
+	stored.sub.Cost = sub.Cost
+	stored.sub.Tags = sub.Tags
+	if sub.Status != "" {
+		stored.sub.Status = sub.Status
+	}
+	if sub.Status != "" && sub.Status != previous {
+		stored.recordPause(previous, sub.Status)
+	}
+
+	r.touch(stored, "update")
+}
+
+// recordPause повторяет subscriptionRepo.recordPause: приостановка открывает паузу
+// с текущего месяца, возобновление закрывает ее предыдущим месяцем или удаляет пустую
+func (s *memorySubscription) recordPause(from, to string) {
+	month := model.CurrentMonth()
+
+	switch {
+	case to == model.StatusPaused:
+		s.pauses = append(s.pauses, memoryPause{start: month})
+	case from == model.StatusPaused && to == model.StatusActive:
+		kept := s.pauses[:0]
+		for _, p := range s.pauses {
+			if p.end == nil {
+				if !p.start.Before(month) {
+					continue
+				}
+				end := month.AddDate(0, -1, 0)
+				p.end = &end
+			}
+			kept = append(kept, p)
+		}
+		s.pauses = kept
+	}
+}
+
+// recordPrice повторяет subscriptionRepo.recordPrice: при первом изменении сохраняет
+// прежнюю стоимость с месяца начала и заменяет записи начиная с месяца from
+func (s *memorySubscription) recordPrice(from time.Time, sub *model.Subscription) {
+	if len(s.prices) == 0 {
+		s.prices = append(s.prices, s.sub.Price(s.sub.StartDate))
+	}
+	kept := s.prices[:0]
+	for _, price := range s.prices {
+		if price.From.Before(from) {
+			kept = append(kept, price)
+		}
+	}
+	s.prices = append(kept, sub.Price(from))
+}
+
+// monthlyBase возвращает начисление за месяц по стоимости, действующей в этом месяце
+func (s *memorySubscription) monthlyBase(month time.Time) *big.Rat {
+	price, ok := model.EffectivePrice(s.prices, month)
+	if !ok {
+		price = s.sub.Price(s.sub.StartDate)
+	}
+	return monthlyBase(price.MonthlyCost, s.sub.PrepaidAmount, price.Period(), price.Cost)
+}
+
+func (r *memorySubscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	delete(r.subs, id)
+	r.logChange(stored, "delete")
+	return nil
+}
+
+func (r *memorySubscriptionRepo) DeleteBatch(ctx context.Context, ids []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Повторный идентификатор в пачке, как и в PostgreSQL, удалять уже нечего
+	stored := make([]*memorySubscription, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for i, id := range ids {
+		s, ok := r.find(ctx, id)
+		if !ok || seen[id] {
+			return &BatchRowError{Row: i, Action: "delete", Message: "subscription not found"}
+		}
+		seen[id] = true
+		stored[i] = s
+	}
+	for i, id := range ids {
+		delete(r.subs, id)
+		r.logChange(stored[i], "delete")
+	}
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Sync(ctx context.Context, creates []*model.Subscription, updates []model.SubscriptionUpdate, deletes []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Как в UpdateBatch и DeleteBatch, все подписки проверяются до изменений
+	removed := make([]*memorySubscription, len(deletes))
+	seen := make(map[uuid.UUID]bool, len(deletes))
+	for i, id := range deletes {
+		s, ok := r.find(ctx, id)
+		if !ok || seen[id] {
+			return &BatchRowError{Row: i, Action: "delete", Message: "subscription not found"}
+		}
+		seen[id] = true
+		removed[i] = s
+	}
+	stored := make([]*memorySubscription, len(updates))
+	for i, u := range updates {
+		s, ok := r.find(ctx, u.ID)
+		if !ok || seen[u.ID] {
+			return &BatchRowError{Row: i, Action: "update", Message: "subscription not found"}
+		}
+		stored[i] = s
+	}
+
+	for i, id := range deletes {
+		delete(r.subs, id)
+		r.logChange(removed[i], "delete")
+	}
+	for i, u := range updates {
+		r.update(stored[i], u.Subscription)
+	}
+	for _, sub := range creates {
+		r.insert(ctx, sub, uuid.New())
+	}
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Activate(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok || !stored.sub.IsDraft {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	stored.sub.IsDraft = false
+	r.touch(stored, "update")
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	cancelledAt := now()
+	stored.sub.Status = model.StatusCancelled
+	stored.sub.EndDate = &endDate
+	stored.sub.CancelReason = reason
+	stored.sub.CancelledAt = &cancelledAt
+	r.touch(stored, "update")
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Transfer(ctx context.Context, id, from, to uuid.UUID, reason *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(ctx, id)
+	if !ok || stored.sub.UserID != from || stored.sub.Status == model.StatusCancelled {
+		return fmt.Errorf("subscription was changed concurrently: %w", ErrConflict)
+	}
+	stored.sub.UserID = to
+	r.touch(stored, "transfer")
+	r.transfers = append(r.transfers, memoryTransfer{subscriptionID: id, from: from, to: to, reason: reason})
+	return nil
+}
+
+// touch отражает изменение строки: новый номер изменения, updated_at и запись в журнал
+func (r *memorySubscriptionRepo) touch(stored *memorySubscription, operation string) {
+	r.seq++
+	stored.sub.ChangeSeq = r.seq
+	stored.sub.UpdatedAt = now()
+	r.logChange(stored, operation)
+}
+
+// logChange пишет в журнал образ строки в том же виде, что и to_jsonb в триггере
+func (r *memorySubscriptionRepo) logChange(stored *memorySubscription, operation string) {
+	sub := stored.sub
+	row := map[string]interface{}{
+		"id":             sub.ID,
+		"service_name":   sub.ServiceName,
+		"monthly_cost":   sub.MonthlyCost,
+		"user_id":        sub.UserID,
+		"start_date":     sub.StartDate.Format("2006-01-02"),
+		"end_date":       formatDatePtr(sub.EndDate),
+		"prepaid_amount": sub.PrepaidAmount,
+		"is_draft":       sub.IsDraft,
+		"status":         sub.Status,
+		"cancel_reason":  sub.CancelReason,
+		"cancelled_at":   sub.CancelledAt,
+		"metadata":       metadataObject(sub.Metadata),
+		"service_id":     sub.ServiceID,
+		"description":    sub.Description,
+		"billing_period": sub.BillingPeriod,
+		"cost":           sub.Cost,
+		"change_seq":     sub.ChangeSeq,
+		"created_at":     sub.CreatedAt,
+		"updated_at":     sub.UpdatedAt,
+		"tenant_id":      stored.tenant,
+	}
+	payload, _ := json.Marshal(row)
+
+	r.changeSeq++
+	r.changes = append(r.changes, memoryChange{
+		change: model.SubscriptionChange{
+			Seq:            r.changeSeq,
+			SubscriptionID: sub.ID,
+			Operation:      operation,
+			Payload:        payload,
+			ChangedAt:      now(),
+		},
+		tenant: stored.tenant,
+	})
+}
+
+func formatDatePtr(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// metadataObject возвращает метки объектом, как JSONB со значением по умолчанию '{}'
+func metadataObject(m model.Metadata) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+// find возвращает подписку организации контекста
+func (r *memorySubscriptionRepo) find(ctx context.Context, id uuid.UUID) (*memorySubscription, bool) {
+	stored, ok := r.subs[id]
+	if !ok || stored.tenant != ctxutil.TenantID(ctx) {
+		return nil, false
+	}
+	return stored, true
+}
+
+// view возвращает копию подписки с состоянием на месяц month, как scanSubscription
+func (s *memorySubscription) view(month time.Time) *model.Subscription {
+	sub := s.sub
+	sub.Status = model.EffectiveStatus(sub.Status, sub.EndDate, month)
+	return &sub
+}
+
+// activeIn сообщает, что подписка действует в месяце month: начата не позже и не закончена раньше
+func (s *memorySubscription) activeIn(month time.Time) bool {
+	return !s.sub.StartDate.After(month) && (s.sub.EndDate == nil || !s.sub.EndDate.Before(month))
+}
+
+// pausedIn сообщает, что month попадает в одну из пауз подписки
+func (s *memorySubscription) pausedIn(month time.Time) bool {
+	for _, p := range s.pauses {
+		if !p.start.After(month) && (p.end == nil || !p.end.Before(month)) {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantSubs возвращает подписки организации контекста, для которых keep возвращает true
+func (r *memorySubscriptionRepo) tenantSubs(ctx context.Context, keep func(*memorySubscription) bool) []*memorySubscription {
+	tenantID := ctxutil.TenantID(ctx)
+	var subs []*memorySubscription
+	for _, stored := range r.subs {
+		if stored.tenant == tenantID && keep(stored) {
+			subs = append(subs, stored)
+		}
+	}
+	return subs
+}
+
+// matchesFilter повторяет условия buildSubscriptionFilter
+func matchesFilter(s *memorySubscription, filter model.SubscriptionFilter, month time.Time) bool {
+	sub := s.sub
+	if filter.UserID != nil && sub.UserID != *filter.UserID {
+		return false
+	}
+	if filter.ServiceName != nil && sub.ServiceName != *filter.ServiceName {
+		return false
+	}
+	if filter.ServiceID != nil && (sub.ServiceID == nil || *sub.ServiceID != *filter.ServiceID) {
+		return false
+	}
+	if filter.Tag != nil && !sub.Tags.Contains(*filter.Tag) {
+		return false
+	}
+	for _, name := range filter.ExcludeServiceNames {
+		if sub.ServiceName == name {
+			return false
+		}
+	}
+	for _, userID := range filter.ExcludeUserIDs {
+		if sub.UserID == userID {
+			return false
+		}
+	}
+	if filter.Status != nil && model.EffectiveStatus(sub.Status, sub.EndDate, month) != *filter.Status {
+		return false
+	}
+	return true
+}
+
+// compareCreated упорядочивает подписки по (created_at, id), как индекс keyset-пагинации
+func compareCreated(a, b *model.Subscription) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+// filtered возвращает подписки под фильтром в порядке (created_at, id)
+func (r *memorySubscriptionRepo) filtered(ctx context.Context, filter model.SubscriptionFilter) []*model.Subscription {
+	month := model.CurrentMonth()
+	stored := r.tenantSubs(ctx, func(s *memorySubscription) bool { return matchesFilter(s, filter, month) })
+
+	subs := make([]*model.Subscription, 0, len(stored))
+	for _, s := range stored {
+		subs = append(subs, s.view(month))
+	}
+	sort.Slice(subs, func(i, j int) bool { return compareCreated(subs[i], subs[j]) < 0 })
+	return subs
+}
+
+func (r *memorySubscriptionRepo) List(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) ([]*model.Subscription, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := r.filtered(ctx, filter)
+	total := len(subs)
+
+	// Список отдается от новых к старым
+	for i, j := 0, len(subs)-1; i < j; i, j = i+1, j-1 {
+		subs[i], subs[j] = subs[j], subs[i]
+	}
+	return window(subs, page.Offset, page.Limit), total, nil
+}
+
+// window возвращает до limit элементов начиная с offset
+func window(subs []*model.Subscription, offset, limit int) []*model.Subscription {
+	if offset >= len(subs) {
+		return nil
+	}
+	subs = subs[offset:]
+	if limit >= 0 && limit < len(subs) {
+		subs = subs[:limit]
+	}
+	return subs
+}
+
+func (r *memorySubscriptionRepo) ListAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) ([]*model.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := r.filtered(ctx, filter)
+	start := 0
+	if after != nil {
+		cursor := &model.Subscription{CreatedAt: after.CreatedAt, ID: after.ID}
+		start = sort.Search(len(subs), func(i int) bool { return compareCreated(subs[i], cursor) > 0 })
+	}
+	return window(subs, start, limit), nil
+}
+
+func (r *memorySubscriptionRepo) Stream(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	// Снимок берется под блокировкой, fn вызывается без нее и может обращаться к репозиторию
+	r.mu.RLock()
+	subs := r.filtered(ctx, filter)
+	r.mu.RUnlock()
+
+	for _, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to stream subscriptions: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Search(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	month := model.CurrentMonth()
+	var subs []*model.Subscription
+	for _, s := range r.tenantSubs(ctx, func(s *memorySubscription) bool { return userID == nil || s.sub.UserID == *userID }) {
+		subs = append(subs, s.view(month))
+	}
+	return rankSearch(subs, query, limit), nil
+}
+
+// normalizeSearch - аналог lower(immutable_unaccent(s)): нижний регистр без диакритики
+func normalizeSearch(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// trigramSimilarity повторяет similarity из pg_trgm: доля общих триграмм слов,
+// дополненных двумя пробелами в начале и одним в конце
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func (r *memorySubscriptionRepo) ListChanges(ctx context.Context, sinceSeq int64, limit int) ([]*model.SubscriptionChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenantID := ctxutil.TenantID(ctx)
+	var changes []*model.SubscriptionChange
+	for _, c := range r.changes {
+		if len(changes) == limit {
+			break
+		}
+		if c.change.Seq > sinceSeq && c.tenant == tenantID {
+			change := c.change
+			changes = append(changes, &change)
+		}
+	}
+	return changes, nil
+}
+
+func (r *memorySubscriptionRepo) ListPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	return append([]model.SubscriptionPrice(nil), stored.prices...), nil
+}
+
+func (r *memorySubscriptionRepo) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.CostTotals, error) {
+	startPeriod, err := model.ParseMonthYear(filter.StartPeriod)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start period format, expected MM-YYYY: %w", err)
+	}
+	endPeriod, err := model.ParseMonthYear(filter.EndPeriod)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid end period format, expected MM-YYYY: %w", err)
+	}
+	periodStart := time.Date(startPeriod.Year(), startPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(endPeriod.Year(), endPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	subFilter := model.SubscriptionFilter{ExcludeServiceNames: filter.ExcludeServiceNames, ExcludeUserIDs: filter.ExcludeUserIDs}
+	if filter.UserID != uuid.Nil {
+		subFilter.UserID = &filter.UserID
+	}
+	if filter.ServiceName != "" {
+		subFilter.ServiceName = &filter.ServiceName
+	}
+	subFilter.ServiceID = filter.ServiceID
+	if filter.Tag != "" {
+		subFilter.Tag = &filter.Tag
+	}
+
+	groupKey, err := model.ParseSummaryGroupBy(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	current := model.CurrentMonth()
+	acc := newCostAccumulator(filter.GroupBy != "")
+	for _, s := range r.tenantSubs(ctx, func(s *memorySubscription) bool { return !s.sub.IsDraft && matchesFilter(s, subFilter, current) }) {
+		cost := new(big.Rat)
+		months := 0
+		for month := monthStart(s.sub.StartDate, periodStart); !month.After(periodEnd); month = month.AddDate(0, 1, 0) {
+			if s.activeIn(month) && !s.pausedIn(month) {
+				cost.Add(cost, s.monthlyBase(month))
+				months++
+			}
+		}
+		// Подписки без оплачиваемых месяцев в периоде в группы не попадают, как и в запросе к базе
+		if months == 0 {
+			continue
+		}
+
+		var key *string
+		if filter.GroupBy == model.SummaryGroupByService {
+			name := s.sub.ServiceName
+			key = &name
+		} else if value, ok := s.sub.Metadata[groupKey]; ok {
+			key = &value
+		}
+		acc.addCost(key, cost, months, s.sub.Status == model.StatusCancelled || (s.sub.EndDate != nil && s.sub.EndDate.Before(current)))
+	}
+	return acc.result(), nil
+}
+
+// monthStart возвращает более поздний из двух месяцев
+func monthStart(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func (r *memorySubscriptionRepo) MonthlySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]model.MonthlySpend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := r.tenantSubs(ctx, func(s *memorySubscription) bool { return s.sub.UserID == userID && !s.sub.IsDraft })
+
+	var spend []model.MonthlySpend
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		total := 0
+		for _, s := range subs {
+			if s.activeIn(month) && !s.pausedIn(month) {
+				price, ok := model.EffectivePrice(s.prices, month)
+				if !ok {
+					price.MonthlyCost = s.sub.MonthlyCost
+				}
+				total += price.MonthlyCost
+			}
+		}
+		spend = append(spend, model.MonthlySpend{Month: month, Total: total})
+	}
+	return spend, nil
+}
+
+func (r *memorySubscriptionRepo) MonthlyCharges(ctx context.Context, userID uuid.UUID, month time.Time) ([]model.MonthlyCharge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := r.tenantSubs(ctx, func(s *memorySubscription) bool {
+		return s.sub.UserID == userID && !s.sub.IsDraft && s.activeIn(month) && !s.pausedIn(month)
+	})
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].sub.ServiceName != subs[j].sub.ServiceName {
+			return subs[i].sub.ServiceName < subs[j].sub.ServiceName
+		}
+		return bytes.Compare(subs[i].sub.ID[:], subs[j].sub.ID[:]) < 0
+	})
+
+	var charges []model.MonthlyCharge
+	for _, s := range subs {
+		base := s.monthlyBase(month)
+		charges = append(charges, model.MonthlyCharge{
+			SubscriptionID: s.sub.ID,
+			ServiceName:    s.sub.ServiceName,
+			Base:           base,
+			Amount:         new(big.Rat).Set(base),
+		})
+	}
+	return charges, nil
+}
+
+func (r *memorySubscriptionRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[uuid.UUID]bool)
+	var userIDs []uuid.UUID
+	for _, s := range r.tenantSubs(ctx, func(s *memorySubscription) bool { return !s.sub.IsDraft }) {
+		if !seen[s.sub.UserID] {
+			seen[s.sub.UserID] = true
+			userIDs = append(userIDs, s.sub.UserID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return bytes.Compare(userIDs[i][:], userIDs[j][:]) < 0 })
+	return userIDs, nil
+}
+
+func (r *memorySubscriptionRepo) ListTenants(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tenants []string
+	for _, s := range r.subs {
+		if !seen[s.tenant] {
+			seen[s.tenant] = true
+			tenants = append(tenants, s.tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
+
+func (r *memorySubscriptionRepo) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []*model.Subscription
+	for _, sub := range r.activeSubs(ctx, model.CurrentMonth()) {
+		if sub.UserID == userID {
+			subs = append(subs, sub)
+		}
+	}
+	return userServices(subs), nil
+}
+
+// activeSubs возвращает подписки организации без черновиков, действующие в месяце month
+func (r *memorySubscriptionRepo) activeSubs(ctx context.Context, month time.Time) []*model.Subscription {
+	var subs []*model.Subscription
+	for _, s := range r.tenantSubs(ctx, func(s *memorySubscription) bool { return !s.sub.IsDraft && s.activeIn(month) }) {
+		subs = append(subs, &s.sub)
+	}
+	return subs
+}
+
+func (r *memorySubscriptionRepo) ServiceNameUsage(ctx context.Context, normalized []string) ([]model.ServiceNameUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []*model.Subscription
+	for _, s := range r.tenantSubs(ctx, func(*memorySubscription) bool { return true }) {
+		subs = append(subs, &s.sub)
+	}
+	return serviceNameUsage(subs, normalized), nil
+}
+
+func (r *memorySubscriptionRepo) ListUserSpend(ctx context.Context, month time.Time) ([]model.UserSpend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return userSpend(r.activeSubs(ctx, month)), nil
+}
+
+func (r *memorySubscriptionRepo) ListServiceSpend(ctx context.Context, month time.Time) ([]model.SharedService, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return serviceSpend(r.activeSubs(ctx, month)), nil
+}
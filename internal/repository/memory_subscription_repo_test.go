@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestInMemoryCalculateTotalCost(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemorySubscriptionRepository()
+	current := model.CurrentMonth()
+	start := current.AddDate(0, -3, 0)
+	ended := current.AddDate(0, -2, 0)
+	prepaid := 1200
+	userID := uuid.New()
+
+	subs := []*model.Subscription{
+		// 4 месяца по 100, текущий месяц приостановлен
+		{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start},
+		// Закончилась два месяца назад: 2 месяца по 10
+		{ServiceName: "Spotify", MonthlyCost: 10, UserID: userID, StartDate: start, EndDate: &ended},
+		// Годовая предоплата: 4 месяца по 1200/12
+		{ServiceName: "Yandex Plus", MonthlyCost: 100, PrepaidAmount: &prepaid, UserID: userID, StartDate: start},
+		// Черновики не учитываются
+		{ServiceName: "Draft", MonthlyCost: 1000, UserID: userID, StartDate: start, IsDraft: true},
+	}
+	for _, sub := range subs {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+	// Подписка другой организации не видна
+	if err := repo.Create(ctxutil.WithTenantID(ctx, "other"), &model.Subscription{ServiceName: "Netflix", MonthlyCost: 5000, UserID: userID, StartDate: start}); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	netflix := *subs[0]
+	netflix.Status = model.StatusPaused
+	if err := repo.Update(ctx, netflix.ID, &netflix); err != nil {
+		t.Fatalf("failed to pause subscription: %v", err)
+	}
+
+	totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: start.Format("01-2006"),
+		EndPeriod:   current.Format("01-2006"),
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+
+	for name, tc := range map[string]struct{ got, want string }{
+		"total":     {totals.Total.RatString(), "720"},
+		"active":    {totals.Active.RatString(), "700"},
+		"cancelled": {totals.Cancelled.RatString(), "20"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %s, want %s", name, tc.got, tc.want)
+		}
+	}
+
+	// Возобновление в том же месяце удаляет пустую паузу
+	netflix.Status = model.StatusActive
+	if err := repo.Update(ctx, netflix.ID, &netflix); err != nil {
+		t.Fatalf("failed to resume subscription: %v", err)
+	}
+	totals, err = repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: current.Format("01-2006"),
+		EndPeriod:   current.Format("01-2006"),
+		ServiceName: "Netflix",
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	if got := totals.Total.RatString(); got != "100" {
+		t.Errorf("total after resume = %s, want 100", got)
+	}
+}
+
+func TestInMemoryListAfter(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemorySubscriptionRepository()
+	for i := 0; i < 5; i++ {
+		if err := repo.Create(ctx, &model.Subscription{ServiceName: "Netflix", MonthlyCost: 100, UserID: uuid.New(), StartDate: model.CurrentMonth()}); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	var seen []uuid.UUID
+	var cursor *model.SubscriptionCursor
+	for {
+		page, err := repo.ListAfter(ctx, model.SubscriptionFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListAfter: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, sub := range page {
+			seen = append(seen, sub.ID)
+		}
+		last := page[len(page)-1]
+		cursor = &model.SubscriptionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	_, total, err := repo.List(ctx, model.SubscriptionFilter{}, model.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(seen) != 5 || total != 5 {
+		t.Errorf("ListAfter returned %d subscriptions, List total %d, want 5", len(seen), total)
+	}
+}
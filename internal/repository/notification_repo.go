@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository interface {
+	// Get возвращает настройки уведомлений пользователя или nil, если их нет
+	Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error)
+	// Save создает или заменяет настройки и заполняет UpdatedAt
+	Save(ctx context.Context, prefs *model.NotificationPreferences) error
+	// Delete удаляет настройки; отсутствие настроек не ошибка
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type notificationRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewNotificationRepository(db *sql.DB, logger *logger.Logger) NotificationRepository {
+	return &notificationRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *notificationRepo) Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	query := `
+		SELECT user_id, email, renewal_reminders, cancellations, updated_at
+		FROM notification_preferences
+		WHERE tenant_id = $1 AND user_id = $2
+	`
+
+	var prefs model.NotificationPreferences
+	err := r.db.QueryRowContext(ctx, query, ctxutil.TenantID(ctx), userID).Scan(
+		&prefs.UserID,
+		&prefs.Email,
+		&prefs.RenewalReminders,
+		&prefs.Cancellations,
+		&prefs.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get notification preferences from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+func (r *notificationRepo) Save(ctx context.Context, prefs *model.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (tenant_id, user_id, email, renewal_reminders, cancellations)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			renewal_reminders = EXCLUDED.renewal_reminders,
+			cancellations = EXCLUDED.cancellations,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		ctxutil.TenantID(ctx),
+		prefs.UserID,
+		prefs.Email,
+		prefs.RenewalReminders,
+		prefs.Cancellations,
+	).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save notification preferences in database",
+			"user_id", prefs.UserID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM notification_preferences WHERE tenant_id = $1 AND user_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, ctxutil.TenantID(ctx), userID); err != nil {
+		r.logger.Error(ctx, "Failed to delete notification preferences from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+	return nil
+}
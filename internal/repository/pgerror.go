@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды ошибок PostgreSQL (SQLSTATE), которые репозитории обрабатывают отдельно
+const (
+	// uniqueViolation - нарушение ограничения UNIQUE
+	uniqueViolation = "23505"
+	// foreignKeyViolation - ссылка на несуществующую строку
+	foreignKeyViolation = "23503"
+	// integrityViolationClass - класс кодов о нарушении ограничений
+	integrityViolationClass = "23"
+)
+
+// pgError - подробности ошибки сервера PostgreSQL. Только asPgError знает тип ошибки
+// драйвера, поэтому смена драйвера не затрагивает обработку ошибок в репозиториях
+type pgError struct {
+	Code       string
+	Constraint string
+	Message    string
+	// Where - контекст ошибки, например "COPY subscriptions, line 3"
+	Where string
+}
+
+// Class возвращает класс кода - первые два символа SQLSTATE
+func (e *pgError) Class() string {
+	if len(e.Code) < 2 {
+		return e.Code
+	}
+	return e.Code[:2]
+}
+
+// asPgError извлекает ошибку сервера PostgreSQL из цепочки err
+func asPgError(err error) (*pgError, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil, false
+	}
+	return &pgError{
+		Code:       pgErr.Code,
+		Constraint: pgErr.ConstraintName,
+		Message:    pgErr.Message,
+		Where:      pgErr.Where,
+	}, true
+}
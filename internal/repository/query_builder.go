@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operator - разрешенный оператор сравнения в динамических условиях
+type operator string
+
+const (
+	opEq    operator = "="
+	opNotEq operator = "<>"
+	opLt    operator = "<"
+	opLte   operator = "<="
+	opGt    operator = ">"
+	opGte   operator = ">="
+	opILike operator = "ILIKE"
+	opIn    operator = "IN"
+	opNotIn operator = "NOT IN"
+)
+
+var allowedOperators = map[operator]struct{}{
+	opEq: {}, opNotEq: {}, opLt: {}, opLte: {}, opGt: {}, opGte: {}, opILike: {}, opIn: {}, opNotIn: {},
+}
+
+// columnSet - белый список колонок, по которым разрешено строить условия
+type columnSet map[string]struct{}
+
+func newColumnSet(columns ...string) columnSet {
+	set := make(columnSet, len(columns))
+	for _, column := range columns {
+		set[column] = struct{}{}
+	}
+	return set
+}
+
+// subscriptionFilterColumns - колонки subscriptions, доступные для динамической фильтрации
+var subscriptionFilterColumns = newColumnSet(
+	"id",
+	"tenant_id",
+	"user_id",
+	"service_name",
+	"service_id",
+	"monthly_cost",
+	"start_date",
+	"end_date",
+	"is_draft",
+	"status",
+	"created_at",
+	"updated_at",
+)
+
+// whereBuilder собирает условия WHERE только из колонок белого списка
+// и параметризованных значений. Значения никогда не попадают в текст запроса.
+type whereBuilder struct {
+	columns    columnSet
+	conditions []string
+	args       []interface{}
+	err        error
+}
+
+// newWhereBuilder создает построитель условий. Переданные args уже заняли
+// плейсхолдеры $1..$N в запросе, новые значения продолжают нумерацию.
+func newWhereBuilder(columns columnSet, args ...interface{}) *whereBuilder {
+	return &whereBuilder{
+		columns: columns,
+		args:    append([]interface{}{}, args...),
+	}
+}
+
+func (b *whereBuilder) check(column string, op operator) bool {
+	if b.err != nil {
+		return false
+	}
+	if _, ok := b.columns[column]; !ok {
+		b.err = fmt.Errorf("column %q is not allowed in filters", column)
+		return false
+	}
+	if _, ok := allowedOperators[op]; !ok {
+		b.err = fmt.Errorf("operator %q is not allowed in filters", op)
+		return false
+	}
+	return true
+}
+
+func (b *whereBuilder) placeholder(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// Where добавляет условие "column op value"
+func (b *whereBuilder) Where(column string, op operator, value interface{}) *whereBuilder {
+	if op == opIn || op == opNotIn {
+		b.err = fmt.Errorf("use In/NotIn for operator %q", op)
+		return b
+	}
+	if !b.check(column, op) {
+		return b
+	}
+
+	b.conditions = append(b.conditions, fmt.Sprintf("%s %s %s", column, op, b.placeholder(value)))
+	return b
+}
+
+// In добавляет условие "column IN (...)". Пустой список не добавляет условие.
+func (b *whereBuilder) In(column string, values []interface{}) *whereBuilder {
+	return b.list(column, opIn, values)
+}
+
+// NotIn добавляет условие "column NOT IN (...)". Пустой список не добавляет условие.
+func (b *whereBuilder) NotIn(column string, values []interface{}) *whereBuilder {
+	return b.list(column, opNotIn, values)
+}
+
+func (b *whereBuilder) list(column string, op operator, values []interface{}) *whereBuilder {
+	if !b.check(column, op) || len(values) == 0 {
+		return b
+	}
+
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = b.placeholder(value)
+	}
+
+	b.conditions = append(b.conditions, fmt.Sprintf("%s %s (%s)", column, op, strings.Join(placeholders, ", ")))
+	return b
+}
+
+// HasTag добавляет условие "у подписки есть тег name". Условие ссылается на id
+// подписки, поэтому id должен быть в белом списке
+func (b *whereBuilder) HasTag(name string) *whereBuilder {
+	if !b.check("id", opIn) {
+		return b
+	}
+
+	b.conditions = append(b.conditions, fmt.Sprintf(
+		"id IN (SELECT st.subscription_id FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE t.name = %s)",
+		b.placeholder(name),
+	))
+	return b
+}
+
+// Build возвращает условия, соединенные через AND (без ведущего AND), и все аргументы запроса
+func (b *whereBuilder) Build() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return strings.Join(b.conditions, " AND "), b.args, nil
+}
+
+// interfaceSlice преобразует значения для In/NotIn
+func interfaceSlice[T any](values []T) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// appendConditions добавляет собранные условия к запросу, который уже содержит WHERE
+func appendConditions(query, conditions string) string {
+	if conditions == "" {
+		return query
+	}
+	return query + " AND " + conditions
+}
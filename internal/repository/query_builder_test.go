@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestWhereBuilder(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func() *whereBuilder
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name: "continues placeholder numbering after existing args",
+			build: func() *whereBuilder {
+				return newWhereBuilder(subscriptionFilterColumns, "end", "start").
+					Where("user_id", opEq, "u1").
+					Where("service_name", opEq, "Netflix")
+			},
+			wantSQL:  "user_id = $3 AND service_name = $4",
+			wantArgs: []interface{}{"end", "start", "u1", "Netflix"},
+		},
+		{
+			name: "in and not in lists",
+			build: func() *whereBuilder {
+				return newWhereBuilder(subscriptionFilterColumns).
+					In("service_name", []interface{}{"a", "b"}).
+					NotIn("user_id", []interface{}{"u1"})
+			},
+			wantSQL:  "service_name IN ($1, $2) AND user_id NOT IN ($3)",
+			wantArgs: []interface{}{"a", "b", "u1"},
+		},
+		{
+			name: "empty list adds nothing",
+			build: func() *whereBuilder {
+				return newWhereBuilder(subscriptionFilterColumns).NotIn("user_id", nil)
+			},
+			wantSQL:  "",
+			wantArgs: []interface{}{},
+		},
+		{
+			name: "unknown column is rejected",
+			build: func() *whereBuilder {
+				return newWhereBuilder(subscriptionFilterColumns).
+					Where("service_name = '' OR 1=1 --", opEq, "x")
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown operator is rejected",
+			build: func() *whereBuilder {
+				return newWhereBuilder(subscriptionFilterColumns).
+					Where("user_id", operator("= ANY"), "x")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.build().Build()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got query %q", sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"Yandex Plus": "Yandex Plus",
+		"100%":        `100\%`,
+		"a_b":         `a\_b`,
+		`c:\path`:     `c:\\path`,
+	}
+	for in, want := range tests {
+		if got := escapeLike(in); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildSubscriptionFilterExclusions(t *testing.T) {
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	ctx := ctxutil.WithTenantID(context.Background(), "acme")
+
+	conditions, args, err := buildSubscriptionFilter(ctx, model.SubscriptionFilter{
+		ExcludeServiceNames: []string{"Yandex Plus", "Netflix"},
+		ExcludeUserIDs:      []uuid.UUID{userID},
+	}, "cursor")
+	if err != nil {
+		t.Fatalf("buildSubscriptionFilter() error = %v", err)
+	}
+
+	wantSQL := "tenant_id = $2 AND service_name NOT IN ($3, $4) AND user_id NOT IN ($5)"
+	if conditions != wantSQL {
+		t.Errorf("conditions = %q, want %q", conditions, wantSQL)
+	}
+	wantArgs := []interface{}{"cursor", "acme", "Yandex Plus", "Netflix", userID}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
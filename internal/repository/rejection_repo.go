@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type RejectionRepository interface {
+	// Create сохраняет отклоненный запрос и заполняет ID и CreatedAt
+	Create(ctx context.Context, rejection *model.RejectedRequest) error
+	// List возвращает отклоненные запросы организации, начиная с последних
+	List(ctx context.Context, filter model.RejectionFilter) ([]*model.RejectedRequest, error)
+	// DeleteBefore удаляет записи всех организаций старше before и возвращает их число
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// rejectionFilterColumns - колонки rejected_requests, доступные для фильтрации
+var rejectionFilterColumns = newColumnSet(
+	"tenant_id",
+	"user_id",
+	"reason",
+	"route",
+	"created_at",
+)
+
+type rejectionRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewRejectionRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) RejectionRepository {
+	return &rejectionRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *rejectionRepo) Create(ctx context.Context, rejection *model.RejectedRequest) error {
+	query := `
+		INSERT INTO rejected_requests (tenant_id, user_id, method, route, status, reason, message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		ctxutil.TenantID(ctx),
+		rejection.UserID,
+		rejection.Method,
+		rejection.Route,
+		rejection.Status,
+		rejection.Reason,
+		rejection.Message,
+	).Scan(&rejection.ID, &rejection.CreatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to create rejected request in database",
+			"error", err,
+		)
+		return fmt.Errorf("failed to create rejected request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rejectionRepo) List(ctx context.Context, filter model.RejectionFilter) ([]*model.RejectedRequest, error) {
+	query := `
+		SELECT id, user_id, method, route, status, reason, message, created_at
+		FROM rejected_requests
+		WHERE 1=1
+	`
+
+	where := newWhereBuilder(rejectionFilterColumns)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.UserID != nil {
+		where.Where("user_id", opEq, *filter.UserID)
+	}
+	if filter.Reason != "" {
+		where.Where("reason", opEq, filter.Reason)
+	}
+	if filter.Route != "" {
+		where.Where("route", opEq, filter.Route)
+	}
+	if filter.Since != nil {
+		where.Where("created_at", opGte, *filter.Since)
+	}
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build rejected requests filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	query = appendConditions(query, conditions) + " ORDER BY created_at DESC, id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	r.logger.Debug(ctx, "Executing dynamic query",
+		"query", query,
+		"args_count", len(args),
+	)
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list rejected requests from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list rejected requests: %w", err)
+	}
+	defer rows.Close()
+
+	var rejections []*model.RejectedRequest
+	for rows.Next() {
+		var rejection model.RejectedRequest
+		err := rows.Scan(
+			&rejection.ID,
+			&rejection.UserID,
+			&rejection.Method,
+			&rejection.Route,
+			&rejection.Status,
+			&rejection.Reason,
+			&rejection.Message,
+			&rejection.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan rejected request row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan rejected request: %w", err)
+		}
+		rejections = append(rejections, &rejection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rejected requests: %w", err)
+	}
+
+	r.queries.Observe("rejected_requests.list", len(rejections), time.Since(start))
+
+	return rejections, nil
+}
+
+func (r *rejectionRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM rejected_requests WHERE created_at < $1`, before)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete old rejected requests",
+			"error", err,
+		)
+		return 0, fmt.Errorf("failed to delete rejected requests: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type ReminderRepository interface {
+	// ListDue возвращает действующие подписки всех организаций, период которых заканчивается
+	// в окне window, без уже отправленных напоминаний
+	ListDue(ctx context.Context, window model.ReminderWindow) ([]model.RenewalReminder, error)
+	// MarkSent отмечает напоминание отправленным
+	MarkSent(ctx context.Context, reminder model.RenewalReminder) error
+
+	// GetSettings возвращает настройки напоминаний подписки или nil, если их нет
+	GetSettings(ctx context.Context, subscriptionID uuid.UUID) (*model.ReminderSettings, error)
+	// SaveSettings создает или заменяет настройки и заполняет UpdatedAt. Подписка
+	// вне организации запроса - ErrNotFound
+	SaveSettings(ctx context.Context, settings *model.ReminderSettings) error
+	// DeleteSettings удаляет настройки; отсутствие настроек не ошибка
+	DeleteSettings(ctx context.Context, subscriptionID uuid.UUID) error
+}
+
+type reminderRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewReminderRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) ReminderRepository {
+	return &reminderRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *reminderRepo) ListDue(ctx context.Context, window model.ReminderWindow) ([]model.RenewalReminder, error) {
+	// Период подписки с end_date заканчивается в начале следующего за end_date месяца;
+	// у бессрочной подписки окончание одно для всех - ближайшее продление. Срок из
+	// настроек подписки заменяет общий
+	query := `
+		SELECT s.id, s.tenant_id, s.user_id, s.service_name, s.monthly_cost, s.end_date IS NULL, d.renews_at,
+			COALESCE(rs.channel, '')
+		FROM subscriptions s
+		LEFT JOIN subscription_reminder_settings rs ON rs.subscription_id = s.id
+		CROSS JOIN LATERAL (
+			SELECT COALESCE((s.end_date + INTERVAL '1 month')::date, $3::date) AS renews_at
+		) d
+		WHERE s.status = 'active' AND NOT s.is_draft
+			AND d.renews_at > $1::date AND d.renews_at <= COALESCE($1::date + rs.days, $2::date)
+			AND NOT EXISTS (
+				SELECT 1 FROM renewal_reminders rr
+				WHERE rr.subscription_id = s.id AND rr.renews_at = d.renews_at
+			)
+		ORDER BY d.renews_at, s.id
+	`
+
+	var openEnded *string
+	if window.OpenEndedRenewal != nil {
+		date := window.OpenEndedRenewal.Format(time.DateOnly)
+		openEnded = &date
+	}
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, window.After.Format(time.DateOnly), window.Until.Format(time.DateOnly), openEnded)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list due renewal reminders from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list due renewal reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []model.RenewalReminder
+	for rows.Next() {
+		var reminder model.RenewalReminder
+		var renewal bool
+		if err := rows.Scan(
+			&reminder.SubscriptionID,
+			&reminder.Tenant,
+			&reminder.UserID,
+			&reminder.ServiceName,
+			&reminder.MonthlyCost,
+			&renewal,
+			&reminder.RenewsAt,
+			&reminder.Channel,
+		); err != nil {
+			r.logger.Error(ctx, "Failed to scan renewal reminder",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan renewal reminder: %w", err)
+		}
+		reminder.Kind = model.ReminderExpiring
+		if renewal {
+			reminder.Kind = model.ReminderRenewal
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Error iterating renewal reminders rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list due renewal reminders: %w", err)
+	}
+	r.queries.Observe("renewal_reminders.list_due", len(reminders), time.Since(start))
+
+	return reminders, nil
+}
+
+func (r *reminderRepo) MarkSent(ctx context.Context, reminder model.RenewalReminder) error {
+	query := `
+		INSERT INTO renewal_reminders (subscription_id, renews_at, kind)
+		VALUES ($1, $2::date, $3)
+		ON CONFLICT (subscription_id, renews_at) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, reminder.SubscriptionID, reminder.RenewsAt.Format(time.DateOnly), reminder.Kind); err != nil {
+		r.logger.Error(ctx, "Failed to mark renewal reminder as sent",
+			"subscription_id", reminder.SubscriptionID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to mark renewal reminder as sent: %w", err)
+	}
+	return nil
+}
+
+func (r *reminderRepo) GetSettings(ctx context.Context, subscriptionID uuid.UUID) (*model.ReminderSettings, error) {
+	query := `
+		SELECT rs.subscription_id, rs.days, rs.channel, rs.updated_at
+		FROM subscription_reminder_settings rs
+		JOIN subscriptions s ON s.id = rs.subscription_id
+		WHERE rs.subscription_id = $1 AND s.tenant_id = $2
+	`
+
+	var settings model.ReminderSettings
+	err := r.db.QueryRowContext(ctx, query, subscriptionID, ctxutil.TenantID(ctx)).Scan(
+		&settings.SubscriptionID,
+		&settings.Days,
+		&settings.Channel,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get reminder settings from database",
+			"subscription_id", subscriptionID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+	return &settings, nil
+}
+
+func (r *reminderRepo) SaveSettings(ctx context.Context, settings *model.ReminderSettings) error {
+	query := `
+		INSERT INTO subscription_reminder_settings (subscription_id, days, channel)
+		SELECT id, $3, $4 FROM subscriptions WHERE id = $1 AND tenant_id = $2
+		ON CONFLICT (subscription_id) DO UPDATE SET
+			days = EXCLUDED.days,
+			channel = EXCLUDED.channel,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		settings.SubscriptionID,
+		ctxutil.TenantID(ctx),
+		settings.Days,
+		settings.Channel,
+	).Scan(&settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save reminder settings in database",
+			"subscription_id", settings.SubscriptionID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to save reminder settings: %w", err)
+	}
+	return nil
+}
+
+func (r *reminderRepo) DeleteSettings(ctx context.Context, subscriptionID uuid.UUID) error {
+	query := `
+		DELETE FROM subscription_reminder_settings
+		WHERE subscription_id = $1
+			AND subscription_id IN (SELECT id FROM subscriptions WHERE tenant_id = $2)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, subscriptionID, ctxutil.TenantID(ctx)); err != nil {
+		r.logger.Error(ctx, "Failed to delete reminder settings from database",
+			"subscription_id", subscriptionID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete reminder settings: %w", err)
+	}
+	return nil
+}
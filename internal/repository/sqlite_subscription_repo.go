@@ -0,0 +1,1368 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/timing"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteTimeLayout - формат времени и дат в базе SQLite (internal/database/sqlite_schema.sql):
+// UTC фиксированной ширины, чтобы строки сравнивались в хронологическом порядке
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// sqliteNow - текущее время в sqliteTimeLayout
+const sqliteNow = `strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')`
+
+// sqliteMonths - месяцы m.month от $2 до $1 включительно, аналог generate_series
+const sqliteMonths = `
+	WITH RECURSIVE m(month) AS (
+		SELECT $2 WHERE $2 <= $1
+		UNION ALL
+		SELECT strftime('%Y-%m-%dT00:00:00.000000Z', month, '+1 month') FROM m WHERE month < $1
+	)
+`
+
+// sqlitePriceJoin присоединяет к подписке s запись истории стоимости p, действующую
+// в месяце m.month, как effectivePriceQuery для Postgres. Без истории колонки p - NULL,
+// и используется стоимость подписки (sqlitePriceColumns)
+const sqlitePriceJoin = `
+	LEFT JOIN subscription_prices p ON p.subscription_id = s.id AND p.effective_from = COALESCE(
+		(SELECT MAX(effective_from) FROM subscription_prices WHERE subscription_id = s.id AND effective_from <= m.month),
+		(SELECT MIN(effective_from) FROM subscription_prices WHERE subscription_id = s.id)
+	)
+`
+
+// sqlitePriceColumns - стоимость в месяце: monthly_cost, billing_period и cost
+const sqlitePriceColumns = `
+	COALESCE(p.monthly_cost, s.monthly_cost),
+	COALESCE(p.billing_period, s.billing_period),
+	CASE WHEN p.subscription_id IS NULL THEN s.cost ELSE p.cost END
+`
+
+// sqliteConn - *sql.DB или *sql.Tx
+type sqliteConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlitePlaceholder - плейсхолдер Postgres $N
+var sqlitePlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// sqliteQuery переводит запрос с плейсхолдерами $N в ?N: SQLite нумерует именованные
+// параметры $N в порядке появления, а не по номеру. Время передается в sqliteTimeLayout
+func sqliteQuery(query string, args []interface{}) (string, []interface{}) {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC().Format(sqliteTimeLayout)
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC().Format(sqliteTimeLayout)
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return sqlitePlaceholder.ReplaceAllString(query, "?$1"), converted
+}
+
+type sqliteSubscriptionRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+// NewSQLiteSubscriptionRepository создает репозиторий подписок в базе SQLite
+// (DB_DRIVER=sqlite) для одноузловых установок и локальной разработки. Журнал изменений,
+// номера изменений и учет пауз ведутся как в PostgreSQL. Скидки хранит DiscountRepository
+// в PostgreSQL, поэтому расчеты стоимости здесь их не учитывают
+func NewSQLiteSubscriptionRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) SubscriptionRepository {
+	return &sqliteSubscriptionRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+// exec, query и queryRow выполняют запрос с параметрами $N и учитывают его время в Server-Timing
+func (r *sqliteSubscriptionRepo) exec(ctx context.Context, conn sqliteConn, query string, args ...interface{}) (sql.Result, error) {
+	query, args = sqliteQuery(query, args)
+	start := time.Now()
+	result, err := conn.ExecContext(ctx, query, args...)
+	timing.FromContext(ctx).Query(time.Since(start))
+	return result, err
+}
+
+func (r *sqliteSubscriptionRepo) query(ctx context.Context, conn sqliteConn, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = sqliteQuery(query, args)
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query, args...)
+	timing.FromContext(ctx).Query(time.Since(start))
+	return rows, err
+}
+
+func (r *sqliteSubscriptionRepo) queryRow(ctx context.Context, conn sqliteConn, query string, args ...interface{}) *sql.Row {
+	query, args = sqliteQuery(query, args)
+	start := time.Now()
+	row := conn.QueryRowContext(ctx, query, args...)
+	timing.FromContext(ctx).Query(time.Since(start))
+	return row
+}
+
+// insert вставляет подписку и читает поля, которые заполняют значения по умолчанию и триггеры
+func (r *sqliteSubscriptionRepo) insert(ctx context.Context, tx *sql.Tx, sub *model.Subscription) error {
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+	}
+	if sub.Status == "" {
+		sub.Status = model.StatusActive
+	}
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = model.BillingMonthly
+	}
+
+	_, err := r.exec(ctx, tx, `
+		INSERT INTO subscriptions (id, service_name, monthly_cost, user_id, start_date, end_date, prepaid_amount, is_draft, status, tenant_id, metadata, service_id, description, billing_period, cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		sub.ID,
+		sub.ServiceName,
+		sub.MonthlyCost,
+		sub.UserID,
+		sub.StartDate,
+		sub.EndDate,
+		sub.PrepaidAmount,
+		sub.IsDraft,
+		sub.Status,
+		ctxutil.TenantID(ctx),
+		sub.Metadata,
+		sub.ServiceID,
+		sub.Description,
+		sub.BillingPeriod,
+		sub.Cost,
+	)
+	if err != nil {
+		return err
+	}
+	if err := r.setTags(ctx, tx, sub.ID, sub.Tags); err != nil {
+		return err
+	}
+
+	// RETURNING не видит изменений, сделанных триггерами после вставки
+	return r.queryRow(ctx, tx, `SELECT change_seq, created_at, updated_at FROM subscriptions WHERE id = $1`, sub.ID).
+		Scan(&sub.ChangeSeq, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+// setTags заменяет теги подписки в транзакции tx
+func (r *sqliteSubscriptionRepo) setTags(ctx context.Context, tx *sql.Tx, id uuid.UUID, tags model.Tags) error {
+	return writeSubscriptionTags(func(query string, args ...interface{}) error {
+		_, err := r.exec(ctx, tx, query, args...)
+		return err
+	}, ctxutil.TenantID(ctx), id, tags)
+}
+
+func (r *sqliteSubscriptionRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	r.logger.Debug(ctx, "Creating subscription in database",
+		"service_name", sub.ServiceName,
+		"user_id", sub.UserID,
+		"monthly_cost", sub.MonthlyCost,
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sub.ID = uuid.Nil
+	if err := r.insert(ctx, tx, sub); err != nil {
+		r.logger.Error(ctx, "Failed to create subscription in database",
+			"service_name", sub.ServiceName,
+			"user_id", sub.UserID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscription created successfully",
+		"subscription_id", sub.ID,
+		"service_name", sub.ServiceName,
+	)
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	r.logger.Debug(ctx, "Creating subscriptions batch in database",
+		"count", len(subs),
+	)
+
+	start := time.Now()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, sub := range subs {
+		sub.ID = uuid.Nil
+		if err := r.insert(ctx, tx, sub); err != nil {
+			var sqliteErr sqlite3.Error
+			if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+				r.logger.Warn(ctx, "Subscription in batch violates constraint",
+					"row", i,
+					"error", err,
+				)
+				return &BatchRowError{Row: i, Action: "create", Message: err.Error()}
+			}
+			r.logger.Error(ctx, "Failed to insert subscriptions batch",
+				"error", err,
+			)
+			return fmt.Errorf("failed to create subscriptions: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscriptions batch created successfully",
+		"count", len(subs),
+	)
+
+	r.queries.Observe("subscriptions.create_batch", len(subs), time.Since(start))
+
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	query := `
+		SELECT ` + sqliteSubscriptionColumns + `
+		FROM subscriptions
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	sub, err := scanSubscription(r.queryRow(ctx, r.db, query, id, ctxutil.TenantID(ctx)))
+	if err == sql.ErrNoRows {
+		r.logger.Debug(ctx, "Subscription not found in database",
+			"subscription_id", id,
+		)
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get subscription from database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *sqliteSubscriptionRepo) Update(ctx context.Context, id uuid.UUID, sub *model.Subscription) error {
+	r.logger.Info(ctx, "Updating subscription in database",
+		"subscription_id", id,
+		"service_name", sub.ServiceName,
+		"user_id", sub.UserID,
+		"status", sub.Status,
+	)
+
+	// Транзакция сразу берет блокировку записи (_txlock=immediate), поэтому состояние
+	// не меняется между чтением и обновлением
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin subscription update transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.update(ctx, tx, id, sub); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscription update transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscription updated successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+// update сохраняет подписку в транзакции tx и ведет учет месяцев приостановки
+func (r *sqliteSubscriptionRepo) update(ctx context.Context, tx *sql.Tx, id uuid.UUID, sub *model.Subscription) error {
+	var previous string
+	err := r.queryRow(ctx, tx, `SELECT status FROM subscriptions WHERE id = $1 AND tenant_id = $2`, id, ctxutil.TenantID(ctx)).Scan(&previous)
+	if err == sql.ErrNoRows {
+		r.logger.Warn(ctx, "Subscription not found for update",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to read subscription for update",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	if sub.PriceFrom != nil {
+		if err := r.recordPrice(ctx, tx, id, sub); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.exec(ctx, tx, `
+		UPDATE subscriptions
+		SET service_name = $1, monthly_cost = $2, user_id = $3, start_date = $4, end_date = $5, prepaid_amount = $6,
+			status = COALESCE(NULLIF($7, ''), status), metadata = $8, service_id = $9, description = $10,
+			billing_period = COALESCE(NULLIF($11, ''), 'monthly'), cost = $12
+		WHERE id = $13
+	`,
+		sub.ServiceName,
+		sub.MonthlyCost,
+		sub.UserID,
+		sub.StartDate,
+		sub.EndDate,
+		sub.PrepaidAmount,
+		sub.Status,
+		sub.Metadata,
+		sub.ServiceID,
+		sub.Description,
+		sub.BillingPeriod,
+		sub.Cost,
+		id,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to update subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if err := r.setTags(ctx, tx, id, sub.Tags); err != nil {
+		r.logger.Error(ctx, "Failed to save subscription tags",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+
+	if sub.Status != "" && sub.Status != previous {
+		if err := r.recordPause(ctx, tx, id, previous, sub.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) UpdateBatch(ctx context.Context, updates []model.SubscriptionUpdate) error {
+	start := time.Now()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin subscriptions batch update transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, u := range updates {
+		if err := r.update(ctx, tx, u.ID, u.Subscription); err != nil {
+			return sqliteBatchRowError(i, "update", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch update transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.queries.Observe("subscriptions.update_batch", len(updates), time.Since(start))
+
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) DeleteBatch(ctx context.Context, ids []uuid.UUID) error {
+	start := time.Now()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin subscriptions batch delete transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.deleteRows(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch delete transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.queries.Observe("subscriptions.delete_batch", len(ids), time.Since(start))
+
+	return nil
+}
+
+// sqliteBatchRowError относит к элементу row пачки отсутствие подписки и нарушение
+// ограничения; остальные ошибки касаются всей пачки
+// deleteRows удаляет подписки ids в транзакции tx; отсутствующая подписка возвращается как *BatchRowError
+func (r *sqliteSubscriptionRepo) deleteRows(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) error {
+	for i, id := range ids {
+		result, err := r.exec(ctx, tx, `DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2`, id, ctxutil.TenantID(ctx))
+		if err != nil {
+			r.logger.Error(ctx, "Failed to delete subscription from database",
+				"subscription_id", id,
+				"error", err,
+			)
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return &BatchRowError{Row: i, Action: "delete", Message: "subscription not found"}
+		}
+	}
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) Sync(ctx context.Context, creates []*model.Subscription, updates []model.SubscriptionUpdate, deletes []uuid.UUID) error {
+	start := time.Now()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin subscriptions sync transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.deleteRows(ctx, tx, deletes); err != nil {
+		return err
+	}
+	for i, u := range updates {
+		if err := r.update(ctx, tx, u.ID, u.Subscription); err != nil {
+			return sqliteBatchRowError(i, "update", err)
+		}
+	}
+	for i, sub := range creates {
+		sub.ID = uuid.Nil
+		if err := r.insert(ctx, tx, sub); err != nil {
+			return sqliteBatchRowError(i, "create", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions sync transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.queries.Observe("subscriptions.sync", len(creates)+len(updates)+len(deletes), time.Since(start))
+
+	return nil
+}
+
+func sqliteBatchRowError(row int, action string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return &BatchRowError{Row: row, Action: action, Message: err.Error()}
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return &BatchRowError{Row: row, Action: action, Message: sqliteErr.Error()}
+	}
+	return err
+}
+
+// recordPause повторяет subscriptionRepo.recordPause; предыдущий месяц вычисляется
+// заранее, а не в запросе
+func (r *sqliteSubscriptionRepo) recordPause(ctx context.Context, tx *sql.Tx, id uuid.UUID, from, to string) error {
+	month := model.CurrentMonth()
+
+	type statement struct {
+		query string
+		args  []interface{}
+	}
+	var statements []statement
+	switch {
+	case to == model.StatusPaused:
+		statements = []statement{
+			{`INSERT INTO subscription_pauses (subscription_id, start_date) VALUES ($1, $2)`, []interface{}{id, month}},
+		}
+	case from == model.StatusPaused && to == model.StatusActive:
+		statements = []statement{
+			{`DELETE FROM subscription_pauses WHERE subscription_id = $1 AND end_date IS NULL AND start_date >= $2`, []interface{}{id, month}},
+			{`UPDATE subscription_pauses SET end_date = $3 WHERE subscription_id = $1 AND end_date IS NULL AND start_date < $2`, []interface{}{id, month, month.AddDate(0, -1, 0)}},
+		}
+	default:
+		return nil
+	}
+
+	for _, stmt := range statements {
+		if _, err := r.exec(ctx, tx, stmt.query, stmt.args...); err != nil {
+			r.logger.Error(ctx, "Failed to record subscription pause",
+				"subscription_id", id,
+				"from", from,
+				"to", to,
+				"error", err,
+			)
+			return fmt.Errorf("failed to record subscription pause: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordPrice повторяет subscriptionRepo.recordPrice
+func (r *sqliteSubscriptionRepo) recordPrice(ctx context.Context, tx *sql.Tx, id uuid.UUID, sub *model.Subscription) error {
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`
+			INSERT INTO subscription_prices (subscription_id, effective_from, monthly_cost, billing_period, cost)
+			SELECT id, start_date, monthly_cost, billing_period, cost
+			FROM subscriptions
+			WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM subscription_prices WHERE subscription_id = $1)
+		`, []interface{}{id}},
+		{`DELETE FROM subscription_prices WHERE subscription_id = $1 AND effective_from >= $2`, []interface{}{id, *sub.PriceFrom}},
+		{`
+			INSERT INTO subscription_prices (subscription_id, effective_from, monthly_cost, billing_period, cost)
+			VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'monthly'), $5)
+		`, []interface{}{id, *sub.PriceFrom, sub.MonthlyCost, sub.BillingPeriod, sub.Cost}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := r.exec(ctx, tx, stmt.query, stmt.args...); err != nil {
+			r.logger.Error(ctx, "Failed to record subscription price",
+				"subscription_id", id,
+				"effective_from", sub.PriceFrom,
+				"error", err,
+			)
+			return fmt.Errorf("failed to record subscription price: %w", err)
+		}
+	}
+	return nil
+}
+
+// execAffected выполняет изменение одной подписки и сообщает, нашлась ли строка
+func (r *sqliteSubscriptionRepo) execAffected(ctx context.Context, id uuid.UUID, action, query string, args ...interface{}) (bool, error) {
+	result, err := r.exec(ctx, r.db, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to "+action+" subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to %s subscription: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"subscription_id", id,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *sqliteSubscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.logger.Info(ctx, "Deleting subscription from database",
+		"subscription_id", id,
+	)
+
+	found, err := r.execAffected(ctx, id, "delete", `DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2`, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	if !found {
+		r.logger.Warn(ctx, "Subscription not found for deletion",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) Activate(ctx context.Context, id uuid.UUID) error {
+	r.logger.Info(ctx, "Activating draft subscription in database",
+		"subscription_id", id,
+	)
+
+	found, err := r.execAffected(ctx, id, "activate", `UPDATE subscriptions SET is_draft = FALSE WHERE id = $1 AND tenant_id = $2 AND is_draft`, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	if !found {
+		r.logger.Warn(ctx, "Draft subscription not found for activation",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason *string) error {
+	query := `
+		UPDATE subscriptions
+		SET status = 'cancelled', end_date = $1, cancel_reason = $2, cancelled_at = ` + sqliteNow + `
+		WHERE id = $3 AND tenant_id = $4
+	`
+
+	r.logger.Info(ctx, "Cancelling subscription in database",
+		"subscription_id", id,
+		"end_date", endDate,
+	)
+
+	found, err := r.execAffected(ctx, id, "cancel", query, endDate, reason, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	if !found {
+		r.logger.Warn(ctx, "Subscription not found for cancellation",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) Transfer(ctx context.Context, id, from, to uuid.UUID, reason *string) error {
+	r.logger.Info(ctx, "Transferring subscription in database",
+		"subscription_id", id,
+		"from_user_id", from,
+		"to_user_id", to,
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := r.exec(ctx, tx, `
+		UPDATE subscriptions
+		SET user_id = $1
+		WHERE id = $2 AND user_id = $3 AND tenant_id = $4 AND status <> 'cancelled'
+	`, to, id, from, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to transfer subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to transfer subscription: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Subscription changed before transfer",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription was changed concurrently: %w", ErrConflict)
+	}
+
+	if _, err := r.exec(ctx, tx, `
+		INSERT INTO subscription_transfers (subscription_id, from_user_id, to_user_id, reason)
+		VALUES ($1, $2, $3, $4)
+	`, id, from, to, reason); err != nil {
+		r.logger.Error(ctx, "Failed to record subscription transfer",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to record transfer: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscription transferred successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) List(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) ([]*model.Subscription, int, error) {
+	conditions, args, err := buildSubscriptionFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	var total int
+	countQuery := appendConditions("SELECT COUNT(*) FROM subscriptions WHERE 1=1", conditions)
+	if err := r.queryRow(ctx, r.db, countQuery, args...).Scan(&total); err != nil {
+		r.logger.Error(ctx, "Failed to count subscriptions in database",
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	query := appendConditions(`SELECT `+sqliteSubscriptionColumns+` FROM subscriptions WHERE 1=1`, conditions) +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, page.Limit, page.Offset)
+
+	start := time.Now()
+	subscriptions, err := r.list(ctx, "list", query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.queries.Observe("subscriptions.list", len(subscriptions), time.Since(start))
+
+	return subscriptions, total, nil
+}
+
+// list читает подписки запроса со столбцами sqliteSubscriptionColumns
+func (r *sqliteSubscriptionRepo) list(ctx context.Context, action, query string, args ...interface{}) ([]*model.Subscription, error) {
+	var subscriptions []*model.Subscription
+	err := r.stream(ctx, query, args, func(sub *model.Subscription) error {
+		subscriptions = append(subscriptions, sub)
+		return nil
+	})
+	if err != nil {
+		r.logger.Error(ctx, "Failed to "+action+" subscriptions from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to %s subscriptions: %w", action, err)
+	}
+	return subscriptions, nil
+}
+
+// stream передает в fn подписки запроса по мере чтения строк
+func (r *sqliteSubscriptionRepo) stream(ctx context.Context, query string, args []interface{}, fn func(*model.Subscription) error) error {
+	rows, err := r.query(ctx, r.db, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *sqliteSubscriptionRepo) Search(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error) {
+	// В SQLite нет unaccent и pg_trgm: подписки организации ранжируются так же, как в Postgres,
+	// но в Go. Для одноузловой установки число подписок организации невелико
+	sqlQuery := `SELECT ` + sqliteSubscriptionColumns + ` FROM subscriptions WHERE tenant_id = $1`
+	args := []interface{}{ctxutil.TenantID(ctx)}
+	if userID != nil {
+		sqlQuery += " AND user_id = $2"
+		args = append(args, *userID)
+	}
+
+	start := time.Now()
+	subs, err := r.list(ctx, "search", sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := rankSearch(subs, query, limit)
+
+	r.queries.Observe("subscriptions.search", len(subscriptions), time.Since(start))
+
+	return subscriptions, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) ([]*model.Subscription, error) {
+	conditions, args, err := buildSubscriptionFilter(ctx, filter, limit)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+	query := appendConditions(`SELECT `+sqliteSubscriptionColumns+` FROM subscriptions WHERE 1=1`, conditions)
+	if after != nil {
+		query += fmt.Sprintf(" AND (created_at, id) > ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+	query += " ORDER BY created_at, id LIMIT $1"
+
+	start := time.Now()
+	subscriptions, err := r.list(ctx, "list", query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.queries.Observe("subscriptions.list_after", len(subscriptions), time.Since(start))
+
+	return subscriptions, nil
+}
+
+func (r *sqliteSubscriptionRepo) Stream(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	conditions, args, err := buildSubscriptionFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return fmt.Errorf("failed to build filter: %w", err)
+	}
+	query := appendConditions(`SELECT `+sqliteSubscriptionColumns+` FROM subscriptions WHERE 1=1`, conditions) + " ORDER BY created_at, id"
+
+	// Ошибка fn возвращается без изменений, ошибки чтения - с контекстом
+	var fnErr error
+	start := time.Now()
+	count := 0
+	err = r.stream(ctx, query, args, func(sub *model.Subscription) error {
+		if fnErr = fn(sub); fnErr != nil {
+			return fnErr
+		}
+		count++
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to stream subscriptions from database",
+			"streamed", count,
+			"error", err,
+		)
+		return fmt.Errorf("failed to stream subscriptions: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.stream", count, time.Since(start))
+
+	return nil
+}
+
+func (r *sqliteSubscriptionRepo) ListChanges(ctx context.Context, sinceSeq int64, limit int) ([]*model.SubscriptionChange, error) {
+	query := `
+		SELECT seq, subscription_id, operation, payload, changed_at
+		FROM subscription_changes
+		WHERE seq > $1 AND tenant_id = $3
+		ORDER BY seq
+		LIMIT $2
+	`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query, sinceSeq, limit, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscription changes from database",
+			"since_seq", sinceSeq,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*model.SubscriptionChange
+	for rows.Next() {
+		var change model.SubscriptionChange
+		var payload string
+		if err := rows.Scan(&change.Seq, &change.SubscriptionID, &change.Operation, &payload, &change.ChangedAt); err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription change row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan subscription change: %w", err)
+		}
+		change.Payload = []byte(payload)
+		changes = append(changes, &change)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate subscription change rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription changes: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.list_changes", len(changes), time.Since(start))
+
+	return changes, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error) {
+	query := `
+		SELECT effective_from, monthly_cost, billing_period, cost
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from
+	`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query, id)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscription prices",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription prices: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []model.SubscriptionPrice
+	for rows.Next() {
+		var price model.SubscriptionPrice
+		if err := rows.Scan(&price.From, &price.MonthlyCost, &price.BillingPeriod, &price.Cost); err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription price",
+				"subscription_id", id,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan subscription price: %w", err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Error iterating subscription prices rows",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription prices: %w", err)
+	}
+	r.queries.Observe("subscription_prices.list", len(prices), time.Since(start))
+
+	return prices, nil
+}
+
+func (r *sqliteSubscriptionRepo) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.CostTotals, error) {
+	r.logger.Debug(ctx, "Calculating total cost in database",
+		"start_period", filter.StartPeriod,
+		"end_period", filter.EndPeriod,
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+	)
+
+	startPeriod, err := model.ParseMonthYear(filter.StartPeriod)
+	if err != nil {
+		r.logger.Error(ctx, "Invalid start period format",
+			"start_period", filter.StartPeriod,
+			"error", err,
+		)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start period format, expected MM-YYYY: %w", err)
+	}
+	endPeriod, err := model.ParseMonthYear(filter.EndPeriod)
+	if err != nil {
+		r.logger.Error(ctx, "Invalid end period format",
+			"end_period", filter.EndPeriod,
+			"error", err,
+		)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid end period format, expected MM-YYYY: %w", err)
+	}
+
+	groupKey, err := model.ParseSummaryGroupBy(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// $1 - последний месяц периода, $2 - первый, $3 - начало текущего месяца,
+	// $4 - путь JSON метки при группировке по метке
+	periodStart := time.Date(startPeriod.Year(), startPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(endPeriod.Year(), endPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
+	args := []interface{}{periodEnd, periodStart, model.CurrentMonth()}
+	groupColumn := "NULL"
+	switch {
+	case groupKey != "":
+		// Ключ метки проверен ParseSummaryGroupBy и не содержит кавычек
+		args = append(args, `$."`+groupKey+`"`)
+		groupColumn = "json_extract(s.metadata, $4)"
+	case filter.GroupBy == model.SummaryGroupByService:
+		groupColumn = "s.service_name"
+	}
+
+	// Число месяцев периода, в которые каждая подписка активна и не приостановлена.
+	// Месяцы строит рекурсивный CTE на strftime вместо generate_series, а стоимость
+	// считается в Go: в SQLite нет точного numeric для годовой предоплаты и годовой
+	// стоимости, деленных на 12
+	costs := sqliteMonths + `
+		SELECT
+			s.prepaid_amount,
+			` + sqlitePriceColumns + `,
+			COUNT(*),
+			s.status = 'cancelled' OR (s.end_date IS NOT NULL AND s.end_date < $3),
+			` + groupColumn + `
+		FROM subscriptions s
+		JOIN m ON s.start_date <= m.month AND (s.end_date IS NULL OR s.end_date >= m.month)
+		` + sqlitePriceJoin + `  -- стоимость, действующая в месяце
+		WHERE NOT s.is_draft  -- черновики не учитываются до активации
+			AND ` + notPausedCondition + `  -- месяцы приостановки не учитываются
+	`
+
+	where := newWhereBuilder(subscriptionFilterColumns, args...)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.UserID != uuid.Nil {
+		where.Where("user_id", opEq, filter.UserID)
+	}
+	if filter.ServiceName != "" {
+		where.Where("service_name", opEq, filter.ServiceName)
+	}
+	if filter.ServiceID != nil {
+		where.Where("service_id", opEq, *filter.ServiceID)
+	}
+	if filter.Tag != "" {
+		where.HasTag(filter.Tag)
+	}
+	where.NotIn("service_name", interfaceSlice(filter.ExcludeServiceNames))
+	where.NotIn("user_id", interfaceSlice(filter.ExcludeUserIDs))
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build total cost filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+	query := appendConditions(costs, conditions) + " GROUP BY s.id, p.effective_from"
+
+	rows, err := r.query(ctx, r.db, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate total cost in database",
+			"start_period", filter.StartPeriod,
+			"end_period", filter.EndPeriod,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+	defer rows.Close()
+
+	acc := newCostAccumulator(filter.GroupBy != "")
+	for rows.Next() {
+		var monthlyCost, months int
+		var prepaid, periodCost *int
+		var period string
+		var ended bool
+		var key *string
+		if err := rows.Scan(&prepaid, &monthlyCost, &period, &periodCost, &months, &ended, &key); err != nil {
+			r.logger.Error(ctx, "Failed to scan total cost row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+		}
+
+		cost := new(big.Rat).Mul(monthlyBase(monthlyCost, prepaid, period, periodCost), big.NewRat(int64(months), 1))
+		acc.addCost(key, cost, months, ended)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate total cost rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	totals := acc.result()
+	r.logger.Info(ctx, "Total cost calculated successfully",
+		"total_cost", totals.Total.FloatString(2),
+		"groups", len(totals.Groups),
+		"start_period", filter.StartPeriod,
+		"end_period", filter.EndPeriod,
+	)
+
+	return totals, nil
+}
+
+func (r *sqliteSubscriptionRepo) MonthlySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]model.MonthlySpend, error) {
+	// $1 - последний месяц, $2 - первый
+	query := sqliteMonths + `
+		SELECT m.month, COALESCE(SUM(COALESCE(p.monthly_cost, s.monthly_cost)), 0)
+		FROM m
+		LEFT JOIN subscriptions s
+			ON s.user_id = $3
+			AND s.tenant_id = $4
+			AND NOT s.is_draft
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+			AND ` + notPausedCondition + `
+		` + sqlitePriceJoin + `
+		GROUP BY m.month
+		ORDER BY m.month
+	`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query, to, from, userID, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate monthly spend in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []model.MonthlySpend
+	for rows.Next() {
+		var month model.MonthlySpend
+		var value string
+		if err := rows.Scan(&value, &month.Total); err != nil {
+			r.logger.Error(ctx, "Failed to scan monthly spend row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan monthly spend: %w", err)
+		}
+		if month.Month, err = time.Parse(sqliteTimeLayout, value); err != nil {
+			return nil, fmt.Errorf("failed to parse monthly spend month: %w", err)
+		}
+		spend = append(spend, month)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate monthly spend rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly spend: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.monthly_spend", len(spend), time.Since(start))
+
+	return spend, nil
+}
+
+func (r *sqliteSubscriptionRepo) MonthlyCharges(ctx context.Context, userID uuid.UUID, month time.Time) ([]model.MonthlyCharge, error) {
+	query := `
+		SELECT s.id, s.service_name, s.prepaid_amount, ` + sqlitePriceColumns + `
+		FROM subscriptions s
+		CROSS JOIN (SELECT $2 AS month) AS m
+		` + sqlitePriceJoin + `
+		WHERE s.user_id = $1
+			AND s.tenant_id = $3
+			AND NOT s.is_draft
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+			AND ` + notPausedCondition + `
+		ORDER BY s.service_name, s.id
+	`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query, userID, month, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate monthly charges in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []model.MonthlyCharge
+	for rows.Next() {
+		var charge model.MonthlyCharge
+		var monthlyCost int
+		var prepaid, cost *int
+		var period string
+		if err := rows.Scan(&charge.SubscriptionID, &charge.ServiceName, &prepaid, &monthlyCost, &period, &cost); err != nil {
+			r.logger.Error(ctx, "Failed to scan monthly charge row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan monthly charge: %w", err)
+		}
+		charge.Base = monthlyBase(monthlyCost, prepaid, period, cost)
+		charge.Amount = new(big.Rat).Set(charge.Base)
+		charges = append(charges, charge)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate monthly charge rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly charges: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.monthly_charges", len(charges), time.Since(start))
+
+	return charges, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT user_id FROM subscriptions WHERE tenant_id = $1 AND NOT is_draft ORDER BY user_id`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list user IDs from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list user IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			r.logger.Error(ctx, "Failed to scan user ID row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user IDs: %w", err)
+	}
+	// Текстовое представление UUID упорядочено иначе, чем байты в Postgres
+	sort.Slice(userIDs, func(i, j int) bool { return bytes.Compare(userIDs[i][:], userIDs[j][:]) < 0 })
+
+	r.queries.Observe("subscriptions.list_user_ids", len(userIDs), time.Since(start))
+
+	return userIDs, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListTenants(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT tenant_id FROM subscriptions ORDER BY tenant_id`
+
+	start := time.Now()
+	rows, err := r.query(ctx, r.db, query)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list tenants from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			r.logger.Error(ctx, "Failed to scan tenant row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.list_tenants", len(tenants), time.Since(start))
+
+	return tenants, nil
+}
+
+// activeSubs возвращает подписки организации без черновиков, действующие в месяце month.
+// Группировки по нормализованному названию выполняются в Go: в SQLite нет regexp_replace и mode()
+func (r *sqliteSubscriptionRepo) activeSubs(ctx context.Context, month time.Time, action string) ([]*model.Subscription, error) {
+	query := `
+		SELECT ` + sqliteSubscriptionColumns + `
+		FROM subscriptions
+		WHERE ` + activeInMonthCondition
+	return r.list(ctx, action, query, ctxutil.TenantID(ctx), month)
+}
+
+func (r *sqliteSubscriptionRepo) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	start := time.Now()
+	subs, err := r.activeSubs(ctx, model.CurrentMonth(), "list")
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []*model.Subscription
+	for _, sub := range subs {
+		if sub.UserID == userID {
+			owned = append(owned, sub)
+		}
+	}
+	services := userServices(owned)
+
+	r.queries.Observe("subscriptions.list_user_services", len(services), time.Since(start))
+
+	return services, nil
+}
+
+func (r *sqliteSubscriptionRepo) ServiceNameUsage(ctx context.Context, normalized []string) ([]model.ServiceNameUsage, error) {
+	start := time.Now()
+	subs, err := r.list(ctx, "read", `SELECT `+sqliteSubscriptionColumns+` FROM subscriptions WHERE tenant_id = $1`, ctxutil.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	usage := serviceNameUsage(subs, normalized)
+
+	r.queries.Observe("subscriptions.service_name_usage", len(usage), time.Since(start))
+	return usage, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListUserSpend(ctx context.Context, month time.Time) ([]model.UserSpend, error) {
+	start := time.Now()
+	subs, err := r.activeSubs(ctx, month, "list")
+	if err != nil {
+		return nil, err
+	}
+	spend := userSpend(subs)
+
+	r.queries.Observe("subscriptions.list_user_spend", len(spend), time.Since(start))
+
+	return spend, nil
+}
+
+func (r *sqliteSubscriptionRepo) ListServiceSpend(ctx context.Context, month time.Time) ([]model.SharedService, error) {
+	start := time.Now()
+	subs, err := r.activeSubs(ctx, month, "list")
+	if err != nil {
+		return nil, err
+	}
+	services := serviceSpend(subs)
+
+	r.queries.Observe("subscriptions.list_service_spend", len(services), time.Since(start))
+
+	return services, nil
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func newSQLiteRepo(t *testing.T) SubscriptionRepository {
+	t.Helper()
+	db, err := database.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "subscriptions.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSQLiteSubscriptionRepository(db, metrics.NewQueries(), logger.New(slog.LevelError))
+}
+
+func TestSQLiteCalculateTotalCost(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	current := model.CurrentMonth()
+	start := current.AddDate(0, -3, 0)
+	ended := current.AddDate(0, -2, 0)
+	prepaid := 1200
+	userID := uuid.New()
+
+	subs := []*model.Subscription{
+		// 4 месяца по 100, текущий месяц приостановлен
+		{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start},
+		// Закончилась два месяца назад: 2 месяца по 10
+		{ServiceName: "Spotify", MonthlyCost: 10, UserID: userID, StartDate: start, EndDate: &ended},
+		// Годовая предоплата: 4 месяца по 1200/12
+		{ServiceName: "Yandex Plus", MonthlyCost: 100, PrepaidAmount: &prepaid, UserID: userID, StartDate: start},
+		// Черновики не учитываются
+		{ServiceName: "Draft", MonthlyCost: 1000, UserID: userID, StartDate: start, IsDraft: true},
+	}
+	for _, sub := range subs {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+	// Подписка другой организации не видна
+	if err := repo.Create(ctxutil.WithTenantID(ctx, "other"), &model.Subscription{ServiceName: "Netflix", MonthlyCost: 5000, UserID: userID, StartDate: start}); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	netflix := *subs[0]
+	netflix.Status = model.StatusPaused
+	if err := repo.Update(ctx, netflix.ID, &netflix); err != nil {
+		t.Fatalf("failed to pause subscription: %v", err)
+	}
+
+	totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: start.Format("01-2006"),
+		EndPeriod:   current.Format("01-2006"),
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+
+	for name, tc := range map[string]struct{ got, want string }{
+		"total":     {totals.Total.RatString(), "720"},
+		"active":    {totals.Active.RatString(), "700"},
+		"cancelled": {totals.Cancelled.RatString(), "20"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %s, want %s", name, tc.got, tc.want)
+		}
+	}
+
+	// Возобновление в том же месяце удаляет пустую паузу
+	netflix.Status = model.StatusActive
+	if err := repo.Update(ctx, netflix.ID, &netflix); err != nil {
+		t.Fatalf("failed to resume subscription: %v", err)
+	}
+	totals, err = repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: current.Format("01-2006"),
+		EndPeriod:   current.Format("01-2006"),
+		ServiceName: "Netflix",
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	if got := totals.Total.RatString(); got != "100" {
+		t.Errorf("total after resume = %s, want 100", got)
+	}
+
+	spend, err := repo.MonthlySpend(ctx, userID, start, current)
+	if err != nil {
+		t.Fatalf("MonthlySpend: %v", err)
+	}
+	if len(spend) != 4 || !spend[0].Month.Equal(start) || spend[0].Total != 210 || spend[3].Total != 200 {
+		t.Errorf("MonthlySpend = %+v", spend)
+	}
+}
+
+func TestSQLiteChangesAndListAfter(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	for i := 0; i < 5; i++ {
+		if err := repo.Create(ctx, &model.Subscription{ServiceName: "Netflix", MonthlyCost: 100, UserID: uuid.New(), StartDate: model.CurrentMonth()}); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	var seen []*model.Subscription
+	var cursor *model.SubscriptionCursor
+	for {
+		page, err := repo.ListAfter(ctx, model.SubscriptionFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListAfter: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		seen = append(seen, page...)
+		last := page[len(page)-1]
+		cursor = &model.SubscriptionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("ListAfter returned %d subscriptions, want 5", len(seen))
+	}
+
+	sub := seen[0]
+	to := uuid.New()
+	if err := repo.Transfer(ctx, sub.ID, sub.UserID, to, nil); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if err := repo.Transfer(ctx, sub.ID, sub.UserID, to, nil); err == nil {
+		t.Error("second Transfer from the previous owner succeeded")
+	}
+	if err := repo.Delete(ctx, sub.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	changes, err := repo.ListChanges(ctx, 0, 100)
+	if err != nil {
+		t.Fatalf("ListChanges: %v", err)
+	}
+	if len(changes) != 7 {
+		t.Fatalf("ListChanges returned %d changes, want 7", len(changes))
+	}
+	transfer, deleted := changes[5], changes[6]
+	if transfer.Operation != "transfer" || deleted.Operation != "delete" || deleted.SubscriptionID != sub.ID {
+		t.Errorf("operations = %s, %s", transfer.Operation, deleted.Operation)
+	}
+
+	var row struct {
+		UserID    uuid.UUID `json:"user_id"`
+		StartDate string    `json:"start_date"`
+		IsDraft   bool      `json:"is_draft"`
+		ChangeSeq int64     `json:"change_seq"`
+	}
+	if err := json.Unmarshal(transfer.Payload, &row); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if row.UserID != to || row.StartDate != model.CurrentMonth().Format("2006-01-02") || row.ChangeSeq != 6 {
+		t.Errorf("transfer payload = %s", transfer.Payload)
+	}
+}
+
+func TestSQLiteFilterByServiceID(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	serviceID := uuid.New()
+	start := model.CurrentMonth()
+
+	linked := &model.Subscription{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: uuid.New(), StartDate: start, ServiceID: &serviceID}
+	// Написание названия не влияет на фильтр по сервису каталога
+	renamed := &model.Subscription{ServiceName: "yandex plus ", MonthlyCost: 300, UserID: uuid.New(), StartDate: start}
+	for _, sub := range []*model.Subscription{linked, renamed, {ServiceName: "Netflix", MonthlyCost: 100, UserID: uuid.New(), StartDate: start}} {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+	renamed.ServiceID = &serviceID
+	if err := repo.Update(ctx, renamed.ID, renamed); err != nil {
+		t.Fatalf("failed to link subscription: %v", err)
+	}
+
+	subs, total, err := repo.List(ctx, model.SubscriptionFilter{ServiceID: &serviceID}, model.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 2 || len(subs) != 2 {
+		t.Fatalf("List returned %d of %d subscriptions, want 2", len(subs), total)
+	}
+	for _, sub := range subs {
+		if sub.ServiceID == nil || *sub.ServiceID != serviceID {
+			t.Errorf("subscription %s service_id = %v, want %s", sub.ServiceName, sub.ServiceID, serviceID)
+		}
+	}
+
+	totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: start.Format("01-2006"),
+		EndPeriod:   start.Format("01-2006"),
+		ServiceID:   &serviceID,
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	if got := totals.Total.RatString(); got != "700" {
+		t.Errorf("total = %s, want 700", got)
+	}
+}
+
+func TestSQLiteSubscriptionTags(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	start := model.CurrentMonth()
+
+	work := &model.Subscription{ServiceName: "Slack", MonthlyCost: 500, UserID: uuid.New(), StartDate: start, Tags: model.Tags{"tools", "work"}}
+	fun := &model.Subscription{ServiceName: "Netflix", MonthlyCost: 300, UserID: uuid.New(), StartDate: start, Tags: model.Tags{"entertainment"}}
+	for _, sub := range []*model.Subscription{work, fun, {ServiceName: "Spotify", MonthlyCost: 200, UserID: uuid.New(), StartDate: start}} {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, work.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !reflect.DeepEqual(got.Tags, model.Tags{"tools", "work"}) {
+		t.Errorf("tags = %v, want [tools work]", got.Tags)
+	}
+
+	// Теги заменяются целиком, общий тег связывает обе подписки
+	fun.Tags = model.Tags{"work"}
+	if err := repo.Update(ctx, fun.ID, fun); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	tag := "work"
+	subs, total, err := repo.List(ctx, model.SubscriptionFilter{Tag: &tag}, model.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 2 || len(subs) != 2 {
+		t.Fatalf("List returned %d of %d subscriptions, want 2", len(subs), total)
+	}
+	got, err = repo.GetByID(ctx, fun.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !reflect.DeepEqual(got.Tags, model.Tags{"work"}) {
+		t.Errorf("tags after update = %v, want [work]", got.Tags)
+	}
+
+	totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: start.Format("01-2006"),
+		EndPeriod:   start.Format("01-2006"),
+		Tag:         "work",
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	if got := totals.Total.RatString(); got != "800" {
+		t.Errorf("total = %s, want 800", got)
+	}
+}
+
+func TestSQLiteSubscriptionDescription(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	description := "Семейный тариф на четверых"
+
+	sub := &model.Subscription{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: uuid.New(), StartDate: model.CurrentMonth(), Description: &description}
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	got, err := repo.GetByID(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Description == nil || *got.Description != description {
+		t.Errorf("description = %v, want %q", got.Description, description)
+	}
+
+	// Без поля описание удаляется
+	sub.Description = nil
+	if err := repo.Update(ctx, sub.ID, sub); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	changes, err := repo.ListChanges(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListChanges: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	for i, want := range []*string{&description, nil} {
+		var payload struct {
+			Description *string `json:"description"`
+		}
+		if err := json.Unmarshal(changes[i].Payload, &payload); err != nil {
+			t.Fatalf("failed to decode change payload: %v", err)
+		}
+		if !reflect.DeepEqual(payload.Description, want) {
+			t.Errorf("change %d description = %v, want %v", i, payload.Description, want)
+		}
+	}
+}
+
+func TestSQLiteCalculateTotalCostBillingPeriods(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepo(t)
+	current := model.CurrentMonth()
+	start := current.AddDate(0, -2, 0)
+	yearly, weekly := 1000, 3
+	userID := uuid.New()
+
+	subs := []*model.Subscription{
+		// 3 месяца по 1000/12, а не по округленным 83
+		{ServiceName: "Kinopoisk", MonthlyCost: 83, BillingPeriod: model.BillingYearly, Cost: &yearly, UserID: userID, StartDate: start},
+		// 3 месяца по 3*52/12
+		{ServiceName: "Newspaper", MonthlyCost: 13, BillingPeriod: model.BillingWeekly, Cost: &weekly, UserID: userID, StartDate: start},
+		// Без периода оплаты подписка помесячная
+		{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start},
+	}
+	for _, sub := range subs {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, subs[2].ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.BillingPeriod != model.BillingMonthly || got.Cost != nil {
+		t.Errorf("billing = %s, %v, want monthly without cost", got.BillingPeriod, got.Cost)
+	}
+
+	totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+		StartPeriod: start.Format("01-2006"),
+		EndPeriod:   current.Format("01-2006"),
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	// 250 + 39 + 300
+	if got := totals.Total.RatString(); got != "589" {
+		t.Errorf("total = %s, want 589", got)
+	}
+
+	charges, err := repo.MonthlyCharges(ctx, userID, current)
+	if err != nil {
+		t.Fatalf("MonthlyCharges: %v", err)
+	}
+	if len(charges) != 3 || charges[0].Base.RatString() != "250/3" {
+		t.Errorf("unexpected monthly charges: %+v", charges)
+	}
+}
+
+func TestSubscriptionPriceHistory(t *testing.T) {
+	for name, repo := range map[string]SubscriptionRepository{
+		"memory": NewInMemorySubscriptionRepository(),
+		"sqlite": newSQLiteRepo(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			current := model.CurrentMonth()
+			start := current.AddDate(0, -3, 0)
+			userID := uuid.New()
+
+			sub := &model.Subscription{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start}
+			if err := repo.Create(ctx, sub); err != nil {
+				t.Fatalf("failed to create subscription: %v", err)
+			}
+
+			// Новая стоимость с прошлого месяца: 2 месяца по 100 и 2 по 200
+			changed := *sub
+			changed.MonthlyCost = 200
+			from := current.AddDate(0, -1, 0)
+			changed.PriceFrom = &from
+			if err := repo.Update(ctx, sub.ID, &changed); err != nil {
+				t.Fatalf("failed to change price: %v", err)
+			}
+
+			totals, err := repo.CalculateTotalCost(ctx, model.SummaryFilter{
+				StartPeriod: start.Format("01-2006"),
+				EndPeriod:   current.Format("01-2006"),
+			})
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+			if got := totals.Total.RatString(); got != "600" {
+				t.Errorf("total = %s, want 600", got)
+			}
+
+			spend, err := repo.MonthlySpend(ctx, userID, start, current)
+			if err != nil {
+				t.Fatalf("MonthlySpend: %v", err)
+			}
+			var got []int
+			for _, month := range spend {
+				got = append(got, month.Total)
+			}
+			if want := []int{100, 100, 200, 200}; !reflect.DeepEqual(got, want) {
+				t.Errorf("monthly spend = %v, want %v", got, want)
+			}
+
+			charges, err := repo.MonthlyCharges(ctx, userID, start)
+			if err != nil {
+				t.Fatalf("MonthlyCharges: %v", err)
+			}
+			if len(charges) != 1 || charges[0].Base.RatString() != "100" {
+				t.Errorf("charges at start = %+v, want base 100", charges)
+			}
+
+			// Более раннее изменение заменяет записи с более поздних месяцев
+			changed.MonthlyCost = 300
+			from = current.AddDate(0, -2, 0)
+			if err := repo.Update(ctx, sub.ID, &changed); err != nil {
+				t.Fatalf("failed to change price: %v", err)
+			}
+
+			prices, err := repo.ListPrices(ctx, sub.ID)
+			if err != nil {
+				t.Fatalf("ListPrices: %v", err)
+			}
+			if len(prices) != 2 || !prices[0].From.Equal(start) || prices[0].MonthlyCost != 100 ||
+				!prices[1].From.Equal(from) || prices[1].MonthlyCost != 300 {
+				t.Errorf("prices = %+v, want 100 from %s and 300 from %s", prices, start, from)
+			}
+
+			totals, err = repo.CalculateTotalCost(ctx, model.SummaryFilter{
+				StartPeriod: start.Format("01-2006"),
+				EndPeriod:   current.Format("01-2006"),
+			})
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+			if got := totals.Total.RatString(); got != "1000" {
+				t.Errorf("total after second change = %s, want 1000", got)
+			}
+		})
+	}
+}
@@ -2,59 +2,232 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
 	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
 	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *model.Subscription) error
+	// CreateBatch создает подписки в одной транзакции через COPY: при ошибке не создается
+	// ни одна. Нарушение ограничения отдельной подпиской возвращается как *BatchRowError
+	CreateBatch(ctx context.Context, subs []*model.Subscription) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	// Update сохраняет подписку. Непустой sub.Status меняет состояние и ведет учет
+	// месяцев приостановки, пустой оставляет состояние прежним
 	Update(ctx context.Context, id uuid.UUID, sub *model.Subscription) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, userID *uuid.UUID, serviceName *string) ([]*model.Subscription, error)
-	CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (int, error)
+	// UpdateBatch сохраняет подписки, как Update, в одной транзакции: при ошибке не меняется
+	// ни одна. Отсутствующая подписка или нарушение ограничения возвращается как *BatchRowError
+	UpdateBatch(ctx context.Context, updates []model.SubscriptionUpdate) error
+	// DeleteBatch удаляет подписки в одной транзакции; отсутствующая подписка возвращается
+	// как *BatchRowError, и не удаляется ни одна
+	DeleteBatch(ctx context.Context, ids []uuid.UUID) error
+	// Sync создает, сохраняет и удаляет подписки в одной транзакции: при ошибке не
+	// применяется ничего. Отсутствующая подписка или нарушение ограничения возвращается
+	// как *BatchRowError с действием и номером элемента в его списке
+	Sync(ctx context.Context, creates []*model.Subscription, updates []model.SubscriptionUpdate, deletes []uuid.UUID) error
+	// List возвращает страницу подписок и общее количество подписок под фильтром
+	List(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) ([]*model.Subscription, int, error)
+	// Search ищет подписки по названию сервиса без учета регистра и диакритики
+	Search(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error)
+	// ListAfter возвращает до limit подписок под фильтром, следующих за after в порядке (created_at, id)
+	ListAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) ([]*model.Subscription, error)
+	// Stream передает в fn подписки под фильтром в порядке (created_at, id) по мере чтения
+	// строк из базы, не накапливая их. Ошибка fn прерывает чтение
+	Stream(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error
+	Activate(ctx context.Context, id uuid.UUID) error
+	// Cancel отменяет подписку: состояние cancelled, последний месяц endDate и причина отмены
+	Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason *string) error
+	// Transfer передает неотмененную подписку пользователя from пользователю to и записывает передачу в историю
+	Transfer(ctx context.Context, id, from, to uuid.UUID, reason *string) error
+	ListChanges(ctx context.Context, sinceSeq int64, limit int) ([]*model.SubscriptionChange, error)
+	// ListPrices возвращает записи истории стоимости подписки по возрастанию месяца; пустой
+	// список - стоимость не менялась. Подписка организации контекста не проверяется
+	ListPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error)
+	CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.CostTotals, error)
+	MonthlySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]model.MonthlySpend, error)
+	// MonthlyCharges возвращает начисления по активным в месяце подпискам пользователя с учетом скидок
+	MonthlyCharges(ctx context.Context, userID uuid.UUID, month time.Time) ([]model.MonthlyCharge, error)
+	ListUserIDs(ctx context.Context) ([]uuid.UUID, error)
+	// ListTenants возвращает организации, у которых есть подписки; фоновые задачи
+	// обходят их по очереди, так как остальные методы ограничены организацией контекста
+	ListTenants(ctx context.Context) ([]string, error)
+	// ListUserServices возвращает сервисы с подписками пользователя, действующими в текущем месяце
+	ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error)
+	// ServiceNameUsage возвращает все написания названий сервисов, нормализованная форма
+	// которых (model.NormalizeServiceName) входит в normalized, с числом пользователей
+	ServiceNameUsage(ctx context.Context, normalized []string) ([]model.ServiceNameUsage, error)
+	// ListUserSpend возвращает по каждому пользователю организации подписки, действующие
+	// в месяце month, с их стоимостью, начиная с самых дорогих
+	ListUserSpend(ctx context.Context, month time.Time) ([]model.UserSpend, error)
+	// ListServiceSpend возвращает по каждому сервису (model.NormalizeServiceName) подписки
+	// организации, действующие в месяце month, и число пользователей, начиная с самых популярных
+	ListServiceSpend(ctx context.Context, month time.Time) ([]model.SharedService, error)
+}
+
+// activeDiscountsQuery - сумма процентных и фиксированных скидок подписки s, действующих
+// в месяце m. Подставляется в LATERAL-подзапросы расчетов стоимости
+const activeDiscountsQuery = `
+	SELECT
+		COALESCE(SUM(value) FILTER (WHERE kind = 'percent'), 0) AS percent,
+		COALESCE(SUM(value) FILTER (WHERE kind = 'fixed'), 0) AS fixed
+	FROM discounts
+	WHERE discounts.tenant_id = s.tenant_id
+		AND (discounts.subscription_id = s.id OR discounts.user_id = s.user_id)
+		AND discounts.start_date <= m.month
+		AND (discounts.end_date IS NULL OR discounts.end_date >= m.month)
+`
+
+// notPausedCondition исключает месяцы m.month, в которые подписка s была приостановлена
+const notPausedCondition = `
+	NOT EXISTS (
+		SELECT 1 FROM subscription_pauses p
+		WHERE p.subscription_id = s.id
+			AND p.start_date <= m.month
+			AND (p.end_date IS NULL OR p.end_date >= m.month)
+	)
+`
+
+// effectivePriceQuery - стоимость подписки s, действующая в месяце m (model.EffectivePrice):
+// последняя запись subscription_prices, вступившая в силу не позже месяца; месяцы до первой
+// записи считаются по ней. Без истории - текущая стоимость подписки. Подставляется
+// в LATERAL-подзапросы расчетов стоимости под именем p
+const effectivePriceQuery = `
+	SELECT
+		COALESCE(h.monthly_cost, s.monthly_cost) AS monthly_cost,
+		COALESCE(h.billing_period, s.billing_period) AS billing_period,
+		CASE WHEN h.subscription_id IS NULL THEN s.cost ELSE h.cost END AS cost
+	FROM (SELECT 1) AS one
+	LEFT JOIN LATERAL (
+		SELECT subscription_id, monthly_cost, billing_period, cost
+		FROM subscription_prices
+		WHERE subscription_prices.subscription_id = s.id
+		ORDER BY effective_from > m.month, ABS(m.month::date - effective_from)
+		LIMIT 1
+	) AS h ON TRUE
+`
+
+// monthlyBaseExpr - начисление за месяц без скидок и округления (monthlyBase для Postgres)
+// по стоимости p из effectivePriceQuery: годовая предоплата распределяется равномерно
+// по месяцам, стоимость за неделю или год переводится в месяц
+const monthlyBaseExpr = `CASE
+		WHEN s.prepaid_amount IS NOT NULL THEN s.prepaid_amount::numeric / 12
+		WHEN p.billing_period = 'yearly' THEN p.cost::numeric / 12
+		WHEN p.billing_period = 'weekly' THEN p.cost::numeric * 52 / 12
+		ELSE p.monthly_cost
+	END`
+
+// subscriptionColumns - колонки subscriptions в порядке полей scanSubscriptions
+const subscriptionColumns = `id, service_name, monthly_cost, user_id, start_date, end_date, prepaid_amount, is_draft, status, cancel_reason, cancelled_at, metadata, service_id, description, billing_period, cost, (` + subscriptionTagsQuery + `subscriptions.id) AS tags, change_seq, created_at, updated_at`
+
+// subscriptionCopyColumns - колонки, которые CreateBatch загружает через COPY
+var subscriptionCopyColumns = []string{"id", "service_name", "monthly_cost", "user_id", "start_date", "end_date", "prepaid_amount", "is_draft", "status", "tenant_id", "metadata", "service_id", "description", "billing_period", "cost"}
+
+// PgxPool - пул соединений pgx, на котором работает репозиторий подписок
+// (database.Pool или *pgxpool.Pool)
+type PgxPool interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 type subscriptionRepo struct {
-	db     *sql.DB
-	logger *logger.Logger
+	db      PgxPool
+	queries *metrics.Queries
+	logger  *logger.Logger
 }
 
-func NewSubscriptionRepository(db *sql.DB, logger *logger.Logger) SubscriptionRepository {
+func NewSubscriptionRepository(db PgxPool, queries *metrics.Queries, logger *logger.Logger) SubscriptionRepository {
 	return &subscriptionRepo{
-		db:     db,
-		logger: logger,
+		db:      db,
+		queries: queries,
+		logger:  logger,
 	}
 }
 
 func (r *subscriptionRepo) Create(ctx context.Context, sub *model.Subscription) error {
-	query := `
-		INSERT INTO subscriptions (service_name, monthly_cost, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
-	`
-
 	r.logger.Debug(ctx, "Creating subscription in database",
 		"service_name", sub.ServiceName,
 		"user_id", sub.UserID,
 		"monthly_cost", sub.MonthlyCost,
 	)
 
-	err := r.db.QueryRowContext(ctx, query,
+	tx, err := r.beginAudited(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.insert(ctx, tx, sub); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscription created successfully",
+		"subscription_id", sub.ID,
+		"service_name", sub.ServiceName,
+	)
+
+	return nil
+}
+
+// insert вставляет подписку в транзакции tx и заполняет поля, которые задает база
+func (r *subscriptionRepo) insert(ctx context.Context, tx pgx.Tx, sub *model.Subscription) error {
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = model.BillingMonthly
+	}
+
+	query := `
+		INSERT INTO subscriptions (service_name, monthly_cost, user_id, start_date, end_date, prepaid_amount, is_draft, status, tenant_id, metadata, service_id, description, billing_period, cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'active'), $9, $10, $11, $12, $13, $14)
+		RETURNING id, status, change_seq, created_at, updated_at
+	`
+
+	err := tx.QueryRow(ctx, query,
 		sub.ServiceName,
 		sub.MonthlyCost,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
-	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+		sub.PrepaidAmount,
+		sub.IsDraft,
+		sub.Status,
+		ctxutil.TenantID(ctx),
+		sub.Metadata,
+		sub.ServiceID,
+		sub.Description,
+		sub.BillingPeriod,
+		sub.Cost,
+	).Scan(&sub.ID, &sub.Status, &sub.ChangeSeq, &sub.CreatedAt, &sub.UpdatedAt)
 
+	if isCatalogViolation(err) {
+		return errUnknownCatalogService
+	}
 	if err != nil {
 		r.logger.Error(ctx, "Failed to create subscription in database",
 			"service_name", sub.ServiceName,
@@ -63,39 +236,222 @@ func (r *subscriptionRepo) Create(ctx context.Context, sub *model.Subscription)
 		)
 		return fmt.Errorf("failed to create subscription: %w", err)
 	}
+	return r.setTags(ctx, tx, sub.ID, sub.Tags)
+}
 
-	r.logger.Info(ctx, "Subscription created successfully",
-		"subscription_id", sub.ID,
-		"service_name", sub.ServiceName,
+func (r *subscriptionRepo) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	// COPY не возвращает строки, поэтому ID назначаются заранее, а номера изменений
+	// и время создания, заполненные триггерами и значениями по умолчанию, читаются после загрузки
+	query := `
+		SELECT id, change_seq, created_at, updated_at
+		FROM subscriptions
+		WHERE id = ANY($1)
+	`
+
+	r.logger.Debug(ctx, "Creating subscriptions batch in database",
+		"count", len(subs),
+	)
+
+	start := time.Now()
+	tx, err := r.beginAudited(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tenantID := ctxutil.TenantID(ctx)
+	ids := make([]uuid.UUID, len(subs))
+	byID := make(map[uuid.UUID]*model.Subscription, len(subs))
+	for i, sub := range subs {
+		sub.ID = uuid.New()
+		if sub.Status == "" {
+			sub.Status = model.StatusActive
+		}
+		if sub.BillingPeriod == "" {
+			sub.BillingPeriod = model.BillingMonthly
+		}
+		ids[i] = sub.ID
+		byID[sub.ID] = sub
+	}
+
+	// Ошибку строки PostgreSQL сообщает после загрузки всех строк, ее номер - в контексте ошибки
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"subscriptions"}, subscriptionCopyColumns,
+		pgx.CopyFromSlice(len(subs), func(i int) ([]any, error) {
+			sub := subs[i]
+			return []any{
+				sub.ID,
+				sub.ServiceName,
+				sub.MonthlyCost,
+				sub.UserID,
+				sub.StartDate,
+				sub.EndDate,
+				sub.PrepaidAmount,
+				sub.IsDraft,
+				sub.Status,
+				tenantID,
+				sub.Metadata,
+				sub.ServiceID,
+				sub.Description,
+				sub.BillingPeriod,
+				sub.Cost,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return r.batchCopyError(ctx, err)
+	}
+
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to read created subscriptions batch",
+			"error", err,
+		)
+		return fmt.Errorf("failed to read created subscriptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var changeSeq int64
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&id, &changeSeq, &createdAt, &updatedAt); err != nil {
+			r.logger.Error(ctx, "Failed to scan created subscription row",
+				"error", err,
+			)
+			return fmt.Errorf("failed to read created subscriptions: %w", err)
+		}
+		if sub, ok := byID[id]; ok {
+			sub.ChangeSeq = changeSeq
+			sub.CreatedAt = createdAt
+			sub.UpdatedAt = updatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate created subscription rows",
+			"error", err,
+		)
+		return fmt.Errorf("failed to read created subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		if err := r.setTags(ctx, tx, sub.ID, sub.Tags); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscriptions batch created successfully",
+		"count", len(subs),
 	)
 
+	r.queries.Observe("subscriptions.create_batch", len(subs), time.Since(start))
+
 	return nil
 }
 
+// beginAudited начинает транзакцию, изменения подписок в которой триггер журнала аудита
+// записывает с автором и идентификатором запроса из ctx
+func (r *subscriptionRepo) beginAudited(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Третий аргумент set_config ограничивает значения транзакцией, соединение пула их не сохраняет
+	if _, err := tx.Exec(ctx,
+		`SELECT set_config('audit.actor', $1, true), set_config('audit.request_id', $2, true)`,
+		auth.Actor(ctx), ctxutil.RequestID(ctx),
+	); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to set audit context: %w", err)
+	}
+	return tx, nil
+}
+
+// execAudited выполняет изменяющий запрос в отдельной транзакции beginAudited
+func (r *subscriptionRepo) execAudited(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	tx, err := r.beginAudited(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return pgconn.CommandTag{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// BatchRowError - ошибка одной из подписок пачки: нарушение ограничения базы или
+// отсутствующая подписка. Row - индекс подписки в переданном срезе, Action - операция
+// пачки (create, update, delete)
+type BatchRowError struct {
+	Row     int
+	Action  string
+	Message string
+}
+
+func (e *BatchRowError) Error() string {
+	return fmt.Sprintf("failed to %s subscription: %s", e.Action, e.Message)
+}
+
+// batchCopyError превращает ошибку COPY в BatchRowError, если строку, нарушившую
+// ограничение, можно определить по контексту ошибки ("COPY subscriptions, line 3: ...")
+func (r *subscriptionRepo) batchCopyError(ctx context.Context, err error) error {
+	if pgErr, ok := asPgError(err); ok && pgErr.Class() == integrityViolationClass {
+		var line int
+		if _, scanErr := fmt.Sscanf(pgErr.Where, "COPY subscriptions, line %d", &line); scanErr == nil && line > 0 {
+			r.logger.Warn(ctx, "Subscription in batch violates constraint",
+				"row", line-1,
+				"constraint", pgErr.Constraint,
+				"error", pgErr.Message,
+			)
+			return &BatchRowError{Row: line - 1, Action: "create", Message: pgErr.Message}
+		}
+	}
+
+	r.logger.Error(ctx, "Failed to copy subscriptions batch",
+		"error", err,
+	)
+	return fmt.Errorf("failed to create subscriptions: %w", err)
+}
+
+// serviceIDConstraint - внешний ключ subscriptions.service_id на каталог сервисов
+const serviceIDConstraint = "subscriptions_service_id_fkey"
+
+// errUnknownCatalogService - service_id подписки не найден в каталоге организации
+var errUnknownCatalogService = model.Invalid(model.ErrInvalidInput, "invalid service_id: service not found in catalog")
+
+// isCatalogViolation сообщает, что подписка ссылается на несуществующий сервис каталога
+func isCatalogViolation(err error) bool {
+	pgErr, ok := asPgError(err)
+	return ok && pgErr.Code == foreignKeyViolation && pgErr.Constraint == serviceIDConstraint
+}
+
 func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
 	query := `
-		SELECT id, service_name, monthly_cost, user_id, start_date, end_date, created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions 
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
 	r.logger.Debug(ctx, "Getting subscription from database",
 		"subscription_id", id,
 	)
 
-	var sub model.Subscription
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&sub.ID,
-		&sub.ServiceName,
-		&sub.MonthlyCost,
-		&sub.UserID,
-		&sub.StartDate,
-		&sub.EndDate,
-		&sub.CreatedAt,
-		&sub.UpdatedAt,
-	)
+	sub, err := scanSubscription(r.db.QueryRow(ctx, query, id, ctxutil.TenantID(ctx)))
 
-	if err == sql.ErrNoRows {
+	if err == pgx.ErrNoRows {
 		r.logger.Debug(ctx, "Subscription not found in database",
 			"subscription_id", id,
 		)
@@ -114,53 +470,37 @@ func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Su
 		"service_name", sub.ServiceName,
 	)
 
-	return &sub, nil
+	return sub, nil
 }
 
 func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, sub *model.Subscription) error {
-	query := `
-		UPDATE subscriptions 
-		SET service_name = $1, monthly_cost = $2, user_id = $3, start_date = $4, end_date = $5
-		WHERE id = $6
-	`
-
 	r.logger.Info(ctx, "Updating subscription in database",
 		"subscription_id", id,
 		"service_name", sub.ServiceName,
 		"user_id", sub.UserID,
+		"status", sub.Status,
 	)
 
-	result, err := r.db.ExecContext(ctx, query,
-		sub.ServiceName,
-		sub.MonthlyCost,
-		sub.UserID,
-		sub.StartDate,
-		sub.EndDate,
-		id,
-	)
-
+	tx, err := r.beginAudited(ctx)
 	if err != nil {
-		r.logger.Error(ctx, "Failed to update subscription in database",
+		r.logger.Error(ctx, "Failed to begin subscription update transaction",
 			"subscription_id", id,
 			"error", err,
 		)
-		return fmt.Errorf("failed to update subscription: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		r.logger.Error(ctx, "Failed to get rows affected",
-			"subscription_id", id,
-			"error", err,
-		)
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	if err := r.update(ctx, tx, id, sub); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		r.logger.Warn(ctx, "Subscription not found for update",
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscription update transaction",
 			"subscription_id", id,
+			"error", err,
 		)
-		return fmt.Errorf("subscription not found")
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	r.logger.Info(ctx, "Subscription updated successfully",
@@ -169,202 +509,1524 @@ func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, sub *model.
 	return nil
 }
 
-func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
-
-	r.logger.Info(ctx, "Deleting subscription from database",
-		"subscription_id", id,
-	)
-
-	result, err := r.db.ExecContext(ctx, query, id)
+// update сохраняет подписку в транзакции tx и ведет учет месяцев приостановки
+func (r *subscriptionRepo) update(ctx context.Context, tx pgx.Tx, id uuid.UUID, sub *model.Subscription) error {
+	// Блокировка строки сохраняет согласованность состояния и учета пауз при параллельных изменениях
+	var previous string
+	// Условие на организацию здесь защищает и последующий UPDATE по id в той же транзакции
+	err := tx.QueryRow(ctx, `SELECT status FROM subscriptions WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, id, ctxutil.TenantID(ctx)).Scan(&previous)
+	if err == pgx.ErrNoRows {
+		r.logger.Warn(ctx, "Subscription not found for update",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
 	if err != nil {
-		r.logger.Error(ctx, "Failed to delete subscription from database",
+		r.logger.Error(ctx, "Failed to lock subscription for update",
 			"subscription_id", id,
 			"error", err,
 		)
-		return fmt.Errorf("failed to delete subscription: %w", err)
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	if sub.PriceFrom != nil {
+		if err := r.recordPrice(ctx, tx, id, sub); err != nil {
+			return err
+		}
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	_, err = tx.Exec(ctx, `
+		UPDATE subscriptions 
+		SET service_name = $1, monthly_cost = $2, user_id = $3, start_date = $4, end_date = $5, prepaid_amount = $6,
+			status = COALESCE(NULLIF($7, ''), status), metadata = $8, service_id = $9, description = $10,
+			billing_period = COALESCE(NULLIF($11, ''), 'monthly'), cost = $12
+		WHERE id = $13
+	`,
+		sub.ServiceName,
+		sub.MonthlyCost,
+		sub.UserID,
+		sub.StartDate,
+		sub.EndDate,
+		sub.PrepaidAmount,
+		sub.Status,
+		sub.Metadata,
+		sub.ServiceID,
+		sub.Description,
+		sub.BillingPeriod,
+		sub.Cost,
+		id,
+	)
+	if isCatalogViolation(err) {
+		return errUnknownCatalogService
+	}
 	if err != nil {
-		r.logger.Error(ctx, "Failed to get rows affected",
+		r.logger.Error(ctx, "Failed to update subscription in database",
 			"subscription_id", id,
 			"error", err,
 		)
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if err := r.setTags(ctx, tx, id, sub.Tags); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		r.logger.Warn(ctx, "Subscription not found for deletion",
+	if sub.Status != "" && sub.Status != previous {
+		if err := r.recordPause(ctx, tx, id, previous, sub.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTags заменяет теги подписки в транзакции tx
+func (r *subscriptionRepo) setTags(ctx context.Context, tx pgx.Tx, id uuid.UUID, tags model.Tags) error {
+	err := writeSubscriptionTags(func(query string, args ...interface{}) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	}, ctxutil.TenantID(ctx), id, tags)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save subscription tags",
 			"subscription_id", id,
+			"error", err,
 		)
-		return fmt.Errorf("subscription not found")
+		return fmt.Errorf("failed to save tags: %w", err)
 	}
-
-	r.logger.Info(ctx, "Subscription deleted successfully",
-		"subscription_id", id,
-	)
 	return nil
 }
 
-func (r *subscriptionRepo) List(ctx context.Context, userID *uuid.UUID, serviceName *string) ([]*model.Subscription, error) {
-	query := `
-		SELECT id, service_name, monthly_cost, user_id, start_date, end_date, created_at, updated_at
-		FROM subscriptions 
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argPos := 1
+func (r *subscriptionRepo) UpdateBatch(ctx context.Context, updates []model.SubscriptionUpdate) error {
+	start := time.Now()
 
-	r.logger.Debug(ctx, "Listing subscriptions from database",
-		"user_id", userID,
-		"service_name", serviceName,
-	)
+	tx, err := r.beginAudited(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin subscriptions batch update transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	if userID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argPos)
-		args = append(args, *userID)
-		argPos++
+	for i, u := range updates {
+		if err := r.update(ctx, tx, u.ID, u.Subscription); err != nil {
+			return batchRowError(i, "update", err)
+		}
 	}
 
-	if serviceName != nil {
-		query += fmt.Sprintf(" AND service_name = $%d", argPos)
-		args = append(args, *serviceName)
-		argPos++
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch update transaction",
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.queries.Observe("subscriptions.update_batch", len(updates), time.Since(start))
+
+	return nil
+}
 
-	query += " ORDER BY created_at DESC"
+func (r *subscriptionRepo) DeleteBatch(ctx context.Context, ids []uuid.UUID) error {
+	start := time.Now()
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	tx, err := r.beginAudited(ctx)
 	if err != nil {
-		r.logger.Error(ctx, "Failed to list subscriptions from database",
-			"user_id", userID,
-			"service_name", serviceName,
+		r.logger.Error(ctx, "Failed to begin subscriptions batch delete transaction",
 			"error", err,
 		)
-		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback(ctx)
 
-	var subscriptions []*model.Subscription
-	for rows.Next() {
-		var sub model.Subscription
-		err := rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.MonthlyCost,
-			&sub.UserID,
-			&sub.StartDate,
-			&sub.EndDate,
-			&sub.CreatedAt,
-			&sub.UpdatedAt,
+	if err := r.deleteRows(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions batch delete transaction",
+			"error", err,
 		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.queries.Observe("subscriptions.delete_batch", len(ids), time.Since(start))
+
+	return nil
+}
+
+// deleteRows удаляет подписки ids в транзакции tx; отсутствующая подписка возвращается как *BatchRowError
+func (r *subscriptionRepo) deleteRows(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) error {
+	for i, id := range ids {
+		result, err := tx.Exec(ctx, `DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2`, id, ctxutil.TenantID(ctx))
 		if err != nil {
-			r.logger.Error(ctx, "Failed to scan subscription row",
+			r.logger.Error(ctx, "Failed to delete subscription from database",
+				"subscription_id", id,
 				"error", err,
 			)
-			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		rowsAffected := result.RowsAffected()
+		if rowsAffected == 0 {
+			return &BatchRowError{Row: i, Action: "delete", Message: "subscription not found"}
 		}
-		subscriptions = append(subscriptions, &sub)
 	}
-
-	r.logger.Debug(ctx, "Subscriptions listed successfully",
-		"count", len(subscriptions),
-		"user_id", userID,
-	)
-
-	return subscriptions, nil
+	return nil
 }
 
-func (r *subscriptionRepo) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (int, error) {
-	query := `
-		SELECT 
-			COALESCE(SUM(
-				monthly_cost * (
-					-- Количество месяцев, которые подписка активна в указанном периоде
-					LEAST(
-						EXTRACT(YEAR FROM age($1, start_date)) * 12 + EXTRACT(MONTH FROM age($1, start_date)),
-						EXTRACT(YEAR FROM age(end_date, $2)) * 12 + EXTRACT(MONTH FROM age(end_date, $2)) + 1,
-						EXTRACT(YEAR FROM age($1, $2)) * 12 + EXTRACT(MONTH FROM age($1, $2)) + 1
-					)
-				)
-			), 0)
-		FROM subscriptions 
-		WHERE start_date <= $1  -- подписка началась до конца периода
-			AND (end_date IS NULL OR end_date >= $2)  -- подписка активна после начала периода
-	`
-
-	r.logger.Debug(ctx, "Calculating total cost in database",
-		"start_period", filter.StartPeriod,
-		"end_period", filter.EndPeriod,
-		"user_id", filter.UserID,
-		"service_name", filter.ServiceName,
-	)
+func (r *subscriptionRepo) Sync(ctx context.Context, creates []*model.Subscription, updates []model.SubscriptionUpdate, deletes []uuid.UUID) error {
+	start := time.Now()
 
-	// Парсим периоды используя ParseMonthYear (формат "01-2006")
-	startPeriod, err := model.ParseMonthYear(filter.StartPeriod)
+	tx, err := r.beginAudited(ctx)
 	if err != nil {
-		r.logger.Error(ctx, "Invalid start period format",
-			"start_period", filter.StartPeriod,
+		r.logger.Error(ctx, "Failed to begin subscriptions sync transaction",
 			"error", err,
 		)
-		return 0, fmt.Errorf("invalid start period format, expected MM-YYYY: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	endPeriod, err := model.ParseMonthYear(filter.EndPeriod)
-	if err != nil {
-		r.logger.Error(ctx, "Invalid end period format",
-			"end_period", filter.EndPeriod,
+	// Удаление идет первым: новая подписка может занять место удаляемой
+	if err := r.deleteRows(ctx, tx, deletes); err != nil {
+		return err
+	}
+	for i, u := range updates {
+		if err := r.update(ctx, tx, u.ID, u.Subscription); err != nil {
+			return batchRowError(i, "update", err)
+		}
+	}
+	for i, sub := range creates {
+		if err := r.insert(ctx, tx, sub); err != nil {
+			return batchRowError(i, "create", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit subscriptions sync transaction",
 			"error", err,
 		)
-		return 0, fmt.Errorf("invalid end period format, expected MM-YYYY: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.queries.Observe("subscriptions.sync", len(creates)+len(updates)+len(deletes), time.Since(start))
 
-	// Начало и конец периода
-	periodStart := time.Date(startPeriod.Year(), startPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
-	periodEnd := time.Date(endPeriod.Year(), endPeriod.Month()+1, 0, 23, 59, 59, 0, time.UTC) // последний день месяца
+	return nil
+}
 
-	args := []interface{}{
-		periodEnd,   // конец периода
-		periodStart, // начало периода
+// batchRowError относит к элементу row пачки отсутствие подписки и нарушение
+// ограничения базы; остальные ошибки касаются всей пачки
+func batchRowError(row int, action string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return &BatchRowError{Row: row, Action: action, Message: err.Error()}
 	}
+	if pgErr, ok := asPgError(err); ok && pgErr.Class() == integrityViolationClass {
+		return &BatchRowError{Row: row, Action: action, Message: pgErr.Message}
+	}
+	return err
+}
+
+// recordPause ведет учет месяцев приостановки при смене состояния. Приостановка
+// открывает паузу с текущего месяца, возобновление закрывает ее предыдущим месяцем,
+// а пауза, не захватившая ни одного месяца, удаляется. При отмене пауза остается
+// открытой: отмененная подписка не начисляется и так
+func (r *subscriptionRepo) recordPause(ctx context.Context, tx pgx.Tx, id uuid.UUID, from, to string) error {
+	month := model.CurrentMonth()
+
+	var query string
+	switch {
+	case to == model.StatusPaused:
+		query = `INSERT INTO subscription_pauses (subscription_id, start_date) VALUES ($1, $2)`
+	case from == model.StatusPaused && to == model.StatusActive:
+		query = `
+			WITH empty AS (
+				DELETE FROM subscription_pauses
+				WHERE subscription_id = $1 AND end_date IS NULL AND start_date >= $2
+			)
+			UPDATE subscription_pauses
+			SET end_date = ($2::date - interval '1 month')::date
+			WHERE subscription_id = $1 AND end_date IS NULL AND start_date < $2
+		`
+	default:
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, query, id, month); err != nil {
+		r.logger.Error(ctx, "Failed to record subscription pause",
+			"subscription_id", id,
+			"from", from,
+			"to", to,
+			"error", err,
+		)
+		return fmt.Errorf("failed to record subscription pause: %w", err)
+	}
+	return nil
+}
+
+// recordPrice записывает новую стоимость подписки в историю с месяца sub.PriceFrom и
+// заменяет записи с более поздних месяцев. Вызывается до изменения строки подписки: при
+// первом изменении прежняя стоимость сохраняется с месяца начала подписки
+func (r *subscriptionRepo) recordPrice(ctx context.Context, tx pgx.Tx, id uuid.UUID, sub *model.Subscription) error {
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`
+			INSERT INTO subscription_prices (subscription_id, effective_from, monthly_cost, billing_period, cost)
+			SELECT id, start_date, monthly_cost, billing_period, cost
+			FROM subscriptions
+			WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM subscription_prices WHERE subscription_id = $1)
+		`, []interface{}{id}},
+		{`DELETE FROM subscription_prices WHERE subscription_id = $1 AND effective_from >= $2`, []interface{}{id, *sub.PriceFrom}},
+		{`
+			INSERT INTO subscription_prices (subscription_id, effective_from, monthly_cost, billing_period, cost)
+			VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'monthly'), $5)
+		`, []interface{}{id, *sub.PriceFrom, sub.MonthlyCost, sub.BillingPeriod, sub.Cost}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt.query, stmt.args...); err != nil {
+			r.logger.Error(ctx, "Failed to record subscription price",
+				"subscription_id", id,
+				"effective_from", sub.PriceFrom,
+				"error", err,
+			)
+			return fmt.Errorf("failed to record subscription price: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *subscriptionRepo) ListPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error) {
+	query := `
+		SELECT effective_from, monthly_cost, billing_period, cost
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from
+	`
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscription prices",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription prices: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []model.SubscriptionPrice
+	for rows.Next() {
+		var price model.SubscriptionPrice
+		if err := rows.Scan(&price.From, &price.MonthlyCost, &price.BillingPeriod, &price.Cost); err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription price",
+				"subscription_id", id,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan subscription price: %w", err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Error iterating subscription prices rows",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription prices: %w", err)
+	}
+	r.queries.Observe("subscription_prices.list", len(prices), time.Since(start))
+
+	return prices, nil
+}
+
+func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2`
+
+	r.logger.Info(ctx, "Deleting subscription from database",
+		"subscription_id", id,
+	)
+
+	result, err := r.execAudited(ctx, query, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete subscription from database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Subscription not found for deletion",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+
+	r.logger.Info(ctx, "Subscription deleted successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+func (r *subscriptionRepo) Activate(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE subscriptions SET is_draft = FALSE WHERE id = $1 AND tenant_id = $2 AND is_draft`
+
+	r.logger.Info(ctx, "Activating draft subscription in database",
+		"subscription_id", id,
+	)
+
+	result, err := r.execAudited(ctx, query, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to activate subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to activate subscription: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Draft subscription not found for activation",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+
+	r.logger.Info(ctx, "Subscription activated successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+func (r *subscriptionRepo) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, reason *string) error {
+	query := `
+		UPDATE subscriptions
+		SET status = 'cancelled', end_date = $1, cancel_reason = $2, cancelled_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND tenant_id = $4
+	`
+
+	r.logger.Info(ctx, "Cancelling subscription in database",
+		"subscription_id", id,
+		"end_date", endDate,
+	)
+
+	result, err := r.execAudited(ctx, query, endDate, reason, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to cancel subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Subscription not found for cancellation",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+
+	r.logger.Info(ctx, "Subscription cancelled successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+func (r *subscriptionRepo) Transfer(ctx context.Context, id, from, to uuid.UUID, reason *string) error {
+	// Условие на владельца и состояние защищает от передачи, одновременной с другим изменением
+	query := `
+		UPDATE subscriptions
+		SET user_id = $1
+		WHERE id = $2 AND user_id = $3 AND tenant_id = $4 AND status <> 'cancelled'
+	`
+	historyQuery := `
+		INSERT INTO subscription_transfers (subscription_id, from_user_id, to_user_id, reason)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	r.logger.Info(ctx, "Transferring subscription in database",
+		"subscription_id", id,
+		"from_user_id", from,
+		"to_user_id", to,
+	)
+
+	tx, err := r.beginAudited(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, to, id, from, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to transfer subscription in database",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to transfer subscription: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Subscription changed before transfer",
+			"subscription_id", id,
+		)
+		return fmt.Errorf("subscription was changed concurrently: %w", ErrConflict)
+	}
+
+	if _, err := tx.Exec(ctx, historyQuery, id, from, to, reason); err != nil {
+		r.logger.Error(ctx, "Failed to record subscription transfer",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to record transfer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error(ctx, "Failed to commit transaction",
+			"subscription_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Subscription transferred successfully",
+		"subscription_id", id,
+	)
+	return nil
+}
+
+func (r *subscriptionRepo) List(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) ([]*model.Subscription, int, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions 
+		WHERE 1=1
+	`
+	r.logger.Debug(ctx, "Listing subscriptions from database",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"status", filter.Status,
+		"limit", page.Limit,
+		"offset", page.Offset,
+	)
+
+	conditions, args, err := buildSubscriptionFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	total, err := r.count(ctx, conditions, args, page.Count)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to count subscriptions in database",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	// id в сортировке делает порядок страниц детерминированным при одинаковом created_at
+	query = appendConditions(query, conditions) +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, page.Limit, page.Offset)
+	r.logQuery(ctx, query, args)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscriptions from database",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions, err := r.scanSubscriptions(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.logger.Debug(ctx, "Subscriptions listed successfully",
+		"count", len(subscriptions),
+		"total", total,
+		"user_id", filter.UserID,
+	)
+
+	r.queries.Observe("subscriptions.list", len(subscriptions), time.Since(start))
+
+	return subscriptions, total, nil
+}
+
+// estimateExactThreshold - оценка количества, ниже которой подписки считаются точно:
+// COUNT(*) по небольшой выборке дешевый, а относительная ошибка оценки на ней наибольшая
+const estimateExactThreshold = 10000
+
+// count возвращает количество подписок под условиями. В режиме model.CountEstimate
+// количество оценивает планировщик (EXPLAIN): время ответа не растет вместе с таблицей.
+// Оценка меньше estimateExactThreshold уточняется COUNT(*)
+func (r *subscriptionRepo) count(ctx context.Context, conditions string, args []interface{}, mode string) (int, error) {
+	if mode == model.CountEstimate {
+		estimate, err := r.estimateCount(ctx, conditions, args)
+		if err != nil {
+			return 0, err
+		}
+		if estimate >= estimateExactThreshold {
+			return estimate, nil
+		}
+	}
+
+	var total int
+	query := appendConditions("SELECT COUNT(*) FROM subscriptions WHERE 1=1", conditions)
+	r.logQuery(ctx, query, args)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// estimateCount возвращает оценку планировщика для выборки подписок под условиями.
+// pg_class.reltuples не подходит: в таблице подписки всех организаций, а условие
+// на tenant_id есть всегда
+func (r *subscriptionRepo) estimateCount(ctx context.Context, conditions string, args []interface{}) (int, error) {
+	query := appendConditions("EXPLAIN (FORMAT JSON) SELECT 1 FROM subscriptions WHERE 1=1", conditions)
+	r.logQuery(ctx, query, args)
+
+	var raw []byte
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&raw); err != nil {
+		return 0, fmt.Errorf("failed to explain count query: %w", err)
+	}
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("failed to parse query plan: empty plan")
+	}
+	return int(math.Round(plans[0].Plan.Rows)), nil
+}
+
+func (r *subscriptionRepo) Search(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error) {
+	// term - нормализованный запрос для сравнения и триграмм, pattern - он же
+	// с экранированными спецсимволами LIKE
+	sqlQuery := `
+		WITH q AS (
+			SELECT lower(immutable_unaccent($1)) AS term, lower(immutable_unaccent($2)) AS pattern
+		), s AS (
+			SELECT subscriptions.*, lower(immutable_unaccent(service_name)) AS normalized
+			FROM subscriptions
+			WHERE tenant_id = $4
+		)
+		SELECT s.id, s.service_name, s.monthly_cost, s.user_id, s.start_date, s.end_date, s.prepaid_amount, s.is_draft, s.status, s.cancel_reason, s.cancelled_at, s.metadata, s.service_id, s.description, s.billing_period, s.cost,
+			(` + subscriptionTagsQuery + `s.id) AS tags, s.change_seq, s.created_at, s.updated_at
+		FROM s, q
+		WHERE (s.normalized LIKE '%' || q.pattern || '%' ESCAPE '\' OR s.normalized % q.term)
+	`
+	args := []interface{}{query, escapeLike(query), limit, ctxutil.TenantID(ctx)}
+
+	if userID != nil {
+		sqlQuery += " AND s.user_id = $5"
+		args = append(args, *userID)
+	}
+	// Сначала точные совпадения, затем совпадения с начала названия, затем по подстроке
+	// и, наконец, похожие по триграммам (опечатки)
+	sqlQuery += `
+		ORDER BY
+			CASE
+				WHEN s.normalized = q.term THEN 0
+				WHEN s.normalized LIKE q.pattern || '%' ESCAPE '\' THEN 1
+				WHEN s.normalized LIKE '%' || q.pattern || '%' ESCAPE '\' THEN 2
+				ELSE 3
+			END,
+			similarity(s.normalized, q.term) DESC,
+			s.created_at DESC,
+			s.id
+		LIMIT $3
+	`
+
+	r.logger.Debug(ctx, "Searching subscriptions in database",
+		"query", query,
+		"user_id", userID,
+	)
+	r.logQuery(ctx, sqlQuery, args)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to search subscriptions in database",
+			"query", query,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to search subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions, err := r.scanSubscriptions(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.queries.Observe("subscriptions.search", len(subscriptions), time.Since(start))
+
+	return subscriptions, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы пользовательский ввод искался буквально
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *subscriptionRepo) ListAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) ([]*model.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE 1=1
+	`
+
+	conditions, args, err := buildSubscriptionFilter(ctx, filter, limit)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+	query = appendConditions(query, conditions)
+
+	// Сравнение кортежей использует индекс (created_at, id) и, в отличие от OFFSET,
+	// не пропускает и не повторяет строки при вставках между страницами
+	if after != nil {
+		query += fmt.Sprintf(" AND (created_at, id) > ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+	query += " ORDER BY created_at, id LIMIT $1"
+
+	r.logQuery(ctx, query, args)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscriptions page from database",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions, err := r.scanSubscriptions(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.queries.Observe("subscriptions.list_after", len(subscriptions), time.Since(start))
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) Stream(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE 1=1
+	`
+
+	conditions, args, err := buildSubscriptionFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build subscriptions filter",
+			"error", err,
+		)
+		return fmt.Errorf("failed to build filter: %w", err)
+	}
+	query = appendConditions(query, conditions) + " ORDER BY created_at, id"
+	r.logQuery(ctx, query, args)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to stream subscriptions from database",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return fmt.Errorf("failed to stream subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription row",
+				"error", err,
+			)
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate subscription rows",
+			"streamed", count,
+			"error", err,
+		)
+		return fmt.Errorf("failed to stream subscriptions: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.stream", count, time.Since(start))
+
+	return nil
+}
+
+func (r *subscriptionRepo) scanSubscriptions(ctx context.Context, rows pgx.Rows) ([]*model.Subscription, error) {
+	var subscriptions []*model.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate subscription rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// scanSubscription читает строку subscriptionColumns; для активных подписок
+// с прошедшим end_date возвращается состояние expired
+func scanSubscription(row rowScanner) (*model.Subscription, error) {
+	var sub model.Subscription
+	err := row.Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.MonthlyCost,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.PrepaidAmount,
+		&sub.IsDraft,
+		&sub.Status,
+		&sub.CancelReason,
+		&sub.CancelledAt,
+		&sub.Metadata,
+		&sub.ServiceID,
+		&sub.Description,
+		&sub.BillingPeriod,
+		&sub.Cost,
+		&sub.Tags,
+		&sub.ChangeSeq,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	sub.Status = model.EffectiveStatus(sub.Status, sub.EndDate, model.CurrentMonth())
+	return &sub, nil
+}
+
+// buildSubscriptionFilter собирает условия фильтра списка подписок организации запроса.
+// Переданные args уже заняли первые плейсхолдеры запроса
+func buildSubscriptionFilter(ctx context.Context, filter model.SubscriptionFilter, args ...interface{}) (string, []interface{}, error) {
+	where := newWhereBuilder(subscriptionFilterColumns, args...)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.UserID != nil {
+		where.Where("user_id", opEq, *filter.UserID)
+	}
+	if filter.ServiceName != nil {
+		where.Where("service_name", opEq, *filter.ServiceName)
+	}
+	if filter.ServiceID != nil {
+		where.Where("service_id", opEq, *filter.ServiceID)
+	}
+	if filter.Tag != nil {
+		where.HasTag(*filter.Tag)
+	}
+	where.NotIn("service_name", interfaceSlice(filter.ExcludeServiceNames))
+	where.NotIn("user_id", interfaceSlice(filter.ExcludeUserIDs))
+
+	// expired хранится как active: состояния различаются только по end_date
+	// относительно текущего месяца
+	var status string
+	if filter.Status != nil {
+		status = *filter.Status
+		if status == model.StatusExpired {
+			where.Where("status", opEq, model.StatusActive)
+		} else {
+			where.Where("status", opEq, status)
+		}
+	}
+
+	conditions, args, err := where.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var endDate string
+	switch status {
+	case model.StatusActive:
+		endDate = fmt.Sprintf("(end_date IS NULL OR end_date >= $%d)", len(args)+1)
+	case model.StatusExpired:
+		endDate = fmt.Sprintf("end_date < $%d", len(args)+1)
+	default:
+		return conditions, args, nil
+	}
+	args = append(args, model.CurrentMonth())
+	if conditions == "" {
+		return endDate, args, nil
+	}
+	return conditions + " AND " + endDate, args, nil
+}
+
+func (r *subscriptionRepo) ListChanges(ctx context.Context, sinceSeq int64, limit int) ([]*model.SubscriptionChange, error) {
+	query := `
+		SELECT seq, subscription_id, operation, payload, changed_at
+		FROM subscription_changes
+		WHERE seq > $1 AND tenant_id = $3
+		ORDER BY seq
+		LIMIT $2
+	`
+
+	r.logger.Debug(ctx, "Listing subscription changes from database",
+		"since_seq", sinceSeq,
+		"limit", limit,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, sinceSeq, limit, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list subscription changes from database",
+			"since_seq", sinceSeq,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription changes: %w", err)
+	}
+	defer rows.Close()
 
-	argPos := 3
+	var changes []*model.SubscriptionChange
+	for rows.Next() {
+		var change model.SubscriptionChange
+		err := rows.Scan(
+			&change.Seq,
+			&change.SubscriptionID,
+			&change.Operation,
+			&change.Payload,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan subscription change row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan subscription change: %w", err)
+		}
+		changes = append(changes, &change)
+	}
 
-	// Добавляем фильтры
-	conditions := []string{}
-	if filter.UserID != uuid.Nil {
-		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argPos))
-		args = append(args, filter.UserID)
-		argPos++
+	r.logger.Debug(ctx, "Subscription changes listed successfully",
+		"count", len(changes),
+		"since_seq", sinceSeq,
+	)
+
+	r.queries.Observe("subscriptions.list_changes", len(changes), time.Since(start))
+
+	return changes, nil
+}
+
+func (r *subscriptionRepo) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.CostTotals, error) {
+	r.logger.Debug(ctx, "Calculating total cost in database",
+		"start_period", filter.StartPeriod,
+		"end_period", filter.EndPeriod,
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+	)
+
+	// Парсим периоды используя ParseMonthYear (формат "01-2006")
+	startPeriod, err := model.ParseMonthYear(filter.StartPeriod)
+	if err != nil {
+		r.logger.Error(ctx, "Invalid start period format",
+			"start_period", filter.StartPeriod,
+			"error", err,
+		)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start period format, expected MM-YYYY: %w", err)
+	}
+
+	endPeriod, err := model.ParseMonthYear(filter.EndPeriod)
+	if err != nil {
+		r.logger.Error(ctx, "Invalid end period format",
+			"end_period", filter.EndPeriod,
+			"error", err,
+		)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid end period format, expected MM-YYYY: %w", err)
+	}
+
+	// Начало и конец периода
+	periodStart := time.Date(startPeriod.Year(), startPeriod.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(endPeriod.Year(), endPeriod.Month()+1, 0, 23, 59, 59, 0, time.UTC) // последний день месяца
+
+	groupKey, err := model.ParseSummaryGroupBy(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отмененные подписки и подписки, закончившиеся до текущего месяца, считаются отмененными
+	// $1 - конец периода, $2 - начало периода, $3 - начало текущего месяца, $4 - ключ метки
+	// при группировке по метке
+	args := []interface{}{periodEnd, periodStart, model.CurrentMonth()}
+	groupColumn := "NULL::text"
+	switch {
+	case groupKey != "":
+		args = append(args, groupKey)
+		groupColumn = "s.metadata ->> $4"
+	case filter.GroupBy == model.SummaryGroupByService:
+		groupColumn = "s.service_name"
 	}
 
+	// Стоимость каждой подписки за каждый месяц периода с учетом действующих в этом месяце скидок,
+	// условия фильтрации добавляются к этому запросу
+	costs := `
+		SELECT
+			s.end_date,
+			s.status,
+			` + groupColumn + ` AS group_key,
+			GREATEST(
+				` + monthlyBaseExpr + ` * (100 - LEAST(d.percent, 100)) / 100 - d.fixed,
+				0
+			) AS cost
+		FROM subscriptions s
+		-- Месяцы, в которые подписка активна внутри периода
+		CROSS JOIN LATERAL generate_series(
+			GREATEST(s.start_date, $2::date),
+			LEAST(COALESCE(s.end_date, $1::date), $1::date),
+			interval '1 month'
+		) AS m(month)
+		CROSS JOIN LATERAL (` + effectivePriceQuery + `) AS p  -- стоимость, действующая в месяце
+		CROSS JOIN LATERAL (` + activeDiscountsQuery + `) AS d
+		WHERE s.start_date <= $1  -- подписка началась до конца периода
+			AND (s.end_date IS NULL OR s.end_date >= $2)  -- подписка активна после начала периода
+			AND NOT s.is_draft  -- черновики не учитываются до активации
+			AND ` + notPausedCondition + `  -- месяцы приостановки не учитываются
+	`
+
+	where := newWhereBuilder(subscriptionFilterColumns, args...)
+	where.Where("tenant_id", opEq, ctxutil.TenantID(ctx))
+	if filter.UserID != uuid.Nil {
+		where.Where("user_id", opEq, filter.UserID)
+	}
 	if filter.ServiceName != "" {
-		conditions = append(conditions, fmt.Sprintf("service_name = $%d", argPos))
-		args = append(args, filter.ServiceName)
-		argPos++
+		where.Where("service_name", opEq, filter.ServiceName)
+	}
+	if filter.ServiceID != nil {
+		where.Where("service_id", opEq, *filter.ServiceID)
 	}
+	if filter.Tag != "" {
+		where.HasTag(filter.Tag)
+	}
+	where.NotIn("service_name", interfaceSlice(filter.ExcludeServiceNames))
+	where.NotIn("user_id", interfaceSlice(filter.ExcludeUserIDs))
 
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
+	conditions, args, err := where.Build()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to build total cost filter",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build filter: %w", err)
 	}
 
-	var totalCost int
-	err = r.db.QueryRowContext(ctx, query, args...).Scan(&totalCost)
+	// Без группировки group_key у всех строк NULL, и запрос возвращает не больше одной строки.
+	// Строка costs - месяц подписки, поэтому COUNT(*) - число оплачиваемых месяцев группы
+	query := `
+		WITH costs AS (` + appendConditions(costs, conditions) + `)
+		SELECT
+			group_key,
+			COALESCE(SUM(cost) FILTER (WHERE status <> 'cancelled' AND (end_date IS NULL OR end_date >= $3)), 0),
+			COALESCE(SUM(cost) FILTER (WHERE status = 'cancelled' OR end_date < $3), 0),
+			COUNT(*)
+		FROM costs
+		GROUP BY group_key
+	`
+	r.logQuery(ctx, query, args)
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		r.logger.Error(ctx, "Failed to calculate total cost in database",
 			"start_period", filter.StartPeriod,
 			"end_period", filter.EndPeriod,
 			"error", err,
 		)
-		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+	defer rows.Close()
+
+	// Суммы возвращаются как numeric, округление выполняет сервис по настроенному правилу
+	acc := newCostAccumulator(filter.GroupBy != "")
+	for rows.Next() {
+		var key *string
+		var active, cancelled string
+		var months int
+		if err := rows.Scan(&key, &active, &cancelled, &months); err != nil {
+			r.logger.Error(ctx, "Failed to scan total cost row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+		}
+
+		parsed, err := parseCostTotals(active, cancelled)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to parse total cost",
+				"active_cost", active,
+				"cancelled_cost", cancelled,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to parse total cost: %w", err)
+		}
+		acc.add(key, parsed.Active, parsed.Cancelled, months)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate total cost rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
 	}
 
+	totals := acc.result()
 	r.logger.Info(ctx, "Total cost calculated successfully",
-		"total_cost", totalCost,
+		"total_cost", totals.Total.FloatString(2),
+		"groups", len(totals.Groups),
 		"start_period", filter.StartPeriod,
 		"end_period", filter.EndPeriod,
 	)
 
-	return totalCost, nil
+	return totals, nil
+}
+
+// parseCostTotals разбирает суммы numeric активных и закончившихся подписок
+func parseCostTotals(active, cancelled string) (*model.CostTotals, error) {
+	var totals model.CostTotals
+	var err error
+
+	if totals.Active, err = money.ParseDecimal(active); err != nil {
+		return nil, err
+	}
+	if totals.Cancelled, err = money.ParseDecimal(cancelled); err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+func (r *subscriptionRepo) MonthlySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]model.MonthlySpend, error) {
+	query := `
+		SELECT m.month, COALESCE(ROUND(SUM(GREATEST(p.monthly_cost * (100 - LEAST(d.percent, 100)) / 100.0 - d.fixed, 0))), 0)::int
+		FROM generate_series($2::date, $3::date, interval '1 month') AS m(month)
+		LEFT JOIN subscriptions s
+			ON s.user_id = $1
+			AND s.tenant_id = $4
+			AND NOT s.is_draft
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+			AND ` + notPausedCondition + `
+		LEFT JOIN LATERAL (` + effectivePriceQuery + `) AS p ON TRUE
+		LEFT JOIN LATERAL (` + activeDiscountsQuery + `) AS d ON TRUE
+		GROUP BY m.month
+		ORDER BY m.month
+	`
+
+	r.logger.Debug(ctx, "Calculating monthly spend in database",
+		"user_id", userID,
+		"from", from,
+		"to", to,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, userID, from, to, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate monthly spend in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []model.MonthlySpend
+	for rows.Next() {
+		var month model.MonthlySpend
+		if err := rows.Scan(&month.Month, &month.Total); err != nil {
+			r.logger.Error(ctx, "Failed to scan monthly spend row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan monthly spend: %w", err)
+		}
+		spend = append(spend, month)
+	}
+
+	r.queries.Observe("subscriptions.monthly_spend", len(spend), time.Since(start))
+
+	return spend, nil
+}
+
+func (r *subscriptionRepo) MonthlyCharges(ctx context.Context, userID uuid.UUID, month time.Time) ([]model.MonthlyCharge, error) {
+	query := `
+		SELECT
+			s.id,
+			s.service_name,
+			c.base,
+			GREATEST(c.base * (100 - LEAST(d.percent, 100)) / 100 - d.fixed, 0)
+		FROM subscriptions s
+		CROSS JOIN (SELECT $2::date AS month) AS m
+		CROSS JOIN LATERAL (` + effectivePriceQuery + `) AS p
+		CROSS JOIN LATERAL (SELECT ` + monthlyBaseExpr + ` AS base) AS c
+		CROSS JOIN LATERAL (` + activeDiscountsQuery + `) AS d
+		WHERE s.user_id = $1
+			AND s.tenant_id = $3
+			AND NOT s.is_draft
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+			AND ` + notPausedCondition + `
+		ORDER BY s.service_name, s.id
+	`
+
+	r.logger.Debug(ctx, "Calculating monthly charges in database",
+		"user_id", userID,
+		"month", month,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, userID, month, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to calculate monthly charges in database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate monthly charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []model.MonthlyCharge
+	for rows.Next() {
+		var charge model.MonthlyCharge
+		var base, amount string
+		if err := rows.Scan(&charge.SubscriptionID, &charge.ServiceName, &base, &amount); err != nil {
+			r.logger.Error(ctx, "Failed to scan monthly charge row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan monthly charge: %w", err)
+		}
+		if charge.Base, err = money.ParseDecimal(base); err != nil {
+			return nil, fmt.Errorf("failed to parse monthly charge: %w", err)
+		}
+		if charge.Amount, err = money.ParseDecimal(amount); err != nil {
+			return nil, fmt.Errorf("failed to parse monthly charge: %w", err)
+		}
+		charges = append(charges, charge)
+	}
+
+	r.queries.Observe("subscriptions.monthly_charges", len(charges), time.Since(start))
+
+	return charges, nil
+}
+
+func (r *subscriptionRepo) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT user_id FROM subscriptions WHERE tenant_id = $1 AND NOT is_draft`
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list user IDs from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list user IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			r.logger.Error(ctx, "Failed to scan user ID row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	r.logger.Debug(ctx, "User IDs listed successfully",
+		"count", len(userIDs),
+	)
+
+	r.queries.Observe("subscriptions.list_user_ids", len(userIDs), time.Since(start))
+
+	return userIDs, nil
+}
+
+func (r *subscriptionRepo) ListTenants(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT tenant_id FROM subscriptions ORDER BY tenant_id`
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list tenants from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			r.logger.Error(ctx, "Failed to scan tenant row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	r.queries.Observe("subscriptions.list_tenants", len(tenants), time.Since(start))
+
+	return tenants, nil
+}
+
+// logQuery фиксирует итоговый динамический запрос и количество параметров для аудита
+func (r *subscriptionRepo) logQuery(ctx context.Context, query string, args []interface{}) {
+	r.logger.Debug(ctx, "Executing dynamic query",
+		"query", query,
+		"args_count", len(args),
+	)
+}
+
+func (r *subscriptionRepo) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	// Приостановленные подписки учитываются в количестве, но не в стоимости
+	query := `
+		SELECT
+			service_name,
+			COUNT(*),
+			COALESCE(SUM(monthly_cost) FILTER (WHERE status <> 'paused'), 0)
+		FROM subscriptions
+		WHERE user_id = $1
+			AND tenant_id = $3
+			AND NOT is_draft
+			AND start_date <= $2
+			AND (end_date IS NULL OR end_date >= $2)
+		GROUP BY service_name
+		ORDER BY 3 DESC, service_name
+	`
+
+	r.logger.Debug(ctx, "Listing user services from database",
+		"user_id", userID,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, userID, model.CurrentMonth(), ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list user services from database",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list user services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []model.UserService{}
+	for rows.Next() {
+		var service model.UserService
+		if err := rows.Scan(&service.ServiceName, &service.Subscriptions, &service.MonthlyCost); err != nil {
+			r.logger.Error(ctx, "Failed to scan user service row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan user service: %w", err)
+		}
+		services = append(services, service)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate user service rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read user services: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.list_user_services", len(services), time.Since(start))
+
+	return services, nil
+}
+
+// normalizedServiceNameSQL - SQL-аналог model.NormalizeServiceName
+const normalizedServiceNameSQL = `lower(btrim(regexp_replace(service_name, '\s+', ' ', 'g')))`
+
+func (r *subscriptionRepo) ServiceNameUsage(ctx context.Context, normalized []string) ([]model.ServiceNameUsage, error) {
+	query := `
+		SELECT service_name, COUNT(DISTINCT user_id)
+		FROM subscriptions
+		WHERE tenant_id = $2 AND ` + normalizedServiceNameSQL + ` = ANY($1)
+		GROUP BY service_name
+		ORDER BY service_name
+	`
+
+	r.logger.Debug(ctx, "Reading service name usage from database",
+		"names", len(normalized),
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, normalized, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to read service name usage from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read service name usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []model.ServiceNameUsage
+	for rows.Next() {
+		var u model.ServiceNameUsage
+		if err := rows.Scan(&u.ServiceName, &u.Users); err != nil {
+			r.logger.Error(ctx, "Failed to scan service name usage row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan service name usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate service name usage rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read service name usage: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.service_name_usage", len(usage), time.Since(start))
+	return usage, nil
+}
+
+// activeInMonthCondition - подписки организации $1, действующие в месяце $2, без черновиков
+const activeInMonthCondition = `
+	tenant_id = $1
+	AND NOT is_draft
+	AND start_date <= $2
+	AND (end_date IS NULL OR end_date >= $2)
+`
+
+func (r *subscriptionRepo) ListUserSpend(ctx context.Context, month time.Time) ([]model.UserSpend, error) {
+	// Приостановленные подписки учитываются в количестве, но не в стоимости
+	query := `
+		SELECT
+			user_id,
+			COUNT(*),
+			COUNT(DISTINCT ` + normalizedServiceNameSQL + `),
+			COALESCE(SUM(monthly_cost) FILTER (WHERE status <> 'paused'), 0)
+		FROM subscriptions
+		WHERE ` + activeInMonthCondition + `
+		GROUP BY user_id
+		ORDER BY 4 DESC, user_id
+	`
+
+	r.logger.Debug(ctx, "Listing user spend from database",
+		"month", month,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, ctxutil.TenantID(ctx), month)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list user spend from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list user spend: %w", err)
+	}
+	defer rows.Close()
+
+	spend := []model.UserSpend{}
+	for rows.Next() {
+		var s model.UserSpend
+		if err := rows.Scan(&s.UserID, &s.Subscriptions, &s.Services, &s.MonthlyCost); err != nil {
+			r.logger.Error(ctx, "Failed to scan user spend row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan user spend: %w", err)
+		}
+		spend = append(spend, s)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate user spend rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read user spend: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.list_user_spend", len(spend), time.Since(start))
+
+	return spend, nil
+}
+
+func (r *subscriptionRepo) ListServiceSpend(ctx context.Context, month time.Time) ([]model.SharedService, error) {
+	// Название сервиса - самое частое из написаний, при равенстве - первое по алфавиту
+	query := `
+		SELECT
+			mode() WITHIN GROUP (ORDER BY service_name),
+			COUNT(DISTINCT user_id),
+			COUNT(*),
+			COALESCE(SUM(monthly_cost) FILTER (WHERE status <> 'paused'), 0)
+		FROM subscriptions
+		WHERE ` + activeInMonthCondition + `
+		GROUP BY ` + normalizedServiceNameSQL + `
+		ORDER BY 2 DESC, 4 DESC, 1
+	`
+
+	r.logger.Debug(ctx, "Listing service spend from database",
+		"month", month,
+	)
+
+	start := time.Now()
+	rows, err := r.db.Query(ctx, query, ctxutil.TenantID(ctx), month)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list service spend from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list service spend: %w", err)
+	}
+	defer rows.Close()
+
+	services := []model.SharedService{}
+	for rows.Next() {
+		var s model.SharedService
+		if err := rows.Scan(&s.ServiceName, &s.Users, &s.Subscriptions, &s.MonthlyCost); err != nil {
+			r.logger.Error(ctx, "Failed to scan service spend row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan service spend: %w", err)
+		}
+		services = append(services, s)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error(ctx, "Failed to iterate service spend rows",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read service spend: %w", err)
+	}
+
+	r.queries.Observe("subscriptions.list_service_spend", len(services), time.Since(start))
+
+	return services, nil
 }
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// newPostgresRepo подключается к TEST_DATABASE_URL с примененными миграциями (например,
+// к базе из docker-compose) и возвращает репозиторий и контекст новой организации.
+// Данные организаций, созданных через tenantContext, удаляются после теста
+func newPostgresRepo(t *testing.T) (SubscriptionRepository, func(ctx context.Context) context.Context) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := database.Open(ctx, dsn, database.Settings{MaxOpenConns: 4, MaxIdleConns: 2})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	if err := database.CheckSchema(ctx, pool.DB); err != nil {
+		t.Fatalf("CheckSchema: %v", err)
+	}
+
+	log := logger.New(slog.LevelError)
+	teardown := NewTeardownRepository(pool.DB, log)
+	tenantContext := func(ctx context.Context) context.Context {
+		tenantID := "test-" + uuid.NewString()[:8]
+		t.Cleanup(func() {
+			if _, err := teardown.Teardown(context.Background(), tenantID, nil, false); err != nil {
+				t.Errorf("failed to remove tenant %s: %v", tenantID, err)
+			}
+		})
+		return ctxutil.WithTenantID(ctx, tenantID)
+	}
+	return NewSubscriptionRepository(pool, metrics.NewQueries(), log), tenantContext
+}
+
+func TestPostgresCalculateTotalCost(t *testing.T) {
+	repo, tenantContext := newPostgresRepo(t)
+	ctx := tenantContext(context.Background())
+	current := model.CurrentMonth()
+	start := current.AddDate(0, -3, 0)
+	ended := current.AddDate(0, -2, 0)
+	prepaid := 1200
+	userID := uuid.New()
+
+	subs := []*model.Subscription{
+		// 4 месяца по 100, текущий месяц приостановлен
+		{ServiceName: "Netflix", MonthlyCost: 100, UserID: userID, StartDate: start},
+		// Закончилась два месяца назад: 2 месяца по 10
+		{ServiceName: "Spotify", MonthlyCost: 10, UserID: userID, StartDate: start, EndDate: &ended},
+		// Годовая предоплата: 4 месяца по 1200/12
+		{ServiceName: "Yandex Plus", MonthlyCost: 100, PrepaidAmount: &prepaid, UserID: userID, StartDate: start},
+		// Черновики не учитываются
+		{ServiceName: "Draft", MonthlyCost: 1000, UserID: userID, StartDate: start, IsDraft: true},
+	}
+	for _, sub := range subs {
+		if err := repo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+	// Подписка того же пользователя в другой организации не видна
+	if err := repo.Create(tenantContext(context.Background()), &model.Subscription{ServiceName: "Netflix", MonthlyCost: 5000, UserID: userID, StartDate: start}); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	netflix := *subs[0]
+	netflix.Status = model.StatusPaused
+	if err := repo.Update(ctx, netflix.ID, &netflix); err != nil {
+		t.Fatalf("failed to pause subscription: %v", err)
+	}
+
+	for name, filter := range map[string]model.SummaryFilter{
+		"tenant": {},
+		"user":   {UserID: userID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			filter.StartPeriod = start.Format("01-2006")
+			filter.EndPeriod = current.Format("01-2006")
+			totals, err := repo.CalculateTotalCost(ctx, filter)
+			if err != nil {
+				t.Fatalf("CalculateTotalCost: %v", err)
+			}
+
+			for name, tc := range map[string]struct{ got, want string }{
+				"total":     {totals.Total.RatString(), "720"},
+				"active":    {totals.Active.RatString(), "700"},
+				"cancelled": {totals.Cancelled.RatString(), "20"},
+			} {
+				if tc.got != tc.want {
+					t.Errorf("%s = %s, want %s", name, tc.got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+// TestPostgresCreateBatch проверяет загрузку пачки через COPY: строка, нарушившая
+// ограничение, возвращается как BatchRowError и пачка не создается, а у созданной
+// пачки заполнены поля, которые задает база
+func TestPostgresCreateBatch(t *testing.T) {
+	repo, tenantContext := newPostgresRepo(t)
+	ctx := tenantContext(context.Background())
+	start := model.CurrentMonth()
+	userID := uuid.New()
+
+	invalid := []*model.Subscription{
+		{ServiceName: "Netflix", MonthlyCost: 400, UserID: userID, StartDate: start},
+		// Нарушает subscriptions_monthly_cost_check
+		{ServiceName: "Spotify", MonthlyCost: -1, UserID: userID, StartDate: start},
+		{ServiceName: "Yandex Plus", MonthlyCost: 300, UserID: userID, StartDate: start},
+	}
+	var rowErr *BatchRowError
+	if err := repo.CreateBatch(ctx, invalid); !errors.As(err, &rowErr) || rowErr.Row != 1 {
+		t.Fatalf("CreateBatch() error = %v, want BatchRowError for row 1", err)
+	}
+	if _, total, err := repo.List(ctx, model.SubscriptionFilter{}, model.Pagination{Limit: 10}); err != nil || total != 0 {
+		t.Fatalf("List() after failed batch = %d, %v, want nothing created", total, err)
+	}
+
+	cost := 1200
+	batch := []*model.Subscription{
+		{ServiceName: "Netflix", MonthlyCost: 400, UserID: userID, StartDate: start, Metadata: model.Metadata{"project": "apollo"}},
+		{ServiceName: "Yandex Plus", MonthlyCost: 100, UserID: userID, StartDate: start, BillingPeriod: model.BillingYearly, Cost: &cost},
+	}
+	if err := repo.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	for _, sub := range batch {
+		if sub.ID == uuid.Nil || sub.ChangeSeq == 0 || sub.CreatedAt.IsZero() {
+			t.Errorf("created %s = id %s, change_seq %d, created_at %s, want values from the database", sub.ServiceName, sub.ID, sub.ChangeSeq, sub.CreatedAt)
+		}
+		got, err := repo.GetByID(ctx, sub.ID)
+		if err != nil || got == nil {
+			t.Fatalf("GetByID(%s) = %v, %v", sub.ID, got, err)
+		}
+		if got.ServiceName != sub.ServiceName || got.MonthlyCost != sub.MonthlyCost || got.BillingPeriod != sub.BillingPeriod || got.Status != model.StatusActive {
+			t.Errorf("GetByID(%s) = %+v, want %+v", sub.ID, got, sub)
+		}
+	}
+	if got, _ := repo.GetByID(ctx, batch[0].ID); got.Metadata["project"] != "apollo" {
+		t.Errorf("metadata = %v, want project=apollo", got.Metadata)
+	}
+
+	// Пачка создается в организации запроса
+	if got, err := repo.GetByID(tenantContext(context.Background()), batch[0].ID); err != nil || got != nil {
+		t.Errorf("GetByID() from other tenant = %v, %v, want not found", got, err)
+	}
+}
+
+// TestPostgresListAfterTies проверяет постраничное чтение по (created_at, id): подписки
+// одной пачки создаются в одной транзакции и получают одинаковый created_at, поэтому
+// граница страницы проходит внутри группы с равным временем, и порядок задает id
+func TestPostgresListAfterTies(t *testing.T) {
+	repo, tenantContext := newPostgresRepo(t)
+	ctx := tenantContext(context.Background())
+	start := model.CurrentMonth()
+
+	var created []*model.Subscription
+	for _, size := range []int{5, 1, 4} {
+		batch := make([]*model.Subscription, size)
+		for i := range batch {
+			batch[i] = &model.Subscription{ServiceName: "Netflix", MonthlyCost: 100, UserID: uuid.New(), StartDate: start}
+		}
+		if err := repo.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch() error = %v", err)
+		}
+		created = append(created, batch...)
+	}
+	if !created[0].CreatedAt.Equal(created[4].CreatedAt) {
+		t.Fatalf("created_at within a batch differs: %s and %s", created[0].CreatedAt, created[4].CreatedAt)
+	}
+
+	// PostgreSQL сравнивает uuid побайтово
+	ordered := slices.Clone(created)
+	slices.SortFunc(ordered, func(a, b *model.Subscription) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	want := make([]uuid.UUID, len(ordered))
+	for i, sub := range ordered {
+		want[i] = sub.ID
+	}
+
+	// Страницы по 2 подписки: граница проходит внутри первой и последней пачки
+	var got []uuid.UUID
+	var after *model.SubscriptionCursor
+	for page := 0; ; page++ {
+		if page > len(created) {
+			t.Fatalf("pagination did not finish after %d pages", page)
+		}
+		items, err := repo.ListAfter(ctx, model.SubscriptionFilter{}, after, 2)
+		if err != nil {
+			t.Fatalf("ListAfter() error = %v", err)
+		}
+		if len(items) == 0 {
+			break
+		}
+		for _, sub := range items {
+			got = append(got, sub.ID)
+		}
+		last := items[len(items)-1]
+		after = &model.SubscriptionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("pages = %v, want %v in (created_at, id) order without gaps or repeats", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionTagsQuery - теги подписки массивом JSON по алфавиту. Запрос дополняется
+// ссылкой на id подписки внешнего запроса
+const subscriptionTagsQuery = `SELECT COALESCE(json_agg(t.name ORDER BY t.name), '[]') FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE st.subscription_id = `
+
+// sqliteSubscriptionTagsQuery - subscriptionTagsQuery для SQLite; json_group_array
+// без строк возвращает пустой массив
+const sqliteSubscriptionTagsQuery = `SELECT json_group_array(t.name ORDER BY t.name) FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE st.subscription_id = `
+
+// sqliteSubscriptionColumns - subscriptionColumns для SQLite
+const sqliteSubscriptionColumns = `id, service_name, monthly_cost, user_id, start_date, end_date, prepaid_amount, is_draft, status, cancel_reason, cancelled_at, metadata, service_id, description, billing_period, cost, (` + sqliteSubscriptionTagsQuery + `subscriptions.id) AS tags, change_seq, created_at, updated_at`
+
+// writeSubscriptionTags заменяет теги подписки в транзакции через exec: недостающие теги
+// организации создаются, связи с прежними тегами удаляются. Запросы не зависят от драйвера
+func writeSubscriptionTags(exec func(query string, args ...interface{}) error, tenantID string, subscriptionID uuid.UUID, tags model.Tags) error {
+	if err := exec(`DELETE FROM subscription_tags WHERE subscription_id = $1`, subscriptionID); err != nil {
+		return err
+	}
+	for _, name := range tags {
+		if err := exec(`INSERT INTO tags (id, tenant_id, name) VALUES ($1, $2, $3) ON CONFLICT (tenant_id, name) DO NOTHING`, uuid.New(), tenantID, name); err != nil {
+			return err
+		}
+		if err := exec(`
+			INSERT INTO subscription_tags (subscription_id, tag_id)
+			SELECT $1, id FROM tags WHERE tenant_id = $2 AND name = $3
+		`, subscriptionID, tenantID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
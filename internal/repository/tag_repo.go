@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// TagRepository управляет тегами организации. Теги подписок сохраняет SubscriptionRepository
+type TagRepository interface {
+	// Create сохраняет тег и заполняет ID и CreatedAt
+	Create(ctx context.Context, tag *model.Tag) error
+	// Rename переименовывает тег у всех подписок и заполняет CreatedAt и Subscriptions
+	Rename(ctx context.Context, id uuid.UUID, tag *model.Tag) error
+	// Delete удаляет тег и снимает его со всех подписок
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает теги по названию с числом подписок у каждого
+	List(ctx context.Context) ([]*model.Tag, error)
+}
+
+// tagSubscriptionsQuery - число подписок с тегом t
+const tagSubscriptionsQuery = `(SELECT COUNT(*) FROM subscription_tags st WHERE st.tag_id = t.id)`
+
+type tagRepo struct {
+	db      *sql.DB
+	queries *metrics.Queries
+	logger  *logger.Logger
+}
+
+func NewTagRepository(db *sql.DB, queries *metrics.Queries, logger *logger.Logger) TagRepository {
+	return &tagRepo{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+func (r *tagRepo) Create(ctx context.Context, tag *model.Tag) error {
+	query := `
+		INSERT INTO tags (name, tenant_id)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+
+	r.logger.Info(ctx, "Creating tag in database",
+		"name", tag.Name,
+	)
+
+	err := r.db.QueryRowContext(ctx, query, tag.Name, ctxutil.TenantID(ctx)).Scan(&tag.ID, &tag.CreatedAt)
+	if err != nil {
+		if pgErr, ok := asPgError(err); ok && pgErr.Code == uniqueViolation {
+			r.logger.Warn(ctx, "Tag already exists",
+				"name", tag.Name,
+			)
+			return fmt.Errorf("tag already exists: %w", ErrConflict)
+		}
+		r.logger.Error(ctx, "Failed to create tag in database",
+			"name", tag.Name,
+			"error", err,
+		)
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+func (r *tagRepo) Rename(ctx context.Context, id uuid.UUID, tag *model.Tag) error {
+	query := `
+		UPDATE tags t
+		SET name = $1
+		WHERE t.id = $2 AND t.tenant_id = $3
+		RETURNING t.created_at, ` + tagSubscriptionsQuery + `
+	`
+
+	r.logger.Info(ctx, "Renaming tag in database",
+		"tag_id", id,
+		"name", tag.Name,
+	)
+
+	err := r.db.QueryRowContext(ctx, query, tag.Name, id, ctxutil.TenantID(ctx)).Scan(&tag.CreatedAt, &tag.Subscriptions)
+	if err == sql.ErrNoRows {
+		r.logger.Warn(ctx, "Tag not found for rename",
+			"tag_id", id,
+		)
+		return fmt.Errorf("tag %w", ErrNotFound)
+	}
+	if err != nil {
+		if pgErr, ok := asPgError(err); ok && pgErr.Code == uniqueViolation {
+			r.logger.Warn(ctx, "Tag name already taken",
+				"tag_id", id,
+				"name", tag.Name,
+			)
+			return fmt.Errorf("tag already exists: %w", ErrConflict)
+		}
+		r.logger.Error(ctx, "Failed to rename tag in database",
+			"tag_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	tag.ID = id
+	return nil
+}
+
+func (r *tagRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM tags WHERE id = $1 AND tenant_id = $2`
+
+	r.logger.Info(ctx, "Deleting tag from database",
+		"tag_id", id,
+	)
+
+	result, err := r.db.ExecContext(ctx, query, id, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to delete tag from database",
+			"tag_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get rows affected",
+			"tag_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn(ctx, "Tag not found for deletion",
+			"tag_id", id,
+		)
+		return fmt.Errorf("tag %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *tagRepo) List(ctx context.Context) ([]*model.Tag, error) {
+	query := `
+		SELECT t.id, t.name, ` + tagSubscriptionsQuery + `, t.created_at
+		FROM tags t
+		WHERE t.tenant_id = $1
+		ORDER BY t.name
+	`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, ctxutil.TenantID(ctx))
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list tags from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Subscriptions, &tag.CreatedAt); err != nil {
+			r.logger.Error(ctx, "Failed to scan tag row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	r.queries.Observe("tags.list", len(tags), time.Since(start))
+
+	return tags, nil
+}
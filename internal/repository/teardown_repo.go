@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+type TeardownRepository interface {
+	// Teardown удаляет в одной транзакции данные организации tenantID (с userID - только
+	// данные пользователя) и возвращает число удаленных строк по таблицам. При dryRun
+	// транзакция откатывается
+	Teardown(ctx context.Context, tenantID string, userID *uuid.UUID, dryRun bool) (map[string]int64, error)
+}
+
+// teardownScope - подписки организации $1, с $2 - только подписки пользователя $2
+const teardownScope = `tenant_id = $1 AND ($2::uuid IS NULL OR user_id = $2)`
+
+// teardownStatements - удаление по таблицам в порядке зависимостей. Строки счетов удаляются
+// каскадно вместе со счетами, журналы изменений и аудита - после подписок, чтобы не оставить
+// записи об их удалении
+var teardownStatements = []struct {
+	table string
+	query string
+}{
+	{"invoices", `DELETE FROM invoices WHERE ` + teardownScope},
+	{"discounts", `
+		DELETE FROM discounts
+		WHERE tenant_id = $1 AND (
+			$2::uuid IS NULL OR user_id = $2
+			OR subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)
+		)
+	`},
+	{"subscription_pauses", `DELETE FROM subscription_pauses WHERE subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)`},
+	{"subscription_transfers", `DELETE FROM subscription_transfers WHERE subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)`},
+	{"subscription_tags", `DELETE FROM subscription_tags WHERE subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)`},
+	{"subscription_prices", `DELETE FROM subscription_prices WHERE subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)`},
+	{"subscription_reminder_settings", `DELETE FROM subscription_reminder_settings WHERE subscription_id IN (SELECT id FROM subscriptions WHERE ` + teardownScope + `)`},
+	{"subscriptions", `DELETE FROM subscriptions WHERE ` + teardownScope},
+	// Теги общие для пользователей организации и удаляются только вместе с ней
+	{"tags", `DELETE FROM tags WHERE tenant_id = $1 AND $2::uuid IS NULL`},
+	{"subscription_changes", `DELETE FROM subscription_changes WHERE tenant_id = $1 AND ($2::uuid IS NULL OR payload->>'user_id' = $2::text)`},
+	{"audit_log", `DELETE FROM audit_log WHERE ` + teardownScope},
+	{"rejected_requests", `DELETE FROM rejected_requests WHERE ` + teardownScope},
+	{"notification_preferences", `DELETE FROM notification_preferences WHERE ` + teardownScope},
+	{"api_tokens", `DELETE FROM api_tokens WHERE ` + teardownScope},
+	// Входящие события принадлежат организации и удаляются только вместе с ней
+	{"inbound_events", `DELETE FROM inbound_events WHERE tenant_id = $1 AND $2::uuid IS NULL`},
+}
+
+type teardownRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewTeardownRepository(db *sql.DB, logger *logger.Logger) TeardownRepository {
+	return &teardownRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *teardownRepo) Teardown(ctx context.Context, tenantID string, userID *uuid.UUID, dryRun bool) (map[string]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin teardown transaction",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleted := make(map[string]int64, len(teardownStatements))
+	for _, stmt := range teardownStatements {
+		result, err := tx.ExecContext(ctx, stmt.query, tenantID, userID)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to delete tenant data",
+				"tenant", tenantID,
+				"table", stmt.table,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to delete %s: %w", stmt.table, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deleted %s count: %w", stmt.table, err)
+		}
+		deleted[stmt.table] = rows
+	}
+
+	if dryRun {
+		return deleted, nil
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit teardown transaction",
+			"tenant", tenantID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
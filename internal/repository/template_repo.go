@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+type TemplateRepository interface {
+	// Create сохраняет новую версию шаблона и заполняет Version и CreatedAt
+	Create(ctx context.Context, tmpl *model.EmailTemplate) error
+	// GetLatest возвращает последнюю версию шаблона или nil, если версий нет
+	GetLatest(ctx context.Context, name string) (*model.EmailTemplate, error)
+	GetVersion(ctx context.Context, name string, version int) (*model.EmailTemplate, error)
+	ListVersions(ctx context.Context, name string) ([]*model.EmailTemplate, error)
+	// ListLatest возвращает последние версии всех шаблонов из базы
+	ListLatest(ctx context.Context) ([]*model.EmailTemplate, error)
+}
+
+type templateRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewTemplateRepository(db *sql.DB, logger *logger.Logger) TemplateRepository {
+	return &templateRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *templateRepo) Create(ctx context.Context, tmpl *model.EmailTemplate) error {
+	// Номер версии вычисляется в том же запросе; при гонке сработает UNIQUE (name, version)
+	query := `
+		INSERT INTO email_templates (name, version, subject, body)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+		FROM email_templates
+		WHERE name = $1
+		RETURNING version, created_at
+	`
+
+	r.logger.Info(ctx, "Saving email template version in database",
+		"name", tmpl.Name,
+	)
+
+	err := r.db.QueryRowContext(ctx, query, tmpl.Name, tmpl.Subject, tmpl.Body).Scan(&tmpl.Version, &tmpl.CreatedAt)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to save email template in database",
+			"name", tmpl.Name,
+			"error", err,
+		)
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+
+	tmpl.Source = model.TemplateSourceDatabase
+
+	r.logger.Info(ctx, "Email template saved successfully",
+		"name", tmpl.Name,
+		"version", tmpl.Version,
+	)
+	return nil
+}
+
+func (r *templateRepo) GetLatest(ctx context.Context, name string) (*model.EmailTemplate, error) {
+	query := `
+		SELECT name, version, subject, body, created_at
+		FROM email_templates
+		WHERE name = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	return r.getOne(ctx, query, name)
+}
+
+func (r *templateRepo) GetVersion(ctx context.Context, name string, version int) (*model.EmailTemplate, error) {
+	query := `
+		SELECT name, version, subject, body, created_at
+		FROM email_templates
+		WHERE name = $1 AND version = $2
+	`
+
+	return r.getOne(ctx, query, name, version)
+}
+
+func (r *templateRepo) ListVersions(ctx context.Context, name string) ([]*model.EmailTemplate, error) {
+	query := `
+		SELECT name, version, subject, body, created_at
+		FROM email_templates
+		WHERE name = $1
+		ORDER BY version DESC
+	`
+
+	return r.list(ctx, query, name)
+}
+
+func (r *templateRepo) ListLatest(ctx context.Context) ([]*model.EmailTemplate, error) {
+	query := `
+		SELECT DISTINCT ON (name) name, version, subject, body, created_at
+		FROM email_templates
+		ORDER BY name, version DESC
+	`
+
+	return r.list(ctx, query)
+}
+
+func (r *templateRepo) getOne(ctx context.Context, query string, args ...interface{}) (*model.EmailTemplate, error) {
+	var tmpl model.EmailTemplate
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&tmpl.Name,
+		&tmpl.Version,
+		&tmpl.Subject,
+		&tmpl.Body,
+		&tmpl.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get email template from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	tmpl.Source = model.TemplateSourceDatabase
+	return &tmpl, nil
+}
+
+func (r *templateRepo) list(ctx context.Context, query string, args ...interface{}) ([]*model.EmailTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to list email templates from database",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*model.EmailTemplate
+	for rows.Next() {
+		var tmpl model.EmailTemplate
+		err := rows.Scan(
+			&tmpl.Name,
+			&tmpl.Version,
+			&tmpl.Subject,
+			&tmpl.Body,
+			&tmpl.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "Failed to scan email template row",
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		tmpl.Source = model.TemplateSourceDatabase
+		templates = append(templates, &tmpl)
+	}
+
+	return templates, nil
+}
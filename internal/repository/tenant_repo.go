@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// ProvisionToken - личный токен для TenantRepository.Provision с хешем его секрета
+type ProvisionToken struct {
+	Token model.IssuedAPIToken
+	Hash  string
+}
+
+type TenantRepository interface {
+	// Provision в одной транзакции заводит организацию tenantID с названием name или
+	// обновляет его, сохраняет налоговые настройки settings (nil - не менять), создает и
+	// обновляет сервисы каталога, создает недостающие теги и токены, которых у
+	// пользователя нет под тем же названием. Возвращает только изменения
+	Provision(ctx context.Context, tenantID, name string, settings *model.TenantSettings, services []*model.CatalogService, tags []string, tokens []ProvisionToken) (*model.ProvisionTenantResult, error)
+	// Settings возвращает налоговые настройки организации; nil - организация не заведена
+	Settings(ctx context.Context, tenantID string) (*model.TenantSettings, error)
+}
+
+type tenantRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewTenantRepository(db *sql.DB, logger *logger.Logger) TenantRepository {
+	return &tenantRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *tenantRepo) Provision(ctx context.Context, tenantID, name string, settings *model.TenantSettings, services []*model.CatalogService, tags []string, tokens []ProvisionToken) (*model.ProvisionTenantResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error(ctx, "Failed to begin provisioning transaction",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Одновременные запросы для одной организации выполняются по очереди, иначе оба
+	// выпустили бы токен с одним названием
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('tenants'), hashtext($1))`, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to lock tenant: %w", err)
+	}
+
+	result := &model.ProvisionTenantResult{
+		Tenant:          tenantID,
+		Name:            name,
+		CreatedServices: []string{},
+		UpdatedServices: []string{},
+		CreatedTags:     []string{},
+		IssuedTokens:    []model.IssuedAPIToken{},
+	}
+
+	// Строка без изменений не возвращается; xmax = 0 у вставленной строки
+	var inserted bool
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenants (id, name) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = NOW()
+		WHERE tenants.name IS DISTINCT FROM EXCLUDED.name
+		RETURNING xmax = 0
+	`, tenantID, name).Scan(&inserted)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, r.fail(ctx, tenantID, "tenant", err)
+	}
+	result.Created = inserted
+
+	if settings != nil {
+		updated, err := tx.ExecContext(ctx, `
+			UPDATE tenants SET tax_rate_percent = $2::numeric, rounding_mode = $3, updated_at = NOW()
+			WHERE id = $1 AND (tax_rate_percent, rounding_mode) IS DISTINCT FROM ($2::numeric, $3)
+		`, tenantID, settings.TaxRatePercent, settings.Rounding)
+		if err != nil {
+			return nil, r.fail(ctx, tenantID, "settings", err)
+		}
+		affected, err := updated.RowsAffected()
+		if err != nil {
+			return nil, r.fail(ctx, tenantID, "settings", err)
+		}
+		result.UpdatedSettings = affected > 0
+	}
+
+	for _, service := range services {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO services (tenant_id, name, category, default_monthly_cost, icon_url)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id, lower(name)) DO UPDATE SET
+				category = EXCLUDED.category,
+				default_monthly_cost = EXCLUDED.default_monthly_cost,
+				icon_url = EXCLUDED.icon_url,
+				updated_at = NOW()
+			WHERE (services.category, services.default_monthly_cost, services.icon_url)
+				IS DISTINCT FROM (EXCLUDED.category, EXCLUDED.default_monthly_cost, EXCLUDED.icon_url)
+			RETURNING xmax = 0
+		`, tenantID, service.Name, service.Category, service.DefaultMonthlyCost, service.IconURL).Scan(&inserted)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return nil, r.fail(ctx, tenantID, "services", err)
+		case inserted:
+			result.CreatedServices = append(result.CreatedServices, service.Name)
+		default:
+			result.UpdatedServices = append(result.UpdatedServices, service.Name)
+		}
+	}
+
+	for _, tag := range tags {
+		var created bool
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO tags (tenant_id, name) VALUES ($1, $2)
+			ON CONFLICT (tenant_id, name) DO NOTHING
+			RETURNING true
+		`, tenantID, tag).Scan(&created)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return nil, r.fail(ctx, tenantID, "tags", err)
+		default:
+			result.CreatedTags = append(result.CreatedTags, tag)
+		}
+	}
+
+	for _, token := range tokens {
+		issued := token.Token
+		issued.Tenant = tenantID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO api_tokens (id, tenant_id, user_id, name, token_hash, scopes, expires_at)
+			SELECT $1::uuid, $2, $3::uuid, $4, $5, $6::text[], $7::timestamptz
+			WHERE NOT EXISTS (
+				SELECT 1 FROM api_tokens WHERE tenant_id = $2 AND user_id = $3 AND name = $4
+			)
+			RETURNING created_at
+		`,
+			issued.ID,
+			tenantID,
+			issued.UserID,
+			issued.Name,
+			token.Hash,
+			issued.Scopes,
+			issued.ExpiresAt,
+		).Scan(&issued.CreatedAt)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return nil, r.fail(ctx, tenantID, "api_tokens", err)
+		default:
+			result.IssuedTokens = append(result.IssuedTokens, issued)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error(ctx, "Failed to commit provisioning transaction",
+			"tenant", tenantID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+func (r *tenantRepo) Settings(ctx context.Context, tenantID string) (*model.TenantSettings, error) {
+	var settings model.TenantSettings
+	err := r.db.QueryRowContext(ctx, `
+		SELECT tax_rate_percent::text, rounding_mode FROM tenants WHERE id = $1
+	`, tenantID).Scan(&settings.TaxRatePercent, &settings.Rounding)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error(ctx, "Failed to get tenant settings",
+			"tenant", tenantID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get tenant settings: %w", err)
+	}
+	return &settings, nil
+}
+
+func (r *tenantRepo) fail(ctx context.Context, tenantID, table string, err error) error {
+	r.logger.Error(ctx, "Failed to provision tenant",
+		"tenant", tenantID,
+		"table", table,
+		"error", err,
+	)
+	return fmt.Errorf("failed to provision %s: %w", table, err)
+}
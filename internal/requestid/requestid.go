@@ -0,0 +1,18 @@
+// Package requestid проверяет идентификатор HTTP-запроса; в контексте его хранит ctxutil.
+// Идентификатор возвращается клиенту в заголовке X-Request-ID и попадает в логи и журнал
+// аудита, связывая изменения данных с запросом, который их сделал
+package requestid
+
+import "regexp"
+
+// Header - заголовок с идентификатором запроса
+const Header = "X-Request-ID"
+
+// pattern ограничивает идентификатор от клиента: он попадает в логи и журнал аудита
+var pattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Valid проверяет идентификатор от клиента: латинские буквы, цифры, ".", "_", ":"
+// и "-", не длиннее 128 символов
+func Valid(id string) bool {
+	return pattern.MatchString(id)
+}
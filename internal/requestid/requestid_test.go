@@ -0,0 +1,26 @@
+package requestid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"6f1f4a36-8b8e-4f7e-9f55-2a4f0b1b7c11", true},
+		{"gateway:abc.123_x", true},
+		{"", false},
+		{"has space", false},
+		{"line\nbreak", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule вычисляет время следующего запуска задачи
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Воскресенье можно указать как 0 или 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Parse разбирает cron-выражение из пяти полей (минута, час, день месяца, месяц,
+// день недели), дескрипторы @daily/@weekly/... и интервалы вида "@every 15m".
+// Время вычисляется в UTC
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("invalid interval in %q: must be a duration of at least 1s", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields", spec)
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[2] == "*" || fields[2] == "?"
+	s.dowAny = fields[4] == "*" || fields[4] == "?"
+
+	return s, nil
+}
+
+// parseField разбирает список через запятую из "*", "a", "a-b" с необязательным шагом "/n"
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepExpr)
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			step = parsed
+		}
+
+		var low, high int
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			low, high = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highExpr); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			low, high = value, value
+			// "5/15" означает "с 5 до конца диапазона с шагом 15"
+			if hasStep {
+				high = f.max
+			}
+		}
+
+		if low > high {
+			return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(expr string) (int, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value in %s field: %q (allowed %d-%d)", f.name, expr, f.min, f.max)
+	}
+	return v, nil
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Как в cron: если оба поля дней заданы явно, достаточно совпадения любого из них
+	domAny, dowAny bool
+}
+
+// Ограничение поиска защищает от выражений, которые никогда не срабатывают (например, 30 февраля)
+const maxSearchYears = 5
+
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
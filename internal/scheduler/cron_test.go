@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Четверг, 10 июля 2025, 09:30:15 UTC
+	now := time.Date(2025, time.July, 10, 9, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.July, 10, 9, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.July, 10, 9, 45, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2025, time.July, 11, 9, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2025, time.July, 11, 9, 30, 0, 0, time.UTC)},
+		{"0 8-10 * * *", time.Date(2025, time.July, 10, 10, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon", time.Date(2025, time.July, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.July, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2025, time.July, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Оба поля дней заданы: срабатывает по любому из них
+		{"0 0 15 * fri", time.Date(2025, time.July, 11, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, time.July, 10, 9, 45, 0, 0, time.UTC)},
+		{"0 12 * jan,dec *", time.Date(2025, time.December, 1, 12, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, time.July, 13, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", now.Add(90 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := schedule.Next(now); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNeverFires(t *testing.T) {
+	schedule, err := Parse("0 0 30 feb *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time", got)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 10ms",
+		"@every soon",
+		"@sometimes",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+}
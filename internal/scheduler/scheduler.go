@@ -0,0 +1,211 @@
+// Package scheduler запускает фоновые задачи по cron-выражениям и хранит
+// состояние последних запусков для административного API.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// Job - описание фоновой задачи
+type Job struct {
+	Name     string
+	Schedule string
+	Enabled  bool
+	// Jitter - максимальная случайная задержка запуска, чтобы реплики не стартовали одновременно
+	Jitter time.Duration
+	// Exclusive - задача выполняется только на реплике, взявшей блокировку Locker;
+	// остальные реплики пропускают запуск
+	Exclusive bool
+	Run       func(ctx context.Context) error
+}
+
+// Locker не дает выполнять задачу одновременно на нескольких репликах
+type Locker interface {
+	// TryLock берет блокировку name без ожидания; false - блокировку держит другая реплика
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+type jobState struct {
+	job      Job
+	schedule Schedule
+	status   model.JobStatus
+}
+
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   map[string]*jobState
+	locker Locker
+	logger *logger.Logger
+}
+
+func New(logger *logger.Logger) *Scheduler {
+	return &Scheduler{
+		jobs:   make(map[string]*jobState),
+		logger: logger,
+	}
+}
+
+// Register добавляет задачу. Ошибка в расписании возвращается сразу, чтобы сервис
+// не стартовал с неверной конфигурацией
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", job.Name, err)
+	}
+	if job.Jitter < 0 {
+		return fmt.Errorf("invalid jitter for job %s: must not be negative", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+
+	s.jobs[job.Name] = &jobState{
+		job:      job,
+		schedule: schedule,
+		status: model.JobStatus{
+			Name:     job.Name,
+			Schedule: job.Schedule,
+			Enabled:  job.Enabled,
+		},
+	}
+	return nil
+}
+
+// SetLocker задает блокировку задач Exclusive. Без нее такие задачи выполняются на
+// каждой реплике, как при одной реплике
+func (s *Scheduler) SetLocker(locker Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker = locker
+}
+
+// Start запускает включенные задачи до отмены контекста
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.jobs {
+		if !state.job.Enabled {
+			s.logger.Info(ctx, "Background job disabled", "job", state.job.Name)
+			continue
+		}
+		go s.loop(ctx, state)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	for {
+		next := state.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn(ctx, "Background job schedule never fires", "job", state.job.Name)
+			return
+		}
+		if state.job.Jitter > 0 {
+			next = next.Add(rand.N(state.job.Jitter))
+		}
+
+		s.mu.Lock()
+		state.status.NextRunAt = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, state)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, state *jobState) {
+	start := time.Now()
+
+	s.mu.Lock()
+	locker := s.locker
+	state.status.NextRunAt = nil
+	s.mu.Unlock()
+
+	var err error
+	if state.job.Exclusive && locker != nil {
+		unlock, ok, lockErr := locker.TryLock(ctx, "job:"+state.job.Name)
+		switch {
+		case lockErr != nil:
+			err = lockErr
+		case !ok:
+			s.mu.Lock()
+			state.status.Skipped++
+			s.mu.Unlock()
+			s.logger.Debug(ctx, "Background job skipped, running on another replica", "job", state.job.Name)
+			return
+		default:
+			defer unlock()
+		}
+	}
+
+	s.mu.Lock()
+	state.status.Running = true
+	s.mu.Unlock()
+
+	if err == nil {
+		s.logger.Debug(ctx, "Background job started", "job", state.job.Name)
+		err = state.job.Run(ctx)
+	}
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.status.Running = false
+	state.status.Runs++
+	state.status.LastRunAt = &start
+	state.status.LastDurationMs = duration.Milliseconds()
+	if err != nil {
+		state.status.Failures++
+		state.status.ConsecutiveFailures++
+		state.status.LastError = err.Error()
+		s.logger.Error(ctx, "Background job failed",
+			"job", state.job.Name,
+			"consecutive_failures", state.status.ConsecutiveFailures,
+			"error", err,
+		)
+		return
+	}
+
+	state.status.ConsecutiveFailures = 0
+	state.status.LastError = ""
+	state.status.LastSuccessAt = &start
+	s.logger.Debug(ctx, "Background job finished",
+		"job", state.job.Name,
+		"duration_ms", duration.Milliseconds(),
+	)
+}
+
+// Status возвращает состояние всех задач, отсортированное по имени
+func (s *Scheduler) Status() []model.JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]model.JobStatus, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		statuses = append(statuses, state.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
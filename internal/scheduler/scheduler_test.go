@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+)
+
+type lockerStub struct {
+	held     bool
+	unlocked int
+}
+
+func (l *lockerStub) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if l.held {
+		return nil, false, nil
+	}
+	return func() { l.unlocked++ }, true, nil
+}
+
+func TestExclusiveJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		held     bool
+		wantRuns int
+	}{
+		{name: "lock acquired", held: false, wantRuns: 1},
+		{name: "lock held by another replica", held: true, wantRuns: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			s := New(logger.New(slog.LevelError + 4))
+			locker := &lockerStub{held: tt.held}
+			s.SetLocker(locker)
+			if err := s.Register(Job{Name: "reminders", Schedule: "@daily", Enabled: true, Exclusive: true, Run: func(context.Context) error {
+				runs++
+				return nil
+			}}); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+
+			s.run(context.Background(), s.jobs["reminders"])
+
+			status := s.Status()[0]
+			if runs != tt.wantRuns || int(status.Runs) != tt.wantRuns || locker.unlocked != tt.wantRuns {
+				t.Errorf("runs = %d, status runs = %d, unlocked = %d, want %d", runs, status.Runs, locker.unlocked, tt.wantRuns)
+			}
+			if tt.held && status.Skipped != 1 {
+				t.Errorf("skipped = %d, want 1", status.Skipped)
+			}
+		})
+	}
+}
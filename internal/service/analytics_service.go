@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// Допустимые интервалы группировки активности
+var activityBuckets = map[string]struct{}{
+	"day":   {},
+	"week":  {},
+	"month": {},
+}
+
+// IsValidActivityBucket проверяет, поддерживается ли интервал группировки
+func IsValidActivityBucket(bucket string) bool {
+	_, ok := activityBuckets[bucket]
+	return ok
+}
+
+type AnalyticsService interface {
+	Activity(ctx context.Context, filter model.ActivityFilter) ([]model.ActivityBucket, error)
+	// Retention возвращает таблицу удержания пользователей по когортам за последние months
+	// месяцев, включая текущий; пустой serviceName - подписки на любые сервисы
+	Retention(ctx context.Context, serviceName string, months int) (*model.RetentionReport, error)
+}
+
+type analyticsService struct {
+	repo   repository.AnalyticsRepository
+	logger *logger.Logger
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, logger *logger.Logger) AnalyticsService {
+	return &analyticsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *analyticsService) Activity(ctx context.Context, filter model.ActivityFilter) ([]model.ActivityBucket, error) {
+	s.logger.Debug(ctx, "Getting subscription activity",
+		"from", filter.From,
+		"to", filter.To,
+		"bucket", filter.Bucket,
+	)
+
+	if !IsValidActivityBucket(filter.Bucket) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid bucket %q, expected day, week or month", filter.Bucket)
+	}
+	if filter.To.Before(filter.From) {
+		return nil, model.Invalid(model.ErrInvalidInput, "to cannot be before from")
+	}
+
+	buckets, err := s.repo.Activity(ctx, filter)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get subscription activity from repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	return buckets, nil
+}
+
+func (s *analyticsService) Retention(ctx context.Context, serviceName string, months int) (*model.RetentionReport, error) {
+	s.logger.Debug(ctx, "Getting retention cohorts",
+		"service_name", serviceName,
+		"months", months,
+	)
+
+	if months < 1 {
+		return nil, model.Invalid(model.ErrInvalidInput, "months must be positive")
+	}
+	to := model.CurrentMonth()
+	from := to.AddDate(0, -(months - 1), 0)
+
+	cells, err := s.repo.Retention(ctx, model.RetentionFilter{ServiceName: serviceName, From: from, To: to})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get retention cohorts from repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get retention: %w", err)
+	}
+
+	return buildRetention(serviceName, cells, from, to), nil
+}
+
+// buildRetention собирает таблицу удержания: строка на каждый месяц с from по to, в строке
+// значения для каждого прошедшего с когорты месяца, включая месяцы без сохраненных пользователей
+func buildRetention(serviceName string, cells []model.RetentionCell, from, to time.Time) *model.RetentionReport {
+	report := &model.RetentionReport{ServiceName: serviceName, Cohorts: []model.RetentionCohort{}}
+	index := make(map[time.Time]int)
+	for cohort := from; !cohort.After(to); cohort = cohort.AddDate(0, 1, 0) {
+		elapsed := (to.Year()-cohort.Year())*12 + int(to.Month()-cohort.Month()) + 1
+		index[cohort] = len(report.Cohorts)
+		report.Cohorts = append(report.Cohorts, model.RetentionCohort{
+			Cohort:   cohort.Format("01-2006"),
+			Retained: make([]int, elapsed),
+			Rates:    make([]float64, elapsed),
+		})
+	}
+
+	for _, cell := range cells {
+		i, ok := index[cell.Cohort]
+		if !ok {
+			continue
+		}
+		cohort := &report.Cohorts[i]
+		if cell.Offset < 0 || cell.Offset >= len(cohort.Retained) {
+			continue
+		}
+		cohort.Users = cell.Users
+		cohort.Retained[cell.Offset] = cell.Retained
+		// Доля с точностью до сотой процента
+		cohort.Rates[cell.Offset] = math.Round(float64(cell.Retained)/float64(cell.Users)*10000) / 10000
+	}
+	return report
+}
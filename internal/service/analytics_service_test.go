@@ -0,0 +1,31 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+func TestBuildRetention(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC) }
+	cells := []model.RetentionCell{
+		{Cohort: month(time.January), Users: 4, Offset: 0, Retained: 4},
+		{Cohort: month(time.January), Users: 4, Offset: 2, Retained: 1},
+		{Cohort: month(time.March), Users: 3, Offset: 0, Retained: 3},
+	}
+
+	report := buildRetention("Netflix", cells, month(time.January), month(time.March))
+
+	want := []model.RetentionCohort{
+		// Через месяц подписки не было ни у кого, через два вернулся один пользователь
+		{Cohort: "01-2025", Users: 4, Retained: []int{4, 0, 1}, Rates: []float64{1, 0, 0.25}},
+		// Когорта без пользователей остается в таблице
+		{Cohort: "02-2025", Retained: []int{0, 0}, Rates: []float64{0, 0}},
+		{Cohort: "03-2025", Users: 3, Retained: []int{3}, Rates: []float64{1}},
+	}
+	if report.ServiceName != "Netflix" || !reflect.DeepEqual(report.Cohorts, want) {
+		t.Errorf("buildRetention = %+v, want %+v", report.Cohorts, want)
+	}
+}
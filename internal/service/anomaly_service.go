@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type AnomalyService interface {
+	DetectAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]model.SpendAnomaly, error)
+	RunDetection(ctx context.Context) error
+}
+
+// AnomalyConfig - параметры поиска аномальных трат
+type AnomalyConfig struct {
+	// ThresholdPercent - превышение среднего, начиная с которого месяц считается аномальным
+	ThresholdPercent int
+	// LookbackMonths - количество предыдущих месяцев для расчета среднего
+	LookbackMonths int
+	// MinSpend - траты месяца, начиная с которых он считается аномальным при нулевом
+	// среднем (трат в предыдущих месяцах не было): процент отклонения от нуля не определен
+	MinSpend int
+}
+
+// EventSpendAnomaly - тип события об аномальных тратах пользователя
+const EventSpendAnomaly = "spend.anomaly"
+
+// EventPublisher рассылает события подписчикам, не блокируя вызывающего
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data interface{})
+}
+
+// AnomalyNotifier сообщает пользователю о найденной аномалии
+type AnomalyNotifier interface {
+	NotifyAnomaly(ctx context.Context, anomaly model.SpendAnomaly) error
+}
+
+type anomalyService struct {
+	repo   repository.SubscriptionRepository
+	cfg    AnomalyConfig
+	events EventPublisher
+	inbox  AnomalyNotifier
+	logger *logger.Logger
+}
+
+// NewAnomalyService создает сервис аномалий; events и inbox получают найденные фоновой
+// проверкой аномалии и могут быть nil
+func NewAnomalyService(repo repository.SubscriptionRepository, cfg AnomalyConfig, events EventPublisher, inbox AnomalyNotifier, logger *logger.Logger) AnomalyService {
+	if cfg.LookbackMonths < 1 {
+		cfg.LookbackMonths = 1
+	}
+
+	return &anomalyService{
+		repo:   repo,
+		cfg:    cfg,
+		events: events,
+		inbox:  inbox,
+		logger: logger,
+	}
+}
+
+// DetectAnomalies проверяет последние months месяцев (включая текущий) пользователя
+// и возвращает месяцы, в которых траты превысили среднее за предыдущие месяцы больше порога
+func (s *anomalyService) DetectAnomalies(ctx context.Context, userID uuid.UUID, months int) ([]model.SpendAnomaly, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Detecting spend anomalies",
+		"user_id", userID,
+		"months", months,
+	)
+
+	currentMonth := model.CurrentMonth()
+	from := currentMonth.AddDate(0, -(months - 1 + s.cfg.LookbackMonths), 0)
+
+	spend, err := s.repo.MonthlySpend(ctx, userID, from, currentMonth)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get monthly spend from repository",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get monthly spend: %w", err)
+	}
+
+	anomalies := []model.SpendAnomaly{}
+	for i := s.cfg.LookbackMonths; i < len(spend); i++ {
+		if anomaly, ok := s.evaluate(userID, spend[i], spend[i-s.cfg.LookbackMonths:i]); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	s.logger.Debug(ctx, "Spend anomalies detected",
+		"user_id", userID,
+		"count", len(anomalies),
+	)
+
+	return anomalies, nil
+}
+
+// RunDetection проверяет текущий месяц всех пользователей всех организаций и сообщает
+// о найденных аномалиях
+func (s *anomalyService) RunDetection(ctx context.Context) error {
+	s.logger.Info(ctx, "Running spend anomaly detection")
+
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list tenants for anomaly detection", "error", err)
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	users, flagged := 0, 0
+	for _, id := range tenants {
+		tenantCtx := ctxutil.WithTenantID(ctx, id)
+		userIDs, err := s.repo.ListUserIDs(tenantCtx)
+		if err != nil {
+			s.logger.Error(tenantCtx, "Failed to list users for anomaly detection", "tenant", id, "error", err)
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		users += len(userIDs)
+
+		for _, userID := range userIDs {
+			anomalies, err := s.DetectAnomalies(tenantCtx, userID, 1)
+			if err != nil {
+				// Ошибка по одному пользователю не должна останавливать проверку остальных
+				continue
+			}
+
+			for _, anomaly := range anomalies {
+				flagged++
+				s.notify(tenantCtx, anomaly)
+			}
+		}
+	}
+
+	s.logger.Info(ctx, "Spend anomaly detection finished",
+		"tenants", len(tenants),
+		"users", users,
+		"flagged", flagged,
+	)
+	return nil
+}
+
+func (s *anomalyService) evaluate(userID uuid.UUID, month model.MonthlySpend, previous []model.MonthlySpend) (model.SpendAnomaly, bool) {
+	total := 0
+	for _, m := range previous {
+		total += m.Total
+	}
+	average := total / len(previous)
+
+	// Без истории трат отклонение не определено: месяц сравнивается с MinSpend,
+	// а DeviationPercent остается 0
+	deviation := 0
+	switch {
+	case average == 0:
+		if month.Total <= 0 || month.Total < s.cfg.MinSpend {
+			return model.SpendAnomaly{}, false
+		}
+	default:
+		deviation = (month.Total - average) * 100 / average
+		if deviation <= s.cfg.ThresholdPercent {
+			return model.SpendAnomaly{}, false
+		}
+	}
+
+	return model.SpendAnomaly{
+		UserID:           userID,
+		Month:            month.Month,
+		Spend:            month.Total,
+		TrailingAverage:  average,
+		DeviationPercent: deviation,
+		ThresholdPercent: s.cfg.ThresholdPercent,
+	}, true
+}
+
+func (s *anomalyService) notify(ctx context.Context, anomaly model.SpendAnomaly) {
+	s.logger.Warn(ctx, "Spend anomaly detected",
+		"tenant", ctxutil.TenantID(ctx),
+		"user_id", anomaly.UserID,
+		"month", anomaly.Month.Format("01-2006"),
+		"spend", anomaly.Spend,
+		"trailing_average", anomaly.TrailingAverage,
+		"deviation_percent", anomaly.DeviationPercent,
+	)
+	if s.events != nil {
+		s.events.Publish(ctx, EventSpendAnomaly, anomaly)
+	}
+	if s.inbox != nil {
+		if err := s.inbox.NotifyAnomaly(ctx, anomaly); err != nil {
+			s.logger.Error(ctx, "Failed to add spend anomaly notification",
+				"user_id", anomaly.UserID,
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestAnomalyEvaluate(t *testing.T) {
+	svc := NewAnomalyService(nil, AnomalyConfig{ThresholdPercent: 50, LookbackMonths: 3, MinSpend: 1000}, nil, nil, logger.New(slog.LevelError+4)).(*anomalyService)
+	month := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	spend := func(totals ...int) []model.MonthlySpend {
+		months := make([]model.MonthlySpend, len(totals))
+		for i, total := range totals {
+			months[i] = model.MonthlySpend{Month: month.AddDate(0, i-len(totals), 0), Total: total}
+		}
+		return months
+	}
+
+	tests := []struct {
+		name          string
+		current       int
+		previous      []model.MonthlySpend
+		wantFlagged   bool
+		wantAverage   int
+		wantDeviation int
+	}{
+		{"above threshold", 2100, spend(1200, 1200, 1200), true, 1200, 75},
+		{"at threshold", 1800, spend(1200, 1200, 1200), false, 0, 0},
+		{"below average", 600, spend(1200, 1200, 1200), false, 0, 0},
+		{"uneven history", 3000, spend(0, 1200, 2400), true, 1200, 150},
+		{"zero baseline above minimum", 1500, spend(0, 0, 0), true, 0, 0},
+		{"zero baseline at minimum", 1000, spend(0, 0, 0), true, 0, 0},
+		{"zero baseline below minimum", 999, spend(0, 0, 0), false, 0, 0},
+		{"zero baseline without spend", 0, spend(0, 0, 0), false, 0, 0},
+		// Среднее округляется вниз: 2 ₽ за три месяца - нулевая база
+		{"baseline rounded to zero", 1200, spend(0, 0, 2), true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			anomaly, flagged := svc.evaluate(userID, model.MonthlySpend{Month: month, Total: tt.current}, tt.previous)
+			if flagged != tt.wantFlagged {
+				t.Fatalf("evaluate() flagged = %t, want %t (%+v)", flagged, tt.wantFlagged, anomaly)
+			}
+			if !flagged {
+				return
+			}
+			if anomaly.UserID != userID || !anomaly.Month.Equal(month) || anomaly.Spend != tt.current || anomaly.ThresholdPercent != 50 {
+				t.Errorf("evaluate() = %+v, want month %s spend %d of user %s", anomaly, month, tt.current, userID)
+			}
+			if anomaly.TrailingAverage != tt.wantAverage || anomaly.DeviationPercent != tt.wantDeviation {
+				t.Errorf("average = %d, deviation = %d, want %d, %d", anomaly.TrailingAverage, anomaly.DeviationPercent, tt.wantAverage, tt.wantDeviation)
+			}
+		})
+	}
+}
+
+// TestDetectAnomaliesScopedToCaller проверяет, что пользователь без прав администратора
+// не видит траты другого пользователя: запрос отклоняется до обращения к базе
+func TestDetectAnomaliesScopedToCaller(t *testing.T) {
+	svc := NewAnomalyService(nil, AnomalyConfig{ThresholdPercent: 50, LookbackMonths: 3}, nil, nil, logger.New(slog.LevelError+4))
+
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: uuid.New()})
+	if _, err := svc.DetectAnomalies(ctx, uuid.New(), 6); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("DetectAnomalies() of other user error = %v, want forbidden", err)
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// apiTokenBytes - длина случайной части личного токена API
+const apiTokenBytes = 32
+
+type APITokenService interface {
+	// IssueToken выпускает личный токен пользователя; секрет возвращается только здесь
+	IssueToken(ctx context.Context, userID uuid.UUID, req model.CreateAPITokenRequest) (*model.IssuedAPIToken, error)
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error)
+	// RevokeToken удаляет токен: запросы с ним перестают проходить аутентификацию
+	RevokeToken(ctx context.Context, userID, id uuid.UUID) error
+
+	// Authenticate возвращает пользователя запроса с личным токеном token.
+	// Неизвестный или просроченный токен - auth.ErrInvalidToken
+	Authenticate(ctx context.Context, token string) (auth.Caller, error)
+}
+
+type apiTokenService struct {
+	repo   repository.APITokenRepository
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+func NewAPITokenService(repo repository.APITokenRepository, logger *logger.Logger) APITokenService {
+	return &apiTokenService{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *apiTokenService) IssueToken(ctx context.Context, userID uuid.UUID, req model.CreateAPITokenRequest) (*model.IssuedAPIToken, error) {
+	if err := s.authorize(ctx, userID, "issuing API tokens"); err != nil {
+		return nil, err
+	}
+
+	issued, err := newAPIToken(userID, req, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, &issued.APIToken, hashAPIToken(issued.Token)); err != nil {
+		return nil, fmt.Errorf("failed to issue API token: %w", err)
+	}
+
+	s.logger.Info(ctx, "API token issued",
+		"user_id", userID,
+		"token_id", issued.ID,
+		"scopes", issued.Scopes,
+	)
+	return issued, nil
+}
+
+func (s *apiTokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error) {
+	if err := s.authorize(ctx, userID, "listing API tokens"); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *apiTokenService) RevokeToken(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.authorize(ctx, userID, "revoking API tokens"); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	if !deleted {
+		return ErrAPITokenNotFound
+	}
+
+	s.logger.Info(ctx, "API token revoked",
+		"user_id", userID,
+		"token_id", id,
+	)
+	return nil
+}
+
+func (s *apiTokenService) Authenticate(ctx context.Context, token string) (auth.Caller, error) {
+	found, err := s.repo.FindByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		return auth.Caller{}, fmt.Errorf("failed to find API token: %w", err)
+	}
+	if found == nil {
+		return auth.Caller{}, fmt.Errorf("%w: unknown API token", auth.ErrInvalidToken)
+	}
+	if found.Expired(s.now()) {
+		return auth.Caller{}, fmt.Errorf("%w: API token expired", auth.ErrInvalidToken)
+	}
+
+	return auth.Caller{
+		UserID: found.UserID,
+		Tenant: found.Tenant,
+		Scopes: found.Scopes,
+	}, nil
+}
+
+// authorize проверяет, что пользователь запроса управляет токенами пользователя userID.
+// Токенами управляет только аутентифицированный пользователь: без OIDC любой клиент мог бы
+// выпустить токен от имени любого пользователя. Запросы с личным токеном токенами не
+// управляют: токен не может выпустить себе права шире собственных
+func (s *apiTokenService) authorize(ctx context.Context, userID uuid.UUID, action string) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return fmt.Errorf("%w: %s requires authentication", auth.ErrForbidden, action)
+	}
+	if caller.Scopes != nil {
+		return fmt.Errorf("%w: %s requires signing in without a personal API token", auth.ErrForbidden, action)
+	}
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newAPIToken собирает токен пользователя userID по запросу req и генерирует его секрет.
+// Срок действия отсчитывается от now
+func newAPIToken(userID uuid.UUID, req model.CreateAPITokenRequest, now time.Time) (*model.IssuedAPIToken, error) {
+	secret := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	token := model.APIToken{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+		Scopes: slices.Compact(scopes),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	return &model.IssuedAPIToken{
+		APIToken: token,
+		Token:    model.APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret),
+	}, nil
+}
+
+// hashAPIToken возвращает хеш токена, под которым он хранится в базе
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// apiTokenRepoStub хранит токены в памяти по хешу секрета, как таблица api_tokens
+type apiTokenRepoStub struct {
+	tokens map[string]model.APIToken
+}
+
+func (r *apiTokenRepoStub) Create(ctx context.Context, token *model.APIToken, hash string) error {
+	token.Tenant = "default"
+	token.CreatedAt = time.Now()
+	r.tokens[hash] = *token
+	return nil
+}
+
+func (r *apiTokenRepoStub) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *apiTokenRepoStub) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	for hash, token := range r.tokens {
+		if token.UserID == userID && token.ID == id {
+			delete(r.tokens, hash)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *apiTokenRepoStub) FindByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func TestAPITokenLifecycle(t *testing.T) {
+	repo := &apiTokenRepoStub{tokens: map[string]model.APIToken{}}
+	svc := NewAPITokenService(repo, logger.New(slog.LevelError+4))
+	userID := uuid.New()
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: userID})
+
+	issued, err := svc.IssueToken(ctx, userID, model.CreateAPITokenRequest{
+		Name:   " Google Sheets ",
+		Scopes: []string{model.ScopeReadSummary, model.ScopeReadSubscriptions, model.ScopeReadSummary},
+	})
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if !strings.HasPrefix(issued.Token, model.APITokenPrefix) || issued.Name != "Google Sheets" {
+		t.Errorf("IssueToken() = %q named %q, want %s prefix and trimmed name", issued.Token, issued.Name, model.APITokenPrefix)
+	}
+	if want := []string{model.ScopeReadSubscriptions, model.ScopeReadSummary}; !slices.Equal(issued.Scopes, want) {
+		t.Errorf("scopes = %v, want %v", issued.Scopes, want)
+	}
+	// Секрет не хранится: в репозитории только его хеш
+	for hash := range repo.tokens {
+		if strings.Contains(hash, issued.Token) {
+			t.Error("repository stores the token secret")
+		}
+	}
+
+	caller, err := svc.Authenticate(context.Background(), issued.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if caller.UserID != userID || caller.Admin || caller.HasScope(model.ScopeWriteSubscriptions) || !caller.HasScope(model.ScopeReadSummary) {
+		t.Errorf("Authenticate() = %+v, want read-only caller %s", caller, userID)
+	}
+
+	// Запрос с личным токеном не выпускает новые токены
+	tokenCtx := auth.WithCaller(context.Background(), caller)
+	req := model.CreateAPITokenRequest{Name: "escalation", Scopes: []string{model.ScopeWriteSubscriptions}}
+	if _, err := svc.IssueToken(tokenCtx, userID, req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("IssueToken() with API token error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.IssueToken(ctx, uuid.New(), req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("IssueToken() for other user error = %v, want ErrForbidden", err)
+	}
+	// Без аутентификации (OIDC не настроен) токены не выпускаются
+	if _, err := svc.IssueToken(context.Background(), userID, req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("IssueToken() without caller error = %v, want ErrForbidden", err)
+	}
+
+	if err := svc.RevokeToken(ctx, userID, issued.ID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), issued.Token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate() revoked error = %v, want ErrInvalidToken", err)
+	}
+	if err := svc.RevokeToken(ctx, userID, issued.ID); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("RevokeToken() twice error = %v, want ErrAPITokenNotFound", err)
+	}
+}
+
+func TestAPITokenExpiry(t *testing.T) {
+	repo := &apiTokenRepoStub{tokens: map[string]model.APIToken{}}
+	svc := NewAPITokenService(repo, logger.New(slog.LevelError+4)).(*apiTokenService)
+	issuedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return issuedAt }
+
+	days := 30
+	userID := uuid.New()
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: userID})
+	issued, err := svc.IssueToken(ctx, userID, model.CreateAPITokenRequest{
+		Name:          "dashboard",
+		Scopes:        []string{model.ScopeReadSummary},
+		ExpiresInDays: &days,
+	})
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	svc.now = func() time.Time { return issuedAt.AddDate(0, 0, days-1) }
+	if _, err := svc.Authenticate(context.Background(), issued.Token); err != nil {
+		t.Errorf("Authenticate() before expiry error = %v", err)
+	}
+	svc.now = func() time.Time { return issuedAt.AddDate(0, 0, days) }
+	if _, err := svc.Authenticate(context.Background(), issued.Token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate() after expiry error = %v, want ErrInvalidToken", err)
+	}
+}
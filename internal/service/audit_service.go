@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type AuditService interface {
+	// History возвращает изменения подписки, начиная с последних. Обычный пользователь
+	// видит только изменения, после которых подписка принадлежала ему
+	History(ctx context.Context, subscriptionID uuid.UUID, filter model.AuditFilter) ([]*model.AuditEntry, error)
+	// List возвращает журнал аудита организации; доступен только администратору
+	List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+type auditService struct {
+	repo   repository.AuditRepository
+	logger *logger.Logger
+}
+
+func NewAuditService(repo repository.AuditRepository, logger *logger.Logger) AuditService {
+	return &auditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *auditService) History(ctx context.Context, subscriptionID uuid.UUID, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	userID, err := auth.ScopeUserID(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+	filter.EntityID = &subscriptionID
+
+	return s.list(ctx, filter)
+}
+
+func (s *auditService) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	if err := auth.RequireAdmin(ctx, "audit log"); err != nil {
+		return nil, err
+	}
+	if filter.Action != "" && !model.IsValidAuditAction(filter.Action) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid action %q: expected create, update or delete", filter.Action)
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return nil, model.Invalid(model.ErrInvalidPeriod, "invalid period: until must be after since")
+	}
+
+	return s.list(ctx, filter)
+}
+
+func (s *auditService) list(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	if entries == nil {
+		entries = []*model.AuditEntry{}
+	}
+	return entries, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type auditRepoStub struct {
+	calls  int
+	filter model.AuditFilter
+}
+
+func (r *auditRepoStub) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	r.calls++
+	r.filter = filter
+	return nil, nil
+}
+
+func TestAuditHistory(t *testing.T) {
+	subscriptionID := uuid.New()
+	self := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested *uuid.UUID
+		wantUser  *uuid.UUID
+		wantErr   string
+	}{
+		{name: "anonymous", ctx: context.Background()},
+		{name: "user scoped to self", ctx: auth.WithCaller(context.Background(), auth.Caller{UserID: self}), wantUser: &self},
+		{name: "user requests other", ctx: auth.WithCaller(context.Background(), auth.Caller{UserID: self}), requested: &other, wantErr: "forbidden"},
+		{name: "admin", ctx: auth.WithCaller(context.Background(), auth.Caller{Admin: true}), requested: &other, wantUser: &other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &auditRepoStub{}
+			svc := NewAuditService(repo, logger.New(slog.LevelError+4))
+
+			entries, err := svc.History(tt.ctx, subscriptionID, model.AuditFilter{UserID: tt.requested, Limit: 10})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("History() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+
+			if entries == nil {
+				t.Error("History() returned nil instead of empty list")
+			}
+			if repo.filter.EntityID == nil || *repo.filter.EntityID != subscriptionID {
+				t.Errorf("repository entity = %v, want %s", repo.filter.EntityID, subscriptionID)
+			}
+			if got := repo.filter.UserID; (got == nil) != (tt.wantUser == nil) || (got != nil && *got != *tt.wantUser) {
+				t.Errorf("repository user = %v, want %v", got, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestAuditList(t *testing.T) {
+	since := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		filter  model.AuditFilter
+		wantErr string
+	}{
+		{name: "admin", ctx: auth.WithCaller(context.Background(), auth.Caller{Admin: true}), filter: model.AuditFilter{Action: model.AuditDelete}},
+		{name: "user", ctx: auth.WithCaller(context.Background(), auth.Caller{UserID: uuid.New()}), wantErr: "forbidden"},
+		{name: "invalid action", ctx: context.Background(), filter: model.AuditFilter{Action: "purge"}, wantErr: "invalid action"},
+		{name: "invalid period", ctx: context.Background(), filter: model.AuditFilter{Since: &since, Until: &until}, wantErr: "invalid period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &auditRepoStub{}
+			svc := NewAuditService(repo, logger.New(slog.LevelError+4))
+
+			_, err := svc.List(tt.ctx, tt.filter)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("List() error = %v, want %q", err, tt.wantErr)
+				}
+				if repo.calls != 0 {
+					t.Errorf("repository called %d times after rejected query", repo.calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if repo.filter.Action != tt.filter.Action {
+				t.Errorf("repository action = %q, want %q", repo.filter.Action, tt.filter.Action)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/backup"
+	"github.com/Zipklas/subscription-service/internal/blobstore"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type BackupService interface {
+	// Run снимает логическую копию таблиц сервиса, шифрует и загружает ее в хранилище
+	// файлов, а затем удаляет копии с истекшим сроком хранения. Неудачная копия
+	// записывается в журнал со статусом failed
+	Run(ctx context.Context) (*model.Backup, error)
+	// List возвращает последние limit копий, начиная с новых
+	List(ctx context.Context, limit int) ([]*model.Backup, error)
+	// Get возвращает копию по ID
+	Get(ctx context.Context, id uuid.UUID) (*model.Backup, error)
+	// PurgeExpired удаляет из хранилища копии с истекшим сроком хранения и возвращает их число
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+// BackupConfig - параметры резервных копий
+type BackupConfig struct {
+	// Tables - таблицы, которые попадают в копию
+	Tables []string
+	// Key - ключ шифрования AES-256
+	Key []byte
+	// Prefix - префикс ключей копий в хранилище файлов
+	Prefix string
+	// Retention - срок хранения копии; 0 - копии не удаляются
+	Retention time.Duration
+}
+
+type backupService struct {
+	repo   repository.BackupRepository
+	store  blobstore.Store
+	config BackupConfig
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewBackupService создает сервис резервных копий. Без store (сервер API) доступен
+// только журнал копий
+func NewBackupService(repo repository.BackupRepository, store blobstore.Store, config BackupConfig, logger *logger.Logger) BackupService {
+	return &backupService{
+		repo:   repo,
+		store:  store,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *backupService) Run(ctx context.Context) (*model.Backup, error) {
+	if s.store == nil || len(s.config.Key) == 0 {
+		return nil, errors.New("backups are not configured: BACKUP_ENCRYPTION_KEY is required")
+	}
+
+	id := uuid.New()
+	record := &model.Backup{
+		ID:     id,
+		Key:    path.Join(s.config.Prefix, s.now().UTC().Format("2006/01/02"), id.String()+".ndjson.gz.enc"),
+		Status: model.BackupRunning,
+	}
+	if err := s.repo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	s.logger.Info(ctx, "Starting backup",
+		"backup_id", record.ID,
+		"key", record.Key,
+		"tables", len(s.config.Tables),
+	)
+
+	if err := s.write(ctx, record); err != nil {
+		s.logger.Error(ctx, "Backup failed",
+			"backup_id", record.ID,
+			"error", err,
+		)
+		message := err.Error()
+		record.Status = model.BackupFailed
+		record.Error = &message
+		// Копия уже не удалась; запись о ней сохраняется и при отмененном ctx
+		if finishErr := s.repo.Finish(context.WithoutCancel(ctx), record); finishErr != nil {
+			s.logger.Error(ctx, "Failed to record backup failure",
+				"backup_id", record.ID,
+				"error", finishErr,
+			)
+		}
+		return record, fmt.Errorf("backup failed: %w", err)
+	}
+
+	record.Status = model.BackupCompleted
+	if s.config.Retention > 0 {
+		expiresAt := record.StartedAt.Add(s.config.Retention)
+		record.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Finish(ctx, record); err != nil {
+		return nil, err
+	}
+	s.logger.Info(ctx, "Backup completed",
+		"backup_id", record.ID,
+		"size_bytes", *record.SizeBytes,
+		"wal_lsn", *record.WALPosition,
+	)
+
+	// Политика хранения применяется только после удачной копии, чтобы не остаться без копий
+	if _, err := s.PurgeExpired(ctx); err != nil {
+		s.logger.Warn(ctx, "Failed to purge expired backups", "error", err)
+	}
+	return record, nil
+}
+
+// write снимает копию во временный файл, загружает его в хранилище и заполняет
+// снимок, размер и контрольную сумму record. Размер нужен хранилищу заранее,
+// поэтому копия не загружается потоком
+func (s *backupService) write(ctx context.Context, record *model.Backup) error {
+	file, err := os.CreateTemp("", "backup-*.enc")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	encrypted, err := backup.NewWriter(io.MultiWriter(file, hash), s.config.Key)
+	if err != nil {
+		return err
+	}
+	compressed := gzip.NewWriter(encrypted)
+
+	snapshot, err := s.repo.Dump(ctx, s.config.Tables, compressed)
+	if err != nil {
+		return err
+	}
+	if err := compressed.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	if err := encrypted.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt backup: %w", err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.store.Put(ctx, record.Key, file, size, "application/octet-stream"); err != nil {
+		return fmt.Errorf("failed to upload backup: %w", err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	record.SnapshotAt = &snapshot.At
+	record.WALPosition = &snapshot.WALPosition
+	record.Tables = snapshot.Tables
+	record.SizeBytes = &size
+	record.SHA256 = &sum
+	return nil
+}
+
+func (s *backupService) List(ctx context.Context, limit int) ([]*model.Backup, error) {
+	backups, err := s.repo.List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return backups, nil
+}
+
+func (s *backupService) Get(ctx context.Context, id uuid.UUID) (*model.Backup, error) {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	if record == nil {
+		return nil, ErrBackupNotFound
+	}
+	return record, nil
+}
+
+func (s *backupService) PurgeExpired(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, errors.New("backups are not configured")
+	}
+	expired, err := s.repo.ListExpired(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, record := range expired {
+		if err := s.store.Delete(ctx, record.Key); err != nil {
+			return purged, fmt.Errorf("failed to delete backup %s: %w", record.ID, err)
+		}
+		if err := s.repo.MarkExpired(ctx, record.ID); err != nil {
+			return purged, err
+		}
+		s.logger.Info(ctx, "Expired backup deleted",
+			"backup_id", record.ID,
+			"key", record.Key,
+			"expires_at", record.ExpiresAt,
+		)
+		purged++
+	}
+	return purged, nil
+}
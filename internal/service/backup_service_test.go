@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/backup"
+	"github.com/Zipklas/subscription-service/internal/blobstore"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const backupDump = `{"table":"subscriptions","row":{"id":1}}` + "\n"
+
+// backupRepoStub хранит журнал копий в памяти и выгружает одну строку
+type backupRepoStub struct {
+	repository.BackupRepository
+	dumpErr error
+	backups map[uuid.UUID]*model.Backup
+	expired []uuid.UUID
+}
+
+func (r *backupRepoStub) Dump(ctx context.Context, tables []string, w io.Writer) (*model.BackupSnapshot, error) {
+	if r.dumpErr != nil {
+		return nil, r.dumpErr
+	}
+	if _, err := io.WriteString(w, backupDump); err != nil {
+		return nil, err
+	}
+	return &model.BackupSnapshot{At: time.Now(), WALPosition: "0/16B3748", Tables: map[string]int64{"subscriptions": 1}}, nil
+}
+
+func (r *backupRepoStub) Create(ctx context.Context, b *model.Backup) error {
+	b.StartedAt = time.Now()
+	r.backups[b.ID] = b
+	return nil
+}
+
+func (r *backupRepoStub) Finish(ctx context.Context, b *model.Backup) error {
+	finished := time.Now()
+	b.FinishedAt = &finished
+	return nil
+}
+
+func (r *backupRepoStub) ListExpired(ctx context.Context, now time.Time) ([]*model.Backup, error) {
+	var expired []*model.Backup
+	for _, b := range r.backups {
+		if b.Status == model.BackupCompleted && b.ExpiresAt != nil && !b.ExpiresAt.After(now) {
+			expired = append(expired, b)
+		}
+	}
+	return expired, nil
+}
+
+func (r *backupRepoStub) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	r.backups[id].Status = model.BackupExpired
+	r.expired = append(r.expired, id)
+	return nil
+}
+
+func newBackupTestService(t *testing.T, repo *backupRepoStub) (*backupService, blobstore.Store, []byte) {
+	t.Helper()
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{7}, backup.KeySize)
+	svc := NewBackupService(repo, store, BackupConfig{
+		Tables:    []string{"subscriptions"},
+		Key:       key,
+		Prefix:    "backups",
+		Retention: 24 * time.Hour,
+	}, logger.New(slog.LevelError+4)).(*backupService)
+	return svc, store, key
+}
+
+func TestRunBackup(t *testing.T) {
+	repo := &backupRepoStub{backups: map[uuid.UUID]*model.Backup{}}
+	svc, store, key := newBackupTestService(t, repo)
+	ctx := context.Background()
+
+	// Копия с истекшим сроком хранения удаляется после новой
+	old := &model.Backup{ID: uuid.New(), Key: "backups/old.ndjson.gz.enc", Status: model.BackupCompleted}
+	expiresAt := time.Now().Add(-time.Hour)
+	old.ExpiresAt = &expiresAt
+	repo.backups[old.ID] = old
+	if err := store.Put(ctx, old.Key, bytes.NewReader([]byte("old")), 3, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if record.Status != model.BackupCompleted || record.ExpiresAt == nil || *record.WALPosition != "0/16B3748" || record.Tables["subscriptions"] != 1 {
+		t.Errorf("unexpected backup record: %+v", record)
+	}
+
+	blob, err := store.Get(ctx, record.Key)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", record.Key, err)
+	}
+	sealed, _ := io.ReadAll(blob)
+	blob.Close()
+	if sum := sha256.Sum256(sealed); hex.EncodeToString(sum[:]) != *record.SHA256 || int64(len(sealed)) != *record.SizeBytes {
+		t.Errorf("checksum or size does not match the uploaded backup")
+	}
+
+	decrypted, err := backup.NewReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	decompressed, err := gzip.NewReader(decrypted)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	if plain, err := io.ReadAll(decompressed); err != nil || string(plain) != backupDump {
+		t.Errorf("restored dump = %q, %v", plain, err)
+	}
+
+	if len(repo.expired) != 1 || repo.expired[0] != old.ID {
+		t.Errorf("expired backups = %v, want %v", repo.expired, old.ID)
+	}
+	if _, err := store.Get(ctx, old.Key); !errors.Is(err, blobstore.ErrNotFound) {
+		t.Errorf("expired backup is still stored: %v", err)
+	}
+}
+
+func TestRunBackupFailure(t *testing.T) {
+	repo := &backupRepoStub{backups: map[uuid.UUID]*model.Backup{}, dumpErr: errors.New("connection reset")}
+	svc, _, _ := newBackupTestService(t, repo)
+
+	record, err := svc.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() succeeded with a failing dump")
+	}
+	if record.Status != model.BackupFailed || record.Error == nil || record.FinishedAt == nil {
+		t.Errorf("failed backup not recorded: %+v", record)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type CatalogService interface {
+	CreateService(ctx context.Context, req model.CatalogServiceRequest) (*model.CatalogService, error)
+	GetService(ctx context.Context, id uuid.UUID) (*model.CatalogService, error)
+	UpdateService(ctx context.Context, id uuid.UUID, req model.CatalogServiceRequest) (*model.CatalogService, error)
+	// DeleteService удаляет сервис; подписки, ссылавшиеся на него, остаются без service_id
+	DeleteService(ctx context.Context, id uuid.UUID) error
+	ListServices(ctx context.Context, filter model.CatalogFilter) ([]*model.CatalogService, error)
+}
+
+type catalogService struct {
+	repo   repository.CatalogRepository
+	logger *logger.Logger
+}
+
+func NewCatalogService(repo repository.CatalogRepository, logger *logger.Logger) CatalogService {
+	return &catalogService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *catalogService) CreateService(ctx context.Context, req model.CatalogServiceRequest) (*model.CatalogService, error) {
+	s.logger.Info(ctx, "Creating catalog service", "name", req.Name)
+
+	service, err := buildCatalogService(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, service); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrCatalogServiceExists
+		}
+		s.logger.Error(ctx, "Failed to create catalog service in repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) GetService(ctx context.Context, id uuid.UUID) (*model.CatalogService, error) {
+	service, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get catalog service from repository",
+			"service_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if service == nil {
+		s.logger.Warn(ctx, "Catalog service not found", "service_id", id)
+		return nil, ErrCatalogServiceNotFound
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) UpdateService(ctx context.Context, id uuid.UUID, req model.CatalogServiceRequest) (*model.CatalogService, error) {
+	s.logger.Info(ctx, "Updating catalog service", "service_id", id)
+
+	service, err := buildCatalogService(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, id, service); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCatalogServiceNotFound
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrCatalogServiceExists
+		}
+		s.logger.Error(ctx, "Failed to update catalog service in repository",
+			"service_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) DeleteService(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting catalog service", "service_id", id)
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCatalogServiceNotFound
+		}
+		s.logger.Error(ctx, "Failed to delete catalog service from repository",
+			"service_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	return nil
+}
+
+func (s *catalogService) ListServices(ctx context.Context, filter model.CatalogFilter) ([]*model.CatalogService, error) {
+	services, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list catalog services from repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	return services, nil
+}
+
+// buildCatalogService проверяет запрос и собирает сервис каталога. Пробелы по краям
+// названия и категории отбрасываются, чтобы уникальность названия не обходилась ими
+func buildCatalogService(req model.CatalogServiceRequest) (*model.CatalogService, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid service: name is required")
+	}
+
+	service := &model.CatalogService{
+		Name:               name,
+		DefaultMonthlyCost: req.DefaultMonthlyCost,
+		IconURL:            req.IconURL,
+	}
+	if req.Category != nil {
+		if category := strings.TrimSpace(*req.Category); category != "" {
+			service.Category = &category
+		}
+	}
+	return service, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// catalogRepoStub проверяет уникальность названия без учета регистра, как индекс services
+type catalogRepoStub struct {
+	repository.CatalogRepository
+	services []*model.CatalogService
+}
+
+func (r *catalogRepoStub) Create(ctx context.Context, service *model.CatalogService) error {
+	for _, existing := range r.services {
+		if strings.EqualFold(existing.Name, service.Name) {
+			return fmt.Errorf("service already exists: %w", repository.ErrConflict)
+		}
+	}
+	service.ID = uuid.New()
+	r.services = append(r.services, service)
+	return nil
+}
+
+func (r *catalogRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	return fmt.Errorf("service %w", repository.ErrNotFound)
+}
+
+func TestCreateCatalogService(t *testing.T) {
+	repo := &catalogRepoStub{}
+	svc := NewCatalogService(repo, logger.New(slog.LevelError+4))
+	ctx := context.Background()
+	blank := " "
+
+	created, err := svc.CreateService(ctx, model.CatalogServiceRequest{Name: " Yandex Plus ", Category: &blank})
+	if err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+	if created.Name != "Yandex Plus" || created.Category != nil {
+		t.Errorf("created = %q, category %v", created.Name, created.Category)
+	}
+
+	if _, err := svc.CreateService(ctx, model.CatalogServiceRequest{Name: "yandex plus"}); !errors.Is(err, ErrCatalogServiceExists) {
+		t.Errorf("duplicate CreateService() error = %v, want ErrCatalogServiceExists", err)
+	}
+	if _, err := svc.CreateService(ctx, model.CatalogServiceRequest{Name: "  "}); !errors.Is(err, model.ErrInvalidInput) {
+		t.Errorf("blank CreateService() error = %v, want ErrInvalidInput", err)
+	}
+	if err := svc.DeleteService(ctx, uuid.New()); !errors.Is(err, ErrCatalogServiceNotFound) {
+		t.Errorf("DeleteService() error = %v, want ErrCatalogServiceNotFound", err)
+	}
+}
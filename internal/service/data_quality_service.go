@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type DataQualityService interface {
+	// Report проверяет подписки пользователя на полноту и возвращает замечания с действиями
+	Report(ctx context.Context, userID uuid.UUID) (*model.DataQualityReport, error)
+}
+
+type dataQualityService struct {
+	repo   repository.SubscriptionRepository
+	logger *logger.Logger
+}
+
+func NewDataQualityService(repo repository.SubscriptionRepository, logger *logger.Logger) DataQualityService {
+	return &dataQualityService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *dataQualityService) Report(ctx context.Context, userID uuid.UUID) (*model.DataQualityReport, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Building data quality report", "user_id", userID)
+
+	var subscriptions []*model.Subscription
+	err := s.repo.Stream(ctx, model.SubscriptionFilter{UserID: &userID}, func(sub *model.Subscription) error {
+		subscriptions = append(subscriptions, sub)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read subscriptions for data quality report",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build data quality report: %w", err)
+	}
+
+	suggested, err := s.suggestedServiceNames(ctx, subscriptions)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read service name usage",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to build data quality report: %w", err)
+	}
+
+	report := &model.DataQualityReport{
+		UserID:        userID,
+		Score:         100,
+		Subscriptions: len(subscriptions),
+		Items:         []model.DataQualityItem{},
+	}
+	month := model.CurrentMonth()
+	for _, sub := range subscriptions {
+		items := checkSubscriptionQuality(sub, month, suggested)
+		if len(items) == 0 {
+			report.Complete++
+		}
+		report.Items = append(report.Items, items...)
+	}
+	if report.Subscriptions > 0 {
+		report.Score = report.Complete * 100 / report.Subscriptions
+	}
+
+	s.logger.Debug(ctx, "Data quality report built",
+		"user_id", userID,
+		"score", report.Score,
+		"items", len(report.Items),
+	)
+	return report, nil
+}
+
+// suggestedServiceNames возвращает для написаний названий сервисов пользователя
+// предлагаемую замену - написание того же названия (model.NormalizeServiceName), которое
+// используют больше пользователей. Равно распространенные написания не заменяются
+func (s *dataQualityService) suggestedServiceNames(ctx context.Context, subscriptions []*model.Subscription) (map[string]string, error) {
+	seen := make(map[string]struct{})
+	var normalized []string
+	for _, sub := range subscriptions {
+		key := model.NormalizeServiceName(sub.ServiceName)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			normalized = append(normalized, key)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	usage, err := s.repo.ServiceNameUsage(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	best := make(map[string]model.ServiceNameUsage, len(normalized))
+	for _, u := range usage {
+		key := model.NormalizeServiceName(u.ServiceName)
+		if b, ok := best[key]; !ok || u.Users > b.Users {
+			best[key] = u
+		}
+	}
+
+	suggested := make(map[string]string)
+	for _, u := range usage {
+		if b := best[model.NormalizeServiceName(u.ServiceName)]; b.Users > u.Users {
+			suggested[u.ServiceName] = b.ServiceName
+		}
+	}
+	return suggested, nil
+}
+
+// checkSubscriptionQuality возвращает замечания к подписке; month - текущий месяц,
+// suggested - замены написаний названий сервисов
+func checkSubscriptionQuality(sub *model.Subscription, month time.Time, suggested map[string]string) []model.DataQualityItem {
+	var items []model.DataQualityItem
+	item := func(code, action string) model.DataQualityItem {
+		return model.DataQualityItem{Code: code, SubscriptionID: sub.ID, ServiceName: sub.ServiceName, Action: action}
+	}
+
+	// У отмененной и истекшей подписки end_date есть всегда
+	if sub.EndDate == nil && (sub.Status == model.StatusActive || sub.Status == model.StatusPaused) {
+		items = append(items, item(model.QualityMissingEndDate,
+			"set end_date if the subscription is not renewed indefinitely, otherwise summaries include all future months"))
+	}
+
+	if sub.IsDraft && sub.StartDate.Before(month) {
+		items = append(items, item(model.QualityStaleDraft,
+			"activate the draft or delete it: drafts are excluded from summaries and invoices"))
+	}
+
+	if suggestion, ok := suggested[sub.ServiceName]; ok {
+		nonstandard := item(model.QualityNonstandardServiceName,
+			fmt.Sprintf("rename the service to %q so it is grouped with other subscriptions to it", suggestion))
+		nonstandard.Suggestion = &suggestion
+		items = append(items, nonstandard)
+	}
+
+	return items
+}
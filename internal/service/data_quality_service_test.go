@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type qualityRepoStub struct {
+	repository.SubscriptionRepository
+	subs  []*model.Subscription
+	usage []model.ServiceNameUsage
+}
+
+func (r *qualityRepoStub) Stream(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	for _, sub := range r.subs {
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *qualityRepoStub) ServiceNameUsage(ctx context.Context, normalized []string) ([]model.ServiceNameUsage, error) {
+	return r.usage, nil
+}
+
+func TestDataQualityReport(t *testing.T) {
+	userID := uuid.New()
+	month := model.CurrentMonth()
+	ended := month.AddDate(0, 6, 0)
+
+	complete := &model.Subscription{ID: uuid.New(), ServiceName: "Netflix", StartDate: month, EndDate: &ended, Status: model.StatusActive}
+	openEnded := &model.Subscription{ID: uuid.New(), ServiceName: "Spotify", StartDate: month, Status: model.StatusActive}
+	staleDraft := &model.Subscription{ID: uuid.New(), ServiceName: "Netflix", StartDate: month.AddDate(0, -2, 0), EndDate: &ended, IsDraft: true, Status: model.StatusActive}
+	misspelled := &model.Subscription{ID: uuid.New(), ServiceName: "yandex  plus", StartDate: month, EndDate: &ended, Status: model.StatusActive}
+
+	repo := &qualityRepoStub{
+		subs: []*model.Subscription{complete, openEnded, staleDraft, misspelled},
+		usage: []model.ServiceNameUsage{
+			{ServiceName: "Netflix", Users: 40},
+			{ServiceName: "Spotify", Users: 3},
+			// Равно распространенное написание не считается ошибкой
+			{ServiceName: "spotify", Users: 3},
+			{ServiceName: "Yandex Plus", Users: 25},
+			{ServiceName: "yandex  plus", Users: 1},
+		},
+	}
+
+	report, err := NewDataQualityService(repo, logger.New(slog.LevelError+4)).Report(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	if report.Subscriptions != 4 || report.Complete != 1 || report.Score != 25 {
+		t.Errorf("subscriptions = %d, complete = %d, score = %d, want 4, 1, 25", report.Subscriptions, report.Complete, report.Score)
+	}
+
+	want := map[uuid.UUID]string{
+		openEnded.ID:  model.QualityMissingEndDate,
+		staleDraft.ID: model.QualityStaleDraft,
+		misspelled.ID: model.QualityNonstandardServiceName,
+	}
+	if len(report.Items) != len(want) {
+		t.Fatalf("items = %+v", report.Items)
+	}
+	for _, item := range report.Items {
+		if want[item.SubscriptionID] != item.Code {
+			t.Errorf("unexpected item %+v", item)
+		}
+		if item.Code == model.QualityNonstandardServiceName && (item.Suggestion == nil || *item.Suggestion != "Yandex Plus") {
+			t.Errorf("suggestion = %v, want Yandex Plus", item.Suggestion)
+		}
+	}
+}
+
+func TestDataQualityReportEmpty(t *testing.T) {
+	report, err := NewDataQualityService(&qualityRepoStub{}, logger.New(slog.LevelError+4)).Report(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.Score != 100 || report.Items == nil {
+		t.Errorf("unexpected empty report: %+v", report)
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// DiscountService управляет скидками. Скидки уменьшают итоги и счета, поэтому их создает,
+// меняет и удаляет только администратор; пользователь видит скидки своих подписок и свои
+type DiscountService interface {
+	CreateDiscount(ctx context.Context, req model.DiscountRequest) (*model.Discount, error)
+	GetDiscount(ctx context.Context, id uuid.UUID) (*model.Discount, error)
+	UpdateDiscount(ctx context.Context, id uuid.UUID, req model.DiscountRequest) (*model.Discount, error)
+	DeleteDiscount(ctx context.Context, id uuid.UUID) error
+	// ListDiscounts возвращает скидки по фильтру. Пользователю без прав администратора
+	// доступны скидки его подписки (filter.SubscriptionID) или его собственные
+	ListDiscounts(ctx context.Context, filter model.DiscountFilter) ([]*model.Discount, error)
+}
+
+type discountService struct {
+	repo          repository.DiscountRepository
+	subscriptions repository.SubscriptionRepository
+	logger        *logger.Logger
+}
+
+func NewDiscountService(repo repository.DiscountRepository, subscriptions repository.SubscriptionRepository, logger *logger.Logger) DiscountService {
+	return &discountService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		logger:        logger,
+	}
+}
+
+func (s *discountService) CreateDiscount(ctx context.Context, req model.DiscountRequest) (*model.Discount, error) {
+	if err := auth.RequireAdmin(ctx, "creating discounts"); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Creating discount",
+		"kind", req.Kind,
+		"subscription_id", req.SubscriptionID,
+		"user_id", req.UserID,
+	)
+
+	discount, err := s.buildDiscount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, discount); err != nil {
+		s.logger.Error(ctx, "Failed to create discount in repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to create discount: %w", err)
+	}
+
+	return discount, nil
+}
+
+func (s *discountService) GetDiscount(ctx context.Context, id uuid.UUID) (*model.Discount, error) {
+	discount, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get discount from repository",
+			"discount_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get discount: %w", err)
+	}
+	if discount == nil {
+		s.logger.Warn(ctx, "Discount not found", "discount_id", id)
+		return nil, ErrDiscountNotFound
+	}
+	if err := s.requireOwner(ctx, discount); err != nil {
+		return nil, err
+	}
+
+	return discount, nil
+}
+
+func (s *discountService) UpdateDiscount(ctx context.Context, id uuid.UUID, req model.DiscountRequest) (*model.Discount, error) {
+	if err := auth.RequireAdmin(ctx, "updating discounts"); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Updating discount", "discount_id", id)
+
+	discount, err := s.buildDiscount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, id, discount); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDiscountNotFound
+		}
+		s.logger.Error(ctx, "Failed to update discount in repository",
+			"discount_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to update discount: %w", err)
+	}
+
+	return s.GetDiscount(ctx, id)
+}
+
+func (s *discountService) DeleteDiscount(ctx context.Context, id uuid.UUID) error {
+	if err := auth.RequireAdmin(ctx, "deleting discounts"); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Deleting discount", "discount_id", id)
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrDiscountNotFound
+		}
+		s.logger.Error(ctx, "Failed to delete discount from repository",
+			"discount_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete discount: %w", err)
+	}
+
+	return nil
+}
+
+func (s *discountService) ListDiscounts(ctx context.Context, filter model.DiscountFilter) ([]*model.Discount, error) {
+	// Скидка подписки не хранит пользователя: ее скидки доступны владельцу подписки
+	if filter.SubscriptionID != nil {
+		if err := requireSubscriptionOwner(ctx, s.subscriptions, s.logger, *filter.SubscriptionID); err != nil {
+			return nil, err
+		}
+	} else {
+		userID, err := auth.ScopeUserID(ctx, filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+		filter.UserID = userID
+	}
+
+	discounts, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list discounts from repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list discounts: %w", err)
+	}
+
+	return discounts, nil
+}
+
+// requireOwner возвращает ErrDiscountNotFound, если скидка относится к другому пользователю
+// или к его подписке: пользователь без прав администратора чужих скидок не видит
+func (s *discountService) requireOwner(ctx context.Context, discount *model.Discount) error {
+	if discount.UserID != nil && !auth.CanAccessUser(ctx, *discount.UserID) {
+		s.logger.Warn(ctx, "Discount not found for caller", "discount_id", discount.ID)
+		return ErrDiscountNotFound
+	}
+	if discount.SubscriptionID != nil {
+		err := requireSubscriptionOwner(ctx, s.subscriptions, s.logger, *discount.SubscriptionID)
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			return ErrDiscountNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// buildDiscount проверяет запрос и собирает скидку. Ошибки проверки начинаются
+// с "invalid discount", чтобы обработчик мог вернуть 400
+func (s *discountService) buildDiscount(ctx context.Context, req model.DiscountRequest) (*model.Discount, error) {
+	if (req.SubscriptionID == nil) == (req.UserID == nil) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: exactly one of subscription_id and user_id is required")
+	}
+	if req.Kind == model.DiscountPercent && req.Value > 100 {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: percent value cannot exceed 100")
+	}
+
+	startDate, err := model.ParseMonthYear(req.StartDate)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: start date format, expected MM-YYYY: %w", err)
+	}
+	endDate, err := model.ParseMonthYearPtr(req.EndDate)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: end date format, expected MM-YYYY: %w", err)
+	}
+	if err := validateDates(startDate, endDate); err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: %w", err)
+	}
+
+	if req.SubscriptionID != nil {
+		sub, err := s.subscriptions.GetByID(ctx, *req.SubscriptionID)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to check discounted subscription",
+				"subscription_id", *req.SubscriptionID,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to check subscription: %w", err)
+		}
+		if sub == nil {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid discount: subscription not found")
+		}
+	}
+
+	return &model.Discount{
+		Kind:           req.Kind,
+		Value:          req.Value,
+		SubscriptionID: req.SubscriptionID,
+		UserID:         req.UserID,
+		PromoCode:      req.PromoCode,
+		StartDate:      startDate,
+		EndDate:        endDate,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type discountRepoStub struct {
+	repository.DiscountRepository
+	created []*model.Discount
+	deleted []uuid.UUID
+	listed  *model.DiscountFilter
+}
+
+func (r *discountRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Discount, error) {
+	for _, discount := range r.created {
+		if discount.ID == id {
+			return discount, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *discountRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *discountRepoStub) List(ctx context.Context, filter model.DiscountFilter) ([]*model.Discount, error) {
+	r.listed = &filter
+	return nil, nil
+}
+
+func (r *discountRepoStub) Create(ctx context.Context, discount *model.Discount) error {
+	discount.ID = uuid.New()
+	r.created = append(r.created, discount)
+	return nil
+}
+
+// subscriptionLookupStub находит только подписку existing пользователя owner
+type subscriptionLookupStub struct {
+	repository.SubscriptionRepository
+	existing uuid.UUID
+	owner    uuid.UUID
+}
+
+func (r *subscriptionLookupStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	if id != r.existing {
+		return nil, nil
+	}
+	return &model.Subscription{ID: id, UserID: r.owner}, nil
+}
+
+func TestCreateDiscountValidation(t *testing.T) {
+	subscriptionID := uuid.New()
+	missingID := uuid.New()
+	userID := uuid.New()
+	endDate := "06-2025"
+
+	tests := []struct {
+		name    string
+		req     model.DiscountRequest
+		wantErr string
+	}{
+		{
+			name: "subscription discount",
+			req:  model.DiscountRequest{Kind: model.DiscountPercent, Value: 20, SubscriptionID: &subscriptionID, StartDate: "07-2025"},
+		},
+		{
+			name: "user discount",
+			req:  model.DiscountRequest{Kind: model.DiscountFixed, Value: 100, UserID: &userID, StartDate: "07-2025"},
+		},
+		{
+			name:    "no target",
+			req:     model.DiscountRequest{Kind: model.DiscountFixed, Value: 100, StartDate: "07-2025"},
+			wantErr: "invalid discount: exactly one of",
+		},
+		{
+			name:    "both targets",
+			req:     model.DiscountRequest{Kind: model.DiscountFixed, Value: 100, SubscriptionID: &subscriptionID, UserID: &userID, StartDate: "07-2025"},
+			wantErr: "invalid discount: exactly one of",
+		},
+		{
+			name:    "percent over 100",
+			req:     model.DiscountRequest{Kind: model.DiscountPercent, Value: 120, UserID: &userID, StartDate: "07-2025"},
+			wantErr: "invalid discount: percent value",
+		},
+		{
+			name:    "end before start",
+			req:     model.DiscountRequest{Kind: model.DiscountPercent, Value: 10, UserID: &userID, StartDate: "07-2025", EndDate: &endDate},
+			wantErr: "invalid discount: end date cannot be before start date",
+		},
+		{
+			name:    "unknown subscription",
+			req:     model.DiscountRequest{Kind: model.DiscountPercent, Value: 10, SubscriptionID: &missingID, StartDate: "07-2025"},
+			wantErr: "invalid discount: subscription not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &discountRepoStub{}
+			svc := NewDiscountService(repo, &subscriptionLookupStub{existing: subscriptionID}, logger.New(slog.LevelError+4))
+
+			discount, err := svc.CreateDiscount(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateDiscount() error = %v, want prefix %q", err, tt.wantErr)
+				}
+				if len(repo.created) != 0 {
+					t.Error("invalid discount must not be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateDiscount() error = %v", err)
+			}
+			if discount.ID == uuid.Nil || discount.StartDate.Format("01-2006") != tt.req.StartDate {
+				t.Errorf("CreateDiscount() = %+v", discount)
+			}
+		})
+	}
+}
+
+// TestDiscountsScopedToCaller проверяет, что пользователь без прав администратора не
+// меняет скидки и видит только свои скидки и скидки своих подписок
+func TestDiscountsScopedToCaller(t *testing.T) {
+	owner, stranger := uuid.New(), uuid.New()
+	subscriptionID := uuid.New()
+	onSubscription := &model.Discount{ID: uuid.New(), Kind: model.DiscountPercent, Value: 20, SubscriptionID: &subscriptionID}
+	onUser := &model.Discount{ID: uuid.New(), Kind: model.DiscountFixed, Value: 100, UserID: &owner}
+	repo := &discountRepoStub{created: []*model.Discount{onSubscription, onUser}}
+	svc := NewDiscountService(repo, &subscriptionLookupStub{existing: subscriptionID, owner: owner}, logger.New(slog.LevelError+4))
+
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: owner})
+	req := model.DiscountRequest{Kind: model.DiscountPercent, Value: 100, SubscriptionID: &subscriptionID, StartDate: "07-2025"}
+	if _, err := svc.CreateDiscount(ctx, req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("CreateDiscount() by owner error = %v, want forbidden", err)
+	}
+	if _, err := svc.UpdateDiscount(ctx, onSubscription.ID, req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("UpdateDiscount() by owner error = %v, want forbidden", err)
+	}
+	if err := svc.DeleteDiscount(ctx, onUser.ID); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("DeleteDiscount() by owner error = %v, want forbidden", err)
+	}
+	if len(repo.created) != 2 || len(repo.deleted) != 0 {
+		t.Error("discounts changed without admin rights")
+	}
+	for _, discount := range []*model.Discount{onSubscription, onUser} {
+		if got, err := svc.GetDiscount(ctx, discount.ID); err != nil || got != discount {
+			t.Errorf("GetDiscount(%s) by owner = %+v, %v", discount.ID, got, err)
+		}
+	}
+
+	ctx = auth.WithCaller(context.Background(), auth.Caller{UserID: stranger})
+	for _, discount := range []*model.Discount{onSubscription, onUser} {
+		if _, err := svc.GetDiscount(ctx, discount.ID); !errors.Is(err, ErrDiscountNotFound) {
+			t.Errorf("GetDiscount(%s) by stranger error = %v, want ErrDiscountNotFound", discount.ID, err)
+		}
+	}
+	if _, err := svc.ListDiscounts(ctx, model.DiscountFilter{SubscriptionID: &subscriptionID}); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("ListDiscounts() of foreign subscription error = %v, want ErrSubscriptionNotFound", err)
+	}
+	if _, err := svc.ListDiscounts(ctx, model.DiscountFilter{UserID: &owner}); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("ListDiscounts() of other user error = %v, want forbidden", err)
+	}
+	if _, err := svc.ListDiscounts(ctx, model.DiscountFilter{}); err != nil || repo.listed.UserID == nil || *repo.listed.UserID != stranger {
+		t.Errorf("ListDiscounts() without filter = %+v, %v; want scoped to caller", repo.listed, err)
+	}
+
+	admin := auth.WithCaller(context.Background(), auth.Caller{Admin: true})
+	if err := svc.DeleteDiscount(admin, onUser.ID); err != nil {
+		t.Errorf("DeleteDiscount() by admin error = %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// Ошибки предметной области. Сервисы возвращают их (с уточнением через %w) вместо
+// ошибок репозиториев, а обработчики выбирают ответ через errors.Is
+var (
+	ErrSubscriptionNotFound            = errors.New("subscription not found")
+	ErrDiscountNotFound                = errors.New("discount not found")
+	ErrTemplateNotFound                = errors.New("template not found")
+	ErrInvoiceNotFound                 = errors.New("invoice not found")
+	ErrNotificationPreferencesNotFound = errors.New("notification preferences not found")
+	ErrNotificationNotFound            = errors.New("notification not found")
+	ErrCatalogServiceNotFound          = errors.New("service not found")
+	ErrTagNotFound                     = errors.New("tag not found")
+	ErrBackupNotFound                  = errors.New("backup not found")
+	ErrReminderSettingsNotFound        = errors.New("reminder settings not found")
+	ErrAPITokenNotFound                = errors.New("API token not found")
+	ErrGroupMappingNotFound            = errors.New("SSO group mapping not found")
+
+	ErrInvalidStatusTransition   = errors.New("invalid status transition")
+	ErrSubscriptionAlreadyActive = errors.New("subscription is already active")
+	ErrTransferNotAllowed        = errors.New("subscription cannot be transferred")
+	ErrInvoiceAlreadyExists      = errors.New("invoice already exists")
+	ErrCatalogServiceExists      = errors.New("service already exists")
+	ErrTagExists                 = errors.New("tag already exists")
+	ErrSyncConflict              = errors.New("subscriptions changed during sync")
+
+	ErrInvalidIdempotencyKey = errors.New("invalid Idempotency-Key")
+	ErrIdempotencyKeyInUse   = errors.New("idempotency key in use")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused")
+
+	ErrInboundEventInProgress = errors.New("inbound event in progress")
+)
+
+// subscriptionError переводит ошибку репозитория подписок в ошибку предметной области:
+// отсутствие подписки - ErrSubscriptionNotFound, остальные ошибки оборачиваются с действием action
+func subscriptionError(err error, action string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	return fmt.Errorf("failed to %s subscription: %w", action, err)
+}
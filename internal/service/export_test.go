@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// keysetRepo хранит подписки в памяти и отдает страницы с той же семантикой,
+// что и запрос "(created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $1"
+type keysetRepo struct {
+	repository.SubscriptionRepository
+
+	rows []*model.Subscription
+	// beforePage вызывается перед чтением каждой страницы и имитирует параллельные вставки
+	beforePage func(page int)
+	pages      int
+}
+
+func (r *keysetRepo) insert(sub *model.Subscription) {
+	r.rows = append(r.rows, sub)
+	sort.Slice(r.rows, func(i, j int) bool {
+		return cursorLess(r.rows[i].CreatedAt, r.rows[i].ID, r.rows[j].CreatedAt, r.rows[j].ID)
+	})
+}
+
+func (r *keysetRepo) ListAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) ([]*model.Subscription, error) {
+	if r.beforePage != nil {
+		r.beforePage(r.pages)
+	}
+	r.pages++
+
+	var page []*model.Subscription
+	for _, sub := range r.rows {
+		if after != nil && !cursorLess(after.CreatedAt, after.ID, sub.CreatedAt, sub.ID) {
+			continue
+		}
+		page = append(page, sub)
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func cursorLess(t1 time.Time, id1 uuid.UUID, t2 time.Time, id2 uuid.UUID) bool {
+	if !t1.Equal(t2) {
+		return t1.Before(t2)
+	}
+	return id1.String() < id2.String()
+}
+
+func newExportTestService(repo repository.SubscriptionRepository) SubscriptionService {
+	tax, _ := money.NewTax("0", true, "half_up")
+	return NewSubscriptionService(repo, FixedTax(tax), nil, nil, logger.New(slog.LevelError+4))
+}
+
+func TestExportSubscriptionsStableUnderConcurrentInserts(t *testing.T) {
+	base := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	repo := &keysetRepo{}
+
+	// Несколько подписок с одинаковым created_at проверяют разрешение равенства по id
+	existing := make(map[uuid.UUID]bool)
+	for i := 0; i < exportPageSize*3+17; i++ {
+		sub := &model.Subscription{ID: uuid.New(), CreatedAt: base.Add(time.Duration(i/3) * time.Second)}
+		repo.insert(sub)
+		existing[sub.ID] = false
+	}
+
+	repo.beforePage = func(page int) {
+		if page == 0 {
+			return
+		}
+		// Новые строки в конец и "запоздавшие" строки с уже пройденным created_at
+		repo.insert(&model.Subscription{ID: uuid.New(), CreatedAt: base.Add(time.Hour)})
+		repo.insert(&model.Subscription{ID: uuid.New(), CreatedAt: base})
+	}
+
+	var previous *model.Subscription
+	err := newExportTestService(repo).ExportSubscriptions(context.Background(), func(sub *model.Subscription) error {
+		if previous != nil && !cursorLess(previous.CreatedAt, previous.ID, sub.CreatedAt, sub.ID) {
+			t.Fatalf("export is not strictly ordered: %v after %v", sub.ID, previous.ID)
+		}
+		previous = sub
+
+		if seen, ok := existing[sub.ID]; ok {
+			if seen {
+				t.Fatalf("subscription %v exported twice", sub.ID)
+			}
+			existing[sub.ID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportSubscriptions() error = %v", err)
+	}
+
+	for id, seen := range existing {
+		if !seen {
+			t.Errorf("subscription %v existing before export was skipped", id)
+		}
+	}
+	if repo.pages < 4 {
+		t.Errorf("expected export to read several pages, got %d", repo.pages)
+	}
+}
+
+func TestListSubscriptionsAfterWalksAllPages(t *testing.T) {
+	base := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	repo := &keysetRepo{}
+	for i := 0; i < 10; i++ {
+		repo.insert(&model.Subscription{ID: uuid.New(), CreatedAt: base.Add(time.Duration(i/2) * time.Second)})
+	}
+	svc := newExportTestService(repo)
+
+	var after *model.SubscriptionCursor
+	var walked []*model.Subscription
+	for pages := 1; ; pages++ {
+		page, err := svc.ListSubscriptionsAfter(context.Background(), model.SubscriptionFilter{}, after, 4)
+		if err != nil {
+			t.Fatalf("ListSubscriptionsAfter() error = %v", err)
+		}
+		if len(page.Items) > 4 {
+			t.Fatalf("page has %d items, want at most 4", len(page.Items))
+		}
+		walked = append(walked, page.Items...)
+
+		if page.Next == nil {
+			if pages != 3 {
+				t.Errorf("walked %d pages, want 3", pages)
+			}
+			break
+		}
+		last := page.Items[len(page.Items)-1]
+		if page.Next.ID != last.ID || !page.Next.CreatedAt.Equal(last.CreatedAt) {
+			t.Fatalf("next cursor %+v does not point to the last item %v", page.Next, last.ID)
+		}
+		after = page.Next
+	}
+
+	if len(walked) != len(repo.rows) {
+		t.Fatalf("walked %d subscriptions, want %d", len(walked), len(repo.rows))
+	}
+	for i, sub := range walked {
+		if sub.ID != repo.rows[i].ID {
+			t.Fatalf("subscription %d = %v, want %v", i, sub.ID, repo.rows[i].ID)
+		}
+	}
+}
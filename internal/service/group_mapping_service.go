@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+)
+
+// groupMappingsTTL - сколько сопоставления групп хранятся в памяти реплики. Изменения через
+// API сбрасывают кэш сразу, изменения на других репликах становятся видны через TTL
+const groupMappingsTTL = 30 * time.Second
+
+type GroupMappingService interface {
+	ListMappings(ctx context.Context) ([]model.GroupMapping, error)
+	// SaveMapping создает или заменяет сопоставление группы: повтор запроса ничего не меняет
+	SaveMapping(ctx context.Context, req model.SaveGroupMappingRequest) (*model.GroupMapping, error)
+	DeleteMapping(ctx context.Context, group string) error
+
+	// ApplyGroups добавляет пользователю провайдера организации и права по его группам SSO.
+	// Группы без сопоставления пропускаются: пользователь без них остается как был
+	ApplyGroups(ctx context.Context, caller auth.Caller, groups []string) (auth.Caller, error)
+}
+
+type groupMappingService struct {
+	repo   repository.GroupMappingRepository
+	logger *logger.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	cached   map[string]model.GroupMapping
+	cachedAt time.Time
+}
+
+func NewGroupMappingService(repo repository.GroupMappingRepository, logger *logger.Logger) GroupMappingService {
+	return &groupMappingService{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *groupMappingService) ListMappings(ctx context.Context) ([]model.GroupMapping, error) {
+	if err := auth.RequireAdmin(ctx, "listing SSO group mappings"); err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSO group mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+func (s *groupMappingService) SaveMapping(ctx context.Context, req model.SaveGroupMappingRequest) (*model.GroupMapping, error) {
+	if err := auth.RequireAdmin(ctx, "saving SSO group mappings"); err != nil {
+		return nil, err
+	}
+
+	mapping := model.GroupMapping{
+		Group:  strings.TrimSpace(req.Group),
+		Tenant: req.Tenant,
+		Role:   req.Role,
+	}
+	if mapping.Group == "" {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid group: must not be empty")
+	}
+	if mapping.Tenant == "" {
+		if mapping.Role != model.GroupRoleAdmin {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid tenant: required for role %s", mapping.Role)
+		}
+	} else if err := tenant.Validate(mapping.Tenant); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to save SSO group mapping: %w", err)
+	}
+	s.invalidate()
+
+	s.logger.Info(ctx, "SSO group mapping saved",
+		"group", mapping.Group,
+		"tenant", mapping.Tenant,
+		"role", mapping.Role,
+		"actor", auth.Actor(ctx),
+	)
+	return &mapping, nil
+}
+
+func (s *groupMappingService) DeleteMapping(ctx context.Context, group string) error {
+	if err := auth.RequireAdmin(ctx, "deleting SSO group mappings"); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, group)
+	if err != nil {
+		return fmt.Errorf("failed to delete SSO group mapping: %w", err)
+	}
+	if !deleted {
+		return ErrGroupMappingNotFound
+	}
+	s.invalidate()
+
+	s.logger.Info(ctx, "SSO group mapping deleted",
+		"group", group,
+		"actor", auth.Actor(ctx),
+	)
+	return nil
+}
+
+func (s *groupMappingService) ApplyGroups(ctx context.Context, caller auth.Caller, groups []string) (auth.Caller, error) {
+	if len(groups) == 0 {
+		return caller, nil
+	}
+	mappings, err := s.mappings(ctx)
+	if err != nil {
+		return caller, err
+	}
+
+	for _, group := range groups {
+		mapping, ok := mappings[group]
+		switch {
+		case !ok:
+			continue
+		case mapping.Tenant == "":
+			caller.Admin = true
+		default:
+			if caller.Memberships == nil {
+				caller.Memberships = make(map[string]bool)
+			}
+			caller.Memberships[mapping.Tenant] = caller.Memberships[mapping.Tenant] || mapping.Role == model.GroupRoleAdmin
+		}
+	}
+	return caller, nil
+}
+
+// mappings возвращает сопоставления по имени группы из кэша, перечитывая их после TTL
+func (s *groupMappingService) mappings(ctx context.Context) (map[string]model.GroupMapping, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && s.now().Sub(s.cachedAt) < groupMappingsTTL {
+		return s.cached, nil
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSO group mappings: %w", err)
+	}
+	s.cached = make(map[string]model.GroupMapping, len(list))
+	for _, mapping := range list {
+		s.cached[mapping.Group] = mapping
+	}
+	s.cachedAt = s.now()
+	return s.cached, nil
+}
+
+func (s *groupMappingService) invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
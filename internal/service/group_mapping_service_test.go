@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+)
+
+// groupMappingRepoStub хранит сопоставления в памяти и считает чтения списка
+type groupMappingRepoStub struct {
+	mappings map[string]model.GroupMapping
+	lists    int
+}
+
+func (r *groupMappingRepoStub) List(ctx context.Context) ([]model.GroupMapping, error) {
+	r.lists++
+	mappings := []model.GroupMapping{}
+	for _, mapping := range r.mappings {
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func (r *groupMappingRepoStub) Save(ctx context.Context, mapping *model.GroupMapping) error {
+	mapping.UpdatedAt = time.Now()
+	r.mappings[mapping.Group] = *mapping
+	return nil
+}
+
+func (r *groupMappingRepoStub) Delete(ctx context.Context, group string) (bool, error) {
+	_, ok := r.mappings[group]
+	delete(r.mappings, group)
+	return ok, nil
+}
+
+func TestGroupMappingApplyGroups(t *testing.T) {
+	repo := &groupMappingRepoStub{mappings: map[string]model.GroupMapping{}}
+	svc := NewGroupMappingService(repo, logger.New(slog.LevelError+4))
+	ctx := context.Background()
+
+	for _, req := range []model.SaveGroupMappingRequest{
+		{Group: "/acme/billing", Tenant: "acme", Role: model.GroupRoleMember},
+		{Group: "/acme/admins", Tenant: "acme", Role: model.GroupRoleAdmin},
+		{Group: "/globex", Tenant: "globex", Role: model.GroupRoleMember},
+		{Group: "/platform", Role: model.GroupRoleAdmin},
+	} {
+		if _, err := svc.SaveMapping(ctx, req); err != nil {
+			t.Fatalf("SaveMapping(%s) error = %v", req.Group, err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		groups          []string
+		wantAdmin       bool
+		wantMemberships map[string]bool
+	}{
+		{"no groups", nil, false, nil},
+		{"unmapped groups", []string{"/other"}, false, nil},
+		{"member", []string{"/acme/billing", "/globex"}, false, map[string]bool{"acme": false, "globex": false}},
+		{"tenant admin wins over member", []string{"/acme/billing", "/acme/admins"}, false, map[string]bool{"acme": true}},
+		{"global admin", []string{"/platform"}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := svc.ApplyGroups(ctx, auth.Caller{}, tt.groups)
+			if err != nil {
+				t.Fatalf("ApplyGroups() error = %v", err)
+			}
+			if caller.Admin != tt.wantAdmin || !maps.Equal(caller.Memberships, tt.wantMemberships) {
+				t.Errorf("ApplyGroups() = admin %t, memberships %v, want %t, %v", caller.Admin, caller.Memberships, tt.wantAdmin, tt.wantMemberships)
+			}
+		})
+	}
+	if repo.lists != 1 {
+		t.Errorf("mappings loaded %d times, want 1 within TTL", repo.lists)
+	}
+
+	// Изменение через API сразу сбрасывает кэш реплики
+	if err := svc.DeleteMapping(ctx, "/platform"); err != nil {
+		t.Fatalf("DeleteMapping() error = %v", err)
+	}
+	if caller, _ := svc.ApplyGroups(ctx, auth.Caller{}, []string{"/platform"}); caller.Admin {
+		t.Error("deleted mapping still grants admin rights")
+	}
+	if err := svc.DeleteMapping(ctx, "/platform"); !errors.Is(err, ErrGroupMappingNotFound) {
+		t.Errorf("DeleteMapping() twice error = %v, want ErrGroupMappingNotFound", err)
+	}
+}
+
+func TestGroupMappingSaveValidation(t *testing.T) {
+	repo := &groupMappingRepoStub{mappings: map[string]model.GroupMapping{}}
+	svc := NewGroupMappingService(repo, logger.New(slog.LevelError+4))
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     model.SaveGroupMappingRequest
+		wantErr error
+	}{
+		{"member without tenant", context.Background(), model.SaveGroupMappingRequest{Group: "/all", Role: model.GroupRoleMember}, model.ErrInvalidInput},
+		{"blank group", context.Background(), model.SaveGroupMappingRequest{Group: "  ", Tenant: "acme", Role: model.GroupRoleMember}, model.ErrInvalidInput},
+		{"invalid tenant", context.Background(), model.SaveGroupMappingRequest{Group: "/acme", Tenant: "Acme Corp", Role: model.GroupRoleMember}, tenant.ErrInvalidTenant},
+		{"not an admin", auth.WithCaller(context.Background(), auth.Caller{}), model.SaveGroupMappingRequest{Group: "/acme", Tenant: "acme", Role: model.GroupRoleAdmin}, auth.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.SaveMapping(tt.ctx, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SaveMapping() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(repo.mappings) != 0 {
+		t.Errorf("invalid mappings saved: %v", repo.mappings)
+	}
+}
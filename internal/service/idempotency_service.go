@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+const (
+	// maxIdempotencyKeyLength - наибольшая длина Idempotency-Key
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTimeout - сколько запрос с ключом считается выполняющимся. Если
+	// реплика упала, не сохранив ответ, после него ключ можно занять повторно
+	idempotencyLockTimeout = 5 * time.Minute
+)
+
+type IdempotencyService interface {
+	// Begin занимает ключ автора запроса из ctx. Для повтора выполненного запроса возвращает
+	// сохраненный ответ; nil - ключ новый, запрос нужно выполнить и передать ответ в Finish.
+	// Ошибки: "invalid Idempotency-Key" - некорректный ключ, "idempotency key in use" - запрос
+	// с ключом еще выполняется, "idempotency key reused" - ключ использован с другим запросом
+	Begin(ctx context.Context, key, fingerprint string) (*model.IdempotencyRecord, error)
+	// Finish сохраняет ответ на запрос с ключом. Ответ 5xx не сохраняется, и повтор
+	// выполнится заново
+	Finish(ctx context.Context, key, fingerprint string, status int, contentType string, body []byte)
+	// Purge удаляет истекшие ключи
+	Purge(ctx context.Context) error
+}
+
+type idempotencyService struct {
+	repo     repository.IdempotencyRepository
+	counters *metrics.Idempotency
+	ttl      time.Duration
+	logger   *logger.Logger
+}
+
+// NewIdempotencyService хранит ответы на запросы с ключом ttl
+func NewIdempotencyService(repo repository.IdempotencyRepository, counters *metrics.Idempotency, ttl time.Duration, logger *logger.Logger) IdempotencyService {
+	return &idempotencyService{
+		repo:     repo,
+		counters: counters,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, key, fingerprint string) (*model.IdempotencyRecord, error) {
+	if err := validateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	existing, err := s.repo.Reserve(ctx, &model.IdempotencyRecord{
+		Actor:       auth.Actor(ctx),
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(idempotencyLockTimeout),
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	switch {
+	case existing == nil:
+		s.counters.Inc(metrics.IdempotencyMiss)
+		return nil, nil
+	case existing.Fingerprint != fingerprint:
+		s.counters.Inc(metrics.IdempotencyMismatch)
+		return nil, fmt.Errorf("%w: key %q was used with a different request", ErrIdempotencyKeyReused, key)
+	case existing.Status != model.IdempotencyCompleted:
+		s.counters.Inc(metrics.IdempotencyInProgress)
+		return nil, fmt.Errorf("%w: request with key %q is still in progress", ErrIdempotencyKeyInUse, key)
+	default:
+		s.counters.Inc(metrics.IdempotencyHit)
+		s.logger.Debug(ctx, "Replaying idempotent response",
+			"idempotency_key", key,
+			"status", existing.ResponseStatus,
+		)
+		return existing, nil
+	}
+}
+
+func (s *idempotencyService) Finish(ctx context.Context, key, fingerprint string, status int, contentType string, body []byte) {
+	// Ответ сохраняется, даже если клиент отключился, не дождавшись его
+	ctx = context.WithoutCancel(ctx)
+	actor := auth.Actor(ctx)
+
+	if status >= http.StatusInternalServerError {
+		if err := s.repo.Release(ctx, actor, key); err != nil {
+			s.logger.Error(ctx, "Failed to release idempotency key",
+				"idempotency_key", key,
+				"error", err,
+			)
+		}
+		return
+	}
+
+	err := s.repo.Complete(ctx, &model.IdempotencyRecord{
+		Actor:          actor,
+		Key:            key,
+		Fingerprint:    fingerprint,
+		ResponseStatus: status,
+		ContentType:    contentType,
+		ResponseBody:   body,
+	})
+	if err != nil {
+		// Ключ останется занятым до истечения idempotencyLockTimeout
+		s.logger.Error(ctx, "Failed to save idempotent response",
+			"idempotency_key", key,
+			"error", err,
+		)
+	}
+}
+
+func (s *idempotencyService) Purge(ctx context.Context) error {
+	deleted, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	s.logger.Info(ctx, "Purged expired idempotency keys",
+		"deleted", deleted,
+	)
+	return nil
+}
+
+// validateIdempotencyKey проверяет ключ: видимые ASCII-символы, не длиннее 255
+func validateIdempotencyKey(key string) error {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return fmt.Errorf("%w: expected 1 to %d characters", ErrInvalidIdempotencyKey, maxIdempotencyKeyLength)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return fmt.Errorf("%w: expected visible ASCII characters", ErrInvalidIdempotencyKey)
+		}
+	}
+	return nil
+}
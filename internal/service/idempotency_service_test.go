@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// idempotencyRepoStub хранит ключи в памяти, как таблица idempotency_keys одной организации
+type idempotencyRepoStub struct {
+	records  map[string]*model.IdempotencyRecord
+	released []string
+}
+
+func (r *idempotencyRepoStub) Reserve(ctx context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	if existing, ok := r.records[record.Actor+"/"+record.Key]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+	reserved := *record
+	reserved.Status = model.IdempotencyProcessing
+	r.records[record.Actor+"/"+record.Key] = &reserved
+	return nil, nil
+}
+
+func (r *idempotencyRepoStub) Complete(ctx context.Context, record *model.IdempotencyRecord) error {
+	stored := r.records[record.Actor+"/"+record.Key]
+	stored.Status = model.IdempotencyCompleted
+	stored.ResponseStatus, stored.ContentType, stored.ResponseBody = record.ResponseStatus, record.ContentType, record.ResponseBody
+	return nil
+}
+
+func (r *idempotencyRepoStub) Release(ctx context.Context, actor, key string) error {
+	delete(r.records, actor+"/"+key)
+	r.released = append(r.released, key)
+	return nil
+}
+
+func (r *idempotencyRepoStub) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotencyBegin(t *testing.T) {
+	ctx := context.Background()
+	repo := &idempotencyRepoStub{records: map[string]*model.IdempotencyRecord{}}
+	counters := metrics.NewIdempotency()
+	svc := NewIdempotencyService(repo, counters, time.Hour, logger.New(slog.LevelError+4))
+
+	if replay, err := svc.Begin(ctx, "key-1", "a"); err != nil || replay != nil {
+		t.Fatalf("first Begin() = %v, %v, want new key", replay, err)
+	}
+	if _, err := svc.Begin(ctx, "key-1", "a"); err == nil || !strings.HasPrefix(err.Error(), "idempotency key in use") {
+		t.Errorf("Begin() during request error = %v, want key in use", err)
+	}
+
+	svc.Finish(ctx, "key-1", "a", http.StatusCreated, "application/json", []byte(`{"id":1}`))
+	replay, err := svc.Begin(ctx, "key-1", "a")
+	if err != nil || replay == nil {
+		t.Fatalf("Begin() after finish = %v, %v, want replay", replay, err)
+	}
+	if replay.ResponseStatus != http.StatusCreated || string(replay.ResponseBody) != `{"id":1}` {
+		t.Errorf("replay = %d %s", replay.ResponseStatus, replay.ResponseBody)
+	}
+
+	if _, err := svc.Begin(ctx, "key-1", "b"); err == nil || !strings.HasPrefix(err.Error(), "idempotency key reused") {
+		t.Errorf("Begin() with other request error = %v, want key reused", err)
+	}
+	if _, err := svc.Begin(ctx, "bad key", "a"); err == nil || !strings.HasPrefix(err.Error(), "invalid Idempotency-Key") {
+		t.Errorf("Begin() with invalid key error = %v, want invalid", err)
+	}
+
+	for outcome, want := range map[string]int64{
+		metrics.IdempotencyMiss:       1,
+		metrics.IdempotencyHit:        1,
+		metrics.IdempotencyInProgress: 1,
+		metrics.IdempotencyMismatch:   1,
+	} {
+		if got := counters.Count(outcome); got != want {
+			t.Errorf("%s count = %d, want %d", outcome, got, want)
+		}
+	}
+}
+
+func TestIdempotencyFinishServerError(t *testing.T) {
+	ctx := context.Background()
+	repo := &idempotencyRepoStub{records: map[string]*model.IdempotencyRecord{}}
+	svc := NewIdempotencyService(repo, nil, time.Hour, logger.New(slog.LevelError+4))
+
+	if _, err := svc.Begin(ctx, "key-1", "a"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	svc.Finish(ctx, "key-1", "a", http.StatusServiceUnavailable, "application/json", nil)
+
+	if len(repo.released) != 1 {
+		t.Fatalf("released keys = %v, want key-1", repo.released)
+	}
+	if replay, err := svc.Begin(ctx, "key-1", "a"); err != nil || replay != nil {
+		t.Errorf("Begin() after server error = %v, %v, want new key", replay, err)
+	}
+}
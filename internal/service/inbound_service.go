@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// inboundLockTimeout - сколько событие считается обрабатываемым. Если реплика упала,
+	// не завершив обработку, после него повтор события обрабатывается заново
+	inboundLockTimeout = 5 * time.Minute
+	// maxDeduplicatedEvents - наибольшее число событий в выборке отброшенных повторов
+	maxDeduplicatedEvents = 500
+)
+
+type InboundService interface {
+	// Consume применяет событие интеграции один раз: повтор обработанного события
+	// отбрасывается и возвращает результат со статусом duplicate. Ошибка "inbound event
+	// in progress" - событие еще обрабатывается; после ошибки применения событие
+	// можно доставить повторно
+	Consume(ctx context.Context, event model.InboundEvent) (*model.InboundEventResult, error)
+	// ListDeduplicated возвращает до limit событий с отброшенными повторами, начиная с последнего повтора
+	ListDeduplicated(ctx context.Context, limit int) ([]model.InboundRecord, error)
+	// Purge удаляет записи событий, обработанных раньше срока хранения
+	Purge(ctx context.Context) error
+}
+
+type inboundService struct {
+	repo          repository.InboundRepository
+	subscriptions SubscriptionService
+	counters      *metrics.InboundEvents
+	retention     time.Duration
+	logger        *logger.Logger
+}
+
+// NewInboundService применяет события к подпискам через subscriptions и хранит
+// идентификаторы обработанных событий retention
+func NewInboundService(repo repository.InboundRepository, subscriptions SubscriptionService, counters *metrics.InboundEvents, retention time.Duration, logger *logger.Logger) InboundService {
+	return &inboundService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		counters:      counters,
+		retention:     retention,
+		logger:        logger,
+	}
+}
+
+func (s *inboundService) Consume(ctx context.Context, event model.InboundEvent) (*model.InboundEventResult, error) {
+	payload, err := event.DecodeData()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.Claim(ctx, &model.InboundRecord{
+		Source:      event.Source,
+		EventID:     event.ID,
+		Type:        event.Type,
+		LockedUntil: time.Now().Add(inboundLockTimeout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim inbound event: %w", err)
+	}
+
+	result := &model.InboundEventResult{ID: event.ID, Source: event.Source, Type: event.Type}
+	if existing != nil {
+		if existing.Status != model.InboundProcessed {
+			s.counters.Inc(event.Source, metrics.InboundInProgress)
+			return nil, fmt.Errorf("%w: event %q from %s", ErrInboundEventInProgress, event.ID, event.Source)
+		}
+		s.counters.Inc(event.Source, metrics.InboundDuplicate)
+		s.logger.Info(ctx, "Dropped duplicate inbound event",
+			"source", event.Source,
+			"event_id", event.ID,
+			"type", event.Type,
+			"duplicates", existing.Duplicates,
+		)
+		result.Status = model.InboundDuplicate
+		result.SubscriptionID = existing.SubscriptionID
+		return result, nil
+	}
+
+	subscriptionID, err := s.apply(ctx, payload)
+	if err != nil {
+		s.counters.Inc(event.Source, metrics.InboundFailed)
+		// Освобождение не зависит от отмены запроса: иначе повтор ждал бы inboundLockTimeout
+		if releaseErr := s.repo.Release(context.WithoutCancel(ctx), event.Source, event.ID); releaseErr != nil {
+			s.logger.Error(ctx, "Failed to release inbound event",
+				"source", event.Source,
+				"event_id", event.ID,
+				"error", releaseErr,
+			)
+		}
+		return nil, err
+	}
+
+	// Событие уже применено, поэтому отметка сохраняется, даже если отправитель отключился
+	if err := s.repo.Complete(context.WithoutCancel(ctx), event.Source, event.ID, subscriptionID); err != nil {
+		// Повтор до истечения inboundLockTimeout получит "in progress", после - применится снова
+		s.logger.Error(ctx, "Failed to mark inbound event as processed",
+			"source", event.Source,
+			"event_id", event.ID,
+			"error", err,
+		)
+	}
+	s.counters.Inc(event.Source, metrics.InboundProcessed)
+
+	result.Status = model.InboundProcessed
+	result.SubscriptionID = subscriptionID
+	return result, nil
+}
+
+// apply применяет данные события к подпискам и возвращает затронутую подписку
+func (s *inboundService) apply(ctx context.Context, payload interface{}) (*uuid.UUID, error) {
+	switch data := payload.(type) {
+	case *model.CreateSubscriptionRequest:
+		sub, err := s.subscriptions.CreateSubscription(ctx, *data)
+		if err != nil {
+			return nil, err
+		}
+		return &sub.ID, nil
+	case *model.InboundSubscriptionUpdate:
+		if err := s.subscriptions.UpdateSubscription(ctx, data.ID, data.UpdateSubscriptionRequest); err != nil {
+			return nil, err
+		}
+		return &data.ID, nil
+	case *model.InboundSubscriptionCancel:
+		if _, err := s.subscriptions.CancelSubscription(ctx, data.ID, data.CancelSubscriptionRequest); err != nil {
+			return nil, err
+		}
+		return &data.ID, nil
+	case *model.InboundSubscriptionRef:
+		if err := s.subscriptions.DeleteSubscription(ctx, data.ID); err != nil {
+			return nil, err
+		}
+		return &data.ID, nil
+	default:
+		return nil, fmt.Errorf("unexpected inbound event data %T", payload)
+	}
+}
+
+func (s *inboundService) ListDeduplicated(ctx context.Context, limit int) ([]model.InboundRecord, error) {
+	if limit < 1 || limit > maxDeduplicatedEvents {
+		return nil, model.Invalid(model.ErrInvalidInput, "limit must be between 1 and %d", maxDeduplicatedEvents)
+	}
+
+	records, err := s.repo.ListDuplicates(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deduplicated inbound events: %w", err)
+	}
+	return records, nil
+}
+
+func (s *inboundService) Purge(ctx context.Context) error {
+	deleted, err := s.repo.DeleteProcessedBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge inbound events: %w", err)
+	}
+
+	s.logger.Info(ctx, "Purged processed inbound events",
+		"deleted", deleted,
+	)
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// inboundRepoStub хранит события в памяти, как таблица inbound_events одной организации
+type inboundRepoStub struct {
+	records  map[string]*model.InboundRecord
+	released []string
+}
+
+func (r *inboundRepoStub) Claim(ctx context.Context, record *model.InboundRecord) (*model.InboundRecord, error) {
+	if existing, ok := r.records[record.Source+"/"+record.EventID]; ok {
+		if existing.Status == model.InboundProcessed {
+			existing.Duplicates++
+		}
+		copied := *existing
+		return &copied, nil
+	}
+	claimed := *record
+	claimed.Status = model.InboundProcessing
+	r.records[record.Source+"/"+record.EventID] = &claimed
+	return nil, nil
+}
+
+func (r *inboundRepoStub) Complete(ctx context.Context, source, eventID string, subscriptionID *uuid.UUID) error {
+	stored := r.records[source+"/"+eventID]
+	stored.Status = model.InboundProcessed
+	stored.SubscriptionID = subscriptionID
+	return nil
+}
+
+func (r *inboundRepoStub) Release(ctx context.Context, source, eventID string) error {
+	delete(r.records, source+"/"+eventID)
+	r.released = append(r.released, eventID)
+	return nil
+}
+
+func (r *inboundRepoStub) ListDuplicates(ctx context.Context, limit int) ([]model.InboundRecord, error) {
+	return nil, nil
+}
+
+func (r *inboundRepoStub) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func inboundEvent(t *testing.T, id, eventType string, data interface{}) model.InboundEvent {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.InboundEvent{ID: id, Source: "billing", Type: eventType, Data: raw}
+}
+
+func TestInboundConsume(t *testing.T) {
+	ctx := context.Background()
+	repo := &inboundRepoStub{records: map[string]*model.InboundRecord{}}
+	subscriptions := repository.NewInMemorySubscriptionRepository()
+	counters := metrics.NewInboundEvents()
+	svc := NewInboundService(repo, newExportTestService(subscriptions), counters, 24*time.Hour, logger.New(slog.LevelError+4))
+
+	created := inboundEvent(t, "evt-1", model.InboundSubscriptionCreated, model.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		MonthlyCost: 500,
+		UserID:      uuid.New(),
+		StartDate:   "07-2025",
+	})
+	result, err := svc.Consume(ctx, created)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if result.Status != model.InboundProcessed || result.SubscriptionID == nil {
+		t.Fatalf("Consume() = %+v, want processed with subscription", result)
+	}
+
+	// Повтор доставки не создает вторую подписку и возвращает ту же подписку
+	replay, err := svc.Consume(ctx, created)
+	if err != nil {
+		t.Fatalf("Consume() replay error = %v", err)
+	}
+	if replay.Status != model.InboundDuplicate || replay.SubscriptionID == nil || *replay.SubscriptionID != *result.SubscriptionID {
+		t.Errorf("Consume() replay = %+v, want duplicate of %s", replay, result.SubscriptionID)
+	}
+	if _, total, err := subscriptions.List(ctx, model.SubscriptionFilter{}, model.Pagination{Limit: 10}); err != nil || total != 1 {
+		t.Errorf("subscriptions after replay = %d, %v, want 1", total, err)
+	}
+
+	// Повтор события, которое еще обрабатывается, отклоняется без применения
+	repo.records["billing/evt-2"] = &model.InboundRecord{Source: "billing", EventID: "evt-2", Status: model.InboundProcessing}
+	deleted := inboundEvent(t, "evt-2", model.InboundSubscriptionDeleted, model.InboundSubscriptionRef{ID: *result.SubscriptionID})
+	if _, err := svc.Consume(ctx, deleted); !errors.Is(err, ErrInboundEventInProgress) {
+		t.Errorf("Consume() in progress error = %v, want ErrInboundEventInProgress", err)
+	}
+
+	// Событие, которое не удалось применить, освобождается для повторной доставки
+	missing := inboundEvent(t, "evt-3", model.InboundSubscriptionDeleted, model.InboundSubscriptionRef{ID: uuid.New()})
+	if _, err := svc.Consume(ctx, missing); err == nil {
+		t.Error("Consume() for missing subscription error = nil, want error")
+	}
+	if len(repo.released) != 1 || repo.released[0] != "evt-3" {
+		t.Errorf("released = %v, want [evt-3]", repo.released)
+	}
+	if _, ok := repo.records["billing/evt-3"]; ok {
+		t.Error("failed event is still claimed")
+	}
+
+	for outcome, want := range map[string]int64{
+		metrics.InboundProcessed:  1,
+		metrics.InboundDuplicate:  1,
+		metrics.InboundInProgress: 1,
+		metrics.InboundFailed:     1,
+	} {
+		if got := counters.Count("billing", outcome); got != want {
+			t.Errorf("counter %s = %d, want %d", outcome, got, want)
+		}
+	}
+}
+
+func TestInboundConsumeRejectsUnknownData(t *testing.T) {
+	repo := &inboundRepoStub{records: map[string]*model.InboundRecord{}}
+	svc := NewInboundService(repo, newExportTestService(repository.NewInMemorySubscriptionRepository()), nil, 24*time.Hour, logger.New(slog.LevelError+4))
+
+	event := model.InboundEvent{ID: "evt-1", Source: "billing", Type: model.InboundSubscriptionDeleted, Data: json.RawMessage(`{"id":"` + uuid.NewString() + `","force":true}`)}
+	if _, err := svc.Consume(context.Background(), event); !errors.Is(err, model.ErrInvalidInput) {
+		t.Errorf("Consume() error = %v, want ErrInvalidInput", err)
+	}
+	if len(repo.records) != 0 {
+		t.Errorf("invalid event was claimed: %v", repo.records)
+	}
+}
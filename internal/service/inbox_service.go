@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type InboxService interface {
+	// List возвращает уведомления пользователя, начиная с последних, и число непрочитанных
+	List(ctx context.Context, userID uuid.UUID, filter model.InboxFilter, page model.Pagination) (*model.InboxPage, error)
+	// MarkRead отмечает прочитанным уведомление id пользователя userID; уведомление
+	// другого пользователя не отмечается - ErrNotificationNotFound
+	MarkRead(ctx context.Context, userID, id uuid.UUID) (*model.InboxNotification, error)
+	// MarkAllRead отмечает прочитанными все уведомления пользователя и возвращает их число
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// NotifyRenewal записывает уведомление expiring_soon; канал напоминаний о продлении
+	NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error
+	// NotifyAnomaly записывает уведомление spend_anomaly; одно на пользователя и месяц
+	NotifyAnomaly(ctx context.Context, anomaly model.SpendAnomaly) error
+	// NotifyPriceIncrease записывает уведомление price_increase о росте стоимости подписки
+	NotifyPriceIncrease(ctx context.Context, sub *model.Subscription, previousCost int) error
+}
+
+type inboxService struct {
+	repo   repository.InboxRepository
+	logger *logger.Logger
+}
+
+func NewInboxService(repo repository.InboxRepository, logger *logger.Logger) InboxService {
+	return &inboxService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *inboxService) List(ctx context.Context, userID uuid.UUID, filter model.InboxFilter, page model.Pagination) (*model.InboxPage, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.List(ctx, userID, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return result, nil
+}
+
+func (s *inboxService) MarkRead(ctx context.Context, userID, id uuid.UUID) (*model.InboxNotification, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	// Репозиторий отмечает уведомление, только если оно принадлежит userID: чужое
+	// уведомление для пользователя не существует
+	n, err := s.repo.MarkRead(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return n, nil
+}
+
+func (s *inboxService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info(ctx, "Marking all notifications as read", "user_id", userID)
+
+	marked, err := s.repo.MarkAllRead(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return marked, nil
+}
+
+func (s *inboxService) NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error {
+	message := fmt.Sprintf("Подписка %s заканчивается %s", reminder.ServiceName, reminder.RenewalDate)
+	if reminder.Kind == model.ReminderRenewal {
+		message = fmt.Sprintf("Подписка %s продлится %s", reminder.ServiceName, reminder.RenewalDate)
+	}
+
+	subscriptionID := reminder.SubscriptionID
+	return s.add(ctx, &model.InboxNotification{
+		UserID:         reminder.UserID,
+		Kind:           model.InboxExpiringSoon,
+		SubscriptionID: &subscriptionID,
+		Message:        message,
+		// Одно уведомление на окончание периода, даже если другой канал напоминаний
+		// вернул ошибку и напоминание повторится
+		DedupKey: fmt.Sprintf("%s:%s:%s", model.InboxExpiringSoon, reminder.SubscriptionID, reminder.RenewsAt.Format(time.DateOnly)),
+	}, reminder)
+}
+
+func (s *inboxService) NotifyAnomaly(ctx context.Context, anomaly model.SpendAnomaly) error {
+	month := anomaly.Month.Format("01-2006")
+	message := fmt.Sprintf("Траты на подписки в %s составили %d ₽, на %d%% выше среднего (%d ₽)",
+		month, anomaly.Spend, anomaly.DeviationPercent, anomaly.TrailingAverage)
+	if anomaly.TrailingAverage == 0 {
+		message = fmt.Sprintf("Траты на подписки в %s составили %d ₽, в предыдущие месяцы трат не было",
+			month, anomaly.Spend)
+	}
+	return s.add(ctx, &model.InboxNotification{
+		UserID:  anomaly.UserID,
+		Kind:    model.InboxSpendAnomaly,
+		Message: message,
+		// Проверка повторяется в течение месяца, уведомление о месяце - одно
+		DedupKey: fmt.Sprintf("%s:%s", model.InboxSpendAnomaly, month),
+	}, anomaly)
+}
+
+func (s *inboxService) NotifyPriceIncrease(ctx context.Context, sub *model.Subscription, previousCost int) error {
+	subscriptionID := sub.ID
+	return s.add(ctx, &model.InboxNotification{
+		UserID:         sub.UserID,
+		Kind:           model.InboxPriceIncrease,
+		SubscriptionID: &subscriptionID,
+		Message: fmt.Sprintf("Стоимость подписки %s выросла с %d до %d ₽ в месяц",
+			sub.ServiceName, previousCost, sub.MonthlyCost),
+	}, map[string]interface{}{
+		"service_name":  sub.ServiceName,
+		"previous_cost": previousCost,
+		"monthly_cost":  sub.MonthlyCost,
+	})
+}
+
+// add записывает уведомление с данными data
+func (s *inboxService) add(ctx context.Context, n *model.InboxNotification, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s notification: %w", n.Kind, err)
+	}
+	n.Data = encoded
+
+	added, err := s.repo.Add(ctx, n)
+	if err != nil {
+		return fmt.Errorf("failed to add %s notification: %w", n.Kind, err)
+	}
+	if !added {
+		s.logger.Debug(ctx, "Notification already in inbox, skipped",
+			"user_id", n.UserID,
+			"kind", n.Kind,
+		)
+	}
+	return nil
+}
+
+// inboxSubscriptionService записывает уведомление о повышении цены после изменения подписки
+type inboxSubscriptionService struct {
+	SubscriptionService
+	inbox  InboxService
+	logger *logger.Logger
+}
+
+// NewInboxSubscriptionService оборачивает сервис подписок уведомлениями price_increase:
+// уведомление записывается при росте monthly_cost в UpdateSubscription, ошибка записи
+// только пишется в лог
+func NewInboxSubscriptionService(next SubscriptionService, inbox InboxService, logger *logger.Logger) SubscriptionService {
+	return &inboxSubscriptionService{
+		SubscriptionService: next,
+		inbox:               inbox,
+		logger:              logger,
+	}
+}
+
+func (s *inboxSubscriptionService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+	existing, err := s.SubscriptionService.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	updated := *existing
+	if err := s.SubscriptionService.UpdateSubscription(ctx, id, req); err != nil {
+		return err
+	}
+	if req.MonthlyCost <= updated.MonthlyCost {
+		return nil
+	}
+
+	previousCost := updated.MonthlyCost
+	updated.ServiceName = req.ServiceName
+	updated.MonthlyCost = req.MonthlyCost
+	updated.UserID = req.UserID
+	// Подписка уже изменена: ошибка записи уведомления не должна превращаться в ошибку запроса
+	if err := s.inbox.NotifyPriceIncrease(ctx, &updated, previousCost); err != nil {
+		s.logger.Error(ctx, "Failed to add price increase notification",
+			"subscription_id", id,
+			"error", err,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// inboxRepoStub хранит уведомления в памяти и пропускает повторы по DedupKey
+type inboxRepoStub struct {
+	repository.InboxRepository
+	added []*model.InboxNotification
+}
+
+func (r *inboxRepoStub) Add(ctx context.Context, n *model.InboxNotification) (bool, error) {
+	for _, existing := range r.added {
+		if n.DedupKey != "" && existing.UserID == n.UserID && existing.DedupKey == n.DedupKey {
+			return false, nil
+		}
+	}
+	n.ID = uuid.New()
+	r.added = append(r.added, n)
+	return true, nil
+}
+
+// MarkRead отмечает уведомление, только если оно принадлежит userID, как и запрос в базе
+func (r *inboxRepoStub) MarkRead(ctx context.Context, userID, id uuid.UUID) (*model.InboxNotification, error) {
+	for _, n := range r.added {
+		if n.ID == id && n.UserID == userID {
+			if n.ReadAt == nil {
+				now := time.Now()
+				n.ReadAt = &now
+			}
+			return n, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *inboxRepoStub) List(ctx context.Context, userID uuid.UUID, filter model.InboxFilter, page model.Pagination) (*model.InboxPage, error) {
+	result := &model.InboxPage{Items: []*model.InboxNotification{}}
+	for _, n := range r.added {
+		if n.UserID == userID {
+			result.Items = append(result.Items, n)
+		}
+	}
+	return result, nil
+}
+
+func TestInboxDeduplicatesGeneratedNotifications(t *testing.T) {
+	repo := &inboxRepoStub{}
+	inbox := NewInboxService(repo, logger.New(slog.LevelError+4))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	reminder := model.RenewalReminder{
+		SubscriptionID: uuid.New(),
+		UserID:         userID,
+		ServiceName:    "Yandex Plus",
+		Kind:           model.ReminderExpiring,
+		RenewsAt:       time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC),
+		RenewalDate:    "01-08-2025",
+	}
+	anomaly := model.SpendAnomaly{UserID: userID, Month: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), Spend: 2100, TrailingAverage: 1200, DeviationPercent: 75}
+	for i := 0; i < 2; i++ {
+		if err := inbox.NotifyRenewal(ctx, reminder); err != nil {
+			t.Fatalf("NotifyRenewal() error = %v", err)
+		}
+		if err := inbox.NotifyAnomaly(ctx, anomaly); err != nil {
+			t.Fatalf("NotifyAnomaly() error = %v", err)
+		}
+	}
+
+	if len(repo.added) != 2 {
+		t.Fatalf("added %d notifications, want 2", len(repo.added))
+	}
+	if got := repo.added[0]; got.Kind != model.InboxExpiringSoon || got.Message != "Подписка Yandex Plus заканчивается 01-08-2025" {
+		t.Errorf("renewal notification = %s %q", got.Kind, got.Message)
+	}
+	if got := repo.added[1]; got.Kind != model.InboxSpendAnomaly || got.SubscriptionID != nil {
+		t.Errorf("anomaly notification = %s, subscription %v", got.Kind, got.SubscriptionID)
+	}
+
+	// Без трат в предыдущих месяцах уведомление не сравнивает траты со средним
+	noHistory := model.SpendAnomaly{UserID: userID, Month: time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC), Spend: 1500}
+	if err := inbox.NotifyAnomaly(ctx, noHistory); err != nil {
+		t.Fatalf("NotifyAnomaly() error = %v", err)
+	}
+	if got := repo.added[len(repo.added)-1]; got.Message != "Траты на подписки в 08-2025 составили 1500 ₽, в предыдущие месяцы трат не было" {
+		t.Errorf("anomaly without history message = %q", got.Message)
+	}
+
+	if _, err := inbox.MarkRead(ctx, userID, uuid.New()); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("MarkRead() error = %v, want ErrNotificationNotFound", err)
+	}
+}
+
+// TestInboxScopedToCaller проверяет, что пользователь без прав администратора не видит
+// чужие уведомления и не отмечает их прочитанными
+func TestInboxScopedToCaller(t *testing.T) {
+	repo := &inboxRepoStub{}
+	inbox := NewInboxService(repo, logger.New(slog.LevelError+4))
+	self := uuid.New()
+	other := uuid.New()
+
+	anomaly := model.SpendAnomaly{UserID: other, Month: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), Spend: 2100, TrailingAverage: 1200, DeviationPercent: 75}
+	if err := inbox.NotifyAnomaly(context.Background(), anomaly); err != nil {
+		t.Fatalf("NotifyAnomaly() error = %v", err)
+	}
+	foreign := repo.added[0]
+
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: self})
+	if _, err := inbox.List(ctx, other, model.InboxFilter{}, model.Pagination{Limit: 10}); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("List() of other user error = %v, want forbidden", err)
+	}
+	if _, err := inbox.MarkAllRead(ctx, other); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("MarkAllRead() of other user error = %v, want forbidden", err)
+	}
+	if _, err := inbox.MarkRead(ctx, other, foreign.ID); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("MarkRead() of other user error = %v, want forbidden", err)
+	}
+	// Чужое уведомление по своему пути не найдено
+	if _, err := inbox.MarkRead(ctx, self, foreign.ID); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("MarkRead() of foreign notification error = %v, want ErrNotificationNotFound", err)
+	}
+	if foreign.ReadAt != nil {
+		t.Error("foreign notification marked as read")
+	}
+
+	page, err := inbox.List(ctx, self, model.InboxFilter{}, model.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("List() own error = %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("own inbox contains %d notifications, want 0", len(page.Items))
+	}
+}
+
+func TestInboxSubscriptionServiceNotifiesPriceIncrease(t *testing.T) {
+	userID := uuid.New()
+	end := model.CurrentMonth().AddDate(1, 0, 0)
+
+	for _, tt := range []struct {
+		name      string
+		cost      int
+		wantAdded int
+	}{
+		{"increase", 500, 1},
+		{"same price", 400, 0},
+		{"decrease", 300, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updateRepoStub{existing: &model.Subscription{
+				ID:          uuid.New(),
+				ServiceName: "Yandex Plus",
+				MonthlyCost: 400,
+				UserID:      userID,
+				StartDate:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:     &end,
+			}}
+			inboxRepo := &inboxRepoStub{}
+			log := logger.New(slog.LevelError + 4)
+			svc := NewInboxSubscriptionService(newExportTestService(repo), NewInboxService(inboxRepo, log), log)
+
+			err := svc.UpdateSubscription(context.Background(), repo.existing.ID, model.UpdateSubscriptionRequest{
+				ServiceName: "Yandex Plus",
+				MonthlyCost: tt.cost,
+				UserID:      userID,
+				StartDate:   "07-2025",
+				EndDate:     strPtr(end.Format("01-2006")),
+			})
+			if err != nil {
+				t.Fatalf("UpdateSubscription() error = %v", err)
+			}
+			if len(inboxRepo.added) != tt.wantAdded {
+				t.Fatalf("added %d notifications, want %d", len(inboxRepo.added), tt.wantAdded)
+			}
+			if tt.wantAdded == 0 {
+				return
+			}
+			n := inboxRepo.added[0]
+			if n.Kind != model.InboxPriceIncrease || n.Message != "Стоимость подписки Yandex Plus выросла с 400 до 500 ₽ в месяц" {
+				t.Errorf("notification = %s %q", n.Kind, n.Message)
+			}
+			if n.SubscriptionID == nil || *n.SubscriptionID != repo.existing.ID {
+				t.Errorf("subscription_id = %v, want %s", n.SubscriptionID, repo.existing.ID)
+			}
+		})
+	}
+}
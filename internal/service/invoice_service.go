@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceService interface {
+	// GenerateInvoice рассчитывает и сохраняет счет пользователя за месяц
+	GenerateInvoice(ctx context.Context, userID uuid.UUID, period string) (*model.Invoice, error)
+	// GetInvoice возвращает счет; счет другого пользователя для пользователя без прав
+	// администратора не существует
+	GetInvoice(ctx context.Context, id uuid.UUID) (*model.Invoice, error)
+	ListInvoices(ctx context.Context, userID uuid.UUID) ([]*model.Invoice, error)
+}
+
+type invoiceService struct {
+	repo          repository.InvoiceRepository
+	subscriptions repository.SubscriptionRepository
+	taxes         TaxResolver
+	logger        *logger.Logger
+}
+
+// NewInvoiceService создает сервис счетов; taxes выбирает ставку и округление организации
+func NewInvoiceService(repo repository.InvoiceRepository, subscriptions repository.SubscriptionRepository, taxes TaxResolver, logger *logger.Logger) InvoiceService {
+	return &invoiceService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		taxes:         taxes,
+		logger:        logger,
+	}
+}
+
+func (s *invoiceService) GenerateInvoice(ctx context.Context, userID uuid.UUID, period string) (*model.Invoice, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Generating invoice",
+		"user_id", userID,
+		"period", period,
+	)
+
+	month, err := model.ParseMonthYear(period)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid period format, expected MM-YYYY: %w", err)
+	}
+
+	existing, err := s.repo.GetByPeriod(ctx, userID, month)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check existing invoice",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to check invoice: %w", err)
+	}
+	if existing != nil {
+		s.logger.Warn(ctx, "Invoice for period already exists",
+			"user_id", userID,
+			"invoice_id", existing.ID,
+		)
+		return nil, ErrInvoiceAlreadyExists
+	}
+
+	charges, err := s.subscriptions.MonthlyCharges(ctx, userID, month)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get monthly charges",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate invoice: %w", err)
+	}
+
+	policy, err := s.taxes.Tax(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to resolve tax policy",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate invoice: %w", err)
+	}
+
+	invoice := buildInvoice(userID, month, charges, policy)
+
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrInvoiceAlreadyExists
+		}
+		s.logger.Error(ctx, "Failed to save invoice",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	s.logger.Info(ctx, "Invoice generated successfully",
+		"invoice_id", invoice.ID,
+		"lines", len(invoice.Lines),
+		"gross_total", invoice.GrossTotal,
+	)
+
+	return invoice, nil
+}
+
+// buildInvoice округляет начисления построчно и раскладывает их по налогу.
+// Итоги считаются суммой округленных строк, чтобы счет сходился по строкам
+func buildInvoice(userID uuid.UUID, month time.Time, charges []model.MonthlyCharge, policy money.Tax) *model.Invoice {
+	invoice := &model.Invoice{
+		UserID:  userID,
+		Period:  month,
+		TaxRate: policy.RatePercent(),
+		Lines:   make([]model.InvoiceLine, 0, len(charges)),
+	}
+
+	for _, charge := range charges {
+		base := money.Round(charge.Base, policy.Rounding)
+		amount := money.Round(charge.Amount, policy.Rounding)
+		net, tax, gross := policy.Split(amount)
+
+		invoice.Lines = append(invoice.Lines, model.InvoiceLine{
+			SubscriptionID: charge.SubscriptionID,
+			ServiceName:    charge.ServiceName,
+			BaseAmount:     base,
+			DiscountAmount: base - amount,
+			NetAmount:      net,
+			TaxAmount:      tax,
+			GrossAmount:    gross,
+		})
+
+		invoice.BaseTotal += base
+		invoice.DiscountTotal += base - amount
+		invoice.NetTotal += net
+		invoice.TaxTotal += tax
+		invoice.GrossTotal += gross
+	}
+
+	return invoice
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, id uuid.UUID) (*model.Invoice, error) {
+	invoice, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get invoice from repository",
+			"invoice_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if invoice == nil || !auth.CanAccessUser(ctx, invoice.UserID) {
+		s.logger.Warn(ctx, "Invoice not found", "invoice_id", id)
+		return nil, ErrInvoiceNotFound
+	}
+
+	return invoice, nil
+}
+
+func (s *invoiceService) ListInvoices(ctx context.Context, userID uuid.UUID) ([]*model.Invoice, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list invoices from repository",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	return invoices, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type invoiceRepoStub struct {
+	repository.InvoiceRepository
+	existing *model.Invoice
+	created  *model.Invoice
+}
+
+func (r *invoiceRepoStub) GetByPeriod(ctx context.Context, userID uuid.UUID, period time.Time) (*model.Invoice, error) {
+	return r.existing, nil
+}
+
+func (r *invoiceRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error) {
+	if r.existing == nil || r.existing.ID != id {
+		return nil, nil
+	}
+	return r.existing, nil
+}
+
+func (r *invoiceRepoStub) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Invoice, error) {
+	if r.existing == nil || r.existing.UserID != userID {
+		return []*model.Invoice{}, nil
+	}
+	return []*model.Invoice{r.existing}, nil
+}
+
+func (r *invoiceRepoStub) Create(ctx context.Context, invoice *model.Invoice) error {
+	invoice.ID = uuid.New()
+	r.created = invoice
+	return nil
+}
+
+type chargesRepoStub struct {
+	repository.SubscriptionRepository
+	charges []model.MonthlyCharge
+}
+
+func (r *chargesRepoStub) MonthlyCharges(ctx context.Context, userID uuid.UUID, month time.Time) ([]model.MonthlyCharge, error) {
+	return r.charges, nil
+}
+
+func TestGenerateInvoice(t *testing.T) {
+	tax, err := money.NewTax("20", false, "half_up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	charges := &chargesRepoStub{charges: []model.MonthlyCharge{
+		// 20% скидки
+		{SubscriptionID: uuid.New(), ServiceName: "Netflix", Base: big.NewRat(400, 1), Amount: big.NewRat(320, 1)},
+		// Годовая предоплата 3590 / 12
+		{SubscriptionID: uuid.New(), ServiceName: "Yandex Plus", Base: big.NewRat(3590, 12), Amount: big.NewRat(3590, 12)},
+	}}
+	repo := &invoiceRepoStub{}
+	svc := NewInvoiceService(repo, charges, FixedTax(tax), logger.New(slog.LevelError+4))
+
+	invoice, err := svc.GenerateInvoice(context.Background(), uuid.New(), "07-2025")
+	if err != nil {
+		t.Fatalf("GenerateInvoice() error = %v", err)
+	}
+	if repo.created != invoice {
+		t.Fatal("invoice was not saved")
+	}
+
+	wantLines := []model.InvoiceLine{
+		{BaseAmount: 400, DiscountAmount: 80, NetAmount: 320, TaxAmount: 64, GrossAmount: 384},
+		{BaseAmount: 299, DiscountAmount: 0, NetAmount: 299, TaxAmount: 60, GrossAmount: 359},
+	}
+	if len(invoice.Lines) != len(wantLines) {
+		t.Fatalf("got %d lines, want %d", len(invoice.Lines), len(wantLines))
+	}
+	for i, want := range wantLines {
+		got := invoice.Lines[i]
+		want.SubscriptionID, want.ServiceName = got.SubscriptionID, got.ServiceName
+		if got != want {
+			t.Errorf("line %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	if invoice.BaseTotal != 699 || invoice.DiscountTotal != 80 || invoice.NetTotal != 619 ||
+		invoice.TaxTotal != 124 || invoice.GrossTotal != 743 || invoice.TaxRate != "20.00" {
+		t.Errorf("totals = %+v", invoice)
+	}
+
+	repo.existing = invoice
+	if _, err := svc.GenerateInvoice(context.Background(), invoice.UserID, "07-2025"); err == nil || err.Error() != "invoice already exists" {
+		t.Errorf("second GenerateInvoice() error = %v, want invoice already exists", err)
+	}
+}
+
+// TestGenerateInvoiceTenantTax проверяет, что ставка и округление организации заменяют
+// значения из конфигурации, а организация без настроек считается по конфигурации
+func TestGenerateInvoiceTenantTax(t *testing.T) {
+	tax, err := money.NewTax("20", false, "half_up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, rounding := "10", "half_even"
+	tenants := &tenantRepoStub{settings: map[string]*model.TenantSettings{
+		"acme": {TaxRatePercent: &rate, Rounding: &rounding},
+	}}
+	charges := &chargesRepoStub{charges: []model.MonthlyCharge{
+		{SubscriptionID: uuid.New(), ServiceName: "Netflix", Base: big.NewRat(225, 2), Amount: big.NewRat(225, 2)},
+	}}
+	svc := NewInvoiceService(&invoiceRepoStub{}, charges, NewTenantTaxResolver(tenants, tax, logger.New(slog.LevelError+4)), logger.New(slog.LevelError+4))
+
+	tests := []struct {
+		tenant   string
+		wantRate string
+		wantLine model.InvoiceLine
+	}{
+		{tenant: "acme", wantRate: "10.00", wantLine: model.InvoiceLine{BaseAmount: 112, NetAmount: 112, TaxAmount: 11, GrossAmount: 123}},
+		{tenant: "default", wantRate: "20.00", wantLine: model.InvoiceLine{BaseAmount: 113, NetAmount: 113, TaxAmount: 23, GrossAmount: 136}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tenant, func(t *testing.T) {
+			invoice, err := svc.GenerateInvoice(ctxutil.WithTenantID(context.Background(), tt.tenant), uuid.New(), "07-2025")
+			if err != nil {
+				t.Fatalf("GenerateInvoice() error = %v", err)
+			}
+			got := invoice.Lines[0]
+			tt.wantLine.SubscriptionID, tt.wantLine.ServiceName = got.SubscriptionID, got.ServiceName
+			if invoice.TaxRate != tt.wantRate || got != tt.wantLine {
+				t.Errorf("invoice = rate %s, line %+v, want rate %s, line %+v", invoice.TaxRate, got, tt.wantRate, tt.wantLine)
+			}
+		})
+	}
+}
+
+// TestInvoicesScopedToCaller проверяет, что пользователь без прав администратора не
+// выставляет, не видит и не выгружает счета другого пользователя
+func TestInvoicesScopedToCaller(t *testing.T) {
+	tax, err := money.NewTax("20", false, "half_up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, stranger := uuid.New(), uuid.New()
+	foreign := &model.Invoice{ID: uuid.New(), UserID: owner, Period: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)}
+	repo := &invoiceRepoStub{existing: foreign}
+	svc := NewInvoiceService(repo, &chargesRepoStub{}, FixedTax(tax), logger.New(slog.LevelError+4))
+
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: stranger})
+	if _, err := svc.GenerateInvoice(ctx, owner, "08-2025"); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("GenerateInvoice() for other user error = %v, want forbidden", err)
+	}
+	if repo.created != nil {
+		t.Error("invoice for other user was saved")
+	}
+	if _, err := svc.ListInvoices(ctx, owner); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("ListInvoices() of other user error = %v, want forbidden", err)
+	}
+	if _, err := svc.GetInvoice(ctx, foreign.ID); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Errorf("GetInvoice() of foreign invoice error = %v, want ErrInvoiceNotFound", err)
+	}
+
+	ctx = auth.WithCaller(context.Background(), auth.Caller{UserID: owner})
+	if invoice, err := svc.GetInvoice(ctx, foreign.ID); err != nil || invoice != foreign {
+		t.Errorf("GetInvoice() by owner = %+v, %v", invoice, err)
+	}
+	if invoices, err := svc.ListInvoices(ctx, owner); err != nil || len(invoices) != 1 {
+		t.Errorf("ListInvoices() by owner = %d invoices, %v", len(invoices), err)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/notifier"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// cancellationEmailTimeout ограничивает отправку письма об отмене после ответа на запрос
+const cancellationEmailTimeout = 30 * time.Second
+
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, userID uuid.UUID, req model.SaveNotificationPreferencesRequest) (*model.NotificationPreferences, error)
+	DeletePreferences(ctx context.Context, userID uuid.UUID) error
+
+	// NotifyRenewal отправляет письмо renewal_reminder; канал email напоминаний о продлении
+	NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error
+	// NotifyCancellation отправляет письмо cancellation об отмененной подписке
+	NotifyCancellation(ctx context.Context, sub *model.Subscription) error
+}
+
+type notificationService struct {
+	repo      repository.NotificationRepository
+	templates TemplateService
+	// sender - nil, если письма отключены (EMAIL_DRIVER не задан)
+	sender notifier.Sender
+	logger *logger.Logger
+}
+
+func NewNotificationService(repo repository.NotificationRepository, templates TemplateService, sender notifier.Sender, logger *logger.Logger) NotificationService {
+	return &notificationService{
+		repo:      repo,
+		templates: templates,
+		sender:    sender,
+		logger:    logger,
+	}
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if prefs == nil {
+		return nil, ErrNotificationPreferencesNotFound
+	}
+	return prefs, nil
+}
+
+func (s *notificationService) SavePreferences(ctx context.Context, userID uuid.UUID, req model.SaveNotificationPreferencesRequest) (*model.NotificationPreferences, error) {
+	// Чужие настройки перенаправили бы письма пользователя на другой адрес
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Saving notification preferences", "user_id", userID)
+
+	prefs := &model.NotificationPreferences{
+		UserID:           userID,
+		Email:            req.Email,
+		RenewalReminders: req.RenewalReminders == nil || *req.RenewalReminders,
+		Cancellations:    req.Cancellations == nil || *req.Cancellations,
+	}
+	if err := s.repo.Save(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+func (s *notificationService) DeletePreferences(ctx context.Context, userID uuid.UUID) error {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Deleting notification preferences", "user_id", userID)
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+	return nil
+}
+
+func (s *notificationService) NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error {
+	prefs, err := s.recipient(ctx, reminder.UserID)
+	if err != nil || prefs == nil || !prefs.RenewalReminders {
+		return err
+	}
+
+	return s.send(ctx, prefs.Email, "renewal_reminder", map[string]interface{}{
+		"ServiceName": reminder.ServiceName,
+		"RenewalDate": reminder.RenewalDate,
+		"MonthlyCost": reminder.MonthlyCost,
+	})
+}
+
+func (s *notificationService) NotifyCancellation(ctx context.Context, sub *model.Subscription) error {
+	prefs, err := s.recipient(ctx, sub.UserID)
+	if err != nil || prefs == nil || !prefs.Cancellations {
+		return err
+	}
+
+	// Отмена всегда задает end_date - последний оплаченный месяц
+	endDate := ""
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.Format("01-2006")
+	}
+	return s.send(ctx, prefs.Email, "cancellation", map[string]interface{}{
+		"ServiceName": sub.ServiceName,
+		"EndDate":     endDate,
+	})
+}
+
+// recipient возвращает настройки получателя письма или nil, если письма отключены
+// или пользователь не указал адрес
+func (s *notificationService) recipient(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	if s.sender == nil {
+		return nil, nil
+	}
+	prefs, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if prefs == nil {
+		s.logger.Debug(ctx, "User has no notification preferences, email skipped", "user_id", userID)
+	}
+	return prefs, nil
+}
+
+func (s *notificationService) send(ctx context.Context, to, templateName string, data map[string]interface{}) error {
+	rendered, err := s.templates.Render(ctx, templateName, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", templateName, err)
+	}
+
+	if err := s.sender.Send(ctx, notifier.Message{To: to, Subject: rendered.Subject, HTML: rendered.Body}); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", templateName, err)
+	}
+
+	s.logger.Info(ctx, "Email sent", "template", templateName)
+	return nil
+}
+
+// notifyingSubscriptionService отправляет письмо об отмене подписки после успешной отмены
+type notifyingSubscriptionService struct {
+	SubscriptionService
+	notifications NotificationService
+	logger        *logger.Logger
+}
+
+// NewNotifyingSubscriptionService оборачивает сервис подписок письмами об отмене. Письмо
+// отправляется в фоне: ответ на отмену не ждет почтовый сервер, а ошибка отправки
+// только записывается в лог
+func NewNotifyingSubscriptionService(next SubscriptionService, notifications NotificationService, logger *logger.Logger) SubscriptionService {
+	return &notifyingSubscriptionService{
+		SubscriptionService: next,
+		notifications:       notifications,
+		logger:              logger,
+	}
+}
+
+func (s *notifyingSubscriptionService) CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+	sub, err := s.SubscriptionService.CancelSubscription(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancellationEmailTimeout)
+		defer cancel()
+		if err := s.notifications.NotifyCancellation(ctx, sub); err != nil {
+			s.logger.Error(ctx, "Failed to send cancellation email",
+				"subscription_id", sub.ID,
+				"error", err,
+			)
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/notifier"
+
+	"github.com/google/uuid"
+)
+
+type notificationRepoStub struct {
+	prefs map[uuid.UUID]*model.NotificationPreferences
+}
+
+func (r *notificationRepoStub) Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	return r.prefs[userID], nil
+}
+
+func (r *notificationRepoStub) Save(ctx context.Context, prefs *model.NotificationPreferences) error {
+	r.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+func (r *notificationRepoStub) Delete(ctx context.Context, userID uuid.UUID) error {
+	delete(r.prefs, userID)
+	return nil
+}
+
+// emptyTemplateRepo - база без сохраненных шаблонов: используются встроенные
+type emptyTemplateRepo struct{}
+
+func (emptyTemplateRepo) Create(ctx context.Context, tmpl *model.EmailTemplate) error { return nil }
+func (emptyTemplateRepo) GetLatest(ctx context.Context, name string) (*model.EmailTemplate, error) {
+	return nil, nil
+}
+func (emptyTemplateRepo) GetVersion(ctx context.Context, name string, version int) (*model.EmailTemplate, error) {
+	return nil, nil
+}
+func (emptyTemplateRepo) ListVersions(ctx context.Context, name string) ([]*model.EmailTemplate, error) {
+	return nil, nil
+}
+func (emptyTemplateRepo) ListLatest(ctx context.Context) ([]*model.EmailTemplate, error) {
+	return nil, nil
+}
+
+type senderStub struct {
+	sent chan notifier.Message
+}
+
+func (s *senderStub) Send(ctx context.Context, msg notifier.Message) error {
+	s.sent <- msg
+	return nil
+}
+
+func newNotificationTestService(t *testing.T, prefs ...*model.NotificationPreferences) (NotificationService, *senderStub) {
+	t.Helper()
+	log := logger.New(slog.LevelError + 4)
+	templates, err := NewTemplateService(emptyTemplateRepo{}, log)
+	if err != nil {
+		t.Fatalf("NewTemplateService() error = %v", err)
+	}
+	repo := &notificationRepoStub{prefs: map[uuid.UUID]*model.NotificationPreferences{}}
+	for _, p := range prefs {
+		repo.prefs[p.UserID] = p
+	}
+	sender := &senderStub{sent: make(chan notifier.Message, 10)}
+	return NewNotificationService(repo, templates, sender, log), sender
+}
+
+func TestNotifyRenewal(t *testing.T) {
+	subscribed := &model.NotificationPreferences{UserID: uuid.New(), Email: "a@example.com", RenewalReminders: true}
+	optedOut := &model.NotificationPreferences{UserID: uuid.New(), Email: "b@example.com", RenewalReminders: false, Cancellations: true}
+	svc, sender := newNotificationTestService(t, subscribed, optedOut)
+
+	for _, userID := range []uuid.UUID{subscribed.UserID, optedOut.UserID, uuid.New()} {
+		err := svc.NotifyRenewal(context.Background(), model.RenewalReminder{
+			UserID:      userID,
+			ServiceName: "Yandex Plus",
+			MonthlyCost: 400,
+			RenewalDate: "01-08-2025",
+		})
+		if err != nil {
+			t.Fatalf("NotifyRenewal() error = %v", err)
+		}
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(sender.sent))
+	}
+	msg := <-sender.sent
+	if msg.To != subscribed.Email || !strings.Contains(msg.Subject, "Yandex Plus") || !strings.Contains(msg.HTML, "01-08-2025") {
+		t.Errorf("unexpected email: %+v", msg)
+	}
+}
+
+func TestCancellationEmail(t *testing.T) {
+	userID := uuid.New()
+	svc, sender := newNotificationTestService(t, &model.NotificationPreferences{UserID: userID, Email: "a@example.com", Cancellations: true})
+
+	endDate := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	subs := &cancelStub{sub: &model.Subscription{ID: uuid.New(), UserID: userID, ServiceName: "Yandex Plus", EndDate: &endDate}}
+	wrapped := NewNotifyingSubscriptionService(subs, svc, logger.New(slog.LevelError+4))
+
+	if _, err := wrapped.CancelSubscription(context.Background(), subs.sub.ID, model.CancelSubscriptionRequest{}); err != nil {
+		t.Fatalf("CancelSubscription() error = %v", err)
+	}
+
+	select {
+	case msg := <-sender.sent:
+		if msg.To != "a@example.com" || !strings.Contains(msg.HTML, "12-2025") {
+			t.Errorf("unexpected email: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancellation email was not sent")
+	}
+}
+
+func TestSavePreferencesDefaults(t *testing.T) {
+	svc, _ := newNotificationTestService(t)
+	userID := uuid.New()
+	off := false
+
+	prefs, err := svc.SavePreferences(context.Background(), userID, model.SaveNotificationPreferencesRequest{Email: "a@example.com", Cancellations: &off})
+	if err != nil {
+		t.Fatalf("SavePreferences() error = %v", err)
+	}
+	if !prefs.RenewalReminders || prefs.Cancellations {
+		t.Errorf("RenewalReminders = %v, Cancellations = %v, want true, false", prefs.RenewalReminders, prefs.Cancellations)
+	}
+
+	if err := svc.DeletePreferences(context.Background(), userID); err != nil {
+		t.Fatalf("DeletePreferences() error = %v", err)
+	}
+	if _, err := svc.GetPreferences(context.Background(), userID); err == nil || err.Error() != "notification preferences not found" {
+		t.Errorf("GetPreferences() error = %v, want not found", err)
+	}
+}
+
+// cancelStub - сервис подписок, в котором отмена возвращает sub
+type cancelStub struct {
+	SubscriptionService
+	sub *model.Subscription
+}
+
+func (s *cancelStub) CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+	return s.sub, nil
+}
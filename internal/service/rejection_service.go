@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+const (
+	// rejectionQueueSize - сколько отклоненных запросов ждет записи; остальные только
+	// учитываются в метриках, чтобы недоступная база не задерживала ответы
+	rejectionQueueSize = 1024
+	// rejectionWriteTimeout ограничивает запись одного отклоненного запроса
+	rejectionWriteTimeout = 5 * time.Second
+	// maxRejectionMessageLength - сколько байт сообщения об ошибке сохраняется
+	maxRejectionMessageLength = 1024
+)
+
+type RejectionService interface {
+	// Record учитывает отклоненный запрос в метриках и ставит его в очередь записи, не блокируясь.
+	// Организация и пользователь берутся из ctx
+	Record(ctx context.Context, rejection model.RejectedRequest)
+	// List возвращает отклоненные запросы организации. Обычный пользователь видит только свои
+	List(ctx context.Context, filter model.RejectionFilter) ([]*model.RejectedRequest, error)
+	// Purge удаляет записи старше срока хранения
+	Purge(ctx context.Context) error
+}
+
+type pendingRejection struct {
+	ctx       context.Context
+	rejection model.RejectedRequest
+}
+
+type rejectionService struct {
+	repo      repository.RejectionRepository
+	counters  *metrics.Rejections
+	retention time.Duration
+	queue     chan pendingRejection
+	logger    *logger.Logger
+}
+
+// NewRejectionService запускает запись отклоненных запросов в фоне. Записи старше
+// retention удаляет Purge
+func NewRejectionService(repo repository.RejectionRepository, counters *metrics.Rejections, retention time.Duration, logger *logger.Logger) RejectionService {
+	s := &rejectionService{
+		repo:      repo,
+		counters:  counters,
+		retention: retention,
+		queue:     make(chan pendingRejection, rejectionQueueSize),
+		logger:    logger,
+	}
+	go s.run()
+	return s
+}
+
+func (s *rejectionService) Record(ctx context.Context, rejection model.RejectedRequest) {
+	s.counters.Inc(rejection.Reason, rejection.Route)
+
+	if rejection.UserID == nil {
+		if caller, ok := auth.CallerFrom(ctx); ok && !caller.Admin {
+			userID := caller.UserID
+			rejection.UserID = &userID
+		}
+	}
+	if len(rejection.Message) > maxRejectionMessageLength {
+		rejection.Message = rejection.Message[:maxRejectionMessageLength]
+	}
+
+	select {
+	// Запись переживает завершение запроса, но сохраняет его организацию и трассу
+	case s.queue <- pendingRejection{ctx: context.WithoutCancel(ctx), rejection: rejection}:
+	default:
+		s.logger.Warn(ctx, "Rejected request queue is full, dropping record",
+			"route", rejection.Route,
+			"reason", rejection.Reason,
+		)
+	}
+}
+
+func (s *rejectionService) run() {
+	for pending := range s.queue {
+		ctx, cancel := context.WithTimeout(pending.ctx, rejectionWriteTimeout)
+		// Ошибку уже записал репозиторий; запись журнала не повторяется
+		_ = s.repo.Create(ctx, &pending.rejection)
+		cancel()
+	}
+}
+
+func (s *rejectionService) List(ctx context.Context, filter model.RejectionFilter) ([]*model.RejectedRequest, error) {
+	userID, err := auth.ScopeUserID(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	rejections, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejected requests: %w", err)
+	}
+	if rejections == nil {
+		rejections = []*model.RejectedRequest{}
+	}
+	return rejections, nil
+}
+
+func (s *rejectionService) Purge(ctx context.Context) error {
+	deleted, err := s.repo.DeleteBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge rejected requests: %w", err)
+	}
+
+	s.logger.Info(ctx, "Purged old rejected requests",
+		"deleted", deleted,
+		"retention", s.retention.String(),
+	)
+	return nil
+}
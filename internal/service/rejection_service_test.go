@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type createdRejection struct {
+	tenant    string
+	rejection model.RejectedRequest
+}
+
+type rejectionRepoStub struct {
+	repository.RejectionRepository
+	created chan createdRejection
+	filter  model.RejectionFilter
+}
+
+func (r *rejectionRepoStub) Create(ctx context.Context, rejection *model.RejectedRequest) error {
+	r.created <- createdRejection{tenant: ctxutil.TenantID(ctx), rejection: *rejection}
+	return nil
+}
+
+func (r *rejectionRepoStub) List(ctx context.Context, filter model.RejectionFilter) ([]*model.RejectedRequest, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func TestRecordRejection(t *testing.T) {
+	repo := &rejectionRepoStub{created: make(chan createdRejection, 1)}
+	counters := metrics.NewRejections()
+	svc := NewRejectionService(repo, counters, 24*time.Hour, logger.New(slog.LevelError+4))
+
+	userID := uuid.New()
+	ctx, cancel := context.WithCancel(ctxutil.WithTenantID(context.Background(), "acme"))
+	ctx = auth.WithCaller(ctx, auth.Caller{UserID: userID, Tenant: "acme"})
+	svc.Record(ctx, model.RejectedRequest{
+		Method:  "POST",
+		Route:   "/api/v1/subscriptions",
+		Status:  400,
+		Reason:  model.RejectionValidation,
+		Message: strings.Repeat("x", 2000),
+	})
+	// Запись не должна зависеть от завершения запроса
+	cancel()
+
+	select {
+	case got := <-repo.created:
+		if got.tenant != "acme" {
+			t.Errorf("tenant = %q, want acme", got.tenant)
+		}
+		if got.rejection.UserID == nil || *got.rejection.UserID != userID {
+			t.Errorf("user_id = %v, want %s", got.rejection.UserID, userID)
+		}
+		if len(got.rejection.Message) != maxRejectionMessageLength {
+			t.Errorf("message length = %d, want %d", len(got.rejection.Message), maxRejectionMessageLength)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("rejected request was not written")
+	}
+
+	var out strings.Builder
+	_ = counters.WritePrometheus(&out)
+	if !strings.Contains(out.String(), `{reason="validation",route="/api/v1/subscriptions"} 1`) {
+		t.Errorf("rejection is not counted:\n%s", out.String())
+	}
+}
+
+func TestListRejectionsScopedToCaller(t *testing.T) {
+	repo := &rejectionRepoStub{}
+	svc := NewRejectionService(repo, nil, 24*time.Hour, logger.New(slog.LevelError+4))
+
+	userID := uuid.New()
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: userID})
+	rejections, err := svc.List(ctx, model.RejectionFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if rejections == nil {
+		t.Error("List() = nil, want empty slice")
+	}
+	if repo.filter.UserID == nil || *repo.filter.UserID != userID {
+		t.Errorf("filter user_id = %v, want %s", repo.filter.UserID, userID)
+	}
+
+	other := uuid.New()
+	if _, err := svc.List(ctx, model.RejectionFilter{UserID: &other}); err == nil || !strings.HasPrefix(err.Error(), "forbidden") {
+		t.Errorf("List() for another user error = %v, want forbidden", err)
+	}
+}
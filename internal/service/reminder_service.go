@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// EventRenewalReminder - тип события о скором окончании периода подписки
+const EventRenewalReminder = "subscription.renewal_reminder"
+
+type ReminderService interface {
+	// RunReminders находит подписки, период которых заканчивается в ближайшие дни, и отправляет
+	// напоминания всеми каналами. Напоминание об одном окончании периода отправляется один раз
+	RunReminders(ctx context.Context) error
+
+	// GetSettings возвращает настройки напоминаний подписки
+	GetSettings(ctx context.Context, subscriptionID uuid.UUID) (*model.ReminderSettings, error)
+	// SaveSettings задает срок и канал напоминаний подписки вместо значений по умолчанию
+	SaveSettings(ctx context.Context, subscriptionID uuid.UUID, req model.SaveReminderSettingsRequest) (*model.ReminderSettings, error)
+	// DeleteSettings возвращает подписке срок и каналы напоминаний по умолчанию
+	DeleteSettings(ctx context.Context, subscriptionID uuid.UUID) error
+}
+
+// ReminderConfig - параметры напоминаний о продлении
+type ReminderConfig struct {
+	// Days - за сколько дней до окончания периода отправляется напоминание
+	Days int
+	// OpenEnded включает напоминания о ежемесячном продлении бессрочных подписок
+	OpenEnded bool
+	// Channels - каналы напоминаний подписок без своего канала
+	Channels []string
+}
+
+// ReminderNotifier доставляет напоминание одним каналом
+type ReminderNotifier interface {
+	NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error
+}
+
+type reminderService struct {
+	repo          repository.ReminderRepository
+	subscriptions repository.SubscriptionRepository
+	cfg           ReminderConfig
+	notifiers     map[string]ReminderNotifier
+	logger        *logger.Logger
+}
+
+// NewReminderService создает сервис напоминаний; notifiers - доступные каналы по именам
+// (model.ReminderChannelLog и другие), из них cfg.Channels - каналы по умолчанию.
+// По subscriptions проверяется владелец подписки в настройках напоминаний
+func NewReminderService(repo repository.ReminderRepository, subscriptions repository.SubscriptionRepository, cfg ReminderConfig, notifiers map[string]ReminderNotifier, logger *logger.Logger) ReminderService {
+	if cfg.Days < 1 {
+		cfg.Days = 1
+	}
+
+	return &reminderService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		cfg:           cfg,
+		notifiers:     notifiers,
+		logger:        logger,
+	}
+}
+
+func (s *reminderService) RunReminders(ctx context.Context) error {
+	// Календарная дата в часовом поясе периодов, как значения DATE из базы
+	now := time.Now().In(model.PeriodLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	window := model.ReminderWindow{After: today, Until: today.AddDate(0, 0, s.cfg.Days)}
+	if s.cfg.OpenEnded {
+		next := model.MonthOf(now).AddDate(0, 1, 0)
+		window.OpenEndedRenewal = &next
+	}
+
+	s.logger.Info(ctx, "Running renewal reminders",
+		"until", window.Until.Format(time.DateOnly),
+		"open_ended", s.cfg.OpenEnded,
+	)
+
+	due, err := s.repo.ListDue(ctx, window)
+	if err != nil {
+		return fmt.Errorf("failed to list due renewal reminders: %w", err)
+	}
+
+	sent, failed := 0, 0
+	for _, reminder := range due {
+		reminder.RenewalDate = reminder.RenewsAt.Format("02-01-2006")
+		reminder.DaysLeft = int(reminder.RenewsAt.Sub(today).Hours() / 24)
+		tenantCtx := ctxutil.WithTenantID(ctx, reminder.Tenant)
+
+		// Напоминание, не доставленное хотя бы одним каналом, повторяется при следующем запуске
+		if err := s.notify(tenantCtx, reminder); err != nil {
+			failed++
+			s.logger.Error(tenantCtx, "Failed to send renewal reminder",
+				"subscription_id", reminder.SubscriptionID,
+				"error", err,
+			)
+			continue
+		}
+		if err := s.repo.MarkSent(tenantCtx, reminder); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	s.logger.Info(ctx, "Renewal reminders finished",
+		"due", len(due),
+		"sent", sent,
+		"failed", failed,
+	)
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d renewal reminders", failed, len(due))
+	}
+	return nil
+}
+
+func (s *reminderService) notify(ctx context.Context, reminder model.RenewalReminder) error {
+	channels := s.cfg.Channels
+	if reminder.Channel != "" {
+		if _, ok := s.notifiers[reminder.Channel]; ok {
+			channels = []string{reminder.Channel}
+		} else {
+			// Канал отключили в конфигурации после сохранения настроек подписки
+			s.logger.Warn(ctx, "Renewal reminder channel is not configured, using default channels",
+				"subscription_id", reminder.SubscriptionID,
+				"channel", reminder.Channel,
+			)
+		}
+	}
+
+	for _, channel := range channels {
+		n, ok := s.notifiers[channel]
+		if !ok {
+			continue
+		}
+		if err := n.NotifyRenewal(ctx, reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *reminderService) GetSettings(ctx context.Context, subscriptionID uuid.UUID) (*model.ReminderSettings, error) {
+	if err := requireSubscriptionOwner(ctx, s.subscriptions, s.logger, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetSettings(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+	if settings == nil {
+		return nil, ErrReminderSettingsNotFound
+	}
+	return settings, nil
+}
+
+func (s *reminderService) SaveSettings(ctx context.Context, subscriptionID uuid.UUID, req model.SaveReminderSettingsRequest) (*model.ReminderSettings, error) {
+	if req.Days == nil && req.Channel == nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "days or channel is required")
+	}
+	if req.Days != nil && (*req.Days < 1 || *req.Days > model.MaxReminderDays) {
+		return nil, model.Invalid(model.ErrInvalidInput, "days must be between 1 and %d", model.MaxReminderDays)
+	}
+	if req.Channel != nil {
+		if _, ok := s.notifiers[*req.Channel]; !ok {
+			return nil, model.Invalid(model.ErrInvalidInput, "reminder channel %q is not available", *req.Channel)
+		}
+	}
+
+	// Чужой пользователь отключил бы напоминания владельца подписки
+	if err := requireSubscriptionOwner(ctx, s.subscriptions, s.logger, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Saving reminder settings", "subscription_id", subscriptionID)
+
+	settings := &model.ReminderSettings{
+		SubscriptionID: subscriptionID,
+		Days:           req.Days,
+		Channel:        req.Channel,
+	}
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, subscriptionError(err, "save reminder settings for")
+	}
+	return settings, nil
+}
+
+func (s *reminderService) DeleteSettings(ctx context.Context, subscriptionID uuid.UUID) error {
+	if err := requireSubscriptionOwner(ctx, s.subscriptions, s.logger, subscriptionID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Deleting reminder settings", "subscription_id", subscriptionID)
+
+	if err := s.repo.DeleteSettings(ctx, subscriptionID); err != nil {
+		return fmt.Errorf("failed to delete reminder settings: %w", err)
+	}
+	return nil
+}
+
+// logReminderNotifier пишет напоминания в лог
+type logReminderNotifier struct {
+	logger *logger.Logger
+}
+
+// NewLogReminderNotifier создает канал напоминаний в лог сервиса
+func NewLogReminderNotifier(logger *logger.Logger) ReminderNotifier {
+	return &logReminderNotifier{logger: logger}
+}
+
+func (n *logReminderNotifier) NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error {
+	n.logger.Info(ctx, "Subscription renewal reminder",
+		"tenant", ctxutil.TenantID(ctx),
+		"subscription_id", reminder.SubscriptionID,
+		"user_id", reminder.UserID,
+		"service_name", reminder.ServiceName,
+		"kind", reminder.Kind,
+		"renewal_date", reminder.RenewalDate,
+		"days_left", reminder.DaysLeft,
+	)
+	return nil
+}
+
+// eventReminderNotifier рассылает напоминания событием subscription.renewal_reminder
+type eventReminderNotifier struct {
+	events EventPublisher
+}
+
+// NewEventReminderNotifier создает канал напоминаний через вебхуки
+func NewEventReminderNotifier(events EventPublisher) ReminderNotifier {
+	return &eventReminderNotifier{events: events}
+}
+
+func (n *eventReminderNotifier) NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error {
+	n.events.Publish(ctx, EventRenewalReminder, reminder)
+	return nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type reminderRepoStub struct {
+	due      []model.RenewalReminder
+	window   model.ReminderWindow
+	sent     []uuid.UUID
+	settings *model.ReminderSettings
+}
+
+func (r *reminderRepoStub) ListDue(ctx context.Context, window model.ReminderWindow) ([]model.RenewalReminder, error) {
+	r.window = window
+	return r.due, nil
+}
+
+func (r *reminderRepoStub) MarkSent(ctx context.Context, reminder model.RenewalReminder) error {
+	r.sent = append(r.sent, reminder.SubscriptionID)
+	return nil
+}
+
+func (r *reminderRepoStub) GetSettings(ctx context.Context, subscriptionID uuid.UUID) (*model.ReminderSettings, error) {
+	return r.settings, nil
+}
+
+func (r *reminderRepoStub) SaveSettings(ctx context.Context, settings *model.ReminderSettings) error {
+	r.settings = settings
+	return nil
+}
+
+func (r *reminderRepoStub) DeleteSettings(ctx context.Context, subscriptionID uuid.UUID) error {
+	r.settings = nil
+	return nil
+}
+
+type notifierStub struct {
+	fail     uuid.UUID
+	received []model.RenewalReminder
+	tenants  []string
+}
+
+func (n *notifierStub) NotifyRenewal(ctx context.Context, reminder model.RenewalReminder) error {
+	if reminder.SubscriptionID == n.fail {
+		return errors.New("smtp unavailable")
+	}
+	n.received = append(n.received, reminder)
+	n.tenants = append(n.tenants, ctxutil.TenantID(ctx))
+	return nil
+}
+
+func TestRunReminders(t *testing.T) {
+	now := time.Now().In(model.PeriodLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	delivered, failing := uuid.New(), uuid.New()
+	repo := &reminderRepoStub{due: []model.RenewalReminder{
+		{SubscriptionID: delivered, Tenant: "acme", Kind: model.ReminderExpiring, RenewsAt: today.AddDate(0, 0, 3)},
+		{SubscriptionID: failing, Tenant: "acme", Kind: model.ReminderRenewal, RenewsAt: today.AddDate(0, 0, 5)},
+	}}
+	notifier := &notifierStub{fail: failing}
+
+	svc := NewReminderService(repo, nil, ReminderConfig{Days: 7, Channels: []string{model.ReminderChannelLog}},
+		map[string]ReminderNotifier{model.ReminderChannelLog: notifier}, logger.New(slog.LevelError+4))
+	if err := svc.RunReminders(context.Background()); err == nil {
+		t.Fatal("RunReminders() error = nil, want failed reminder")
+	}
+
+	if !repo.window.Until.Equal(today.AddDate(0, 0, 7)) || repo.window.OpenEndedRenewal != nil {
+		t.Errorf("unexpected window: %+v", repo.window)
+	}
+	if len(notifier.received) != 1 {
+		t.Fatalf("received %d reminders, want 1", len(notifier.received))
+	}
+	got := notifier.received[0]
+	if got.DaysLeft != 3 || got.RenewalDate != today.AddDate(0, 0, 3).Format("02-01-2006") || notifier.tenants[0] != "acme" {
+		t.Errorf("unexpected reminder %+v in tenant %q", got, notifier.tenants[0])
+	}
+	// Недоставленное напоминание не отмечается и повторяется при следующем запуске
+	if len(repo.sent) != 1 || repo.sent[0] != delivered {
+		t.Errorf("marked as sent: %v, want only %s", repo.sent, delivered)
+	}
+}
+
+func TestRunRemindersSubscriptionChannel(t *testing.T) {
+	now := time.Now().In(model.PeriodLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	custom, fallback, plain := uuid.New(), uuid.New(), uuid.New()
+	repo := &reminderRepoStub{due: []model.RenewalReminder{
+		{SubscriptionID: custom, Kind: model.ReminderExpiring, RenewsAt: today.AddDate(0, 0, 30), Channel: model.ReminderChannelEmail},
+		// Канал подписки отключен в конфигурации - напоминание идет каналами по умолчанию
+		{SubscriptionID: fallback, Kind: model.ReminderExpiring, RenewsAt: today.AddDate(0, 0, 2), Channel: model.ReminderChannelInbox},
+		{SubscriptionID: plain, Kind: model.ReminderExpiring, RenewsAt: today.AddDate(0, 0, 3)},
+	}}
+	logs, email := &notifierStub{}, &notifierStub{}
+
+	svc := NewReminderService(repo, nil, ReminderConfig{Days: 7, Channels: []string{model.ReminderChannelLog}}, map[string]ReminderNotifier{
+		model.ReminderChannelLog:   logs,
+		model.ReminderChannelEmail: email,
+	}, logger.New(slog.LevelError+4))
+	if err := svc.RunReminders(context.Background()); err != nil {
+		t.Fatalf("RunReminders() error = %v", err)
+	}
+
+	if len(email.received) != 1 || email.received[0].SubscriptionID != custom || email.received[0].DaysLeft != 30 {
+		t.Errorf("email received %+v, want only %s", email.received, custom)
+	}
+	if len(logs.received) != 2 || logs.received[0].SubscriptionID != fallback || logs.received[1].SubscriptionID != plain {
+		t.Errorf("log received %+v, want %s and %s", logs.received, fallback, plain)
+	}
+	if len(repo.sent) != 3 {
+		t.Errorf("marked as sent: %v, want all 3", repo.sent)
+	}
+}
+
+func TestSaveReminderSettings(t *testing.T) {
+	ctx := context.Background()
+	repo := &reminderRepoStub{}
+	svc := NewReminderService(repo, nil, ReminderConfig{Days: 7, Channels: []string{model.ReminderChannelLog}},
+		map[string]ReminderNotifier{model.ReminderChannelLog: &notifierStub{}}, logger.New(slog.LevelError+4))
+
+	days, tooMany := 30, model.MaxReminderDays+1
+	email, logs := model.ReminderChannelEmail, model.ReminderChannelLog
+	tests := []struct {
+		name    string
+		req     model.SaveReminderSettingsRequest
+		wantErr bool
+	}{
+		{name: "days and channel", req: model.SaveReminderSettingsRequest{Days: &days, Channel: &logs}},
+		{name: "days only", req: model.SaveReminderSettingsRequest{Days: &days}},
+		{name: "empty", req: model.SaveReminderSettingsRequest{}, wantErr: true},
+		{name: "too many days", req: model.SaveReminderSettingsRequest{Days: &tooMany}, wantErr: true},
+		{name: "unavailable channel", req: model.SaveReminderSettingsRequest{Channel: &email}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.settings = nil
+			settings, err := svc.SaveSettings(ctx, uuid.New(), tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, model.ErrInvalidInput) || repo.settings != nil {
+					t.Fatalf("SaveSettings() = %+v, %v, want ErrInvalidInput", settings, err)
+				}
+				return
+			}
+			if err != nil || repo.settings != settings || *settings.Days != days {
+				t.Fatalf("SaveSettings() = %+v, %v", settings, err)
+			}
+		})
+	}
+
+	if err := svc.DeleteSettings(ctx, uuid.New()); err != nil {
+		t.Fatalf("DeleteSettings() error = %v", err)
+	}
+	if _, err := svc.GetSettings(ctx, uuid.New()); !errors.Is(err, ErrReminderSettingsNotFound) {
+		t.Errorf("GetSettings() error = %v, want ErrReminderSettingsNotFound", err)
+	}
+}
+
+// TestReminderSettingsOwnership проверяет, что пользователь без прав администратора не
+// читает и не меняет настройки напоминаний чужой подписки
+func TestReminderSettingsOwnership(t *testing.T) {
+	owner, stranger := uuid.New(), uuid.New()
+	subscriptionID := uuid.New()
+	days := 3
+	existing := &model.ReminderSettings{SubscriptionID: subscriptionID, Days: &days}
+	repo := &reminderRepoStub{settings: existing}
+	svc := NewReminderService(repo, &subscriptionLookupStub{existing: subscriptionID, owner: owner}, ReminderConfig{Days: 7, Channels: []string{model.ReminderChannelLog}},
+		map[string]ReminderNotifier{model.ReminderChannelLog: &notifierStub{}}, logger.New(slog.LevelError+4))
+
+	ctx := auth.WithCaller(context.Background(), auth.Caller{UserID: stranger})
+	if _, err := svc.GetSettings(ctx, subscriptionID); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("GetSettings() error = %v, want ErrSubscriptionNotFound", err)
+	}
+	if _, err := svc.SaveSettings(ctx, subscriptionID, model.SaveReminderSettingsRequest{Days: &days}); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("SaveSettings() error = %v, want ErrSubscriptionNotFound", err)
+	}
+	if err := svc.DeleteSettings(ctx, subscriptionID); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("DeleteSettings() error = %v, want ErrSubscriptionNotFound", err)
+	}
+	if repo.settings != existing {
+		t.Errorf("settings of foreign subscription changed: %+v", repo.settings)
+	}
+
+	ctx = auth.WithCaller(context.Background(), auth.Caller{UserID: owner})
+	if settings, err := svc.GetSettings(ctx, subscriptionID); err != nil || settings != existing {
+		t.Errorf("GetSettings() by owner = %+v, %v", settings, err)
+	}
+	if err := svc.DeleteSettings(ctx, subscriptionID); err != nil || repo.settings != nil {
+		t.Errorf("DeleteSettings() by owner error = %v, settings %+v", err, repo.settings)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxSparklineCacheEntries ограничивает память кэша: при переполнении он очищается целиком
+const maxSparklineCacheEntries = 10000
+
+type SparklineService interface {
+	// Sparkline возвращает траты пользователя за последние months месяцев, включая текущий
+	Sparkline(ctx context.Context, userID uuid.UUID, months int) (*model.Sparkline, error)
+	// CacheTTL - время, в течение которого ответ можно считать актуальным
+	CacheTTL() time.Duration
+}
+
+type sparklineEntry struct {
+	sparkline *model.Sparkline
+	expiresAt time.Time
+}
+
+type sparklineService struct {
+	repo         repository.SubscriptionRepository
+	ttl          time.Duration
+	logger       *logger.Logger
+	currentMonth func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]sparklineEntry
+	// group объединяет одновременные промахи кэша по одному ключу в один запрос к базе
+	group singleflight.Group
+}
+
+func NewSparklineService(repo repository.SubscriptionRepository, ttl time.Duration, logger *logger.Logger) SparklineService {
+	return &sparklineService{
+		repo:         repo,
+		ttl:          ttl,
+		logger:       logger,
+		currentMonth: model.CurrentMonth,
+		cache:        make(map[string]sparklineEntry),
+	}
+}
+
+func (s *sparklineService) CacheTTL() time.Duration {
+	return s.ttl
+}
+
+func (s *sparklineService) Sparkline(ctx context.Context, userID uuid.UUID, months int) (*model.Sparkline, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	to := s.currentMonth()
+	from := to.AddDate(0, -(months - 1), 0)
+	// Текущий месяц входит в ключ, чтобы после смены месяца кэш не отдавал старое окно,
+	// организация - чтобы один user_id в разных организациях не получал чужие траты
+	key := fmt.Sprintf("%s/%s:%d:%s", ctxutil.TenantID(ctx), userID, months, to.Format("2006-01"))
+
+	if sparkline, ok := s.cached(key); ok {
+		s.logger.Debug(ctx, "Sparkline served from cache", "user_id", userID, "months", months)
+		return sparkline, nil
+	}
+
+	result, err, _ := s.group.Do(key, func() (interface{}, error) {
+		// Результат получат все ожидающие, поэтому отмена запроса первого из них
+		// не должна прерывать запрос остальных; время ограничивает statement_timeout
+		spend, err := s.repo.MonthlySpend(context.WithoutCancel(ctx), userID, from, to)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to get monthly spend for sparkline",
+				"user_id", userID,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to get monthly spend: %w", err)
+		}
+
+		// Месяцы без трат, которые репозиторий не вернул, остаются нулями: значений
+		// всегда months, по одному на месяц окна
+		sparkline := &model.Sparkline{From: from, To: to, Values: make([]int, months)}
+		for _, month := range spend {
+			i := (month.Month.Year()-from.Year())*12 + int(month.Month.Month()-from.Month())
+			if i >= 0 && i < months {
+				sparkline.Values[i] = month.Total
+			}
+		}
+
+		s.store(key, sparkline)
+		return sparkline, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*model.Sparkline), nil
+}
+
+func (s *sparklineService) cached(key string) (*model.Sparkline, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.sparkline, true
+}
+
+func (s *sparklineService) store(key string, sparkline *model.Sparkline) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxSparklineCacheEntries {
+		now := time.Now()
+		for k, entry := range s.cache {
+			if now.After(entry.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= maxSparklineCacheEntries {
+			s.cache = make(map[string]sparklineEntry)
+		}
+	}
+
+	s.cache[key] = sparklineEntry{sparkline: sparkline, expiresAt: time.Now().Add(s.ttl)}
+}
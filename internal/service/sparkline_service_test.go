@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// spendRepoStub возвращает траты по организациям и считает запросы; месяцы без трат
+// не возвращает, как если бы репозиторий не дополнял окно нулями
+type spendRepoStub struct {
+	repository.SubscriptionRepository
+	spend map[string][]model.MonthlySpend
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (r *spendRepoStub) MonthlySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]model.MonthlySpend, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var spend []model.MonthlySpend
+	for _, month := range r.spend[ctxutil.TenantID(ctx)] {
+		if !month.Month.Before(from) && !month.Month.After(to) {
+			spend = append(spend, month)
+		}
+	}
+	return spend, nil
+}
+
+func sparklineMonth(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSparklineZeroFillsMissingMonths(t *testing.T) {
+	repo := &spendRepoStub{spend: map[string][]model.MonthlySpend{
+		"default": {{Month: sparklineMonth(2025, time.May), Total: 500}, {Month: sparklineMonth(2025, time.July), Total: 700}},
+	}}
+	svc := NewSparklineService(repo, time.Minute, logger.New(slog.LevelError+4)).(*sparklineService)
+	svc.currentMonth = func() time.Time { return sparklineMonth(2025, time.July) }
+
+	sparkline, err := svc.Sparkline(context.Background(), uuid.New(), 4)
+	if err != nil {
+		t.Fatalf("Sparkline() error = %v", err)
+	}
+	if want := []int{0, 500, 0, 700}; !slices.Equal(sparkline.Values, want) {
+		t.Errorf("values = %v, want %v", sparkline.Values, want)
+	}
+	if !sparkline.From.Equal(sparklineMonth(2025, time.April)) || !sparkline.To.Equal(sparklineMonth(2025, time.July)) {
+		t.Errorf("window = %s..%s, want 04-2025..07-2025", sparkline.From, sparkline.To)
+	}
+}
+
+func TestSparklineCacheKey(t *testing.T) {
+	repo := &spendRepoStub{spend: map[string][]model.MonthlySpend{
+		"default": {{Month: sparklineMonth(2025, time.July), Total: 700}, {Month: sparklineMonth(2025, time.August), Total: 800}},
+		"acme":    {{Month: sparklineMonth(2025, time.July), Total: 9000}},
+	}}
+	svc := NewSparklineService(repo, time.Hour, logger.New(slog.LevelError+4)).(*sparklineService)
+	current := sparklineMonth(2025, time.July)
+	svc.currentMonth = func() time.Time { return current }
+	userID := uuid.New()
+	ctx := context.Background()
+
+	values := func(ctx context.Context) []int {
+		t.Helper()
+		sparkline, err := svc.Sparkline(ctx, userID, 2)
+		if err != nil {
+			t.Fatalf("Sparkline() error = %v", err)
+		}
+		return sparkline.Values
+	}
+
+	if got := values(ctx); !slices.Equal(got, []int{0, 700}) {
+		t.Errorf("July values = %v, want [0 700]", got)
+	}
+	values(ctx)
+	if repo.calls != 1 {
+		t.Errorf("repository calls = %d, want 1: repeated request must be cached", repo.calls)
+	}
+
+	// Тот же user_id в другой организации - другие траты, а не кэш default
+	if got := values(ctxutil.WithTenantID(ctx, "acme")); !slices.Equal(got, []int{0, 9000}) {
+		t.Errorf("acme values = %v, want [0 9000]", got)
+	}
+
+	// После смены месяца окно сдвигается, несмотря на действующий TTL
+	current = sparklineMonth(2025, time.August)
+	if got := values(ctx); !slices.Equal(got, []int{700, 800}) {
+		t.Errorf("August values = %v, want [700 800]", got)
+	}
+	if repo.calls != 3 {
+		t.Errorf("repository calls = %d, want 3", repo.calls)
+	}
+}
+
+func TestSparklineIgnoresCallerCancellation(t *testing.T) {
+	repo := &spendRepoStub{spend: map[string][]model.MonthlySpend{}}
+	svc := NewSparklineService(repo, time.Minute, logger.New(slog.LevelError+4))
+
+	// Запрос к базе общий для всех ожидающих: отмена запроса одного из них его не прерывает
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.Sparkline(ctx, uuid.New(), 3); err != nil {
+		t.Errorf("Sparkline() with cancelled caller error = %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func (s *subscriptionService) BulkCreateSubscriptions(ctx context.Context, mode string, reqs []model.CreateSubscriptionRequest, invalid map[int]error) (*model.BulkResponse, error) {
+	s.logger.Info(ctx, "Creating subscriptions in bulk",
+		"mode", mode,
+		"count", len(reqs),
+	)
+
+	results := newBulkResults(len(reqs))
+	subs := make([]*model.Subscription, len(reqs))
+	for i, req := range reqs {
+		if err := invalid[i]; err != nil {
+			failItem(&results[i], err)
+			continue
+		}
+		sub, err := s.buildSubscription(ctx, req)
+		if err != nil {
+			failItem(&results[i], err)
+			continue
+		}
+		subs[i] = sub
+	}
+
+	created := func(i int) {
+		results[i].Status = model.BulkItemCreated
+		results[i].ID = &subs[i].ID
+		results[i].Subscription = subs[i]
+	}
+
+	if mode == model.BulkBestEffort {
+		for i, sub := range subs {
+			if sub == nil {
+				continue
+			}
+			if err := s.repo.Create(ctx, sub); err != nil {
+				s.logger.Warn(ctx, "Failed to create subscription in bulk",
+					"index", i,
+					"error", err,
+				)
+				failItem(&results[i], fmt.Errorf("failed to create subscription: %w", err))
+				continue
+			}
+			created(i)
+		}
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+
+	if failed(results) {
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+	if err := s.repo.CreateBatch(ctx, subs); err != nil {
+		return s.atomicFailure(ctx, mode, results, "create", err)
+	}
+	for i := range subs {
+		created(i)
+	}
+	return s.bulkResponse(ctx, mode, results), nil
+}
+
+func (s *subscriptionService) BulkUpdateSubscriptions(ctx context.Context, mode string, items []model.BulkUpdateItem, invalid map[int]error) (*model.BulkResponse, error) {
+	s.logger.Info(ctx, "Updating subscriptions in bulk",
+		"mode", mode,
+		"count", len(items),
+	)
+
+	results := newBulkResults(len(items))
+	var (
+		updates []model.SubscriptionUpdate
+		indexes []int
+	)
+	for i, item := range items {
+		results[i].ID = &items[i].ID
+		if err := invalid[i]; err != nil {
+			failItem(&results[i], err)
+			continue
+		}
+		sub, err := s.prepareUpdate(ctx, item.ID, item.UpdateSubscriptionRequest)
+		if err != nil {
+			failItem(&results[i], err)
+			continue
+		}
+		if sub == nil {
+			results[i].Status = model.BulkItemUnchanged
+			continue
+		}
+		updates = append(updates, model.SubscriptionUpdate{ID: item.ID, Subscription: sub})
+		indexes = append(indexes, i)
+	}
+
+	if mode == model.BulkBestEffort {
+		for j, u := range updates {
+			i := indexes[j]
+			if err := s.repo.Update(ctx, u.ID, u.Subscription); err != nil {
+				s.logger.Warn(ctx, "Failed to update subscription in bulk",
+					"index", i,
+					"subscription_id", u.ID,
+					"error", err,
+				)
+				failItem(&results[i], subscriptionError(err, "update"))
+				continue
+			}
+			results[i].Status = model.BulkItemUpdated
+		}
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+
+	if failed(results) {
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+	if err := s.repo.UpdateBatch(ctx, updates); err != nil {
+		// Строка ошибки репозитория - индекс среди измененных подписок
+		var rowErr *repository.BatchRowError
+		if errors.As(err, &rowErr) && rowErr.Row < len(indexes) {
+			rowErr.Row = indexes[rowErr.Row]
+		}
+		return s.atomicFailure(ctx, mode, results, "update", err)
+	}
+	for _, i := range indexes {
+		results[i].Status = model.BulkItemUpdated
+	}
+	return s.bulkResponse(ctx, mode, results), nil
+}
+
+func (s *subscriptionService) BulkDeleteSubscriptions(ctx context.Context, mode string, ids []uuid.UUID) (*model.BulkResponse, error) {
+	s.logger.Info(ctx, "Deleting subscriptions in bulk",
+		"mode", mode,
+		"count", len(ids),
+	)
+
+	results := newBulkResults(len(ids))
+	for i := range ids {
+		results[i].ID = &ids[i]
+		if err := s.requireOwner(ctx, ids[i]); err != nil {
+			failItem(&results[i], err)
+		}
+	}
+
+	if mode == model.BulkBestEffort {
+		for i, id := range ids {
+			if results[i].Status == model.BulkItemFailed {
+				continue
+			}
+			if err := s.repo.Delete(ctx, id); err != nil {
+				s.logger.Warn(ctx, "Failed to delete subscription in bulk",
+					"index", i,
+					"subscription_id", id,
+					"error", err,
+				)
+				failItem(&results[i], subscriptionError(err, "delete"))
+				continue
+			}
+			results[i].Status = model.BulkItemDeleted
+		}
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+
+	if failed(results) {
+		return s.bulkResponse(ctx, mode, results), nil
+	}
+	if err := s.repo.DeleteBatch(ctx, ids); err != nil {
+		return s.atomicFailure(ctx, mode, results, "delete", err)
+	}
+	for i := range results {
+		results[i].Status = model.BulkItemDeleted
+	}
+	return s.bulkResponse(ctx, mode, results), nil
+}
+
+func newBulkResults(n int) []model.BulkItemResult {
+	results := make([]model.BulkItemResult, n)
+	for i := range results {
+		results[i].Index = i
+	}
+	return results
+}
+
+// failItem отмечает ошибку элемента пачки
+func failItem(result *model.BulkItemResult, err error) {
+	result.Status = model.BulkItemFailed
+	result.Error = err.Error()
+}
+
+// failed сообщает, есть ли в пачке элементы с ошибкой
+func failed(results []model.BulkItemResult) bool {
+	for _, r := range results {
+		if r.Status == model.BulkItemFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// atomicFailure разбирает ошибку атомарной пачки: ошибка одной строки отмечается у
+// ее элемента, остальные элементы не применены. Прочие ошибки возвращаются вызывающему
+func (s *subscriptionService) atomicFailure(ctx context.Context, mode string, results []model.BulkItemResult, action string, err error) (*model.BulkResponse, error) {
+	var rowErr *repository.BatchRowError
+	if !errors.As(err, &rowErr) || rowErr.Row >= len(results) {
+		s.logger.Error(ctx, "Failed to apply subscriptions batch",
+			"action", action,
+			"count", len(results),
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to %s subscriptions: %w", action, err)
+	}
+
+	// Отсутствие подписки сообщается так же, как в режиме best_effort
+	if rowErr.Message == "subscription not found" {
+		err = errors.New(rowErr.Message)
+	}
+	failItem(&results[rowErr.Row], err)
+	return s.bulkResponse(ctx, mode, results), nil
+}
+
+// bulkResponse собирает ответ пакетной операции. Если атомарная пачка не применена,
+// элементы без ошибки отмечаются пропущенными, а созданные подписки не возвращаются
+func (s *subscriptionService) bulkResponse(ctx context.Context, mode string, results []model.BulkItemResult) *model.BulkResponse {
+	rolledBack := mode == model.BulkAtomic && failed(results)
+
+	resp := &model.BulkResponse{Mode: mode, Items: results}
+	for i := range results {
+		switch {
+		case results[i].Status == model.BulkItemFailed:
+			resp.Failed++
+		case rolledBack:
+			results[i].Status = model.BulkItemSkipped
+			results[i].Subscription = nil
+		default:
+			resp.Succeeded++
+		}
+	}
+
+	s.logger.Info(ctx, "Bulk operation finished",
+		"mode", mode,
+		"succeeded", resp.Succeeded,
+		"failed", resp.Failed,
+	)
+	return resp
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func TestBulkCreateSubscriptions(t *testing.T) {
+	userID := uuid.New()
+	reqs := []model.CreateSubscriptionRequest{
+		{ServiceName: "Netflix", MonthlyCost: 400, UserID: userID, StartDate: "07-2025"},
+		{ServiceName: "Spotify", MonthlyCost: 200, UserID: userID, StartDate: "2025-07"},
+		{ServiceName: "Yandex Plus", MonthlyCost: 300, UserID: userID, StartDate: "07-2025"},
+	}
+
+	tests := []struct {
+		mode          string
+		want          []string
+		wantSucceeded int
+		wantCreated   int
+	}{
+		{mode: model.BulkAtomic, want: []string{model.BulkItemSkipped, model.BulkItemFailed, model.BulkItemSkipped}},
+		{mode: model.BulkBestEffort, want: []string{model.BulkItemCreated, model.BulkItemFailed, model.BulkItemCreated}, wantSucceeded: 2, wantCreated: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			repo := repository.NewInMemorySubscriptionRepository()
+			resp, err := newExportTestService(repo).BulkCreateSubscriptions(context.Background(), tt.mode, reqs, nil)
+			if err != nil {
+				t.Fatalf("BulkCreateSubscriptions() error = %v", err)
+			}
+
+			for i, item := range resp.Items {
+				if item.Index != i || item.Status != tt.want[i] {
+					t.Errorf("item %d = %+v, want status %s", i, item, tt.want[i])
+				}
+			}
+			if resp.Items[1].Error == "" {
+				t.Error("failed item has no error")
+			}
+			if resp.Succeeded != tt.wantSucceeded || resp.Failed != 1 {
+				t.Errorf("succeeded = %d, failed = %d, want %d, 1", resp.Succeeded, resp.Failed, tt.wantSucceeded)
+			}
+
+			_, total, err := repo.List(context.Background(), model.SubscriptionFilter{}, model.Pagination{Limit: 10})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if total != tt.wantCreated {
+				t.Errorf("stored %d subscriptions, want %d", total, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestBulkUpdateAndDeleteSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, mode := range []string{model.BulkAtomic, model.BulkBestEffort} {
+		t.Run(mode, func(t *testing.T) {
+			repo := repository.NewInMemorySubscriptionRepository()
+			svc := newExportTestService(repo)
+
+			created, err := svc.BulkCreateSubscriptions(ctx, model.BulkAtomic, []model.CreateSubscriptionRequest{
+				{ServiceName: "Netflix", MonthlyCost: 400, UserID: userID, StartDate: "07-2025"},
+				{ServiceName: "Spotify", MonthlyCost: 200, UserID: userID, StartDate: "07-2025"},
+			}, nil)
+			if err != nil || created.Failed != 0 {
+				t.Fatalf("BulkCreateSubscriptions() = %+v, %v", created, err)
+			}
+			first, second := *created.Items[0].ID, *created.Items[1].ID
+			missing := uuid.New()
+
+			update := func(id uuid.UUID, name string, cost int) model.BulkUpdateItem {
+				return model.BulkUpdateItem{ID: id, UpdateSubscriptionRequest: model.UpdateSubscriptionRequest{
+					ServiceName: name, MonthlyCost: cost, UserID: userID, StartDate: "07-2025",
+				}}
+			}
+			updated, err := svc.BulkUpdateSubscriptions(ctx, mode, []model.BulkUpdateItem{
+				update(first, "Netflix", 500),
+				update(second, "Spotify", 200),
+				update(missing, "Kinopoisk", 300),
+			}, nil)
+			if err != nil {
+				t.Fatalf("BulkUpdateSubscriptions() error = %v", err)
+			}
+
+			wantFirst, wantCost := model.BulkItemUpdated, 500
+			if mode == model.BulkAtomic {
+				wantFirst, wantCost = model.BulkItemSkipped, 400
+			}
+			if updated.Items[0].Status != wantFirst || updated.Items[2].Status != model.BulkItemFailed || updated.Items[2].Error != "subscription not found" {
+				t.Errorf("unexpected update items: %+v", updated.Items)
+			}
+			if sub, _ := repo.GetByID(ctx, first); sub.MonthlyCost != wantCost {
+				t.Errorf("monthly cost = %d, want %d", sub.MonthlyCost, wantCost)
+			}
+
+			deleted, err := svc.BulkDeleteSubscriptions(ctx, mode, []uuid.UUID{first, missing, second})
+			if err != nil {
+				t.Fatalf("BulkDeleteSubscriptions() error = %v", err)
+			}
+			if deleted.Items[1].Status != model.BulkItemFailed || deleted.Failed != 1 {
+				t.Errorf("unexpected delete items: %+v", deleted.Items)
+			}
+
+			wantLeft := 0
+			if mode == model.BulkAtomic {
+				wantLeft = 2
+			}
+			if _, total, _ := repo.List(ctx, model.SubscriptionFilter{}, model.Pagination{Limit: 10}); total != wantLeft {
+				t.Errorf("%d subscriptions left, want %d", total, wantLeft)
+			}
+		})
+	}
+}
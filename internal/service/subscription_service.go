@@ -2,34 +2,108 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
 	"github.com/Zipklas/subscription-service/internal/logger"
 	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/modifier"
+	"github.com/Zipklas/subscription-service/internal/money"
 	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/signing"
 
 	"github.com/google/uuid"
 )
 
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error)
+	// ImportSubscriptions создает подписки из строк файла пачками и возвращает отчет с ошибками по строкам
+	ImportSubscriptions(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error)
+	// ValidateSubscription проверяет запрос на создание как CreateSubscription, но ничего не сохраняет.
+	// Ошибка возвращается только при сбое чтения из базы, ошибки проверки - в результате
+	ValidateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error)
 	GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
 	UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error
 	DeleteSubscription(ctx context.Context, id uuid.UUID) error
-	ListSubscriptions(ctx context.Context, userID *uuid.UUID, serviceName *string) ([]*model.Subscription, error)
+	ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error)
+	// ListSubscriptionsAfter возвращает до limit подписок, следующих за after в порядке (created_at, id)
+	ListSubscriptionsAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error)
+	SearchSubscriptions(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error)
+	// ExportSubscriptions передает в fn все подписки в порядке (created_at, id), читая их страницами
+	ExportSubscriptions(ctx context.Context, fn func(*model.Subscription) error) error
+	// StreamSubscriptions передает в fn подписки под фильтром одним запросом по мере чтения из базы
+	StreamSubscriptions(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error
+	ActivateSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	// CancelSubscription отменяет подписку сразу (с указанного месяца) или в конце оплаченного срока
+	CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error)
+	// TransferSubscription передает подписку другому пользователю; отмененные и истекшие подписки не передаются
+	TransferSubscription(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error)
+	// ListSubscriptionPrices возвращает стоимость подписки по месяцам ее срока
+	ListSubscriptionPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error)
+	ListChanges(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error)
+	// CalculateTotalCost считает итоги за период; с filter.Signed добавляет параметры расчета и подпись
 	CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error)
+	// VerifySummary проверяет подпись итогов, полученных с signed=true
+	VerifySummary(ctx context.Context, summary *model.SummaryResponse) (*model.SummaryVerification, error)
+	// ListUserServices возвращает сервисы пользователя с числом подписок и стоимостью в месяц
+	ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error)
+	// BulkCreateSubscriptions создает подписки в режиме mode (model.BulkAtomic или model.BulkBestEffort).
+	// invalid - элементы, не прошедшие проверку запроса, по индексам. Ошибки элементов
+	// возвращаются в результате, ошибка - только при сбое всей атомарной пачки
+	BulkCreateSubscriptions(ctx context.Context, mode string, reqs []model.CreateSubscriptionRequest, invalid map[int]error) (*model.BulkResponse, error)
+	// BulkUpdateSubscriptions изменяет подписки, как UpdateSubscription, в режиме mode
+	BulkUpdateSubscriptions(ctx context.Context, mode string, items []model.BulkUpdateItem, invalid map[int]error) (*model.BulkResponse, error)
+	// BulkDeleteSubscriptions удаляет подписки в режиме mode
+	BulkDeleteSubscriptions(ctx context.Context, mode string, ids []uuid.UUID) (*model.BulkResponse, error)
+	// SyncUserSubscriptions приводит подписки пользователя к желаемому набору items одной
+	// транзакцией и возвращает изменения; с dryRun изменения только вычисляются
+	SyncUserSubscriptions(ctx context.Context, userID uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error)
+}
+
+// importBatchSize - количество подписок, загружаемых при импорте одним COPY в одной транзакции
+const importBatchSize = 1000
+
+// maxOverlapCandidates - сколько подписок пользователя на тот же сервис просматривается
+// при поиске пересечений в ValidateSubscription
+const maxOverlapCandidates = 100
+
+// exportPageSize - количество подписок, читаемых из базы за один запрос при выгрузке
+const exportPageSize = 500
+
+// statusTransitions - разрешенные переходы состояния подписки. Отмененная и истекшая
+// подписки в другие состояния не переводятся
+var statusTransitions = map[string][]string{
+	model.StatusActive: {model.StatusPaused, model.StatusCancelled},
+	model.StatusPaused: {model.StatusActive, model.StatusCancelled},
 }
 
 type subscriptionService struct {
-	repo   repository.SubscriptionRepository
+	repo      repository.SubscriptionRepository
+	taxes     TaxResolver
+	modifiers []modifier.CostModifier
+	// signer подписывает итоги; nil - подпись не настроена
+	signer *signing.Signer
 	logger *logger.Logger
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository, logger *logger.Logger) SubscriptionService {
+// NewSubscriptionService создает сервис подписок; taxes выбирает ставку и округление
+// организации, modifiers применяются к итогам CalculateTotalCost по порядку, signer
+// подписывает итоги по запросу
+func NewSubscriptionService(repo repository.SubscriptionRepository, taxes TaxResolver, modifiers []modifier.CostModifier, signer *signing.Signer, logger *logger.Logger) SubscriptionService {
 	return &subscriptionService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		taxes:     taxes,
+		modifiers: modifiers,
+		signer:    signer,
+		logger:    logger,
 	}
 }
 
@@ -40,6 +114,36 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req model.
 		"monthly_cost", req.MonthlyCost,
 	)
 
+	subscription, err := s.buildSubscription(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		s.logger.Error(ctx, "Failed to create subscription in repository",
+			"user_id", req.UserID,
+			"service_name", req.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	s.logger.Info(ctx, "Subscription created successfully",
+		"subscription_id", subscription.ID,
+		"user_id", req.UserID,
+		"is_draft", subscription.IsDraft,
+	)
+
+	return subscription, nil
+}
+
+// buildSubscription проверяет запрос на создание и собирает из него подписку
+func (s *subscriptionService) buildSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+	// Пользователь без прав администратора создает подписки только себе
+	if _, err := auth.ScopeUserID(ctx, &req.UserID); err != nil {
+		return nil, err
+	}
+
 	// Парсим даты из строк в формате "01-2006" (месяц-год)
 	startDate, err := model.ParseMonthYear(req.StartDate)
 	if err != nil {
@@ -47,7 +151,7 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req model.
 			"start_date", req.StartDate,
 			"error", err,
 		)
-		return nil, fmt.Errorf("invalid start date format, expected MM-YYYY: %w", err)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start date format, expected MM-YYYY: %w", err)
 	}
 
 	endDate, err := model.ParseMonthYearPtr(req.EndDate)
@@ -56,7 +160,7 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req model.
 			"end_date", req.EndDate,
 			"error", err,
 		)
-		return nil, fmt.Errorf("invalid end date format, expected MM-YYYY: %w", err)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid end date format, expected MM-YYYY: %w", err)
 	}
 
 	// Валидация дат
@@ -69,34 +173,255 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req model.
 		return nil, err
 	}
 
-	subscription := &model.Subscription{
-		ServiceName: req.ServiceName,
-		MonthlyCost: req.MonthlyCost,
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+	if err := validateFree(req.IsFree, req.MonthlyCost, req.PrepaidAmount, req.Cost); err != nil {
+		s.logger.Error(ctx, "Free subscription validation failed",
+			"monthly_cost", req.MonthlyCost,
+			"prepaid_amount", req.PrepaidAmount,
+			"error", err,
+		)
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, subscription); err != nil {
-		s.logger.Error(ctx, "Failed to create subscription in repository",
-			"user_id", req.UserID,
-			"service_name", req.ServiceName,
+	if err := req.Metadata.Validate(); err != nil {
+		s.logger.Error(ctx, "Metadata validation failed",
 			"error", err,
 		)
-		return nil, fmt.Errorf("failed to create subscription: %w", err)
+		return nil, err
 	}
 
-	s.logger.Info(ctx, "Subscription created successfully",
-		"subscription_id", subscription.ID,
+	tags, err := model.NormalizeTags(req.Tags)
+	if err != nil {
+		s.logger.Error(ctx, "Tags validation failed",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	description, err := model.NormalizeDescription(req.Description)
+	if err != nil {
+		s.logger.Error(ctx, "Description validation failed",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	// Стоимость за неделю или год задает ежемесячную стоимость
+	billingPeriod, cost, monthlyCost, err := s.applyBillingPeriod(ctx, req.BillingPeriod, req.Cost, req.MonthlyCost, req.PrepaidAmount)
+	if err != nil {
+		s.logger.Error(ctx, "Billing period validation failed",
+			"billing_period", req.BillingPeriod,
+			"cost", req.Cost,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	// Годовая предоплата задает период и ежемесячную стоимость
+	endDate, monthlyCost, err = s.applyPrepaid(ctx, startDate, endDate, req.PrepaidAmount, monthlyCost)
+	if err != nil {
+		s.logger.Error(ctx, "Prepaid validation failed",
+			"start_date", startDate,
+			"end_date", endDate,
+			"prepaid_amount", req.PrepaidAmount,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return &model.Subscription{
+		ServiceName:   req.ServiceName,
+		MonthlyCost:   monthlyCost,
+		UserID:        req.UserID,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		PrepaidAmount: req.PrepaidAmount,
+		IsDraft:       req.IsDraft,
+		Status:        model.StatusActive,
+		Metadata:      req.Metadata,
+		ServiceID:     req.ServiceID,
+		Description:   description,
+		BillingPeriod: billingPeriod,
+		Cost:          cost,
+		Tags:          tags,
+	}, nil
+}
+
+func (s *subscriptionService) ImportSubscriptions(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error) {
+	s.logger.Info(ctx, "Importing subscriptions",
+		"rows", len(rows),
+	)
+
+	result := &model.ImportResult{Errors: []model.ImportError{}}
+	batch := make([]*model.Subscription, 0, importBatchSize)
+	lines := make([]int, 0, importBatchSize)
+
+	// Строка, нарушившая ограничение базы, исключается из пачки, и пачка загружается
+	// повторно. Остальные ошибки помечают все строки: транзакция откатывается целиком
+	flush := func() error {
+		defer func() {
+			batch = batch[:0]
+			lines = lines[:0]
+		}()
+		for len(batch) > 0 {
+			err := s.repo.CreateBatch(ctx, batch)
+			if err == nil {
+				result.Imported += len(batch)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			var rowErr *repository.BatchRowError
+			if errors.As(err, &rowErr) && rowErr.Row < len(batch) {
+				result.Errors = append(result.Errors, model.ImportError{Line: lines[rowErr.Row], Error: err.Error()})
+				batch = append(batch[:rowErr.Row], batch[rowErr.Row+1:]...)
+				lines = append(lines[:rowErr.Row], lines[rowErr.Row+1:]...)
+				continue
+			}
+
+			s.logger.Error(ctx, "Failed to import subscriptions batch",
+				"first_line", lines[0],
+				"count", len(batch),
+				"error", err,
+			)
+			for _, line := range lines {
+				result.Errors = append(result.Errors, model.ImportError{Line: line, Error: err.Error()})
+			}
+			return nil
+		}
+		return nil
+	}
+
+	for _, row := range rows {
+		subscription, err := s.buildSubscription(ctx, row.Request)
+		if err != nil {
+			result.Errors = append(result.Errors, model.ImportError{Line: row.Line, Error: err.Error()})
+			continue
+		}
+
+		batch = append(batch, subscription)
+		lines = append(lines, row.Line)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("failed to import subscriptions: %w", err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, fmt.Errorf("failed to import subscriptions: %w", err)
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+	result.Failed = len(result.Errors)
+
+	s.logger.Info(ctx, "Subscriptions imported",
+		"imported", result.Imported,
+		"failed", result.Failed,
+	)
+
+	return result, nil
+}
+
+func (s *subscriptionService) ValidateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error) {
+	s.logger.Debug(ctx, "Validating subscription",
 		"user_id", req.UserID,
+		"service_name", req.ServiceName,
 	)
 
-	return subscription, nil
+	result := &model.ValidationResult{Warnings: []model.ValidationWarning{}}
+
+	subscription, err := s.buildSubscription(ctx, req)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+
+	normalized := model.CreateSubscriptionRequest{
+		ServiceName:   subscription.ServiceName,
+		MonthlyCost:   subscription.MonthlyCost,
+		UserID:        subscription.UserID,
+		StartDate:     subscription.StartDate.Format("01-2006"),
+		PrepaidAmount: subscription.PrepaidAmount,
+		IsDraft:       subscription.IsDraft,
+		IsFree:        subscription.Free(),
+		Metadata:      subscription.Metadata,
+		ServiceID:     subscription.ServiceID,
+		Description:   subscription.Description,
+		BillingPeriod: subscription.BillingPeriod,
+		Cost:          subscription.Cost,
+		Tags:          subscription.Tags,
+	}
+	if subscription.EndDate != nil {
+		endDate := subscription.EndDate.Format("01-2006")
+		normalized.EndDate = &endDate
+	}
+	result.Normalized = &normalized
+
+	if model.EffectiveStatus(subscription.Status, subscription.EndDate, model.CurrentMonth()) == model.StatusExpired {
+		result.Warnings = append(result.Warnings, model.ValidationWarning{
+			Code:    model.WarningExpired,
+			Message: fmt.Sprintf("subscription ends in %s, before the current month", *normalized.EndDate),
+		})
+	}
+
+	existing, _, err := s.repo.List(ctx, model.SubscriptionFilter{
+		UserID:      &subscription.UserID,
+		ServiceName: &subscription.ServiceName,
+	}, model.Pagination{Limit: maxOverlapCandidates})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list subscriptions for overlap check",
+			"user_id", subscription.UserID,
+			"service_name", subscription.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to check overlapping subscriptions: %w", err)
+	}
+
+	for _, other := range existing {
+		if !periodsOverlap(subscription.StartDate, subscription.EndDate, other.StartDate, other.EndDate) {
+			continue
+		}
+		period := other.StartDate.Format("01-2006") + " - "
+		if other.EndDate != nil {
+			period += other.EndDate.Format("01-2006")
+		}
+		result.Warnings = append(result.Warnings, model.ValidationWarning{
+			Code:           model.WarningOverlap,
+			Message:        fmt.Sprintf("user already has a %s subscription for %s", other.ServiceName, period),
+			SubscriptionID: &other.ID,
+		})
+	}
+
+	return result, nil
 }
 
 func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
 	s.logger.Info(ctx, "Updating subscription", "subscription_id", id)
 
+	subscription, err := s.prepareUpdate(ctx, id, req)
+	if err != nil || subscription == nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, id, subscription); err != nil {
+		s.logger.Error(ctx, "Failed to update subscription in repository",
+			"subscription_id", id,
+			"error", err,
+		)
+		return subscriptionError(err, "update")
+	}
+
+	s.logger.Info(ctx, "Subscription updated successfully", "subscription_id", id)
+	return nil
+}
+
+// prepareUpdate проверяет запрос на изменение подписки id и собирает новые данные для
+// репозитория. nil без ошибки - запрос ничего не меняет
+func (s *subscriptionService) prepareUpdate(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) (*model.Subscription, error) {
 	// Парсим даты из строк в формате "01-2006" (месяц-год)
 	startDate, err := model.ParseMonthYear(req.StartDate)
 	if err != nil {
@@ -104,7 +429,7 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UU
 			"start_date", req.StartDate,
 			"error", err,
 		)
-		return fmt.Errorf("invalid start date format, expected MM-YYYY: %w", err)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid start date format, expected MM-YYYY: %w", err)
 	}
 
 	endDate, err := model.ParseMonthYearPtr(req.EndDate)
@@ -113,7 +438,7 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UU
 			"end_date", req.EndDate,
 			"error", err,
 		)
-		return fmt.Errorf("invalid end date format, expected MM-YYYY: %w", err)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid end date format, expected MM-YYYY: %w", err)
 	}
 
 	// Валидация дат
@@ -123,7 +448,71 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UU
 			"end_date", endDate,
 			"error", err,
 		)
-		return err
+		return nil, err
+	}
+
+	priceFrom, err := model.ParseMonthYearPtr(req.PriceEffectiveFrom)
+	if err != nil {
+		s.logger.Error(ctx, "Invalid price effective date format",
+			"price_effective_from", req.PriceEffectiveFrom,
+			"error", err,
+		)
+		return nil, model.Invalid(model.ErrInvalidPeriodFormat, "invalid price_effective_from format, expected MM-YYYY: %w", err)
+	}
+
+	if err := validateFree(req.IsFree, req.MonthlyCost, req.PrepaidAmount, req.Cost); err != nil {
+		s.logger.Error(ctx, "Free subscription validation failed",
+			"monthly_cost", req.MonthlyCost,
+			"prepaid_amount", req.PrepaidAmount,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	if err := req.Metadata.Validate(); err != nil {
+		s.logger.Error(ctx, "Metadata validation failed",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	tags, err := model.NormalizeTags(req.Tags)
+	if err != nil {
+		s.logger.Error(ctx, "Tags validation failed",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	description, err := model.NormalizeDescription(req.Description)
+	if err != nil {
+		s.logger.Error(ctx, "Description validation failed",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	// Стоимость за неделю или год задает ежемесячную стоимость
+	billingPeriod, cost, monthlyCost, err := s.applyBillingPeriod(ctx, req.BillingPeriod, req.Cost, req.MonthlyCost, req.PrepaidAmount)
+	if err != nil {
+		s.logger.Error(ctx, "Billing period validation failed",
+			"billing_period", req.BillingPeriod,
+			"cost", req.Cost,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	// Годовая предоплата задает период и ежемесячную стоимость
+	endDate, monthlyCost, err = s.applyPrepaid(ctx, startDate, endDate, req.PrepaidAmount, monthlyCost)
+	if err != nil {
+		s.logger.Error(ctx, "Prepaid validation failed",
+			"start_date", startDate,
+			"end_date", endDate,
+			"prepaid_amount", req.PrepaidAmount,
+			"error", err,
+		)
+		return nil, err
 	}
 
 	// Проверяем существование подписки
@@ -133,31 +522,75 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id uuid.UU
 			"subscription_id", id,
 			"error", err,
 		)
-		return fmt.Errorf("failed to check subscription: %w", err)
+		return nil, fmt.Errorf("failed to check subscription: %w", err)
 	}
-	if existing == nil {
+	if existing == nil || !auth.CanAccessUser(ctx, existing.UserID) {
 		s.logger.Warn(ctx, "Subscription not found for update", "subscription_id", id)
-		return fmt.Errorf("subscription not found")
+		return nil, ErrSubscriptionNotFound
+	}
+	if _, err := auth.ScopeUserID(ctx, &req.UserID); err != nil {
+		return nil, err
+	}
+
+	status := existing.Status
+	if req.Status != nil && *req.Status != existing.Status {
+		if err := checkStatusTransition(existing.Status, *req.Status); err != nil {
+			s.logger.Warn(ctx, "Invalid subscription status transition",
+				"subscription_id", id,
+				"from", existing.Status,
+				"to", *req.Status,
+			)
+			return nil, err
+		}
+		status = *req.Status
+	}
+	if status == model.StatusCancelled {
+		endDate = cancelledEndDate(existing, startDate, endDate)
 	}
 
 	subscription := &model.Subscription{
-		ServiceName: req.ServiceName,
-		MonthlyCost: req.MonthlyCost,
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ServiceName:   req.ServiceName,
+		MonthlyCost:   monthlyCost,
+		UserID:        req.UserID,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		PrepaidAmount: req.PrepaidAmount,
+		Status:        status,
+		Metadata:      req.Metadata,
+		ServiceID:     req.ServiceID,
+		Description:   description,
+		BillingPeriod: billingPeriod,
+		Cost:          cost,
+		Tags:          tags,
 	}
 
-	if err := s.repo.Update(ctx, id, subscription); err != nil {
-		s.logger.Error(ctx, "Failed to update subscription in repository",
-			"subscription_id", id,
-			"error", err,
-		)
-		return fmt.Errorf("failed to update subscription: %w", err)
+	// Новая стоимость записывается в историю цен и действует с месяца priceFrom
+	if !model.SamePrice(existing.Price(existing.StartDate), subscription.Price(startDate)) {
+		prepaid := req.PrepaidAmount != nil || existing.PrepaidAmount != nil
+		from, err := priceEffectiveFrom(priceFrom, startDate, endDate, prepaid)
+		if err != nil {
+			s.logger.Warn(ctx, "Invalid price effective date",
+				"subscription_id", id,
+				"price_effective_from", req.PriceEffectiveFrom,
+				"error", err,
+			)
+			return nil, err
+		}
+		subscription.PriceFrom = &from
 	}
 
-	s.logger.Info(ctx, "Subscription updated successfully", "subscription_id", id)
-	return nil
+	// Синхронизации часто повторяют PUT без изменений. Такой запрос не пишем в базу,
+	// чтобы не сдвигать updated_at и change_seq и не добавлять запись в журнал изменений
+	if contentHash(existing) == contentHash(subscription) {
+		s.logger.Debug(ctx, "Subscription is unchanged, skipping update", "subscription_id", id)
+		return nil, nil
+	}
+
+	// Пустое состояние репозиторий не меняет; expired вычисляется и в базу не пишется
+	if status == existing.Status {
+		subscription.Status = ""
+	}
+	return subscription, nil
 }
 
 func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
@@ -172,9 +605,9 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID)
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
-	if subscription == nil {
+	if subscription == nil || !auth.CanAccessUser(ctx, subscription.UserID) {
 		s.logger.Warn(ctx, "Subscription not found", "subscription_id", id)
-		return nil, fmt.Errorf("subscription not found")
+		return nil, ErrSubscriptionNotFound
 	}
 
 	s.logger.Debug(ctx, "Subscription retrieved successfully",
@@ -185,10 +618,35 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id uuid.UUID)
 	return subscription, nil
 }
 
+func (s *subscriptionService) ListSubscriptionPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error) {
+	subscription, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := s.repo.ListPrices(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list subscription prices from repository",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription prices: %w", err)
+	}
+
+	return model.PriceHistory(subscription, recorded), nil
+}
+
 func (s *subscriptionService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
 	s.logger.Info(ctx, "Deleting subscription", "subscription_id", id)
 
+	if err := s.requireOwner(ctx, id); err != nil {
+		return err
+	}
 	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Warn(ctx, "Subscription not found for deletion", "subscription_id", id)
+			return ErrSubscriptionNotFound
+		}
 		s.logger.Error(ctx, "Failed to delete subscription from repository",
 			"subscription_id", id,
 			"error", err,
@@ -200,65 +658,857 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, id uuid.UU
 	return nil
 }
 
-func (s *subscriptionService) ListSubscriptions(ctx context.Context, userID *uuid.UUID, serviceName *string) ([]*model.Subscription, error) {
-	s.logger.Debug(ctx, "Listing subscriptions",
-		"user_id", userID,
-		"service_name", serviceName,
-	)
+// requireOwner возвращает ErrSubscriptionNotFound, если подписки id нет или она принадлежит
+// другому пользователю: пользователь без прав администратора чужих подписок не видит.
+// Без аутентификации и администратору подписка не загружается
+func (s *subscriptionService) requireOwner(ctx context.Context, id uuid.UUID) error {
+	return requireSubscriptionOwner(ctx, s.repo, s.logger, id)
+}
 
-	subscriptions, err := s.repo.List(ctx, userID, serviceName)
+// requireSubscriptionOwner - проверка requireOwner для сервисов, которые хранят данные
+// подписки отдельно от нее
+func requireSubscriptionOwner(ctx context.Context, repo repository.SubscriptionRepository, log *logger.Logger, id uuid.UUID) error {
+	if !auth.Restricted(ctx) {
+		return nil
+	}
+	existing, err := repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to list subscriptions from repository",
-			"user_id", userID,
-			"service_name", serviceName,
+		log.Error(ctx, "Failed to check subscription owner",
+			"subscription_id", id,
 			"error", err,
 		)
-		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		return fmt.Errorf("failed to check subscription: %w", err)
 	}
+	if existing == nil || !auth.CanAccessUser(ctx, existing.UserID) {
+		log.Warn(ctx, "Subscription not found for caller", "subscription_id", id)
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
 
-	s.logger.Debug(ctx, "Subscriptions listed successfully",
-		"count", len(subscriptions),
-		"user_id", userID,
-	)
+func (s *subscriptionService) ActivateSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	s.logger.Info(ctx, "Activating subscription", "subscription_id", id)
 
-	return subscriptions, nil
-}
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check subscription existence",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to check subscription: %w", err)
+	}
+	if existing == nil || !auth.CanAccessUser(ctx, existing.UserID) {
+		s.logger.Warn(ctx, "Subscription not found for activation", "subscription_id", id)
+		return nil, ErrSubscriptionNotFound
+	}
+	if !existing.IsDraft {
+		s.logger.Warn(ctx, "Subscription is already active", "subscription_id", id)
+		return nil, ErrSubscriptionAlreadyActive
+	}
 
-func (s *subscriptionService) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
-	s.logger.Info(ctx, "Calculating total cost",
-		"start_period", filter.StartPeriod,
-		"end_period", filter.EndPeriod,
-		"user_id", filter.UserID,
-		"service_name", filter.ServiceName,
-	)
+	if err := s.repo.Activate(ctx, id); err != nil {
+		s.logger.Error(ctx, "Failed to activate subscription in repository",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, subscriptionError(err, "activate")
+	}
 
-	total, err := s.repo.CalculateTotalCost(ctx, filter)
+	activated, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to calculate total cost",
-			"start_period", filter.StartPeriod,
-			"end_period", filter.EndPeriod,
+		s.logger.Error(ctx, "Failed to get activated subscription from repository",
+			"subscription_id", id,
 			"error", err,
 		)
-		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if activated == nil {
+		s.logger.Warn(ctx, "Subscription not found after activation", "subscription_id", id)
+		return nil, ErrSubscriptionNotFound
 	}
 
-	s.logger.Info(ctx, "Total cost calculated successfully",
-		"total_cost", total,
-		"start_period", filter.StartPeriod,
-		"end_period", filter.EndPeriod,
+	s.logger.Info(ctx, "Subscription activated successfully", "subscription_id", id)
+	return activated, nil
+}
+
+func (s *subscriptionService) CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+	s.logger.Info(ctx, "Cancelling subscription",
+		"subscription_id", id,
+		"effective_period", req.EffectivePeriod,
+		"at_period_end", req.AtPeriodEnd,
 	)
 
-	return &model.SummaryResponse{TotalCost: total}, nil
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check subscription existence",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to check subscription: %w", err)
+	}
+	if existing == nil || !auth.CanAccessUser(ctx, existing.UserID) {
+		s.logger.Warn(ctx, "Subscription not found for cancellation", "subscription_id", id)
+		return nil, ErrSubscriptionNotFound
+	}
+	if err := checkStatusTransition(existing.Status, model.StatusCancelled); err != nil {
+		s.logger.Warn(ctx, "Invalid subscription status transition",
+			"subscription_id", id,
+			"from", existing.Status,
+			"to", model.StatusCancelled,
+		)
+		return nil, err
+	}
+
+	endDate, err := cancellationEndDate(existing, req, model.CurrentMonth())
+	if err != nil {
+		s.logger.Warn(ctx, "Invalid cancellation request",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	if err := s.repo.Cancel(ctx, id, endDate, req.Reason); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		s.logger.Error(ctx, "Failed to cancel subscription in repository",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	s.logger.Info(ctx, "Subscription cancelled successfully",
+		"subscription_id", id,
+		"end_date", endDate,
+	)
+	return s.GetSubscription(ctx, id)
+}
+
+func (s *subscriptionService) TransferSubscription(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+	s.logger.Info(ctx, "Transferring subscription",
+		"subscription_id", id,
+		"to_user_id", req.UserID,
+	)
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check subscription existence",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to check subscription: %w", err)
+	}
+	if existing == nil {
+		s.logger.Warn(ctx, "Subscription not found for transfer", "subscription_id", id)
+		return nil, ErrSubscriptionNotFound
+	}
+	if existing.UserID == req.UserID {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid transfer: subscription already belongs to user %s", req.UserID)
+	}
+	if existing.Status == model.StatusCancelled || existing.Status == model.StatusExpired {
+		s.logger.Warn(ctx, "Subscription cannot be transferred",
+			"subscription_id", id,
+			"status", existing.Status,
+		)
+		return nil, fmt.Errorf("%w: it is %s", ErrTransferNotAllowed, existing.Status)
+	}
+
+	if err := s.repo.Transfer(ctx, id, existing.UserID, req.UserID, req.Reason); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("%w: it was changed concurrently", ErrTransferNotAllowed)
+		}
+		s.logger.Error(ctx, "Failed to transfer subscription in repository",
+			"subscription_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to transfer subscription: %w", err)
+	}
+
+	s.logger.Info(ctx, "Subscription transferred successfully",
+		"subscription_id", id,
+		"from_user_id", existing.UserID,
+		"to_user_id", req.UserID,
+	)
+	return s.GetSubscription(ctx, id)
+}
+
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+	filter, err := scopeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Listing subscriptions",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"status", filter.Status,
+		"limit", page.Limit,
+		"offset", page.Offset,
+		"count", page.Count,
+	)
+
+	subscriptions, total, err := s.repo.List(ctx, filter, page)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list subscriptions from repository",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	s.logger.Debug(ctx, "Subscriptions listed successfully",
+		"count", len(subscriptions),
+		"total", total,
+		"user_id", filter.UserID,
+	)
+
+	return &model.SubscriptionPage{Items: subscriptions, Total: total, Estimated: page.Count == model.CountEstimate}, nil
+}
+
+// ListSubscriptionsAfter читает на одну подписку больше limit, чтобы без COUNT узнать,
+// есть ли следующая страница
+func (s *subscriptionService) ListSubscriptionsAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error) {
+	filter, err := scopeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Listing subscriptions after cursor",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"status", filter.Status,
+		"after", after,
+		"limit", limit,
+	)
+
+	subscriptions, err := s.repo.ListAfter(ctx, filter, after, limit+1)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list subscriptions page from repository",
+			"user_id", filter.UserID,
+			"service_name", filter.ServiceName,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	page := &model.SubscriptionCursorPage{Items: subscriptions}
+	if len(subscriptions) > limit {
+		page.Items = subscriptions[:limit]
+		last := page.Items[limit-1]
+		page.Next = &model.SubscriptionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// SearchSubscriptions ищет подписки по названию сервиса без учета регистра и диакритики,
+// лучшие совпадения идут первыми
+func (s *subscriptionService) SearchSubscriptions(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, model.Invalid(model.ErrInvalidInput, "search query is empty")
+	}
+	userID, err := auth.ScopeUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "Searching subscriptions",
+		"query", query,
+		"user_id", userID,
+	)
+
+	subscriptions, err := s.repo.Search(ctx, query, userID, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to search subscriptions in repository",
+			"query", query,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to search subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (s *subscriptionService) ExportSubscriptions(ctx context.Context, fn func(*model.Subscription) error) error {
+	// Выгрузка содержит подписки всех пользователей
+	if err := auth.RequireAdmin(ctx, "export"); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Exporting subscriptions")
+
+	var cursor *model.SubscriptionCursor
+	exported := 0
+	for {
+		page, err := s.repo.ListAfter(ctx, model.SubscriptionFilter{}, cursor, exportPageSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to read subscriptions page for export",
+				"exported", exported,
+				"error", err,
+			)
+			return fmt.Errorf("failed to export subscriptions: %w", err)
+		}
+
+		for _, sub := range page {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+		exported += len(page)
+
+		if len(page) < exportPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		cursor = &model.SubscriptionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	s.logger.Info(ctx, "Subscriptions exported successfully",
+		"count", exported,
+	)
+	return nil
+}
+
+func (s *subscriptionService) StreamSubscriptions(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	filter, err := scopeFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Streaming subscriptions",
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"status", filter.Status,
+	)
+
+	streamed := 0
+	err = s.repo.Stream(ctx, filter, func(sub *model.Subscription) error {
+		streamed++
+		return fn(sub)
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to stream subscriptions",
+			"streamed", streamed,
+			"error", err,
+		)
+		return err
+	}
+
+	s.logger.Info(ctx, "Subscriptions streamed successfully",
+		"count", streamed,
+	)
+	return nil
+}
+
+func (s *subscriptionService) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	s.logger.Debug(ctx, "Listing user services", "user_id", userID)
+
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+	services, err := s.repo.ListUserServices(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list user services from repository",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list user services: %w", err)
+	}
+
+	return services, nil
+}
+
+func (s *subscriptionService) ListChanges(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error) {
+	s.logger.Debug(ctx, "Listing subscription changes",
+		"since_seq", sinceSeq,
+		"limit", limit,
+	)
+
+	// Журнал содержит образы подписок всех пользователей, как и полная выгрузка
+	if err := auth.RequireAdmin(ctx, "changes feed"); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.repo.ListChanges(ctx, sinceSeq, limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list subscription changes from repository",
+			"since_seq", sinceSeq,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list subscription changes: %w", err)
+	}
+
+	// Курсор не двигается, если изменений нет, чтобы клиент продолжал опрос с той же позиции
+	nextSinceSeq := sinceSeq
+	if len(changes) > 0 {
+		nextSinceSeq = changes[len(changes)-1].Seq
+	}
+	if changes == nil {
+		changes = []*model.SubscriptionChange{}
+	}
+
+	s.logger.Debug(ctx, "Subscription changes listed successfully",
+		"count", len(changes),
+		"next_since_seq", nextSinceSeq,
+	)
+
+	return &model.ChangesResponse{Items: changes, NextSinceSeq: nextSinceSeq}, nil
+}
+
+func (s *subscriptionService) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+	if filter.Signed && s.signer == nil {
+		return nil, errSigningDisabled
+	}
+	if _, err := model.ParseSummaryGroupBy(filter.GroupBy); err != nil {
+		return nil, err
+	}
+	// Подпись охватывает только итоги, поэтому группы без подписи не возвращаются вместе с ней
+	if filter.Signed && filter.GroupBy != "" {
+		return nil, errSignedGroups
+	}
+
+	var requested *uuid.UUID
+	if filter.UserID != uuid.Nil {
+		requested = &filter.UserID
+	}
+	userID, err := auth.ScopeUserID(ctx, requested)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil {
+		filter.UserID = *userID
+	}
+
+	s.logger.Info(ctx, "Calculating total cost",
+		"start_period", filter.StartPeriod,
+		"end_period", filter.EndPeriod,
+		"user_id", filter.UserID,
+		"service_name", filter.ServiceName,
+		"group_by", filter.GroupBy,
+	)
+
+	totals, err := s.repo.CalculateTotalCost(ctx, filter)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to calculate total cost",
+			"start_period", filter.StartPeriod,
+			"end_period", filter.EndPeriod,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	policy, err := s.taxes.Tax(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to resolve tax policy",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	total := money.Round(totals.Total, policy.Rounding)
+	active := money.Round(totals.Active, policy.Rounding)
+	cancelled := money.Round(totals.Cancelled, policy.Rounding)
+
+	// Корректировки развертывания применяются к суммам в хранимом виде, до пересчета налога
+	var adjustments []model.SummaryAdjustment
+	for _, m := range s.modifiers {
+		adjustment, err := m.Modify(ctx, modifier.Request{Filter: filter, ActiveCost: active, CancelledCost: cancelled})
+		if err != nil {
+			s.logger.Error(ctx, "Summary modifier failed",
+				"modifier", m.Name(),
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to apply summary modifier %s: %w", m.Name(), err)
+		}
+		if adjustment == nil {
+			continue
+		}
+		adjustment.Modifier = m.Name()
+		active += adjustment.ActiveCost
+		cancelled += adjustment.CancelledCost
+		total += adjustment.ActiveCost + adjustment.CancelledCost
+		adjustments = append(adjustments, *adjustment)
+	}
+
+	response := &model.SummaryResponse{
+		TotalCost:     total,
+		ActiveCost:    active,
+		CancelledCost: cancelled,
+		Adjustments:   adjustments,
+	}
+	switch {
+	case filter.GroupBy == model.SummaryGroupByService:
+		response.GroupBy = filter.GroupBy
+		response.Breakdown = summaryBreakdown(totals.Groups, policy.Rounding)
+	case filter.GroupBy != "":
+		response.GroupBy = filter.GroupBy
+		response.Groups = make([]model.SummaryGroup, 0, len(totals.Groups))
+		for _, g := range totals.Groups {
+			response.Groups = append(response.Groups, model.SummaryGroup{
+				Key:           g.Key,
+				TotalCost:     money.Round(g.Total, policy.Rounding),
+				ActiveCost:    money.Round(g.Active, policy.Rounding),
+				CancelledCost: money.Round(g.Cancelled, policy.Rounding),
+			})
+		}
+	}
+
+	// Пересчитываем суммы с учетом налога, если запрошен конкретный вид суммы
+	if filter.Amount != "" {
+		amountType, err := money.ParseAmountType(filter.Amount)
+		if err != nil {
+			s.logger.Error(ctx, "Invalid amount type",
+				"amount", filter.Amount,
+				"error", err,
+			)
+			return nil, err
+		}
+
+		_, tax, _ := policy.Split(total)
+		response.TotalCost = convertAmount(policy, total, amountType)
+		response.ActiveCost = convertAmount(policy, active, amountType)
+		response.CancelledCost = convertAmount(policy, cancelled, amountType)
+		for i := range response.Groups {
+			g := &response.Groups[i]
+			g.TotalCost = convertAmount(policy, g.TotalCost, amountType)
+			g.ActiveCost = convertAmount(policy, g.ActiveCost, amountType)
+			g.CancelledCost = convertAmount(policy, g.CancelledCost, amountType)
+		}
+		for i := range response.Breakdown {
+			response.Breakdown[i].Total = convertAmount(policy, response.Breakdown[i].Total, amountType)
+		}
+		response.AmountType = string(amountType)
+		response.TaxRate = policy.RatePercent()
+		response.TaxAmount = &tax
+	}
+
+	if filter.Signed {
+		s.signSummary(ctx, response, filter)
+	}
+
+	s.logger.Info(ctx, "Total cost calculated successfully",
+		"total_cost", response.TotalCost,
+		"amount_type", response.AmountType,
+		"start_period", filter.StartPeriod,
+		"end_period", filter.EndPeriod,
+	)
+
+	return response, nil
+}
+
+// summaryBreakdown переводит группы итогов по сервисам в суммы сервисов по убыванию,
+// сервисы с равной суммой - по названию
+func summaryBreakdown(groups []model.CostGroup, mode money.RoundingMode) []model.SummaryBreakdown {
+	breakdown := make([]model.SummaryBreakdown, 0, len(groups))
+	for _, g := range groups {
+		if g.Key == nil {
+			continue
+		}
+		breakdown = append(breakdown, model.SummaryBreakdown{
+			ServiceName: *g.Key,
+			Months:      g.Months,
+			Total:       money.Round(g.Total, mode),
+		})
+	}
+	sort.SliceStable(breakdown, func(i, j int) bool {
+		if breakdown[i].Total != breakdown[j].Total {
+			return breakdown[i].Total > breakdown[j].Total
+		}
+		return breakdown[i].ServiceName < breakdown[j].ServiceName
+	})
+	return breakdown
+}
+
+// errSigningDisabled - подпись итогов запрошена, но SUMMARY_SIGNING_KEY не задан
+var errSigningDisabled = model.Invalid(model.ErrInvalidInput, "invalid signed: summary signing is not configured")
+
+// errSignedGroups - подпись запрошена вместе с группировкой
+var errSignedGroups = model.Invalid(model.ErrInvalidInput, "invalid signed: grouped summary cannot be signed")
+
+// signSummary добавляет к итогам параметры расчета с фильтром после ограничения
+// пользователем токена и подписывает их
+func (s *subscriptionService) signSummary(ctx context.Context, response *model.SummaryResponse, filter model.SummaryFilter) {
+	calc := &model.SummaryCalculation{
+		AlgorithmVersion: model.SummaryAlgorithmVersion,
+		Tenant:           ctxutil.TenantID(ctx),
+		Filters: model.SummaryCalculationFilters{
+			StartPeriod:         filter.StartPeriod,
+			EndPeriod:           filter.EndPeriod,
+			ServiceName:         filter.ServiceName,
+			ServiceID:           filter.ServiceID,
+			Tag:                 filter.Tag,
+			Amount:              filter.Amount,
+			ExcludeServiceNames: filter.ExcludeServiceNames,
+			ExcludeUserIDs:      filter.ExcludeUserIDs,
+		},
+		CalculatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if filter.UserID != uuid.Nil {
+		userID := filter.UserID
+		calc.Filters.UserID = &userID
+	}
+	response.Calculation = calc
+	response.Signature = &model.SummarySignature{
+		Algorithm: signing.Algorithm,
+		KeyID:     s.signer.KeyID(),
+		Value:     s.signer.Sign(response.SigningPayload()),
+	}
+}
+
+func (s *subscriptionService) VerifySummary(ctx context.Context, summary *model.SummaryResponse) (*model.SummaryVerification, error) {
+	if s.signer == nil {
+		return nil, errSigningDisabled
+	}
+
+	var reason string
+	switch {
+	case summary.Calculation == nil || summary.Signature == nil:
+		reason = "calculation and signature are required"
+	case summary.Signature.Algorithm != signing.Algorithm:
+		reason = fmt.Sprintf("unsupported algorithm %q", summary.Signature.Algorithm)
+	case summary.Signature.KeyID != s.signer.KeyID():
+		reason = fmt.Sprintf("unknown key %q", summary.Signature.KeyID)
+	case !s.signer.Verify(summary.SigningPayload(), summary.Signature.Value):
+		reason = "signature mismatch"
+	}
+
+	if reason != "" {
+		s.logger.Warn(ctx, "Summary signature rejected", "reason", reason)
+		return &model.SummaryVerification{Valid: false, Reason: reason}, nil
+	}
+	return &model.SummaryVerification{Valid: true}, nil
+}
+
+// convertAmount переводит хранимую сумму в запрошенный вид (с налогом или без)
+func convertAmount(policy money.Tax, amount int, amountType money.AmountType) int {
+	net, _, gross := policy.Split(amount)
+	if amountType == money.AmountNet {
+		return net
+	}
+	return gross
+}
+
+// applyPrepaid проверяет, что годовая предоплата покрывает ровно 12 месяцев
+// (при отсутствии end_date он вычисляется), и возвращает ежемесячную долю предоплаты.
+// Для обычных подписок значения возвращаются без изменений.
+func (s *subscriptionService) applyPrepaid(ctx context.Context, startDate time.Time, endDate *time.Time, prepaidAmount *int, monthlyCost int) (*time.Time, int, error) {
+	if prepaidAmount == nil {
+		return endDate, monthlyCost, nil
+	}
+
+	// end_date - последний оплаченный месяц включительно
+	prepaidEnd := startDate.AddDate(0, 11, 0)
+	if endDate == nil {
+		endDate = &prepaidEnd
+	} else if !endDate.Equal(prepaidEnd) {
+		return nil, 0, model.Invalid(model.ErrInvalidPeriod, "prepaid subscription must cover exactly 12 months, expected end date %s", prepaidEnd.Format("01-2006"))
+	}
+
+	policy, err := s.taxes.Tax(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to resolve rounding: %w", err)
+	}
+	// monthly_cost хранится для совместимости и не может быть нулевым даже для очень малых сумм
+	monthly := max(money.Round(big.NewRat(int64(*prepaidAmount), 12), policy.Rounding), 1)
+	return endDate, monthly, nil
+}
+
+// applyBillingPeriod проверяет период оплаты и возвращает его вместе со стоимостью
+// за период и ее ежемесячным эквивалентом. Cost помесячной подписки становится ее
+// monthly_cost и отдельно не хранится
+func (s *subscriptionService) applyBillingPeriod(ctx context.Context, period string, cost *int, monthlyCost int, prepaidAmount *int) (string, *int, int, error) {
+	if period == "" {
+		period = model.BillingMonthly
+	}
+	if cost == nil {
+		if period != model.BillingMonthly {
+			return "", nil, 0, model.Invalid(model.ErrInvalidInput, "cost is required for %s billing period", period)
+		}
+		return period, nil, monthlyCost, nil
+	}
+	if monthlyCost != 0 || prepaidAmount != nil {
+		return "", nil, 0, model.Invalid(model.ErrInvalidInput, "cost must not be combined with monthly_cost or prepaid_amount")
+	}
+	if period == model.BillingMonthly {
+		return period, nil, *cost, nil
+	}
+
+	policy, err := s.taxes.Tax(ctx)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("failed to resolve rounding: %w", err)
+	}
+	// monthly_cost не может быть нулевым даже для очень малых сумм, как и у предоплаты
+	monthly := max(money.Round(model.MonthlyRate(period, *cost), policy.Rounding), 1)
+	return period, cost, monthly, nil
+}
+
+// validateFree проверяет, что у бесплатной подписки не задана стоимость. Нулевая
+// monthly_cost хранится только у бесплатных подписок
+func validateFree(isFree bool, monthlyCost int, prepaidAmount, cost *int) error {
+	if isFree && (monthlyCost != 0 || prepaidAmount != nil || cost != nil) {
+		return model.Invalid(model.ErrInvalidInput, "free subscription must not have monthly_cost, prepaid_amount or cost")
+	}
+	return nil
+}
+
+// contentHash возвращает хеш полей подписки, которые изменяет PUT
+func contentHash(sub *model.Subscription) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s\x00", sub.ServiceName, sub.MonthlyCost, sub.UserID, sub.StartDate.Format("01-2006"))
+	if sub.EndDate != nil {
+		fmt.Fprint(h, sub.EndDate.Format("01-2006"))
+	}
+	h.Write([]byte{0})
+	if sub.PrepaidAmount != nil {
+		fmt.Fprint(h, *sub.PrepaidAmount)
+	}
+	fmt.Fprintf(h, "\x00%s", sub.Status)
+	// Метки добавляются в хеш только непустыми, чтобы хеш подписок без меток не изменился
+	if len(sub.Metadata) > 0 {
+		keys := make([]string, 0, len(sub.Metadata))
+		for key := range sub.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(h, "\x00%s=%s", key, sub.Metadata[key])
+		}
+	}
+	// Ключи меток не содержат '#', поэтому service_id, описание и теги не совпадут с меткой
+	if sub.ServiceID != nil {
+		fmt.Fprintf(h, "\x00#service_id=%s", sub.ServiceID)
+	}
+	if sub.Description != nil {
+		fmt.Fprintf(h, "\x00#description=%s", *sub.Description)
+	}
+	// Помесячная оплата не добавляется, чтобы хеш прежних подписок не изменился
+	if sub.Cost != nil {
+		fmt.Fprintf(h, "\x00#billing=%s:%d", sub.BillingPeriod, *sub.Cost)
+	}
+	for _, tag := range sub.Tags {
+		fmt.Fprintf(h, "\x00#tag=%s", tag)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checkStatusTransition проверяет переход состояния по statusTransitions
+func checkStatusTransition(from, to string) error {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, from, to)
+}
+
+// cancelledEndDate возвращает end_date отмененной подписки. При отмене подписка
+// заканчивается текущим месяцем, если не закончилась раньше; у уже отмененной
+// подписки дата отмены сохраняется
+func cancelledEndDate(existing *model.Subscription, startDate time.Time, endDate *time.Time) *time.Time {
+	if existing.Status == model.StatusCancelled {
+		return existing.EndDate
+	}
+	month := model.CurrentMonth()
+	if month.Before(startDate) {
+		// Подписка отменена до начала: остается только первый месяц
+		month = startDate
+	}
+	if endDate != nil && endDate.Before(month) {
+		return endDate
+	}
+	return &month
+}
+
+// cancellationEndDate возвращает последний месяц отменяемой подписки. В конце срока
+// подписка заканчивается своим end_date (годовая предоплата, срочная подписка), а без
+// него - текущим месяцем, за который уже заплачено. При немедленной отмене - месяцем
+// effective_period или текущим. Ошибки начинаются с "invalid cancellation"
+func cancellationEndDate(existing *model.Subscription, req model.CancelSubscriptionRequest, month time.Time) (time.Time, error) {
+	if req.AtPeriodEnd && req.EffectivePeriod != nil {
+		return time.Time{}, model.Invalid(model.ErrInvalidInput, "invalid cancellation: effective_period cannot be combined with at_period_end")
+	}
+	if req.AtPeriodEnd && existing.EndDate != nil {
+		return *existing.EndDate, nil
+	}
+
+	endDate := month
+	if endDate.Before(existing.StartDate) {
+		// Подписка еще не началась: по умолчанию остается только первый месяц
+		endDate = existing.StartDate
+	}
+	if req.EffectivePeriod != nil {
+		parsed, err := model.ParseMonthYear(*req.EffectivePeriod)
+		if err != nil {
+			return time.Time{}, model.Invalid(model.ErrInvalidInput, "invalid cancellation: effective period format, expected MM-YYYY: %w", err)
+		}
+		endDate = parsed
+	}
+
+	if endDate.Before(existing.StartDate) {
+		return time.Time{}, model.Invalid(model.ErrInvalidInput, "invalid cancellation: effective period cannot be before start date")
+	}
+	if existing.EndDate != nil && endDate.After(*existing.EndDate) {
+		return time.Time{}, model.Invalid(model.ErrInvalidInput, "invalid cancellation: effective period cannot be after end date")
+	}
+	return endDate, nil
+}
+
+// periodsOverlap сообщает, что периоды подписок (nil end - бессрочная) имеют общий месяц
+func periodsOverlap(startA time.Time, endA *time.Time, startB time.Time, endB *time.Time) bool {
+	return (endB == nil || !startA.After(*endB)) && (endA == nil || !startB.After(*endA))
+}
+
+// scopeFilter ограничивает фильтр списка подписками пользователя запроса
+func scopeFilter(ctx context.Context, filter model.SubscriptionFilter) (model.SubscriptionFilter, error) {
+	userID, err := auth.ScopeUserID(ctx, filter.UserID)
+	if err != nil {
+		return filter, err
+	}
+	filter.UserID = userID
+	return filter, nil
+}
+
+// priceEffectiveFrom выбирает месяц, с которого действует новая стоимость подписки:
+// requested или по умолчанию текущий месяц. Стоимость подписки, которая еще не началась
+// или уже закончилась, и годовой предоплаты (она покрывает весь срок) заменяется целиком -
+// с месяца начала. Месяц не может быть раньше начала, позже конца подписки или в будущем
+func priceEffectiveFrom(requested *time.Time, startDate time.Time, endDate *time.Time, prepaid bool) (time.Time, error) {
+	current := model.CurrentMonth()
+	if requested == nil {
+		if prepaid || current.Before(startDate) || (endDate != nil && endDate.Before(current)) {
+			return startDate, nil
+		}
+		return current, nil
+	}
+
+	if prepaid && !requested.Equal(startDate) {
+		return time.Time{}, model.Invalid(model.ErrInvalidPeriod, "price of a prepaid subscription can only change from its start date %s", startDate.Format("01-2006"))
+	}
+	latest := current
+	if latest.Before(startDate) {
+		latest = startDate
+	}
+	if endDate != nil && endDate.Before(latest) {
+		latest = *endDate
+	}
+	if requested.Before(startDate) || requested.After(latest) {
+		return time.Time{}, model.Invalid(model.ErrInvalidPeriod, "price_effective_from must be between %s and %s", startDate.Format("01-2006"), latest.Format("01-2006"))
+	}
+	return *requested, nil
 }
 
 func validateDates(startDate time.Time, endDate *time.Time) error {
 	if startDate.IsZero() {
-		return fmt.Errorf("start date is required")
+		return model.Invalid(model.ErrInvalidInput, "start date is required")
 	}
 
 	if endDate != nil && !endDate.IsZero() {
 		if endDate.Before(startDate) {
-			return fmt.Errorf("end date cannot be before start date")
+			return model.Invalid(model.ErrInvalidPeriod, "end date cannot be before start date")
 		}
 	}
 
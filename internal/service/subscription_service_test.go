@@ -0,0 +1,729 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/modifier"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/signing"
+
+	"github.com/google/uuid"
+)
+
+type updateRepoStub struct {
+	repository.SubscriptionRepository
+	existing *model.Subscription
+	updates  int
+	updated  *model.Subscription
+}
+
+func (r *updateRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	return r.existing, nil
+}
+
+func (r *updateRepoStub) Update(ctx context.Context, id uuid.UUID, sub *model.Subscription) error {
+	r.updates++
+	r.updated = sub
+	return nil
+}
+
+func TestUpdateSubscriptionSkipsUnchangedPayload(t *testing.T) {
+	userID := uuid.New()
+	prepaid := 4800
+	endDate := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		req         model.UpdateSubscriptionRequest
+		wantUpdates int
+	}{
+		{
+			name:        "identical",
+			req:         model.UpdateSubscriptionRequest{ServiceName: "Yandex Plus", UserID: userID, StartDate: "07-2025", PrepaidAmount: &prepaid},
+			wantUpdates: 0,
+		},
+		{
+			name:        "identical with explicit end date",
+			req:         model.UpdateSubscriptionRequest{ServiceName: "Yandex Plus", UserID: userID, StartDate: "07-2025", EndDate: strPtr("06-2026"), PrepaidAmount: &prepaid},
+			wantUpdates: 0,
+		},
+		{
+			name:        "changed service name",
+			req:         model.UpdateSubscriptionRequest{ServiceName: "Kinopoisk", UserID: userID, StartDate: "07-2025", PrepaidAmount: &prepaid},
+			wantUpdates: 1,
+		},
+		{
+			name:        "prepaid replaced by monthly cost",
+			req:         model.UpdateSubscriptionRequest{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: userID, StartDate: "07-2025", EndDate: strPtr("06-2026")},
+			wantUpdates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updateRepoStub{existing: &model.Subscription{
+				ID:            uuid.New(),
+				ServiceName:   "Yandex Plus",
+				MonthlyCost:   400,
+				UserID:        userID,
+				StartDate:     time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:       &endDate,
+				PrepaidAmount: &prepaid,
+			}}
+
+			if err := newExportTestService(repo).UpdateSubscription(context.Background(), repo.existing.ID, tt.req); err != nil {
+				t.Fatalf("UpdateSubscription() error = %v", err)
+			}
+			if repo.updates != tt.wantUpdates {
+				t.Errorf("repository updates = %d, want %d", repo.updates, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestUpdateSubscriptionStatusTransitions(t *testing.T) {
+	userID := uuid.New()
+	currentMonth := model.CurrentMonth()
+	farEnd := currentMonth.AddDate(2, 0, 0)
+
+	tests := []struct {
+		name       string
+		from       string
+		to         *string
+		wantErr    string
+		wantStatus string
+		wantEnd    *time.Time
+	}{
+		{name: "pause active", from: model.StatusActive, to: strPtr(model.StatusPaused), wantStatus: model.StatusPaused, wantEnd: &farEnd},
+		{name: "resume paused", from: model.StatusPaused, to: strPtr(model.StatusActive), wantStatus: model.StatusActive, wantEnd: &farEnd},
+		{name: "cancel ends current month", from: model.StatusActive, to: strPtr(model.StatusCancelled), wantStatus: model.StatusCancelled, wantEnd: &currentMonth},
+		{name: "status omitted", from: model.StatusPaused, wantStatus: "", wantEnd: &farEnd},
+		{name: "cancelled is terminal", from: model.StatusCancelled, to: strPtr(model.StatusActive), wantErr: "invalid status transition from cancelled to active"},
+		{name: "expired is terminal", from: model.StatusExpired, to: strPtr(model.StatusPaused), wantErr: "invalid status transition from expired to paused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updateRepoStub{existing: &model.Subscription{
+				ID:          uuid.New(),
+				ServiceName: "Yandex Plus",
+				MonthlyCost: 400,
+				UserID:      userID,
+				StartDate:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:     &farEnd,
+				Status:      tt.from,
+			}}
+			req := model.UpdateSubscriptionRequest{
+				ServiceName: "Yandex Plus",
+				MonthlyCost: 500,
+				UserID:      userID,
+				StartDate:   "07-2025",
+				EndDate:     strPtr(farEnd.Format("01-2006")),
+				Status:      tt.to,
+			}
+
+			err := newExportTestService(repo).UpdateSubscription(context.Background(), repo.existing.ID, req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("UpdateSubscription() error = %v, want %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Errorf("error %v is not ErrInvalidStatusTransition", err)
+				}
+				if repo.updates != 0 {
+					t.Errorf("repository was updated on rejected transition")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSubscription() error = %v", err)
+			}
+			if repo.updated.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", repo.updated.Status, tt.wantStatus)
+			}
+			if repo.updated.EndDate == nil || !repo.updated.EndDate.Equal(*tt.wantEnd) {
+				t.Errorf("end date = %v, want %v", repo.updated.EndDate, *tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestUpdateSubscriptionPriceEffectiveFrom(t *testing.T) {
+	userID := uuid.New()
+	current := model.CurrentMonth()
+	start := current.AddDate(0, -6, 0)
+	future := current.AddDate(0, 2, 0)
+	past := start.AddDate(0, 2, 0)
+	prepaid := 6000
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		cost      int
+		prepaid   *int
+		requested *string
+		wantFrom  *time.Time
+		wantErr   error
+	}{
+		{name: "defaults to current month", start: start, cost: 500, wantFrom: &current},
+		{name: "explicit past month", start: start, cost: 500, requested: strPtr(past.Format("01-2006")), wantFrom: &past},
+		{name: "not started replaces price", start: future, cost: 500, wantFrom: &future},
+		{name: "price unchanged", start: start, cost: 400},
+		{name: "before start", start: start, cost: 500, requested: strPtr(start.AddDate(0, -1, 0).Format("01-2006")), wantErr: model.ErrInvalidPeriod},
+		{name: "future month", start: start, cost: 500, requested: strPtr(current.AddDate(0, 1, 0).Format("01-2006")), wantErr: model.ErrInvalidPeriod},
+		{name: "prepaid from start only", start: start, prepaid: &prepaid, requested: strPtr(current.Format("01-2006")), wantErr: model.ErrInvalidPeriod},
+		{name: "invalid format", start: start, cost: 500, requested: strPtr("2025-09"), wantErr: model.ErrInvalidPeriodFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updateRepoStub{existing: &model.Subscription{
+				ID:          uuid.New(),
+				ServiceName: "Netflix",
+				MonthlyCost: 400,
+				UserID:      userID,
+				StartDate:   tt.start,
+			}}
+			req := model.UpdateSubscriptionRequest{
+				ServiceName:        "Netflix",
+				MonthlyCost:        tt.cost,
+				PrepaidAmount:      tt.prepaid,
+				UserID:             userID,
+				StartDate:          tt.start.Format("01-2006"),
+				PriceEffectiveFrom: tt.requested,
+			}
+
+			err := newExportTestService(repo).UpdateSubscription(context.Background(), repo.existing.ID, req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateSubscription() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSubscription() error = %v", err)
+			}
+			if tt.wantFrom == nil {
+				if repo.updated != nil && repo.updated.PriceFrom != nil {
+					t.Errorf("price from = %v, want unchanged price", repo.updated.PriceFrom)
+				}
+				return
+			}
+			if repo.updated.PriceFrom == nil || !repo.updated.PriceFrom.Equal(*tt.wantFrom) {
+				t.Errorf("price from = %v, want %v", repo.updated.PriceFrom, *tt.wantFrom)
+			}
+		})
+	}
+}
+
+func TestCancellationEndDate(t *testing.T) {
+	month := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	prepaidEnd := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		end     *time.Time
+		req     model.CancelSubscriptionRequest
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "immediate defaults to current month", start: start, end: &prepaidEnd, want: month},
+		{name: "immediate with effective period", start: start, req: model.CancelSubscriptionRequest{EffectivePeriod: strPtr("06-2025")}, want: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "at period end keeps end date", start: start, end: &prepaidEnd, req: model.CancelSubscriptionRequest{AtPeriodEnd: true}, want: prepaidEnd},
+		{name: "at period end of open subscription", start: start, req: model.CancelSubscriptionRequest{AtPeriodEnd: true}, want: month},
+		{name: "not started yet", start: future, want: future},
+		{name: "effective period with at period end", start: start, req: model.CancelSubscriptionRequest{EffectivePeriod: strPtr("10-2025"), AtPeriodEnd: true}, wantErr: true},
+		{name: "effective period before start", start: start, req: model.CancelSubscriptionRequest{EffectivePeriod: strPtr("12-2024")}, wantErr: true},
+		{name: "effective period after end", start: start, end: &prepaidEnd, req: model.CancelSubscriptionRequest{EffectivePeriod: strPtr("01-2026")}, wantErr: true},
+		{name: "malformed effective period", start: start, req: model.CancelSubscriptionRequest{EffectivePeriod: strPtr("2025-10")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &model.Subscription{StartDate: tt.start, EndDate: tt.end, Status: model.StatusActive}
+
+			got, err := cancellationEndDate(existing, tt.req, month)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "invalid cancellation") {
+					t.Fatalf("cancellationEndDate() error = %v, want invalid cancellation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cancellationEndDate() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("cancellationEndDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+type batchRepoStub struct {
+	repository.SubscriptionRepository
+	batches [][]*model.Subscription
+	// failBatch - номер пачки (с 0), создание которой завершается ошибкой
+	failBatch int
+}
+
+func (r *batchRepoStub) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	r.batches = append(r.batches, append([]*model.Subscription(nil), subs...))
+	if len(r.batches)-1 == r.failBatch {
+		return errors.New("duplicate key")
+	}
+	return nil
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	userID := uuid.New()
+	var rows []model.ImportRow
+	for i := 0; i < importBatchSize*2+2; i++ {
+		rows = append(rows, model.ImportRow{
+			Line: i + 2,
+			Request: model.CreateSubscriptionRequest{
+				ServiceName: fmt.Sprintf("Service %d", i),
+				MonthlyCost: 100,
+				UserID:      userID,
+				StartDate:   "07-2025",
+			},
+		})
+	}
+	// Некорректная строка не попадает в пачку и не сдвигает границы пачек
+	rows[3].Request.StartDate = "2025-07"
+
+	repo := &batchRepoStub{failBatch: 1}
+	result, err := newExportTestService(repo).ImportSubscriptions(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("ImportSubscriptions() error = %v", err)
+	}
+
+	if len(repo.batches) != 3 || len(repo.batches[0]) != importBatchSize || len(repo.batches[2]) != 1 {
+		t.Fatalf("unexpected batches: %d", len(repo.batches))
+	}
+	if result.Imported != len(rows)-1-importBatchSize {
+		t.Errorf("imported = %d, want %d", result.Imported, len(rows)-1-importBatchSize)
+	}
+	if result.Failed != importBatchSize+1 || len(result.Errors) != result.Failed {
+		t.Fatalf("failed = %d, errors = %d, want %d", result.Failed, len(result.Errors), importBatchSize+1)
+	}
+	if result.Errors[0].Line != rows[3].Line || !strings.Contains(result.Errors[0].Error, "MM-YYYY") {
+		t.Errorf("first error = %+v, want invalid start date on line %d", result.Errors[0], rows[3].Line)
+	}
+	for i := 1; i < len(result.Errors); i++ {
+		if result.Errors[i].Line <= result.Errors[i-1].Line {
+			t.Fatalf("errors are not ordered by line: %+v", result.Errors[i-1:i+1])
+		}
+	}
+}
+
+// rowViolationRepoStub отклоняет пачку, в которой есть подписка на сервис invalid,
+// как база отклоняет COPY со строкой, нарушающей ограничение
+type rowViolationRepoStub struct {
+	repository.SubscriptionRepository
+	invalid  string
+	attempts int
+	created  []*model.Subscription
+}
+
+func (r *rowViolationRepoStub) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	r.attempts++
+	for i, sub := range subs {
+		if sub.ServiceName == r.invalid {
+			return &repository.BatchRowError{Row: i, Action: "create", Message: "new row violates check constraint"}
+		}
+	}
+	r.created = append(r.created, subs...)
+	return nil
+}
+
+func TestImportSubscriptionsRowViolation(t *testing.T) {
+	userID := uuid.New()
+	var rows []model.ImportRow
+	for i := 0; i < 5; i++ {
+		rows = append(rows, model.ImportRow{
+			Line: i + 2,
+			Request: model.CreateSubscriptionRequest{
+				ServiceName: fmt.Sprintf("Service %d", i),
+				MonthlyCost: 100,
+				UserID:      userID,
+				StartDate:   "07-2025",
+			},
+		})
+	}
+
+	repo := &rowViolationRepoStub{invalid: "Service 2"}
+	result, err := newExportTestService(repo).ImportSubscriptions(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("ImportSubscriptions() error = %v", err)
+	}
+
+	// Нарушившая ограничение строка не отклоняет остальные строки пачки
+	if result.Imported != 4 || len(repo.created) != 4 || repo.attempts != 2 {
+		t.Errorf("imported = %d, created = %d, attempts = %d, want 4, 4, 2", result.Imported, len(repo.created), repo.attempts)
+	}
+	if result.Failed != 1 || result.Errors[0].Line != 4 || !strings.Contains(result.Errors[0].Error, "check constraint") {
+		t.Errorf("errors = %+v, want constraint violation on line 4", result.Errors)
+	}
+}
+
+func TestTransferSubscriptionRejected(t *testing.T) {
+	owner := uuid.New()
+	ended := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		status  string
+		endDate *time.Time
+		to      uuid.UUID
+		wantErr string
+		wantIs  error
+	}{
+		{"same owner", model.StatusActive, nil, owner, "invalid transfer", model.ErrInvalidInput},
+		{"cancelled", model.StatusCancelled, nil, uuid.New(), "subscription cannot be transferred: it is cancelled", ErrTransferNotAllowed},
+		{"expired", model.StatusExpired, &ended, uuid.New(), "subscription cannot be transferred: it is expired", ErrTransferNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Transfer не реализован в заглушке: вызов репозитория здесь приведет к панике
+			repo := &updateRepoStub{existing: &model.Subscription{
+				ID:      uuid.New(),
+				UserID:  owner,
+				Status:  tt.status,
+				EndDate: tt.endDate,
+			}}
+			_, err := newExportTestService(repo).TransferSubscription(context.Background(), repo.existing.ID, model.TransferSubscriptionRequest{UserID: tt.to})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want prefix %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, tt.wantIs) {
+				t.Errorf("error %v is not %v", err, tt.wantIs)
+			}
+		})
+	}
+}
+
+type listRepoStub struct {
+	repository.SubscriptionRepository
+	items  []*model.Subscription
+	filter model.SubscriptionFilter
+}
+
+func (r *listRepoStub) List(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) ([]*model.Subscription, int, error) {
+	r.filter = filter
+	return r.items, len(r.items), nil
+}
+
+func TestValidateSubscription(t *testing.T) {
+	userID := uuid.New()
+	prepaid := 4800
+	// Периоды отсчитываются от текущего месяца, чтобы предупреждение expired зависело только от случая
+	month := model.CurrentMonth()
+	endDate := month.AddDate(0, 2, 0)
+	existing := &model.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Yandex Plus",
+		UserID:      userID,
+		StartDate:   month.AddDate(-1, 0, 0),
+		EndDate:     &endDate,
+	}
+
+	tests := []struct {
+		name         string
+		req          model.CreateSubscriptionRequest
+		wantValid    bool
+		wantWarnings []string
+		wantEndDate  string
+	}{
+		{
+			name:         "prepaid overlapping existing",
+			req:          model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", UserID: userID, StartDate: month.Format("01-2006"), PrepaidAmount: &prepaid},
+			wantValid:    true,
+			wantWarnings: []string{model.WarningOverlap},
+			wantEndDate:  month.AddDate(0, 11, 0).Format("01-2006"),
+		},
+		{
+			name:         "after existing ends",
+			req:          model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: userID, StartDate: month.AddDate(0, 3, 0).Format("01-2006")},
+			wantValid:    true,
+			wantWarnings: []string{},
+		},
+		{
+			name:         "ended in the past",
+			req:          model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: userID, StartDate: "01-2020", EndDate: strPtr("06-2020")},
+			wantValid:    true,
+			wantWarnings: []string{model.WarningExpired},
+			wantEndDate:  "06-2020",
+		},
+		{
+			name:      "end before start",
+			req:       model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: userID, StartDate: "07-2025", EndDate: strPtr("06-2025")},
+			wantValid: false,
+		},
+		{
+			name:         "free",
+			req:          model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", UserID: userID, StartDate: month.AddDate(0, 3, 0).Format("01-2006"), IsFree: true},
+			wantValid:    true,
+			wantWarnings: []string{},
+		},
+		{
+			name:      "free with cost",
+			req:       model.CreateSubscriptionRequest{ServiceName: "Yandex Plus", MonthlyCost: 400, UserID: userID, StartDate: month.AddDate(0, 3, 0).Format("01-2006"), IsFree: true},
+			wantValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &listRepoStub{items: []*model.Subscription{existing}}
+			result, err := newExportTestService(repo).ValidateSubscription(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("ValidateSubscription() error = %v", err)
+			}
+
+			if result.Valid != tt.wantValid || (result.Error == "") != tt.wantValid {
+				t.Fatalf("valid = %v, error = %q, want valid %v", result.Valid, result.Error, tt.wantValid)
+			}
+			if !tt.wantValid {
+				return
+			}
+
+			var codes []string
+			for _, w := range result.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if fmt.Sprint(codes) != fmt.Sprint(tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", codes, tt.wantWarnings)
+			}
+			if result.Normalized.IsFree != tt.req.IsFree {
+				t.Errorf("normalized is_free = %v, want %v", result.Normalized.IsFree, tt.req.IsFree)
+			}
+			if tt.wantEndDate != "" && (result.Normalized.EndDate == nil || *result.Normalized.EndDate != tt.wantEndDate) {
+				t.Errorf("normalized end date = %v, want %s", result.Normalized.EndDate, tt.wantEndDate)
+			}
+			if repo.filter.ServiceName == nil || *repo.filter.ServiceName != "Yandex Plus" || *repo.filter.UserID != userID {
+				t.Errorf("unexpected overlap filter: %+v", repo.filter)
+			}
+		})
+	}
+}
+
+func TestApplyBillingPeriod(t *testing.T) {
+	svc := newExportTestService(nil).(*subscriptionService)
+	prepaid := 4800
+
+	tests := []struct {
+		name        string
+		period      string
+		cost        *int
+		monthlyCost int
+		prepaid     *int
+		wantPeriod  string
+		wantCost    *int
+		wantMonthly int
+		wantErr     bool
+	}{
+		{name: "monthly cost by default", monthlyCost: 400, wantPeriod: model.BillingMonthly, wantMonthly: 400},
+		{name: "monthly cost field", period: model.BillingMonthly, cost: intPtr(400), wantPeriod: model.BillingMonthly, wantMonthly: 400},
+		{name: "yearly", period: model.BillingYearly, cost: intPtr(4990), wantPeriod: model.BillingYearly, wantCost: intPtr(4990), wantMonthly: 416},
+		{name: "weekly", period: model.BillingWeekly, cost: intPtr(150), wantPeriod: model.BillingWeekly, wantCost: intPtr(150), wantMonthly: 650},
+		{name: "small yearly cost", period: model.BillingYearly, cost: intPtr(5), wantPeriod: model.BillingYearly, wantCost: intPtr(5), wantMonthly: 1},
+		{name: "yearly without cost", period: model.BillingYearly, monthlyCost: 400, wantErr: true},
+		{name: "cost with monthly cost", period: model.BillingYearly, cost: intPtr(4990), monthlyCost: 400, wantErr: true},
+		{name: "cost with prepaid", period: model.BillingYearly, cost: intPtr(4990), prepaid: &prepaid, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, cost, monthly, err := svc.applyBillingPeriod(context.Background(), tt.period, tt.cost, tt.monthlyCost, tt.prepaid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyBillingPeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, model.ErrInvalidInput) {
+					t.Errorf("error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if period != tt.wantPeriod || monthly != tt.wantMonthly || !reflect.DeepEqual(cost, tt.wantCost) {
+				t.Errorf("got (%s, %v, %d), want (%s, %v, %d)", period, cost, monthly, tt.wantPeriod, tt.wantCost, tt.wantMonthly)
+			}
+		})
+	}
+}
+
+type totalsRepoStub struct {
+	repository.SubscriptionRepository
+	totals *model.CostTotals
+}
+
+func (r *totalsRepoStub) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.CostTotals, error) {
+	return r.totals, nil
+}
+
+type discountModifier struct{ percent int }
+
+func (m discountModifier) Name() string { return "company_discount" }
+
+func (m discountModifier) Modify(ctx context.Context, req modifier.Request) (*model.SummaryAdjustment, error) {
+	return &model.SummaryAdjustment{ActiveCost: -req.ActiveCost * m.percent / 100}, nil
+}
+
+func TestCalculateTotalCostModifiers(t *testing.T) {
+	repo := &totalsRepoStub{totals: &model.CostTotals{
+		Total:     big.NewRat(1200, 1),
+		Active:    big.NewRat(1000, 1),
+		Cancelled: big.NewRat(200, 1),
+	}}
+	tax, _ := money.NewTax("20", true, "half_up")
+	// Второй модификатор видит итоги после первого
+	modifiers := []modifier.CostModifier{discountModifier{percent: 10}, discountModifier{percent: 50}}
+	svc := NewSubscriptionService(repo, FixedTax(tax), modifiers, nil, logger.New(slog.LevelError+4))
+
+	result, err := svc.CalculateTotalCost(context.Background(), model.SummaryFilter{StartPeriod: "01-2025", EndPeriod: "12-2025", Amount: "net"})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost() error = %v", err)
+	}
+
+	if len(result.Adjustments) != 2 || result.Adjustments[0].ActiveCost != -100 || result.Adjustments[1].ActiveCost != -450 {
+		t.Fatalf("unexpected adjustments: %+v", result.Adjustments)
+	}
+	if result.Adjustments[0].Modifier != "company_discount" {
+		t.Errorf("modifier name = %q", result.Adjustments[0].Modifier)
+	}
+	// 1200 - 550 = 650 с налогом, без налога 20% - 541.67 -> 542
+	if result.TotalCost != 542 || result.CancelledCost != 167 {
+		t.Errorf("total = %d, cancelled = %d, want 542 and 167", result.TotalCost, result.CancelledCost)
+	}
+}
+
+func TestCalculateTotalCostBreakdown(t *testing.T) {
+	netflix, spotify, zoom := "Netflix", "Spotify", "Zoom"
+	repo := &totalsRepoStub{totals: &model.CostTotals{
+		Total:     big.NewRat(1700, 1),
+		Active:    big.NewRat(1700, 1),
+		Cancelled: new(big.Rat),
+		Groups: []model.CostGroup{
+			{Key: &netflix, Total: big.NewRat(500, 1), Months: 5},
+			{Key: &spotify, Total: big.NewRat(1000, 1), Months: 10},
+			{Key: &zoom, Total: big.NewRat(200, 1), Months: 2},
+		},
+	}}
+	tax, _ := money.NewTax("20", true, "half_up")
+	svc := NewSubscriptionService(repo, FixedTax(tax), nil, nil, logger.New(slog.LevelError+4))
+
+	result, err := svc.CalculateTotalCost(context.Background(), model.SummaryFilter{StartPeriod: "01-2025", EndPeriod: "12-2025", GroupBy: model.SummaryGroupByService})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost() error = %v", err)
+	}
+
+	// Сервисы по убыванию суммы, группы меток не возвращаются
+	want := []model.SummaryBreakdown{
+		{ServiceName: "Spotify", Months: 10, Total: 1000},
+		{ServiceName: "Netflix", Months: 5, Total: 500},
+		{ServiceName: "Zoom", Months: 2, Total: 200},
+	}
+	if !reflect.DeepEqual(result.Breakdown, want) {
+		t.Errorf("breakdown = %+v, want %+v", result.Breakdown, want)
+	}
+	if result.GroupBy != model.SummaryGroupByService || result.Groups != nil {
+		t.Errorf("group_by = %q, groups = %+v", result.GroupBy, result.Groups)
+	}
+}
+
+func TestCalculateTotalCostSigned(t *testing.T) {
+	repo := &totalsRepoStub{totals: &model.CostTotals{
+		Total:     big.NewRat(1200, 1),
+		Active:    big.NewRat(1000, 1),
+		Cancelled: big.NewRat(200, 1),
+	}}
+	tax, _ := money.NewTax("20", true, "half_up")
+	signer := signing.NewSigner("v1", []byte("0123456789abcdef0123456789abcdef"))
+	svc := NewSubscriptionService(repo, FixedTax(tax), nil, signer, logger.New(slog.LevelError+4))
+	ctx := ctxutil.WithTenantID(context.Background(), "acme")
+
+	filter := model.SummaryFilter{StartPeriod: "01-2025", EndPeriod: "12-2025", ExcludeServiceNames: []string{"Zoom"}, Signed: true}
+	result, err := svc.CalculateTotalCost(ctx, filter)
+	if err != nil {
+		t.Fatalf("CalculateTotalCost() error = %v", err)
+	}
+	if result.Calculation == nil || result.Signature == nil {
+		t.Fatalf("signed summary has no calculation or signature: %+v", result)
+	}
+	if result.Calculation.Tenant != "acme" || result.Calculation.Filters.ExcludeServiceNames[0] != "Zoom" || result.Signature.KeyID != "v1" {
+		t.Errorf("unexpected calculation %+v, signature %+v", result.Calculation, result.Signature)
+	}
+
+	verification, err := svc.VerifySummary(ctx, result)
+	if err != nil || !verification.Valid {
+		t.Fatalf("VerifySummary() = %+v, %v, want valid", verification, err)
+	}
+
+	result.TotalCost++
+	verification, err = svc.VerifySummary(ctx, result)
+	if err != nil || verification.Valid || verification.Reason != "signature mismatch" {
+		t.Errorf("VerifySummary() of changed total = %+v, %v, want mismatch", verification, err)
+	}
+}
+
+func TestCalculateTotalCostSignedWithoutKey(t *testing.T) {
+	tax, _ := money.NewTax("0", true, "half_up")
+	svc := NewSubscriptionService(&totalsRepoStub{}, FixedTax(tax), nil, nil, logger.New(slog.LevelError+4))
+
+	_, err := svc.CalculateTotalCost(context.Background(), model.SummaryFilter{StartPeriod: "01-2025", EndPeriod: "12-2025", Signed: true})
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid signed") {
+		t.Fatalf("CalculateTotalCost() error = %v, want invalid signed", err)
+	}
+}
+
+// TestUserScopedReads проверяет, что пользователь без прав администратора не читает
+// сервисы и траты других пользователей и журнал изменений всей организации
+func TestUserScopedReads(t *testing.T) {
+	self := uuid.New()
+	other := uuid.New()
+	repo := repository.NewInMemorySubscriptionRepository()
+	svc := newExportTestService(repo)
+	sparklines := NewSparklineService(repo, time.Minute, logger.New(slog.LevelError+4))
+	user := auth.WithCaller(context.Background(), auth.Caller{UserID: self})
+	admin := auth.WithCaller(context.Background(), auth.Caller{Admin: true})
+
+	if _, err := svc.ListUserServices(user, other); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("ListUserServices(other) error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.ListUserServices(user, self); err != nil {
+		t.Errorf("ListUserServices(self) error = %v", err)
+	}
+	if _, err := sparklines.Sparkline(user, other, 12); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Sparkline(other) error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.ListChanges(user, 0, 10); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("ListChanges() by user error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.ListChanges(admin, 0, 10); err != nil {
+		t.Errorf("ListChanges() by admin error = %v", err)
+	}
+}
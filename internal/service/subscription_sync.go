@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// syncKey - ключ сопоставления подписки желаемого набора без ID с существующей
+type syncKey struct {
+	serviceName string
+	startDate   string
+}
+
+func (s *subscriptionService) SyncUserSubscriptions(ctx context.Context, userID uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error) {
+	if _, err := auth.ScopeUserID(ctx, &userID); err != nil {
+		return nil, err
+	}
+	s.logger.Info(ctx, "Syncing user subscriptions",
+		"user_id", userID,
+		"count", len(items),
+		"dry_run", dryRun,
+	)
+
+	var existing []*model.Subscription
+	err := s.repo.Stream(ctx, model.SubscriptionFilter{UserID: &userID}, func(sub *model.Subscription) error {
+		existing = append(existing, sub)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read subscriptions for sync",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	matches, err := matchSyncItems(existing, items)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.SyncResponse{
+		DryRun:    dryRun,
+		Created:   []*model.Subscription{},
+		Updated:   []model.SyncUpdate{},
+		Deleted:   []*model.Subscription{},
+		Unchanged: []uuid.UUID{},
+	}
+	var (
+		creates []*model.Subscription
+		updates []model.SubscriptionUpdate
+		deletes []uuid.UUID
+	)
+	for i, item := range items {
+		before := matches[i]
+		if before == nil {
+			if item.Status != nil && *item.Status != model.StatusActive {
+				return nil, model.Invalid(model.ErrInvalidInput, "subscriptions[%d]: new subscription must be active", i)
+			}
+			sub, err := s.buildSubscription(ctx, syncCreateRequest(item.UpdateSubscriptionRequest))
+			if err != nil {
+				return nil, syncItemError(i, err)
+			}
+			creates = append(creates, sub)
+			continue
+		}
+
+		sub, err := s.prepareUpdate(ctx, before.ID, item.UpdateSubscriptionRequest)
+		if err != nil {
+			return nil, syncItemError(i, err)
+		}
+		if sub == nil {
+			resp.Unchanged = append(resp.Unchanged, before.ID)
+			continue
+		}
+		updates = append(updates, model.SubscriptionUpdate{ID: before.ID, Subscription: sub})
+		resp.Updated = append(resp.Updated, model.SyncUpdate{ID: before.ID, Before: before})
+	}
+
+	matched := make(map[uuid.UUID]bool, len(items))
+	for _, sub := range matches {
+		if sub != nil {
+			matched[sub.ID] = true
+		}
+	}
+	for _, sub := range existing {
+		if !matched[sub.ID] {
+			deletes = append(deletes, sub.ID)
+			resp.Deleted = append(resp.Deleted, sub)
+		}
+	}
+
+	if dryRun {
+		for i, u := range updates {
+			resp.Updated[i].After = syncPreview(resp.Updated[i].Before, u.Subscription)
+		}
+		resp.Created = append(resp.Created, creates...)
+		return resp, nil
+	}
+
+	if err := s.repo.Sync(ctx, creates, updates, deletes); err != nil {
+		var rowErr *repository.BatchRowError
+		if errors.As(err, &rowErr) {
+			if rowErr.Action != "create" {
+				// Подписку удалили или передали параллельным запросом после чтения набора
+				return nil, fmt.Errorf("%w: retry the request", ErrSyncConflict)
+			}
+			return nil, model.Invalid(model.ErrInvalidInput, "failed to create subscription: %s", rowErr.Message)
+		}
+		s.logger.Error(ctx, "Failed to sync user subscriptions",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to sync subscriptions: %w", err)
+	}
+
+	resp.Created = append(resp.Created, creates...)
+	for i, u := range updates {
+		after, err := s.repo.GetByID(ctx, u.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read synced subscription: %w", err)
+		}
+		resp.Updated[i].After = after
+	}
+
+	s.logger.Info(ctx, "User subscriptions synced",
+		"user_id", userID,
+		"created", len(resp.Created),
+		"updated", len(resp.Updated),
+		"deleted", len(resp.Deleted),
+		"unchanged", len(resp.Unchanged),
+	)
+	return resp, nil
+}
+
+// matchSyncItems сопоставляет элементы желаемого набора с существующими подписками
+// пользователя: сначала по ID, затем оставшиеся - по сервису и месяцу начала в порядке
+// создания. nil - элемент описывает новую подписку
+func matchSyncItems(existing []*model.Subscription, items []model.SyncSubscriptionItem) ([]*model.Subscription, error) {
+	byID := make(map[uuid.UUID]*model.Subscription, len(existing))
+	for _, sub := range existing {
+		byID[sub.ID] = sub
+	}
+
+	matches := make([]*model.Subscription, len(items))
+	claimed := make(map[uuid.UUID]bool, len(items))
+	for i, item := range items {
+		if item.ID == nil {
+			continue
+		}
+		sub, ok := byID[*item.ID]
+		if !ok {
+			return nil, model.Invalid(model.ErrInvalidInput, "subscriptions[%d]: subscription %s not found for user", i, *item.ID)
+		}
+		if claimed[sub.ID] {
+			return nil, model.Invalid(model.ErrInvalidInput, "subscriptions[%d]: duplicate subscription %s", i, *item.ID)
+		}
+		claimed[sub.ID] = true
+		matches[i] = sub
+	}
+
+	byKey := make(map[syncKey][]*model.Subscription)
+	for _, sub := range existing {
+		if claimed[sub.ID] {
+			continue
+		}
+		key := syncKey{sub.ServiceName, sub.StartDate.Format("01-2006")}
+		byKey[key] = append(byKey[key], sub)
+	}
+	for i, item := range items {
+		if item.ID != nil {
+			continue
+		}
+		key := syncKey{item.ServiceName, item.StartDate}
+		if candidates := byKey[key]; len(candidates) > 0 {
+			matches[i] = candidates[0]
+			byKey[key] = candidates[1:]
+		}
+	}
+	return matches, nil
+}
+
+// syncCreateRequest переводит элемент желаемого набора в запрос на создание подписки
+func syncCreateRequest(req model.UpdateSubscriptionRequest) model.CreateSubscriptionRequest {
+	return model.CreateSubscriptionRequest{
+		ServiceName:   req.ServiceName,
+		MonthlyCost:   req.MonthlyCost,
+		UserID:        req.UserID,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		PrepaidAmount: req.PrepaidAmount,
+		IsFree:        req.IsFree,
+		Metadata:      req.Metadata,
+		ServiceID:     req.ServiceID,
+		Description:   req.Description,
+		BillingPeriod: req.BillingPeriod,
+		Cost:          req.Cost,
+		Tags:          req.Tags,
+	}
+}
+
+// syncPreview возвращает подписку before после изменения sub без записи в базу
+func syncPreview(before, sub *model.Subscription) *model.Subscription {
+	after := *sub
+	after.ID = before.ID
+	after.IsDraft = before.IsDraft
+	after.CancelReason = before.CancelReason
+	after.CancelledAt = before.CancelledAt
+	after.ChangeSeq = before.ChangeSeq
+	after.CreatedAt = before.CreatedAt
+	after.UpdatedAt = before.UpdatedAt
+	if after.Status == "" {
+		after.Status = before.Status
+	}
+	return &after
+}
+
+// syncItemError относит ошибку проверки к элементу желаемого набора
+func syncItemError(i int, err error) error {
+	if errors.Is(err, model.ErrInvalidInput) {
+		return model.Invalid(err, "subscriptions[%d]: %w", i, err)
+	}
+	return err
+}
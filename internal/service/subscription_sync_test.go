@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func TestSyncUserSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	userID, otherID := uuid.New(), uuid.New()
+
+	for _, dryRun := range []bool{true, false} {
+		repo := repository.NewInMemorySubscriptionRepository()
+		svc := newExportTestService(repo)
+
+		var ids []uuid.UUID
+		for _, req := range []model.CreateSubscriptionRequest{
+			{ServiceName: "Netflix", MonthlyCost: 400, UserID: userID, StartDate: "07-2025"},
+			{ServiceName: "Spotify", MonthlyCost: 200, UserID: userID, StartDate: "07-2025"},
+			{ServiceName: "Kinopoisk", MonthlyCost: 300, UserID: userID, StartDate: "07-2025"},
+			{ServiceName: "Netflix", MonthlyCost: 400, UserID: otherID, StartDate: "07-2025"},
+		} {
+			sub, err := svc.CreateSubscription(ctx, req)
+			if err != nil {
+				t.Fatalf("CreateSubscription() error = %v", err)
+			}
+			ids = append(ids, sub.ID)
+		}
+
+		item := func(id *uuid.UUID, name string, cost int) model.SyncSubscriptionItem {
+			return model.SyncSubscriptionItem{ID: id, UpdateSubscriptionRequest: model.UpdateSubscriptionRequest{
+				ServiceName: name, MonthlyCost: cost, UserID: userID, StartDate: "07-2025",
+			}}
+		}
+		resp, err := svc.SyncUserSubscriptions(ctx, userID, []model.SyncSubscriptionItem{
+			item(nil, "Netflix", 500),
+			item(&ids[1], "Spotify", 200),
+			item(nil, "Okko", 250),
+		}, dryRun)
+		if err != nil {
+			t.Fatalf("SyncUserSubscriptions(dry_run=%v) error = %v", dryRun, err)
+		}
+
+		if len(resp.Created) != 1 || resp.Created[0].ServiceName != "Okko" {
+			t.Errorf("created = %+v, want Okko", resp.Created)
+		}
+		if len(resp.Updated) != 1 || resp.Updated[0].ID != ids[0] ||
+			resp.Updated[0].Before.MonthlyCost != 400 || resp.Updated[0].After.MonthlyCost != 500 {
+			t.Errorf("updated = %+v, want Netflix 400 -> 500", resp.Updated)
+		}
+		if len(resp.Deleted) != 1 || resp.Deleted[0].ID != ids[2] {
+			t.Errorf("deleted = %+v, want Kinopoisk", resp.Deleted)
+		}
+		if len(resp.Unchanged) != 1 || resp.Unchanged[0] != ids[1] {
+			t.Errorf("unchanged = %v, want %s", resp.Unchanged, ids[1])
+		}
+
+		wantCost := 500
+		if dryRun {
+			wantCost = 400
+		}
+		if sub, _ := repo.GetByID(ctx, ids[0]); sub.MonthlyCost != wantCost {
+			t.Errorf("dry_run=%v: monthly cost = %d, want %d", dryRun, sub.MonthlyCost, wantCost)
+		}
+		_, total, err := repo.List(ctx, model.SubscriptionFilter{UserID: &userID}, model.Pagination{Limit: 10})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if total != 3 {
+			t.Errorf("dry_run=%v: user has %d subscriptions, want 3", dryRun, total)
+		}
+		if sub, _ := repo.GetByID(ctx, ids[2]); (sub != nil) != dryRun {
+			t.Errorf("dry_run=%v: deleted subscription = %+v", dryRun, sub)
+		}
+		if sub, _ := repo.GetByID(ctx, ids[3]); sub == nil {
+			t.Error("other user's subscription deleted")
+		}
+	}
+}
+
+func TestSyncUserSubscriptionsRejectsForeignID(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemorySubscriptionRepository()
+	svc := newExportTestService(repo)
+
+	userID, otherID := uuid.New(), uuid.New()
+	foreign, err := svc.CreateSubscription(ctx, model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", MonthlyCost: 400, UserID: otherID, StartDate: "07-2025",
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+
+	_, err = svc.SyncUserSubscriptions(ctx, userID, []model.SyncSubscriptionItem{{
+		ID: &foreign.ID,
+		UpdateSubscriptionRequest: model.UpdateSubscriptionRequest{
+			ServiceName: "Netflix", MonthlyCost: 500, UserID: userID, StartDate: "07-2025",
+		},
+	}}, false)
+	if !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("error = %v, want ErrInvalidInput", err)
+	}
+	if sub, _ := repo.GetByID(ctx, foreign.ID); sub.MonthlyCost != 400 || sub.UserID != otherID {
+		t.Errorf("foreign subscription changed: %+v", sub)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type TagService interface {
+	CreateTag(ctx context.Context, req model.TagRequest) (*model.Tag, error)
+	// RenameTag переименовывает тег у всех подписок, на которых он стоит
+	RenameTag(ctx context.Context, id uuid.UUID, req model.TagRequest) (*model.Tag, error)
+	// DeleteTag удаляет тег и снимает его со всех подписок
+	DeleteTag(ctx context.Context, id uuid.UUID) error
+	ListTags(ctx context.Context) ([]*model.Tag, error)
+}
+
+type tagService struct {
+	repo   repository.TagRepository
+	logger *logger.Logger
+}
+
+func NewTagService(repo repository.TagRepository, logger *logger.Logger) TagService {
+	return &tagService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *tagService) CreateTag(ctx context.Context, req model.TagRequest) (*model.Tag, error) {
+	name, err := model.NormalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Creating tag", "name", name)
+
+	tag := &model.Tag{Name: name}
+	if err := s.repo.Create(ctx, tag); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrTagExists
+		}
+		s.logger.Error(ctx, "Failed to create tag in repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) RenameTag(ctx context.Context, id uuid.UUID, req model.TagRequest) (*model.Tag, error) {
+	name, err := model.NormalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Renaming tag", "tag_id", id, "name", name)
+
+	tag := &model.Tag{Name: name}
+	if err := s.repo.Rename(ctx, id, tag); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTagNotFound
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrTagExists
+		}
+		s.logger.Error(ctx, "Failed to rename tag in repository",
+			"tag_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting tag", "tag_id", id)
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTagNotFound
+		}
+		s.logger.Error(ctx, "Failed to delete tag from repository",
+			"tag_id", id,
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tagService) ListTags(ctx context.Context) ([]*model.Tag, error) {
+	tags, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list tags from repository",
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// TaxResolver выбирает налоговую политику организации запроса для итогов и счетов
+type TaxResolver interface {
+	Tax(ctx context.Context) (money.Tax, error)
+}
+
+// FixedTax - одна политика для всех организаций; используется без PostgreSQL, где
+// организации не хранятся
+func FixedTax(tax money.Tax) TaxResolver {
+	return fixedTax{tax: tax}
+}
+
+type fixedTax struct {
+	tax money.Tax
+}
+
+func (f fixedTax) Tax(context.Context) (money.Tax, error) {
+	return f.tax, nil
+}
+
+type tenantTax struct {
+	repo     repository.TenantRepository
+	fallback money.Tax
+	logger   *logger.Logger
+}
+
+// NewTenantTaxResolver берет ставку и округление из строки организации (ctxutil.TenantID),
+// а незаданные в ней значения и признак PricesIncludeTax - из fallback
+func NewTenantTaxResolver(repo repository.TenantRepository, fallback money.Tax, logger *logger.Logger) TaxResolver {
+	return &tenantTax{
+		repo:     repo,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+func (t *tenantTax) Tax(ctx context.Context) (money.Tax, error) {
+	tenantID := ctxutil.TenantID(ctx)
+	settings, err := t.repo.Settings(ctx, tenantID)
+	if err != nil {
+		return money.Tax{}, fmt.Errorf("failed to get tax settings: %w", err)
+	}
+	tax, err := applyTenantSettings(t.fallback, settings)
+	if err != nil {
+		t.logger.Error(ctx, "Invalid tenant tax settings",
+			"tenant", tenantID,
+			"error", err,
+		)
+		return money.Tax{}, err
+	}
+	return tax, nil
+}
+
+// applyTenantSettings заменяет в tax значения, заданные организацией. Настройки
+// проверяются при сохранении, поэтому ошибка означает порчу данных в базе
+func applyTenantSettings(tax money.Tax, settings *model.TenantSettings) (money.Tax, error) {
+	if settings == nil {
+		return tax, nil
+	}
+	if settings.TaxRatePercent != nil {
+		rate, err := money.ParsePercent(*settings.TaxRatePercent)
+		if err != nil {
+			return money.Tax{}, fmt.Errorf("invalid tenant tax rate: %w", err)
+		}
+		tax.Rate = rate
+	}
+	if settings.Rounding != nil {
+		mode, err := money.ParseRoundingMode(*settings.Rounding)
+		if err != nil {
+			return money.Tax{}, fmt.Errorf("invalid tenant rounding: %w", err)
+		}
+		tax.Rounding = mode
+	}
+	return tax, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// consolidationMinUsers - со скольких пользователей с отдельными подписками на сервис
+// он предлагается для общей подписки
+const consolidationMinUsers = 3
+
+type TeamService interface {
+	// Overview сравнивает подписки пользователей организации запроса за текущий месяц.
+	// Доступен только администраторам
+	Overview(ctx context.Context, groupBy string) (*model.TenantOverview, error)
+}
+
+type teamService struct {
+	repo   repository.SubscriptionRepository
+	logger *logger.Logger
+}
+
+func NewTeamService(repo repository.SubscriptionRepository, logger *logger.Logger) TeamService {
+	return &teamService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *teamService) Overview(ctx context.Context, groupBy string) (*model.TenantOverview, error) {
+	if err := auth.RequireAdmin(ctx, "tenant overview"); err != nil {
+		return nil, err
+	}
+	if groupBy != model.OverviewGroupByUser {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid group_by %q: only %q is supported", groupBy, model.OverviewGroupByUser)
+	}
+
+	month := model.CurrentMonth()
+	s.logger.Debug(ctx, "Building tenant overview", "month", month)
+
+	users, err := s.repo.ListUserSpend(ctx, month)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read user spend for tenant overview", "error", err)
+		return nil, fmt.Errorf("failed to build tenant overview: %w", err)
+	}
+	services, err := s.repo.ListServiceSpend(ctx, month)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to read service spend for tenant overview", "error", err)
+		return nil, fmt.Errorf("failed to build tenant overview: %w", err)
+	}
+
+	overview := &model.TenantOverview{
+		Tenant:         ctxutil.TenantID(ctx),
+		GroupBy:        groupBy,
+		Month:          month,
+		Users:          users,
+		SharedServices: []model.SharedService{},
+		Consolidation:  []model.ConsolidationOpportunity{},
+	}
+	for _, u := range users {
+		overview.MonthlyCost += u.MonthlyCost
+	}
+	// services упорядочены по числу пользователей, поэтому списки сохраняют этот порядок
+	for _, service := range services {
+		if service.Users < 2 {
+			continue
+		}
+		overview.SharedServices = append(overview.SharedServices, service)
+		if service.Users >= consolidationMinUsers {
+			overview.Consolidation = append(overview.Consolidation, model.ConsolidationOpportunity{
+				ServiceName: service.ServiceName,
+				Users:       service.Users,
+				MonthlyCost: service.MonthlyCost,
+				AverageCost: service.MonthlyCost / service.Subscriptions,
+			})
+		}
+	}
+
+	s.logger.Debug(ctx, "Tenant overview built",
+		"users", len(overview.Users),
+		"shared_services", len(overview.SharedServices),
+		"consolidation", len(overview.Consolidation),
+	)
+	return overview, nil
+}
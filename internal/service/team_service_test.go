@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type teamRepoStub struct {
+	repository.SubscriptionRepository
+	users    []model.UserSpend
+	services []model.SharedService
+}
+
+func (r *teamRepoStub) ListUserSpend(ctx context.Context, month time.Time) ([]model.UserSpend, error) {
+	return r.users, nil
+}
+
+func (r *teamRepoStub) ListServiceSpend(ctx context.Context, month time.Time) ([]model.SharedService, error) {
+	return r.services, nil
+}
+
+func TestTenantOverview(t *testing.T) {
+	repo := &teamRepoStub{
+		users: []model.UserSpend{
+			{UserID: uuid.New(), Subscriptions: 3, Services: 2, MonthlyCost: 1500},
+			{UserID: uuid.New(), Subscriptions: 1, Services: 1, MonthlyCost: 400},
+		},
+		services: []model.SharedService{
+			{ServiceName: "Yandex Plus", Users: 3, Subscriptions: 4, MonthlyCost: 1200},
+			{ServiceName: "Netflix", Users: 2, Subscriptions: 2, MonthlyCost: 1000},
+			{ServiceName: "Spotify", Users: 1, Subscriptions: 1, MonthlyCost: 300},
+		},
+	}
+	svc := NewTeamService(repo, logger.New(slog.LevelError+4))
+	ctx := ctxutil.WithTenantID(context.Background(), "acme")
+
+	overview, err := svc.Overview(auth.WithCaller(ctx, auth.Caller{Admin: true}), model.OverviewGroupByUser)
+	if err != nil {
+		t.Fatalf("Overview() error = %v", err)
+	}
+	if overview.Tenant != "acme" || overview.MonthlyCost != 1900 || len(overview.Users) != 2 {
+		t.Errorf("unexpected overview: %+v", overview)
+	}
+	if len(overview.SharedServices) != 2 || overview.SharedServices[1].ServiceName != "Netflix" {
+		t.Errorf("shared services = %+v, want Yandex Plus and Netflix", overview.SharedServices)
+	}
+	want := model.ConsolidationOpportunity{ServiceName: "Yandex Plus", Users: 3, MonthlyCost: 1200, AverageCost: 300}
+	if len(overview.Consolidation) != 1 || overview.Consolidation[0] != want {
+		t.Errorf("consolidation = %+v, want %+v", overview.Consolidation, want)
+	}
+
+	if _, err := svc.Overview(auth.WithCaller(ctx, auth.Caller{UserID: uuid.New()}), model.OverviewGroupByUser); err == nil || !strings.HasPrefix(err.Error(), "forbidden") {
+		t.Errorf("Overview() for regular user error = %v, want forbidden", err)
+	}
+	if _, err := svc.Overview(ctx, "service"); err == nil || !strings.HasPrefix(err.Error(), "invalid group_by") {
+		t.Errorf("Overview() with unsupported group_by error = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+)
+
+type TeardownService interface {
+	// Teardown удаляет тестовые данные организации tenantID или одного ее пользователя.
+	// Запрос должен подтверждать организацию в Confirm
+	Teardown(ctx context.Context, tenantID string, req model.TeardownRequest) (*model.TeardownResult, error)
+}
+
+type teardownService struct {
+	repo    repository.TeardownRepository
+	enabled bool
+	logger  *logger.Logger
+}
+
+// NewTeardownService создает сервис удаления тестовых данных; при enabled=false
+// (production без TEARDOWN_ALLOW_PRODUCTION) удаление запрещено
+func NewTeardownService(repo repository.TeardownRepository, enabled bool, logger *logger.Logger) TeardownService {
+	return &teardownService{
+		repo:    repo,
+		enabled: enabled,
+		logger:  logger,
+	}
+}
+
+func (s *teardownService) Teardown(ctx context.Context, tenantID string, req model.TeardownRequest) (*model.TeardownResult, error) {
+	if !s.enabled {
+		s.logger.Warn(ctx, "Tenant teardown rejected in production",
+			"tenant", tenantID,
+		)
+		return nil, fmt.Errorf("%w: teardown is disabled in production, set TEARDOWN_ALLOW_PRODUCTION=true to force", auth.ErrForbidden)
+	}
+	if err := tenant.Validate(tenantID); err != nil {
+		return nil, err
+	}
+	if req.Confirm != tenantID {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid confirmation: confirm must equal tenant id %q", tenantID)
+	}
+
+	s.logger.Warn(ctx, "Tearing down tenant data",
+		"tenant", tenantID,
+		"user_id", req.UserID,
+		"dry_run", req.DryRun,
+	)
+
+	deleted, err := s.repo.Teardown(ctx, tenantID, req.UserID, req.DryRun)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to tear down tenant data",
+			"tenant", tenantID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to tear down tenant data: %w", err)
+	}
+
+	s.logger.Info(ctx, "Tenant data torn down",
+		"tenant", tenantID,
+		"user_id", req.UserID,
+		"dry_run", req.DryRun,
+		"deleted", deleted,
+	)
+
+	return &model.TeardownResult{
+		Tenant:  tenantID,
+		UserID:  req.UserID,
+		DryRun:  req.DryRun,
+		Deleted: deleted,
+	}, nil
+}
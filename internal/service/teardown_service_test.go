@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type teardownRepoStub struct {
+	calls  int
+	tenant string
+	userID *uuid.UUID
+	dryRun bool
+}
+
+func (r *teardownRepoStub) Teardown(ctx context.Context, tenantID string, userID *uuid.UUID, dryRun bool) (map[string]int64, error) {
+	r.calls++
+	r.tenant, r.userID, r.dryRun = tenantID, userID, dryRun
+	return map[string]int64{"subscriptions": 3}, nil
+}
+
+func TestTeardown(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		enabled bool
+		tenant  string
+		req     model.TeardownRequest
+		wantErr string
+	}{
+		{name: "tenant", enabled: true, tenant: "demo", req: model.TeardownRequest{Confirm: "demo"}},
+		{name: "user dry run", enabled: true, tenant: "demo", req: model.TeardownRequest{Confirm: "demo", UserID: &userID, DryRun: true}},
+		{name: "production", enabled: false, tenant: "demo", req: model.TeardownRequest{Confirm: "demo"}, wantErr: "forbidden"},
+		{name: "wrong confirmation", enabled: true, tenant: "demo", req: model.TeardownRequest{Confirm: "default"}, wantErr: "invalid confirmation"},
+		{name: "invalid tenant", enabled: true, tenant: "Demo!", req: model.TeardownRequest{Confirm: "Demo!"}, wantErr: "invalid tenant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &teardownRepoStub{}
+			svc := NewTeardownService(repo, tt.enabled, logger.New(slog.LevelError+4))
+
+			result, err := svc.Teardown(context.Background(), tt.tenant, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("Teardown() error = %v, want %q", err, tt.wantErr)
+				}
+				if repo.calls != 0 {
+					t.Errorf("repository called %d times after rejected teardown", repo.calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Teardown() error = %v", err)
+			}
+
+			if repo.tenant != tt.tenant || repo.userID != tt.req.UserID || repo.dryRun != tt.req.DryRun {
+				t.Errorf("repository got tenant %q, user %v, dry run %v", repo.tenant, repo.userID, repo.dryRun)
+			}
+			if result.Tenant != tt.tenant || result.DryRun != tt.req.DryRun || result.Deleted["subscriptions"] != 3 {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
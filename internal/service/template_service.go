@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// Встроенные шаблоны используются, пока в базе нет ни одной версии шаблона
+//
+//go:embed templates/*.tmpl
+var defaultTemplatesFS embed.FS
+
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+
+// templateSampleData - примерные данные для предпросмотра встроенных шаблонов
+var templateSampleData = map[string]map[string]interface{}{
+	"renewal_reminder": {
+		"ServiceName": "Yandex Plus",
+		"RenewalDate": "01-08-2025",
+		"MonthlyCost": 400,
+	},
+	"cancellation": {
+		"ServiceName": "Yandex Plus",
+		"EndDate":     "12-2025",
+	},
+	"spend_anomaly": {
+		"Month":            "07-2025",
+		"Spend":            2400,
+		"TrailingAverage":  1200,
+		"DeviationPercent": 100,
+	},
+}
+
+type TemplateService interface {
+	ListTemplates(ctx context.Context) ([]*model.EmailTemplate, error)
+	GetTemplate(ctx context.Context, name string, version int) (*model.EmailTemplate, error)
+	ListVersions(ctx context.Context, name string) ([]*model.EmailTemplate, error)
+	SaveTemplate(ctx context.Context, name string, req model.SaveTemplateRequest) (*model.EmailTemplate, error)
+	Preview(ctx context.Context, name string, req model.PreviewTemplateRequest) (*model.RenderedTemplate, error)
+	// Render подставляет data в текущую версию шаблона
+	Render(ctx context.Context, name string, data interface{}) (*model.RenderedTemplate, error)
+}
+
+type templateService struct {
+	repo     repository.TemplateRepository
+	defaults map[string]*model.EmailTemplate
+	logger   *logger.Logger
+}
+
+func NewTemplateService(repo repository.TemplateRepository, logger *logger.Logger) (TemplateService, error) {
+	defaults, err := loadDefaultTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateService{
+		repo:     repo,
+		defaults: defaults,
+		logger:   logger,
+	}, nil
+}
+
+// loadDefaultTemplates собирает встроенные шаблоны из файлов <name>.subject.tmpl и <name>.body.tmpl
+func loadDefaultTemplates() (map[string]*model.EmailTemplate, error) {
+	entries, err := defaultTemplatesFS.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default templates: %w", err)
+	}
+
+	defaults := make(map[string]*model.EmailTemplate)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		content, err := defaultTemplatesFS.ReadFile("templates/" + fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default template %s: %w", fileName, err)
+		}
+
+		parts := strings.Split(strings.TrimSuffix(fileName, ".tmpl"), ".")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected default template file name: %s", fileName)
+		}
+
+		tmpl, ok := defaults[parts[0]]
+		if !ok {
+			tmpl = &model.EmailTemplate{Name: parts[0], Source: model.TemplateSourceDefault}
+			defaults[parts[0]] = tmpl
+		}
+
+		switch parts[1] {
+		case "subject":
+			tmpl.Subject = strings.TrimSpace(string(content))
+		case "body":
+			tmpl.Body = string(content)
+		default:
+			return nil, fmt.Errorf("unexpected default template file name: %s", fileName)
+		}
+	}
+
+	for name, tmpl := range defaults {
+		if err := validateTemplate(tmpl.Subject, tmpl.Body); err != nil {
+			return nil, fmt.Errorf("invalid default template %s: %w", name, err)
+		}
+	}
+
+	return defaults, nil
+}
+
+func (s *templateService) ListTemplates(ctx context.Context) ([]*model.EmailTemplate, error) {
+	stored, err := s.repo.ListLatest(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list templates from repository", "error", err)
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	byName := make(map[string]*model.EmailTemplate, len(s.defaults)+len(stored))
+	for name, tmpl := range s.defaults {
+		byName[name] = tmpl
+	}
+	for _, tmpl := range stored {
+		byName[tmpl.Name] = tmpl
+	}
+
+	templates := make([]*model.EmailTemplate, 0, len(byName))
+	for _, tmpl := range byName {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// GetTemplate возвращает указанную версию шаблона. Нулевая версия означает текущую:
+// последнюю из базы или встроенную, если в базе версий нет
+func (s *templateService) GetTemplate(ctx context.Context, name string, version int) (*model.EmailTemplate, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template name")
+	}
+
+	var tmpl *model.EmailTemplate
+	var err error
+	if version > 0 {
+		tmpl, err = s.repo.GetVersion(ctx, name, version)
+	} else {
+		tmpl, err = s.repo.GetLatest(ctx, name)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get template from repository",
+			"name", name,
+			"version", version,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	if tmpl != nil {
+		return tmpl, nil
+	}
+
+	if version == 0 {
+		if fallback, ok := s.defaults[name]; ok {
+			s.logger.Debug(ctx, "Using default template", "name", name)
+			return fallback, nil
+		}
+	}
+
+	return nil, ErrTemplateNotFound
+}
+
+func (s *templateService) ListVersions(ctx context.Context, name string) ([]*model.EmailTemplate, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template name")
+	}
+
+	versions, err := s.repo.ListVersions(ctx, name)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list template versions from repository",
+			"name", name,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to list template versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		if fallback, ok := s.defaults[name]; ok {
+			return []*model.EmailTemplate{fallback}, nil
+		}
+		return nil, ErrTemplateNotFound
+	}
+
+	return versions, nil
+}
+
+// SaveTemplate сохраняет новую версию шаблона. Предыдущие версии не изменяются
+func (s *templateService) SaveTemplate(ctx context.Context, name string, req model.SaveTemplateRequest) (*model.EmailTemplate, error) {
+	s.logger.Info(ctx, "Saving email template", "name", name)
+
+	if err := auth.RequireAdmin(ctx, "saving email templates"); err != nil {
+		return nil, err
+	}
+	if !templateNamePattern.MatchString(name) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template name")
+	}
+
+	if err := validateTemplate(req.Subject, req.Body); err != nil {
+		s.logger.Warn(ctx, "Invalid email template",
+			"name", name,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	tmpl := &model.EmailTemplate{
+		Name:    name,
+		Subject: req.Subject,
+		Body:    req.Body,
+	}
+
+	if err := s.repo.Create(ctx, tmpl); err != nil {
+		s.logger.Error(ctx, "Failed to save template in repository",
+			"name", name,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Preview рендерит шаблон с примерными или переданными данными без сохранения
+func (s *templateService) Preview(ctx context.Context, name string, req model.PreviewTemplateRequest) (*model.RenderedTemplate, error) {
+	if err := auth.RequireAdmin(ctx, "previewing email templates"); err != nil {
+		return nil, err
+	}
+
+	subject, body := req.Subject, req.Body
+	if subject == nil || body == nil {
+		current, err := s.GetTemplate(ctx, name, 0)
+		if err != nil {
+			return nil, err
+		}
+		if subject == nil {
+			subject = &current.Subject
+		}
+		if body == nil {
+			body = &current.Body
+		}
+	} else if !templateNamePattern.MatchString(name) {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template name")
+	}
+
+	data := req.Data
+	if data == nil {
+		data = templateSampleData[name]
+	}
+
+	if err := validateTemplate(*subject, *body); err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(*subject, *body, data)
+}
+
+func (s *templateService) Render(ctx context.Context, name string, data interface{}) (*model.RenderedTemplate, error) {
+	tmpl, err := s.GetTemplate(ctx, name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderTemplate(tmpl.Subject, tmpl.Body, data)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to render email template",
+			"name", name,
+			"version", tmpl.Version,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return rendered, nil
+}
+
+// validateTemplate проверяет синтаксис шаблонов. Ошибки начинаются с "invalid template",
+// чтобы обработчик мог вернуть 400
+func validateTemplate(subject, body string) error {
+	if _, err := texttemplate.New("subject").Parse(subject); err != nil {
+		return model.Invalid(model.ErrInvalidInput, "invalid template subject: %w", err)
+	}
+	if _, err := htmltemplate.New("body").Parse(body); err != nil {
+		return model.Invalid(model.ErrInvalidInput, "invalid template body: %w", err)
+	}
+	return nil
+}
+
+// renderTemplate рендерит тему как текст, а тело как HTML с экранированием данных
+func renderTemplate(subject, body string, data interface{}) (*model.RenderedTemplate, error) {
+	subjectTmpl, err := texttemplate.New("subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template subject: %w", err)
+	}
+	bodyTmpl, err := htmltemplate.New("body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template body: %w", err)
+	}
+
+	var subjectBuf, bodyBuf bytes.Buffer
+	if err := subjectTmpl.Execute(&subjectBuf, data); err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template subject: %w", err)
+	}
+	if err := bodyTmpl.Execute(&bodyBuf, data); err != nil {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid template body: %w", err)
+	}
+
+	return &model.RenderedTemplate{
+		Subject: strings.TrimSpace(subjectBuf.String()),
+		Body:    bodyBuf.String(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestTemplateWritesRequireAdmin(t *testing.T) {
+	svc, err := NewTemplateService(emptyTemplateRepo{}, logger.New(slog.LevelError+4))
+	if err != nil {
+		t.Fatalf("NewTemplateService() error = %v", err)
+	}
+
+	req := model.SaveTemplateRequest{Subject: "Скоро продление {{.ServiceName}}", Body: "<p>{{.ServiceName}}</p>"}
+	user := auth.WithCaller(context.Background(), auth.Caller{UserID: uuid.New()})
+	if _, err := svc.SaveTemplate(user, "renewal_reminder", req); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("SaveTemplate() by user error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.Preview(user, "renewal_reminder", model.PreviewTemplateRequest{}); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Preview() by user error = %v, want ErrForbidden", err)
+	}
+	// Чтение шаблонов доступно всем
+	if _, err := svc.GetTemplate(user, "renewal_reminder", 0); err != nil {
+		t.Errorf("GetTemplate() by user error = %v", err)
+	}
+
+	admin := auth.WithCaller(context.Background(), auth.Caller{Admin: true})
+	if _, err := svc.SaveTemplate(admin, "renewal_reminder", req); err != nil {
+		t.Errorf("SaveTemplate() by admin error = %v", err)
+	}
+	if _, err := svc.Preview(admin, "renewal_reminder", model.PreviewTemplateRequest{}); err != nil {
+		t.Errorf("Preview() by admin error = %v", err)
+	}
+}
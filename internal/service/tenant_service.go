@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+)
+
+type TenantService interface {
+	// Provision заводит организацию tenantID или приводит ее к состоянию req: название,
+	// налоговые настройки, сервисы каталога, теги и личные токены API. Повтор запроса
+	// ничего не меняет
+	Provision(ctx context.Context, tenantID string, req model.ProvisionTenantRequest) (*model.ProvisionTenantResult, error)
+}
+
+type tenantService struct {
+	repo   repository.TenantRepository
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+func NewTenantService(repo repository.TenantRepository, logger *logger.Logger) TenantService {
+	return &tenantService{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *tenantService) Provision(ctx context.Context, tenantID string, req model.ProvisionTenantRequest) (*model.ProvisionTenantResult, error) {
+	if err := auth.RequireAdmin(ctx, "provisioning tenants"); err != nil {
+		return nil, err
+	}
+	if err := tenant.Validate(tenantID); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, model.Invalid(model.ErrInvalidInput, "invalid tenant: name is required")
+	}
+
+	settings, err := buildTenantSettings(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*model.CatalogService, 0, len(req.Services))
+	seenServices := make(map[string]bool, len(req.Services))
+	for _, serviceReq := range req.Services {
+		service, err := buildCatalogService(serviceReq)
+		if err != nil {
+			return nil, err
+		}
+		// Название уникально без учета регистра: два описания одного сервиса противоречат друг другу
+		key := strings.ToLower(service.Name)
+		if seenServices[key] {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid services: %q is listed twice", service.Name)
+		}
+		seenServices[key] = true
+		services = append(services, service)
+	}
+
+	tags := make([]string, 0, len(req.Tags))
+	seenTags := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		name, err := model.NormalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seenTags[name] {
+			seenTags[name] = true
+			tags = append(tags, name)
+		}
+	}
+
+	// Секреты генерируются для всех токенов запроса; репозиторий сохраняет только те,
+	// которых у пользователя еще нет, остальные секреты отбрасываются
+	tokens := make([]repository.ProvisionToken, 0, len(req.APITokens))
+	for _, tokenReq := range req.APITokens {
+		issued, err := newAPIToken(tokenReq.UserID, tokenReq.CreateAPITokenRequest, s.now())
+		if err != nil {
+			return nil, err
+		}
+		if issued.Name == "" {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid API token: name is required")
+		}
+		tokens = append(tokens, repository.ProvisionToken{Token: *issued, Hash: hashAPIToken(issued.Token)})
+	}
+
+	result, err := s.repo.Provision(ctx, tenantID, name, settings, services, tags, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision tenant: %w", err)
+	}
+
+	s.logger.Info(ctx, "Tenant provisioned",
+		"tenant", tenantID,
+		"created", result.Created,
+		"updated_settings", result.UpdatedSettings,
+		"created_services", len(result.CreatedServices),
+		"updated_services", len(result.UpdatedServices),
+		"created_tags", len(result.CreatedTags),
+		"issued_tokens", len(result.IssuedTokens),
+		"actor", auth.Actor(ctx),
+	)
+	return result, nil
+}
+
+// buildTenantSettings проверяет налоговые настройки и приводит ставку к виду, в котором
+// она хранится (NUMERIC с четырьмя знаками после запятой)
+func buildTenantSettings(req *model.TenantSettings) (*model.TenantSettings, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	settings := &model.TenantSettings{}
+	if req.TaxRatePercent != nil {
+		rate, err := money.ParsePercent(strings.TrimSpace(*req.TaxRatePercent))
+		if err != nil {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid settings: %w", err)
+		}
+		percent := new(big.Rat).Mul(rate, big.NewRat(100, 1))
+		if percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid settings: tax_rate_percent cannot exceed 100")
+		}
+		if !new(big.Rat).Mul(percent, big.NewRat(10000, 1)).IsInt() {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid settings: tax_rate_percent allows at most 4 decimal places")
+		}
+		value := percent.FloatString(4)
+		settings.TaxRatePercent = &value
+	}
+	if req.Rounding != nil {
+		mode, err := money.ParseRoundingMode(*req.Rounding)
+		if err != nil {
+			return nil, model.Invalid(model.ErrInvalidInput, "invalid settings: %w", err)
+		}
+		value := string(mode)
+		settings.Rounding = &value
+	}
+	return settings, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/repository"
+	"github.com/Zipklas/subscription-service/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// tenantRepoStub запоминает аргументы Provision и сообщает, что все создано;
+// настройки сохраняются в settings, откуда их возвращает Settings
+type tenantRepoStub struct {
+	services []*model.CatalogService
+	tags     []string
+	tokens   []repository.ProvisionToken
+	settings map[string]*model.TenantSettings
+}
+
+func (r *tenantRepoStub) Provision(ctx context.Context, tenantID, name string, settings *model.TenantSettings, services []*model.CatalogService, tags []string, tokens []repository.ProvisionToken) (*model.ProvisionTenantResult, error) {
+	r.services, r.tags, r.tokens = services, tags, tokens
+	if settings != nil {
+		if r.settings == nil {
+			r.settings = make(map[string]*model.TenantSettings)
+		}
+		r.settings[tenantID] = settings
+	}
+	result := &model.ProvisionTenantResult{Tenant: tenantID, Name: name, Created: true, UpdatedSettings: settings != nil, CreatedTags: tags}
+	for _, token := range tokens {
+		result.IssuedTokens = append(result.IssuedTokens, token.Token)
+	}
+	return result, nil
+}
+
+func (r *tenantRepoStub) Settings(ctx context.Context, tenantID string) (*model.TenantSettings, error) {
+	return r.settings[tenantID], nil
+}
+
+func TestTenantProvision(t *testing.T) {
+	repo := &tenantRepoStub{}
+	svc := NewTenantService(repo, logger.New(slog.LevelError+4))
+	userID := uuid.New()
+
+	category := " music "
+	result, err := svc.Provision(context.Background(), "acme", model.ProvisionTenantRequest{
+		Name:     " Acme Inc ",
+		Services: []model.CatalogServiceRequest{{Name: " Yandex Plus ", Category: &category}},
+		Tags:     []string{"Work", " personal", "work"},
+		APITokens: []model.ProvisionAPIToken{{
+			UserID:                userID,
+			CreateAPITokenRequest: model.CreateAPITokenRequest{Name: "terraform", Scopes: []string{model.ScopeReadSummary}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if result.Name != "Acme Inc" {
+		t.Errorf("name = %q, want trimmed", result.Name)
+	}
+	if len(repo.services) != 1 || repo.services[0].Name != "Yandex Plus" || *repo.services[0].Category != "music" {
+		t.Errorf("services = %+v, want trimmed Yandex Plus", repo.services)
+	}
+	if want := []string{"work", "personal"}; !slices.Equal(repo.tags, want) {
+		t.Errorf("tags = %v, want %v", repo.tags, want)
+	}
+	if len(repo.tokens) != 1 {
+		t.Fatalf("tokens = %d, want 1", len(repo.tokens))
+	}
+	token := repo.tokens[0]
+	if token.Token.UserID != userID || !strings.HasPrefix(token.Token.Token, model.APITokenPrefix) || token.Hash != hashAPIToken(token.Token.Token) {
+		t.Errorf("token = %+v, want secret of user %s with its hash", token, userID)
+	}
+}
+
+// TestTenantProvisionSettings проверяет, что налоговые настройки из запроса сохраняются
+// в виде хранения и действуют в итогах и счетах организации
+func TestTenantProvisionSettings(t *testing.T) {
+	repo := &tenantRepoStub{}
+	svc := NewTenantService(repo, logger.New(slog.LevelError+4))
+
+	result, err := svc.Provision(context.Background(), "acme", model.ProvisionTenantRequest{
+		Name:     "Acme Inc",
+		Settings: &model.TenantSettings{TaxRatePercent: strPtr(" 7.5 "), Rounding: strPtr("half_even")},
+	})
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if !result.UpdatedSettings {
+		t.Error("UpdatedSettings = false, want true")
+	}
+	settings := repo.settings["acme"]
+	if settings == nil || *settings.TaxRatePercent != "7.5000" || *settings.Rounding != "half_even" {
+		t.Fatalf("saved settings = %+v, want 7.5000 and half_even", settings)
+	}
+
+	fallback, _ := money.NewTax("20", false, "half_up")
+	tax, err := NewTenantTaxResolver(repo, fallback, logger.New(slog.LevelError+4)).Tax(ctxutil.WithTenantID(context.Background(), "acme"))
+	if err != nil {
+		t.Fatalf("Tax() error = %v", err)
+	}
+	if tax.RatePercent() != "7.50" || tax.Rounding != money.RoundHalfEven || tax.PricesIncludeTax {
+		t.Errorf("tax = %+v, want 7.50%% with half_even and PricesIncludeTax from config", tax)
+	}
+}
+
+func TestTenantProvisionValidation(t *testing.T) {
+	svc := NewTenantService(&tenantRepoStub{}, logger.New(slog.LevelError+4))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		tenantID string
+		req      model.ProvisionTenantRequest
+		wantErr  error
+	}{
+		{"invalid tenant", context.Background(), "Acme Corp", model.ProvisionTenantRequest{Name: "Acme"}, tenant.ErrInvalidTenant},
+		{"blank name", context.Background(), "acme", model.ProvisionTenantRequest{Name: " "}, model.ErrInvalidInput},
+		{"duplicate service", context.Background(), "acme", model.ProvisionTenantRequest{
+			Name:     "Acme",
+			Services: []model.CatalogServiceRequest{{Name: "Netflix"}, {Name: "netflix"}},
+		}, model.ErrInvalidInput},
+		{"blank tag", context.Background(), "acme", model.ProvisionTenantRequest{Name: "Acme", Tags: []string{" "}}, model.ErrInvalidInput},
+		{"unknown rounding", context.Background(), "acme", model.ProvisionTenantRequest{Name: "Acme", Settings: &model.TenantSettings{Rounding: strPtr("down")}}, model.ErrInvalidInput},
+		{"tax rate above 100", context.Background(), "acme", model.ProvisionTenantRequest{Name: "Acme", Settings: &model.TenantSettings{TaxRatePercent: strPtr("120")}}, model.ErrInvalidInput},
+		{"tax rate precision", context.Background(), "acme", model.ProvisionTenantRequest{Name: "Acme", Settings: &model.TenantSettings{TaxRatePercent: strPtr("5.55555")}}, model.ErrInvalidInput},
+		{"not an admin", auth.WithCaller(context.Background(), auth.Caller{}), "acme", model.ProvisionTenantRequest{Name: "Acme"}, auth.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Provision(tt.ctx, tt.tenantID, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Provision() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
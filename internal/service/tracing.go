@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/timing"
+	"github.com/Zipklas/subscription-service/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// tracedSubscriptionService записывает спан каждого вызова сервиса подписок между
+// спаном HTTP-запроса и спанами запросов к базе и учитывает время вызова в Server-Timing
+type tracedSubscriptionService struct {
+	next SubscriptionService
+}
+
+// NewTracedSubscriptionService оборачивает сервис подписок спанами трассировки
+func NewTracedSubscriptionService(next SubscriptionService) SubscriptionService {
+	return &tracedSubscriptionService{next: next}
+}
+
+// serviceCall - спан вызова сервиса и отсчет его времени для Server-Timing
+type serviceCall struct {
+	*tracing.Span
+	done func()
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, serviceCall) {
+	done := timing.FromContext(ctx).Service()
+	ctx, span := tracing.Start(ctx, "SubscriptionService."+method, tracing.KindInternal)
+	return ctx, serviceCall{Span: span, done: done}
+}
+
+func endSpan(call serviceCall, err error) {
+	call.done()
+	call.RecordError(err)
+	call.End()
+}
+
+func (s *tracedSubscriptionService) CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "CreateSubscription")
+	sub, err := s.next.CreateSubscription(ctx, req)
+	endSpan(span, err)
+	return sub, err
+}
+
+func (s *tracedSubscriptionService) ImportSubscriptions(ctx context.Context, rows []model.ImportRow) (*model.ImportResult, error) {
+	ctx, span := startSpan(ctx, "ImportSubscriptions")
+	span.SetAttributes(tracing.Attribute{Key: "import.rows", Value: len(rows)})
+	result, err := s.next.ImportSubscriptions(ctx, rows)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) ValidateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.ValidationResult, error) {
+	ctx, span := startSpan(ctx, "ValidateSubscription")
+	result, err := s.next.ValidateSubscription(ctx, req)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "GetSubscription")
+	sub, err := s.next.GetSubscription(ctx, id)
+	endSpan(span, err)
+	return sub, err
+}
+
+func (s *tracedSubscriptionService) ListSubscriptionPrices(ctx context.Context, id uuid.UUID) ([]model.SubscriptionPrice, error) {
+	ctx, span := startSpan(ctx, "ListSubscriptionPrices")
+	prices, err := s.next.ListSubscriptionPrices(ctx, id)
+	endSpan(span, err)
+	return prices, err
+}
+
+func (s *tracedSubscriptionService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) error {
+	ctx, span := startSpan(ctx, "UpdateSubscription")
+	err := s.next.UpdateSubscription(ctx, id, req)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracedSubscriptionService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "DeleteSubscription")
+	err := s.next.DeleteSubscription(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracedSubscriptionService) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Pagination) (*model.SubscriptionPage, error) {
+	ctx, span := startSpan(ctx, "ListSubscriptions")
+	result, err := s.next.ListSubscriptions(ctx, filter, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) ListSubscriptionsAfter(ctx context.Context, filter model.SubscriptionFilter, after *model.SubscriptionCursor, limit int) (*model.SubscriptionCursorPage, error) {
+	ctx, span := startSpan(ctx, "ListSubscriptionsAfter")
+	result, err := s.next.ListSubscriptionsAfter(ctx, filter, after, limit)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) SearchSubscriptions(ctx context.Context, query string, userID *uuid.UUID, limit int) ([]*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "SearchSubscriptions")
+	result, err := s.next.SearchSubscriptions(ctx, query, userID, limit)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) ExportSubscriptions(ctx context.Context, fn func(*model.Subscription) error) error {
+	ctx, span := startSpan(ctx, "ExportSubscriptions")
+	err := s.next.ExportSubscriptions(ctx, fn)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracedSubscriptionService) StreamSubscriptions(ctx context.Context, filter model.SubscriptionFilter, fn func(*model.Subscription) error) error {
+	ctx, span := startSpan(ctx, "StreamSubscriptions")
+	err := s.next.StreamSubscriptions(ctx, filter, fn)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracedSubscriptionService) ActivateSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "ActivateSubscription")
+	sub, err := s.next.ActivateSubscription(ctx, id)
+	endSpan(span, err)
+	return sub, err
+}
+
+func (s *tracedSubscriptionService) CancelSubscription(ctx context.Context, id uuid.UUID, req model.CancelSubscriptionRequest) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "CancelSubscription")
+	sub, err := s.next.CancelSubscription(ctx, id, req)
+	endSpan(span, err)
+	return sub, err
+}
+
+func (s *tracedSubscriptionService) TransferSubscription(ctx context.Context, id uuid.UUID, req model.TransferSubscriptionRequest) (*model.Subscription, error) {
+	ctx, span := startSpan(ctx, "TransferSubscription")
+	sub, err := s.next.TransferSubscription(ctx, id, req)
+	endSpan(span, err)
+	return sub, err
+}
+
+func (s *tracedSubscriptionService) ListChanges(ctx context.Context, sinceSeq int64, limit int) (*model.ChangesResponse, error) {
+	ctx, span := startSpan(ctx, "ListChanges")
+	result, err := s.next.ListChanges(ctx, sinceSeq, limit)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) CalculateTotalCost(ctx context.Context, filter model.SummaryFilter) (*model.SummaryResponse, error) {
+	ctx, span := startSpan(ctx, "CalculateTotalCost")
+	span.SetAttributes(
+		tracing.Attribute{Key: "summary.start_period", Value: filter.StartPeriod},
+		tracing.Attribute{Key: "summary.end_period", Value: filter.EndPeriod},
+	)
+	result, err := s.next.CalculateTotalCost(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) VerifySummary(ctx context.Context, summary *model.SummaryResponse) (*model.SummaryVerification, error) {
+	ctx, span := startSpan(ctx, "VerifySummary")
+	result, err := s.next.VerifySummary(ctx, summary)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) ListUserServices(ctx context.Context, userID uuid.UUID) ([]model.UserService, error) {
+	ctx, span := startSpan(ctx, "ListUserServices")
+	result, err := s.next.ListUserServices(ctx, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) BulkCreateSubscriptions(ctx context.Context, mode string, reqs []model.CreateSubscriptionRequest, invalid map[int]error) (*model.BulkResponse, error) {
+	ctx, span := startSpan(ctx, "BulkCreateSubscriptions")
+	span.SetAttributes(tracing.Attribute{Key: "bulk.mode", Value: mode}, tracing.Attribute{Key: "bulk.items", Value: len(reqs)})
+	result, err := s.next.BulkCreateSubscriptions(ctx, mode, reqs, invalid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) BulkUpdateSubscriptions(ctx context.Context, mode string, items []model.BulkUpdateItem, invalid map[int]error) (*model.BulkResponse, error) {
+	ctx, span := startSpan(ctx, "BulkUpdateSubscriptions")
+	span.SetAttributes(tracing.Attribute{Key: "bulk.mode", Value: mode}, tracing.Attribute{Key: "bulk.items", Value: len(items)})
+	result, err := s.next.BulkUpdateSubscriptions(ctx, mode, items, invalid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) BulkDeleteSubscriptions(ctx context.Context, mode string, ids []uuid.UUID) (*model.BulkResponse, error) {
+	ctx, span := startSpan(ctx, "BulkDeleteSubscriptions")
+	span.SetAttributes(tracing.Attribute{Key: "bulk.mode", Value: mode}, tracing.Attribute{Key: "bulk.items", Value: len(ids)})
+	result, err := s.next.BulkDeleteSubscriptions(ctx, mode, ids)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedSubscriptionService) SyncUserSubscriptions(ctx context.Context, userID uuid.UUID, items []model.SyncSubscriptionItem, dryRun bool) (*model.SyncResponse, error) {
+	ctx, span := startSpan(ctx, "SyncUserSubscriptions")
+	span.SetAttributes(tracing.Attribute{Key: "sync.items", Value: len(items)}, tracing.Attribute{Key: "sync.dry_run", Value: dryRun})
+	result, err := s.next.SyncUserSubscriptions(ctx, userID, items, dryRun)
+	endSpan(span, err)
+	return result, err
+}
@@ -0,0 +1,45 @@
+// Package signing подписывает результаты расчетов HMAC-SHA256 секретом развертывания,
+// чтобы получатель мог позже проверить, что числа не изменены
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Algorithm - алгоритм подписи в ответах
+const Algorithm = "HMAC-SHA256"
+
+// Signer подписывает данные одним ключом. KeyID передается вместе с подписью, чтобы
+// после смены ключа было видно, каким ключом подписан результат
+type Signer struct {
+	keyID string
+	key   []byte
+}
+
+func NewSigner(keyID string, key []byte) *Signer {
+	return &Signer{keyID: keyID, key: key}
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign возвращает подпись payload в шестнадцатеричном виде
+func (s *Signer) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись за постоянное время
+func (s *Signer) Verify(payload []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,24 @@
+package signing
+
+import "testing"
+
+func TestSignVerify(t *testing.T) {
+	signer := NewSigner("v1", []byte("0123456789abcdef0123456789abcdef"))
+	payload := []byte("total_cost=2400")
+
+	signature := signer.Sign(payload)
+	if !signer.Verify(payload, signature) {
+		t.Fatal("Verify() = false for own signature")
+	}
+	if signer.Verify([]byte("total_cost=2401"), signature) {
+		t.Error("Verify() = true for changed payload")
+	}
+	if signer.Verify(payload, "not-hex") {
+		t.Error("Verify() = true for malformed signature")
+	}
+
+	other := NewSigner("v2", []byte("another key another key another key"))
+	if other.Verify(payload, signature) {
+		t.Error("Verify() = true for signature made with another key")
+	}
+}
@@ -0,0 +1,29 @@
+// Package tenant описывает организацию, данными которой ограничен запрос; в контексте
+// ее хранит ctxutil. Репозитории добавляют ее ко всем запросам, поэтому организации
+// одной установки сервиса не видят данные друг друга
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Default - организация запросов без явно указанной организации и данных,
+// созданных до появления организаций
+const Default = "default"
+
+// idPattern ограничивает идентификатор организации: он попадает в логи и заголовки
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ErrInvalidTenant - идентификатор организации не соответствует формату
+var ErrInvalidTenant = errors.New("invalid tenant")
+
+// Validate проверяет идентификатор организации: строчные латинские буквы, цифры,
+// "-" и "_", не длиннее 64 символов
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w %q: expected lowercase letters, digits, '-' or '_', up to 64 characters", ErrInvalidTenant, id)
+	}
+	return nil
+}
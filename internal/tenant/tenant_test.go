@@ -0,0 +1,29 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"acme", false},
+		{"acme-corp_2", false},
+		{Default, false},
+		{"", true},
+		{"Acme", true},
+		{"-acme", true},
+		{"acme corp", true},
+		{"acme'; DROP TABLE subscriptions; --", true},
+		{strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.id); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package timing собирает время обработки запроса по слоям - обработчик, сервис, база -
+// для заголовка Server-Timing. Клиент видит, на что ушло время запроса, без доступа
+// к трассам сервера
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder - время запроса по слоям. Методы nil-Recorder ничего не делают, поэтому
+// сервисы и пул соединений не проверяют, включен ли Server-Timing
+type Recorder struct {
+	start time.Time
+
+	mu           sync.Mutex
+	service      time.Duration
+	serviceDepth int
+	db           time.Duration
+	queries      int
+}
+
+// NewRecorder начинает отсчет времени запроса
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+type recorderKey struct{}
+
+// WithRecorder возвращает контекст, в который слои записывают свое время
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext возвращает Recorder запроса; вне запроса с Server-Timing - nil
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Service отмечает начало вызова сервиса и возвращает функцию его завершения.
+// Вложенные вызовы сервисов не учитываются повторно
+func (r *Recorder) Service() func() {
+	if r == nil {
+		return func() {}
+	}
+	r.mu.Lock()
+	r.serviceDepth++
+	r.mu.Unlock()
+
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.serviceDepth--
+		if r.serviceDepth == 0 {
+			r.service += elapsed
+		}
+	}
+}
+
+// Query добавляет время выполнения запроса к базе
+func (r *Recorder) Query(elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.db += elapsed
+	r.queries++
+}
+
+// Elapsed возвращает время с начала запроса
+func (r *Recorder) Elapsed() time.Duration {
+	return time.Since(r.start)
+}
+
+// Header возвращает значение Server-Timing: время обработчика (без сервиса), сервиса
+// (без базы), базы с числом запросов и общее время. Непустой budget добавляется
+// отдельной метрикой, чтобы клиент сравнил с ним total
+func (r *Recorder) Header(budget time.Duration) string {
+	total := r.Elapsed()
+
+	r.mu.Lock()
+	service, db, queries := r.service, r.db, r.queries
+	r.mu.Unlock()
+
+	// Запросы к базе могут выполняться параллельно, поэтому разности ограничены нулем
+	metrics := []string{
+		metric("handler", max(total-service, 0), ""),
+		metric("service", max(service-db, 0), ""),
+		metric("db", db, fmt.Sprintf("queries: %d", queries)),
+		metric("total", total, ""),
+	}
+	if budget > 0 {
+		metrics = append(metrics, metric("budget", budget, ""))
+	}
+	return strings.Join(metrics, ", ")
+}
+
+func metric(name string, d time.Duration, desc string) string {
+	value := fmt.Sprintf("%s;dur=%.1f", name, float64(d.Microseconds())/1000)
+	if desc != "" {
+		value += fmt.Sprintf(`;desc="%s"`, desc)
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package timing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	ctx := WithRecorder(context.Background(), r)
+
+	outer := FromContext(ctx).Service()
+	inner := FromContext(ctx).Service()
+	FromContext(ctx).Query(2 * time.Millisecond)
+	FromContext(ctx).Query(3 * time.Millisecond)
+	inner()
+	outer()
+
+	if r.db != 5*time.Millisecond || r.queries != 2 {
+		t.Errorf("db = %v in %d queries, want 5ms in 2", r.db, r.queries)
+	}
+	if r.service <= 0 || r.serviceDepth != 0 {
+		t.Errorf("service = %v, depth = %d", r.service, r.serviceDepth)
+	}
+
+	header := r.Header(500 * time.Millisecond)
+	for _, want := range []string{"handler;dur=", "service;dur=", `db;dur=5.0;desc="queries: 2"`, "total;dur=", "budget;dur=500.0"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("Header() = %q, missing %q", header, want)
+		}
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	r := FromContext(context.Background())
+	if r != nil {
+		t.Fatalf("FromContext() without recorder = %v, want nil", r)
+	}
+	// Слои вызывают методы без проверки, включен ли Server-Timing
+	r.Service()()
+	r.Query(time.Millisecond)
+}
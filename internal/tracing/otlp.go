@@ -0,0 +1,260 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// exportBatchSize - сколько спанов отправляется одним запросом
+	exportBatchSize = 512
+	// exportQueueSize - сколько завершенных спанов ждет отправки; остальные отбрасываются,
+	// чтобы недоступный коллектор не замедлял запросы и не занимал память
+	exportQueueSize = 4096
+)
+
+// OTLPExporter отправляет спаны пачками в коллектор OpenTelemetry по OTLP/HTTP
+// в кодировке JSON (POST <endpoint>/v1/traces)
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	interval    time.Duration
+
+	queue   chan *Span
+	flush   chan chan struct{}
+	stop    sync.Once
+	dropped atomic.Int64
+	onError func(error)
+}
+
+// NewOTLPExporter запускает отправку спанов каждые interval и при накоплении пачки.
+// onError получает ошибки отправки; спаны неудачной пачки не повторяются. nil client -
+// клиент с таймаутом 10 секунд
+func NewOTLPExporter(endpoint, serviceName string, interval time.Duration, client *http.Client, onError func(error)) *OTLPExporter {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	e := &OTLPExporter{
+		url:         strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      client,
+		interval:    interval,
+		queue:       make(chan *Span, exportQueueSize),
+		flush:       make(chan chan struct{}),
+		onError:     onError,
+	}
+	go e.run()
+	return e
+}
+
+// Export ставит спан в очередь отправки, не блокируясь
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Dropped возвращает число спанов, отброшенных из-за переполнения очереди
+func (e *OTLPExporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Shutdown отправляет накопленные спаны и останавливает экспортер
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	var err error
+	e.stop.Do(func() {
+		flushed := make(chan struct{})
+		select {
+		case e.flush <- flushed:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	})
+	return err
+}
+
+func (e *OTLPExporter) run() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exportBatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil && e.onError != nil {
+			e.onError(err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == exportBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case flushed := <-e.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) == exportBatchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(flushed)
+			return
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to export spans: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Структуры OTLP в JSON-представлении protobuf: идентификаторы - hex, время - строка наносекунд
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpPayload struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func (e *OTLPExporter) payload(spans []*Span) otlpPayload {
+	scope := otlpScopeSpans{
+		Scope: otlpScope{Name: "github.com/Zipklas/subscription-service/internal/tracing"},
+		Spans: make([]otlpSpan, 0, len(spans)),
+	}
+	for _, span := range spans {
+		span.mu.Lock()
+		out := otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		}
+		if span.ParentID != (SpanID{}) {
+			out.ParentSpanID = span.ParentID.String()
+		}
+		for _, a := range span.attributes {
+			out.Attributes = append(out.Attributes, attribute(a))
+		}
+		if span.err != "" {
+			out.Status = otlpStatus{Code: 2, Message: span.err}
+		}
+		span.mu.Unlock()
+		scope.Spans = append(scope.Spans, out)
+	}
+
+	return otlpPayload{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{attribute(Attribute{Key: "service.name", Value: e.serviceName})}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
+
+func attribute(a Attribute) otlpAttribute {
+	out := otlpAttribute{Key: a.Key}
+	switch v := a.Value.(type) {
+	case string:
+		out.Value.StringValue = &v
+	case bool:
+		out.Value.BoolValue = &v
+	case int:
+		s := strconv.Itoa(v)
+		out.Value.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		out.Value.IntValue = &s
+	case float64:
+		out.Value.DoubleValue = &v
+	default:
+		s := fmt.Sprint(v)
+		out.Value.StringValue = &s
+	}
+	return out
+}
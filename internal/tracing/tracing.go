@@ -0,0 +1,189 @@
+// Package tracing записывает спаны запросов и передает их экспортеру OTLP.
+// Контекст трассировки принимается и передается в формате W3C traceparent, поэтому
+// спаны сервиса встраиваются в трассы шлюза и клиентов, инструментированных OpenTelemetry
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Виды спанов в нумерации OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext - идентификаторы спана, передаваемые между сервисами
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// Exporter получает завершенные спаны
+type Exporter interface {
+	Export(span *Span)
+}
+
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter включает запись спанов; nil отключает ее, и Start возвращает пустые спаны
+func SetExporter(e Exporter) {
+	if e == nil {
+		exporter.Store(nil)
+		return
+	}
+	exporter.Store(&e)
+}
+
+// Attribute - атрибут спана; Value - string, bool, int, int64 или float64
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span - операция трассы. Методы nil-спана ничего не делают, поэтому код
+// не проверяет, включена ли трассировка
+type Span struct {
+	Name     string
+	Kind     int
+	Context  SpanContext
+	ParentID SpanID
+	Start    time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []Attribute
+	err        string
+	exporter   Exporter
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// Start начинает спан, дочерний к спану контекста или к удаленному спану из traceparent.
+// Без экспортера и для трасс, которые вызывающая сторона не записывает, возвращает nil
+func Start(ctx context.Context, name string, kind int, attributes ...Attribute) (context.Context, *Span) {
+	e := exporter.Load()
+	if e == nil {
+		return ctx, nil
+	}
+
+	span := &Span{Name: name, Kind: kind, Start: time.Now(), attributes: attributes, exporter: *e}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.Context.TraceID = parent.Context.TraceID
+		span.ParentID = parent.Context.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		if !remote.Sampled {
+			return ctx, nil
+		}
+		span.Context.TraceID = remote.TraceID
+		span.ParentID = remote.SpanID
+	} else {
+		_, _ = rand.Read(span.Context.TraceID[:])
+	}
+	_, _ = rand.Read(span.Context.SpanID[:])
+	span.Context.Sampled = true
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext возвращает текущий спан контекста или nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// WithRemoteParent возвращает контекст, в котором следующий Start продолжит трассу parent
+func WithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, parent)
+}
+
+// SetAttributes добавляет атрибуты спану
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attributes = append(s.attributes, attributes...)
+	s.mu.Unlock()
+}
+
+// RecordError помечает спан как завершившийся ошибкой; nil не меняет спан
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err.Error()
+	s.mu.Unlock()
+}
+
+// End завершает спан и передает его экспортеру; повторные вызовы игнорируются
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.exporter.Export(s)
+}
+
+// ParseTraceparent разбирает заголовок W3C traceparent: "00-<trace-id>-<parent-id>-<flags>"
+func ParseTraceparent(header string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", header)
+	}
+
+	var sc SpanContext
+	var flags [1]byte
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", header)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent trace id: %w", err)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent parent id: %w", err)
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent flags: %w", err)
+	}
+	if sc.TraceID == (TraceID{}) || sc.SpanID == (SpanID{}) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q: zero id", header)
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// Traceparent форматирует контекст спана для заголовка traceparent
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
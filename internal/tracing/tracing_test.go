@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		header      string
+		wantErr     bool
+		wantSampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false, false},
+		// Будущие версии могут добавлять поля после флагов
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", true, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01", true, false},
+		{"", true, false},
+	}
+
+	for _, tt := range tests {
+		sc, err := ParseTraceparent(tt.header)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTraceparent(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if err == nil && sc.Sampled != tt.wantSampled {
+			t.Errorf("ParseTraceparent(%q) sampled = %v", tt.header, sc.Sampled)
+		}
+	}
+
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Traceparent() = %q", got)
+	}
+}
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(span *Span) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+}
+
+func TestStart(t *testing.T) {
+	if _, span := Start(context.Background(), "disabled", KindInternal); span != nil {
+		t.Fatal("Start() without exporter returned a span")
+	}
+
+	exporter := &recordingExporter{}
+	SetExporter(exporter)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, server := Start(WithRemoteParent(context.Background(), remote), "GET /subscriptions", KindServer)
+	_, child := Start(ctx, "db SELECT", KindClient)
+	child.RecordError(errors.New("statement timeout"))
+	child.End()
+	server.End()
+	server.End()
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(exporter.spans))
+	}
+	if server.Context.TraceID != remote.TraceID || server.ParentID != remote.SpanID {
+		t.Errorf("server span does not continue remote trace: %+v", server.Context)
+	}
+	if child.Context.TraceID != remote.TraceID || child.ParentID != server.Context.SpanID || child.err != "statement timeout" {
+		t.Errorf("unexpected child span: %+v", child)
+	}
+
+	// Трассу, которую вызывающая сторона не записывает, сервис тоже не записывает
+	notSampled, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if _, span := Start(WithRemoteParent(context.Background(), notSampled), "GET /health", KindServer); span != nil {
+		t.Error("Start() recorded a span of a not sampled trace")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var payload otlpPayload
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL+"/", "subscription-service", time.Hour, nil, func(err error) { t.Error(err) })
+	SetExporter(exporter)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	_, span := Start(context.Background(), "SubscriptionService.CalculateTotalCost", KindInternal,
+		Attribute{Key: "summary.start_period", Value: "01-2025"},
+		Attribute{Key: "rows", Value: 3},
+	)
+	span.RecordError(errors.New("failed"))
+	span.End()
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payload.ResourceSpans) != 1 || len(payload.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.TraceID != span.Context.TraceID.String() || got.Kind != KindInternal || got.Status.Code != 2 || got.ParentSpanID != "" {
+		t.Errorf("unexpected span: %+v", got)
+	}
+	if len(got.Attributes) != 2 || *got.Attributes[0].Value.StringValue != "01-2025" || *got.Attributes[1].Value.IntValue != "3" {
+		t.Errorf("unexpected attributes: %+v", got.Attributes)
+	}
+	if name := payload.ResourceSpans[0].Resource.Attributes[0]; name.Key != "service.name" || *name.Value.StringValue != "subscription-service" {
+		t.Errorf("unexpected resource: %+v", payload.ResourceSpans[0].Resource)
+	}
+}
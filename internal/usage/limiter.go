@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// Limiter ограничивает число запросов ключа в минуту (фиксированное окно).
+// Нулевой лимит отключает ограничение, но потребление все равно считается.
+// Если за минуту пришли запросы с MaxKeys разными ключами, новые ключи до конца
+// минуты не ограничиваются и не запоминаются
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int
+	windows map[string]*window
+}
+
+type window struct {
+	start time.Time
+	used  int
+}
+
+func NewLimiter(limitPerMinute int) *Limiter {
+	return &Limiter{
+		limit:   limitPerMinute,
+		windows: make(map[string]*window),
+	}
+}
+
+// Allow учитывает запрос и сообщает, укладывается ли он в лимит
+func (l *Limiter) Allow(apiKey string, now time.Time) (model.RateLimitUsage, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.current(apiKey, now)
+	if l.limit > 0 && w.used >= l.limit {
+		return l.usage(w), false
+	}
+	w.used++
+	return l.usage(w), true
+}
+
+// Usage возвращает потребление лимита в текущем окне без учета нового запроса
+func (l *Limiter) Usage(apiKey string, now time.Time) model.RateLimitUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.usage(l.current(apiKey, now))
+}
+
+func (l *Limiter) current(apiKey string, now time.Time) *window {
+	start := now.UTC().Truncate(time.Minute)
+
+	w, ok := l.windows[apiKey]
+	if !ok || !w.start.Equal(start) {
+		if !ok && len(l.windows) > 0 {
+			l.cleanup(start)
+		}
+		w = &window{start: start}
+		if ok || len(l.windows) < MaxKeys {
+			l.windows[apiKey] = w
+		}
+	}
+	return w
+}
+
+// cleanup удаляет окна прошлых минут, чтобы карта не росла с числом ключей
+func (l *Limiter) cleanup(current time.Time) {
+	for key, w := range l.windows {
+		if w.start.Before(current) {
+			delete(l.windows, key)
+		}
+	}
+}
+
+func (l *Limiter) usage(w *window) model.RateLimitUsage {
+	usage := model.RateLimitUsage{
+		LimitPerMinute: l.limit,
+		Used:           w.used,
+		ResetAt:        w.start.Add(time.Minute),
+	}
+	if l.limit > 0 {
+		usage.Remaining = max(l.limit-w.used, 0)
+	}
+	return usage
+}
@@ -0,0 +1,142 @@
+// Package usage считает запросы к API по ключам клиентов и ограничивает их частоту.
+// Счетчики хранятся в памяти процесса: при нескольких репликах каждая считает свою долю трафика.
+// Ключ клиента не проверяется, поэтому статистика и лимит справочные: клиент, сменивший
+// ключ, считается новым. Число ключей ограничено MaxKeys, чтобы произвольные ключи не
+// занимали память без предела
+package usage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+const dateLayout = "2006-01-02"
+
+// MaxKeys - сколько ключей одновременно хранят Store и Limiter
+const MaxKeys = 10000
+
+// Store - счетчики запросов по ключу, дню (UTC) и эндпоинту
+type Store struct {
+	mu        sync.Mutex
+	retention int
+	// counters[apiKey][day][endpoint]
+	counters map[string]map[string]map[string]int64
+	// today - день последнего запроса; со сменой дня удаляются ключи без запросов за период хранения
+	today string
+}
+
+// NewStore создает хранилище, которое держит статистику за последние retentionDays дней
+func NewStore(retentionDays int) *Store {
+	if retentionDays < 1 {
+		retentionDays = 1
+	}
+	return &Store{
+		retention: retentionDays,
+		counters:  make(map[string]map[string]map[string]int64),
+	}
+}
+
+// Retention возвращает количество дней, за которые хранится статистика
+func (s *Store) Retention() int {
+	return s.retention
+}
+
+// Increment учитывает один запрос клиента к эндпоинту
+func (s *Store) Increment(apiKey, endpoint string, at time.Time) {
+	day := at.UTC().Format(dateLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day != s.today {
+		s.today = day
+		s.sweep(at)
+	}
+
+	days, ok := s.counters[apiKey]
+	if !ok {
+		if len(s.counters) >= MaxKeys {
+			s.evict(at)
+		}
+		days = make(map[string]map[string]int64)
+		s.counters[apiKey] = days
+	}
+	endpoints, ok := days[day]
+	if !ok {
+		endpoints = make(map[string]int64)
+		days[day] = endpoints
+		// Новый день - удобный момент отбросить устаревшую статистику ключа
+		s.expire(days, at)
+	}
+	endpoints[endpoint]++
+}
+
+// sweep удаляет устаревшую статистику всех ключей и ключи, по которым не осталось статистики
+func (s *Store) sweep(now time.Time) {
+	for apiKey, days := range s.counters {
+		s.expire(days, now)
+		if len(days) == 0 {
+			delete(s.counters, apiKey)
+		}
+	}
+}
+
+// evict освобождает место для нового ключа: удаляет ключи без запросов за период хранения,
+// а если их нет - ключ с самым давним последним запросом
+func (s *Store) evict(now time.Time) {
+	s.sweep(now)
+	if len(s.counters) < MaxKeys {
+		return
+	}
+
+	oldestKey, oldestDay := "", ""
+	for apiKey, days := range s.counters {
+		latest := ""
+		for day := range days {
+			latest = max(latest, day)
+		}
+		if oldestKey == "" || latest < oldestDay {
+			oldestKey, oldestDay = apiKey, latest
+		}
+	}
+	delete(s.counters, oldestKey)
+}
+
+func (s *Store) expire(days map[string]map[string]int64, now time.Time) {
+	oldest := now.UTC().AddDate(0, 0, -(s.retention - 1)).Format(dateLayout)
+	for day := range days {
+		// Даты в формате YYYY-MM-DD сравниваются как строки
+		if day < oldest {
+			delete(days, day)
+		}
+	}
+}
+
+// Usage возвращает статистику клиента за последние days дней, начиная с текущего
+func (s *Store) Usage(apiKey string, days int, now time.Time) []model.DailyUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]model.DailyUsage, 0, days)
+	for i := 0; i < days; i++ {
+		day := now.UTC().AddDate(0, 0, -i).Format(dateLayout)
+		daily := model.DailyUsage{Date: day, Endpoints: []model.EndpointUsage{}}
+
+		for endpoint, count := range s.counters[apiKey][day] {
+			daily.Requests += count
+			daily.Endpoints = append(daily.Endpoints, model.EndpointUsage{Endpoint: endpoint, Requests: count})
+		}
+		sort.Slice(daily.Endpoints, func(a, b int) bool {
+			if daily.Endpoints[a].Requests != daily.Endpoints[b].Requests {
+				return daily.Endpoints[a].Requests > daily.Endpoints[b].Requests
+			}
+			return daily.Endpoints[a].Endpoint < daily.Endpoints[b].Endpoint
+		})
+
+		result = append(result, daily)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2)
+	now := time.Date(2025, time.July, 10, 9, 30, 15, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if _, ok := limiter.Allow("key", now); !ok {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+
+	usage, ok := limiter.Allow("key", now.Add(10*time.Second))
+	if ok {
+		t.Fatal("third request in the same minute should be rejected")
+	}
+	if usage.Used != 2 || usage.Remaining != 0 || !usage.ResetAt.Equal(now.Truncate(time.Minute).Add(time.Minute)) {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	if _, ok := limiter.Allow("other", now); !ok {
+		t.Error("limit must be tracked per key")
+	}
+	if _, ok := limiter.Allow("key", now.Add(time.Minute)); !ok {
+		t.Error("request in the next minute should be allowed")
+	}
+}
+
+func TestStoreUsage(t *testing.T) {
+	store := NewStore(2)
+	day1 := time.Date(2025, time.July, 9, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+
+	store.Increment("key", "GET /api/v1/subscriptions", day1)
+	store.Increment("key", "GET /api/v1/subscriptions", day2)
+	store.Increment("key", "GET /api/v1/subscriptions", day2)
+	store.Increment("key", "POST /api/v1/subscriptions", day2)
+	store.Increment("other", "GET /api/v1/subscriptions", day2)
+
+	usage := store.Usage("key", 2, day2)
+	if len(usage) != 2 || usage[0].Date != "2025-07-10" || usage[1].Date != "2025-07-09" {
+		t.Fatalf("unexpected days: %+v", usage)
+	}
+	if usage[0].Requests != 3 || usage[0].Endpoints[0].Endpoint != "GET /api/v1/subscriptions" || usage[0].Endpoints[0].Requests != 2 {
+		t.Errorf("unexpected usage for 2025-07-10: %+v", usage[0])
+	}
+	if usage[1].Requests != 1 {
+		t.Errorf("unexpected usage for 2025-07-09: %+v", usage[1])
+	}
+
+	// Через два дня статистика за 9 июля выходит за пределы хранения
+	store.Increment("key", "GET /api/v1/subscriptions", day1.AddDate(0, 0, 2))
+	if usage := store.Usage("key", 3, day1.AddDate(0, 0, 2)); usage[2].Requests != 0 {
+		t.Errorf("expired day should be dropped: %+v", usage[2])
+	}
+}
+
+func TestStoreEvictsKeys(t *testing.T) {
+	store := NewStore(2)
+	day := time.Date(2025, time.July, 10, 9, 0, 0, 0, time.UTC)
+
+	store.Increment("stale", "GET /api/v1/subscriptions", day)
+	// После периода хранения ключ без новых запросов удаляется со сменой дня
+	store.Increment("key", "GET /api/v1/subscriptions", day.AddDate(0, 0, 2))
+	if _, ok := store.counters["stale"]; ok {
+		t.Error("key without requests in the retention window should be evicted")
+	}
+
+	// Заполненное хранилище освобождает место за счет ключа с самым давним запросом
+	store = NewStore(2)
+	now := day.AddDate(0, 0, 3)
+	store.Increment("oldest", "GET /api/v1/subscriptions", now.AddDate(0, 0, -1))
+	for i := 0; len(store.counters) < MaxKeys; i++ {
+		store.Increment(fmt.Sprintf("key-%d", i), "GET /api/v1/subscriptions", now)
+	}
+	store.Increment("new", "GET /api/v1/subscriptions", now)
+	if len(store.counters) > MaxKeys {
+		t.Errorf("store holds %d keys, want at most %d", len(store.counters), MaxKeys)
+	}
+	if _, ok := store.counters["oldest"]; ok {
+		t.Error("least recently active key should be evicted")
+	}
+	if usage := store.Usage("new", 1, now); usage[0].Requests != 1 {
+		t.Errorf("new key usage = %+v, want 1 request", usage[0])
+	}
+}
+
+func TestLimiterBoundsKeys(t *testing.T) {
+	limiter := NewLimiter(1)
+	now := time.Date(2025, time.July, 10, 9, 30, 0, 0, time.UTC)
+
+	for i := 0; i < MaxKeys+10; i++ {
+		limiter.Allow(fmt.Sprintf("key-%d", i), now)
+	}
+	if len(limiter.windows) > MaxKeys {
+		t.Errorf("limiter holds %d windows, want at most %d", len(limiter.windows), MaxKeys)
+	}
+	// Окна прошлой минуты удаляются с первым новым ключом
+	limiter.Allow("next", now.Add(time.Minute))
+	if len(limiter.windows) != 1 {
+		t.Errorf("limiter holds %d windows after a minute, want 1", len(limiter.windows))
+	}
+}
@@ -0,0 +1,301 @@
+// Package webhook рассылает события сервиса на HTTP-адреса подписчиков. Каждый адрес
+// обслуживается своей очередью с ограниченным числом одновременных запросов и своим
+// автоматическим выключателем, поэтому медленный или недоступный адрес не задерживает
+// доставку остальным
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/eventschema"
+	"github.com/Zipklas/subscription-service/internal/model"
+)
+
+// Заголовки запроса с типом события и версией схемы его данных
+const (
+	EventTypeHeader          = "X-Event-Type"
+	EventSchemaVersionHeader = "X-Event-Schema-Version"
+)
+
+// Event - событие, отправляемое подписчикам в теле POST. Data соответствует схеме
+// версии из заголовка X-Event-Schema-Version
+type Event struct {
+	Type       string          `json:"type"`
+	Tenant     string          `json:"tenant"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Config - параметры доставки
+type Config struct {
+	// Workers - сколько запросов всех адресов выполняется одновременно
+	Workers int
+	// PerEndpoint - сколько запросов к одному адресу выполняется одновременно
+	PerEndpoint int
+	// QueueSize - сколько событий ждет отправки на один адрес; остальные отбрасываются
+	QueueSize int
+	Timeout   time.Duration
+	// FailureThreshold - число ошибок подряд, после которого адрес отключается на Cooldown
+	FailureThreshold int
+	Cooldown         time.Duration
+	// Client - клиент доставки с настройками исходящих запросов; nil - клиент с таймаутом Timeout
+	Client *http.Client
+	// Schemas - схемы данных событий; nil - схемы, встроенные в сервис
+	Schemas *eventschema.Registry
+}
+
+func (c Config) withDefaults() Config {
+	if c.Workers < 1 {
+		c.Workers = 16
+	}
+	if c.PerEndpoint < 1 {
+		c.PerEndpoint = 2
+	}
+	if c.QueueSize < 1 {
+		c.QueueSize = 1000
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: c.Timeout}
+	}
+	if c.FailureThreshold < 1 {
+		c.FailureThreshold = 5
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = time.Minute
+	}
+	if c.Schemas == nil {
+		c.Schemas = eventschema.Default
+	}
+	return c
+}
+
+// Dispatcher рассылает события на адреса подписчиков. Нулевой указатель допустим
+// и ничего не отправляет
+type Dispatcher struct {
+	cfg       Config
+	client    *http.Client
+	workers   chan struct{}
+	endpoints []*endpoint
+	onError   func(url string, err error)
+	wg        sync.WaitGroup
+	stop      sync.Once
+}
+
+// delivery - событие в очереди адреса
+type delivery struct {
+	eventType string
+	version   int
+	body      []byte
+}
+
+type endpoint struct {
+	url   string
+	queue chan delivery
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+
+	mu          sync.Mutex
+	failures    int
+	openedUntil time.Time
+}
+
+// NewDispatcher запускает доставку на urls. onError получает ошибки отправки; неудачная
+// доставка не повторяется
+func NewDispatcher(urls []string, cfg Config, onError func(url string, err error)) *Dispatcher {
+	cfg = cfg.withDefaults()
+	d := &Dispatcher{
+		cfg:     cfg,
+		client:  cfg.Client,
+		workers: make(chan struct{}, cfg.Workers),
+		onError: onError,
+	}
+	for _, url := range urls {
+		e := &endpoint{url: url, queue: make(chan delivery, cfg.QueueSize)}
+		d.endpoints = append(d.endpoints, e)
+		for i := 0; i < cfg.PerEndpoint; i++ {
+			d.wg.Add(1)
+			go d.run(e)
+		}
+	}
+	return d
+}
+
+// Publish ставит событие в очереди всех адресов, не блокируясь. Организация события
+// берется из ctx. Данные проверяются по последней версии схемы типа; событие без схемы
+// или с данными, не прошедшими проверку, не отправляется
+func (d *Dispatcher) Publish(ctx context.Context, eventType string, data interface{}) {
+	if d == nil || len(d.endpoints) == 0 {
+		return
+	}
+
+	version, ok := d.cfg.Schemas.Latest(eventType)
+	if !ok {
+		d.report("", fmt.Errorf("failed to publish %s event: no schema registered", eventType))
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		d.report("", fmt.Errorf("failed to encode %s event: %w", eventType, err))
+		return
+	}
+	if err := d.cfg.Schemas.Validate(eventType, version, payload); err != nil {
+		d.report("", fmt.Errorf("failed to publish %s event: %w", eventType, err))
+		return
+	}
+
+	body, err := json.Marshal(Event{
+		Type:       eventType,
+		Tenant:     ctxutil.TenantID(ctx),
+		OccurredAt: time.Now().UTC(),
+		Data:       payload,
+	})
+	if err != nil {
+		d.report("", fmt.Errorf("failed to encode %s event: %w", eventType, err))
+		return
+	}
+
+	event := delivery{eventType: eventType, version: version, body: body}
+	for _, e := range d.endpoints {
+		select {
+		case e.queue <- event:
+		default:
+			e.dropped.Add(1)
+		}
+	}
+}
+
+// Close прекращает прием событий и ждет отправки уже поставленных в очередь или отмены ctx
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.stop.Do(func() {
+		for _, e := range d.endpoints {
+			close(e.queue)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run(e *endpoint) {
+	defer d.wg.Done()
+	for event := range e.queue {
+		if !e.allow(time.Now()) {
+			// Выключатель разомкнут: событие не отправляется, чтобы не тратить воркеры
+			e.dropped.Add(1)
+			continue
+		}
+
+		d.workers <- struct{}{}
+		err := d.send(e.url, event)
+		<-d.workers
+
+		e.record(err, time.Now(), d.cfg)
+		if err != nil {
+			e.failed.Add(1)
+			d.report(e.url, err)
+			continue
+		}
+		e.delivered.Add(1)
+	}
+}
+
+func (d *Dispatcher) send(url string, event delivery) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(event.body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, event.eventType)
+	req.Header.Set(EventSchemaVersionHeader, strconv.Itoa(event.version))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to deliver webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) report(url string, err error) {
+	if d.onError != nil {
+		d.onError(url, err)
+	}
+}
+
+// allow сообщает, можно ли отправлять на адрес. После Cooldown выключатель пропускает
+// запросы снова; первая же ошибка опять размыкает его
+func (e *endpoint) allow(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.openedUntil)
+}
+
+func (e *endpoint) record(err error, now time.Time, cfg Config) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= cfg.FailureThreshold {
+		e.openedUntil = now.Add(cfg.Cooldown)
+		// Держим счетчик у порога: после паузы одна ошибка снова размыкает выключатель
+		e.failures = cfg.FailureThreshold - 1
+	}
+}
+
+// Stats возвращает состояние доставки по адресам
+func (d *Dispatcher) Stats() []model.WebhookEndpointStats {
+	stats := []model.WebhookEndpointStats{}
+	if d == nil {
+		return stats
+	}
+
+	now := time.Now()
+	for _, e := range d.endpoints {
+		e.mu.Lock()
+		open := now.Before(e.openedUntil)
+		e.mu.Unlock()
+
+		stats = append(stats, model.WebhookEndpointStats{
+			URL:         e.url,
+			QueueDepth:  len(e.queue),
+			Delivered:   e.delivered.Load(),
+			Failed:      e.failed.Load(),
+			Dropped:     e.dropped.Load(),
+			CircuitOpen: open,
+		})
+	}
+	return stats
+}
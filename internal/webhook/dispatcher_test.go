@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/ctxutil"
+	"github.com/Zipklas/subscription-service/internal/eventschema"
+)
+
+// testSchemas - схемы событий тестов: "test" принимает любые данные
+func testSchemas(t *testing.T) *eventschema.Registry {
+	t.Helper()
+	schemas, err := eventschema.Load(fstest.MapFS{
+		"schemas/test.v1.json":  {Data: []byte(`{}`)},
+		"schemas/count.v1.json": {Data: []byte(`{"type": "object", "required": ["n"], "properties": {"n": {"type": "integer"}}}`)},
+		"schemas/count.v2.json": {Data: []byte(`{"type": "object", "required": ["n"], "properties": {"n": {"type": "integer"}, "label": {"type": "string"}}}`)},
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return schemas
+}
+
+func TestSlowEndpointDoesNotDelayOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	received := make(chan Event, 10)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer fast.Close()
+
+	d := NewDispatcher([]string{slow.URL, fast.URL}, Config{Workers: 4, PerEndpoint: 1, Timeout: 10 * time.Second, Schemas: testSchemas(t)}, nil)
+	ctx := ctxutil.WithTenantID(context.Background(), "acme")
+	for i := 0; i < 3; i++ {
+		d.Publish(ctx, "count", map[string]int{"n": i})
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-received:
+			if event.Type != "count" || event.Tenant != "acme" {
+				t.Errorf("unexpected event %+v", event)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("fast endpoint received %d of 3 events while slow endpoint is blocked", i)
+		}
+	}
+
+	// Счетчик доставленных обновляется после ответа подписчика
+	deadline := time.Now().Add(2 * time.Second)
+	for d.Stats()[1].Delivered != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := d.Stats()
+	if stats[0].QueueDepth != 2 {
+		t.Errorf("slow endpoint queue depth = %d, want 2", stats[0].QueueDepth)
+	}
+	if stats[1].Delivered != 3 {
+		t.Errorf("fast endpoint delivered = %d, want 3", stats[1].Delivered)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls atomic.Int64
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	d := NewDispatcher([]string{failing.URL}, Config{PerEndpoint: 1, FailureThreshold: 2, Cooldown: time.Hour, Schemas: testSchemas(t)}, nil)
+	for i := 0; i < 5; i++ {
+		d.Publish(context.Background(), "test", nil)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("endpoint called %d times, want 2 before the circuit opens", calls.Load())
+	}
+	stats := d.Stats()[0]
+	if !stats.CircuitOpen || stats.Failed != 2 || stats.Dropped != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	var out strings.Builder
+	if err := d.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	for _, line := range []string{
+		`subscription_service_webhook_deliveries_total{endpoint="` + failing.URL + `",result="dropped"} 3`,
+		`subscription_service_webhook_circuit_open{endpoint="` + failing.URL + `"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPublishValidatesSchema(t *testing.T) {
+	headers := make(chan http.Header, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+	}))
+	defer server.Close()
+
+	var errs []string
+	d := NewDispatcher([]string{server.URL}, Config{Schemas: testSchemas(t)}, func(url string, err error) {
+		errs = append(errs, err.Error())
+	})
+	d.Publish(context.Background(), "count", map[string]string{"n": "one"})
+	d.Publish(context.Background(), "unknown", nil)
+	d.Publish(context.Background(), "count", map[string]int{"n": 1})
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if len(errs) != 2 || !strings.Contains(errs[0], "invalid count v2 payload") || !strings.Contains(errs[1], "no schema registered") {
+		t.Errorf("errors = %q, want invalid payload and missing schema", errs)
+	}
+	if len(headers) != 1 {
+		t.Fatalf("delivered %d events, want only the valid one", len(headers))
+	}
+	h := <-headers
+	if h.Get(EventTypeHeader) != "count" || h.Get(EventSchemaVersionHeader) != "2" {
+		t.Errorf("headers %s=%q %s=%q, want count and 2", EventTypeHeader, h.Get(EventTypeHeader), EventSchemaVersionHeader, h.Get(EventSchemaVersionHeader))
+	}
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var d *Dispatcher
+	d.Publish(context.Background(), "test", nil)
+	if len(d.Stats()) != 0 {
+		t.Error("nil dispatcher returned stats")
+	}
+}
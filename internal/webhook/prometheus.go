@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/Zipklas/subscription-service/internal/metrics"
+)
+
+// WritePrometheus пишет глубину очередей, результаты доставки и состояние выключателей
+// по адресам в текстовом формате Prometheus
+func (d *Dispatcher) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	stats := d.Stats()
+
+	fmt.Fprintf(bw, "# HELP %s Webhook events waiting for delivery.\n", metrics.WebhookQueueDepthMetric)
+	fmt.Fprintf(bw, "# TYPE %s gauge\n", metrics.WebhookQueueDepthMetric)
+	for _, s := range stats {
+		fmt.Fprintf(bw, "%s{endpoint=%q} %d\n", metrics.WebhookQueueDepthMetric, s.URL, s.QueueDepth)
+	}
+
+	fmt.Fprintf(bw, "# HELP %s Webhook events by delivery result.\n", metrics.WebhookDeliveriesMetric)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", metrics.WebhookDeliveriesMetric)
+	for _, s := range stats {
+		fmt.Fprintf(bw, "%s{endpoint=%q,result=\"delivered\"} %d\n", metrics.WebhookDeliveriesMetric, s.URL, s.Delivered)
+		fmt.Fprintf(bw, "%s{endpoint=%q,result=\"failed\"} %d\n", metrics.WebhookDeliveriesMetric, s.URL, s.Failed)
+		fmt.Fprintf(bw, "%s{endpoint=%q,result=\"dropped\"} %d\n", metrics.WebhookDeliveriesMetric, s.URL, s.Dropped)
+	}
+
+	fmt.Fprintf(bw, "# HELP %s Whether the webhook endpoint circuit breaker is open.\n", metrics.WebhookCircuitOpenMetric)
+	fmt.Fprintf(bw, "# TYPE %s gauge\n", metrics.WebhookCircuitOpenMetric)
+	for _, s := range stats {
+		open := 0
+		if s.CircuitOpen {
+			open = 1
+		}
+		fmt.Fprintf(bw, "%s{endpoint=%q} %d\n", metrics.WebhookCircuitOpenMetric, s.URL, open)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,261 @@
+// Package xlsx читает значения ячеек первого листа книги Office Open XML (.xlsx).
+// Поддерживается только то, что нужно для импорта табличных данных: строки
+// (общие и встроенные), числа и логические значения. Стили и формулы не
+// вычисляются - берется сохраненное в файле значение, поэтому даты, введенные
+// как даты, читаются числом (серийным номером дня), а не текстом.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize ограничивает распакованный размер одной части книги,
+// чтобы сжатый файл небольшого размера не занял всю память
+const maxPartSize = 64 << 20
+
+// maxColumns - число столбцов листа Excel (последний - XFD); ссылки дальше отклоняются,
+// чтобы ячейка вида AAAAAAA1 не заставила выделить строку на сотни миллионов ячеек
+const (
+	maxColumns       = 16384
+	maxColumnLetters = 3
+)
+
+// Row - непустая строка листа. Line - номер строки в книге (с 1),
+// Cells - значения ячеек по столбцам начиная с A; пропущенные ячейки пустые
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// ReadFirstSheet возвращает строки первого листа книги
+func ReadFirstSheet(r io.ReaderAt, size int64) ([]Row, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s not found in workbook", sheetPath)
+	}
+	return readSheet(f, shared)
+}
+
+type workbookXML struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// firstSheetPath находит часть архива с первым листом по workbook.xml и его связям
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook workbookXML
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	var rels relationshipsXML
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		// Target бывает относительным (worksheets/sheet1.xml) и абсолютным (/xl/worksheets/sheet1.xml)
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", fmt.Errorf("relationship %q of the first sheet not found", workbook.Sheets[0].RelID)
+}
+
+// richText - текст, который может быть разбит на фрагменты с разным форматированием
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []richText `xml:"si"`
+	}
+	if err := decodeFile(f, &sst); err != nil {
+		return nil, err
+	}
+
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+type cellXML struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline richText `xml:"is"`
+}
+
+func readSheet(f *zip.File, shared []string) ([]Row, error) {
+	var sheet struct {
+		Rows []struct {
+			Line  int       `xml:"r,attr"`
+			Cells []cellXML `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeFile(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(sheet.Rows))
+	for i, r := range sheet.Rows {
+		row := Row{Line: r.Line}
+		// Атрибут r необязателен: без него строки и ячейки идут подряд
+		if row.Line == 0 {
+			row.Line = i + 1
+		}
+
+		for j, cell := range r.Cells {
+			column := j
+			if cell.Ref != "" {
+				var err error
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if column >= maxColumns {
+				return nil, fmt.Errorf("row %d: too many columns, at most %d are supported", row.Line, maxColumns)
+			}
+
+			value, err := cellValue(cell, shared)
+			if err != nil {
+				return nil, fmt.Errorf("cell %s: %w", cell.Ref, err)
+			}
+			if value == "" {
+				continue
+			}
+			for len(row.Cells) <= column {
+				row.Cells = append(row.Cells, "")
+			}
+			row.Cells[column] = value
+		}
+
+		if len(row.Cells) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func cellValue(cell cellXML, shared []string) (string, error) {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("invalid shared string index %q", cell.Value)
+		}
+		return shared[i], nil
+	case "inlineStr":
+		return cell.Inline.String(), nil
+	case "b":
+		return strconv.FormatBool(cell.Value == "1"), nil
+	default:
+		// Числа, строковые результаты формул (str) и ошибки (e) хранятся в v как есть
+		return cell.Value, nil
+	}
+}
+
+// columnIndex возвращает индекс столбца (с 0) по ссылке на ячейку вида AB12.
+// Столбцы после XFD (maxColumns) - ошибка
+func columnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		letters++
+		if letters > maxColumnLetters {
+			return 0, fmt.Errorf("invalid cell reference %q: column is beyond XFD", ref)
+		}
+		index = index*26 + int(ch-'A'+1)
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	if index > maxColumns {
+		return 0, fmt.Errorf("invalid cell reference %q: column is beyond XFD", ref)
+	}
+	return index - 1, nil
+}
+
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s not found in workbook", name)
+	}
+	return decodeFile(f, v)
+}
+
+func decodeFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.Name, err)
+	}
+	return nil
+}
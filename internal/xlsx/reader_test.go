@@ -0,0 +1,118 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// buildWorkbook собирает минимальную книгу из переданных частей
+func buildWorkbook(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadFirstSheet(t *testing.T) {
+	workbook := buildWorkbook(t, map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+	<sheets>
+		<sheet name="Подписки" sheetId="2" r:id="rId7"/>
+		<sheet name="Прочее" sheetId="1" r:id="rId1"/>
+	</sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+	<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+	<Relationship Id="rId7" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+	<si><t>service_name</t></si>
+	<si><r><t>Yandex</t></r><r><t xml:space="preserve"> Plus</t></r></si>
+</sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet/>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+	<sheetData>
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>monthly_cost</t></is></c></row>
+		<row r="2"/>
+		<row r="4"><c r="A4" t="s"><v>1</v></c><c r="B4"><v>400</v></c><c r="D4" t="b"><v>1</v></c></row>
+	</sheetData>
+</worksheet>`,
+	})
+
+	rows, err := ReadFirstSheet(workbook, int64(workbook.Len()))
+	if err != nil {
+		t.Fatalf("ReadFirstSheet() error = %v", err)
+	}
+
+	want := []Row{
+		{Line: 1, Cells: []string{"service_name", "monthly_cost"}},
+		{Line: 4, Cells: []string{"Yandex Plus", "400", "", "true"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ReadFirstSheet() = %+v, want %+v", rows, want)
+	}
+}
+
+func TestReadFirstSheetErrors(t *testing.T) {
+	t.Run("not a zip", func(t *testing.T) {
+		r := bytes.NewReader([]byte("service_name,monthly_cost\n"))
+		if _, err := ReadFirstSheet(r, int64(r.Len())); err == nil {
+			t.Error("expected error for non-zip input")
+		}
+	})
+
+	// Ячейка за пределами листа отклоняется, а не растягивает строку на сотни миллионов ячеек
+	t.Run("column beyond XFD", func(t *testing.T) {
+		workbook := buildWorkbook(t, map[string]string{
+			"xl/workbook.xml":            `<workbook><sheets><sheet r:id="rId1" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row r="1"><c r="AAAAAAA1" t="inlineStr"><is><t>x</t></is></c></row></sheetData></worksheet>`,
+		})
+		if _, err := ReadFirstSheet(workbook, int64(workbook.Len())); err == nil {
+			t.Error("expected error for column beyond XFD")
+		}
+	})
+
+	t.Run("bad shared string index", func(t *testing.T) {
+		workbook := buildWorkbook(t, map[string]string{
+			"xl/workbook.xml":            `<workbook><sheets><sheet r:id="rId1" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>3</v></c></row></sheetData></worksheet>`,
+		})
+		if _, err := ReadFirstSheet(workbook, int64(workbook.Len())); err == nil {
+			t.Error("expected error for missing shared string")
+		}
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB1": 27, "XFD1": 16383} {
+		if got, err := columnIndex(ref); err != nil || got != want {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"12", "XFE1", "AAAA1", "AAAAAAA1", "ZZZZZZZZZZZZZZZ1"} {
+		if got, err := columnIndex(ref); err == nil {
+			t.Errorf("columnIndex(%q) = %d, want error", ref, got)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+-- Черновики подписок: не участвуют в подсчете стоимости до активации
+ALTER TABLE subscriptions ADD COLUMN is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_subscriptions_is_draft ON subscriptions(is_draft) WHERE is_draft;
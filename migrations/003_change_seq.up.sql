@@ -0,0 +1,23 @@
+-- Монотонный номер изменения для потребителей CDC и polling API
+CREATE SEQUENCE subscriptions_change_seq;
+
+ALTER TABLE subscriptions ADD COLUMN change_seq BIGINT NOT NULL DEFAULT nextval('subscriptions_change_seq');
+
+CREATE INDEX idx_subscriptions_change_seq ON subscriptions(change_seq);
+
+-- Каждое изменение строки получает новый номер
+CREATE OR REPLACE FUNCTION set_change_seq()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.change_seq = nextval('subscriptions_change_seq');
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER set_subscriptions_change_seq
+    BEFORE INSERT OR UPDATE ON subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION set_change_seq();
+
+-- Полный образ строки в WAL, чтобы Debezium получал старые значения при UPDATE/DELETE
+ALTER TABLE subscriptions REPLICA IDENTITY FULL;
@@ -0,0 +1,35 @@
+-- Журнал изменений подписок для polling API: каждая операция записывается с полным образом строки
+CREATE TABLE subscription_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    operation VARCHAR(16) NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    payload JSONB NOT NULL,
+    previous JSONB NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_changes_subscription_id ON subscription_changes(subscription_id);
+
+CREATE OR REPLACE FUNCTION log_subscription_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO subscription_changes (subscription_id, operation, payload)
+        VALUES (NEW.id, 'create', to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO subscription_changes (subscription_id, operation, payload, previous)
+        VALUES (NEW.id, 'update', to_jsonb(NEW), to_jsonb(OLD));
+        RETURN NEW;
+    ELSE
+        INSERT INTO subscription_changes (subscription_id, operation, payload)
+        VALUES (OLD.id, 'delete', to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER log_subscriptions_change
+    AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION log_subscription_change();
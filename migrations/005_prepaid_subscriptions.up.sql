@@ -0,0 +1,2 @@
+-- Годовая предоплата: сумма распределяется равномерно по 12 месяцам
+ALTER TABLE subscriptions ADD COLUMN prepaid_amount INTEGER NULL CHECK (prepaid_amount > 0);
@@ -0,0 +1,10 @@
+-- Шаблоны писем с версионированием: каждое сохранение создает новую версию
+CREATE TABLE email_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (name, version)
+);
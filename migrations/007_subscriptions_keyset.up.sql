@@ -0,0 +1,5 @@
+-- Выгрузки идут страницами по (created_at, id): нужен индекс и непустой created_at
+UPDATE subscriptions SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE subscriptions ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_subscriptions_created_at_id ON subscriptions(created_at, id);
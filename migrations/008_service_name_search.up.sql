@@ -0,0 +1,12 @@
+-- Поиск по названию сервиса без учета регистра и диакритики
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() помечена как STABLE, для индекса нужна IMMUTABLE-обертка с явным словарем
+CREATE OR REPLACE FUNCTION immutable_unaccent(text)
+RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE INDEX idx_subscriptions_service_name_trgm
+    ON subscriptions USING gin (lower(immutable_unaccent(service_name)) gin_trgm_ops);
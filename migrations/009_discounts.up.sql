@@ -0,0 +1,19 @@
+-- Скидки: процент или фиксированная сумма в месяц на подписку или на все подписки пользователя
+CREATE TABLE discounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value INTEGER NOT NULL CHECK (value > 0),
+    subscription_id UUID NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NULL,
+    promo_code VARCHAR(50) NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- Скидка относится либо к одной подписке, либо ко всем подпискам пользователя
+    CHECK ((subscription_id IS NULL) <> (user_id IS NULL)),
+    CHECK (kind <> 'percent' OR value <= 100),
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_discounts_subscription_id ON discounts(subscription_id);
+CREATE INDEX idx_discounts_user_id ON discounts(user_id);
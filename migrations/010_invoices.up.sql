@@ -0,0 +1,29 @@
+-- Счета пользователей за месяц. Строки счета хранят рассчитанные суммы,
+-- поэтому последующие изменения подписок, скидок и ставки налога не меняют выставленный счет
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    period DATE NOT NULL,
+    tax_rate NUMERIC(5, 2) NOT NULL,
+    base_total INTEGER NOT NULL,
+    discount_total INTEGER NOT NULL,
+    net_total INTEGER NOT NULL,
+    tax_total INTEGER NOT NULL,
+    gross_total INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, period)
+);
+
+-- subscription_id без внешнего ключа: счет должен пережить удаление подписки
+CREATE TABLE invoice_lines (
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    subscription_id UUID NOT NULL,
+    service_name VARCHAR(255) NOT NULL,
+    base_amount INTEGER NOT NULL,
+    discount_amount INTEGER NOT NULL,
+    net_amount INTEGER NOT NULL,
+    tax_amount INTEGER NOT NULL,
+    gross_amount INTEGER NOT NULL,
+    PRIMARY KEY (invoice_id, line_no)
+);
@@ -0,0 +1,17 @@
+-- Состояние подписки. expired не хранится: это активная подписка с прошедшим end_date
+ALTER TABLE subscriptions
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'paused', 'cancelled'));
+
+CREATE INDEX idx_subscriptions_status ON subscriptions(status);
+
+-- Месяцы приостановки: в расчетах стоимости не учитываются. Открытая пауза имеет end_date NULL
+CREATE TABLE subscription_pauses (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_id ON subscription_pauses(subscription_id);
@@ -0,0 +1,4 @@
+-- Причина и момент отмены подписки для отчетности
+ALTER TABLE subscriptions
+    ADD COLUMN cancel_reason VARCHAR(500) NULL,
+    ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE NULL;
@@ -0,0 +1,40 @@
+-- История передачи подписок между пользователями. Хранится и после удаления подписки
+CREATE TABLE subscription_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    from_user_id UUID NOT NULL,
+    to_user_id UUID NOT NULL,
+    reason VARCHAR(500) NULL,
+    transferred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_transfers_subscription_id ON subscription_transfers(subscription_id);
+
+-- Смена владельца попадает в журнал изменений отдельной операцией transfer
+ALTER TABLE subscription_changes DROP CONSTRAINT subscription_changes_operation_check;
+ALTER TABLE subscription_changes ADD CONSTRAINT subscription_changes_operation_check
+    CHECK (operation IN ('create', 'update', 'delete', 'transfer'));
+
+CREATE OR REPLACE FUNCTION log_subscription_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO subscription_changes (subscription_id, operation, payload)
+        VALUES (NEW.id, 'create', to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO subscription_changes (subscription_id, operation, payload, previous)
+        VALUES (
+            NEW.id,
+            CASE WHEN NEW.user_id <> OLD.user_id THEN 'transfer' ELSE 'update' END,
+            to_jsonb(NEW),
+            to_jsonb(OLD)
+        );
+        RETURN NEW;
+    ELSE
+        INSERT INTO subscription_changes (subscription_id, operation, payload)
+        VALUES (OLD.id, 'delete', to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,47 @@
+-- Организации (tenant) одной установки сервиса. Существующие данные относятся к организации default
+ALTER TABLE subscriptions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE discounts ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE invoices ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_changes ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Все выборки ограничены организацией, поэтому она идет первой колонкой индексов
+DROP INDEX idx_subscriptions_user_id;
+DROP INDEX idx_subscriptions_user_service;
+DROP INDEX idx_subscriptions_created_at_id;
+CREATE INDEX idx_subscriptions_tenant_user ON subscriptions(tenant_id, user_id, service_name);
+CREATE INDEX idx_subscriptions_tenant_created_at_id ON subscriptions(tenant_id, created_at, id);
+
+DROP INDEX idx_discounts_user_id;
+CREATE INDEX idx_discounts_tenant_user ON discounts(tenant_id, user_id);
+
+CREATE INDEX idx_subscription_changes_tenant_seq ON subscription_changes(tenant_id, seq);
+
+-- Счет выставляется пользователю организации за месяц один раз
+ALTER TABLE invoices DROP CONSTRAINT invoices_user_id_period_key;
+ALTER TABLE invoices ADD CONSTRAINT invoices_tenant_user_period_key UNIQUE (tenant_id, user_id, period);
+
+-- Журнал изменений наследует организацию подписки
+CREATE OR REPLACE FUNCTION log_subscription_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload)
+        VALUES (NEW.id, NEW.tenant_id, 'create', to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload, previous)
+        VALUES (
+            NEW.id,
+            NEW.tenant_id,
+            CASE WHEN NEW.user_id <> OLD.user_id THEN 'transfer' ELSE 'update' END,
+            to_jsonb(NEW),
+            to_jsonb(OLD)
+        );
+        RETURN NEW;
+    ELSE
+        INSERT INTO subscription_changes (subscription_id, tenant_id, operation, payload)
+        VALUES (OLD.id, OLD.tenant_id, 'delete', to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,16 @@
+-- Отклоненные запросы на запись: поддержка видит, почему создание или изменение не прошло,
+-- не разбирая логи. Записи старше REJECTED_REQUESTS_RETENTION_DAYS удаляет фоновая задача
+CREATE TABLE rejected_requests (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    user_id UUID,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status INTEGER NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rejected_requests_tenant_created_at ON rejected_requests(tenant_id, created_at DESC, id DESC);
+CREATE INDEX idx_rejected_requests_tenant_user ON rejected_requests(tenant_id, user_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Бесплатные подписки (бесплатные тарифы) хранятся с нулевой monthly_cost
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_monthly_cost_check;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_monthly_cost_check CHECK (monthly_cost >= 0);
@@ -0,0 +1,9 @@
+-- Отправленные напоминания о продлении: фоновая задача не повторяет напоминание
+-- об одном и том же окончании периода подписки
+CREATE TABLE renewal_reminders (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    renews_at DATE NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('expiring', 'renewal')),
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, renews_at)
+);
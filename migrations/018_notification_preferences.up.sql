@@ -0,0 +1,10 @@
+-- Настройки уведомлений пользователя: адрес и виды писем. Пользователь без настроек писем не получает
+CREATE TABLE notification_preferences (
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    email VARCHAR(254) NOT NULL,
+    renewal_reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    cancellations BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, user_id)
+);
@@ -0,0 +1,48 @@
+-- Журнал аудита изменений подписок: образы строки до и после, пользователь и ID запроса.
+-- Пользователя и ID запроса передает репозиторий через set_config в транзакции изменения;
+-- изменения вне API (миграции, ручные запросы) записываются без них
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id UUID NOT NULL,
+    -- user_id - владелец подписки после изменения (для удаления - до него)
+    user_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    actor VARCHAR(128) NULL,
+    request_id VARCHAR(128) NULL,
+    before JSONB NULL,
+    after JSONB NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_tenant_entity ON audit_log(tenant_id, entity_id, id);
+CREATE INDEX idx_audit_log_tenant_id ON audit_log(tenant_id, id);
+CREATE INDEX idx_audit_log_tenant_user ON audit_log(tenant_id, user_id, id);
+
+CREATE OR REPLACE FUNCTION log_subscription_audit()
+RETURNS TRIGGER AS $$
+DECLARE
+    audit_actor TEXT := NULLIF(current_setting('audit.actor', true), '');
+    audit_request_id TEXT := NULLIF(current_setting('audit.request_id', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO audit_log (tenant_id, entity_type, entity_id, user_id, action, actor, request_id, after)
+        VALUES (NEW.tenant_id, 'subscription', NEW.id, NEW.user_id, 'create', audit_actor, audit_request_id, to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO audit_log (tenant_id, entity_type, entity_id, user_id, action, actor, request_id, before, after)
+        VALUES (NEW.tenant_id, 'subscription', NEW.id, NEW.user_id, 'update', audit_actor, audit_request_id, to_jsonb(OLD), to_jsonb(NEW));
+        RETURN NEW;
+    ELSE
+        INSERT INTO audit_log (tenant_id, entity_type, entity_id, user_id, action, actor, request_id, before)
+        VALUES (OLD.tenant_id, 'subscription', OLD.id, OLD.user_id, 'delete', audit_actor, audit_request_id, to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER log_subscriptions_audit
+    AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION log_subscription_audit();
@@ -0,0 +1,22 @@
+-- Ключи идемпотентности POST- и PATCH-запросов, общие для всех реплик: повтор запроса
+-- с тем же Idempotency-Key за балансировщиком получает сохраненный ответ вместо повторного
+-- выполнения. Ключ принадлежит автору запроса; записи после expires_at удаляет фоновая задача
+CREATE TABLE idempotency_keys (
+    tenant_id VARCHAR(64) NOT NULL,
+    actor VARCHAR(128) NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255) NOT NULL,
+    -- fingerprint - SHA-256 метода, пути и тела запроса
+    fingerprint CHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('processing', 'completed')),
+    response_status INTEGER NULL,
+    content_type VARCHAR(255) NULL,
+    response_body BYTEA NULL,
+    -- locked_until - до какого момента запрос считается выполняющимся; после него ключ
+    -- можно занять повторно, если реплика, выполнявшая запрос, упала
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (tenant_id, actor, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
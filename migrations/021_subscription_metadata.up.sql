@@ -0,0 +1,7 @@
+-- Метки подписок организации (проект, отдел), по которым группируются итоги:
+-- GET /subscriptions/summary?group_by=metadata.<key>. GIN-индекс с jsonb_ops обслуживает
+-- проверки наличия ключа (?) и вхождения (@>) по меткам
+ALTER TABLE subscriptions ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_metadata_check CHECK (jsonb_typeof(metadata) = 'object');
+
+CREATE INDEX idx_subscriptions_metadata ON subscriptions USING GIN (metadata);
@@ -0,0 +1,19 @@
+-- Входящие уведомления пользователя для центра уведомлений в интерфейсе: скорое окончание
+-- подписки, повышение цены и аномальные траты. dedup_key не дает фоновым задачам записать
+-- одно и то же уведомление повторно
+CREATE TABLE user_notifications (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('expiring_soon', 'price_increase', 'spend_anomaly')),
+    subscription_id UUID NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    dedup_key VARCHAR(255) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX idx_user_notifications_tenant_user ON user_notifications(tenant_id, user_id, created_at DESC);
+CREATE INDEX idx_user_notifications_unread ON user_notifications(tenant_id, user_id) WHERE read_at IS NULL;
+CREATE UNIQUE INDEX idx_user_notifications_dedup ON user_notifications(tenant_id, user_id, dedup_key) WHERE dedup_key IS NOT NULL;
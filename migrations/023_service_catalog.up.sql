@@ -0,0 +1,21 @@
+-- Каталог известных сервисов организации: каноническое название, категория, стоимость
+-- по умолчанию и иконка. Подписка может ссылаться на запись каталога (service_id), чтобы
+-- фильтры и отчеты не зависели от написания service_name
+CREATE TABLE services (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(64) NULL,
+    default_monthly_cost INTEGER NULL CHECK (default_monthly_cost >= 0),
+    icon_url VARCHAR(2048) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Название уникально в организации без учета регистра
+CREATE UNIQUE INDEX idx_services_tenant_name ON services(tenant_id, lower(name));
+CREATE INDEX idx_services_tenant_category ON services(tenant_id, category);
+
+-- Удаление записи каталога оставляет подписки без ссылки
+ALTER TABLE subscriptions ADD COLUMN service_id UUID NULL REFERENCES services(id) ON DELETE SET NULL;
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(tenant_id, service_id);
@@ -0,0 +1,18 @@
+-- Теги подписок ("work", "entertainment") для группировки трат. Название тега хранится
+-- в нижнем регистре и уникально в организации; подписка может иметь несколько тегов
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE subscription_tags (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+-- Фильтр ?tag= ищет подписки по тегу
+CREATE INDEX idx_subscription_tags_tag_id ON subscription_tags(tag_id);
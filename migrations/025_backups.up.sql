@@ -0,0 +1,20 @@
+-- Журнал логических резервных копий (server -backup): где лежит зашифрованная копия,
+-- на какой момент и позицию WAL она согласована, сколько строк каждой таблицы в ней
+-- и когда ее удалит политика хранения
+CREATE TABLE backups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    blob_key VARCHAR(512) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed', 'expired')),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE NULL,
+    snapshot_at TIMESTAMP WITH TIME ZONE NULL,
+    wal_lsn VARCHAR(32) NULL,
+    table_rows JSONB NULL,
+    size_bytes BIGINT NULL,
+    sha256 CHAR(64) NULL,
+    error TEXT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX idx_backups_started_at ON backups(started_at DESC);
+CREATE INDEX idx_backups_expires_at ON backups(expires_at) WHERE status = 'completed';
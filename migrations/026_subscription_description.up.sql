@@ -0,0 +1,2 @@
+-- Произвольное описание подписки (заметки пользователя: тариф, кто оплачивает, где отменить)
+ALTER TABLE subscriptions ADD COLUMN description TEXT NULL;
@@ -0,0 +1,10 @@
+-- Период оплаты подписки и стоимость за этот период. monthly_cost остается округленной
+-- стоимостью в месяц, точные итоги переводят cost в месяц: годовая делится на 12,
+-- недельная умножается на 52/12. У помесячных подписок cost не хранится и равен monthly_cost
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+    CHECK (billing_period IN ('weekly', 'monthly', 'yearly'));
+
+ALTER TABLE subscriptions
+    ADD COLUMN cost INTEGER NULL CHECK (cost > 0),
+    ADD CONSTRAINT subscriptions_billing_period_cost_check CHECK ((billing_period = 'monthly') = (cost IS NULL));
@@ -0,0 +1,9 @@
+-- Настройки напоминаний о продлении отдельной подписки: срок и канал вместо значений
+-- RENEWAL_REMINDER_DAYS и RENEWAL_REMINDER_CHANNELS. NULL - значение по умолчанию
+CREATE TABLE subscription_reminder_settings (
+    subscription_id UUID PRIMARY KEY REFERENCES subscriptions(id) ON DELETE CASCADE,
+    days INTEGER NULL CHECK (days BETWEEN 1 AND 365),
+    channel VARCHAR(16) NULL CHECK (channel IN ('log', 'webhook', 'email', 'inbox')),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (days IS NOT NULL OR channel IS NOT NULL)
+);
@@ -0,0 +1,24 @@
+-- Входящие события интеграций (вебхуки, потребители очередей): повторно доставленное
+-- событие с тем же идентификатором источника не применяется второй раз. Записи
+-- обработанных событий старше INBOUND_EVENTS_RETENTION_DAYS удаляет фоновая задача
+CREATE TABLE inbound_events (
+    tenant_id VARCHAR(64) NOT NULL,
+    source VARCHAR(64) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('processing', 'processed')),
+    -- locked_until - до какого момента событие считается обрабатываемым; после него
+    -- повтор обрабатывается заново, если реплика упала, не завершив обработку
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    subscription_id UUID NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE NULL,
+    -- duplicates - сколько повторов обработанного события отброшено
+    duplicates INTEGER NOT NULL DEFAULT 0,
+    last_duplicate_at TIMESTAMP WITH TIME ZONE NULL,
+    PRIMARY KEY (tenant_id, source, event_id)
+);
+
+CREATE INDEX idx_inbound_events_processed_at ON inbound_events(processed_at);
+CREATE INDEX idx_inbound_events_last_duplicate ON inbound_events(tenant_id, last_duplicate_at DESC)
+    WHERE last_duplicate_at IS NOT NULL;
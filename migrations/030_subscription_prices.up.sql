@@ -0,0 +1,14 @@
+-- История стоимости подписок: запись действует с месяца effective_from до следующей записи.
+-- subscriptions.monthly_cost, billing_period и cost хранят последнюю стоимость; подписка
+-- без записей во всех месяцах считается по ней. Первая запись появляется при первом
+-- изменении стоимости и хранит прежнюю стоимость с месяца начала подписки
+CREATE TABLE subscription_prices (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL,
+    monthly_cost INTEGER NOT NULL CHECK (monthly_cost >= 0),
+    billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly' CHECK (billing_period IN ('weekly', 'monthly', 'yearly')),
+    cost INTEGER NULL CHECK (cost > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, effective_from),
+    CHECK ((billing_period = 'monthly') = (cost IS NULL))
+);
@@ -0,0 +1,15 @@
+-- Личные токены API с ограниченными правами (scopes) для интеграций: плагинов таблиц,
+-- дашбордов. Хранится только SHA-256 токена: сам токен показывается один раз при выпуске
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- expires_at - после этого момента токен не принимается; NULL - бессрочный токен
+    expires_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX idx_api_tokens_tenant_user ON api_tokens(tenant_id, user_id, created_at DESC);
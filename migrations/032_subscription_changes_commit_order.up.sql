@@ -0,0 +1,22 @@
+-- Номера журнала изменений в порядке фиксации транзакций. BIGSERIAL выдает seq при вставке,
+-- поэтому долгая транзакция могла зафиксировать меньший номер уже после того, как клиент
+-- polling API прочитал больший, и клиент это изменение пропускал.
+-- Отложенный триггер перенумеровывает строки транзакции при фиксации под advisory-блокировкой,
+-- которая снимается после того, как транзакция стала видна: следующая транзакция получает
+-- номера только после этого, и видимые номера всегда образуют продолжение прочитанных
+CREATE OR REPLACE FUNCTION renumber_subscription_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('subscription_changes'), 0);
+    UPDATE subscription_changes
+    SET seq = nextval(pg_get_serial_sequence('subscription_changes', 'seq'))
+    WHERE seq = NEW.seq;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE CONSTRAINT TRIGGER renumber_subscription_changes
+    AFTER INSERT ON subscription_changes
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    EXECUTE FUNCTION renumber_subscription_change();
@@ -0,0 +1,11 @@
+-- Сопоставления групп SSO (claim OIDC_GROUPS_CLAIM токена провайдера) организациям и ролям:
+-- участники группы получают доступ к организации без отдельного claim организации.
+-- tenant_id NULL - роль admin во всех организациях
+CREATE TABLE sso_group_mappings (
+    group_name VARCHAR(255) PRIMARY KEY,
+    tenant_id VARCHAR(64) NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('member', 'admin')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (tenant_id IS NOT NULL OR role = 'admin')
+);
@@ -0,0 +1,9 @@
+-- Организации, заведенные через PUT /admin/tenants/{tenant}. Данные организаций по-прежнему
+-- ссылаются на tenant_id без внешнего ключа: default и организации, выбранные заголовком
+-- без регистрации, продолжают работать
+CREATE TABLE tenants (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
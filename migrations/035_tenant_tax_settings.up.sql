@@ -0,0 +1,5 @@
+-- Налоговая ставка и округление организации. NULL - значение из конфигурации
+-- (TAX_RATE_PERCENT, ROUNDING_MODE)
+ALTER TABLE tenants
+    ADD COLUMN tax_rate_percent NUMERIC(7, 4) NULL CHECK (tax_rate_percent >= 0),
+    ADD COLUMN rounding_mode VARCHAR(16) NULL CHECK (rounding_mode IN ('half_up', 'half_even'));
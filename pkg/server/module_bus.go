@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Zipklas/subscription-service/internal/lifecycle"
+	"github.com/Zipklas/subscription-service/internal/webhook"
+)
+
+// busModule - рассылка событий подписчикам вебхуков. Без WEBHOOK_URLS dispatcher
+// равен nil, и события никуда не отправляются
+type busModule struct {
+	webhooks *webhook.Dispatcher
+}
+
+// newBusModule создает очереди доставки; при остановке модуль дожидается отправки
+// поставленных событий, но не дольше контекста остановки
+func newBusModule(lc *lifecycle.Lifecycle, core *core) *busModule {
+	cfg, log := core.cfg, core.log
+	m := &busModule{}
+
+	// У каждого адреса своя очередь и выключатель
+	if len(cfg.WebhookURLs) > 0 {
+		m.webhooks = webhook.NewDispatcher(cfg.WebhookURLs, webhook.Config{
+			Workers:          cfg.WebhookWorkers,
+			PerEndpoint:      cfg.WebhookPerEndpoint,
+			QueueSize:        cfg.WebhookQueueSize,
+			Timeout:          cfg.WebhookTimeout,
+			FailureThreshold: cfg.WebhookFailureThreshold,
+			Cooldown:         cfg.WebhookCooldown,
+			Client:           core.egress.Client(cfg.WebhookTimeout),
+		}, func(url string, err error) {
+			log.Warn(context.Background(), "Failed to deliver webhook", "url", url, "error", err)
+		})
+		log.Info(context.Background(), "Webhooks enabled", "endpoints", len(cfg.WebhookURLs))
+	}
+
+	lc.Append(lifecycle.Hook{
+		Name: "webhooks",
+		OnStop: func(ctx context.Context) error {
+			// Недоставленные события не мешают остановке остальных модулей
+			if err := m.webhooks.Close(ctx); err != nil {
+				log.Warn(ctx, "Webhook queues were not drained", "error", err)
+			}
+			return nil
+		},
+	})
+	return m
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/egress"
+	"github.com/Zipklas/subscription-service/internal/i18n"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/money"
+	"github.com/Zipklas/subscription-service/internal/notifier"
+	"github.com/Zipklas/subscription-service/internal/tracing"
+)
+
+// core - конфигурация и общие для всех модулей зависимости
+type core struct {
+	cfg          *config.Config
+	log          *logger.Logger
+	pod          model.PodMetadata
+	tax          money.Tax
+	reportLocale i18n.Locale
+	// egress создает клиенты всех исходящих запросов с прокси и списком разрешенных хостов
+	egress *egress.Factory
+}
+
+// newCore читает конфигурацию, создает логгер и применяет настройки процесса:
+// часовой пояс периодов и экспорт трассировки
+func newCore(o *options) (*core, error) {
+	cfg, err := config.LoadFile(o.configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, configure := range o.configure {
+		configure(cfg)
+	}
+
+	// Логгер; в Kubernetes каждая запись содержит под, namespace и узел
+	pod := model.PodMetadata{Name: cfg.PodName, Namespace: cfg.PodNamespace, Node: cfg.NodeName}
+	log := o.logger
+	if log == nil {
+		log = logger.New(cfg.LogLevel)
+	}
+	log = log.With(pod.LogAttrs()...)
+	log.Info(context.Background(), "Starting subscription service",
+		"port", cfg.AppPort,
+		"log_level", cfg.LogLevel.String(),
+		"config_file", o.configFile,
+	)
+
+	// Налоговая политика для отчетов
+	tax, err := money.NewTax(cfg.TaxRatePercent, cfg.PricesIncludeTax, cfg.RoundingMode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tax configuration: %w", err)
+	}
+
+	// Часовой пояс, в котором считаются месяцы периодов
+	periodLocation, err := time.LoadLocation(cfg.PeriodTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period timezone %q: %w", cfg.PeriodTimezone, err)
+	}
+	model.SetPeriodLocation(periodLocation)
+
+	// Язык подписей в отчетах по умолчанию
+	reportLocale, err := i18n.Parse(cfg.ReportLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report locale: %w", err)
+	}
+
+	// Исходящие запросы; адреса из конфигурации проверяются сразу, а не при первой отправке
+	egressFactory, err := egress.New(egress.Config{
+		ProxyURL:     cfg.OutboundProxyURL,
+		DialTimeout:  cfg.OutboundDialTimeout,
+		AllowedHosts: cfg.EgressAllowedHosts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid egress configuration: %w", err)
+	}
+	if err := checkEgress(egressFactory, cfg); err != nil {
+		return nil, err
+	}
+
+	// Трассировка запросов в коллектор OpenTelemetry
+	if cfg.OTLPEndpoint != "" {
+		tracing.SetExporter(tracing.NewOTLPExporter(cfg.OTLPEndpoint, cfg.TraceServiceName, cfg.TraceExportInterval, egressFactory.Client(10*time.Second), func(err error) {
+			log.Warn(context.Background(), "Failed to export spans", "error", err)
+		}))
+		log.Info(context.Background(), "Tracing enabled", "otlp_endpoint", cfg.OTLPEndpoint)
+	}
+
+	return &core{
+		cfg:          cfg,
+		log:          log,
+		pod:          pod,
+		tax:          tax,
+		reportLocale: reportLocale,
+		egress:       egressFactory,
+	}, nil
+}
+
+// checkEgress проверяет, что адреса вебхуков, OIDC, коллектора трасс, сайдкаров
+// модификаторов и почтового сервера входят в EGRESS_ALLOWED_HOSTS
+func checkEgress(f *egress.Factory, cfg *config.Config) error {
+	urls := append([]string{}, cfg.WebhookURLs...)
+	if cfg.OIDCJWKSURL != "" {
+		urls = append(urls, cfg.OIDCJWKSURL)
+	}
+	if cfg.OTLPEndpoint != "" {
+		urls = append(urls, cfg.OTLPEndpoint)
+	}
+	switch cfg.EmailDriver {
+	case "smtp":
+		urls = append(urls, "smtp://"+cfg.SMTPHost)
+	case "sendgrid":
+		urls = append(urls, notifier.DefaultSendGridURL)
+	}
+	for _, spec := range cfg.SummaryModifiers {
+		if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+			urls = append(urls, spec)
+		}
+	}
+
+	for _, u := range urls {
+		if err := f.Check(u); err != nil {
+			return fmt.Errorf("invalid egress configuration: %s: %w", u, err)
+		}
+	}
+	return nil
+}
+
+// postgres сообщает, что подписки хранятся в PostgreSQL. При DB_DRIVER=sqlite и memory
+// пул остается без подключения, а остальные данные в базе недоступны
+func (c *core) postgres() bool {
+	return c.cfg.DBDriver == "postgres"
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/lifecycle"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+)
+
+// databaseModule - пул соединений с Postgres, проверка расхождения схемы
+// и метрики запросов репозиториев
+type databaseModule struct {
+	pool  *database.Pool
+	drift *database.DriftDetector
+	// upkeep - статистика таблиц и индексов и ANALYZE для административного API
+	upkeep  *database.Maintenance
+	queries *metrics.Queries
+}
+
+// newDatabaseModule создает пул без подключения; подключение выполняется при запуске,
+// закрытие пула - при остановке. Пустой dsn собирается из DB_*
+func newDatabaseModule(lc *lifecycle.Lifecycle, core *core, dsn string) (*databaseModule, error) {
+	cfg := core.cfg
+	if dsn == "" {
+		dsn = cfg.GetDBConnectionString()
+	}
+	pool, err := database.New(dsn, database.Settings{
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		ConnMaxLifetime:  cfg.DBConnMaxLifetime,
+		StatementTimeout: cfg.DBStatementTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	m := &databaseModule{
+		pool: pool,
+		// Расхождение схемы с ожидаемой ищем до первых запросов, а не по ошибкам сканирования
+		drift:   database.NewDriftDetector(pool.DB),
+		upkeep:  database.NewMaintenance(pool.DB),
+		queries: metrics.NewQueries(),
+	}
+	lc.Append(lifecycle.Hook{
+		Name:    "database",
+		OnStart: func(ctx context.Context) error { return m.connect(ctx, core) },
+		OnStop:  func(context.Context) error { return pool.Close() },
+	})
+	return m, nil
+}
+
+// connect дожидается подключения к базе, проверяет фазы изменений схемы и запускает
+// проверку расхождения схемы. В ленивом режиме подключение и проверки выполняются в фоне
+func (m *databaseModule) connect(ctx context.Context, core *core) error {
+	cfg, log := core.cfg, core.log
+
+	// Подписки хранятся в SQLite или в памяти процесса; пул остается без подключения,
+	// и остальные данные в базе (скидки, счета, шаблоны, аналитика) недоступны
+	const unavailable = "discounts, service catalog, tags, invoices, templates, analytics, rejected requests, tenant provisioning and teardown, renewal reminders, notification preferences, audit log, idempotency keys, backups, inbound events, personal API tokens, SSO group mappings, admin database API"
+	switch cfg.DBDriver {
+	case "memory":
+		log.Warn(ctx, "Using in-memory subscription storage, data is lost on restart",
+			"unavailable", unavailable,
+		)
+		return nil
+	case "sqlite":
+		log.Warn(ctx, "Using SQLite subscription storage",
+			"path", cfg.SQLitePath,
+			"unavailable", unavailable,
+		)
+		return nil
+	}
+
+	backoff := database.Backoff{
+		Initial: cfg.DBConnectRetryInitial,
+		Max:     cfg.DBConnectRetryMax,
+		MaxWait: cfg.DBConnectMaxWait,
+	}
+	onRetry := func(attempt int, delay time.Duration, err error) {
+		log.Warn(context.Background(), "Database is not ready, retrying",
+			"attempt", attempt,
+			"retry_in", delay.String(),
+			"error", err,
+		)
+	}
+
+	// В ленивом режиме сервис стартует сразу: запросы к базе завершаются ошибкой,
+	// пока подключение не установится, а /health отвечает 503
+	if cfg.DBLazyConnect {
+		backoff.MaxWait = 0
+		go func() {
+			if err := m.pool.WaitReady(context.Background(), backoff, onRetry); err != nil {
+				log.Error(context.Background(), "Failed to connect to database", "error", err)
+				return
+			}
+			log.Info(context.Background(), "Connected to database successfully")
+			// Сервис уже принимает запросы; несовместимую схему не пропустит проба готовности
+			if err := database.CheckPhases(context.Background(), m.pool.DB); err != nil {
+				log.Error(context.Background(), "Database schema phase is not supported", "error", err)
+			}
+			reportSchemaDrift(m.drift, log)
+		}()
+		log.Warn(ctx, "Starting without database connection, connecting in background")
+		return nil
+	}
+
+	if err := m.pool.WaitReady(ctx, backoff, onRetry); err != nil {
+		return err
+	}
+
+	log.Info(ctx, "Connected to database successfully")
+	log.Debug(ctx, "Database connection pool configured")
+
+	// Сборка не стартует на схеме, которую не умеет читать: при blue/green выкате
+	// это останавливает новую версию до применения expand, а старую - после contract
+	if err := database.CheckPhases(ctx, m.pool.DB); err != nil {
+		return err
+	}
+	go reportSchemaDrift(m.drift, log)
+	return nil
+}
+
+// schemaDriftTimeout ограничивает проверку схемы при старте
+const schemaDriftTimeout = 10 * time.Second
+
+// reportSchemaDrift сравнивает схему подключенной базы с ожидаемой и пишет в лог
+// отсутствующие таблицы, колонки и индексы
+func reportSchemaDrift(drift *database.DriftDetector, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaDriftTimeout)
+	defer cancel()
+
+	result, err := drift.Detect(ctx)
+	if err != nil {
+		log.Error(ctx, "Failed to check database schema drift", "error", err)
+		return
+	}
+	if result.Drifted {
+		log.Warn(ctx, "Database schema drift detected",
+			"missing_tables", result.MissingTables,
+			"missing_columns", result.MissingColumns,
+			"missing_indexes", result.MissingIndexes,
+		)
+		return
+	}
+	log.Info(ctx, "Database schema matches expected state")
+}
@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/auth"
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/eventschema"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/logger"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/usage"
+
+	"github.com/gin-gonic/gin"
+
+	// Swagger
+	_ "github.com/Zipklas/subscription-service/docs"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// httpModule - обработчики, middleware и роутер сервиса
+type httpModule struct {
+	router *gin.Engine
+}
+
+// newHTTPModule собирает обработчики поверх сервисов и настраивает маршруты
+func newHTTPModule(core *core, db *databaseModule, storage *storageModule, bus *busModule, services *servicesModule, jobs *jobsModule) *httpModule {
+	cfg, log := core.cfg, core.log
+
+	subscriptionHandler := handler.NewSubscriptionHandler(services.subscriptions, cfg.AdminToken, log)
+	anomalyHandler := handler.NewAnomalyHandler(services.anomalies, log)
+	spendHandler := handler.NewSpendHandler(services.sparklines, log)
+	dataQualityHandler := handler.NewDataQualityHandler(services.dataQuality, log)
+	teamHandler := handler.NewTeamHandler(services.teams, log)
+	analyticsHandler := handler.NewAnalyticsHandler(services.analytics, log)
+	templateHandler := handler.NewTemplateHandler(services.templates, cfg.AdminToken, log)
+	discountHandler := handler.NewDiscountHandler(services.discounts, log)
+	catalogHandler := handler.NewCatalogHandler(services.catalog, log)
+	tagHandler := handler.NewTagHandler(services.tags, log)
+	invoiceHandler := handler.NewInvoiceHandler(services.invoices, log)
+	rejectionHandler := handler.NewRejectionHandler(services.rejections, log)
+	teardownHandler := handler.NewTeardownHandler(services.teardown, cfg.AdminToken, log)
+	notificationHandler := handler.NewNotificationHandler(services.notifications, log)
+	reminderHandler := handler.NewReminderHandler(services.reminders, log)
+	inboxHandler := handler.NewInboxHandler(services.inbox, log)
+	auditHandler := handler.NewAuditHandler(services.audit, cfg.AdminToken, log)
+	backupHandler := handler.NewBackupHandler(services.backups, cfg.AdminToken, log)
+	inboundHandler := handler.NewInboundHandler(services.inbound, cfg.AdminToken, log)
+	apiTokenHandler := handler.NewAPITokenHandler(services.apiTokens, log)
+	groupMappingHandler := handler.NewGroupMappingHandler(services.groups, cfg.AdminToken, log)
+	tenantHandler := handler.NewTenantHandler(services.tenants, cfg.AdminToken, log)
+	eventSchemaHandler := handler.NewEventSchemaHandler(eventschema.Default, log)
+	adminHandler := handler.NewAdminHandler(db.pool, jobs.scheduler, db.queries, bus.webhooks, db.drift, db.upkeep, cfg.AdminToken, log)
+	usageHandler := handler.NewUsageHandler(usage.NewStore(cfg.UsageRetentionDays), usage.NewLimiter(cfg.RateLimitPerMinute), log)
+
+	// Аутентификация токенами внешнего провайдера (OIDC)
+	var verifier *auth.Verifier
+	if cfg.OIDCJWKSURL != "" {
+		verifier = auth.NewVerifier(auth.OIDCConfig{
+			JWKSURL:     cfg.OIDCJWKSURL,
+			Issuer:      cfg.OIDCIssuer,
+			Audience:    cfg.OIDCAudience,
+			AdminRole:   cfg.OIDCAdminRole,
+			TenantClaim: cfg.OIDCTenantClaim,
+			GroupsClaim: cfg.OIDCGroupsClaim,
+		}, core.egress.Client(10*time.Second))
+		log.Info(context.Background(), "OIDC authentication enabled",
+			"jwks_url", cfg.OIDCJWKSURL,
+			"issuer", cfg.OIDCIssuer,
+		)
+	}
+
+	// Личные токены API и сопоставления групп SSO хранятся в PostgreSQL; при DB_DRIVER=sqlite
+	// и memory они не проверяются
+	var tokens handler.TokenAuthenticator
+	var groups handler.GroupResolver
+	if core.postgres() {
+		tokens = services.apiTokens
+		groups = services.groups
+	}
+
+	// Настраиваем роутер
+	dateFormats := metrics.NewDateFormats()
+	apiMiddleware := []gin.HandlerFunc{
+		// Журнал отклоненных запросов первым, чтобы видеть отказы остальных middleware
+		rejectionHandler.Middleware(),
+		usageHandler.Middleware(),
+		handler.Authenticate(verifier, tokens, groups, cfg.AdminToken, log),
+		handler.ResolveTenant(cfg.TenantHeader, log),
+		handler.RequireTokenScope(apiBasePath),
+		handler.ContentNegotiation(cfg.MsgpackEnabled),
+		handler.DateFormat(handler.DateFormatRollout{Tenants: cfg.DateFormatISOTenants, Percent: cfg.DateFormatISOPercent}, dateFormats),
+		handler.Localization(core.reportLocale),
+		handler.UUIDValidation(cfg.UUIDVersions),
+		handler.StrictFilters(cfg.StrictFilters),
+	}
+	// Ключи идемпотентности хранятся в PostgreSQL, общем для всех реплик
+	if core.postgres() && cfg.IdempotencyTTL > 0 {
+		apiMiddleware = append(apiMiddleware, handler.Idempotency(services.idempotency, log))
+	}
+	var checks []handler.ReadinessCheck
+	switch {
+	case core.postgres():
+		checks = []handler.ReadinessCheck{
+			{Name: "database", Check: db.pool.Ping},
+			{Name: "migrations", Check: func(ctx context.Context) error { return database.CheckSchema(ctx, db.pool.DB) }},
+			{Name: "schema_phases", Check: func(ctx context.Context) error { return database.CheckPhases(ctx, db.pool.DB) }},
+		}
+	case storage.sqlite != nil:
+		checks = []handler.ReadinessCheck{{Name: "database", Check: storage.sqlite.PingContext}}
+	}
+	probes := handler.NewHealthHandler(checks, cfg.ReadinessTimeout, core.pod, log)
+	global := globalMiddleware(log, cfg)
+	exporters := metricsHandler(db.queries, services.rejectionCounters, services.idempotencyCounters, services.inboundCounters, dateFormats, storage.coalesced, bus.webhooks, db.drift, metrics.NewPodInfo(core.pod))
+	router := setupRouter(log, global, healthCheck(db.pool, core.postgres(), core.pod), probes, exporters, apiMiddleware, subscriptionHandler, anomalyHandler, spendHandler, dataQualityHandler, teamHandler, analyticsHandler, templateHandler, discountHandler, catalogHandler, tagHandler, invoiceHandler, rejectionHandler, adminHandler, tenantHandler, teardownHandler, backupHandler, notificationHandler, reminderHandler, inboxHandler, auditHandler, eventSchemaHandler, inboundHandler, apiTokenHandler, groupMappingHandler, usageHandler)
+
+	// Описание маршрутов для GET /admin/routes
+	apiAuth := handler.RouteAuthNone
+	if verifier != nil {
+		apiAuth = handler.RouteAuthBearer
+	}
+	adminHandler.SetRoutes(router.Routes(), []handler.RouteGroup{
+		{Prefix: "/", Middlewares: global},
+		{Prefix: apiBasePath, Middlewares: apiMiddleware, Auth: apiAuth},
+	})
+
+	return &httpModule{router: router}
+}
+
+// routeRegistrar - обработчик, который умеет регистрировать свои маршруты в группе API
+type routeRegistrar interface {
+	RegisterRoutes(api gin.IRouter)
+}
+
+// setupRouter настраивает маршруты приложения
+// @Summary Health check
+// @Description Проверка работоспособности сервиса
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "status"
+// @Router /health [get]
+func setupRouter(log *logger.Logger, global []gin.HandlerFunc, health gin.HandlerFunc, probes *handler.HealthHandler, metricsExport gin.HandlerFunc, apiMiddleware []gin.HandlerFunc, handlers ...routeRegistrar) *gin.Engine {
+	// Устанавливаем режим Gin
+	if os.Getenv("APP_ENV") == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	} else {
+		gin.SetMode(gin.DebugMode)
+	}
+
+	router := gin.New()
+	// Для существующего пути с другим методом отвечаем 405 с заголовком Allow вместо 404
+	router.HandleMethodNotAllowed = true
+
+	// Middleware
+	router.Use(global...)
+
+	// Health check; для Kubernetes - раздельные liveness- и readiness-пробы
+	router.GET("/health", health)
+	probes.RegisterProbes(router)
+
+	// Метрики в формате Prometheus; правила алертов для них генерирует cmd/rulesgen
+	router.GET("/metrics", metricsExport)
+
+	// Swagger documentation
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// API routes
+	api := router.Group(apiBasePath, apiMiddleware...)
+	for _, h := range handlers {
+		h.RegisterRoutes(api)
+	}
+
+	// 404 handler
+	router.NoRoute(func(c *gin.Context) {
+		log.Warn(context.Background(), "Endpoint not found",
+			"path", c.Request.URL.Path,
+			"method", c.Request.Method,
+		)
+		handler.AbortProblem(c, http.StatusNotFound, handler.CodeNotFound,
+			"endpoint not found, use /api/v1/subscriptions for subscriptions API, documentation: /swagger/index.html")
+	})
+
+	// 405 handler, заголовок Allow со списком методов пути выставляет gin
+	router.NoMethod(func(c *gin.Context) {
+		log.Warn(c.Request.Context(), "Method not allowed",
+			"path", c.Request.URL.Path,
+			"method", c.Request.Method,
+			"allow", c.Writer.Header().Get("Allow"),
+		)
+		handler.AbortProblem(c, http.StatusMethodNotAllowed, handler.CodeMethodNotAllowed,
+			"method not allowed, allowed methods: "+c.Writer.Header().Get("Allow"))
+	})
+
+	return router
+}
+
+// apiBasePath - префикс маршрутов API
+const apiBasePath = "/api/v1"
+
+// globalMiddleware возвращает middleware всех маршрутов: трассировку, идентификатор
+// запроса, Server-Timing (SERVER_TIMING_ENABLED), логирование запросов, восстановление
+// после паники и CORS
+func globalMiddleware(log *logger.Logger, cfg *config.Config) []gin.HandlerFunc {
+	middleware := []gin.HandlerFunc{handler.Tracing(), handler.RequestID()}
+	if cfg.ServerTimingEnabled {
+		middleware = append(middleware, handler.ServerTiming(cfg.ResponseTimeBudget, log))
+	}
+	return append(middleware,
+		ginLoggerMiddleware(log), // Кастомный логгер
+		gin.Recovery(),
+		corsMiddleware(cfg.CORSAllowedOrigins),
+	)
+}
+
+// prometheusWriter - источник метрик для /metrics
+type prometheusWriter interface {
+	WritePrometheus(w io.Writer) error
+}
+
+// metricsHandler отдает метрики sources (гистограммы запросов репозиториев, счетчики
+// отклоненных запросов, доставку вебхуков) в текстовом формате Prometheus
+func metricsHandler(sources ...prometheusWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		for _, source := range sources {
+			if err := source.WritePrometheus(c.Writer); err != nil {
+				c.Error(err)
+				return
+			}
+		}
+	}
+}
+
+// healthCheck возвращает статус сервиса
+// @Summary Health check
+// @Description Проверка работоспособности сервиса. Пока не установлено первое подключение к базе (DB_LAZY_CONNECT), отвечает 503 со status=degraded
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "status"
+// @Failure 503 {object} map[string]interface{} "status"
+// @Router /health [get]
+func healthCheck(pool *database.Pool, postgres bool, pod model.PodMetadata) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		location := model.PeriodLocation()
+		currentTime := time.Now().In(location)
+
+		// Без Postgres (DB_DRIVER=sqlite или memory) сервис исправен и без подключения
+		status, code := "ok", http.StatusOK
+		if postgres && !pool.Ready() {
+			status, code = "degraded", http.StatusServiceUnavailable
+		}
+
+		body := gin.H{
+			"status":    status,
+			"database":  pool.Ready(),
+			"timestamp": currentTime.Format("2006-01-02 15:04:05"),
+			"timezone":  location.String(),
+			"service":   "subscription-service",
+			"version":   "1.0.0",
+		}
+		if !pod.IsZero() {
+			body["pod"] = pod
+		}
+		c.JSON(code, body)
+	}
+}
+
+func ginLoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// Обрабатываем запрос
+		c.Next()
+
+		// Логируем после обработки
+		duration := time.Since(start)
+
+		log.Info(c.Request.Context(), "HTTP request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration_ms", duration.Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+// corsMiddleware разрешает запросы из origins; "*" среди них разрешает любой источник.
+// Для списка источников в ответ возвращается Origin запроса, если он разрешен
+func corsMiddleware(origins []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+
+	return func(c *gin.Context) {
+		// Timing-Allow-Origin открывает Server-Timing для Resource Timing API тем же источникам
+		if allowed["*"] {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			c.Writer.Header().Set("Timing-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Add("Vary", "Origin")
+			if origin := c.GetHeader("Origin"); allowed[origin] {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Set("Timing-Allow-Origin", origin)
+			}
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Idempotency-Key, X-Date-Format")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor, Link, Server-Timing, X-Request-ID, Idempotent-Replayed, X-Date-Format")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/lifecycle"
+	"github.com/Zipklas/subscription-service/internal/scheduler"
+)
+
+// jobsModule - фоновые задачи по расписанию: поиск аномалий расходов, очистка журнала
+// отклоненных запросов, напоминания о продлении и очистка ключей идемпотентности
+type jobsModule struct {
+	scheduler *scheduler.Scheduler
+}
+
+// newJobsModule регистрирует задачи; они запускаются вместе с сервисом, если
+// не отключены WithoutBackgroundJobs, и останавливаются при его остановке
+func newJobsModule(lc *lifecycle.Lifecycle, core *core, db *databaseModule, services *servicesModule, enabled bool) (*jobsModule, error) {
+	cfg := core.cfg
+	jobs := scheduler.New(core.log)
+	// Задачи Exclusive при нескольких репликах выполняет одна, взявшая advisory lock
+	if core.postgres() {
+		jobs.SetLocker(database.NewAdvisoryLocker(db.pool))
+	}
+
+	for _, job := range []scheduler.Job{
+		{
+			Name:     "anomaly_detection",
+			Schedule: cfg.AnomalyDetectionJob.Schedule,
+			Enabled:  cfg.AnomalyDetectionJob.Enabled,
+			Jitter:   cfg.AnomalyDetectionJob.Jitter,
+			Run:      services.anomalies.RunDetection,
+		},
+		{
+			Name:     "rejected_requests_purge",
+			Schedule: cfg.RejectedRequestsPurgeJob.Schedule,
+			Enabled:  cfg.RejectedRequestsPurgeJob.Enabled,
+			Jitter:   cfg.RejectedRequestsPurgeJob.Jitter,
+			Run:      services.rejections.Purge,
+		},
+		// Отправленные напоминания хранятся только в PostgreSQL
+		{
+			Name:      "renewal_reminders",
+			Schedule:  cfg.RenewalRemindersJob.Schedule,
+			Enabled:   cfg.RenewalRemindersJob.Enabled && core.postgres(),
+			Jitter:    cfg.RenewalRemindersJob.Jitter,
+			Exclusive: true,
+			Run:       services.reminders.RunReminders,
+		},
+		// Ключи идемпотентности общие для реплик, поэтому очищает их одна
+		{
+			Name:      "idempotency_purge",
+			Schedule:  cfg.IdempotencyPurgeJob.Schedule,
+			Enabled:   cfg.IdempotencyPurgeJob.Enabled && core.postgres() && cfg.IdempotencyTTL > 0,
+			Jitter:    cfg.IdempotencyPurgeJob.Jitter,
+			Exclusive: true,
+			Run:       services.idempotency.Purge,
+		},
+		// Принятые входящие события хранятся только в PostgreSQL
+		{
+			Name:      "inbound_events_purge",
+			Schedule:  cfg.InboundEventsPurgeJob.Schedule,
+			Enabled:   cfg.InboundEventsPurgeJob.Enabled && core.postgres(),
+			Jitter:    cfg.InboundEventsPurgeJob.Jitter,
+			Exclusive: true,
+			Run:       services.inbound.Purge,
+		},
+	} {
+		if err := jobs.Register(job); err != nil {
+			return nil, fmt.Errorf("invalid background job configuration: %w", err)
+		}
+	}
+
+	if enabled {
+		// Задачи живут дольше контекста запуска, поэтому у них собственный контекст
+		ctx, cancel := context.WithCancel(context.Background())
+		lc.Append(lifecycle.Hook{
+			Name: "jobs",
+			OnStart: func(context.Context) error {
+				jobs.Start(ctx)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+	}
+	return &jobsModule{scheduler: jobs}, nil
+}
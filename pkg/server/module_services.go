@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/internal/modifier"
+	"github.com/Zipklas/subscription-service/internal/notifier"
+	"github.com/Zipklas/subscription-service/internal/service"
+	"github.com/Zipklas/subscription-service/internal/signing"
+)
+
+// servicesModule - бизнес-логика поверх репозиториев и шины событий
+type servicesModule struct {
+	subscriptions service.SubscriptionService
+	anomalies     service.AnomalyService
+	sparklines    service.SparklineService
+	dataQuality   service.DataQualityService
+	teams         service.TeamService
+	analytics     service.AnalyticsService
+	templates     service.TemplateService
+	discounts     service.DiscountService
+	catalog       service.CatalogService
+	tags          service.TagService
+	invoices      service.InvoiceService
+	rejections    service.RejectionService
+	teardown      service.TeardownService
+	reminders     service.ReminderService
+	notifications service.NotificationService
+	inbox         service.InboxService
+	audit         service.AuditService
+	idempotency   service.IdempotencyService
+	// backups - журнал резервных копий; копии снимает команда server -backup
+	backups service.BackupService
+	// inbound - прием событий интеграций с отбрасыванием повторов
+	inbound service.InboundService
+	// apiTokens - личные токены API пользователей
+	apiTokens service.APITokenService
+	// groups - сопоставления групп SSO организациям и ролям
+	groups service.GroupMappingService
+	// tenants - заведение организаций системами развертывания
+	tenants service.TenantService
+	// rejectionCounters - счетчики отклоненных запросов для /metrics
+	rejectionCounters *metrics.Rejections
+	// idempotencyCounters - счетчики запросов с Idempotency-Key для /metrics
+	idempotencyCounters *metrics.Idempotency
+	// inboundCounters - счетчики входящих событий для /metrics
+	inboundCounters *metrics.InboundEvents
+}
+
+// newServicesModule создает сервисы; ошибка означает неверную конфигурацию
+// модификаторов стоимости, отправки писем или встроенных шаблонов писем
+func newServicesModule(core *core, storage *storageModule, bus *busModule) (*servicesModule, error) {
+	cfg, log := core.cfg, core.log
+
+	// Модификаторы суммарной стоимости: встроенные по имени и сайдкары по адресу
+	summaryModifiers, err := modifier.Chain(cfg.SummaryModifiers, core.egress.Client(cfg.SummaryModifierTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary modifiers configuration: %w", err)
+	}
+	templates, err := service.NewTemplateService(storage.templates, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default email templates: %w", err)
+	}
+	rejectionCounters := metrics.NewRejections()
+	idempotencyCounters := metrics.NewIdempotency()
+	inboundCounters := metrics.NewInboundEvents()
+
+	// Письма пользователям; без EMAIL_DRIVER настройки уведомлений сохраняются, но писем нет
+	var sender notifier.Sender
+	if cfg.EmailDriver != "" {
+		sender, err = notifier.New(notifier.Config{
+			Driver:         cfg.EmailDriver,
+			From:           cfg.EmailFrom,
+			FromName:       cfg.EmailFromName,
+			SMTPHost:       cfg.SMTPHost,
+			SMTPPort:       cfg.SMTPPort,
+			SMTPUsername:   cfg.SMTPUsername,
+			SMTPPassword:   cfg.SMTPPassword,
+			SendGridAPIKey: cfg.SendGridAPIKey,
+			Timeout:        cfg.EmailTimeout,
+		}, core.egress.Client(cfg.EmailTimeout))
+		if err != nil {
+			return nil, fmt.Errorf("invalid email configuration: %w", err)
+		}
+	}
+	notifications := service.NewNotificationService(storage.notifications, templates, sender, log)
+
+	// Подпись итогов; длина ключа проверена при чтении конфигурации
+	var signer *signing.Signer
+	if cfg.SummarySigningKey != "" {
+		signer = signing.NewSigner(cfg.SummarySigningKeyID, []byte(cfg.SummarySigningKey))
+	}
+
+	// Ставка и округление организации хранятся в PostgreSQL; при DB_DRIVER=sqlite и memory
+	// для всех организаций действуют TAX_RATE_PERCENT и ROUNDING_MODE
+	taxes := service.FixedTax(core.tax)
+	if core.postgres() {
+		taxes = service.NewTenantTaxResolver(storage.tenants, core.tax, log)
+	}
+
+	subscriptions := service.NewTracedSubscriptionService(service.NewSubscriptionService(storage.subscriptions, taxes, summaryModifiers, signer, log))
+	if sender != nil {
+		subscriptions = service.NewNotifyingSubscriptionService(subscriptions, notifications, log)
+	}
+
+	// Входящие уведомления хранятся в PostgreSQL; при DB_DRIVER=sqlite и memory
+	// генераторы уведомлений не подключаются
+	inbox := service.NewInboxService(storage.inbox, log)
+	var anomalyInbox service.AnomalyNotifier
+	// Каналы напоминаний о продлении: по умолчанию - RENEWAL_REMINDER_CHANNELS (значения
+	// проверены при чтении конфигурации), остальные доступны в настройках подписки
+	reminderNotifiers := map[string]service.ReminderNotifier{
+		model.ReminderChannelLog:     service.NewLogReminderNotifier(log),
+		model.ReminderChannelWebhook: service.NewEventReminderNotifier(bus.webhooks),
+	}
+	if sender != nil {
+		reminderNotifiers[model.ReminderChannelEmail] = notifications
+	}
+	reminderChannels := cfg.RenewalReminderChannels
+	if core.postgres() {
+		subscriptions = service.NewInboxSubscriptionService(subscriptions, inbox, log)
+		anomalyInbox = inbox
+		reminderNotifiers[model.ReminderChannelInbox] = inbox
+		reminderChannels = append([]string{model.ReminderChannelInbox}, reminderChannels...)
+	}
+
+	return &servicesModule{
+		subscriptions: subscriptions,
+		anomalies: service.NewAnomalyService(storage.subscriptions, service.AnomalyConfig{
+			ThresholdPercent: cfg.AnomalyThresholdPercent,
+			LookbackMonths:   cfg.AnomalyLookbackMonths,
+			MinSpend:         cfg.AnomalyMinSpend,
+		}, bus.webhooks, anomalyInbox, log),
+		sparklines:  service.NewSparklineService(storage.subscriptions, cfg.SparklineCacheTTL, log),
+		dataQuality: service.NewDataQualityService(storage.subscriptions, log),
+		teams:       service.NewTeamService(storage.subscriptions, log),
+		analytics:   service.NewAnalyticsService(storage.analytics, log),
+		templates:   templates,
+		discounts:   service.NewDiscountService(storage.discounts, storage.subscriptions, log),
+		catalog:     service.NewCatalogService(storage.catalog, log),
+		tags:        service.NewTagService(storage.tags, log),
+		invoices:    service.NewInvoiceService(storage.invoices, storage.subscriptions, taxes, log),
+		rejections:  service.NewRejectionService(storage.rejections, rejectionCounters, time.Duration(cfg.RejectedRequestsRetentionDays)*24*time.Hour, log),
+		teardown:    service.NewTeardownService(storage.teardown, cfg.TeardownEnabled(), log),
+		reminders: service.NewReminderService(storage.reminders, storage.subscriptions, service.ReminderConfig{
+			Days:      cfg.RenewalReminderDays,
+			OpenEnded: cfg.RenewalReminderOpenEnded,
+			Channels:  reminderChannels,
+		}, reminderNotifiers, log),
+		notifications:       notifications,
+		inbox:               inbox,
+		audit:               service.NewAuditService(storage.audit, log),
+		idempotency:         service.NewIdempotencyService(storage.idempotency, idempotencyCounters, cfg.IdempotencyTTL, log),
+		backups:             service.NewBackupService(storage.backups, nil, service.BackupConfig{}, log),
+		inbound:             service.NewInboundService(storage.inbound, subscriptions, inboundCounters, time.Duration(cfg.InboundEventsRetentionDays)*24*time.Hour, log),
+		apiTokens:           service.NewAPITokenService(storage.apiTokens, log),
+		groups:              service.NewGroupMappingService(storage.groups, log),
+		tenants:             service.NewTenantService(storage.tenants, log),
+		rejectionCounters:   rejectionCounters,
+		idempotencyCounters: idempotencyCounters,
+		inboundCounters:     inboundCounters,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Zipklas/subscription-service/internal/database"
+	"github.com/Zipklas/subscription-service/internal/lifecycle"
+	"github.com/Zipklas/subscription-service/internal/metrics"
+	"github.com/Zipklas/subscription-service/internal/repository"
+)
+
+// storageModule - репозитории поверх пула соединений. Чтения подписок идут через
+// кэш одновременных запросов (coalescing), подписки при DB_DRIVER=sqlite - в файл SQLite,
+// при DB_DRIVER=memory - в память процесса
+type storageModule struct {
+	subscriptions repository.SubscriptionRepository
+	coalesced     *metrics.Coalesced
+	analytics     repository.AnalyticsRepository
+	templates     repository.TemplateRepository
+	discounts     repository.DiscountRepository
+	catalog       repository.CatalogRepository
+	tags          repository.TagRepository
+	invoices      repository.InvoiceRepository
+	rejections    repository.RejectionRepository
+	teardown      repository.TeardownRepository
+	reminders     repository.ReminderRepository
+	notifications repository.NotificationRepository
+	inbox         repository.InboxRepository
+	audit         repository.AuditRepository
+	idempotency   repository.IdempotencyRepository
+	backups       repository.BackupRepository
+	inbound       repository.InboundRepository
+	apiTokens     repository.APITokenRepository
+	groups        repository.GroupMappingRepository
+	tenants       repository.TenantRepository
+	// sqlite - база подписок при DB_DRIVER=sqlite, иначе nil
+	sqlite *sql.DB
+}
+
+// newStorageModule создает репозитории. Соединения Postgres принадлежат модулю базы;
+// файл SQLite открывается здесь и закрывается при остановке
+func newStorageModule(lc *lifecycle.Lifecycle, core *core, db *databaseModule) (*storageModule, error) {
+	log, sqlDB := core.log, db.pool.DB
+
+	var storage repository.SubscriptionRepository
+	var sqlite *sql.DB
+	switch core.cfg.DBDriver {
+	case "memory":
+		storage = repository.NewInMemorySubscriptionRepository()
+	case "sqlite":
+		var err error
+		sqlite, err = database.OpenSQLite(context.Background(), core.cfg.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite database %s: %w", core.cfg.SQLitePath, err)
+		}
+		lc.Append(lifecycle.Hook{
+			Name:   "sqlite",
+			OnStop: func(context.Context) error { return sqlite.Close() },
+		})
+		storage = repository.NewSQLiteSubscriptionRepository(sqlite, db.queries, log)
+	default:
+		storage = repository.NewSubscriptionRepository(db.pool, db.queries, log)
+	}
+	// Одновременные чтения одной подписки выполняются одним запросом к базе
+	coalesced := metrics.NewCoalesced()
+
+	return &storageModule{
+		subscriptions: repository.NewCoalescingSubscriptionRepository(storage, coalesced, log),
+		coalesced:     coalesced,
+		analytics:     repository.NewAnalyticsRepository(sqlDB, db.queries, log),
+		templates:     repository.NewTemplateRepository(sqlDB, log),
+		discounts:     repository.NewDiscountRepository(sqlDB, db.queries, log),
+		catalog:       repository.NewCatalogRepository(sqlDB, db.queries, log),
+		tags:          repository.NewTagRepository(sqlDB, db.queries, log),
+		invoices:      repository.NewInvoiceRepository(sqlDB, db.queries, log),
+		rejections:    repository.NewRejectionRepository(sqlDB, db.queries, log),
+		teardown:      repository.NewTeardownRepository(sqlDB, log),
+		reminders:     repository.NewReminderRepository(sqlDB, db.queries, log),
+		notifications: repository.NewNotificationRepository(sqlDB, log),
+		inbox:         repository.NewInboxRepository(sqlDB, db.queries, log),
+		audit:         repository.NewAuditRepository(sqlDB, db.queries, log),
+		idempotency:   repository.NewIdempotencyRepository(sqlDB, db.queries, log),
+		backups:       repository.NewBackupRepository(sqlDB, log),
+		inbound:       repository.NewInboundRepository(sqlDB, db.queries, log),
+		apiTokens:     repository.NewAPITokenRepository(sqlDB, db.queries, log),
+		groups:        repository.NewGroupMappingRepository(sqlDB, db.queries, log),
+		tenants:       repository.NewTenantRepository(sqlDB, log),
+		sqlite:        sqlite,
+	}, nil
+}
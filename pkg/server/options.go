@@ -0,0 +1,67 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/logger"
+)
+
+// Option меняет сборку Server
+type Option func(*options)
+
+type options struct {
+	configFile string
+	dsn        string
+	jobs       bool
+	logger     *logger.Logger
+	// configure применяются к конфигурации после чтения файла и окружения
+	configure []func(*config.Config)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{jobs: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithConfigFile читает конфигурацию из файла YAML или JSON; переменные окружения
+// по-прежнему переопределяют его значения
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithDSN задает строку подключения к Postgres вместо DB_HOST, DB_PORT и остальных DB_*
+func WithDSN(dsn string) Option {
+	return func(o *options) {
+		o.dsn = dsn
+	}
+}
+
+// WithLogger пишет логи сервиса через l вместо собственного JSON-логгера в stdout
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger.Logger{Logger: l}
+	}
+}
+
+// WithAdminToken задает Bearer-токен административного API вместо ADMIN_TOKEN
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.configure = append(o.configure, func(cfg *config.Config) {
+			cfg.AdminToken = token
+		})
+	}
+}
+
+// WithoutBackgroundJobs не запускает фоновые задачи (поиск аномалий, очистку журналов),
+// например, когда их выполняет отдельный экземпляр
+func WithoutBackgroundJobs() Option {
+	return func(o *options) {
+		o.jobs = false
+	}
+}
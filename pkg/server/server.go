@@ -0,0 +1,123 @@
+// Package server собирает сервис подписок целиком - репозитории, сервисы, обработчики
+// и роутер - в http.Handler, который можно запустить отдельно (cmd/server) или
+// подключить в маршруты другого Go-приложения.
+//
+//	srv, err := server.New(server.WithDSN(dsn), server.WithoutBackgroundJobs())
+//	if err != nil {
+//		return err
+//	}
+//	defer srv.Close(context.Background())
+//	mux.Handle("/subscriptions/", http.StripPrefix("/subscriptions", srv))
+//
+// Сервис собирается из модулей (база, хранилище, шина событий, сервисы, фоновые задачи,
+// HTTP), каждый в своем файле module_*.go. Модуль получает зависимости от собранных
+// раньше и регистрирует хуки запуска и остановки в lifecycle.Lifecycle; новая подсистема
+// добавляется новым модулем и одной строкой в New.
+//
+// Часовой пояс периодов и экспорт трассировки - настройки процесса, поэтому в одном
+// процессе стоит создавать один Server.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Zipklas/subscription-service/internal/config"
+	"github.com/Zipklas/subscription-service/internal/lifecycle"
+	"github.com/Zipklas/subscription-service/internal/logger"
+)
+
+// Server - собранный сервис подписок. Реализует http.Handler; Close останавливает
+// фоновые задачи, доставку вебхуков и закрывает пул соединений
+type Server struct {
+	http      *httpModule
+	cfg       *config.Config
+	lifecycle *lifecycle.Lifecycle
+	logger    *logger.Logger
+}
+
+// New читает конфигурацию (файл из WithConfigFile, поверх него - переменные окружения),
+// применяет opts, собирает модули и запускает их: подключается к базе и стартует фоновые задачи
+func New(opts ...Option) (*Server, error) {
+	o := newOptions(opts)
+
+	core, err := newCore(o)
+	if err != nil {
+		return nil, err
+	}
+
+	lc := lifecycle.New()
+	web, err := assemble(lc, core, o)
+	if err != nil {
+		// Ошибка сборки не должна оставлять открытыми пул и очереди вебхуков
+		lc.Stop(context.Background())
+		return nil, err
+	}
+	if err := lc.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		http:      web,
+		cfg:       core.cfg,
+		lifecycle: lc,
+		logger:    core.log,
+	}, nil
+}
+
+// assemble собирает модули в порядке зависимостей и возвращает HTTP-модуль
+func assemble(lc *lifecycle.Lifecycle, core *core, o *options) (*httpModule, error) {
+	db, err := newDatabaseModule(lc, core, o.dsn)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := newStorageModule(lc, core, db)
+	if err != nil {
+		return nil, err
+	}
+	bus := newBusModule(lc, core)
+	services, err := newServicesModule(core, storage, bus)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := newJobsModule(lc, core, db, services, o.jobs)
+	if err != nil {
+		return nil, err
+	}
+	return newHTTPModule(core, db, storage, bus, services, jobs), nil
+}
+
+// ServeHTTP обрабатывает запрос маршрутами сервиса: API под /api/v1, пробы, /metrics и Swagger
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.http.router.ServeHTTP(w, r)
+}
+
+// ListenAndServe запускает HTTP-сервер на APP_PORT с таймаутами из конфигурации
+func (s *Server) ListenAndServe() error {
+	server := &http.Server{
+		Addr:         ":" + s.cfg.AppPort,
+		Handler:      s,
+		ReadTimeout:  s.cfg.ServerReadTimeout,
+		WriteTimeout: s.cfg.ServerWriteTimeout,
+		IdleTimeout:  s.cfg.ServerIdleTimeout,
+	}
+
+	s.logger.Info(context.Background(), "Server starting",
+		"address", "http://localhost:"+s.cfg.AppPort,
+	)
+	s.logger.Info(context.Background(), "Swagger documentation available",
+		"url", "http://localhost:"+s.cfg.AppPort+"/swagger/index.html",
+	)
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	return nil
+}
+
+// Close останавливает модули в обратном порядке: фоновые задачи, доставку поставленных
+// в очередь вебхуков (не дольше ctx) и пул соединений с базой
+func (s *Server) Close(ctx context.Context) error {
+	return s.lifecycle.Stop(ctx)
+}
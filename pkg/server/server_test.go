@@ -0,0 +1,194 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Zipklas/subscription-service/docs"
+	"github.com/Zipklas/subscription-service/internal/handler"
+	"github.com/Zipklas/subscription-service/internal/model"
+	"github.com/Zipklas/subscription-service/pkg/server"
+)
+
+func TestNewMountsIntoMux(t *testing.T) {
+	// Ленивое подключение позволяет собрать сервис без Postgres
+	t.Setenv("DB_LAZY_CONNECT", "true")
+	t.Setenv("APP_ENV", "production")
+
+	srv, err := server.New(
+		server.WithDSN("host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"),
+		server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		server.WithAdminToken("embedded-token"),
+		server.WithoutBackgroundJobs(),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer srv.Close(context.Background())
+
+	mux := http.NewServeMux()
+	mux.Handle("/billing/", http.StripPrefix("/billing", srv))
+
+	for path, want := range map[string]int{
+		"/billing/healthz":                 http.StatusOK,
+		"/billing/health":                  http.StatusServiceUnavailable,
+		"/billing/api/v1/admin/jobs":       http.StatusUnauthorized,
+		"/billing/api/v1/no-such-endpoint": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/billing/api/v1/admin/jobs", nil)
+	req.Header.Set("Authorization", "Bearer embedded-token")
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET admin jobs with token = %d, want 200", rec.Code)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	t.Setenv("ROUNDING_MODE", "sideways")
+
+	if _, err := server.New(server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))); err == nil {
+		t.Fatal("expected error for invalid rounding mode")
+	}
+}
+
+// TestMethodNotAllowed проверяет, что другой метод существующего пути получает 405
+// с методами пути в Allow, а неизвестный путь - 404 при любом методе
+func TestMethodNotAllowed(t *testing.T) {
+	t.Setenv("DB_LAZY_CONNECT", "true")
+	t.Setenv("APP_ENV", "production")
+
+	srv, err := server.New(
+		server.WithDSN("host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"),
+		server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		server.WithAdminToken("embedded-token"),
+		server.WithoutBackgroundJobs(),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer srv.Close(context.Background())
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	tests := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantAllow []string
+	}{
+		{http.MethodPost, "/health", http.StatusMethodNotAllowed, []string{http.MethodGet}},
+		{http.MethodPatch, "/api/v1/subscriptions", http.StatusMethodNotAllowed, []string{http.MethodGet, http.MethodPost}},
+		{http.MethodGet, "/api/v1/subscriptions/60601fee-2bf1-4721-ae6f-7636e79a0cba/activate", http.StatusMethodNotAllowed, []string{http.MethodPost}},
+		{http.MethodGet, "/api/v1/no-such-endpoint", http.StatusNotFound, nil},
+		{http.MethodDelete, "/api/v1/no-such-endpoint", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			var allow []string
+			if header := resp.Header.Get("Allow"); header != "" {
+				allow = strings.Split(header, ", ")
+			}
+			slices.Sort(allow)
+			want := slices.Sorted(slices.Values(tt.wantAllow))
+			if !slices.Equal(allow, want) {
+				t.Errorf("Allow = %q, want %v", resp.Header.Get("Allow"), want)
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, handler.MIMEProblemJSON) {
+				t.Errorf("Content-Type = %q, want %s", got, handler.MIMEProblemJSON)
+			}
+		})
+	}
+}
+
+// TestSwaggerCoversRoutes проверяет, что документация перестроена после изменения API:
+// каждый маршрут /api/v1 описан в docs (go generate ./docs)
+func TestSwaggerCoversRoutes(t *testing.T) {
+	t.Setenv("DB_LAZY_CONNECT", "true")
+
+	srv, err := server.New(
+		server.WithDSN("host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"),
+		server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		server.WithAdminToken("embedded-token"),
+		server.WithoutBackgroundJobs(),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer srv.Close(context.Background())
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/routes", nil)
+	req.Header.Set("Authorization", "Bearer embedded-token")
+	srv.ServeHTTP(rec, req)
+	var routes []model.RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatalf("GET admin routes = %d: %v", rec.Code, err)
+	}
+
+	var spec struct {
+		BasePath string                                `json:"basePath"`
+		Paths    map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec); err != nil {
+		t.Fatalf("invalid swagger document: %v", err)
+	}
+
+	param := regexp.MustCompile(`/[:*](\w+)`)
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, spec.BasePath)
+		if !ok {
+			continue
+		}
+		path = param.ReplaceAllString(path, "/{$1}")
+		if !documented(spec.Paths, path, strings.ToLower(route.Method)) {
+			t.Errorf("%s %s is not documented, regenerate docs", route.Method, route.Path)
+		}
+	}
+}
+
+// documented ищет операцию в документации. Маршрут с параметром внутри сегмента
+// (subscriptions:method) описан отдельно для каждого метода: subscriptions:sync
+func documented(paths map[string]map[string]json.RawMessage, path, method string) bool {
+	if _, ok := paths[path][method]; ok {
+		return true
+	}
+	prefix, _, custom := strings.Cut(path, ":")
+	if !custom {
+		return false
+	}
+	for documentedPath, operations := range paths {
+		if _, ok := operations[method]; ok && strings.HasPrefix(documentedPath, prefix+":") {
+			return true
+		}
+	}
+	return false
+}